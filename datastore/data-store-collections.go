@@ -0,0 +1,132 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// CollectionType is the type of a collection as reported by listCollections.
+	CollectionType string
+
+	// CollectionInfo is a single entry of the listCollections command output.
+	//
+	// See [https://www.mongodb.com/docs/manual/reference/command/listCollections/#output]
+	CollectionInfo struct {
+		Name    string         `bson:"name"`
+		Type    CollectionType `bson:"type"`
+		Options bson.M         `bson:"options"`
+		Info    struct {
+			ReadOnly bool             `bson:"readOnly"`
+			UUID     primitive.Binary `bson:"uuid,omitempty"`
+		} `bson:"info"`
+		IDIndex bson.M `bson:"idIndex,omitempty"`
+	}
+)
+
+const (
+	CollectionTypeCollection CollectionType = "collection"
+	CollectionTypeView       CollectionType = "view"
+	CollectionTypeTimeSeries CollectionType = "timeseries"
+)
+
+// IsView reports whether the entry describes a view rather than a collection that stores documents.
+func (c CollectionInfo) IsView() bool {
+	return c.Type == CollectionTypeView
+}
+
+// ListCollections returns all collections and views of the default database that match the given filter.
+//
+// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Database.ListCollections]
+func (dataStore *DataStore) ListCollections(ctx context.Context, filter bson.M) ([]CollectionInfo, error) {
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	cur, err := dataStore.Database.ListCollections(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []CollectionInfo
+	err = cur.All(ctx, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CollectionExists reports whether a collection or view with the given name exists in the default database.
+func (dataStore *DataStore) CollectionExists(ctx context.Context, name string) (bool, error) {
+	names, err := dataStore.Database.ListCollectionNames(ctx, bson.M{"name": name})
+	if err != nil {
+		return false, err
+	}
+
+	return len(names) > 0, nil
+}
+
+// DropCollection drops the collection with the given name from the default database.
+//
+// By default, a collection that still contains documents is not dropped and [ErrCollectionNotEmpty] is returned.
+// Pass [WithForce] to drop it anyway. Dropping a collection that does not exist is not an error.
+func (dataStore *DataStore) DropCollection(ctx context.Context, name string, opts ...DropOption) error {
+	if dataStore.readOnly {
+		return &ReadOnlyError{Operation: "DropCollection", Collection: name}
+	}
+
+	ops := &dropOption{}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+
+	col := dataStore.Database.Collection(name)
+
+	if !ops.force {
+		count, err := col.CountDocuments(ctx, bson.M{}, options.Count().SetLimit(1))
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("DropCollection %v: %w", name, ErrCollectionNotEmpty)
+		}
+	}
+
+	return col.Drop(ctx)
+}
+
+// RenameCollection renames a collection of the default database, using the admin renameCollection command.
+//
+// [ErrCollectionNotFound] is returned if the source does not exist,
+// and [ErrCollectionExists] if the target exists and dropTarget is false.
+//
+// See [https://www.mongodb.com/docs/manual/reference/command/renameCollection/]
+func (dataStore *DataStore) RenameCollection(ctx context.Context, from, to string, dropTarget bool) error {
+	if dataStore.readOnly {
+		return &ReadOnlyError{Operation: "RenameCollection", Collection: from}
+	}
+
+	dbName := dataStore.Database.Name()
+	cmd := bson.D{
+		{Key: "renameCollection", Value: dbName + "." + from},
+		{Key: "to", Value: dbName + "." + to},
+		{Key: "dropTarget", Value: dropTarget},
+	}
+
+	err := dataStore.Client.Database("admin").RunCommand(ctx, cmd).Err()
+	switch {
+	case err == nil:
+		return nil
+	case hasErrorCode(err, codeNamespaceNotFound):
+		return fmt.Errorf("RenameCollection %v: %w", from, ErrCollectionNotFound)
+	case hasErrorCode(err, codeNamespaceExists):
+		return fmt.Errorf("RenameCollection %v: %w", to, ErrCollectionExists)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,197 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// AutoEncryptionConfig configures automatic client-side field level encryption of a [DataStore].
+	//
+	// The fields map to the driver's [options.AutoEncryptionOptions].
+	// Client-side encryption requires building with the cse tag and libmongocrypt installed,
+	// see [https://www.mongodb.com/docs/drivers/go/current/fundamentals/encrypt-fields/]
+	AutoEncryptionConfig struct {
+		// KeyVaultNamespace is the "db.collection" namespace of the key vault, e.g. "encryption.__keyVault".
+		KeyVaultNamespace string
+		// KmsProviders maps KMS provider names, e.g. "local" or "aws", to their credentials.
+		KmsProviders map[string]map[string]interface{}
+		// SchemaMap maps "db.collection" namespaces to JSON schemas with encrypt keywords, see [EncryptionSchemaFor].
+		SchemaMap map[string]interface{}
+		// EncryptedFieldsMap maps "db.collection" namespaces to encryptedFields documents for Queryable Encryption.
+		EncryptedFieldsMap map[string]interface{}
+		// BypassAutoEncryption disables automatic encryption, while reads are still decrypted.
+		BypassAutoEncryption bool
+		// ExtraOptions are passed to mongocryptd or the crypt_shared library, e.g. "cryptSharedLibPath".
+		ExtraOptions map[string]interface{}
+	}
+
+	// EncryptAlgorithm is the value of the encrypt struct tag used by [EncryptionSchemaFor].
+	EncryptAlgorithm string
+)
+
+const (
+	// EncryptDeterministic always encrypts a value to the same ciphertext, so that the field can be queried for equality.
+	EncryptDeterministic EncryptAlgorithm = "deterministic"
+	// EncryptRandom encrypts a value to a different ciphertext each time. The field cannot be queried.
+	EncryptRandom EncryptAlgorithm = "random"
+)
+
+var (
+	// ErrEncryptionNotConfigured is returned by [DataStore.CreateDataKey] when the DataStore was created without [WithAutoEncryption].
+	ErrEncryptionNotConfigured = errors.New("datastore: auto encryption is not configured")
+)
+
+var encryptAlgorithms = map[EncryptAlgorithm]string{
+	EncryptDeterministic: "AEAD_AES_256_CBC_HMAC_SHA_512-Deterministic",
+	EncryptRandom:        "AEAD_AES_256_CBC_HMAC_SHA_512-Random",
+}
+
+func (config AutoEncryptionConfig) options() *options.AutoEncryptionOptions {
+	opts := options.AutoEncryption().
+		SetKeyVaultNamespace(config.KeyVaultNamespace).
+		SetKmsProviders(config.KmsProviders).
+		SetBypassAutoEncryption(config.BypassAutoEncryption)
+	if config.SchemaMap != nil {
+		opts.SetSchemaMap(config.SchemaMap)
+	}
+	if config.EncryptedFieldsMap != nil {
+		opts.SetEncryptedFieldsMap(config.EncryptedFieldsMap)
+	}
+	if config.ExtraOptions != nil {
+		opts.SetExtraOptions(config.ExtraOptions)
+	}
+	return opts
+}
+
+// CreateDataKey creates a data key in the key vault of the DataStore, encrypted with the master key of the given KMS provider.
+//
+// The returned key id is used in the encryptMetadata of a schema, see [EncryptionSchemaFor].
+// [ErrEncryptionNotConfigured] is returned if the DataStore was created without [WithAutoEncryption].
+//
+// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#ClientEncryption.CreateDataKey]
+func (dataStore *DataStore) CreateDataKey(ctx context.Context, kmsProvider string, opts ...*options.DataKeyOptions) (primitive.Binary, error) {
+	if dataStore.encryption == nil {
+		return primitive.Binary{}, ErrEncryptionNotConfigured
+	}
+
+	ce, err := mongo.NewClientEncryption(dataStore.Client, options.ClientEncryption().
+		SetKeyVaultNamespace(dataStore.encryption.KeyVaultNamespace).
+		SetKmsProviders(dataStore.encryption.KmsProviders))
+	if err != nil {
+		return primitive.Binary{}, fmt.Errorf("%v: %v: %w", "datastore.DataStore.CreateDataKey", kmsProvider, err)
+	}
+	defer ce.Close(ctx)
+
+	id, err := ce.CreateDataKey(ctx, kmsProvider, opts...)
+	if err != nil {
+		return primitive.Binary{}, fmt.Errorf("%v: %v: %w", "datastore.DataStore.CreateDataKey", kmsProvider, err)
+	}
+
+	return id, nil
+}
+
+// EncryptionSchemaFor builds the JSON schema for the schemaMap of [AutoEncryptionConfig] from the encrypt struct tags of T,
+// encrypting all tagged fields with the given data key.
+//
+//	type Customer struct {
+//		mongodb.BaseModel `bson:",inline"`
+//		Name              string `bson:"name"`
+//		TaxID             string `bson:"taxID" encrypt:"deterministic"`
+//		Notes             string `bson:"notes" encrypt:"random"`
+//	}
+//
+// Deterministic encryption requires the bson type of the field to be known,
+// so only strings, booleans, int32, int64, float64, dates, ObjectIDs and byte slices are supported for it.
+func EncryptionSchemaFor[T any](keyID primitive.Binary) (bson.M, error) {
+	properties, err := encryptedProperties(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "datastore.EncryptionSchemaFor", err)
+	}
+
+	return bson.M{
+		"bsonType":        "object",
+		"encryptMetadata": bson.M{"keyId": bson.A{keyID}},
+		"properties":      properties,
+	}, nil
+}
+
+func encryptedProperties(t reflect.Type) (bson.M, error) {
+	fields, err := mongodb.BSONFields(t)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := bson.M{}
+	for _, bsonField := range fields {
+		field := bsonField.Field
+
+		algorithm := EncryptAlgorithm(field.Tag.Get("encrypt"))
+		if algorithm == "" {
+			if mongodb.IsSubdocumentType(field.Type) {
+				nested, err := encryptedProperties(field.Type)
+				if err != nil {
+					return nil, err
+				}
+				if len(nested) > 0 {
+					properties[bsonField.Name] = bson.M{"bsonType": "object", "properties": nested}
+				}
+			}
+			continue
+		}
+
+		name, ok := encryptAlgorithms[algorithm]
+		if !ok {
+			return nil, fmt.Errorf("field %v: unknown encrypt algorithm %q", field.Name, algorithm)
+		}
+		encrypt := bson.M{"algorithm": name}
+		bsonType, known := encryptBSONType(field.Type)
+		switch {
+		case known:
+			encrypt["bsonType"] = bsonType
+		case algorithm == EncryptDeterministic:
+			return nil, fmt.Errorf("field %v: deterministic encryption of %v is not supported", field.Name, field.Type)
+		}
+		properties[bsonField.Name] = bson.M{"encrypt": encrypt}
+	}
+
+	return properties, nil
+}
+
+func encryptBSONType(t reflect.Type) (string, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case reflect.TypeOf(time.Time{}), reflect.TypeOf(primitive.DateTime(0)):
+		return "date", true
+	case reflect.TypeOf(primitive.ObjectID{}):
+		return "objectId", true
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string", true
+	case reflect.Bool:
+		return "bool", true
+	case reflect.Int32:
+		return "int", true
+	case reflect.Int64:
+		return "long", true
+	case reflect.Float64:
+		return "double", true
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "binData", true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,71 @@
+//go:build cse
+
+package datastore_test
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestAutoEncryption(t *testing.T) {
+	ctx := context.Background()
+
+	masterKey := make([]byte, 96)
+	if _, err := rand.Read(masterKey); err != nil {
+		t.Fatalf("Error generating master key: %v", err)
+	}
+	config := datastore.AutoEncryptionConfig{
+		KeyVaultNamespace: "testdb_encryption.__keyVault",
+		KmsProviders:      map[string]map[string]interface{}{"local": {"key": masterKey}},
+	}
+
+	// The data key is created first, since the schema map needs its id.
+	keyStore, err := datastore.NewDataStore("mongodb://localhost:27017", "testdb", datastore.WithAutoEncryption(config))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer keyStore.Disconnect()
+	defer keyStore.DatabaseFor("testdb_encryption").Drop(ctx)
+
+	keyID, err := keyStore.CreateDataKey(ctx, "local")
+	if err != nil {
+		t.Fatalf("Error creating data key: %v", err)
+	}
+
+	schema, err := datastore.EncryptionSchemaFor[EncryptedCustomer](keyID)
+	if err != nil {
+		t.Fatalf("Error building schema: %v", err)
+	}
+	config.SchemaMap = map[string]interface{}{"testdb.customer_encrypted": schema}
+
+	store, err := datastore.NewDataStore("mongodb://localhost:27017", "testdb", datastore.WithAutoEncryption(config))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer store.Disconnect()
+
+	repo := datastore.RepositoryFor[*EncryptedCustomer](store, "customer_encrypted")
+	defer repo.DeleteMany(ctx, bson.M{})
+
+	if _, err := repo.InsertOne(ctx, &EncryptedCustomer{Name: "ACME", TaxID: "DE123", Address: EncryptedAddress{Street: "Main St 1", City: "Berlin"}}); err != nil {
+		t.Fatalf("Error on inserting customer: %v", err)
+	}
+
+	// Deterministically encrypted fields can be queried, and are decrypted transparently.
+	customer, err := repo.FindOne(ctx, bson.M{"taxID": "DE123"})
+	if err != nil {
+		t.Fatalf("Error on finding customer: %v", err)
+	}
+	assert.Equal(t, "Main St 1", customer.Address.Street)
+
+	// Without auto encryption, the stored values are ciphertexts.
+	var raw bson.M
+	err = newTestDataStore(t).Database.Collection("customer_encrypted").FindOne(ctx, bson.M{}).Decode(&raw)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "DE123", raw["taxID"])
+}
@@ -0,0 +1,89 @@
+package datastore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	EncryptedCustomer struct {
+		mongodb.BaseModel `bson:",inline"`
+		Name              string           `bson:"name"`
+		TaxID             string           `bson:"taxID" encrypt:"deterministic"`
+		Address           EncryptedAddress `bson:"address"`
+	}
+
+	EncryptedAddress struct {
+		Street string    `bson:"street" encrypt:"random"`
+		City   string    `bson:"city"`
+		Since  time.Time `bson:"since"`
+	}
+)
+
+func TestEncryptionSchemaFor(t *testing.T) {
+	keyID := primitive.Binary{Subtype: 4, Data: make([]byte, 16)}
+
+	schema, err := datastore.EncryptionSchemaFor[EncryptedCustomer](keyID)
+	if err != nil {
+		t.Fatalf("Error on building schema: %v", err)
+	}
+
+	assert.Equal(t, bson.M{
+		"bsonType":        "object",
+		"encryptMetadata": bson.M{"keyId": bson.A{keyID}},
+		"properties": bson.M{
+			"taxID": bson.M{"encrypt": bson.M{"bsonType": "string", "algorithm": "AEAD_AES_256_CBC_HMAC_SHA_512-Deterministic"}},
+			"address": bson.M{"bsonType": "object", "properties": bson.M{
+				"street": bson.M{"encrypt": bson.M{"bsonType": "string", "algorithm": "AEAD_AES_256_CBC_HMAC_SHA_512-Random"}},
+			}},
+		},
+	}, schema)
+}
+
+func TestEncryptionSchemaForInline(t *testing.T) {
+	type Contact struct {
+		EncryptedAddress `bson:",inline,omitempty"`
+		Avatar           primitive.Binary `bson:"avatar"`
+		Internal         string           `bson:"-" encrypt:"random"`
+	}
+
+	schema, err := datastore.EncryptionSchemaFor[Contact](primitive.Binary{})
+	if err != nil {
+		t.Fatalf("Error on building schema: %v", err)
+	}
+	assert.Equal(t, bson.M{
+		"street": bson.M{"encrypt": bson.M{"bsonType": "string", "algorithm": "AEAD_AES_256_CBC_HMAC_SHA_512-Random"}},
+	}, schema["properties"])
+}
+
+func TestEncryptionSchemaForUnsupportedType(t *testing.T) {
+	type Tagged struct {
+		Tags []string `bson:"tags" encrypt:"deterministic"`
+	}
+	_, err := datastore.EncryptionSchemaFor[Tagged](primitive.Binary{})
+	assert.ErrorContains(t, err, "deterministic encryption of []string is not supported")
+
+	type Unknown struct {
+		Name string `bson:"name" encrypt:"aes"`
+	}
+	_, err = datastore.EncryptionSchemaFor[Unknown](primitive.Binary{})
+	assert.ErrorContains(t, err, `unknown encrypt algorithm "aes"`)
+}
+
+func TestCreateDataKeyNotConfigured(t *testing.T) {
+	store, err := datastore.NewDataStore("mongodb://localhost:27017", "testdb", datastore.WithUsePingOption(false))
+	if err != nil {
+		t.Fatalf("Error creating DataStore: %v", err)
+	}
+	defer store.Disconnect()
+
+	_, err = store.CreateDataKey(context.Background(), "local")
+	assert.ErrorIs(t, err, datastore.ErrEncryptionNotConfigured)
+}
@@ -0,0 +1,195 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/filestore"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	defaultEraseParallelism = 4
+	defaultEraseBatchSize   = 1000
+)
+
+type (
+	// ErasableRepository is a repository whose documents can be erased by a [TenantEraser], every [mongodb.Repository] is one.
+	ErasableRepository interface {
+		mongodb.Counter
+		mongodb.DeleteManyAudited
+	}
+
+	// TenantEraser deletes all data of a company from a set of registered collections and GridFS buckets,
+	// e.g. to fulfill a data deletion request.
+	//
+	// Collections are registered once with the name of their companyID field, and every call to [TenantEraser.Erase]
+	// erases the same set of collections. A TenantEraser must not be modified while Erase is running.
+	TenantEraser struct {
+		dataStore   *DataStore
+		targets     []eraseTarget
+		parallelism int
+		batchSize   int
+	}
+
+	// EraseReport is the result of [TenantEraser.Erase], with one entry per registered collection in registration order.
+	EraseReport struct {
+		CompanyID   primitive.ObjectID
+		DryRun      bool
+		Collections []CollectionErasure
+	}
+
+	// CollectionErasure is the result of erasing a single collection or GridFS bucket.
+	//
+	// Count is the number of documents or files of the company, on a dry run, and the number of deleted ones otherwise.
+	// If Err is set, Count is the number deleted before the error occurred.
+	CollectionErasure struct {
+		Collection string
+		Count      int
+		Err        error
+	}
+
+	// eraseTarget counts and deletes the data of a company in a single collection or bucket.
+	eraseTarget struct {
+		name  string
+		count func(ctx context.Context, companyID primitive.ObjectID) (int, error)
+		erase func(ctx context.Context, companyID primitive.ObjectID, batchSize int) (int, error)
+	}
+
+	// erasedDocument is the document type of collections registered by name, only _id is ever read.
+	erasedDocument struct {
+		mongodb.BaseModel `bson:",inline"`
+	}
+)
+
+// NewTenantEraser creates a TenantEraser for the collections of dataStore, see [TenantEraser.Register].
+func NewTenantEraser(dataStore *DataStore, opts ...EraserOption) *TenantEraser {
+	ops := &eraserOption{parallelism: defaultEraseParallelism, batchSize: defaultEraseBatchSize}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+
+	return &TenantEraser{dataStore: dataStore, parallelism: ops.parallelism, batchSize: ops.batchSize}
+}
+
+// Register registers the collection with the given name of the default database, whose documents store the companyID in companyField.
+//
+// Documents are matched by companyField only, so soft deleted documents are deleted as well.
+func (e *TenantEraser) Register(collection string, companyField string) *TenantEraser {
+	return e.RegisterRepository(collection, RepositoryFor[*erasedDocument](e.dataStore, collection).(ErasableRepository), companyField)
+}
+
+// RegisterRepository registers a repository, whose documents store the companyID in companyField.
+// The name is only used in the [EraseReport].
+//
+// Documents are matched by companyField only, so soft deleted documents are deleted as well.
+func (e *TenantEraser) RegisterRepository(name string, repo ErasableRepository, companyField string) *TenantEraser {
+	filter := func(companyID primitive.ObjectID) bson.M {
+		return bson.M{companyField: companyID}
+	}
+
+	e.targets = append(e.targets, eraseTarget{
+		name: name,
+		count: func(ctx context.Context, companyID primitive.ObjectID) (int, error) {
+			return repo.CountDocuments(ctx, filter(companyID))
+		},
+		erase: func(ctx context.Context, companyID primitive.ObjectID, batchSize int) (int, error) {
+			return repo.DeleteManyAudited(ctx, filter(companyID), batchSize, func([]primitive.ObjectID) error { return nil })
+		},
+	})
+	return e
+}
+
+// RegisterFiles registers a GridFS bucket, whose files store the companyID in the companyField of their metadata.
+// The name is only used in the [EraseReport]. Files are deleted together with their chunks.
+func (e *TenantEraser) RegisterFiles(name string, files *filestore.FileRepository, companyField string) *TenantEraser {
+	filter := func(companyID primitive.ObjectID) bson.M {
+		return bson.M{companyField: companyID}
+	}
+
+	e.targets = append(e.targets, eraseTarget{
+		name: name,
+		count: func(ctx context.Context, companyID primitive.ObjectID) (int, error) {
+			infos, err := files.Find(ctx, filter(companyID))
+			return len(infos), err
+		},
+		erase: func(ctx context.Context, companyID primitive.ObjectID, _ int) (int, error) {
+			infos, err := files.Find(ctx, filter(companyID))
+			if err != nil {
+				return 0, err
+			}
+
+			deleted := 0
+			for _, info := range infos {
+				err := files.Delete(ctx, info.ID)
+				if errors.Is(err, mongodb.ErrNotFound) {
+					// Deleted concurrently.
+					continue
+				}
+				if err != nil {
+					return deleted, err
+				}
+				deleted++
+			}
+			return deleted, nil
+		},
+	})
+	return e
+}
+
+// Erase deletes all documents and files of the company with the given id from the registered collections, and returns
+// the number of deleted documents per collection. On a dry run, the matching documents and files are only counted.
+//
+// The collections are erased concurrently, but at most as many at once as set by [WithEraseParallelism], and documents
+// are deleted in batches, see [WithEraseBatchSize]. A failing collection does not stop the others: its error is recorded
+// in the report, and the returned error joins the errors of all failed collections.
+func (e *TenantEraser) Erase(ctx context.Context, companyID primitive.ObjectID, dryRun bool) (EraseReport, error) {
+	report := EraseReport{
+		CompanyID:   companyID,
+		DryRun:      dryRun,
+		Collections: make([]CollectionErasure, len(e.targets)),
+	}
+
+	var g errgroup.Group
+	g.SetLimit(e.parallelism)
+	erase := func(erasure *CollectionErasure, target eraseTarget) func() error {
+		return func() error {
+			var count int
+			var err error
+			if dryRun {
+				count, err = target.count(ctx, companyID)
+			} else {
+				count, err = target.erase(ctx, companyID, e.batchSize)
+			}
+
+			*erasure = CollectionErasure{Collection: target.name, Count: count, Err: err}
+			// Errors are collected in the report, so that the other collections are still erased.
+			return nil
+		}
+	}
+	for i, target := range e.targets {
+		g.Go(erase(&report.Collections[i], target))
+	}
+	g.Wait()
+
+	var errs []error
+	for _, c := range report.Collections {
+		if c.Err != nil {
+			errs = append(errs, fmt.Errorf("%v: %v: %w", "datastore.TenantEraser.Erase", c.Collection, c.Err))
+		}
+	}
+	return report, mongodb.JoinErrors(errs...)
+}
+
+// Total returns the sum of the counts of all collections.
+func (r EraseReport) Total() int {
+	total := 0
+	for _, c := range r.Collections {
+		total += c.Count
+	}
+	return total
+}
@@ -0,0 +1,122 @@
+package datastore_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/DataInsightHub/Go-Mongo-Helper/filestore"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	TenantOrder struct {
+		mongodb.BaseModel `bson:",inline"`
+		CompanyID         primitive.ObjectID `bson:"companyID"`
+		DeletedAt         *time.Time         `bson:"deletedAt,omitempty"`
+	}
+
+	TenantContact struct {
+		mongodb.BaseModel `bson:",inline"`
+		OwnerID           primitive.ObjectID `bson:"ownerCompany"`
+	}
+)
+
+func TestTenantEraser(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDataStore(t)
+
+	orders := datastore.RepositoryFor[*TenantOrder](store, "eraser_orders").(*mongodb.Repository[*TenantOrder])
+	contacts := datastore.RepositoryFor[*TenantContact](store, "eraser_contacts").(*mongodb.Repository[*TenantContact])
+	defer orders.Drop(ctx)
+	defer contacts.Drop(ctx)
+	defer store.DropCollection(ctx, "eraser_files.files", datastore.WithForce())
+	defer store.DropCollection(ctx, "eraser_files.chunks", datastore.WithForce())
+
+	files, err := filestore.NewFileRepository(store.Database, options.GridFSBucket().SetName("eraser_files"))
+	if err != nil {
+		t.Fatalf("Error creating FileRepository: %v", err)
+	}
+
+	target, other := primitive.NewObjectID(), primitive.NewObjectID()
+	deletedAt := time.Now()
+	for _, companyID := range []primitive.ObjectID{target, other} {
+		if _, err := orders.InsertMany(ctx, []*TenantOrder{{CompanyID: companyID}, {CompanyID: companyID}, {CompanyID: companyID, DeletedAt: &deletedAt}}); err != nil {
+			t.Fatalf("Error on inserting orders: %v", err)
+		}
+		if _, err := contacts.InsertOne(ctx, &TenantContact{OwnerID: companyID}); err != nil {
+			t.Fatalf("Error on inserting contact: %v", err)
+		}
+		for _, name := range []string{"a.txt", "b.txt"} {
+			if _, err := files.Upload(ctx, name, strings.NewReader(name), bson.M{"companyID": companyID}); err != nil {
+				t.Fatalf("Error on uploading file: %v", err)
+			}
+		}
+	}
+
+	eraser := datastore.NewTenantEraser(store, datastore.WithEraseBatchSize(2), datastore.WithEraseParallelism(2)).
+		Register("eraser_orders", "companyID").
+		RegisterRepository("eraser_contacts", contacts, "ownerCompany").
+		RegisterFiles("eraser_files", files, "companyID")
+
+	report, err := eraser.Erase(ctx, target, true)
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, []datastore.CollectionErasure{
+		{Collection: "eraser_orders", Count: 3},
+		{Collection: "eraser_contacts", Count: 1},
+		{Collection: "eraser_files", Count: 2},
+	}, report.Collections)
+
+	count, err := orders.CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 6, count)
+
+	report, err = eraser.Erase(ctx, target, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, report.Total())
+
+	for _, check := range []struct {
+		repo  mongodb.Counter
+		field string
+	}{{orders, "companyID"}, {contacts, "ownerCompany"}} {
+		count, err := check.repo.CountDocuments(ctx, bson.M{check.field: target})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		count, err = check.repo.CountDocuments(ctx, bson.M{check.field: other})
+		assert.NoError(t, err)
+		assert.NotZero(t, count)
+	}
+
+	infos, err := files.Find(ctx, bson.M{"companyID": target})
+	assert.NoError(t, err)
+	assert.Empty(t, infos)
+	infos, err = files.Find(ctx, bson.M{"companyID": other})
+	assert.NoError(t, err)
+	assert.Len(t, infos, 2)
+}
+
+func TestTenantEraserCollectsErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	store := newTestDataStore(t)
+
+	eraser := datastore.NewTenantEraser(store).
+		Register("eraser_a", "companyID").
+		Register("eraser_b", "companyID")
+
+	report, err := eraser.Erase(ctx, primitive.NewObjectID(), false)
+	assert.ErrorIs(t, err, context.Canceled)
+	if assert.Len(t, report.Collections, 2) {
+		assert.Equal(t, "eraser_a", report.Collections[0].Collection)
+		assert.ErrorIs(t, report.Collections[0].Err, context.Canceled)
+		assert.ErrorIs(t, report.Collections[1].Err, context.Canceled)
+	}
+}
@@ -0,0 +1,115 @@
+package datastore
+
+import (
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	// ErrCollectionNotFound is returned when an operation requires an existing collection.
+	ErrCollectionNotFound = errors.New("datastore: collection does not exist")
+	// ErrCollectionExists is returned when an operation would overwrite an existing collection.
+	ErrCollectionExists = errors.New("datastore: collection already exists")
+	// ErrCollectionNotEmpty is returned by [DataStore.DropCollection] when the collection still contains documents and [WithForce] was not passed.
+	ErrCollectionNotEmpty = errors.New("datastore: collection is not empty")
+	// ErrDataStoreClosed is returned by repositories of a DataStore once [DataStore.Shutdown] was called.
+	ErrDataStoreClosed = errors.New("datastore: data store is closed")
+	// ErrFeatureNotSupported is returned when the connected server or topology does not support a [Feature].
+	ErrFeatureNotSupported = errors.New("datastore: feature not supported by the server")
+	// ErrReadOnly is returned by repositories created via [NewReadRepositoryForView] for every write operation,
+	// and by DataStores created with [WithReadOnly] for every write operation and maintenance command.
+	ErrReadOnly = errors.New("datastore: repository is read-only")
+	// ErrNotReady is returned by [WaitForReady] when MongoDB did not answer in time.
+	ErrNotReady = errors.New("datastore: MongoDB is not ready")
+	// ErrUnknownTenant is returned by a [RoutedRepository] when the tenant of the context has no companyID or database,
+	// and should be returned by a [TenantResolver] for contexts without a known tenant.
+	ErrUnknownTenant = errors.New("datastore: unknown tenant")
+	// ErrTenantMismatch is returned by a [RoutedRepository] for documents that store the companyID of another tenant.
+	ErrTenantMismatch = errors.New("datastore: document belongs to another tenant")
+)
+
+// Server error codes, see [https://github.com/mongodb/mongo/blob/master/src/mongo/base/error_codes.yml]
+const (
+	codeIllegalOperation    int32 = 20
+	codeNamespaceNotFound   int32 = 26
+	codeNamespaceExists     int32 = 48
+	codeCommandNotFound     int32 = 59
+	codeCommandNotSupported int32 = 115
+)
+
+func hasErrorCode(err error, code int32) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Code == code
+	}
+
+	return false
+}
+
+// CollectionMismatchError is returned when a collection already exists, but was created with different options than requested.
+//
+// It matches [ErrCollectionExists] with errors.Is.
+type CollectionMismatchError struct {
+	Collection string
+	Option     string
+	Expected   interface{}
+	Actual     interface{}
+}
+
+func (e *CollectionMismatchError) Error() string {
+	return fmt.Sprintf("datastore: collection %v already exists with %v %v, expected %v", e.Collection, e.Option, e.Actual, e.Expected)
+}
+
+func (e *CollectionMismatchError) Unwrap() error {
+	return ErrCollectionExists
+}
+
+// ReadOnlyError is returned when a write operation is called on a read-only repository.
+//
+// It matches [ErrReadOnly] with errors.Is.
+type ReadOnlyError struct {
+	Operation  string
+	Collection string
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("datastore: %v on %v: repository is read-only", e.Operation, e.Collection)
+}
+
+func (e *ReadOnlyError) Unwrap() error {
+	return ErrReadOnly
+}
+
+// WaitError is returned by [WaitForReady] when MongoDB could not be reached.
+//
+// It matches [ErrNotReady] with errors.Is if MongoDB did not answer before the context ended,
+// but not if waiting was ended early by an error that retrying can not fix, like a failed authentication.
+type WaitError struct {
+	// Attempts is the number of connection attempts.
+	Attempts int
+	// Err is the error of the last attempt.
+	Err error
+	// NotReady is true if the context ended while waiting.
+	NotReady bool
+}
+
+func (e *WaitError) Error() string {
+	attempts := "attempts"
+	if e.Attempts == 1 {
+		attempts = "attempt"
+	}
+	if e.NotReady {
+		return fmt.Sprintf("datastore: MongoDB not ready after %d %v: %v", e.Attempts, attempts, e.Err)
+	}
+	return fmt.Sprintf("datastore: connecting to MongoDB failed after %d %v: %v", e.Attempts, attempts, e.Err)
+}
+
+func (e *WaitError) Unwrap() error {
+	return e.Err
+}
+
+func (e *WaitError) Is(target error) bool {
+	return e.NotReady && target == ErrNotReady
+}
@@ -0,0 +1,115 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type (
+	// ValidationResult is the decoded output of the validate command, see [DataStore.ValidateCollection].
+	ValidationResult struct {
+		// Namespace is the validated collection as "database.collection".
+		Namespace string `bson:"ns"`
+		// Valid is false if the collection or one of its indexes is corrupt.
+		Valid    bool     `bson:"valid"`
+		Errors   []string `bson:"errors"`
+		Warnings []string `bson:"warnings"`
+		// NRecords is the number of documents in the collection.
+		NRecords int64 `bson:"nrecords"`
+		NIndexes int   `bson:"nIndexes"`
+		// KeysPerIndex is the number of keys of each index by the name of the index.
+		KeysPerIndex map[string]int64 `bson:"keysPerIndex"`
+	}
+
+	// UnsupportedCommandError is returned when the storage engine or the topology of the server does not support a command.
+	//
+	// It matches [ErrFeatureNotSupported] with errors.Is.
+	UnsupportedCommandError struct {
+		Command    string
+		Collection string
+		Reason     string
+		// Err is the error of the server, nil if the command was refused before it was sent.
+		Err error
+	}
+)
+
+func (e *UnsupportedCommandError) Error() string {
+	return fmt.Sprintf("datastore: %v on %v is not supported: %v", e.Command, e.Collection, e.Reason)
+}
+
+func (e *UnsupportedCommandError) Unwrap() error {
+	return e.Err
+}
+
+func (e *UnsupportedCommandError) Is(target error) bool {
+	return target == ErrFeatureNotSupported
+}
+
+// ValidateCollection checks the data and the indexes of a collection of the default database for corruption.
+//
+// With full set, the validation is thorough, but it blocks all operations on the collection while it runs.
+// A corrupt collection is not an error, it is reported by [ValidationResult.Valid] and [ValidationResult.Errors].
+// [ErrCollectionNotFound] is returned if the collection does not exist.
+//
+// See [https://www.mongodb.com/docs/manual/reference/command/validate/]
+func (dataStore *DataStore) ValidateCollection(ctx context.Context, name string, full bool) (ValidationResult, error) {
+	if dataStore.readOnly {
+		return ValidationResult{}, &ReadOnlyError{Operation: "ValidateCollection", Collection: name}
+	}
+
+	var res ValidationResult
+	err := dataStore.Database.RunCommand(ctx, bson.D{{Key: "validate", Value: name}, {Key: "full", Value: full}}).Decode(&res)
+	switch {
+	case err == nil:
+		return res, nil
+	case hasErrorCode(err, codeNamespaceNotFound):
+		return ValidationResult{}, fmt.Errorf("ValidateCollection %v: %w", name, ErrCollectionNotFound)
+	default:
+		return ValidationResult{}, fmt.Errorf("ValidateCollection %v: %w", name, err)
+	}
+}
+
+// CompactCollection rewrites the data and the indexes of a collection of the default database to release unused disk space.
+//
+// Compaction is only supported by the WiredTiger storage engine, and has to be run on the members of the shards
+// instead of a mongos. In these cases, and when the server refuses the command, an [*UnsupportedCommandError] is returned.
+// [ErrCollectionNotFound] is returned if the collection does not exist.
+//
+// See [https://www.mongodb.com/docs/manual/reference/command/compact/]
+func (dataStore *DataStore) CompactCollection(ctx context.Context, name string) error {
+	if dataStore.readOnly {
+		return &ReadOnlyError{Operation: "CompactCollection", Collection: name}
+	}
+
+	dataStore.infoMu.Lock()
+	info := dataStore.serverInfo
+	dataStore.infoMu.Unlock()
+	if info == nil {
+		// Without server info, the server decides whether it supports the command.
+		if fetched, err := dataStore.ServerInfo(ctx); err == nil {
+			info = &fetched
+		}
+	}
+	if info != nil {
+		switch {
+		case info.StorageEngine != "" && info.StorageEngine != "wiredTiger":
+			return &UnsupportedCommandError{Command: "compact", Collection: name, Reason: "storage engine " + info.StorageEngine}
+		case info.Sharded:
+			return &UnsupportedCommandError{Command: "compact", Collection: name, Reason: "connected to a mongos, compact the members of the shards instead"}
+		}
+	}
+
+	err := dataStore.Database.RunCommand(ctx, bson.D{{Key: "compact", Value: name}}).Err()
+	switch {
+	case err == nil:
+		return nil
+	case hasErrorCode(err, codeNamespaceNotFound):
+		return fmt.Errorf("CompactCollection %v: %w", name, ErrCollectionNotFound)
+	case hasErrorCode(err, codeCommandNotFound), hasErrorCode(err, codeCommandNotSupported), hasErrorCode(err, codeIllegalOperation):
+		return &UnsupportedCommandError{Command: "compact", Collection: name, Reason: err.Error(), Err: err}
+	default:
+		return fmt.Errorf("CompactCollection %v: %w", name, err)
+	}
+}
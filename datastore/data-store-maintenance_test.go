@@ -0,0 +1,61 @@
+package datastore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidateCollection(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDataStore(t)
+
+	repo := datastore.RepositoryFor[*User](store, "validate")
+	defer store.Database.Collection("validate").Drop(context.Background())
+	if _, err := repo.InsertMany(ctx, []*User{{Name: "Willy"}, {Name: "Tom"}, {Name: "Anna"}}); err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	for _, full := range []bool{false, true} {
+		res, err := store.ValidateCollection(ctx, "validate", full)
+		assert.NoError(t, err)
+		assert.True(t, res.Valid)
+		assert.Empty(t, res.Errors)
+		assert.Equal(t, "testdb.validate", res.Namespace)
+		assert.Equal(t, int64(3), res.NRecords)
+		assert.Equal(t, 1, res.NIndexes)
+		assert.Equal(t, int64(3), res.KeysPerIndex["_id_"])
+	}
+
+	_, err := store.ValidateCollection(ctx, "validate_missing", false)
+	assert.ErrorIs(t, err, datastore.ErrCollectionNotFound)
+}
+
+func TestMaintenanceReadOnly(t *testing.T) {
+	ctx := context.Background()
+	store, err := datastore.NewDataStore("mongodb://localhost:27017", "testdb", datastore.WithUsePingOption(false), datastore.WithReadOnly())
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer store.Disconnect()
+
+	_, err = store.ValidateCollection(ctx, "validate", true)
+	assert.ErrorIs(t, err, datastore.ErrReadOnly)
+	assert.ErrorIs(t, store.CompactCollection(ctx, "validate"), datastore.ErrReadOnly)
+	assert.ErrorIs(t, store.DropCollection(ctx, "validate", datastore.WithForce()), datastore.ErrReadOnly)
+
+	var readOnly *datastore.ReadOnlyError
+	_, err = datastore.RepositoryFor[*User](store, "validate").DeleteMany(ctx, primitive.M{})
+	if assert.ErrorAs(t, err, &readOnly) {
+		assert.Equal(t, "DeleteMany", readOnly.Operation)
+	}
+}
+
+func TestUnsupportedCommandError(t *testing.T) {
+	err := &datastore.UnsupportedCommandError{Command: "compact", Collection: "users", Reason: "storage engine inMemory"}
+	assert.ErrorIs(t, err, datastore.ErrFeatureNotSupported)
+	assert.EqualError(t, err, "datastore: compact on users is not supported: storage engine inMemory")
+}
@@ -1,7 +1,10 @@
 package datastore
 
 import (
+	"context"
 	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
 )
 
 type (
@@ -12,8 +15,14 @@ type (
 
 type (
 	dataStoreOption struct {
-		timeout time.Duration
-		usePing bool
+		timeout    time.Duration
+		usePing    bool
+		encryption *AutoEncryptionConfig
+		requestTag func(ctx context.Context) string
+		maxWait    time.Duration
+		readOnly   bool
+		diagnose   bool
+		keys       mongodb.KeyProvider
 	}
 )
 
@@ -40,3 +49,180 @@ func (value usePingOption) apply(o *dataStoreOption) {
 func WithUsePingOption(usePing bool) DataStoreOptions {
 	return usePingOption(usePing)
 }
+
+type autoEncryptionOption AutoEncryptionConfig
+
+func (value autoEncryptionOption) apply(o *dataStoreOption) {
+	config := AutoEncryptionConfig(value)
+	o.encryption = &config
+}
+
+// WithAutoEncryption enables automatic client-side field level encryption,
+// so that repositories of the DataStore transparently encrypt and decrypt the fields of the schema map.
+func WithAutoEncryption(config AutoEncryptionConfig) DataStoreOptions {
+	return autoEncryptionOption(config)
+}
+
+type requestTaggingOption func(ctx context.Context) string
+
+func (value requestTaggingOption) apply(o *dataStoreOption) {
+	o.requestTag = value
+}
+
+// WithRequestTagging applies [mongodb.WithRequestTagging] to all repositories created by [RepositoryFor] and [NewReadRepositoryForView].
+func WithRequestTagging(extract func(ctx context.Context) string) DataStoreOptions {
+	return requestTaggingOption(extract)
+}
+
+type waitForReadyOption time.Duration
+
+func (value waitForReadyOption) apply(o *dataStoreOption) {
+	o.maxWait = time.Duration(value)
+}
+
+// WithWaitForReady makes [NewDataStore] wait up to maxWait for MongoDB to accept connections with [WaitForReady],
+// before it connects with the timeout of [WithTimeoutOption].
+func WithWaitForReady(maxWait time.Duration) DataStoreOptions {
+	return waitForReadyOption(maxWait)
+}
+
+type readOnlyOption bool
+
+func (value readOnlyOption) apply(o *dataStoreOption) {
+	o.readOnly = bool(value)
+}
+
+// WithReadOnly marks the DataStore as read-only, e.g. for tools that inspect a production database.
+// Repositories of the DataStore reject write operations, and the collection and maintenance commands
+// like [DataStore.DropCollection] and [DataStore.CompactCollection] refuse to run, with a [*ReadOnlyError].
+func WithReadOnly() DataStoreOptions {
+	return readOnlyOption(true)
+}
+
+type timeoutDiagnosticsOption bool
+
+func (value timeoutDiagnosticsOption) apply(o *dataStoreOption) {
+	o.diagnose = bool(value)
+}
+
+// WithTimeoutDiagnostics installs the monitors of [mongodb.NewTimeoutDiagnostics] on the client, and registers it on all repositories
+// created by [RepositoryFor] and [NewReadRepositoryForView], so that their timeout errors tell [mongodb.TimeoutDetails]
+// whether the server was slow or the pool was exhausted.
+func WithTimeoutDiagnostics() DataStoreOptions {
+	return timeoutDiagnosticsOption(true)
+}
+
+type encryptedFieldsOption struct {
+	provider mongodb.KeyProvider
+}
+
+func (value encryptedFieldsOption) apply(o *dataStoreOption) {
+	o.keys = value.provider
+}
+
+// WithEncryptedFields sets [mongodb.EncryptionRegistry] with provider on the client and on all repositories created by
+// [RepositoryFor], [NewReadRepositoryForView] and [NewRoutedRepository], so that their [mongodb.EncryptedString] and
+// [mongodb.EncryptedBytes] fields are encrypted with the keys of provider. DataStores with different providers do not
+// affect each other.
+//
+// Unlike [WithAutoEncryption], the fields are encrypted by the application, so it needs neither libmongocrypt nor a key vault.
+func WithEncryptedFields(provider mongodb.KeyProvider) DataStoreOptions {
+	return encryptedFieldsOption{provider: provider}
+}
+
+type (
+	DropOption interface {
+		apply(*dropOption)
+	}
+)
+
+type (
+	dropOption struct {
+		force bool
+	}
+)
+
+type forceOption bool
+
+func (value forceOption) apply(o *dropOption) {
+	o.force = bool(value)
+}
+
+// WithForce allows [DataStore.DropCollection] to drop collections that still contain documents.
+func WithForce() DropOption {
+	return forceOption(true)
+}
+
+type (
+	// CollectionOption configures the collection created by [EnsureCollection].
+	CollectionOption interface {
+		apply(*collectionOption)
+	}
+)
+
+type (
+	collectionOption struct {
+		validationLevel  ValidationLevel
+		validationAction ValidationAction
+	}
+)
+
+type validationLevelOption ValidationLevel
+
+func (value validationLevelOption) apply(o *collectionOption) {
+	o.validationLevel = ValidationLevel(value)
+}
+
+func WithValidationLevel(level ValidationLevel) CollectionOption {
+	return validationLevelOption(level)
+}
+
+type validationActionOption ValidationAction
+
+func (value validationActionOption) apply(o *collectionOption) {
+	o.validationAction = ValidationAction(value)
+}
+
+func WithValidationAction(action ValidationAction) CollectionOption {
+	return validationActionOption(action)
+}
+
+type (
+	// EraserOption configures a [TenantEraser].
+	EraserOption interface {
+		apply(*eraserOption)
+	}
+)
+
+type (
+	eraserOption struct {
+		parallelism int
+		batchSize   int
+	}
+)
+
+type eraseParallelismOption int
+
+func (value eraseParallelismOption) apply(o *eraserOption) {
+	if value > 0 {
+		o.parallelism = int(value)
+	}
+}
+
+// WithEraseParallelism sets the number of collections that are erased at the same time, the default is 4.
+func WithEraseParallelism(n int) EraserOption {
+	return eraseParallelismOption(n)
+}
+
+type eraseBatchSizeOption int
+
+func (value eraseBatchSizeOption) apply(o *eraserOption) {
+	if value > 0 {
+		o.batchSize = int(value)
+	}
+}
+
+// WithEraseBatchSize sets the number of documents that are deleted per batch, the default is 1000.
+func WithEraseBatchSize(n int) EraserOption {
+	return eraseBatchSizeOption(n)
+}
@@ -0,0 +1,22 @@
+//go:build go1.23
+
+package datastore
+
+import (
+	"context"
+	"iter"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *RoutedRepository[T]) FindIter(ctx context.Context, filter bson.M, opts ...*options.FindOptions) iter.Seq2[T, error] {
+	repo, companyID, err := r.route(ctx, "FindIter")
+	if err != nil {
+		return func(yield func(T, error) bool) {
+			var zero T
+			yield(zero, err)
+		}
+	}
+	return repo.FindIter(ctx, scoped(filter, companyID), opts...)
+}
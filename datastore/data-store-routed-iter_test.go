@@ -0,0 +1,33 @@
+//go:build go1.23
+
+package datastore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestRoutedRepositoryFindIterWithoutTenant(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+	store := &datastore.DataStore{Client: client, Database: client.Database("testdb")}
+	repo := datastore.NewRoutedRepository[*Invoice](store, "invoices", tenantResolver(map[primitive.ObjectID]string{}))
+
+	count := 0
+	for _, err := range repo.FindIter(ctx, bson.M{}) {
+		assert.ErrorIs(t, err, datastore.ErrUnknownTenant)
+		count++
+	}
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,824 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// companyIDField is the field RoutedRepository scopes all operations by.
+const companyIDField = "companyID"
+
+type (
+	// TenantResolver returns the company of the tenant of ctx, and the name of the database that stores its data.
+	// It should return an error wrapping [ErrUnknownTenant] for contexts without a known tenant.
+	TenantResolver func(ctx context.Context) (companyID primitive.ObjectID, dbName string, err error)
+
+	// RoutedRepository is a repository for a collection that exists in the database of every tenant,
+	// e.g. if the largest customers get their own database while the others share a common one.
+	//
+	// Every operation resolves the tenant from its context with the [TenantResolver], and runs on the collection of
+	// the database of the tenant. The handles of the collections are cached per database. On top of that,
+	// all operations are scoped to the companyID of the tenant:
+	//   - filters are combined with the companyID, with [mongodb.MergeFilter]
+	//   - aggregations and change streams start with a $match on the companyID
+	//   - documents to insert, replace or upsert must contain the companyID of the tenant, if they store one
+	//   - updates may only set the companyID to the one of the tenant, updates that unset, rename or otherwise
+	//     change it are rejected
+	//   - UpsertManyByKey also matches on the companyID
+	//
+	// Aggregations with $lookup, $unionWith or $graphLookup are rejected, since they read other collections unscoped.
+	//
+	// Drop, Stats, Verify and the index operations act on the whole collection of the database of the tenant,
+	// including the data of other tenants that share the database.
+	RoutedRepository[T mongodb.Document[T]] struct {
+		dataStore  *DataStore
+		collection string
+		resolve    TenantResolver
+		opts       []mongodb.RepositoryOption
+		session    mongo.Session
+		routes     *routes[T]
+	}
+
+	// routes caches the collection and repository of every database, shared by the session-bound copies of a RoutedRepository.
+	routes[T mongodb.Document[T]] struct {
+		mu   sync.Mutex
+		byDB map[string]route[T]
+	}
+
+	route[T mongodb.Document[T]] struct {
+		collection *mongo.Collection
+		repo       mongodb.RepositoryI[T]
+	}
+)
+
+var _ mongodb.RepositoryI[*erasedDocument] = (*RoutedRepository[*erasedDocument])(nil)
+
+// NewRoutedRepository creates a [RoutedRepository] for the collection with the given name in the databases of the
+// tenants that resolve returns. The options are applied to the repository of every database.
+//
+// Like the repositories of [RepositoryFor], its operations are tracked for [DataStore.Shutdown].
+func NewRoutedRepository[T mongodb.Document[T]](dataStore *DataStore, collection string, resolve TenantResolver, opts ...mongodb.RepositoryOption) *RoutedRepository[T] {
+	return &RoutedRepository[T]{
+		dataStore:  dataStore,
+		collection: collection,
+		resolve:    resolve,
+		opts:       opts,
+		routes:     &routes[T]{byDB: map[string]route[T]{}},
+	}
+}
+
+// TenantCollection returns the handle of the collection in the database of the tenant of ctx.
+// Handles are cached, so repeated calls for the same database return the same *mongo.Collection.
+func (r *RoutedRepository[T]) TenantCollection(ctx context.Context) (*mongo.Collection, error) {
+	_, dbName, err := r.tenant(ctx, "TenantCollection")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.routeOf(dbName).collection, nil
+}
+
+// tenant resolves the tenant of ctx, and rejects tenants without companyID or database.
+func (r *RoutedRepository[T]) tenant(ctx context.Context, name string) (primitive.ObjectID, string, error) {
+	companyID, dbName, err := r.resolve(ctx)
+	if err != nil {
+		return companyID, dbName, fmt.Errorf("%v: %w", "datastore.RoutedRepository."+name, err)
+	}
+	if companyID.IsZero() || dbName == "" {
+		return companyID, dbName, fmt.Errorf("%v: %w", "datastore.RoutedRepository."+name, ErrUnknownTenant)
+	}
+
+	return companyID, dbName, nil
+}
+
+func (r *RoutedRepository[T]) routeOf(dbName string) route[T] {
+	r.routes.mu.Lock()
+	defer r.routes.mu.Unlock()
+
+	if rt, ok := r.routes.byDB[dbName]; ok {
+		return rt
+	}
+
+	col := r.dataStore.DatabaseFor(dbName).Collection(r.collection)
+	opts := r.dataStore.repositoryOptions(append(r.opts[:len(r.opts):len(r.opts)], mongodb.WithHook(operationTracker{store: r.dataStore}))...)
+	rt := route[T]{collection: col, repo: mongodb.NewRepository[T](col, opts...)}
+	r.routes.byDB[dbName] = rt
+
+	return rt
+}
+
+// route returns the repository of the database of the tenant of ctx, bound to the session of r, and the companyID of the tenant.
+func (r *RoutedRepository[T]) route(ctx context.Context, name string) (mongodb.Forwarder[T], primitive.ObjectID, error) {
+	companyID, dbName, err := r.tenant(ctx, name)
+	if err != nil {
+		return mongodb.Forwarder[T]{}, companyID, err
+	}
+
+	repo := r.routeOf(dbName).repo
+	if r.session != nil {
+		return mongodb.Forwarder[T]{RepositoryI: mongodb.WithSession(repo, r.session)}, companyID, nil
+	}
+	return mongodb.Forwarder[T]{RepositoryI: repo}, companyID, nil
+}
+
+// scoped returns a copy of filter that only matches documents of the company.
+func scoped(filter bson.M, companyID primitive.ObjectID) bson.M {
+	// MergeFilter never fails.
+	res, _ := mongodb.MergeBSON(filter, mongodb.CompanyIDFilter(companyID), mongodb.MergeFilter)
+	return res
+}
+
+// scopedWhere is like scoped, but keeps the key order of ordered filters.
+func scopedWhere(filter mongodb.Filter, companyID primitive.ObjectID) mongodb.Filter {
+	if !filter.IsOrdered() {
+		return mongodb.FromM(scoped(filter.M(), companyID))
+	}
+
+	d := filter.Document().(bson.D)
+	for _, e := range d {
+		if e.Key == companyIDField {
+			return mongodb.FromD(bson.D{{Key: "$and", Value: bson.A{d, mongodb.CompanyIDFilter(companyID)}}})
+		}
+	}
+	return mongodb.FromD(append(d[:len(d):len(d)], bson.E{Key: companyIDField, Value: companyID}))
+}
+
+// scopedAny is like scoped, for the filters of write models, which may be of any type.
+func scopedAny(filter interface{}, companyID primitive.ObjectID) interface{} {
+	switch f := filter.(type) {
+	case nil:
+		return mongodb.CompanyIDFilter(companyID)
+	case bson.M:
+		return scoped(f, companyID)
+	case bson.D:
+		return scopedWhere(mongodb.FromD(f), companyID).Document()
+	default:
+		return bson.D{{Key: "$and", Value: bson.A{filter, mongodb.CompanyIDFilter(companyID)}}}
+	}
+}
+
+// checkCompany returns an error wrapping [ErrTenantMismatch] if doc stores a companyID other than the one of the tenant.
+func checkCompany(registry *bsoncodec.Registry, doc interface{}, companyID primitive.ObjectID) error {
+	data, err := bson.MarshalWithRegistry(registry, doc)
+	if err != nil {
+		return err
+	}
+
+	value, err := bson.Raw(data).LookupErr(companyIDField)
+	if err != nil {
+		// The document type stores no companyID.
+		return nil
+	}
+	if id, ok := value.ObjectIDOK(); !ok || id != companyID {
+		return fmt.Errorf("%w: document has companyID %v, tenant has %v", ErrTenantMismatch, value, companyID.Hex())
+	}
+
+	return nil
+}
+
+func checkCompanies[T any](registry *bsoncodec.Registry, docs []T, companyID primitive.ObjectID) error {
+	for i, doc := range docs {
+		if err := checkCompany(registry, doc, companyID); err != nil {
+			return fmt.Errorf("document %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// containsString reports whether values contains value.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// touchesCompanyID reports whether the update of field changes the companyID.
+func touchesCompanyID(field string) bool {
+	return field == companyIDField || strings.HasPrefix(field, companyIDField+".")
+}
+
+// checkSet returns an error wrapping [ErrTenantMismatch] if the fields of a $set, or of a $set stage of a pipeline,
+// set the companyID to anything but the one of the tenant.
+func checkSet(fields bson.Raw, companyID primitive.ObjectID) error {
+	elements, err := fields.Elements()
+	if err != nil {
+		return err
+	}
+	for _, e := range elements {
+		if !touchesCompanyID(e.Key()) {
+			continue
+		}
+		if id, ok := e.Value().ObjectIDOK(); !ok || e.Key() != companyIDField || id != companyID {
+			return fmt.Errorf("%w: update sets %v to %v, tenant has %v", ErrTenantMismatch, e.Key(), e.Value(), companyID.Hex())
+		}
+	}
+	return nil
+}
+
+// checkFields returns an error wrapping [ErrTenantMismatch] if the update operator op changes the companyID.
+func checkFields(op string, fields bson.Raw, companyID primitive.ObjectID) error {
+	if op == "$set" || op == "$setOnInsert" {
+		return checkSet(fields, companyID)
+	}
+
+	elements, err := fields.Elements()
+	if err != nil {
+		return err
+	}
+	for _, e := range elements {
+		target, _ := e.Value().StringValueOK()
+		if touchesCompanyID(e.Key()) || (op == "$rename" && touchesCompanyID(target)) {
+			return fmt.Errorf("%w: update %v changes %v", ErrTenantMismatch, op, companyIDField)
+		}
+	}
+	return nil
+}
+
+// checkUpdate returns an error wrapping [ErrTenantMismatch] if update, a document of update operators or a pipeline,
+// can change the companyID. Pipelines with $project, $replaceRoot or $replaceWith stages are always rejected,
+// since they can drop or replace the companyID.
+func checkUpdate(registry *bsoncodec.Registry, update interface{}, companyID primitive.ObjectID) error {
+	t, data, err := bson.MarshalValueWithRegistry(registry, update)
+	if err != nil {
+		return err
+	}
+
+	switch t {
+	case bsontype.EmbeddedDocument:
+		elements, err := bson.Raw(data).Elements()
+		if err != nil {
+			return err
+		}
+		for _, e := range elements {
+			fields, ok := e.Value().DocumentOK()
+			if !ok || !strings.HasPrefix(e.Key(), "$") {
+				return fmt.Errorf("%w: update %v is not an update operator", ErrTenantMismatch, e.Key())
+			}
+			if err := checkFields(e.Key(), fields, companyID); err != nil {
+				return err
+			}
+		}
+		return nil
+	case bsontype.Array:
+		stages, err := bson.Raw(data).Values()
+		if err != nil {
+			return err
+		}
+		for _, stage := range stages {
+			if err := checkUpdateStage(stage, companyID); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported update of type %v", t)
+	}
+}
+
+func checkUpdateStage(stage bson.RawValue, companyID primitive.ObjectID) error {
+	doc, ok := stage.DocumentOK()
+	if !ok {
+		return fmt.Errorf("pipeline stage of type %v", stage.Type)
+	}
+	elements, err := doc.Elements()
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elements {
+		switch e.Key() {
+		case "$set", "$addFields":
+			fields, ok := e.Value().DocumentOK()
+			if !ok {
+				return fmt.Errorf("%v stage of type %v", e.Key(), e.Value().Type)
+			}
+			if err := checkSet(fields, companyID); err != nil {
+				return err
+			}
+		case "$unset":
+			fields := []bson.RawValue{e.Value()}
+			if arr, ok := e.Value().ArrayOK(); ok {
+				if fields, err = arr.Values(); err != nil {
+					return err
+				}
+			}
+			for _, field := range fields {
+				if name, _ := field.StringValueOK(); touchesCompanyID(name) {
+					return fmt.Errorf("%w: update $unset changes %v", ErrTenantMismatch, companyIDField)
+				}
+			}
+		default:
+			return fmt.Errorf("%w: update stage %v can change %v", ErrTenantMismatch, e.Key(), companyIDField)
+		}
+	}
+	return nil
+}
+
+// checkAggregate returns an error wrapping [ErrTenantMismatch] if pipeline reads other collections,
+// also within the sub-pipelines of $facet.
+func checkAggregate(pipeline []bson.D) error {
+	for _, stage := range pipeline {
+		for _, e := range stage {
+			switch e.Key {
+			case "$lookup", "$unionWith", "$graphLookup":
+				return fmt.Errorf("%w: %v stage reads unscoped documents", ErrTenantMismatch, e.Key)
+			case "$facet":
+				facets, err := bson.Marshal(e.Value)
+				if err != nil {
+					return err
+				}
+				var subPipelines map[string][]bson.D
+				if err := bson.Unmarshal(facets, &subPipelines); err != nil {
+					return err
+				}
+				for _, sub := range subPipelines {
+					if err := checkAggregate(sub); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Registry returns the registry of the DataStore, see [WithEncryptedFields].
+func (r *RoutedRepository[T]) Registry() *bsoncodec.Registry {
+	return r.dataStore.registryOrDefault()
+}
+
+// Returns a copy of the repository whose operations all run within the given session.
+func (r *RoutedRepository[T]) WithSession(sess mongo.Session) mongodb.RepositoryI[T] {
+	clone := *r
+	clone.session = sess
+	return &clone
+}
+
+func (r *RoutedRepository[T]) FindOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (res T, err error) {
+	repo, companyID, err := r.route(ctx, "FindOne")
+	if err != nil {
+		return res, err
+	}
+	return repo.FindOne(ctx, scoped(filter, companyID), opts...)
+}
+
+// Finds the document of the company with the given _id, see [mongodb.Repository.GetByID].
+func (r *RoutedRepository[T]) GetByID(ctx context.Context, id primitive.ObjectID, projection ...string) (res T, err error) {
+	repo, companyID, err := r.route(ctx, "GetByID")
+	if err != nil {
+		return res, err
+	}
+
+	var opts []*options.FindOneOptions
+	if len(projection) > 0 {
+		fields := make(bson.D, len(projection))
+		for i, field := range projection {
+			fields[i] = bson.E{Key: field, Value: 1}
+		}
+		opts = append(opts, options.FindOne().SetProjection(fields))
+	}
+
+	res, err = repo.FindOne(ctx, bson.M{"_id": id, companyIDField: companyID}, opts...)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return res, fmt.Errorf("%v: %v: %w: %w", "datastore.RoutedRepository.GetByID", id.Hex(), mongodb.ErrNotFound, err)
+	}
+	return res, err
+}
+
+func (r *RoutedRepository[T]) FindMany(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]T, error) {
+	repo, companyID, err := r.route(ctx, "FindMany")
+	if err != nil {
+		return nil, err
+	}
+	return repo.FindMany(ctx, scoped(filter, companyID), opts...)
+}
+
+func (r *RoutedRepository[T]) FindManyN(ctx context.Context, filter bson.M, expectedCount int, opts ...*options.FindOptions) ([]T, error) {
+	repo, companyID, err := r.route(ctx, "FindManyN")
+	if err != nil {
+		return nil, err
+	}
+	return repo.FindManyN(ctx, scoped(filter, companyID), expectedCount, opts...)
+}
+
+func (r *RoutedRepository[T]) FindManyRaw(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]bson.Raw, error) {
+	repo, companyID, err := r.route(ctx, "FindManyRaw")
+	if err != nil {
+		return nil, err
+	}
+	return repo.FindManyRaw(ctx, scoped(filter, companyID), opts...)
+}
+
+func (r *RoutedRepository[T]) FindCursor(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	repo, companyID, err := r.route(ctx, "FindCursor")
+	if err != nil {
+		return nil, err
+	}
+	return repo.FindCursor(ctx, scoped(filter, companyID), opts...)
+}
+
+func (r *RoutedRepository[T]) FindManyParallel(ctx context.Context, filter bson.M, parallelism int, fn func([]T) error) error {
+	repo, companyID, err := r.route(ctx, "FindManyParallel")
+	if err != nil {
+		return err
+	}
+	return repo.FindManyParallel(ctx, scoped(filter, companyID), parallelism, fn)
+}
+
+func (r *RoutedRepository[T]) InsertOne(ctx context.Context, doc T, opts ...*options.InsertOneOptions) (T, error) {
+	repo, companyID, err := r.route(ctx, "InsertOne")
+	if err != nil {
+		return doc, err
+	}
+	if err := checkCompany(r.Registry(), doc, companyID); err != nil {
+		return doc, fmt.Errorf("%v: %w", "datastore.RoutedRepository.InsertOne", err)
+	}
+	return repo.InsertOne(ctx, doc, opts...)
+}
+
+func (r *RoutedRepository[T]) InsertMany(ctx context.Context, docs []T, opts ...*options.InsertManyOptions) ([]T, error) {
+	repo, companyID, err := r.route(ctx, "InsertMany")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCompanies(r.Registry(), docs, companyID); err != nil {
+		return nil, fmt.Errorf("%v: %w", "datastore.RoutedRepository.InsertMany", err)
+	}
+	return repo.InsertMany(ctx, docs, opts...)
+}
+
+func (r *RoutedRepository[T]) InsertManyResult(ctx context.Context, docs []T, opts ...*options.InsertManyOptions) ([]T, *mongo.InsertManyResult, error) {
+	repo, companyID, err := r.route(ctx, "InsertManyResult")
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkCompanies(r.Registry(), docs, companyID); err != nil {
+		return nil, nil, fmt.Errorf("%v: %w", "datastore.RoutedRepository.InsertManyResult", err)
+	}
+	return repo.InsertManyResult(ctx, docs, opts...)
+}
+
+func (r *RoutedRepository[T]) UpdateOne(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	repo, companyID, err := r.route(ctx, "UpdateOne")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUpdate(r.Registry(), bson.M{"$set": data}, companyID); err != nil {
+		return nil, fmt.Errorf("%v: %w", "datastore.RoutedRepository.UpdateOne", err)
+	}
+	return repo.UpdateOne(ctx, scoped(filter, companyID), data, opts...)
+}
+
+func (r *RoutedRepository[T]) UpdateMany(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) error {
+	repo, companyID, err := r.route(ctx, "UpdateMany")
+	if err != nil {
+		return err
+	}
+	if err := checkUpdate(r.Registry(), bson.M{"$set": data}, companyID); err != nil {
+		return fmt.Errorf("%v: %w", "datastore.RoutedRepository.UpdateMany", err)
+	}
+	return repo.UpdateMany(ctx, scoped(filter, companyID), data, opts...)
+}
+
+func (r *RoutedRepository[T]) UpdateManyResult(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	repo, companyID, err := r.route(ctx, "UpdateManyResult")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUpdate(r.Registry(), bson.M{"$set": data}, companyID); err != nil {
+		return nil, fmt.Errorf("%v: %w", "datastore.RoutedRepository.UpdateManyResult", err)
+	}
+	return repo.UpdateManyResult(ctx, scoped(filter, companyID), data, opts...)
+}
+
+func (r *RoutedRepository[T]) ReplaceOne(ctx context.Context, filter bson.M, doc T, opts ...*options.ReplaceOptions) (T, error) {
+	repo, companyID, err := r.route(ctx, "ReplaceOne")
+	if err != nil {
+		return doc, err
+	}
+	if err := checkCompany(r.Registry(), doc, companyID); err != nil {
+		return doc, fmt.Errorf("%v: %w", "datastore.RoutedRepository.ReplaceOne", err)
+	}
+	return repo.ReplaceOne(ctx, scoped(filter, companyID), doc, opts...)
+}
+
+func (r *RoutedRepository[T]) FindOneOrCreate(ctx context.Context, filter bson.M, defaultDoc T, opts ...*options.FindOneAndUpdateOptions) (T, bool, error) {
+	repo, companyID, err := r.route(ctx, "FindOneOrCreate")
+	if err != nil {
+		return defaultDoc, false, err
+	}
+	if err := checkCompany(r.Registry(), defaultDoc, companyID); err != nil {
+		return defaultDoc, false, fmt.Errorf("%v: %w", "datastore.RoutedRepository.FindOneOrCreate", err)
+	}
+	return repo.FindOneOrCreate(ctx, scoped(filter, companyID), defaultDoc, opts...)
+}
+
+// Like [mongodb.Repository.UpsertManyByKey], but the companyID is part of the key of every document.
+func (r *RoutedRepository[T]) UpsertManyByKey(ctx context.Context, docs []T, keyFields []string, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	repo, companyID, err := r.route(ctx, "UpsertManyByKey")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCompanies(r.Registry(), docs, companyID); err != nil {
+		return nil, fmt.Errorf("%v: %w", "datastore.RoutedRepository.UpsertManyByKey", err)
+	}
+
+	keys := keyFields[:len(keyFields):len(keyFields)]
+	if !containsString(keys, companyIDField) {
+		keys = append(keys, companyIDField)
+	}
+	return repo.UpsertManyByKey(ctx, docs, keys, opts...)
+}
+
+func (r *RoutedRepository[T]) FindOneWhere(ctx context.Context, filter mongodb.Filter, opts ...*options.FindOneOptions) (res T, err error) {
+	repo, companyID, err := r.route(ctx, "FindOneWhere")
+	if err != nil {
+		return res, err
+	}
+	return repo.FindOneWhere(ctx, scopedWhere(filter, companyID), opts...)
+}
+
+func (r *RoutedRepository[T]) FindManyWhere(ctx context.Context, filter mongodb.Filter, opts ...*options.FindOptions) ([]T, error) {
+	repo, companyID, err := r.route(ctx, "FindManyWhere")
+	if err != nil {
+		return nil, err
+	}
+	return repo.FindManyWhere(ctx, scopedWhere(filter, companyID), opts...)
+}
+
+func (r *RoutedRepository[T]) UpdateOneWhere(ctx context.Context, filter mongodb.Filter, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	repo, companyID, err := r.route(ctx, "UpdateOneWhere")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUpdate(r.Registry(), bson.M{"$set": data}, companyID); err != nil {
+		return nil, fmt.Errorf("%v: %w", "datastore.RoutedRepository.UpdateOneWhere", err)
+	}
+	return repo.UpdateOneWhere(ctx, scopedWhere(filter, companyID), data, opts...)
+}
+
+func (r *RoutedRepository[T]) UpdateManyWhere(ctx context.Context, filter mongodb.Filter, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	repo, companyID, err := r.route(ctx, "UpdateManyWhere")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUpdate(r.Registry(), bson.M{"$set": data}, companyID); err != nil {
+		return nil, fmt.Errorf("%v: %w", "datastore.RoutedRepository.UpdateManyWhere", err)
+	}
+	return repo.UpdateManyWhere(ctx, scopedWhere(filter, companyID), data, opts...)
+}
+
+func (r *RoutedRepository[T]) DeleteOneWhere(ctx context.Context, filter mongodb.Filter, opts ...*options.DeleteOptions) error {
+	repo, companyID, err := r.route(ctx, "DeleteOneWhere")
+	if err != nil {
+		return err
+	}
+	return repo.DeleteOneWhere(ctx, scopedWhere(filter, companyID), opts...)
+}
+
+func (r *RoutedRepository[T]) DeleteManyWhere(ctx context.Context, filter mongodb.Filter, opts ...*options.DeleteOptions) (int, error) {
+	repo, companyID, err := r.route(ctx, "DeleteManyWhere")
+	if err != nil {
+		return 0, err
+	}
+	return repo.DeleteManyWhere(ctx, scopedWhere(filter, companyID), opts...)
+}
+
+func (r *RoutedRepository[T]) CountWhere(ctx context.Context, filter mongodb.Filter, opts ...*options.CountOptions) (int, error) {
+	repo, companyID, err := r.route(ctx, "CountWhere")
+	if err != nil {
+		return 0, err
+	}
+	return repo.CountWhere(ctx, scopedWhere(filter, companyID), opts...)
+}
+
+func (r *RoutedRepository[T]) DeleteOne(ctx context.Context, filter bson.M, opts ...*options.DeleteOptions) error {
+	repo, companyID, err := r.route(ctx, "DeleteOne")
+	if err != nil {
+		return err
+	}
+	return repo.DeleteOne(ctx, scoped(filter, companyID), opts...)
+}
+
+func (r *RoutedRepository[T]) DeleteMany(ctx context.Context, filter bson.M, opts ...*options.DeleteOptions) (int, error) {
+	repo, companyID, err := r.route(ctx, "DeleteMany")
+	if err != nil {
+		return 0, err
+	}
+	return repo.DeleteMany(ctx, scoped(filter, companyID), opts...)
+}
+
+// Deletes the documents of the company with the given _ids, see [mongodb.Repository.DeleteManyByIDs].
+func (r *RoutedRepository[T]) DeleteManyByIDs(ctx context.Context, ids []primitive.ObjectID, chunkSize int) (int, error) {
+	repo, companyID, err := r.route(ctx, "DeleteManyByIDs")
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	err = mongodb.ChunkedIn(ids, chunkSize, func(filterValue primitive.M) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, err := repo.DeleteMany(ctx, bson.M{"_id": filterValue, companyIDField: companyID})
+		deleted += n
+		return err
+	})
+	if err != nil {
+		return deleted, fmt.Errorf("%v: %w", "datastore.RoutedRepository.DeleteManyByIDs", err)
+	}
+
+	return deleted, nil
+}
+
+func (r *RoutedRepository[T]) DeleteManyAudited(ctx context.Context, filter bson.M, batchSize int, onBatch func(deletedIDs []primitive.ObjectID) error) (int, error) {
+	repo, companyID, err := r.route(ctx, "DeleteManyAudited")
+	if err != nil {
+		return 0, err
+	}
+	return repo.DeleteManyAudited(ctx, scoped(filter, companyID), batchSize, onBatch)
+}
+
+// Like [mongodb.Repository.BulkWrite], but the filters of the models are scoped to the company,
+// the documents of insert and replace models must contain its companyID, and update models must not change it.
+func (r *RoutedRepository[T]) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	repo, companyID, err := r.route(ctx, "BulkWrite")
+	if err != nil {
+		return nil, err
+	}
+
+	scopedModels := make([]mongo.WriteModel, len(models))
+	for i, model := range models {
+		scopedModels[i], err = scopedModel(r.Registry(), model, companyID)
+		if err != nil {
+			return nil, fmt.Errorf("%v: model %d: %w", "datastore.RoutedRepository.BulkWrite", i, err)
+		}
+	}
+	return repo.BulkWrite(ctx, scopedModels, opts...)
+}
+
+// scopedModel returns a copy of model that only writes documents of the company.
+func scopedModel(registry *bsoncodec.Registry, model mongo.WriteModel, companyID primitive.ObjectID) (mongo.WriteModel, error) {
+	switch m := model.(type) {
+	case *mongo.InsertOneModel:
+		return m, checkCompany(registry, m.Document, companyID)
+	case *mongo.ReplaceOneModel:
+		if err := checkCompany(registry, m.Replacement, companyID); err != nil {
+			return nil, err
+		}
+		res := *m
+		res.Filter = scopedAny(m.Filter, companyID)
+		return &res, nil
+	case *mongo.UpdateOneModel:
+		if err := checkUpdate(registry, m.Update, companyID); err != nil {
+			return nil, err
+		}
+		res := *m
+		res.Filter = scopedAny(m.Filter, companyID)
+		return &res, nil
+	case *mongo.UpdateManyModel:
+		if err := checkUpdate(registry, m.Update, companyID); err != nil {
+			return nil, err
+		}
+		res := *m
+		res.Filter = scopedAny(m.Filter, companyID)
+		return &res, nil
+	case *mongo.DeleteOneModel:
+		res := *m
+		res.Filter = scopedAny(m.Filter, companyID)
+		return &res, nil
+	case *mongo.DeleteManyModel:
+		res := *m
+		res.Filter = scopedAny(m.Filter, companyID)
+		return &res, nil
+	default:
+		return nil, fmt.Errorf("unsupported write model %T", model)
+	}
+}
+
+// Like [mongodb.Repository.Aggregate], but the pipeline starts with a $match on the companyID.
+//
+// Only the documents of the collection are scoped, so pipelines with $lookup, $unionWith or $graphLookup stages,
+// including those within $facet, are rejected with an error wrapping [ErrTenantMismatch].
+// Join other collections in the application instead.
+func (r *RoutedRepository[T]) Aggregate(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	repo, companyID, err := r.route(ctx, "Aggregate")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAggregate(pipeline); err != nil {
+		return nil, fmt.Errorf("%v: %w", "datastore.RoutedRepository.Aggregate", err)
+	}
+
+	stages := append(mongo.Pipeline{{{Key: "$match", Value: mongodb.CompanyIDFilter(companyID)}}}, pipeline...)
+	return repo.Aggregate(ctx, stages, opts...)
+}
+
+// Like [mongodb.Repository.Watch], but only reports changes of documents of the company,
+// which excludes deletes, since they have no fullDocument.
+func (r *RoutedRepository[T]) Watch(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error) {
+	repo, companyID, err := r.route(ctx, "Watch")
+	if err != nil {
+		return nil, err
+	}
+
+	stages := append(mongo.Pipeline{{{Key: "$match", Value: bson.M{"fullDocument." + companyIDField: companyID}}}}, pipeline...)
+	return repo.Watch(ctx, stages, opts...)
+}
+
+func (r *RoutedRepository[T]) CountDocuments(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int, error) {
+	repo, companyID, err := r.route(ctx, "CountDocuments")
+	if err != nil {
+		return 0, err
+	}
+	return repo.CountDocuments(ctx, scoped(filter, companyID), opts...)
+}
+
+func (r *RoutedRepository[T]) Distinct(ctx context.Context, path mongodb.FieldPath, filter bson.M, opts ...*options.DistinctOptions) ([]interface{}, error) {
+	repo, companyID, err := r.route(ctx, "Distinct")
+	if err != nil {
+		return nil, err
+	}
+	return repo.Distinct(ctx, path, scoped(filter, companyID), opts...)
+}
+
+// Drops the collection of the database of the tenant, including the data of other tenants that share it.
+func (r *RoutedRepository[T]) Drop(ctx context.Context) error {
+	repo, _, err := r.route(ctx, "Drop")
+	if err != nil {
+		return err
+	}
+	return repo.Drop(ctx)
+}
+
+func (r *RoutedRepository[T]) Stats(ctx context.Context) (mongodb.CollectionStats, error) {
+	repo, _, err := r.route(ctx, "Stats")
+	if err != nil {
+		return mongodb.CollectionStats{}, err
+	}
+	return repo.Stats(ctx)
+}
+
+func (r *RoutedRepository[T]) Verify(ctx context.Context) error {
+	repo, _, err := r.route(ctx, "Verify")
+	if err != nil {
+		return err
+	}
+	return repo.Verify(ctx)
+}
+
+func (r *RoutedRepository[T]) CreateIndex(ctx context.Context, keys bson.D, opts ...*options.IndexOptions) (string, error) {
+	repo, _, err := r.route(ctx, "CreateIndex")
+	if err != nil {
+		return "", err
+	}
+	return repo.CreateIndex(ctx, keys, opts...)
+}
+
+func (r *RoutedRepository[T]) CreateIndexes(ctx context.Context, models []mongo.IndexModel) ([]string, error) {
+	repo, _, err := r.route(ctx, "CreateIndexes")
+	if err != nil {
+		return nil, err
+	}
+	return repo.CreateIndexes(ctx, models)
+}
+
+func (r *RoutedRepository[T]) DropIndex(ctx context.Context, name string) error {
+	repo, _, err := r.route(ctx, "DropIndex")
+	if err != nil {
+		return err
+	}
+	return repo.DropIndex(ctx, name)
+}
+
+func (r *RoutedRepository[T]) ListIndexes(ctx context.Context) ([]mongodb.IndexInfo, error) {
+	repo, _, err := r.route(ctx, "ListIndexes")
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListIndexes(ctx)
+}
+
+func (r *RoutedRepository[T]) SetIndexExpireAfter(ctx context.Context, name string, expireAfter time.Duration) error {
+	repo, _, err := r.route(ctx, "SetIndexExpireAfter")
+	if err != nil {
+		return err
+	}
+	return repo.SetIndexExpireAfter(ctx, name, expireAfter)
+}
@@ -0,0 +1,173 @@
+package datastore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	tenantKey struct{}
+
+	Invoice struct {
+		mongodb.BaseModel `bson:",inline"`
+		CompanyID         primitive.ObjectID `bson:"companyID"`
+		Number            string             `bson:"number"`
+	}
+)
+
+// tenantResolver routes the companies of tenants to their databases, the company is taken from the context.
+func tenantResolver(tenants map[primitive.ObjectID]string) datastore.TenantResolver {
+	return func(ctx context.Context) (primitive.ObjectID, string, error) {
+		companyID, _ := ctx.Value(tenantKey{}).(primitive.ObjectID)
+		dbName, ok := tenants[companyID]
+		if !ok {
+			return companyID, "", datastore.ErrUnknownTenant
+		}
+		return companyID, dbName, nil
+	}
+}
+
+func TestRoutedRepositoryWithoutServer(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+	store := &datastore.DataStore{Client: client, Database: client.Database("testdb")}
+
+	companyA, companyB, companyC := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	repo := datastore.NewRoutedRepository[*Invoice](store, "invoices", tenantResolver(map[primitive.ObjectID]string{
+		companyA: "testdb_tenant_a",
+		companyB: "testdb_shared",
+		companyC: "testdb_shared",
+	}))
+	ctxA := context.WithValue(ctx, tenantKey{}, companyA)
+	ctxB := context.WithValue(ctx, tenantKey{}, companyB)
+	ctxC := context.WithValue(ctx, tenantKey{}, companyC)
+
+	colA, err := repo.TenantCollection(ctxA)
+	assert.NoError(t, err)
+	assert.Equal(t, "testdb_tenant_a", colA.Database().Name())
+
+	colB, err := repo.TenantCollection(ctxB)
+	assert.NoError(t, err)
+	assert.Equal(t, "testdb_shared", colB.Database().Name())
+
+	again, err := repo.TenantCollection(ctxA)
+	assert.NoError(t, err)
+	assert.Same(t, colA, again, "the handle is cached")
+	colC, err := repo.TenantCollection(ctxC)
+	assert.NoError(t, err)
+	assert.Same(t, colB, colC, "tenants of the same database share the handle")
+
+	_, err = repo.FindOne(ctx, bson.M{})
+	assert.ErrorIs(t, err, datastore.ErrUnknownTenant)
+	_, err = repo.InsertOne(context.WithValue(ctx, tenantKey{}, primitive.NewObjectID()), &Invoice{})
+	assert.ErrorIs(t, err, datastore.ErrUnknownTenant)
+
+	_, err = repo.InsertOne(ctxA, &Invoice{CompanyID: companyB, Number: "1"})
+	assert.ErrorIs(t, err, datastore.ErrTenantMismatch)
+	_, err = repo.InsertMany(ctxA, []*Invoice{{CompanyID: companyA}, {Number: "2"}})
+	assert.ErrorIs(t, err, datastore.ErrTenantMismatch)
+
+	_, err = repo.UpdateOne(ctxA, bson.M{}, bson.M{"companyID": companyB})
+	assert.ErrorIs(t, err, datastore.ErrTenantMismatch)
+	_, err = repo.UpdateManyWhere(ctxA, mongodb.FromM(bson.M{}), bson.M{"companyID.x": companyA})
+	assert.ErrorIs(t, err, datastore.ErrTenantMismatch)
+	assert.ErrorIs(t, repo.UpdateMany(ctxA, bson.M{}, bson.M{"companyID": "x"}), datastore.ErrTenantMismatch)
+	for name, update := range map[string]interface{}{
+		"$set":         bson.M{"$set": bson.M{"companyID": companyB}},
+		"$setOnInsert": bson.D{{Key: "$setOnInsert", Value: bson.D{{Key: "companyID", Value: companyB}}}},
+		"$unset":       bson.M{"$unset": bson.M{"companyID": ""}},
+		"$rename to":   bson.M{"$rename": bson.M{"number": "companyID"}},
+		"$rename from": bson.M{"$rename": bson.M{"companyID": "owner"}},
+		"replacement":  bson.M{"companyID": companyB},
+		"pipeline":     mongo.Pipeline{{{Key: "$set", Value: bson.M{"companyID": "$owner"}}}},
+		"$replaceWith": bson.A{bson.M{"$replaceWith": bson.M{"number": "1"}}},
+		"$unset stage": bson.A{bson.M{"$unset": bson.A{"number", "companyID"}}},
+	} {
+		_, err = repo.BulkWrite(ctxA, []mongo.WriteModel{mongo.NewUpdateManyModel().SetFilter(bson.M{}).SetUpdate(update)})
+		assert.ErrorIs(t, err, datastore.ErrTenantMismatch, name)
+	}
+
+	_, err = repo.Aggregate(ctxA, mongo.Pipeline{{{Key: "$lookup", Value: bson.M{"from": "invoices", "as": "all", "pipeline": bson.A{}}}}})
+	assert.ErrorIs(t, err, datastore.ErrTenantMismatch)
+	_, err = repo.Aggregate(ctxA, mongo.Pipeline{{{Key: "$facet", Value: bson.M{"all": bson.A{bson.M{"$unionWith": "invoices"}}}}}})
+	assert.ErrorIs(t, err, datastore.ErrTenantMismatch)
+}
+
+func TestRoutedRepository(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDataStore(t)
+
+	companyA, companyB, companyC := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	repo := datastore.NewRoutedRepository[*Invoice](store, "invoices_routed", tenantResolver(map[primitive.ObjectID]string{
+		companyA: "testdb_tenant_a",
+		companyB: "testdb_shared",
+		companyC: "testdb_shared",
+	}))
+	t.Cleanup(func() {
+		store.DatabaseFor("testdb_tenant_a").Collection("invoices_routed").Drop(ctx)
+		store.DatabaseFor("testdb_shared").Collection("invoices_routed").Drop(ctx)
+	})
+	ctxA := context.WithValue(ctx, tenantKey{}, companyA)
+	ctxB := context.WithValue(ctx, tenantKey{}, companyB)
+	ctxC := context.WithValue(ctx, tenantKey{}, companyC)
+
+	for _, tenant := range []struct {
+		ctx       context.Context
+		companyID primitive.ObjectID
+	}{{ctxA, companyA}, {ctxB, companyB}, {ctxC, companyC}} {
+		_, err := repo.InsertMany(tenant.ctx, []*Invoice{{CompanyID: tenant.companyID, Number: "1"}, {CompanyID: tenant.companyID, Number: "2"}})
+		if err != nil {
+			t.Fatalf("Error on inserting invoices: %v", err)
+		}
+	}
+
+	countA, err := store.DatabaseFor("testdb_tenant_a").Collection("invoices_routed").CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), countA)
+	countShared, err := store.DatabaseFor("testdb_shared").Collection("invoices_routed").CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), countShared)
+
+	invoices, err := repo.FindMany(ctxB, bson.M{})
+	assert.NoError(t, err)
+	assert.Len(t, invoices, 2)
+	for _, invoice := range invoices {
+		assert.Equal(t, companyB, invoice.CompanyID)
+	}
+
+	// A filter on the companyID of another tenant of the same database matches nothing.
+	invoices, err = repo.FindMany(ctxB, bson.M{"companyID": companyC})
+	assert.NoError(t, err)
+	assert.Empty(t, invoices)
+
+	n, err := repo.DeleteMany(ctxC, bson.M{"number": "1"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	count, err := repo.CountDocuments(ctxB, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	cur, err := repo.Aggregate(ctxC, mongo.Pipeline{{{Key: "$count", Value: "n"}}})
+	if assert.NoError(t, err) {
+		var res []struct {
+			N int `bson:"n"`
+		}
+		assert.NoError(t, cur.All(ctx, &res))
+		assert.Equal(t, []struct {
+			N int `bson:"n"`
+		}{{N: 1}}, res)
+	}
+}
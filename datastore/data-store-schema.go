@@ -0,0 +1,250 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// ValidationLevel determines how strictly MongoDB applies the validator to existing documents during updates.
+	//
+	// See [https://www.mongodb.com/docs/manual/core/schema-validation/specify-validation-level/]
+	ValidationLevel string
+
+	// ValidationAction determines whether MongoDB rejects invalid documents or only logs a warning.
+	//
+	// See [https://www.mongodb.com/docs/manual/core/schema-validation/handle-invalid-documents/]
+	ValidationAction string
+)
+
+const (
+	ValidationLevelOff      ValidationLevel = "off"
+	ValidationLevelStrict   ValidationLevel = "strict"
+	ValidationLevelModerate ValidationLevel = "moderate"
+
+	ValidationActionError ValidationAction = "error"
+	ValidationActionWarn  ValidationAction = "warn"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	dateTimeType   = reflect.TypeOf(primitive.DateTime(0))
+	objectIDType   = reflect.TypeOf(primitive.ObjectID{})
+	decimal128Type = reflect.TypeOf(primitive.Decimal128{})
+	byteType       = reflect.TypeOf(byte(0))
+
+	valueMarshalerType = reflect.TypeOf((*bsoncodec.ValueMarshaler)(nil)).Elem()
+	marshalerType      = reflect.TypeOf((*bson.Marshaler)(nil)).Elem()
+
+	// primitiveBSONTypes are the BSON types of the types the driver encodes regardless of their Go kind.
+	primitiveBSONTypes = map[reflect.Type]string{
+		reflect.TypeOf(primitive.Binary{}):        "binData",
+		reflect.TypeOf(primitive.Timestamp{}):     "timestamp",
+		reflect.TypeOf(primitive.Regex{}):         "regex",
+		reflect.TypeOf(primitive.JavaScript("")):  "javascript",
+		reflect.TypeOf(primitive.CodeWithScope{}): "javascriptWithScope",
+		reflect.TypeOf(primitive.Symbol("")):      "symbol",
+		reflect.TypeOf(primitive.DBPointer{}):     "dbPointer",
+		reflect.TypeOf(primitive.MinKey{}):        "minKey",
+		reflect.TypeOf(primitive.MaxKey{}):        "maxKey",
+		reflect.TypeOf(primitive.Null{}):          "null",
+		reflect.TypeOf(primitive.Undefined{}):     "undefined",
+		reflect.TypeOf(primitive.D{}):             "object",
+		reflect.TypeOf(bson.Raw{}):                "object",
+	}
+)
+
+// EnsureCollection makes sure that the collection with the given name exists in the default database
+// and validates its documents with a $jsonSchema derived from T, see [JSONSchema].
+//
+// A missing collection is created with the validator. If the collection already exists,
+// its validator is replaced using the collMod command.
+func EnsureCollection[T any](ctx context.Context, store *DataStore, name string, opts ...CollectionOption) error {
+	ops := &collectionOption{}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+
+	schema, err := JSONSchema[T]()
+	if err != nil {
+		return err
+	}
+	validator := bson.M{"$jsonSchema": schema}
+
+	exists, err := store.CollectionExists(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		createOpts := options.CreateCollection().SetValidator(validator)
+		if ops.validationLevel != "" {
+			createOpts.SetValidationLevel(string(ops.validationLevel))
+		}
+		if ops.validationAction != "" {
+			createOpts.SetValidationAction(string(ops.validationAction))
+		}
+
+		return store.Database.CreateCollection(ctx, name, createOpts)
+	}
+
+	cmd := bson.D{
+		{Key: "collMod", Value: name},
+		{Key: "validator", Value: validator},
+	}
+	if ops.validationLevel != "" {
+		cmd = append(cmd, bson.E{Key: "validationLevel", Value: string(ops.validationLevel)})
+	}
+	if ops.validationAction != "" {
+		cmd = append(cmd, bson.E{Key: "validationAction", Value: string(ops.validationAction)})
+	}
+
+	return store.Database.RunCommand(ctx, cmd).Err()
+}
+
+// JSONSchema generates a $jsonSchema document for the struct T.
+//
+// The property names are taken from the bson tags, inline structs are merged into their parent,
+// and nested structs are recursed. Fields that are neither pointers nor tagged with omitempty are required.
+func JSONSchema[T any]() (bson.M, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("JSONSchema: %v is not a struct", t)
+	}
+
+	return structSchema(t, map[reflect.Type]bool{})
+}
+
+func structSchema(t reflect.Type, visiting map[reflect.Type]bool) (bson.M, error) {
+	if visiting[t] {
+		// Recursive types can not be expressed completely, so the recursion stops here.
+		return bson.M{"bsonType": "object"}, nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	properties := bson.M{}
+	required := []string{}
+
+	err := collectProperties(t, visiting, properties, &required)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := bson.M{
+		"bsonType":   "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema, nil
+}
+
+// collectProperties adds the fields of t to properties, with the same struct tag rules as the other helpers, see [mongodb.BSONFields].
+func collectProperties(t reflect.Type, visiting map[reflect.Type]bool, properties bson.M, required *[]string) error {
+	fields, err := mongodb.BSONFields(t)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		fieldSchema, err := typeSchema(field.Field.Type, visiting)
+		if err != nil {
+			return err
+		}
+		properties[field.Name] = fieldSchema
+
+		if field.Field.Type.Kind() != reflect.Pointer && !field.OmitEmpty {
+			*required = append(*required, field.Name)
+		}
+	}
+
+	return nil
+}
+
+func typeSchema(t reflect.Type, visiting map[reflect.Type]bool) (bson.M, error) {
+	if t.Kind() == reflect.Pointer {
+		schema, err := typeSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		if bsonType, ok := schema["bsonType"].(string); ok {
+			schema["bsonType"] = []string{bsonType, "null"}
+		}
+		return schema, nil
+	}
+
+	switch t {
+	case timeType, dateTimeType:
+		return bson.M{"bsonType": "date"}, nil
+	case objectIDType:
+		return bson.M{"bsonType": "objectId"}, nil
+	case decimal128Type:
+		return bson.M{"bsonType": "decimal"}, nil
+	}
+	if bsonType, ok := primitiveBSONTypes[t]; ok {
+		return bson.M{"bsonType": bsonType}, nil
+	}
+
+	// Types that marshal themselves, e.g. mongodb.Optional or the encrypted types, are stored as whatever they return,
+	// which does not depend on their Go kind, so they are not constrained.
+	if t.Implements(valueMarshalerType) || t.Implements(marshalerType) ||
+		reflect.PointerTo(t).Implements(valueMarshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+		return bson.M{}, nil
+	}
+
+	// Byte slices and arrays are stored as binary data, including named types, and nil slices are stored as null.
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem() == byteType {
+		if t.Kind() == reflect.Slice {
+			return bson.M{"bsonType": []string{"binData", "null"}}, nil
+		}
+		return bson.M{"bsonType": "binData"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return bson.M{"bsonType": "string"}, nil
+	case reflect.Bool:
+		return bson.M{"bsonType": "bool"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return bson.M{"bsonType": "number"}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := typeSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		schema := bson.M{"bsonType": "array"}
+		if t.Kind() == reflect.Slice {
+			schema["bsonType"] = []string{"array", "null"}
+		}
+		if len(items) > 0 {
+			schema["items"] = items
+		}
+		return schema, nil
+	case reflect.Map:
+		return bson.M{"bsonType": []string{"object", "null"}}, nil
+	case reflect.Struct:
+		if mongodb.IsSubdocumentType(t) {
+			return structSchema(t, visiting)
+		}
+	}
+
+	// Interfaces, structs with an encoder of their own and other types can hold any value, so they are not constrained.
+	return bson.M{}, nil
+}
@@ -0,0 +1,209 @@
+package datastore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	Address struct {
+		Street string `bson:"street"`
+	}
+
+	Customer struct {
+		mongodb.BaseModel `bson:",inline"`
+		Name              string             `bson:"name"`
+		Age               int                `bson:"age"`
+		Active            bool               `bson:"active"`
+		Tags              []string           `bson:"tags"`
+		Attributes        map[string]string  `bson:"attributes"`
+		Avatar            []byte             `bson:"avatar"`
+		Address           Address            `bson:"address"`
+		Nickname          *string            `bson:"nickname"`
+		CompanyID         primitive.ObjectID `bson:"companyID,omitempty"`
+		Ignored           string             `bson:"-"`
+	}
+
+	Token []byte
+
+	SpecialTypes struct {
+		Binary    primitive.Binary         `bson:"binary"`
+		Timestamp primitive.Timestamp      `bson:"timestamp"`
+		Regex     primitive.Regex          `bson:"regex"`
+		Raw       bson.Raw                 `bson:"raw"`
+		Document  bson.D                   `bson:"document"`
+		Token     Token                    `bson:"token"`
+		Hash      [4]byte                  `bson:"hash"`
+		Nickname  mongodb.Optional[string] `bson:"nickname"`
+		Secret    mongodb.EncryptedString  `bson:"secret"`
+	}
+)
+
+func TestJSONSchema(t *testing.T) {
+	schema, err := datastore.JSONSchema[Customer]()
+	if err != nil {
+		t.Fatalf("Error on generating schema: %v", err)
+	}
+
+	properties := schema["properties"].(bson.M)
+	assert.Equal(t, bson.M{"bsonType": "objectId"}, properties["_id"])
+	assert.Equal(t, bson.M{"bsonType": "date"}, properties["createdAt"])
+	assert.Equal(t, bson.M{"bsonType": "string"}, properties["name"])
+	assert.Equal(t, bson.M{"bsonType": "number"}, properties["age"])
+	assert.Equal(t, bson.M{"bsonType": "bool"}, properties["active"])
+	assert.Equal(t, bson.M{"bsonType": []string{"array", "null"}, "items": bson.M{"bsonType": "string"}}, properties["tags"])
+	assert.Equal(t, bson.M{"bsonType": []string{"object", "null"}}, properties["attributes"])
+	assert.Equal(t, bson.M{"bsonType": []string{"binData", "null"}}, properties["avatar"])
+	assert.Equal(t, bson.M{"bsonType": []string{"string", "null"}}, properties["nickname"])
+	assert.Equal(t, bson.M{
+		"bsonType":   "object",
+		"properties": bson.M{"street": bson.M{"bsonType": "string"}},
+		"required":   []string{"street"},
+	}, properties["address"])
+	assert.NotContains(t, properties, "Ignored")
+
+	assert.ElementsMatch(t, []string{"createdAt", "updatedAt", "name", "age", "active", "tags", "attributes", "avatar", "address"}, schema["required"])
+}
+
+func TestJSONSchemaAcceptsZeroValue(t *testing.T) {
+	schema, err := datastore.JSONSchema[Customer]()
+	if err != nil {
+		t.Fatalf("Error on generating schema: %v", err)
+	}
+	data, err := bson.Marshal(Customer{})
+	if err != nil {
+		t.Fatalf("Error on marshaling customer: %v", err)
+	}
+
+	names := map[bsontype.Type]string{
+		bsontype.String: "string", bsontype.Boolean: "bool", bsontype.Int32: "number", bsontype.Int64: "number",
+		bsontype.Array: "array", bsontype.EmbeddedDocument: "object", bsontype.Binary: "binData",
+		bsontype.DateTime: "date", bsontype.ObjectID: "objectId", bsontype.Null: "null",
+	}
+	properties := schema["properties"].(bson.M)
+	for _, field := range schema["required"].([]string) {
+		value, err := bson.Raw(data).LookupErr(field)
+		if !assert.NoError(t, err, field) {
+			continue
+		}
+		allowed := properties[field].(bson.M)["bsonType"]
+		if types, ok := allowed.([]string); ok {
+			assert.Contains(t, types, names[value.Type], field)
+		} else {
+			assert.Equal(t, allowed, names[value.Type], field)
+		}
+	}
+}
+
+func TestJSONSchemaSpecialTypes(t *testing.T) {
+	schema, err := datastore.JSONSchema[SpecialTypes]()
+	if err != nil {
+		t.Fatalf("Error on generating schema: %v", err)
+	}
+
+	properties := schema["properties"].(bson.M)
+	assert.Equal(t, bson.M{"bsonType": "binData"}, properties["binary"])
+	assert.Equal(t, bson.M{"bsonType": "timestamp"}, properties["timestamp"])
+	assert.Equal(t, bson.M{"bsonType": "regex"}, properties["regex"])
+	assert.Equal(t, bson.M{"bsonType": "object"}, properties["raw"])
+	assert.Equal(t, bson.M{"bsonType": "object"}, properties["document"])
+	assert.Equal(t, bson.M{"bsonType": []string{"binData", "null"}}, properties["token"])
+	assert.Equal(t, bson.M{"bsonType": "binData"}, properties["hash"])
+	// Types that marshal themselves are not constrained.
+	assert.Equal(t, bson.M{}, properties["nickname"])
+	assert.Equal(t, bson.M{}, properties["secret"])
+
+	raw, err := bson.Marshal(bson.M{"a": 1})
+	if err != nil {
+		t.Fatalf("Error on marshaling: %v", err)
+	}
+	data, err := bson.Marshal(bson.M{
+		"binary":    primitive.Binary{Data: []byte{1}},
+		"timestamp": primitive.Timestamp{T: 1},
+		"regex":     primitive.Regex{Pattern: "^a"},
+		"raw":       bson.Raw(raw),
+		"document":  bson.D{{Key: "a", Value: 1}},
+		"token":     Token("token"),
+		"hash":      [4]byte{1, 2, 3, 4},
+	})
+	if err != nil {
+		t.Fatalf("Error on marshaling: %v", err)
+	}
+
+	names := map[bsontype.Type]string{
+		bsontype.Binary: "binData", bsontype.Timestamp: "timestamp", bsontype.Regex: "regex",
+		bsontype.EmbeddedDocument: "object", bsontype.Null: "null",
+	}
+	for _, field := range []string{"binary", "timestamp", "regex", "raw", "document", "token", "hash"} {
+		allowed := properties[field].(bson.M)["bsonType"]
+		value := bson.Raw(data).Lookup(field)
+		if types, ok := allowed.([]string); ok {
+			assert.Contains(t, types, names[value.Type], field)
+		} else {
+			assert.Equal(t, allowed, names[value.Type], field)
+		}
+	}
+}
+
+func TestJSONSchemaTags(t *testing.T) {
+	type Audit struct {
+		By string `bson:"by"`
+	}
+	type Tagged struct {
+		Audit    `bson:",inline,omitempty"`
+		Name     string `bson:"name,omitempty"`
+		Internal string `bson:"-"`
+		Untagged int
+	}
+
+	schema, err := datastore.JSONSchema[Tagged]()
+	if err != nil {
+		t.Fatalf("Error on generating schema: %v", err)
+	}
+
+	assert.Equal(t, bson.M{
+		"by":       bson.M{"bsonType": "string"},
+		"name":     bson.M{"bsonType": "string"},
+		"untagged": bson.M{"bsonType": "number"},
+	}, schema["properties"])
+	assert.Equal(t, []string{"by", "untagged"}, schema["required"])
+}
+
+func TestEnsureCollection(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDataStore(t)
+	defer store.DropCollection(ctx, "customer", datastore.WithForce())
+
+	err := datastore.EnsureCollection[Customer](ctx, store, "customer", datastore.WithValidationAction(datastore.ValidationActionError))
+	if err != nil {
+		t.Fatalf("Error on ensuring collection: %v", err)
+	}
+
+	col := store.Database.Collection("customer")
+	_, err = col.InsertOne(ctx, Customer{Name: "Willy", Tags: []string{"vip"}, Attributes: map[string]string{"tier": "gold"}, Avatar: []byte{1},
+		BaseModel: mongodb.BaseModel{CreatedAt: time.Now(), UpdatedAt: time.Now()}})
+	assert.NoError(t, err)
+	// Nil slices and maps are stored as null.
+	_, err = col.InsertOne(ctx, Customer{Name: "Wanda", BaseModel: mongodb.BaseModel{CreatedAt: time.Now(), UpdatedAt: time.Now()}})
+	assert.NoError(t, err)
+
+	_, err = col.InsertOne(ctx, bson.M{"name": 42})
+	assert.Error(t, err)
+
+	// A second call with a more relaxed schema updates the validator of the existing collection.
+	err = datastore.EnsureCollection[Address](ctx, store, "customer")
+	if err != nil {
+		t.Fatalf("Error on updating collection: %v", err)
+	}
+
+	_, err = col.InsertOne(ctx, bson.M{"street": "Main Street", "name": 42})
+	assert.NoError(t, err)
+}
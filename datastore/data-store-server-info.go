@@ -0,0 +1,191 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type (
+	// Version is a parsed MongoDB server version.
+	Version struct {
+		Major int
+		Minor int
+		Patch int
+	}
+
+	// ServerInfo describes the server and topology the DataStore is connected to.
+	// It combines the output of the buildInfo, hello and serverStatus commands.
+	ServerInfo struct {
+		Version       Version
+		VersionString string
+		// StorageEngine is empty if the user is not allowed to run serverStatus.
+		StorageEngine string
+		// ReplicaSet is the name of the replica set, or empty for standalone servers and mongos.
+		ReplicaSet        string
+		Sharded           bool
+		Primary           string
+		Hosts             []string
+		IsWritablePrimary bool
+	}
+
+	// Feature is a server capability that depends on the version or topology.
+	Feature int
+)
+
+const (
+	// FeatureTransactions requires a replica set or a sharded cluster.
+	FeatureTransactions Feature = iota
+	// FeatureChangeStreams requires a replica set or a sharded cluster.
+	FeatureChangeStreams
+	// FeatureTimeSeries requires MongoDB 5.0 or newer.
+	FeatureTimeSeries
+)
+
+func (f Feature) String() string {
+	switch f {
+	case FeatureTransactions:
+		return "transactions"
+	case FeatureChangeStreams:
+		return "change streams"
+	case FeatureTimeSeries:
+		return "time-series collections"
+	}
+
+	return "feature(" + strconv.Itoa(int(f)) + ")"
+}
+
+// ParseVersion parses a version string like "7.0.4" or "6.0.0-rc1".
+func ParseVersion(version string) (Version, error) {
+	core := version
+	if i := strings.IndexAny(core, "-+ "); i >= 0 {
+		core = core[:i]
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Version{}, fmt.Errorf("ParseVersion: invalid version %q", version)
+	}
+
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("ParseVersion: invalid version %q", version)
+		}
+		numbers[i] = n
+	}
+
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// AtLeast reports whether the version is greater than or equal to major.minor.
+func (v Version) AtLeast(major, minor int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+
+	return v.Minor >= minor
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Supports reports whether the server supports the given feature.
+func (info ServerInfo) Supports(feature Feature) bool {
+	switch feature {
+	case FeatureTransactions:
+		return info.ReplicaSet != "" || (info.Sharded && info.Version.AtLeast(4, 2))
+	case FeatureChangeStreams:
+		return info.ReplicaSet != "" || info.Sharded
+	case FeatureTimeSeries:
+		return info.Version.AtLeast(5, 0)
+	}
+
+	return false
+}
+
+// ServerInfo fetches information about the server and topology.
+// The result is cached for [DataStore.FeatureSupported].
+func (dataStore *DataStore) ServerInfo(ctx context.Context) (ServerInfo, error) {
+	admin := dataStore.Client.Database("admin")
+
+	var buildInfo struct {
+		Version string `bson:"version"`
+	}
+	err := admin.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&buildInfo)
+	if err != nil {
+		return ServerInfo{}, fmt.Errorf("ServerInfo: buildInfo: %w", err)
+	}
+
+	version, err := ParseVersion(buildInfo.Version)
+	if err != nil {
+		return ServerInfo{}, err
+	}
+
+	var hello struct {
+		IsWritablePrimary bool     `bson:"isWritablePrimary"`
+		IsMaster          bool     `bson:"ismaster"`
+		SetName           string   `bson:"setName"`
+		Primary           string   `bson:"primary"`
+		Hosts             []string `bson:"hosts"`
+		Msg               string   `bson:"msg"`
+	}
+	err = admin.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		// Servers older than 4.4.2 only know the legacy command.
+		err = admin.RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&hello)
+		if err != nil {
+			return ServerInfo{}, fmt.Errorf("ServerInfo: hello: %w", err)
+		}
+	}
+
+	var serverStatus struct {
+		StorageEngine struct {
+			Name string `bson:"name"`
+		} `bson:"storageEngine"`
+	}
+	// serverStatus requires the clusterMonitor role, the storage engine is only informational.
+	_ = admin.RunCommand(ctx, bson.D{{Key: "serverStatus", Value: 1}}).Decode(&serverStatus)
+
+	info := ServerInfo{
+		Version:           version,
+		VersionString:     buildInfo.Version,
+		StorageEngine:     serverStatus.StorageEngine.Name,
+		ReplicaSet:        hello.SetName,
+		Sharded:           hello.Msg == "isdbgrid",
+		Primary:           hello.Primary,
+		Hosts:             hello.Hosts,
+		IsWritablePrimary: hello.IsWritablePrimary || hello.IsMaster,
+	}
+
+	dataStore.infoMu.Lock()
+	dataStore.serverInfo = &info
+	dataStore.infoMu.Unlock()
+
+	return info, nil
+}
+
+// FeatureSupported reports whether the connected server supports the given feature.
+//
+// It uses the result of the last [DataStore.ServerInfo] call, and fetches it with ctx if there is none yet.
+// Errors of that call are returned and not cached, so the next call tries again.
+func (dataStore *DataStore) FeatureSupported(ctx context.Context, feature Feature) (bool, error) {
+	dataStore.infoMu.Lock()
+	info := dataStore.serverInfo
+	dataStore.infoMu.Unlock()
+
+	if info == nil {
+		fetched, err := dataStore.ServerInfo(ctx)
+		if err != nil {
+			return false, fmt.Errorf("%v: %w", "datastore.DataStore.FeatureSupported", err)
+		}
+		info = &fetched
+	}
+
+	return info.Supports(feature), nil
+}
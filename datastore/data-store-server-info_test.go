@@ -0,0 +1,59 @@
+package datastore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    datastore.Version
+		wantErr bool
+	}{
+		{version: "7.0.4", want: datastore.Version{Major: 7, Minor: 0, Patch: 4}},
+		{version: "6.0.0-rc1", want: datastore.Version{Major: 6, Minor: 0, Patch: 0}},
+		{version: "4.4", want: datastore.Version{Major: 4, Minor: 4}},
+		{version: "", wantErr: true},
+		{version: "seven", wantErr: true},
+		{version: "1.2.3.4", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := datastore.ParseVersion(tt.version)
+		if tt.wantErr {
+			assert.Error(t, err, tt.version)
+			continue
+		}
+		assert.NoError(t, err, tt.version)
+		assert.Equal(t, tt.want, got, tt.version)
+	}
+}
+
+func TestServerInfoSupports(t *testing.T) {
+	standalone := datastore.ServerInfo{Version: datastore.Version{Major: 5}}
+	replicaSet := datastore.ServerInfo{Version: datastore.Version{Major: 4, Minor: 4}, ReplicaSet: "rs0"}
+
+	assert.False(t, standalone.Supports(datastore.FeatureTransactions))
+	assert.True(t, standalone.Supports(datastore.FeatureTimeSeries))
+	assert.True(t, replicaSet.Supports(datastore.FeatureTransactions))
+	assert.False(t, replicaSet.Supports(datastore.FeatureTimeSeries))
+}
+
+func TestServerInfo(t *testing.T) {
+	store := newTestDataStore(t)
+
+	info, err := store.ServerInfo(context.Background())
+	if err != nil {
+		t.Fatalf("Error on fetching server info: %v", err)
+	}
+
+	assert.NotEmpty(t, info.VersionString)
+	assert.Equal(t, info.Version.String()[:2], info.VersionString[:2])
+	supported, err := store.FeatureSupported(context.Background(), datastore.FeatureTransactions)
+	assert.NoError(t, err)
+	assert.Equal(t, info.Supports(datastore.FeatureTransactions), supported)
+}
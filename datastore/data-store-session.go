@@ -0,0 +1,90 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NewSession starts a new session with causal consistency enabled, unless the options disable it.
+// The caller must end the session with session.EndSession.
+//
+// Causal consistency only orders the operations of the same session. Reads are only guaranteed to observe the prior writes
+// of the session if both use majority read and write concerns, see [https://www.mongodb.com/docs/manual/core/causal-consistency-read-write-concerns/]
+//
+// Repositories can be bound to the session with [mongodb.Repository.WithSession].
+func (dataStore *DataStore) NewSession(ctx context.Context, opts ...*options.SessionOptions) (mongo.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sessionOpts := options.MergeSessionOptions(append([]*options.SessionOptions{options.Session().SetCausalConsistency(true)}, opts...)...)
+
+	return dataStore.Client.StartSession(sessionOpts)
+}
+
+// CausalToken is the cluster time and operation time of a session.
+// It can be persisted, e.g. in a cookie, and passed to [AdvanceClusterTime] in a later request,
+// so reads of that request observe the writes that happened before the token was taken,
+// as long as the writes used a majority write concern and the reads use a majority read concern.
+type CausalToken struct {
+	ClusterTime   bson.Raw
+	OperationTime *primitive.Timestamp
+}
+
+// WithCausalConsistency runs fn inside a causally consistent session.
+//
+// The repository calls that use the ctx passed to fn run in the same session, so a read observes the prior writes of fn,
+// even if it is routed to a secondary, but only if the writes use a majority write concern and the read a majority read concern.
+// With weaker concerns, e.g. the default write concern w:1, a read may miss writes that were rolled back or not replicated yet.
+// The concerns can be set on the client or database of the DataStore, or per call with [mongodb.ReadConcern] and [mongodb.WriteConcern], see [mongodb.WithOpOptions].
+func WithCausalConsistency(ctx context.Context, store *DataStore, fn func(ctx context.Context) error) error {
+	session, err := store.NewSession(ctx)
+	if err != nil {
+		return fmt.Errorf("WithCausalConsistency: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	return fn(mongo.NewSessionContext(ctx, session))
+}
+
+// CausalTokenFromContext returns the current cluster and operation time of the session carried by ctx.
+// It reports false if ctx does not carry a session.
+func CausalTokenFromContext(ctx context.Context) (CausalToken, bool) {
+	session := mongo.SessionFromContext(ctx)
+	if session == nil {
+		return CausalToken{}, false
+	}
+
+	return CausalToken{
+		ClusterTime:   session.ClusterTime(),
+		OperationTime: session.OperationTime(),
+	}, true
+}
+
+// AdvanceClusterTime advances the session carried by ctx to the given token,
+// so its following reads with a majority read concern observe the majority-acknowledged writes that happened before the token was taken.
+func AdvanceClusterTime(ctx context.Context, token CausalToken) error {
+	session := mongo.SessionFromContext(ctx)
+	if session == nil {
+		return errors.New("AdvanceClusterTime: context does not carry a session")
+	}
+
+	if token.ClusterTime != nil {
+		if err := session.AdvanceClusterTime(token.ClusterTime); err != nil {
+			return fmt.Errorf("AdvanceClusterTime: %w", err)
+		}
+	}
+	if token.OperationTime != nil {
+		if err := session.AdvanceOperationTime(token.OperationTime); err != nil {
+			return fmt.Errorf("AdvanceClusterTime: %w", err)
+		}
+	}
+
+	return nil
+}
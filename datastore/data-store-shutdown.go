@@ -0,0 +1,104 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+)
+
+// operationTracker counts the in-flight operations of all repositories created via [RepositoryFor],
+// so that [DataStore.Shutdown] can wait for them. It is registered as an [mongodb.OperationHook].
+type operationTracker struct {
+	store *DataStore
+}
+
+func (t operationTracker) Before(ctx context.Context, op *mongodb.Operation) (context.Context, error) {
+	s := t.store
+	s.initLifecycle()
+
+	s.lifecycleMu.Lock()
+	defer s.lifecycleMu.Unlock()
+
+	if s.closing {
+		return ctx, fmt.Errorf("%v on %v: %w", op.Name, op.Collection, ErrDataStoreClosed)
+	}
+	s.inFlight++
+
+	return ctx, nil
+}
+
+func (t operationTracker) After(_ context.Context, _ *mongodb.Operation, err error) error {
+	s := t.store
+
+	s.lifecycleMu.Lock()
+	defer s.lifecycleMu.Unlock()
+
+	s.inFlight--
+	if s.closing && s.inFlight == 0 {
+		s.closeDrained()
+	}
+
+	return err
+}
+
+func (dataStore *DataStore) initLifecycle() {
+	dataStore.lifecycleOnce.Do(func() {
+		dataStore.closed = make(chan struct{})
+		dataStore.drained = make(chan struct{})
+	})
+}
+
+// closeDrained must be called with lifecycleMu held.
+func (dataStore *DataStore) closeDrained() {
+	select {
+	case <-dataStore.drained:
+	default:
+		close(dataStore.drained)
+	}
+}
+
+// Closed returns a channel that is closed as soon as [DataStore.Shutdown] was called.
+func (dataStore *DataStore) Closed() <-chan struct{} {
+	dataStore.initLifecycle()
+	return dataStore.closed
+}
+
+// Shutdown gracefully stops the DataStore.
+//
+// New operations of repositories created via [RepositoryFor] are rejected with [ErrDataStoreClosed],
+// operations that are already running are waited for, and then the client is disconnected.
+// If ctx is done before all operations finished, the client is disconnected anyway and the context error is returned.
+func (dataStore *DataStore) Shutdown(ctx context.Context) error {
+	dataStore.initLifecycle()
+
+	dataStore.lifecycleMu.Lock()
+	if !dataStore.closing {
+		dataStore.closing = true
+		close(dataStore.closed)
+	}
+	if dataStore.inFlight == 0 {
+		dataStore.closeDrained()
+	}
+	dataStore.lifecycleMu.Unlock()
+
+	var waitErr error
+	select {
+	case <-dataStore.drained:
+	case <-ctx.Done():
+		dataStore.lifecycleMu.Lock()
+		waitErr = fmt.Errorf("Shutdown: %d operations still running: %w", dataStore.inFlight, ctx.Err())
+		dataStore.lifecycleMu.Unlock()
+	}
+
+	if dataStore.cancel != nil {
+		defer dataStore.cancel()
+	}
+
+	err := dataStore.Client.Disconnect(ctx)
+	if waitErr != nil {
+		return waitErr
+	}
+
+	return err
+}
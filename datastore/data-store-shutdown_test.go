@@ -0,0 +1,47 @@
+package datastore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestShutdownDrainsOperations(t *testing.T) {
+	ctx := context.Background()
+	store, err := datastore.NewDataStore("mongodb://localhost:27017", "testdb")
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+
+	repo := datastore.RepositoryFor[*User](store, "shutdown")
+	if _, err := repo.InsertOne(ctx, &User{Name: "Willy"}); err != nil {
+		t.Fatalf("Error on inserting user: %v", err)
+	}
+	defer store.Database.Collection("shutdown").Drop(context.Background())
+
+	result := make(chan error, 1)
+	go func() {
+		// $where with sleep keeps the operation in flight while Shutdown is called.
+		_, err := repo.FindMany(ctx, primitive.M{"$where": "sleep(500) || true"})
+		result <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- store.Shutdown(shutdownCtx) }()
+
+	<-store.Closed()
+	_, err = repo.CountDocuments(ctx, primitive.M{})
+	assert.ErrorIs(t, err, datastore.ErrDataStoreClosed)
+
+	assert.NoError(t, <-result)
+	assert.NoError(t, <-shutdown)
+}
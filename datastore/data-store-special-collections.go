@@ -0,0 +1,157 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Default granularity of time-series collections, if none is passed.
+const defaultGranularity = "seconds"
+
+const (
+	// minCappedSize is the smallest size of a capped collection, smaller sizes are raised to it by the server.
+	minCappedSize = 4096
+	// maxCappedSize is the largest size of a capped collection the server accepts, 1 PB.
+	maxCappedSize = 1 << 50
+)
+
+// CreateCappedCollection creates a capped collection with the given maximum size in bytes and maximum number of documents.
+// A maxDocs of 0 means that the number of documents is only limited by the size.
+//
+// Like the server does, the size is raised to at least 4096 bytes and rounded up to a multiple of 256 bytes.
+// Sizes that are not positive or larger than 1 PB, and a negative maxDocs, are rejected with an error.
+//
+// If a capped collection with the same options already exists, nothing is done.
+// If the collection exists with other options, a [*CollectionMismatchError] is returned.
+//
+// See [https://www.mongodb.com/docs/manual/core/capped-collections/]
+func (dataStore *DataStore) CreateCappedCollection(ctx context.Context, name string, sizeBytes int64, maxDocs int64) error {
+	if sizeBytes <= 0 || sizeBytes > maxCappedSize {
+		return fmt.Errorf("CreateCappedCollection: size of %v bytes is not between 1 byte and 1 PB", sizeBytes)
+	}
+	if maxDocs < 0 {
+		return fmt.Errorf("CreateCappedCollection: negative maximum number of documents %v", maxDocs)
+	}
+	size := roundUp(sizeBytes, 256)
+	if size < minCappedSize {
+		size = minCappedSize
+	}
+
+	info, err := dataStore.collectionInfo(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if info != nil {
+		if capped, _ := info.Options["capped"].(bool); !capped {
+			return &CollectionMismatchError{Collection: name, Option: "capped", Expected: true, Actual: false}
+		}
+		if actual := toInt64(info.Options["size"]); actual != size {
+			return &CollectionMismatchError{Collection: name, Option: "size", Expected: size, Actual: actual}
+		}
+		if max := toInt64(info.Options["max"]); max != maxDocs {
+			return &CollectionMismatchError{Collection: name, Option: "max", Expected: maxDocs, Actual: max}
+		}
+		return nil
+	}
+
+	opts := options.CreateCollection().SetCapped(true).SetSizeInBytes(size)
+	if maxDocs > 0 {
+		opts.SetMaxDocuments(maxDocs)
+	}
+
+	return dataStore.Database.CreateCollection(ctx, name, opts)
+}
+
+// CreateTimeSeriesCollection creates a time-series collection.
+// metaField is optional, granularity defaults to "seconds" and an expireAfter of 0 disables the automatic removal of documents.
+//
+// If a time-series collection with the same options already exists, nothing is done.
+// If the collection exists with other options, a [*CollectionMismatchError] is returned.
+//
+// See [https://www.mongodb.com/docs/manual/core/timeseries-collections/]
+func (dataStore *DataStore) CreateTimeSeriesCollection(ctx context.Context, name string, timeField, metaField string, granularity string, expireAfter time.Duration) error {
+	if granularity == "" {
+		granularity = defaultGranularity
+	}
+	expireAfterSeconds := int64(expireAfter / time.Second)
+
+	info, err := dataStore.collectionInfo(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if info != nil {
+		timeseries, ok := info.Options["timeseries"].(bson.M)
+		if info.Type != CollectionTypeTimeSeries || !ok {
+			return &CollectionMismatchError{Collection: name, Option: "type", Expected: CollectionTypeTimeSeries, Actual: info.Type}
+		}
+
+		expected := map[string]string{"timeField": timeField, "metaField": metaField, "granularity": granularity}
+		for _, key := range []string{"timeField", "metaField", "granularity"} {
+			actual, _ := timeseries[key].(string)
+			if actual != expected[key] {
+				return &CollectionMismatchError{Collection: name, Option: key, Expected: expected[key], Actual: actual}
+			}
+		}
+
+		if actual := toInt64(info.Options["expireAfterSeconds"]); actual != expireAfterSeconds {
+			return &CollectionMismatchError{Collection: name, Option: "expireAfterSeconds", Expected: expireAfterSeconds, Actual: actual}
+		}
+		return nil
+	}
+
+	tsOpts := options.TimeSeries().SetTimeField(timeField).SetGranularity(granularity)
+	if metaField != "" {
+		tsOpts.SetMetaField(metaField)
+	}
+
+	opts := options.CreateCollection().SetTimeSeriesOptions(tsOpts)
+	if expireAfterSeconds > 0 {
+		opts.SetExpireAfterSeconds(expireAfterSeconds)
+	}
+
+	return dataStore.Database.CreateCollection(ctx, name, opts)
+}
+
+// collectionInfo returns the listCollections entry for the given name, or nil if it does not exist.
+func (dataStore *DataStore) collectionInfo(ctx context.Context, name string) (*CollectionInfo, error) {
+	infos, err := dataStore.ListCollections(ctx, bson.M{"name": name})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(infos) == 0 {
+		return nil, nil
+	}
+
+	return &infos[0], nil
+}
+
+// toInt64 converts the numeric types the server may return for an option into an int64.
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+
+	return 0
+}
+
+func roundUp(value, multiple int64) int64 {
+	if remainder := value % multiple; remainder != 0 {
+		return value + multiple - remainder
+	}
+
+	return value
+}
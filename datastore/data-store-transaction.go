@@ -0,0 +1,42 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WithTransaction runs fn inside a multi-document transaction.
+//
+// All repository calls that use the ctx passed to fn participate in the transaction.
+// If fn returns an error, the transaction is aborted and the error is returned.
+// Transient transaction errors and unknown commit results are retried as specified by the driver,
+// so fn may be called more than once and must not have side effects outside of the database.
+//
+// Transactions require a replica set or a sharded cluster, on a standalone server an error wrapping [ErrFeatureNotSupported] is returned.
+// If the server info can not be fetched, that error is returned instead.
+//
+// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Session]
+func (dataStore *DataStore) WithTransaction(ctx context.Context, fn func(ctx context.Context) error, opts ...*options.TransactionOptions) error {
+	supported, err := dataStore.FeatureSupported(ctx, FeatureTransactions)
+	if err != nil {
+		return fmt.Errorf("WithTransaction: %w", err)
+	}
+	if !supported {
+		return fmt.Errorf("WithTransaction: %v require a replica set or a sharded cluster: %w", FeatureTransactions, ErrFeatureNotSupported)
+	}
+
+	session, err := dataStore.Client.StartSession()
+	if err != nil {
+		return fmt.Errorf("WithTransaction: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	}, opts...)
+
+	return err
+}
@@ -0,0 +1,64 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+)
+
+// VerificationError lists the errors of all repositories that failed [DataStore.VerifyAll].
+//
+// errors.Is and errors.As match the error against the error of every repository.
+type VerificationError struct {
+	Errors []error
+}
+
+func (e *VerificationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return "datastore: verification failed: " + strings.Join(msgs, "\n")
+}
+
+func (e *VerificationError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *VerificationError) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyAll pings the server and verifies all repositories, e.g. in a readiness check, see [mongodb.Repository.Verify].
+//
+// All repositories are verified, and a [*VerificationError] with the errors of all failed repositories is returned.
+// If the ping fails, the repositories are not verified.
+func (dataStore *DataStore) VerifyAll(ctx context.Context, repos ...mongodb.Verifier) error {
+	if err := dataStore.Client.Ping(ctx, nil); err != nil {
+		return &VerificationError{Errors: []error{err}}
+	}
+
+	var errs []error
+	for _, repo := range repos {
+		if err := repo.Verify(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return &VerificationError{Errors: errs}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package datastore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyAll(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDataStore(t)
+
+	users := datastore.RepositoryFor[*User](store, "user_verify_all").(*mongodb.Repository[*User])
+	defer users.Drop(ctx)
+	if _, err := users.InsertOne(ctx, &User{Name: "alice"}); err != nil {
+		t.Fatalf("Error on inserting user: %v", err)
+	}
+	assert.NoError(t, store.VerifyAll(ctx, users))
+
+	missing := datastore.RepositoryFor[*User](store, "user_verify_all_typo").(*mongodb.Repository[*User])
+	err := store.VerifyAll(ctx, users, missing)
+	assert.ErrorIs(t, err, mongodb.ErrCollectionNotFound)
+	var verr *datastore.VerificationError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Len(t, verr.Errors, 1)
+	}
+
+}
@@ -0,0 +1,131 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// readOperations are the repository operations that a read-only repository allows, see [NewReadRepositoryForView].
+var readOperations = map[string]bool{
+	"FindOne":          true,
+	"FindOneWhere":     true,
+	"GetByID":          true,
+	"FindMany":         true,
+	"FindManyWhere":    true,
+	"FindManyN":        true,
+	"FindManyRaw":      true,
+	"FindCursor":       true,
+	"FindIter":         true,
+	"FindManyParallel": true,
+	"CountDocuments":   true,
+	"CountWhere":       true,
+	"Distinct":         true,
+	"Aggregate":        true,
+	"Watch":            true,
+	"Stats":            true,
+	"Verify":           true,
+	"ListIndexes":      true,
+}
+
+// readOnlyGuard rejects all operations but the ones in readOperations with a [*ReadOnlyError].
+// It is registered as an [mongodb.OperationHook].
+type readOnlyGuard struct{}
+
+func (readOnlyGuard) Before(ctx context.Context, op *mongodb.Operation) (context.Context, error) {
+	if !readOperations[op.Name] {
+		return ctx, &ReadOnlyError{Operation: op.Name, Collection: op.Collection}
+	}
+	return ctx, nil
+}
+
+func (readOnlyGuard) After(_ context.Context, _ *mongodb.Operation, err error) error {
+	return err
+}
+
+// EnsureView creates a view with the given name on sourceCollection of the default database.
+//
+// If the view already exists on the same source with the same pipeline, nothing is done. If the source or the pipeline
+// differ, the view is dropped and created again, which is safe because a view stores no documents. If a collection that
+// is not a view exists with the name, a [*CollectionMismatchError] is returned.
+//
+// See [https://www.mongodb.com/docs/manual/core/views/]
+func (dataStore *DataStore) EnsureView(ctx context.Context, viewName, sourceCollection string, pipeline mongo.Pipeline) error {
+	if pipeline == nil {
+		pipeline = mongo.Pipeline{}
+	}
+
+	info, err := dataStore.collectionInfo(ctx, viewName)
+	if err != nil {
+		return fmt.Errorf("%v: %w", "datastore.EnsureView", err)
+	}
+
+	if info != nil {
+		if !info.IsView() {
+			return &CollectionMismatchError{Collection: viewName, Option: "type", Expected: CollectionTypeView, Actual: info.Type}
+		}
+
+		same, err := sameView(info, sourceCollection, pipeline)
+		if err != nil {
+			return fmt.Errorf("%v: %w", "datastore.EnsureView", err)
+		}
+		if same {
+			return nil
+		}
+
+		if err := dataStore.Database.Collection(viewName).Drop(ctx); err != nil {
+			return fmt.Errorf("%v: %v: %w", "datastore.EnsureView", viewName, err)
+		}
+	}
+
+	if err := dataStore.Database.CreateView(ctx, viewName, sourceCollection, pipeline); err != nil {
+		return fmt.Errorf("%v: %v: %w", "datastore.EnsureView", viewName, err)
+	}
+
+	return nil
+}
+
+// sameView reports whether the existing view was created on sourceCollection with pipeline.
+//
+// The pipeline is compared after a round trip through BSON, so that it is decoded the same way as the listCollections output.
+func sameView(info *CollectionInfo, sourceCollection string, pipeline mongo.Pipeline) (bool, error) {
+	if viewOn, _ := info.Options["viewOn"].(string); viewOn != sourceCollection {
+		return false, nil
+	}
+
+	raw, err := bson.Marshal(bson.M{"pipeline": pipeline})
+	if err != nil {
+		return false, err
+	}
+	var expected bson.M
+	if err := bson.Unmarshal(raw, &expected); err != nil {
+		return false, err
+	}
+
+	actual := info.Options["pipeline"]
+	if actual == nil {
+		actual = bson.A{}
+	}
+	return reflect.DeepEqual(expected["pipeline"], actual), nil
+}
+
+// NewReadRepositoryForView creates a read-only repository for the view with the given name in the default database
+// or the given database, e.g. for a view created by [DataStore.EnsureView].
+//
+// Only reading operations are allowed, every other operation returns a [*ReadOnlyError] without contacting the server.
+// Like [RepositoryFor], the repository is tracked by [DataStore.Shutdown].
+func NewReadRepositoryForView[T mongodb.Document[T]](dataStore *DataStore, viewName string, database ...string) mongodb.RepositoryI[T] {
+	db := dataStore.Database
+	if len(database) > 0 && database[0] != "" {
+		db = dataStore.DatabaseFor(database[0])
+	}
+
+	return mongodb.NewRepository[T](db.Collection(viewName), dataStore.repositoryOptions(
+		mongodb.WithHook(readOnlyGuard{}),
+		mongodb.WithHook(operationTracker{store: dataStore}),
+	)...)
+}
@@ -0,0 +1,72 @@
+package datastore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type (
+	Employee struct {
+		mongodb.BaseModel `bson:",inline"`
+		Name              string `bson:"name"`
+		Salary            int    `bson:"salary"`
+	}
+
+	PublicEmployee struct {
+		mongodb.BaseModel `bson:",inline"`
+		Name              string `bson:"name"`
+		Salary            int    `bson:"salary,omitempty"`
+	}
+)
+
+func TestEnsureView(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDataStore(t)
+
+	employees := datastore.RepositoryFor[*Employee](store, "view_employees").(*mongodb.Repository[*Employee])
+	defer employees.Drop(ctx)
+	defer store.DropCollection(ctx, "view_public_employees", datastore.WithForce())
+
+	if _, err := employees.InsertMany(ctx, []*Employee{{Name: "alice", Salary: 100}, {Name: "bob", Salary: 200}}); err != nil {
+		t.Fatalf("Error on inserting employees: %v", err)
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$project", Value: bson.D{{Key: "name", Value: 1}}}}}
+	assert.NoError(t, store.EnsureView(ctx, "view_public_employees", "view_employees", pipeline))
+	assert.NoError(t, store.EnsureView(ctx, "view_public_employees", "view_employees", pipeline))
+
+	view := datastore.NewReadRepositoryForView[*PublicEmployee](store, "view_public_employees")
+	res, err := view.FindMany(ctx, bson.M{}, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, res, 2) {
+		assert.NotEmpty(t, res[0].Name)
+		assert.Zero(t, res[0].Salary)
+	}
+
+	_, err = view.InsertOne(ctx, &PublicEmployee{Name: "eve"})
+	assert.ErrorIs(t, err, datastore.ErrReadOnly)
+	var readOnly *datastore.ReadOnlyError
+	if assert.ErrorAs(t, err, &readOnly) {
+		assert.Equal(t, "InsertOne", readOnly.Operation)
+	}
+	_, err = view.DeleteMany(ctx, bson.M{})
+	assert.ErrorIs(t, err, datastore.ErrReadOnly)
+
+	pipeline = mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "salary", Value: bson.D{{Key: "$gt", Value: 150}}}}}},
+		{{Key: "$project", Value: bson.D{{Key: "name", Value: 1}}}},
+	}
+	assert.NoError(t, store.EnsureView(ctx, "view_public_employees", "view_employees", pipeline))
+	count, err := view.CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	var mismatch *datastore.CollectionMismatchError
+	assert.ErrorAs(t, store.EnsureView(ctx, "view_employees", "view_public_employees", pipeline), &mismatch)
+}
@@ -0,0 +1,154 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/auth"
+)
+
+// codeAuthenticationFailed is the server error code of failed authentications.
+const codeAuthenticationFailed int32 = 18
+
+type (
+	// WaitOption configures [WaitForReady].
+	WaitOption interface {
+		apply(*waitOption)
+	}
+)
+
+type (
+	waitOption struct {
+		initialBackoff time.Duration
+		maxBackoff     time.Duration
+		attemptTimeout time.Duration
+	}
+)
+
+type initialBackoffOption time.Duration
+
+func (value initialBackoffOption) apply(o *waitOption) {
+	if value > 0 {
+		o.initialBackoff = time.Duration(value)
+	}
+}
+
+// WithInitialBackoff sets the wait after the first failed attempt, which doubles after every further attempt. The default is 100ms.
+func WithInitialBackoff(d time.Duration) WaitOption {
+	return initialBackoffOption(d)
+}
+
+type maxBackoffOption time.Duration
+
+func (value maxBackoffOption) apply(o *waitOption) {
+	if value > 0 {
+		o.maxBackoff = time.Duration(value)
+	}
+}
+
+// WithMaxBackoff caps the wait between two attempts. The default is 5s.
+func WithMaxBackoff(d time.Duration) WaitOption {
+	return maxBackoffOption(d)
+}
+
+type attemptTimeoutOption time.Duration
+
+func (value attemptTimeoutOption) apply(o *waitOption) {
+	if value > 0 {
+		o.attemptTimeout = time.Duration(value)
+	}
+}
+
+// WithAttemptTimeout caps the time a single connect and ping may take. The default is 2s.
+func WithAttemptTimeout(d time.Duration) WaitOption {
+	return attemptTimeoutOption(d)
+}
+
+// WaitForReady connects to the MongoDB at uri and pings it until it answers or ctx ends, e.g. when a service in docker-compose
+// or CI starts before MongoDB accepts connections. Between the attempts, WaitForReady waits with exponential backoff.
+//
+// Only refused connections, timeouts and similar errors are retried. Failed authentications and invalid URIs fail after the
+// first attempt, since retrying can not fix them. The returned [*WaitError] contains the number of attempts and the last error,
+// it matches [ErrNotReady] if ctx ended before MongoDB was ready.
+func WaitForReady(ctx context.Context, uri string, opts ...WaitOption) error {
+	ops := &waitOption{
+		initialBackoff: 100 * time.Millisecond,
+		maxBackoff:     5 * time.Second,
+		attemptTimeout: 2 * time.Second,
+	}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+
+	backoff := ops.initialBackoff
+	for attempt := 1; ; attempt++ {
+		err := ping(ctx, uri, ops.attemptTimeout)
+		if err == nil {
+			return nil
+		}
+		if permanent(err) {
+			return &WaitError{Attempts: attempt, Err: err}
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &WaitError{Attempts: attempt, Err: err, NotReady: true}
+		case <-timer.C:
+		}
+		backoff *= 2
+		if backoff > ops.maxBackoff {
+			backoff = ops.maxBackoff
+		}
+	}
+}
+
+// ping connects a new client to uri and pings the primary.
+func ping(ctx context.Context, uri string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetServerSelectionTimeout(timeout).SetConnectTimeout(timeout))
+	if err != nil {
+		return &connectError{err: err}
+	}
+	defer client.Disconnect(context.Background())
+
+	return client.Ping(ctx, readpref.Primary())
+}
+
+// permanent reports whether err can not be fixed by waiting for the server.
+func permanent(err error) bool {
+	var (
+		connectErr *connectError
+		authErr    *auth.Error
+		cmdErr     mongo.CommandError
+	)
+	switch {
+	case errors.As(err, &connectErr):
+		// mongo.Connect does not contact the server, its errors are invalid options.
+		return true
+	case errors.As(err, &authErr):
+		return true
+	case errors.As(err, &cmdErr):
+		return cmdErr.Code == codeAuthenticationFailed
+	}
+	return false
+}
+
+type connectError struct {
+	err error
+}
+
+func (e *connectError) Error() string {
+	return e.err.Error()
+}
+
+func (e *connectError) Unwrap() error {
+	return e.err
+}
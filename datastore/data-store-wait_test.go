@@ -0,0 +1,52 @@
+package datastore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForReadyTimeout(t *testing.T) {
+	// 10.255.255.1 is not routable, so connections time out instead of being refused.
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := datastore.WaitForReady(ctx, "mongodb://10.255.255.1:27017/?connect=direct",
+		datastore.WithInitialBackoff(50*time.Millisecond), datastore.WithAttemptTimeout(200*time.Millisecond))
+	assert.Less(t, time.Since(start), 3*time.Second)
+
+	var waitErr *datastore.WaitError
+	if assert.ErrorAs(t, err, &waitErr) {
+		assert.Greater(t, waitErr.Attempts, 1)
+		assert.NotNil(t, waitErr.Err)
+	}
+	assert.ErrorIs(t, err, datastore.ErrNotReady)
+	assert.ErrorContains(t, err, "MongoDB not ready after")
+}
+
+func TestWaitForReadyInvalidURI(t *testing.T) {
+	err := datastore.WaitForReady(context.Background(), "mongodb://localhost:27017/?connectTimeoutMS=invalid")
+
+	var waitErr *datastore.WaitError
+	if assert.ErrorAs(t, err, &waitErr) {
+		assert.Equal(t, 1, waitErr.Attempts)
+	}
+	assert.False(t, errors.Is(err, datastore.ErrNotReady))
+}
+
+func TestNewDataStoreWaitForReady(t *testing.T) {
+	_, err := datastore.NewDataStore("mongodb://10.255.255.1:27017/?connect=direct", "testdb", datastore.WithWaitForReady(500*time.Millisecond))
+	assert.ErrorIs(t, err, datastore.ErrNotReady)
+}
+
+func TestWaitForReady(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	assert.NoError(t, datastore.WaitForReady(ctx, "mongodb://localhost:27017"))
+}
@@ -2,8 +2,12 @@ package datastore
 
 import (
 	"context"
+	"sync"
 	"time"
 
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -13,6 +17,26 @@ type (
 		Client   *mongo.Client
 		Database *mongo.Database
 		Ctx      context.Context
+
+		cancel    context.CancelFunc
+		dbMu      sync.Mutex
+		databases map[string]*mongo.Database
+
+		infoMu     sync.Mutex
+		serverInfo *ServerInfo
+
+		encryption  *AutoEncryptionConfig
+		requestTag  func(ctx context.Context) string
+		readOnly    bool
+		diagnostics *mongodb.TimeoutDiagnostics
+		registry    *bsoncodec.Registry
+
+		lifecycleOnce sync.Once
+		lifecycleMu   sync.Mutex
+		closing       bool
+		inFlight      int
+		closed        chan struct{}
+		drained       chan struct{}
 	}
 )
 
@@ -26,9 +50,34 @@ func NewDataStore(mongoDbUri, mongoDbName string, dataStoreOptions ...DataStoreO
 		datastoreOption.apply(ops)
 	}
 
-	ctx, _ := context.WithTimeout(context.Background(), ops.timeout)
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoDbUri))
+	if ops.maxWait > 0 {
+		waitCtx, cancelWait := context.WithTimeout(context.Background(), ops.maxWait)
+		err := WaitForReady(waitCtx, mongoDbUri)
+		cancelWait()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ops.timeout)
+	clientOptions := options.Client().ApplyURI(mongoDbUri)
+	if ops.encryption != nil {
+		clientOptions.SetAutoEncryptionOptions(ops.encryption.options())
+	}
+	var registry *bsoncodec.Registry
+	if ops.keys != nil {
+		registry = mongodb.EncryptionRegistry(ops.keys)
+		clientOptions.SetRegistry(registry)
+	}
+	var diagnostics *mongodb.TimeoutDiagnostics
+	if ops.diagnose {
+		diagnostics = mongodb.NewTimeoutDiagnostics()
+		clientOptions.SetMonitor(diagnostics.CommandMonitor()).SetPoolMonitor(diagnostics.PoolMonitor())
+	}
+
+	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
@@ -36,6 +85,7 @@ func NewDataStore(mongoDbUri, mongoDbName string, dataStoreOptions ...DataStoreO
 		// Check connection
 		err = client.Ping(ctx, nil)
 		if err != nil {
+			cancel()
 			return nil, err
 		}
 	}
@@ -43,15 +93,103 @@ func NewDataStore(mongoDbUri, mongoDbName string, dataStoreOptions ...DataStoreO
 	db := client.Database(mongoDbName)
 
 	store := &DataStore{
-		Client:   client,
-		Database: db,
-		Ctx:      ctx,
+		Client:    client,
+		Database:  db,
+		Ctx:       ctx,
+		cancel:    cancel,
+		databases: map[string]*mongo.Database{mongoDbName: db},
+
+		encryption:  ops.encryption,
+		requestTag:  ops.requestTag,
+		readOnly:    ops.readOnly,
+		diagnostics: diagnostics,
+		registry:    registry,
 	}
 
 	return store, nil
 }
 
+// DatabaseFor returns a handle for the database with the given name on the same client.
+//
+// Handles are cached, so repeated calls with the same name return the same *mongo.Database.
+// The options are only applied when the handle is created by the first call for a name.
+func (dataStore *DataStore) DatabaseFor(name string, opts ...*options.DatabaseOptions) *mongo.Database {
+	dataStore.dbMu.Lock()
+	defer dataStore.dbMu.Unlock()
+
+	if dataStore.databases == nil {
+		dataStore.databases = map[string]*mongo.Database{}
+		if dataStore.Database != nil {
+			dataStore.databases[dataStore.Database.Name()] = dataStore.Database
+		}
+	}
+
+	if db, ok := dataStore.databases[name]; ok {
+		return db
+	}
+
+	db := dataStore.Client.Database(name, opts...)
+	dataStore.databases[name] = db
+
+	return db
+}
+
+// ListDatabaseNames returns the names of all databases on the server that match the given filter.
+//
+// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Client.ListDatabaseNames]
+func (dataStore *DataStore) ListDatabaseNames(ctx context.Context, filter bson.M, opts ...*options.ListDatabasesOptions) ([]string, error) {
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	return dataStore.Client.ListDatabaseNames(ctx, filter, opts...)
+}
+
+// RepositoryFor creates a new repository for the given collection.
+//
+// The collection is taken from the default database of the DataStore,
+// unless a database name is passed, in which case [DataStore.DatabaseFor] is used.
+//
+// Operations of the repository are tracked, so that [DataStore.Shutdown] can wait for them.
+func RepositoryFor[T mongodb.Document[T]](dataStore *DataStore, collection string, database ...string) mongodb.RepositoryI[T] {
+	db := dataStore.Database
+	if len(database) > 0 && database[0] != "" {
+		db = dataStore.DatabaseFor(database[0])
+	}
+
+	return mongodb.NewRepository[T](db.Collection(collection), dataStore.repositoryOptions(mongodb.WithHook(operationTracker{store: dataStore}))...)
+}
+
+// repositoryOptions returns opts with the options that the DataStore applies to all of its repositories.
+func (dataStore *DataStore) repositoryOptions(opts ...mongodb.RepositoryOption) []mongodb.RepositoryOption {
+	if dataStore.requestTag != nil {
+		opts = append(opts, mongodb.WithRequestTagging(dataStore.requestTag))
+	}
+	if dataStore.readOnly {
+		opts = append(opts, mongodb.WithHook(readOnlyGuard{}))
+	}
+	if dataStore.diagnostics != nil {
+		opts = append(opts, mongodb.WithHook(dataStore.diagnostics))
+	}
+	if dataStore.registry != nil {
+		opts = append(opts, mongodb.WithRegistry(dataStore.registry))
+	}
+	return opts
+}
+
+// registryOrDefault returns the registry of the client, see [WithEncryptedFields].
+func (dataStore *DataStore) registryOrDefault() *bsoncodec.Registry {
+	if dataStore.registry == nil {
+		return bson.DefaultRegistry
+	}
+	return dataStore.registry
+}
+
 func (dataStore *DataStore) Disconnect() error {
+	if dataStore.cancel != nil {
+		defer dataStore.cancel()
+	}
+
 	err := dataStore.Client.Disconnect(dataStore.Ctx)
 	if err != nil {
 		return err
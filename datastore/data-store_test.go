@@ -0,0 +1,459 @@
+package datastore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+type (
+	User struct {
+		mongodb.BaseModel `bson:",inline"`
+		Name              string `bson:"name"`
+	}
+)
+
+func newTestDataStore(t *testing.T) *datastore.DataStore {
+	t.Helper()
+
+	store, err := datastore.NewDataStore("mongodb://localhost:27017", "testdb")
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	t.Cleanup(func() { store.Disconnect() })
+
+	return store
+}
+
+func TestRepositoryForMultipleDatabases(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDataStore(t)
+
+	assert.Same(t, store.DatabaseFor("testdb_region1"), store.DatabaseFor("testdb_region1"))
+	assert.Same(t, store.Database, store.DatabaseFor("testdb"))
+
+	repo1 := datastore.RepositoryFor[*User](store, "user", "testdb_region1")
+	repo2 := datastore.RepositoryFor[*User](store, "user", "testdb_region2")
+	defer repo1.DeleteMany(ctx, primitive.M{})
+	defer repo2.DeleteMany(ctx, primitive.M{})
+
+	if _, err := repo1.InsertOne(ctx, &User{Name: "Region1"}); err != nil {
+		t.Fatalf("Error on inserting user: %v", err)
+	}
+
+	count1, err := repo1.CountDocuments(ctx, primitive.M{})
+	if err != nil {
+		t.Fatalf("Error on counting users: %v", err)
+	}
+	count2, err := repo2.CountDocuments(ctx, primitive.M{})
+	if err != nil {
+		t.Fatalf("Error on counting users: %v", err)
+	}
+
+	assert.Equal(t, 1, count1)
+	assert.Equal(t, 0, count2)
+
+	names, err := store.ListDatabaseNames(ctx, primitive.M{"name": "testdb_region1"})
+	if err != nil {
+		t.Fatalf("Error on listing databases: %v", err)
+	}
+	assert.Equal(t, []string{"testdb_region1"}, names)
+}
+
+func TestListCollections(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDataStore(t)
+
+	col := store.Database.Collection("listcollections")
+	view := store.Database.Collection("listcollections_view")
+	defer col.Drop(ctx)
+	defer view.Drop(ctx)
+
+	if err := store.Database.CreateCollection(ctx, col.Name()); err != nil {
+		t.Fatalf("Error on creating collection: %v", err)
+	}
+	if err := store.Database.CreateView(ctx, view.Name(), col.Name(), mongo.Pipeline{}); err != nil {
+		t.Fatalf("Error on creating view: %v", err)
+	}
+
+	infos, err := store.ListCollections(ctx, primitive.M{"name": primitive.M{"$in": []string{col.Name(), view.Name()}}})
+	if err != nil {
+		t.Fatalf("Error on listing collections: %v", err)
+	}
+
+	types := map[string]datastore.CollectionType{}
+	for _, info := range infos {
+		types[info.Name] = info.Type
+	}
+	assert.Equal(t, datastore.CollectionTypeCollection, types[col.Name()])
+	assert.Equal(t, datastore.CollectionTypeView, types[view.Name()])
+
+	exists, err := store.CollectionExists(ctx, col.Name())
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = store.CollectionExists(ctx, "does_not_exist")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestDropAndRenameCollection(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDataStore(t)
+
+	repo := datastore.RepositoryFor[*User](store, "rename_src")
+	defer store.DropCollection(ctx, "rename_src", datastore.WithForce())
+	defer store.DropCollection(ctx, "rename_dst", datastore.WithForce())
+	defer store.DropCollection(ctx, "rename_other", datastore.WithForce())
+
+	if _, err := repo.InsertOne(ctx, &User{Name: "Willy"}); err != nil {
+		t.Fatalf("Error on inserting user: %v", err)
+	}
+
+	err := store.DropCollection(ctx, "rename_src")
+	assert.ErrorIs(t, err, datastore.ErrCollectionNotEmpty)
+
+	err = store.RenameCollection(ctx, "does_not_exist", "rename_dst", false)
+	assert.ErrorIs(t, err, datastore.ErrCollectionNotFound)
+
+	if err := store.Database.CreateCollection(ctx, "rename_other"); err != nil {
+		t.Fatalf("Error on creating collection: %v", err)
+	}
+	err = store.RenameCollection(ctx, "rename_src", "rename_other", false)
+	assert.ErrorIs(t, err, datastore.ErrCollectionExists)
+
+	assert.NoError(t, store.RenameCollection(ctx, "rename_src", "rename_dst", false))
+
+	exists, err := store.CollectionExists(ctx, "rename_dst")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	assert.NoError(t, store.DropCollection(ctx, "rename_dst", datastore.WithForce()))
+}
+
+func TestCreateCappedCollectionInvalidSize(t *testing.T) {
+	store, err := datastore.NewDataStore("mongodb://localhost:27017", "testdb", datastore.WithUsePingOption(false))
+	if err != nil {
+		t.Fatalf("Error creating DataStore: %v", err)
+	}
+	defer store.Disconnect()
+
+	ctx := context.Background()
+	assert.ErrorContains(t, store.CreateCappedCollection(ctx, "capped", 0, 0), "not between 1 byte and 1 PB")
+	assert.ErrorContains(t, store.CreateCappedCollection(ctx, "capped", -4096, 0), "not between 1 byte and 1 PB")
+	assert.ErrorContains(t, store.CreateCappedCollection(ctx, "capped", 1<<51, 0), "not between 1 byte and 1 PB")
+	assert.ErrorContains(t, store.CreateCappedCollection(ctx, "capped", 4096, -1), "negative maximum number of documents")
+}
+
+func TestCreateSpecialCollections(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDataStore(t)
+	defer store.DropCollection(ctx, "capped", datastore.WithForce())
+	defer store.DropCollection(ctx, "timeseries", datastore.WithForce())
+
+	assert.NoError(t, store.CreateCappedCollection(ctx, "capped", 4096, 100))
+	assert.NoError(t, store.CreateCappedCollection(ctx, "capped", 4096, 100))
+	// Smaller sizes are raised to the minimum of the server.
+	assert.NoError(t, store.CreateCappedCollection(ctx, "capped", 1000, 100))
+
+	var mismatch *datastore.CollectionMismatchError
+	err := store.CreateCappedCollection(ctx, "capped", 4096, 200)
+	assert.ErrorAs(t, err, &mismatch)
+	assert.ErrorIs(t, err, datastore.ErrCollectionExists)
+
+	assert.NoError(t, store.CreateTimeSeriesCollection(ctx, "timeseries", "timestamp", "meta", "minutes", 24*time.Hour))
+	assert.NoError(t, store.CreateTimeSeriesCollection(ctx, "timeseries", "timestamp", "meta", "minutes", 24*time.Hour))
+
+	err = store.CreateTimeSeriesCollection(ctx, "timeseries", "timestamp", "meta", "hours", 24*time.Hour)
+	assert.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, "granularity", mismatch.Option)
+
+	infos, err := store.ListCollections(ctx, primitive.M{"name": "timeseries"})
+	if err != nil {
+		t.Fatalf("Error on listing collections: %v", err)
+	}
+	assert.Equal(t, datastore.CollectionTypeTimeSeries, infos[0].Type)
+	assert.EqualValues(t, 86400, infos[0].Options["expireAfterSeconds"])
+}
+
+func TestWithTransactionRollback(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDataStore(t)
+	supported, err := store.FeatureSupported(ctx, datastore.FeatureTransactions)
+	if err != nil {
+		t.Fatalf("Error on fetching server info: %v", err)
+	}
+	if !supported {
+		t.Skip("transactions require a replica set")
+	}
+
+	repo := datastore.RepositoryFor[*User](store, "transaction")
+	defer repo.DeleteMany(ctx, primitive.M{})
+
+	errRollback := errors.New("rollback")
+	err = store.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, err := repo.InsertOne(ctx, &User{Name: "Willy"}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+
+	count, err := repo.CountDocuments(ctx, primitive.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	err = store.WithTransaction(ctx, func(ctx context.Context) error {
+		_, err := repo.InsertOne(ctx, &User{Name: "Willy"})
+		return err
+	})
+	assert.NoError(t, err)
+
+	count, err = repo.CountDocuments(ctx, primitive.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestWithSessionSharesTransaction(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDataStore(t)
+	supported, err := store.FeatureSupported(ctx, datastore.FeatureTransactions)
+	if err != nil {
+		t.Fatalf("Error on fetching server info: %v", err)
+	}
+	if !supported {
+		t.Skip("transactions require a replica set")
+	}
+
+	users := datastore.RepositoryFor[*User](store, "session_users")
+	admins := datastore.RepositoryFor[*User](store, "session_admins")
+	defer users.DeleteMany(ctx, primitive.M{})
+	defer admins.DeleteMany(ctx, primitive.M{})
+
+	// The collections must exist, because they can not be created inside a transaction on older servers.
+	store.Database.CreateCollection(ctx, "session_users")
+	store.Database.CreateCollection(ctx, "session_admins")
+
+	session, err := store.NewSession(ctx)
+	if err != nil {
+		t.Fatalf("Error on starting session: %v", err)
+	}
+	defer session.EndSession(ctx)
+
+	if err := session.StartTransaction(); err != nil {
+		t.Fatalf("Error on starting transaction: %v", err)
+	}
+
+	boundUsers := mongodb.WithSession(users, session)
+	boundAdmins := mongodb.WithSession(admins, session)
+
+	inserted, err := boundUsers.InsertOne(ctx, &User{Name: "Willy"})
+	if err != nil {
+		t.Fatalf("Error on inserting user: %v", err)
+	}
+	if _, err := boundAdmins.InsertOne(ctx, &User{Name: "Admin"}); err != nil {
+		t.Fatalf("Error on inserting admin: %v", err)
+	}
+
+	found, err := boundUsers.FindOne(ctx, mongodb.MongoIDFilter(inserted.MongoID))
+	assert.NoError(t, err)
+	assert.Equal(t, "Willy", found.Name)
+
+	count, err := admins.CountDocuments(ctx, primitive.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count, "uncommitted writes must not be visible outside of the session")
+
+	assert.NoError(t, session.CommitTransaction(ctx))
+
+	count, err = admins.CountDocuments(ctx, primitive.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestUnitOfWork(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDataStore(t)
+	supported, err := store.FeatureSupported(ctx, datastore.FeatureTransactions)
+	if err != nil {
+		t.Fatalf("Error on fetching server info: %v", err)
+	}
+	if !supported {
+		t.Skip("transactions require a replica set")
+	}
+
+	users := datastore.RepositoryFor[*User](store, "uow_users")
+	orders := datastore.RepositoryFor[*User](store, "uow_orders")
+	defer users.DeleteMany(ctx, primitive.M{})
+	defer orders.DeleteMany(ctx, primitive.M{})
+	store.Database.CreateCollection(ctx, "uow_users")
+	store.Database.CreateCollection(ctx, "uow_orders")
+
+	run := func(commit bool) {
+		uow, err := datastore.NewUnitOfWork(ctx, store)
+		if err != nil {
+			t.Fatalf("Error on starting unit of work: %v", err)
+		}
+
+		if _, err := datastore.UnitOfWorkRepository[*User](uow, "uow_users").InsertOne(ctx, &User{Name: "Willy"}); err != nil {
+			t.Fatalf("Error on inserting user: %v", err)
+		}
+		if _, err := datastore.Bind(uow, orders).InsertOne(ctx, &User{Name: "Order"}); err != nil {
+			t.Fatalf("Error on inserting order: %v", err)
+		}
+
+		if commit {
+			assert.NoError(t, uow.Commit(ctx))
+		} else {
+			assert.NoError(t, uow.Rollback(ctx))
+		}
+		assert.ErrorIs(t, uow.Commit(ctx), datastore.ErrUnitOfWorkDone)
+	}
+
+	run(false)
+	count, _ := users.CountDocuments(ctx, primitive.M{})
+	assert.Equal(t, 0, count)
+	count, _ = orders.CountDocuments(ctx, primitive.M{})
+	assert.Equal(t, 0, count)
+
+	run(true)
+	count, _ = users.CountDocuments(ctx, primitive.M{})
+	assert.Equal(t, 1, count)
+	count, _ = orders.CountDocuments(ctx, primitive.M{})
+	assert.Equal(t, 1, count)
+}
+
+func TestUnitOfWorkCommitUnknownResult(t *testing.T) {
+	unknown := mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 50, Name: "MaxTimeMSExpired", Message: "commit timed out",
+		Labels: []string{"UnknownTransactionCommitResult"}})
+	// The server info of a replica set, so that transactions are supported.
+	serverInfo := []bson.D{
+		mtest.CreateSuccessResponse(bson.E{Key: "version", Value: "7.0.4"}),
+		mtest.CreateSuccessResponse(bson.E{Key: "isWritablePrimary", Value: true}, bson.E{Key: "setName", Value: "rs0"}),
+		mtest.CreateSuccessResponse(),
+	}
+
+	run := func(mt *mtest.T, commitResponses ...bson.D) (int, error) {
+		store := &datastore.DataStore{Client: mt.Client, Database: mt.DB}
+		mt.AddMockResponses(serverInfo...)
+		uow, err := datastore.NewUnitOfWork(context.Background(), store)
+		if err != nil {
+			mt.Fatalf("Error on starting unit of work: %v", err)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		if _, err := datastore.UnitOfWorkRepository[*User](uow, mt.Coll.Name()).InsertOne(context.Background(), &User{Name: "Willy"}); err != nil {
+			mt.Fatalf("Error on inserting user: %v", err)
+		}
+
+		mt.ClearEvents()
+		mt.AddMockResponses(commitResponses...)
+		err = uow.Commit(context.Background())
+
+		commits := 0
+		for _, event := range mt.GetAllStartedEvents() {
+			if event.CommandName == "commitTransaction" {
+				commits++
+			}
+		}
+		return commits, err
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("retried", func(mt *mtest.T) {
+		commits, err := run(mt, unknown, unknown, mtest.CreateSuccessResponse())
+		assert.NoError(t, err)
+		assert.Equal(t, 3, commits)
+	})
+	mt.Run("unknown", func(mt *mtest.T) {
+		commits, err := run(mt, unknown, unknown, unknown, unknown, unknown)
+		assert.ErrorIs(t, err, datastore.ErrCommitResultUnknown)
+		var cmdErr mongo.CommandError
+		assert.ErrorAs(t, err, &cmdErr)
+		assert.Equal(t, 4, commits)
+	})
+}
+
+func TestUnitOfWorkServerInfoError(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("buildInfo fails", func(mt *mtest.T) {
+		store := &datastore.DataStore{Client: mt.Client, Database: mt.DB}
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 13, Name: "Unauthorized", Message: "not authorized"}))
+
+		_, err := datastore.NewUnitOfWork(context.Background(), store)
+		var cmdErr mongo.CommandError
+		assert.ErrorAs(t, err, &cmdErr)
+		assert.NotErrorIs(t, err, datastore.ErrFeatureNotSupported)
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 13, Name: "Unauthorized", Message: "not authorized"}))
+		err = store.WithTransaction(context.Background(), func(ctx context.Context) error { return nil })
+		assert.ErrorAs(t, err, &cmdErr)
+		assert.NotErrorIs(t, err, datastore.ErrFeatureNotSupported)
+	})
+}
+
+func TestWithCausalConsistency(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDataStore(t)
+
+	repo := datastore.RepositoryFor[*User](store, "causal")
+	defer repo.DeleteMany(ctx, primitive.M{})
+
+	var token datastore.CausalToken
+	err := datastore.WithCausalConsistency(ctx, store, func(ctx context.Context) error {
+		assert.NotNil(t, mongo.SessionFromContext(ctx))
+
+		inserted, err := repo.InsertOne(ctx, &User{Name: "Willy"})
+		if err != nil {
+			return err
+		}
+
+		found, err := repo.FindOne(ctx, mongodb.MongoIDFilter(inserted.MongoID))
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, "Willy", found.Name)
+
+		var ok bool
+		token, ok = datastore.CausalTokenFromContext(ctx)
+		assert.True(t, ok)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	err = datastore.WithCausalConsistency(ctx, store, func(ctx context.Context) error {
+		return datastore.AdvanceClusterTime(ctx, token)
+	})
+	assert.NoError(t, err)
+
+	_, ok := datastore.CausalTokenFromContext(ctx)
+	assert.False(t, ok)
+}
+
+func TestWithTimeoutDiagnostics(t *testing.T) {
+	// No server listens on this port, so the operation times out selecting a server.
+	store, err := datastore.NewDataStore("mongodb://localhost:27099", "testdb", datastore.WithUsePingOption(false), datastore.WithTimeoutDiagnostics())
+	if err != nil {
+		t.Fatalf("Error creating data store: %v", err)
+	}
+	defer store.Disconnect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = datastore.RepositoryFor[*User](store, "diagnostics").FindOne(ctx, primitive.M{})
+	info, ok := mongodb.TimeoutDetails(err)
+	if assert.True(t, ok, err) {
+		assert.True(t, info.WaitingForConnection)
+		assert.Empty(t, info.Address)
+		assert.Zero(t, info.Execution)
+		assert.GreaterOrEqual(t, info.ConnectionWait, 100*time.Millisecond)
+	}
+}
@@ -0,0 +1,144 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// commitRetries is how often Commit retries a commit whose result is unknown.
+const commitRetries = 3
+
+// unknownCommitResultLabel is the error label of commits that may or may not have been applied, e.g. after a network error.
+const unknownCommitResultLabel = "UnknownTransactionCommitResult"
+
+var (
+	// ErrUnitOfWorkDone is returned when a [UnitOfWork] is used after Commit or Rollback.
+	ErrUnitOfWorkDone = errors.New("datastore: unit of work already committed or rolled back")
+
+	// ErrCommitResultUnknown is returned by [UnitOfWork.Commit] if it could not be determined whether the writes were committed.
+	ErrCommitResultUnknown = errors.New("datastore: commit result of the unit of work is unknown")
+)
+
+// UnitOfWork coordinates writes across multiple repositories in a single transaction.
+//
+// Operations are executed eagerly inside an open transaction: every call on a repository obtained from
+// [UnitOfWorkRepository] or [Bind] is sent to the server immediately, returns its own result and error,
+// and sees the uncommitted writes of the other repositories of the same UnitOfWork.
+// Nothing is visible outside of the UnitOfWork until [UnitOfWork.Commit] succeeds.
+//
+// If any operation fails, the server may already have aborted the transaction, so the caller should call
+// [UnitOfWork.Rollback] instead of continuing. A failed Commit also discards all writes.
+// A UnitOfWork can not be reused after Commit or Rollback.
+//
+// A UnitOfWork is not safe for concurrent use. All of its repositories share one session, which must not be used
+// by multiple goroutines at once, so their operations, Commit and Rollback have to be called one after another.
+type UnitOfWork struct {
+	store   *DataStore
+	session mongo.Session
+
+	mu   sync.Mutex
+	done bool
+}
+
+// NewUnitOfWork starts a session and a transaction for a new UnitOfWork.
+//
+// Transactions require a replica set or a sharded cluster, on a standalone server an error wrapping [ErrFeatureNotSupported] is returned.
+// If the server info can not be fetched with ctx, that error is returned instead.
+func NewUnitOfWork(ctx context.Context, store *DataStore, opts ...*options.TransactionOptions) (*UnitOfWork, error) {
+	supported, err := store.FeatureSupported(ctx, FeatureTransactions)
+	if err != nil {
+		return nil, fmt.Errorf("NewUnitOfWork: %w", err)
+	}
+	if !supported {
+		return nil, fmt.Errorf("NewUnitOfWork: %v require a replica set or a sharded cluster: %w", FeatureTransactions, ErrFeatureNotSupported)
+	}
+
+	session, err := store.Client.StartSession()
+	if err != nil {
+		return nil, fmt.Errorf("NewUnitOfWork: %w", err)
+	}
+
+	err = session.StartTransaction(opts...)
+	if err != nil {
+		session.EndSession(ctx)
+		return nil, fmt.Errorf("NewUnitOfWork: %w", err)
+	}
+
+	return &UnitOfWork{
+		store:   store,
+		session: session,
+	}, nil
+}
+
+// UnitOfWorkRepository creates a repository for the given collection of the DataStore, whose operations run inside the UnitOfWork.
+func UnitOfWorkRepository[T mongodb.Document[T]](uow *UnitOfWork, collection string, database ...string) mongodb.RepositoryI[T] {
+	return Bind(uow, RepositoryFor[T](uow.store, collection, database...))
+}
+
+// Bind returns a copy of an existing repository, whose operations run inside the UnitOfWork, see [mongodb.WithSession].
+func Bind[T mongodb.Document[T]](uow *UnitOfWork, repository mongodb.RepositoryI[T]) mongodb.RepositoryI[T] {
+	return mongodb.WithSession(repository, uow.session)
+}
+
+// Commit commits all writes of the UnitOfWork atomically.
+//
+// If the result of the commit is unknown, e.g. after a network error or a failover, the commit is retried,
+// which is safe since the server applies the transaction at most once. If the result is still unknown after the retries
+// or when ctx is done, an error wrapping [ErrCommitResultUnknown] is returned, and the writes may or may not have been committed.
+func (uow *UnitOfWork) Commit(ctx context.Context) error {
+	if err := uow.finish(); err != nil {
+		return err
+	}
+	defer uow.session.EndSession(ctx)
+
+	err := uow.session.CommitTransaction(ctx)
+	for attempt := 0; attempt < commitRetries && unknownCommitResult(err) && ctx.Err() == nil; attempt++ {
+		err = uow.session.CommitTransaction(ctx)
+	}
+	if unknownCommitResult(err) {
+		return fmt.Errorf("UnitOfWork.Commit: %w: %w", ErrCommitResultUnknown, err)
+	}
+	if err != nil {
+		return fmt.Errorf("UnitOfWork.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func unknownCommitResult(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorLabel(unknownCommitResultLabel)
+}
+
+// Rollback discards all writes of the UnitOfWork.
+func (uow *UnitOfWork) Rollback(ctx context.Context) error {
+	if err := uow.finish(); err != nil {
+		return err
+	}
+	defer uow.session.EndSession(ctx)
+
+	err := uow.session.AbortTransaction(ctx)
+	if err != nil {
+		return fmt.Errorf("UnitOfWork.Rollback: %w", err)
+	}
+
+	return nil
+}
+
+func (uow *UnitOfWork) finish() error {
+	uow.mu.Lock()
+	defer uow.mu.Unlock()
+
+	if uow.done {
+		return ErrUnitOfWorkDone
+	}
+	uow.done = true
+
+	return nil
+}
@@ -0,0 +1,281 @@
+package datastore
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	schemeMongoDB    = "mongodb"
+	schemeMongoDBSRV = "mongodb+srv"
+	redactedPassword = "xxxxx"
+)
+
+// URIBuilder assembles MongoDB connection strings, escaping all user-provided parts.
+//
+//	uri, err := datastore.NewURIBuilder().
+//		Hosts("db1:27017", "db2:27017").
+//		Username("app").
+//		Password(password).
+//		ReplicaSet("rs0").
+//		Build()
+//
+// See [https://www.mongodb.com/docs/manual/reference/connection-string/]
+type URIBuilder struct {
+	srv         bool
+	hosts       []string
+	username    string
+	password    string
+	passwordSet bool
+	database    string
+	params      url.Values
+}
+
+// NewURIBuilder creates an empty URIBuilder for the standard mongodb:// scheme.
+func NewURIBuilder() *URIBuilder {
+	return &URIBuilder{params: url.Values{}}
+}
+
+// Hosts appends hosts in the form "host" or "host:port".
+func (b *URIBuilder) Hosts(hosts ...string) *URIBuilder {
+	b.hosts = append(b.hosts, hosts...)
+	return b
+}
+
+func (b *URIBuilder) Username(username string) *URIBuilder {
+	b.username = username
+	return b
+}
+
+func (b *URIBuilder) Password(password string) *URIBuilder {
+	b.password = password
+	b.passwordSet = true
+	return b
+}
+
+// Database sets the default authentication database that is part of the path.
+func (b *URIBuilder) Database(database string) *URIBuilder {
+	b.database = database
+	return b
+}
+
+func (b *URIBuilder) ReplicaSet(name string) *URIBuilder {
+	return b.Param("replicaSet", name)
+}
+
+func (b *URIBuilder) AuthSource(db string) *URIBuilder {
+	return b.Param("authSource", db)
+}
+
+func (b *URIBuilder) TLS(enabled bool) *URIBuilder {
+	return b.Param("tls", strconv.FormatBool(enabled))
+}
+
+// SRV switches to the mongodb+srv:// scheme, which requires exactly one host without a port.
+func (b *URIBuilder) SRV(enabled bool) *URIBuilder {
+	b.srv = enabled
+	return b
+}
+
+// Param sets a connection option. An empty value removes the option.
+func (b *URIBuilder) Param(key, value string) *URIBuilder {
+	if b.params == nil {
+		b.params = url.Values{}
+	}
+
+	if value == "" {
+		b.params.Del(key)
+	} else {
+		b.params.Set(key, value)
+	}
+	return b
+}
+
+// Build validates the configuration and returns the escaped connection string.
+func (b *URIBuilder) Build() (string, error) {
+	return b.build(false)
+}
+
+// String returns the connection string with a redacted password, so it is safe to log.
+// Invalid configurations are formatted anyway.
+func (b *URIBuilder) String() string {
+	uri, err := b.build(true)
+	if err != nil {
+		return fmt.Sprintf("invalid URI: %v", err)
+	}
+
+	return uri
+}
+
+func (b *URIBuilder) build(redact bool) (string, error) {
+	if len(b.hosts) == 0 {
+		return "", errors.New("URIBuilder: at least one host is required")
+	}
+
+	for _, host := range b.hosts {
+		if host == "" || strings.ContainsAny(host, "/?@,") {
+			return "", fmt.Errorf("URIBuilder: invalid host %q", host)
+		}
+	}
+
+	scheme := schemeMongoDB
+	if b.srv {
+		scheme = schemeMongoDBSRV
+		if len(b.hosts) != 1 {
+			return "", errors.New("URIBuilder: SRV URIs require exactly one host")
+		}
+		if strings.Contains(b.hosts[0], ":") {
+			return "", errors.New("URIBuilder: SRV URIs must not contain a port")
+		}
+	}
+
+	if b.passwordSet && b.username == "" {
+		return "", errors.New("URIBuilder: a password requires a username")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(scheme)
+	sb.WriteString("://")
+
+	if b.username != "" {
+		sb.WriteString(escapeUserInfo(b.username))
+		if b.passwordSet {
+			sb.WriteByte(':')
+			if redact {
+				sb.WriteString(redactedPassword)
+			} else {
+				sb.WriteString(escapeUserInfo(b.password))
+			}
+		}
+		sb.WriteByte('@')
+	}
+
+	sb.WriteString(strings.Join(b.hosts, ","))
+	sb.WriteByte('/')
+	sb.WriteString(url.PathEscape(b.database))
+
+	if len(b.params) > 0 {
+		sb.WriteByte('?')
+		sb.WriteString(encodeParams(b.params))
+	}
+
+	return sb.String(), nil
+}
+
+// ParseURI parses a connection string into a URIBuilder, so it can be modified and rebuilt.
+//
+// Unlike the driver, it does not resolve SRV records.
+func ParseURI(uri string) (*URIBuilder, error) {
+	b := NewURIBuilder()
+
+	switch {
+	case strings.HasPrefix(uri, schemeMongoDBSRV+"://"):
+		b.srv = true
+		uri = uri[len(schemeMongoDBSRV)+3:]
+	case strings.HasPrefix(uri, schemeMongoDB+"://"):
+		uri = uri[len(schemeMongoDB)+3:]
+	default:
+		return nil, fmt.Errorf("ParseURI: scheme must be %q or %q", schemeMongoDB, schemeMongoDBSRV)
+	}
+
+	// User info is only part of the authority, an @ in the database or the options is not a separator.
+	authority := uri
+	if idx := strings.IndexAny(uri, "/?"); idx != -1 {
+		authority = uri[:idx]
+	}
+	if idx := strings.LastIndex(authority, "@"); idx != -1 {
+		userInfo := uri[:idx]
+		uri = uri[idx+1:]
+
+		username := userInfo
+		if idx := strings.Index(userInfo, ":"); idx != -1 {
+			username = userInfo[:idx]
+
+			password, err := url.PathUnescape(userInfo[idx+1:])
+			if err != nil {
+				return nil, fmt.Errorf("ParseURI: invalid password: %w", err)
+			}
+			b.Password(password)
+		}
+
+		var err error
+		b.username, err = url.PathUnescape(username)
+		if err != nil {
+			return nil, fmt.Errorf("ParseURI: invalid username: %w", err)
+		}
+	}
+
+	hosts := uri
+	rest := ""
+	if idx := strings.IndexAny(uri, "/?"); idx != -1 {
+		hosts = uri[:idx]
+		rest = uri[idx:]
+	}
+	if hosts == "" {
+		return nil, errors.New("ParseURI: at least one host is required")
+	}
+	b.hosts = strings.Split(hosts, ",")
+
+	rest = strings.TrimPrefix(rest, "/")
+	path := rest
+	query := ""
+	if idx := strings.Index(rest, "?"); idx != -1 {
+		path = rest[:idx]
+		query = rest[idx+1:]
+	}
+
+	database, err := url.PathUnescape(path)
+	if err != nil {
+		return nil, fmt.Errorf("ParseURI: invalid database: %w", err)
+	}
+	b.database = database
+
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("ParseURI: invalid options: %w", err)
+	}
+	b.params = params
+
+	return b, nil
+}
+
+// escapeUserInfo percent-encodes everything except the unreserved characters of RFC 3986.
+func escapeUserInfo(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || strings.IndexByte("-._~", c) >= 0 {
+			sb.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&sb, "%%%02X", c)
+	}
+
+	return sb.String()
+}
+
+// encodeParams encodes the options sorted by key, with spaces as %20 because the driver does not treat + as a space everywhere.
+func encodeParams(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		for _, value := range params[key] {
+			parts = append(parts, escapeParam(key)+"="+escapeParam(value))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+func escapeParam(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,249 @@
+// Package filestore stores files in GridFS, with an API consistent with the repositories of the mongodb package.
+package filestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// FileRepository stores files in a GridFS bucket.
+	//
+	// The driver's buckets only support deadlines, so the deadline of the context is applied to every operation,
+	// but a context without a deadline cannot cancel an operation that already started.
+	FileRepository struct {
+		db   *mongo.Database
+		opts []*options.BucketOptions
+	}
+
+	// FileInfo is the entry of a file in the files collection of the bucket.
+	FileInfo struct {
+		ID         primitive.ObjectID `bson:"_id"`
+		Name       string             `bson:"filename"`
+		Length     int64              `bson:"length"`
+		ChunkSize  int32              `bson:"chunkSize"`
+		UploadDate time.Time          `bson:"uploadDate"`
+		Metadata   bson.M             `bson:"metadata,omitempty"`
+	}
+)
+
+// fileFields are the top-level fields of the files collection, which are not prefixed by Find.
+var fileFields = map[string]bool{
+	"_id":        true,
+	"filename":   true,
+	"length":     true,
+	"chunkSize":  true,
+	"uploadDate": true,
+	"metadata":   true,
+}
+
+// NewFileRepository creates a FileRepository for the GridFS bucket in db, the default bucket is named "fs".
+//
+// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo/gridfs#NewBucket]
+func NewFileRepository(db *mongo.Database, opts ...*options.BucketOptions) (*FileRepository, error) {
+	// The bucket is only created to validate the options, every operation uses its own bucket for its deadline.
+	if _, err := gridfs.NewBucket(db, opts...); err != nil {
+		return nil, fmt.Errorf("%v: %w", "filestore.NewFileRepository", err)
+	}
+
+	return &FileRepository{db: db, opts: opts}, nil
+}
+
+func (r *FileRepository) bucket(ctx context.Context) (*gridfs.Bucket, error) {
+	bucket, err := gridfs.NewBucket(r.db, r.opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := bucket.SetReadDeadline(deadline); err != nil {
+			return nil, err
+		}
+		if err := bucket.SetWriteDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	return bucket, nil
+}
+
+// Upload stores the content of rd as a new file with the given name and metadata, and returns its id.
+//
+// To scope files to a company, store the companyID in the metadata, e.g. mongodb.CompanyIDFilter(companyID),
+// and pass the same filter to [FileRepository.Find].
+func (r *FileRepository) Upload(ctx context.Context, name string, rd io.Reader, metadata bson.M) (primitive.ObjectID, error) {
+	bucket, err := r.bucket(ctx)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("%v: %w", "filestore.FileRepository.Upload", err)
+	}
+
+	opts := options.GridFSUpload()
+	if metadata != nil {
+		opts.SetMetadata(metadata)
+	}
+
+	id, err := bucket.UploadFromStream(name, contextReader{ctx: ctx, rd: rd}, opts)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("%v: %v: %w", "filestore.FileRepository.Upload", name, err)
+	}
+
+	return id, nil
+}
+
+// Download writes the content of the file with the given id to w.
+//
+// If there is no such file, an error wrapping [mongodb.ErrNotFound] is returned.
+func (r *FileRepository) Download(ctx context.Context, id primitive.ObjectID, w io.Writer) error {
+	rc, err := r.openDownload(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%v: %w", "filestore.FileRepository.Download", err)
+	}
+	defer rc.Close()
+
+	if _, err := io.Copy(w, contextReader{ctx: ctx, rd: rc}); err != nil {
+		return fmt.Errorf("%v: %v: %w", "filestore.FileRepository.Download", id.Hex(), err)
+	}
+
+	return nil
+}
+
+// OpenDownload opens the file with the given id for streaming. The caller must close the returned reader.
+//
+// If there is no such file, an error wrapping [mongodb.ErrNotFound] is returned.
+func (r *FileRepository) OpenDownload(ctx context.Context, id primitive.ObjectID) (io.ReadCloser, error) {
+	rc, err := r.openDownload(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "filestore.FileRepository.OpenDownload", err)
+	}
+
+	return rc, nil
+}
+
+func (r *FileRepository) openDownload(ctx context.Context, id primitive.ObjectID) (*gridfs.DownloadStream, error) {
+	bucket, err := r.bucket(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := bucket.OpenDownloadStream(id)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return nil, fmt.Errorf("%v: %w", id.Hex(), mongodb.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", id.Hex(), err)
+	}
+
+	return stream, nil
+}
+
+// Delete deletes the file with the given id and all its chunks.
+//
+// If there is no such file, an error wrapping [mongodb.ErrNotFound] is returned.
+func (r *FileRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	bucket, err := r.bucket(ctx)
+	if err != nil {
+		return fmt.Errorf("%v: %w", "filestore.FileRepository.Delete", err)
+	}
+
+	err = bucket.DeleteContext(ctx, id)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return fmt.Errorf("%v: %v: %w", "filestore.FileRepository.Delete", id.Hex(), mongodb.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("%v: %v: %w", "filestore.FileRepository.Delete", id.Hex(), err)
+	}
+
+	return nil
+}
+
+// Find returns the files matching filter, ordered by upload date.
+//
+// Fields of filter other than the fields of [FileInfo] are matched against the metadata, unless they already start with "metadata",
+// so filters of the mongodb package, e.g. mongodb.CompanyIDFilter(companyID), match the metadata passed to [FileRepository.Upload].
+//
+//	files, err := repo.Find(ctx, mongodb.NewFilter(mongodb.WithCompanyID(companyID)))
+func (r *FileRepository) Find(ctx context.Context, filter bson.M) ([]FileInfo, error) {
+	bucket, err := r.bucket(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "filestore.FileRepository.Find", err)
+	}
+
+	cur, err := bucket.FindContext(ctx, metadataFilter(filter), options.GridFSFind().SetSort(bson.D{{Key: "uploadDate", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "filestore.FileRepository.Find", err)
+	}
+
+	res := []FileInfo{}
+	if err := cur.All(ctx, &res); err != nil {
+		return nil, fmt.Errorf("%v: %w", "filestore.FileRepository.Find", err)
+	}
+
+	return res, nil
+}
+
+// metadataFilter prefixes the fields of filter that are not fields of the files collection with "metadata.",
+// descending into the $and, $or and $nor operators.
+func metadataFilter(filter bson.M) bson.M {
+	res := bson.M{}
+	for key, value := range filter {
+		switch {
+		case key == "$and" || key == "$or" || key == "$nor":
+			res[key] = metadataFilters(value)
+		case strings.HasPrefix(key, "$") || strings.HasPrefix(key, "metadata.") || fileFields[key]:
+			res[key] = value
+		default:
+			res["metadata."+key] = value
+		}
+	}
+	return res
+}
+
+func metadataFilters(value interface{}) interface{} {
+	var filters []bson.M
+	switch v := value.(type) {
+	case []bson.M:
+		filters = v
+	case bson.A:
+		for _, f := range v {
+			m, ok := f.(bson.M)
+			if !ok {
+				return value
+			}
+			filters = append(filters, m)
+		}
+	case []interface{}:
+		return metadataFilters(bson.A(v))
+	default:
+		return value
+	}
+
+	res := make(bson.A, len(filters))
+	for i, f := range filters {
+		res[i] = metadataFilter(f)
+	}
+	return res
+}
+
+// contextReader stops reading once the context is done.
+type contextReader struct {
+	ctx context.Context
+	rd  io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.rd.Read(p)
+}
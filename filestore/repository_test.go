@@ -0,0 +1,95 @@
+package filestore_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/filestore"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func testFileRepository(t *testing.T, bucket string) *filestore.FileRepository {
+	t.Helper()
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+
+	db := client.Database("testdb")
+	t.Cleanup(func() {
+		db.Collection(bucket + ".files").Drop(ctx)
+		db.Collection(bucket + ".chunks").Drop(ctx)
+		client.Disconnect(ctx)
+	})
+
+	repo, err := filestore.NewFileRepository(db, options.GridFSBucket().SetName(bucket).SetChunkSizeBytes(1024))
+	if err != nil {
+		t.Fatalf("Error creating FileRepository: %v", err)
+	}
+
+	return repo
+}
+
+func TestFileRepositoryRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repo := testFileRepository(t, "report_roundtrip")
+	companyID := primitive.NewObjectID()
+
+	content := make([]byte, 5*1024+123)
+	if _, err := rand.Read(content); err != nil {
+		t.Fatalf("Error generating content: %v", err)
+	}
+
+	id, err := repo.Upload(ctx, "report.pdf", bytes.NewReader(content), bson.M{"companyID": companyID, "kind": "report"})
+	if err != nil {
+		t.Fatalf("Error on uploading: %v", err)
+	}
+	if _, err := repo.Upload(ctx, "other.csv", bytes.NewReader([]byte("a,b")), bson.M{"companyID": primitive.NewObjectID()}); err != nil {
+		t.Fatalf("Error on uploading: %v", err)
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, repo.Download(ctx, id, &buf))
+	assert.Equal(t, content, buf.Bytes())
+
+	rc, err := repo.OpenDownload(ctx, id)
+	if err != nil {
+		t.Fatalf("Error on opening download: %v", err)
+	}
+	streamed, err := io.ReadAll(rc)
+	assert.NoError(t, err)
+	assert.NoError(t, rc.Close())
+	assert.Equal(t, content, streamed)
+
+	files, err := repo.Find(ctx, mongodb.NewFilter(mongodb.WithCompanyID(companyID)))
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, id, files[0].ID)
+		assert.Equal(t, "report.pdf", files[0].Name)
+		assert.Equal(t, int64(len(content)), files[0].Length)
+		assert.Equal(t, "report", files[0].Metadata["kind"])
+	}
+
+	files, err = repo.Find(ctx, bson.M{"filename": "other.csv"})
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	// Fields that already address the metadata are not prefixed again.
+	files, err = repo.Find(ctx, bson.M{"metadata.kind": "report", "metadata": bson.M{"$exists": true}})
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	assert.NoError(t, repo.Delete(ctx, id))
+	assert.ErrorIs(t, repo.Download(ctx, id, io.Discard), mongodb.ErrNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, id), mongodb.ErrNotFound)
+}
@@ -2,12 +2,15 @@ module github.com/DataInsightHub/Go-Mongo-Helper
 
 go 1.18
 
-require go.mongodb.org/mongo-driver v1.14.0
+require (
+	github.com/stretchr/testify v1.9.0
+	go.mongodb.org/mongo-driver v1.14.0
+	go.uber.org/goleak v1.3.0
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/testify v1.9.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
@@ -21,6 +24,6 @@ require (
 	github.com/xdg-go/stringprep v1.0.4 // indirect
 	github.com/youmark/pkcs8 v0.0.0-20181117223130-1be2e3e5546d // indirect
 	golang.org/x/crypto v0.17.0 // indirect
-	golang.org/x/sync v0.1.0 // indirect
+	golang.org/x/sync v0.1.0
 	golang.org/x/text v0.14.0 // indirect
 )
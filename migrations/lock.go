@@ -0,0 +1,136 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	lockCollection = "schema_migrations_lock"
+	lockID         = "migrations"
+	// lockPollInterval is the interval in which a waiting runner retries to acquire the lock.
+	lockPollInterval = 500 * time.Millisecond
+)
+
+type (
+	lockDocument struct {
+		ID        string    `bson:"_id"`
+		Owner     string    `bson:"owner"`
+		ExpiresAt time.Time `bson:"expiresAt"`
+	}
+
+	// lockLoss cancels the context of the running migrations when the lock is lost, and keeps the reason.
+	lockLoss struct {
+		cancel context.CancelFunc
+
+		mu  sync.Mutex
+		err error
+	}
+)
+
+// acquireLock takes the lock if it is free, expired or already held by this runner.
+// It returns ErrLocked if another runner holds the lock.
+func (r *Runner) acquireLock(ctx context.Context) error {
+	col := r.db.Collection(lockCollection)
+
+	// The TTL index removes locks of crashed runners, expired locks are taken over regardless.
+	_, err := col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = r.tryLock(ctx)
+	deadline := time.Now().Add(r.ops.lockWait)
+	for err == ErrLocked && time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+		err = r.tryLock(ctx)
+	}
+	return err
+}
+
+func (r *Runner) tryLock(ctx context.Context) error {
+	now := time.Now()
+	filter := bson.M{
+		"_id": lockID,
+		"$or": bson.A{
+			bson.M{"owner": r.owner},
+			bson.M{"expiresAt": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"owner": r.owner, "expiresAt": now.Add(r.ops.lockTTL)}}
+
+	// If another runner holds the lock, the filter does not match and the upsert fails with a duplicate _id.
+	_, err := r.db.Collection(lockCollection).UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrLocked
+	}
+	return err
+}
+
+// heartbeat extends the lock until ctx is done. If the lock was taken over by another runner, or could not be
+// extended before it may expire, heartbeat calls lost with an error wrapping [ErrLockLost] and returns.
+func (r *Runner) heartbeat(ctx context.Context, lost func(err error)) {
+	interval := r.ops.lockTTL / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	expiresAt := time.Now().Add(r.ops.lockTTL)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			next := time.Now().Add(r.ops.lockTTL)
+			res, err := r.db.Collection(lockCollection).UpdateOne(ctx,
+				bson.M{"_id": lockID, "owner": r.owner},
+				bson.M{"$set": bson.M{"expiresAt": next}},
+			)
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err == nil && res.MatchedCount == 0:
+				lost(fmt.Errorf("%w: taken over by another runner", ErrLockLost))
+				return
+			case err == nil:
+				expiresAt = next
+			case time.Now().Add(interval).After(expiresAt):
+				// The lock may expire before the next attempt, so another runner could take it over.
+				lost(fmt.Errorf("%w: extending the lock: %v", ErrLockLost, err))
+				return
+			}
+		}
+	}
+}
+
+func (r *Runner) releaseLock(ctx context.Context) error {
+	_, err := r.db.Collection(lockCollection).DeleteOne(ctx, bson.M{"_id": lockID, "owner": r.owner})
+	return err
+}
+
+// lose records err as the reason the lock was lost, and cancels the context.
+func (l *lockLoss) lose(err error) {
+	l.mu.Lock()
+	l.err = err
+	l.mu.Unlock()
+	l.cancel()
+}
+
+// Err returns the reason the lock was lost, or nil if it is still held.
+func (l *lockLoss) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
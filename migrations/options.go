@@ -0,0 +1,55 @@
+package migrations
+
+import "time"
+
+type (
+	// RunnerOption configures a [Runner], see [NewRunner].
+	RunnerOption interface {
+		apply(*runnerOption)
+	}
+)
+
+type (
+	runnerOption struct {
+		database string
+		lockTTL  time.Duration
+		lockWait time.Duration
+	}
+)
+
+type databaseOption string
+
+func (value databaseOption) apply(o *runnerOption) {
+	o.database = string(value)
+}
+
+// WithDatabase runs the migrations against the database with the given name instead of the default database of the DataStore.
+func WithDatabase(name string) RunnerOption {
+	return databaseOption(name)
+}
+
+type lockTTLOption time.Duration
+
+func (value lockTTLOption) apply(o *runnerOption) {
+	if value > 0 {
+		o.lockTTL = time.Duration(value)
+	}
+}
+
+// WithLockTTL sets how long the lock is held without a heartbeat, the default is one minute.
+// A runner that crashed blocks other runners for at most this duration.
+func WithLockTTL(ttl time.Duration) RunnerOption {
+	return lockTTLOption(ttl)
+}
+
+type lockWaitOption time.Duration
+
+func (value lockWaitOption) apply(o *runnerOption) {
+	o.lockWait = time.Duration(value)
+}
+
+// WithLockWait makes [Runner.Run] wait up to the given duration for a lock held by another runner,
+// instead of returning [ErrLocked] immediately.
+func WithLockWait(wait time.Duration) RunnerOption {
+	return lockWaitOption(wait)
+}
@@ -0,0 +1,241 @@
+// Package migrations runs versioned data migrations once per database.
+//
+// Applied versions are recorded in the schema_migrations collection. A lock document ensures that only one
+// [Runner] applies migrations at a time, even when every replica of a service runs them on startup.
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const migrationsCollection = "schema_migrations"
+
+var (
+	// ErrLocked is returned by [Runner.Run] when another runner currently applies migrations.
+	ErrLocked = errors.New("migrations: locked by another runner")
+	// ErrDuplicateVersion is returned by [Runner.Register] when a version is registered twice.
+	ErrDuplicateVersion = errors.New("migrations: version already registered")
+	// ErrLockLost is returned by [Runner.Run] when the lock could not be extended while migrations were applied,
+	// so another runner may apply them at the same time.
+	ErrLockLost = errors.New("migrations: lock lost")
+)
+
+type (
+	// MigrationFunc applies a migration to db.
+	MigrationFunc func(ctx context.Context, db *mongo.Database) error
+
+	// Runner applies registered migrations in the order of their versions.
+	Runner struct {
+		db         *mongo.Database
+		ops        *runnerOption
+		owner      string
+		migrations []migration
+	}
+
+	// Status describes a registered or recorded migration, see [Runner.Status].
+	Status struct {
+		Version int
+		Name    string
+		// Applied reports whether the migration ran successfully.
+		Applied bool
+		// AppliedAt is the time of the last run, the zero time if it never ran.
+		AppliedAt time.Time
+		// Duration is the duration of the last run.
+		Duration time.Duration
+		// Error is the error of the last run, if it failed.
+		Error string
+	}
+)
+
+type (
+	migration struct {
+		version int
+		name    string
+		up      MigrationFunc
+	}
+
+	migrationRecord struct {
+		Version    int       `bson:"_id"`
+		Name       string    `bson:"name"`
+		Applied    bool      `bson:"applied"`
+		AppliedAt  time.Time `bson:"appliedAt"`
+		DurationMS int64     `bson:"durationMs"`
+		Error      string    `bson:"error,omitempty"`
+	}
+)
+
+// NewRunner creates a Runner for the default database of store.
+func NewRunner(store *datastore.DataStore, opts ...RunnerOption) *Runner {
+	ops := &runnerOption{lockTTL: time.Minute}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+
+	db := store.Database
+	if ops.database != "" {
+		db = store.DatabaseFor(ops.database)
+	}
+
+	return &Runner{
+		db:    db,
+		ops:   ops,
+		owner: primitive.NewObjectID().Hex(),
+	}
+}
+
+// Register adds a migration. Migrations run in ascending order of version, regardless of the order they are registered.
+func (r *Runner) Register(version int, name string, up MigrationFunc) error {
+	for _, m := range r.migrations {
+		if m.version == version {
+			return fmt.Errorf("%w: %d (%v)", ErrDuplicateVersion, version, m.name)
+		}
+	}
+
+	r.migrations = append(r.migrations, migration{version: version, name: name, up: up})
+	sort.Slice(r.migrations, func(i, j int) bool { return r.migrations[i].version < r.migrations[j].version })
+	return nil
+}
+
+// Run applies all pending migrations in order.
+//
+// A migration that fails is recorded with its error, and Run stops without applying later migrations.
+// The failed migration is retried by the next Run. If another runner holds the lock, Run returns [ErrLocked].
+//
+// If the lock is lost while migrations are applied, the context passed to the running migration is canceled,
+// and Run stops with an error wrapping [ErrLockLost].
+func (r *Runner) Run(ctx context.Context) (err error) {
+	if err := r.acquireLock(ctx); err != nil {
+		return fmt.Errorf("migrations.Run: %w", err)
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	lost := &lockLoss{cancel: cancel}
+	heartbeatCtx, stopHeartbeat := context.WithCancel(ctx)
+	go r.heartbeat(heartbeatCtx, lost.lose)
+	defer func() {
+		stopHeartbeat()
+		cancel()
+		if releaseErr := r.releaseLock(context.Background()); releaseErr != nil && err == nil {
+			err = fmt.Errorf("migrations.Run: releasing lock: %w", releaseErr)
+		}
+	}()
+
+	applied, err := r.records(ctx)
+	if err != nil {
+		return fmt.Errorf("migrations.Run: %w", err)
+	}
+
+	for _, m := range r.migrations {
+		if record, ok := applied[m.version]; ok && record.Applied {
+			continue
+		}
+
+		started := time.Now()
+		runErr := m.up(ctx, r.db)
+		record := migrationRecord{
+			Version:    m.version,
+			Name:       m.name,
+			Applied:    runErr == nil,
+			AppliedAt:  started,
+			DurationMS: time.Since(started).Milliseconds(),
+		}
+		if runErr != nil {
+			record.Error = runErr.Error()
+		}
+
+		// The outcome is recorded even if the lock was lost in the meantime, since the migration ran regardless.
+		_, err := r.db.Collection(migrationsCollection).ReplaceOne(parent, bson.M{"_id": m.version}, record, options.Replace().SetUpsert(true))
+		if err != nil {
+			return fmt.Errorf("migrations.Run: recording %d (%v): %w", m.version, m.name, err)
+		}
+		if lostErr := lost.Err(); lostErr != nil {
+			return fmt.Errorf("migrations.Run: %d (%v): %w", m.version, m.name, lostErr)
+		}
+		if runErr != nil {
+			return fmt.Errorf("migrations.Run: %d (%v): %w", m.version, m.name, runErr)
+		}
+	}
+
+	return nil
+}
+
+// Status lists all registered migrations and all recorded migrations that are no longer registered, ordered by version.
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	records, err := r.records(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrations.Status: %w", err)
+	}
+
+	res := make([]Status, 0, len(r.migrations))
+	for _, m := range r.migrations {
+		status := Status{Version: m.version, Name: m.name}
+		if record, ok := records[m.version]; ok {
+			status = record.status()
+			status.Name = m.name
+			delete(records, m.version)
+		}
+		res = append(res, status)
+	}
+	for _, record := range records {
+		res = append(res, record.status())
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+	return res, nil
+}
+
+// Pending returns the versions of all registered migrations that were not applied yet.
+func (r *Runner) Pending(ctx context.Context) ([]int, error) {
+	records, err := r.records(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrations.Pending: %w", err)
+	}
+
+	var res []int
+	for _, m := range r.migrations {
+		if record, ok := records[m.version]; !ok || !record.Applied {
+			res = append(res, m.version)
+		}
+	}
+	return res, nil
+}
+
+func (r *Runner) records(ctx context.Context) (map[int]migrationRecord, error) {
+	cur, err := r.db.Collection(migrationsCollection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	var records []migrationRecord
+	if err := cur.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	res := make(map[int]migrationRecord, len(records))
+	for _, record := range records {
+		res[record.Version] = record
+	}
+	return res, nil
+}
+
+func (m migrationRecord) status() Status {
+	return Status{
+		Version:   m.Version,
+		Name:      m.Name,
+		Applied:   m.Applied,
+		AppliedAt: m.AppliedAt,
+		Duration:  time.Duration(m.DurationMS) * time.Millisecond,
+		Error:     m.Error,
+	}
+}
@@ -0,0 +1,190 @@
+package migrations_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/DataInsightHub/Go-Mongo-Helper/migrations"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func newTestRunner(t *testing.T, opts ...migrations.RunnerOption) *migrations.Runner {
+	t.Helper()
+
+	store, err := datastore.NewDataStore("mongodb://localhost:27017", "testdb_migrations")
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	t.Cleanup(func() {
+		store.Database.Drop(context.Background())
+		store.Disconnect()
+	})
+
+	return migrations.NewRunner(store, opts...)
+}
+
+func TestRegisterDuplicateVersion(t *testing.T) {
+	// Without ping no server is needed to create the runner.
+	store, err := datastore.NewDataStore("mongodb://localhost:27017", "testdb_migrations", datastore.WithUsePingOption(false))
+	if err != nil {
+		t.Fatalf("Error creating DataStore: %v", err)
+	}
+	defer store.Disconnect()
+
+	runner := migrations.NewRunner(store)
+	noop := func(ctx context.Context, db *mongo.Database) error { return nil }
+
+	assert.NoError(t, runner.Register(1, "first", noop))
+	assert.ErrorIs(t, runner.Register(1, "again", noop), migrations.ErrDuplicateVersion)
+}
+
+func TestRunOrderAndRerun(t *testing.T) {
+	ctx := context.Background()
+	runner := newTestRunner(t)
+
+	var order []int
+	record := func(version int) migrations.MigrationFunc {
+		return func(ctx context.Context, db *mongo.Database) error {
+			order = append(order, version)
+			return nil
+		}
+	}
+	runner.Register(3, "third", record(3))
+	runner.Register(1, "first", record(1))
+	runner.Register(2, "second", record(2))
+
+	if err := runner.Run(ctx); err != nil {
+		t.Fatalf("Error on running migrations: %v", err)
+	}
+	assert.Equal(t, []int{1, 2, 3}, order)
+
+	// A second run does not apply anything.
+	if err := runner.Run(ctx); err != nil {
+		t.Fatalf("Error on re-running migrations: %v", err)
+	}
+	assert.Equal(t, []int{1, 2, 3}, order)
+
+	status, err := runner.Status(ctx)
+	if err != nil {
+		t.Fatalf("Error on getting status: %v", err)
+	}
+	if assert.Len(t, status, 3) {
+		assert.Equal(t, "first", status[0].Name)
+		assert.True(t, status[2].Applied)
+	}
+}
+
+func TestRunFailureIsRecorded(t *testing.T) {
+	ctx := context.Background()
+	runner := newTestRunner(t)
+
+	fail := true
+	runner.Register(1, "flaky", func(ctx context.Context, db *mongo.Database) error {
+		if fail {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	runner.Register(2, "after", func(ctx context.Context, db *mongo.Database) error { return nil })
+
+	assert.Error(t, runner.Run(ctx))
+
+	pending, err := runner.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, pending)
+
+	status, err := runner.Status(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "boom", status[0].Error)
+
+	fail = false
+	assert.NoError(t, runner.Run(ctx))
+
+	pending, err = runner.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestRunLock(t *testing.T) {
+	ctx := context.Background()
+	first := newTestRunner(t)
+	second := newTestRunner(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	first.Register(1, "slow", func(ctx context.Context, db *mongo.Database) error {
+		close(started)
+		<-release
+		return nil
+	})
+	second.Register(1, "slow", func(ctx context.Context, db *mongo.Database) error { return nil })
+
+	done := make(chan error)
+	go func() { done <- first.Run(ctx) }()
+	<-started
+
+	assert.ErrorIs(t, second.Run(ctx), migrations.ErrLocked)
+
+	close(release)
+	assert.NoError(t, <-done)
+
+	// Once released, the lock can be taken again.
+	assert.NoError(t, second.Run(ctx))
+}
+
+func TestRunLockWait(t *testing.T) {
+	ctx := context.Background()
+	first := newTestRunner(t)
+	second := newTestRunner(t, migrations.WithLockWait(10*time.Second))
+
+	started := make(chan struct{})
+	first.Register(1, "slow", func(ctx context.Context, db *mongo.Database) error {
+		close(started)
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	done := make(chan error)
+	go func() { done <- first.Run(ctx) }()
+	<-started
+
+	assert.NoError(t, second.Run(ctx))
+	assert.NoError(t, <-done)
+}
+
+func TestRunLockLost(t *testing.T) {
+	ctx := context.Background()
+	runner := newTestRunner(t, migrations.WithLockTTL(300*time.Millisecond))
+
+	runner.Register(1, "slow", func(ctx context.Context, db *mongo.Database) error {
+		// Another runner takes over the lock, e.g. after this one was paused for longer than the TTL.
+		_, err := db.Collection("schema_migrations_lock").UpdateOne(ctx,
+			bson.M{"_id": "migrations"}, bson.M{"$set": bson.M{"owner": "other"}})
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return errors.New("the context was not canceled after the lock was lost")
+		}
+	})
+	runner.Register(2, "never", func(ctx context.Context, db *mongo.Database) error {
+		t.Error("migration 2 must not run after the lock was lost")
+		return nil
+	})
+
+	err := runner.Run(ctx)
+	assert.ErrorIs(t, err, migrations.ErrLockLost)
+
+	pending, err := runner.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, pending)
+}
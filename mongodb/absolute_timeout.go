@@ -0,0 +1,74 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrAbsoluteTimeout is matched by errors of operations that were canceled by the timeout of [WithAbsoluteTimeout].
+var ErrAbsoluteTimeout = errors.New("mongodb: repository absolute timeout")
+
+type (
+	// AbsoluteTimeoutError is returned by operations whose context had no deadline and that ran into the timeout of [WithAbsoluteTimeout].
+	// It matches [ErrAbsoluteTimeout] with errors.Is and unwraps to the error of the operation.
+	//
+	// The message names the operation, so that the call sites without a deadline can be found in the logs.
+	AbsoluteTimeoutError struct {
+		// Operation is the name of the repository method, e.g. "FindOne".
+		Operation  string
+		Collection string
+		Timeout    time.Duration
+		err        error
+	}
+)
+
+func (e *AbsoluteTimeoutError) Error() string {
+	return fmt.Sprintf("mongodb: %v on %v: no caller deadline, repository absolute timeout of %v applied: %v", e.Operation, e.Collection, e.Timeout, e.err)
+}
+
+func (e *AbsoluteTimeoutError) Unwrap() error {
+	return e.err
+}
+
+func (e *AbsoluteTimeoutError) Is(target error) bool {
+	return target == ErrAbsoluteTimeout
+}
+
+type absoluteTimeoutOption time.Duration
+
+func (value absoluteTimeoutOption) apply(o *repositoryOption) {
+	o.absoluteTimeout = time.Duration(value)
+}
+
+// WithAbsoluteTimeout sets a timeout for every operation whose context has no deadline, as a safety net for callers
+// that pass context.Background(). Contexts with a deadline, even a later one, are left untouched.
+// A timeout <= 0 disables it, which is the default.
+//
+// Operations that return a cursor or a change stream are only limited while they open it.
+// Errors caused by the timeout are an [*AbsoluteTimeoutError].
+func WithAbsoluteTimeout(d time.Duration) RepositoryOption {
+	return absoluteTimeoutOption(d)
+}
+
+// withAbsoluteTimeout applies the absolute timeout to ctx if it has no deadline.
+// The returned function releases the timeout, and must be called with the result of the operation.
+func (r *Repository[T]) withAbsoluteTimeout(ctx context.Context, name string) (context.Context, func(error) error) {
+	if r.absoluteTimeout <= 0 {
+		return ctx, noopFinish
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, noopFinish
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.absoluteTimeout)
+	return ctx, func(err error) error {
+		defer cancel()
+
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return &AbsoluteTimeoutError{Operation: name, Collection: r.db.Name(), Timeout: r.absoluteTimeout, err: err}
+		}
+		return err
+	}
+}
@@ -0,0 +1,84 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// hangingHook simulates a hung operation by blocking until the context of the operation is done.
+type hangingHook struct {
+	deadline time.Time
+}
+
+func (h *hangingHook) Before(ctx context.Context, op *mongodb.Operation) (context.Context, error) {
+	h.deadline, _ = ctx.Deadline()
+	<-ctx.Done()
+	return ctx, ctx.Err()
+}
+
+func (h *hangingHook) After(ctx context.Context, op *mongodb.Operation, err error) error {
+	return err
+}
+
+func TestAbsoluteTimeout(t *testing.T) {
+	ctx := context.Background()
+	// Connect does not need a server, the hook rejects the operation before it is sent.
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	defer client.Disconnect(ctx)
+	col := client.Database("testdb").Collection("user_absolute_timeout")
+
+	t.Run("no caller deadline", func(t *testing.T) {
+		hook := &hangingHook{}
+		repo := mongodb.NewRepository[*User](col, mongodb.WithAbsoluteTimeout(20*time.Millisecond), mongodb.WithHook(hook))
+
+		started := time.Now()
+		_, err := repo.FindOne(ctx, bson.M{})
+		assert.Less(t, time.Since(started), time.Second)
+		assert.ErrorIs(t, err, mongodb.ErrAbsoluteTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "FindOne on user_absolute_timeout: no caller deadline, repository absolute timeout of 20ms applied")
+
+		var timeoutErr *mongodb.AbsoluteTimeoutError
+		if assert.True(t, errors.As(err, &timeoutErr)) {
+			assert.Equal(t, "FindOne", timeoutErr.Operation)
+			assert.Equal(t, 20*time.Millisecond, timeoutErr.Timeout)
+		}
+	})
+
+	t.Run("caller deadline", func(t *testing.T) {
+		hook := &hangingHook{}
+		repo := mongodb.NewRepository[*User](col, mongodb.WithAbsoluteTimeout(time.Hour), mongodb.WithHook(hook))
+
+		callerCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		callerDeadline, _ := callerCtx.Deadline()
+
+		_, err := repo.CountDocuments(callerCtx, bson.M{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NotErrorIs(t, err, mongodb.ErrAbsoluteTimeout)
+		assert.Equal(t, callerDeadline, hook.deadline)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		hook := &hangingHook{}
+		repo := mongodb.NewRepository[*User](col, mongodb.WithHook(hook))
+
+		callerCtx, cancel := context.WithCancel(ctx)
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		_, err := repo.FindMany(callerCtx, bson.M{})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.True(t, hook.deadline.IsZero())
+	})
+}
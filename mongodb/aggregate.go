@@ -0,0 +1,90 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrMemoryLimitExceeded is matched by errors of aggregations that exceeded the memory limit of a pipeline stage.
+var ErrMemoryLimitExceeded = errors.New("mongodb: aggregation exceeded memory limit")
+
+// Server error codes of stages that exceeded their memory limit without allowDiskUse.
+const (
+	codeQueryExceededMemoryLimitNoDiskUseAllowed int32 = 292
+	codeGroupExceededMemoryLimit                 int32 = 16945
+	codeSortExceededMemoryLimit                  int32 = 16819
+)
+
+type (
+	// MemoryLimitError is returned by [Repository.Aggregate] when a stage exceeded its memory limit.
+	// It matches [ErrMemoryLimitExceeded] with errors.Is and unwraps to the driver error.
+	MemoryLimitError struct {
+		err error
+	}
+)
+
+func (e *MemoryLimitError) Error() string {
+	return "mongodb: aggregation exceeded memory limit, set allowDiskUse, see WithAggregateDefaults: " + e.err.Error()
+}
+
+func (e *MemoryLimitError) Unwrap() error {
+	return e.err
+}
+
+func (e *MemoryLimitError) Is(target error) bool {
+	return target == ErrMemoryLimitExceeded
+}
+
+type aggregateDefaultsOption struct {
+	allowDiskUse bool
+	maxTime      time.Duration
+	batchSize    int32
+}
+
+func (value aggregateDefaultsOption) apply(o *repositoryOption) {
+	defaults := options.Aggregate()
+	if value.allowDiskUse {
+		defaults.SetAllowDiskUse(true)
+	}
+	if value.maxTime > 0 {
+		defaults.SetMaxTime(value.maxTime)
+	}
+	if value.batchSize > 0 {
+		defaults.SetBatchSize(value.batchSize)
+	}
+	o.aggregateDefaults = defaults
+}
+
+// WithAggregateDefaults sets options for every [Repository.Aggregate] whose options do not set them.
+// allowDiskUse is only applied if true, maxTime and batchSize only if greater than 0.
+func WithAggregateDefaults(allowDiskUse bool, maxTime time.Duration, batchSize int32) RepositoryOption {
+	return aggregateDefaultsOption{allowDiskUse: allowDiskUse, maxTime: maxTime, batchSize: batchSize}
+}
+
+// aggregateOptions puts the defaults and the [OpOption]s of ctx in front of opts,
+// so that fields set by opts take precedence when the driver merges them.
+func (r *Repository[T]) aggregateOptions(ctx context.Context, opts []*options.AggregateOptions) []*options.AggregateOptions {
+	ops := r.contextOptions(ctx)
+	// A maxTime of the defaults is an explicit limit, that a server-side deadline must not override.
+	if r.aggregateDefaults == nil || r.aggregateDefaults.MaxTime == nil {
+		ops = r.opOptions(ctx)
+	}
+	if !ops.isZero() {
+		opts = append([]*options.AggregateOptions{ops.aggregate()}, opts...)
+	}
+	if r.aggregateDefaults == nil {
+		return opts
+	}
+
+	return append([]*options.AggregateOptions{r.aggregateDefaults}, opts...)
+}
+
+func mapMemoryLimitError(err error) error {
+	if hasErrorCode(err, codeQueryExceededMemoryLimitNoDiskUseAllowed, codeGroupExceededMemoryLimit, codeSortExceededMemoryLimit) {
+		return &MemoryLimitError{err: err}
+	}
+	return err
+}
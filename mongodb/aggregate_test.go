@@ -0,0 +1,95 @@
+package mongodb_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// aggregateCommands connects with a monitor that records every aggregate command sent to the server.
+func aggregateCommands(t *testing.T, name string) (*mongo.Collection, func() []bson.Raw) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var commands []bson.Raw
+	monitor := &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			if e.CommandName != "aggregate" {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			commands = append(commands, e.Command)
+		},
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017").SetMonitor(monitor))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+
+	col := client.Database("testdb").Collection(name)
+	t.Cleanup(func() {
+		col.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+
+	return col, func() []bson.Raw {
+		mu.Lock()
+		defer mu.Unlock()
+		return commands
+	}
+}
+
+func TestAggregateDefaults(t *testing.T) {
+	ctx := context.Background()
+	col, commands := aggregateCommands(t, "user_aggregate_defaults")
+	repo := mongodb.NewRepository[*User](col, mongodb.WithAggregateDefaults(true, 5*time.Second, 50))
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{}}}}
+	if _, err := repo.Aggregate(ctx, pipeline); err != nil {
+		t.Fatalf("Error on aggregating: %v", err)
+	}
+	if _, err := repo.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(false).SetMaxTime(time.Second)); err != nil {
+		t.Fatalf("Error on aggregating with options: %v", err)
+	}
+
+	sent := commands()
+	if !assert.Len(t, sent, 2) {
+		return
+	}
+
+	assert.Equal(t, true, sent[0].Lookup("allowDiskUse").Boolean())
+	assert.Equal(t, int64(5000), sent[0].Lookup("maxTimeMS").AsInt64())
+	assert.Equal(t, int64(50), sent[0].Lookup("cursor", "batchSize").AsInt64())
+
+	assert.Equal(t, false, sent[1].Lookup("allowDiskUse").Boolean())
+	assert.Equal(t, int64(1000), sent[1].Lookup("maxTimeMS").AsInt64())
+	assert.Equal(t, int64(50), sent[1].Lookup("cursor", "batchSize").AsInt64())
+}
+
+func TestFindManyParallelAggregateDefaults(t *testing.T) {
+	ctx := context.Background()
+	col, commands := aggregateCommands(t, "user_parallel_aggregate_defaults")
+	repo := mongodb.NewRepository[*User](col, mongodb.WithAggregateDefaults(false, 5*time.Second, 0)).(*mongodb.Repository[*User])
+
+	if err := repo.FindManyParallel(ctx, bson.M{}, 4, func([]*User) error { return nil }); err != nil {
+		t.Fatalf("Error on finding users: %v", err)
+	}
+
+	sent := commands()
+	if !assert.Len(t, sent, 1) {
+		return
+	}
+	assert.Equal(t, true, sent[0].Lookup("allowDiskUse").Boolean())
+	assert.Equal(t, int64(5000), sent[0].Lookup("maxTimeMS").AsInt64())
+}
@@ -0,0 +1,119 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultArchiveBatchSize = 500
+
+type (
+	// ArchiveReport summarizes the result of [Archive].
+	ArchiveReport struct {
+		// Copied is the number of documents written to the archive.
+		Copied int
+		// Deleted is the number of archived documents deleted from the source.
+		Deleted int
+		// Failed is the number of documents that could not be written to the archive, they are kept in the source.
+		Failed int
+	}
+)
+
+// Archive moves all documents matching filter from src to archive, e.g. a "*_archive" collection, in batches of batchSize.
+//
+// Every batch is first written to archive, replacing documents with the same _id, and only the documents
+// that were written successfully are then deleted from src. A document is therefore never lost: if Archive is
+// interrupted, it exists in src, or in both collections, and running Archive again converges.
+// A batchSize <= 0 uses batches of 500 documents.
+//
+// The documents are copied as raw BSON, so fields that T does not model are archived as well. Documents are only
+// deleted if they still match filter, so documents that were changed to no longer match it in the meantime are kept.
+// Documents that were changed, but still match, are deleted with the archived copy of the earlier version;
+// run Archive within a transaction with [WithSession] if documents can change while they are archived.
+// src must implement [FindManyRaw], otherwise Archive fails with an error wrapping [ErrUnsupported].
+func Archive[T Document[T]](ctx context.Context, src RepositoryI[T], archive RepositoryI[T], filter bson.M, batchSize int) (ArchiveReport, error) {
+	report := ArchiveReport{}
+	if sameRepository(src, archive) {
+		return report, fmt.Errorf("%v: %w", "mongodb.Archive", ErrSameCollection)
+	}
+	reader, err := Capability[FindManyRaw](src)
+	if err != nil {
+		return report, fmt.Errorf("%v: %w", "mongodb.Archive", err)
+	}
+	if batchSize <= 0 {
+		batchSize = defaultArchiveBatchSize
+	}
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	findOpts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(batchSize))
+	var lastID interface{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, fmt.Errorf("%v: %w", "mongodb.Archive", err)
+		}
+
+		// Documents that failed stay in src, so the batches continue after the last _id instead of starting over.
+		batchFilter := filter
+		if lastID != nil {
+			batchFilter = bson.M{"$and": bson.A{filter, bson.M{"_id": bson.M{"$gt": lastID}}}}
+		}
+
+		batch, err := reader.FindManyRaw(ctx, batchFilter, findOpts)
+		if err != nil {
+			return report, fmt.Errorf("%v: %w", "mongodb.Archive", err)
+		}
+		if len(batch) == 0 {
+			return report, nil
+		}
+
+		ids := make([]interface{}, len(batch))
+		models := make([]mongo.WriteModel, len(batch))
+		for i, doc := range batch {
+			id, err := doc.LookupErr("_id")
+			if err != nil {
+				return report, fmt.Errorf("%v: document has no _id: %w", "mongodb.Archive", err)
+			}
+			ids[i] = id
+			models[i] = mongo.NewReplaceOneModel().SetFilter(bson.M{"_id": id}).SetReplacement(doc).SetUpsert(true)
+		}
+		lastID = ids[len(ids)-1]
+
+		_, err = archive.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		var bwe mongo.BulkWriteException
+		if err != nil && (!errors.As(err, &bwe) || bwe.WriteConcernError != nil) {
+			return report, fmt.Errorf("%v: %w", "mongodb.Archive", err)
+		}
+
+		failed := make(map[int]bool, len(bwe.WriteErrors))
+		for _, we := range bwe.WriteErrors {
+			failed[we.Index] = true
+		}
+		copied := make([]interface{}, 0, len(ids))
+		for i, id := range ids {
+			if !failed[i] {
+				copied = append(copied, id)
+			}
+		}
+		report.Copied += len(copied)
+		report.Failed += len(failed)
+
+		if len(copied) > 0 {
+			deleted, err := src.DeleteMany(ctx, bson.M{"$and": bson.A{filter, bson.M{"_id": bson.M{"$in": copied}}}})
+			report.Deleted += deleted
+			if err != nil {
+				return report, fmt.Errorf("%v: %w", "mongodb.Archive", err)
+			}
+		}
+
+		if len(batch) < batchSize {
+			return report, nil
+		}
+	}
+}
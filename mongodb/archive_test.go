@@ -0,0 +1,101 @@
+package mongodb_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// cancelAfterBulkWrite cancels the context once the wrapped repository received its first BulkWrite,
+// simulating a process that is killed between copying and deleting a batch.
+type cancelAfterBulkWrite struct {
+	mongodb.RepositoryI[*User]
+	cancel context.CancelFunc
+}
+
+func (r cancelAfterBulkWrite) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	defer r.cancel()
+	return r.RepositoryI.BulkWrite(ctx, models, opts...)
+}
+
+func TestArchive(t *testing.T) {
+	ctx := context.Background()
+	src := mongodb.NewRepository[*User](testCollection(t, "user_archive_src"))
+	archive := mongodb.NewRepository[*User](testCollection(t, "user_archive"))
+
+	users := make([]*User, 30)
+	for i := range users {
+		users[i] = &User{Name: fmt.Sprintf("User %d", i), Email: "old@example.com"}
+	}
+	users = append(users, &User{Name: "current", Email: "new@example.com"})
+	if _, err := src.InsertMany(ctx, users); err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+	filter := bson.M{"email": "old@example.com"}
+
+	// Interrupted after the first batch was copied, but before it was deleted.
+	canceled, cancel := context.WithCancel(ctx)
+	defer cancel()
+	_, err := mongodb.Archive[*User](canceled, src, cancelAfterBulkWrite{RepositoryI: archive, cancel: cancel}, filter, 10)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	inArchive, err := archive.CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 10, inArchive)
+	inSource, err := src.CountDocuments(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 30, inSource)
+
+	// The re-run converges.
+	report, err := mongodb.Archive[*User](ctx, src, archive, filter, 10)
+	if err != nil {
+		t.Fatalf("Error on archiving: %v", err)
+	}
+	assert.Equal(t, mongodb.ArchiveReport{Copied: 30, Deleted: 30}, report)
+
+	inArchive, err = archive.CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 30, inArchive)
+	remaining, err := src.FindMany(ctx, bson.M{})
+	assert.NoError(t, err)
+	if assert.Len(t, remaining, 1) {
+		assert.Equal(t, "current", remaining[0].Name)
+	}
+}
+
+func TestArchiveKeepsUnmodelledFields(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("raw copy", func(mt *mtest.T) {
+		src := mongodb.NewRepository[*User](mt.Coll)
+		archive := mongodb.NewRepository[*User](mt.DB.Collection("user_archive"))
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "db.user", mtest.FirstBatch, bson.D{{Key: "_id", Value: id}, {Key: "name", Value: "Alice"}, {Key: "legacy", Value: 42}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "upserted", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: id}}}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+		)
+
+		report, err := mongodb.Archive[*User](context.Background(), src, archive, bson.M{"name": "Alice"}, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, mongodb.ArchiveReport{Copied: 1, Deleted: 1}, report)
+
+		mt.GetStartedEvent() // find
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, int32(42), update.Lookup("u", "legacy").Int32(), "unmodelled fields are archived")
+
+		del := mt.GetStartedEvent().Command.Lookup("deletes").Array().Index(0).Value().Document()
+		conditions, err := del.Lookup("q", "$and").Array().Values()
+		assert.NoError(t, err)
+		if assert.Len(t, conditions, 2) {
+			assert.Equal(t, "Alice", conditions[0].Document().Lookup("name").StringValue(), "only documents that still match are deleted")
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/sync/errgroup"
+)
+
+type (
+	ArrayPageFinder[T Document[T]] interface {
+		FindOne[T]
+		Aggregater
+	}
+)
+
+// SliceField returns find options with a $slice projection, that returns limit elements of the array field,
+// after skipping skip elements. A negative skip counts from the end of the array.
+//
+// All other fields of the document are returned as well, since $slice alone does not make the projection an inclusion.
+//
+// See [https://www.mongodb.com/docs/manual/reference/operator/projection/slice/]
+func SliceField(field string, skip, limit int) *options.FindOneOptions {
+	return options.FindOne().SetProjection(bson.D{sliceProjection(field, skip, limit)})
+}
+
+func sliceProjection(field string, skip, limit int) bson.E {
+	return bson.E{Key: field, Value: bson.M{"$slice": bson.A{skip, limit}}}
+}
+
+// FindArrayPage returns a page of the array field arrayField of the document that matches filter, decoded into []E,
+// and the total length of the array, e.g. to page through an activity log embedded in a document.
+// filter should match a single document, e.g. by _id.
+//
+// Only _id and the requested slice of the array are transferred. The total is read by a $size aggregation that runs
+// concurrently with the find. A missing array has no elements. If filter matches no document, the error wraps
+// mongo.ErrNoDocuments.
+func FindArrayPage[T Document[T], E any](ctx context.Context, r ArrayPageFinder[T], filter bson.M, arrayField string, skip, limit int) ([]E, int, error) {
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	var (
+		doc   T
+		total int
+	)
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.Go(func() error {
+		projection := bson.D{{Key: "_id", Value: 1}, sliceProjection(arrayField, skip, limit)}
+
+		var err error
+		doc, err = r.FindOne(groupCtx, filter, options.FindOne().SetProjection(projection))
+		return err
+	})
+	group.Go(func() error {
+		var err error
+		total, err = arraySize(groupCtx, r, filter, arrayField)
+		return err
+	})
+	if err := group.Wait(); err != nil {
+		return nil, 0, fmt.Errorf("%v: %w", "mongodb.FindArrayPage", err)
+	}
+
+	registry := registryOf(r)
+	data, err := bson.MarshalWithRegistry(registry, doc)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%v: %w", "mongodb.FindArrayPage", err)
+	}
+
+	var res []E
+	value, err := bson.Raw(data).LookupErr(strings.Split(arrayField, ".")...)
+	if err != nil || value.Type == bsontype.Null {
+		return res, total, nil
+	}
+	if err := value.UnmarshalWithRegistry(registry, &res); err != nil {
+		return nil, 0, fmt.Errorf("%v: decoding %v: %w", "mongodb.FindArrayPage", arrayField, err)
+	}
+
+	return res, total, nil
+}
+
+// arraySize returns the length of the array field of the first document that matches filter, 0 if it is missing.
+func arraySize(ctx context.Context, r Aggregater, filter bson.M, field string) (int, error) {
+	cur, err := r.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$limit", Value: 1}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "total": bson.M{"$size": bson.M{"$ifNull": bson.A{"$" + field, bson.A{}}}}}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var res []struct {
+		Total int `bson:"total"`
+	}
+	if err := cur.All(ctx, &res); err != nil {
+		return 0, err
+	}
+	if len(res) == 0 {
+		return 0, mongo.ErrNoDocuments
+	}
+
+	return res[0].Total, nil
+}
@@ -0,0 +1,99 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	LogEntry struct {
+		Message string `bson:"message"`
+		N       int    `bson:"n"`
+	}
+
+	ActivityLog struct {
+		mongodb.BaseModel `bson:",inline"`
+		Title             string     `bson:"title"`
+		Entries           []LogEntry `bson:"entries,omitempty"`
+	}
+)
+
+func TestSliceField(t *testing.T) {
+	opts := mongodb.SliceField("entries", 10, 5)
+	assert.Equal(t, bson.D{{Key: "entries", Value: bson.M{"$slice": bson.A{10, 5}}}}, opts.Projection)
+}
+
+func TestFindArrayPage(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var projections []bson.Raw
+	monitor := &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			if e.CommandName != "find" {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			projections = append(projections, e.Command.Lookup("projection").Document())
+		},
+	}
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017").SetMonitor(monitor))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	col := client.Database("testdb").Collection("activity_log_array_page")
+	t.Cleanup(func() {
+		col.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+	repo := mongodb.NewRepository[*ActivityLog](col)
+
+	log := &ActivityLog{Title: "Orders"}
+	for i := 0; i < 25; i++ {
+		log.Entries = append(log.Entries, LogEntry{Message: fmt.Sprintf("Entry %d", i), N: i})
+	}
+	if _, err := repo.InsertOne(ctx, log); err != nil {
+		t.Fatalf("Error on inserting log: %v", err)
+	}
+	empty, err := repo.InsertOne(ctx, &ActivityLog{Title: "Empty"})
+	if err != nil {
+		t.Fatalf("Error on inserting log: %v", err)
+	}
+
+	entries, total, err := mongodb.FindArrayPage[*ActivityLog, LogEntry](ctx, repo, bson.M{"_id": log.MongoID}, "entries", 10, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 25, total)
+	assert.Equal(t, log.Entries[10:15], entries)
+
+	mu.Lock()
+	if assert.Len(t, projections, 1) {
+		var projection bson.M
+		assert.NoError(t, bson.Unmarshal(projections[0], &projection))
+		assert.Equal(t, bson.M{"_id": int32(1), "entries": bson.M{"$slice": bson.A{int32(10), int32(5)}}}, projection)
+	}
+	mu.Unlock()
+
+	entries, total, err = mongodb.FindArrayPage[*ActivityLog, LogEntry](ctx, repo, bson.M{"_id": log.MongoID}, "entries", 20, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 25, total)
+	assert.Equal(t, log.Entries[20:], entries)
+
+	entries, total, err = mongodb.FindArrayPage[*ActivityLog, LogEntry](ctx, repo, bson.M{"_id": empty.MongoID}, "entries", 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total)
+	assert.Empty(t, entries)
+
+	_, _, err = mongodb.FindArrayPage[*ActivityLog, LogEntry](ctx, repo, bson.M{"title": "Missing"}, "entries", 0, 10)
+	assert.True(t, errors.Is(err, mongo.ErrNoDocuments), "got %v", err)
+}
@@ -0,0 +1,569 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrChangeNotRecorded is returned by an audited repository with [WithStrictAudit], if a write succeeded but its
+// [ChangeRecord] could not be written.
+var ErrChangeNotRecorded = errors.New("mongodb: change was not recorded")
+
+type (
+	// ChangeRecord is the history entry an audited repository writes for every successful write, see [NewAuditedRepository].
+	ChangeRecord struct {
+		BaseModel `bson:",inline"`
+		// Collection is the name of the collection that was written.
+		Collection string `bson:"collection"`
+		// Operation is the name of the repository method, e.g. "UpdateMany". Write models of BulkWrite
+		// are recorded separately, e.g. "BulkWrite.DeleteOne".
+		Operation string `bson:"operation"`
+		// DocumentIDs are the _ids of the written documents. Inserted documents only have one if it was set before the write.
+		DocumentIDs []interface{} `bson:"documentIds"`
+		// Actor is the actor of the context of the write, see [WithActor] and [WithAuditActor].
+		Actor     string    `bson:"actor,omitempty"`
+		Timestamp time.Time `bson:"timestamp"`
+		// Set are the fields set by updates and upserts, sorted by field.
+		Set []FieldChange `bson:"set,omitempty"`
+		// Prior are the documents before they were replaced or deleted, if [WithPriorDocuments] is passed.
+		Prior []bson.Raw `bson:"prior,omitempty"`
+	}
+
+	// FieldChange is a single field set by an update, see [ChangeRecord.Set].
+	// Fields are stored as values, since field names with dots are not allowed on older servers.
+	FieldChange struct {
+		Field string      `bson:"field"`
+		Value interface{} `bson:"value"`
+	}
+
+	// AuditOption configures a repository created by [NewAuditedRepository].
+	AuditOption interface {
+		apply(*auditOption)
+	}
+
+	auditOption struct {
+		strict     bool
+		prior      bool
+		collection string
+		actor      func(ctx context.Context) string
+		logf       func(format string, args ...interface{})
+	}
+
+	// auditedRepository records the writes of the embedded repository in a history repository, see [NewAuditedRepository].
+	auditedRepository[T Document[T]] struct {
+		Forwarder[T]
+		history RepositoryI[*ChangeRecord]
+		ops     *auditOption
+	}
+
+	actorKey struct{}
+)
+
+type strictAuditOption bool
+
+func (value strictAuditOption) apply(o *auditOption) {
+	o.strict = bool(value)
+}
+
+// WithStrictAudit makes writes fail with [ErrChangeNotRecorded] if their [ChangeRecord] can not be written.
+// Without it, the failure is only logged, see [WithAuditLogger].
+//
+// The write itself has already succeeded at that point. Run it in a transaction, e.g. with a DataStore,
+// so that it is rolled back together with the record.
+func WithStrictAudit() AuditOption {
+	return strictAuditOption(true)
+}
+
+type priorDocumentsOption bool
+
+func (value priorDocumentsOption) apply(o *auditOption) {
+	o.prior = bool(value)
+}
+
+// WithPriorDocuments stores the documents before they are replaced or deleted in [ChangeRecord.Prior].
+func WithPriorDocuments() AuditOption {
+	return priorDocumentsOption(true)
+}
+
+type auditCollectionOption string
+
+func (value auditCollectionOption) apply(o *auditOption) {
+	o.collection = string(value)
+}
+
+// WithAuditCollection sets [ChangeRecord.Collection]. By default, it is the name of the collection of the inner repository,
+// if it implements [CollectionProvider].
+func WithAuditCollection(name string) AuditOption {
+	return auditCollectionOption(name)
+}
+
+type auditActorOption func(ctx context.Context) string
+
+func (value auditActorOption) apply(o *auditOption) {
+	o.actor = value
+}
+
+// WithAuditActor extracts [ChangeRecord.Actor] from the context of a write, e.g. the user of an authenticated request.
+// By default, the actor set with [WithActor] is used.
+func WithAuditActor(extract func(ctx context.Context) string) AuditOption {
+	return auditActorOption(extract)
+}
+
+type auditLoggerOption func(format string, args ...interface{})
+
+func (value auditLoggerOption) apply(o *auditOption) {
+	o.logf = value
+}
+
+// WithAuditLogger sets the function records that could not be written are logged with, the default is log.Printf.
+// Pass nil to not log them.
+func WithAuditLogger(logf func(format string, args ...interface{})) AuditOption {
+	return auditLoggerOption(logf)
+}
+
+// WithActor returns a context that records actor as the [ChangeRecord.Actor] of the writes of audited repositories.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set with [WithActor], or "" if there is none.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// NewAuditedRepository wraps inner, so that every successful write is recorded as a [ChangeRecord] in history,
+// e.g. to keep the full history of a sensitive collection.
+//
+// Inserts, updates, replaces and deletes, FindOneOrCreate when it creates a document, UpsertManyByKey, BulkWrite and Drop
+// are recorded. Writes that match no document are not. InsertMany and the other writes of multiple documents are recorded
+// as a single ChangeRecord with all _ids, while BulkWrite records one per write model, and UpsertManyByKey one per document.
+// Writes that fail are not recorded, including BulkWrites that fail after some of their models were written.
+//
+// The _ids of updated, replaced and deleted documents, and the prior documents of [WithPriorDocuments], are read from
+// inner just before the write. So the write costs another read, and for filters that match several documents,
+// the write of a single document may record another one than the server picked. Filter by _id to avoid that.
+// The batches of DeleteManyAudited are recorded after they were passed to onBatch, even if deleting them fails,
+// since some of their documents may have been deleted.
+//
+// The records are written with the context of the write, so within a transaction they are committed with the write.
+// Reads are passed through to inner unchanged.
+func NewAuditedRepository[T Document[T]](inner RepositoryI[T], history RepositoryI[*ChangeRecord], opts ...AuditOption) RepositoryI[T] {
+	ops := &auditOption{actor: ActorFromContext, logf: log.Printf}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+	return &auditedRepository[T]{Forwarder: Forwarder[T]{RepositoryI: inner}, history: history, ops: ops}
+}
+
+func (r *auditedRepository[T]) WithSession(sess mongo.Session) RepositoryI[T] {
+	return &auditedRepository[T]{Forwarder: Forwarder[T]{RepositoryI: WithSession(r.RepositoryI, sess)}, history: WithSession(r.history, sess), ops: r.ops}
+}
+
+func (r *auditedRepository[T]) Registry() *bsoncodec.Registry {
+	return registryOf(r.RepositoryI)
+}
+
+func (r *auditedRepository[T]) InsertOne(ctx context.Context, doc T, opts ...*options.InsertOneOptions) (T, error) {
+	res, err := r.RepositoryI.InsertOne(ctx, doc, opts...)
+	if err != nil {
+		return res, err
+	}
+	return res, r.record(ctx, "InsertOne", &ChangeRecord{Operation: "InsertOne", DocumentIDs: insertedIDs(registryOf(r.RepositoryI), []T{res}, 1)})
+}
+
+func (r *auditedRepository[T]) InsertMany(ctx context.Context, docs []T, opts ...*options.InsertManyOptions) ([]T, error) {
+	res, err := r.RepositoryI.InsertMany(ctx, docs, opts...)
+	if err != nil {
+		return res, err
+	}
+	return res, r.record(ctx, "InsertMany", &ChangeRecord{Operation: "InsertMany", DocumentIDs: insertedIDs(registryOf(r.RepositoryI), res, len(res))})
+}
+
+func (r *auditedRepository[T]) InsertManyResult(ctx context.Context, docs []T, opts ...*options.InsertManyOptions) ([]T, *mongo.InsertManyResult, error) {
+	res, result, err := r.Forwarder.InsertManyResult(ctx, docs, opts...)
+	if err != nil {
+		return res, result, err
+	}
+	return res, result, r.record(ctx, "InsertManyResult", &ChangeRecord{Operation: "InsertManyResult", DocumentIDs: result.InsertedIDs})
+}
+
+func (r *auditedRepository[T]) UpdateOne(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return r.update(ctx, "UpdateOne", filter, data, false, opts, func() (*mongo.UpdateResult, error) {
+		return r.RepositoryI.UpdateOne(ctx, filter, data, opts...)
+	})
+}
+
+func (r *auditedRepository[T]) UpdateMany(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) error {
+	_, err := r.update(ctx, "UpdateMany", filter, data, true, opts, func() (*mongo.UpdateResult, error) {
+		return nil, r.RepositoryI.UpdateMany(ctx, filter, data, opts...)
+	})
+	return err
+}
+
+func (r *auditedRepository[T]) UpdateManyResult(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return r.update(ctx, "UpdateManyResult", filter, data, true, opts, func() (*mongo.UpdateResult, error) {
+		return r.Forwarder.UpdateManyResult(ctx, filter, data, opts...)
+	})
+}
+
+func (r *auditedRepository[T]) UpdateOneWhere(ctx context.Context, filter Filter, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return r.update(ctx, "UpdateOneWhere", filter, data, false, opts, func() (*mongo.UpdateResult, error) {
+		return r.Forwarder.UpdateOneWhere(ctx, filter, data, opts...)
+	})
+}
+
+func (r *auditedRepository[T]) UpdateManyWhere(ctx context.Context, filter Filter, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return r.update(ctx, "UpdateManyWhere", filter, data, true, opts, func() (*mongo.UpdateResult, error) {
+		return r.Forwarder.UpdateManyWhere(ctx, filter, data, opts...)
+	})
+}
+
+// update runs the update write, and records it with the _ids that matched filter before. run may return a nil result,
+// then the update is recorded if a document matched or an upsert was requested.
+func (r *auditedRepository[T]) update(ctx context.Context, name string, filter interface{}, data primitive.M, many bool, opts []*options.UpdateOptions,
+	run func() (*mongo.UpdateResult, error)) (*mongo.UpdateResult, error) {
+	ids, _, err := r.affected(ctx, name, filter, many, false)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := run()
+	if err != nil {
+		return res, err
+	}
+
+	upsert := options.MergeUpdateOptions(opts...).Upsert
+	switch {
+	case res != nil && res.UpsertedID != nil:
+		ids = []interface{}{res.UpsertedID}
+	case res != nil && res.MatchedCount == 0, res == nil && len(ids) == 0 && (upsert == nil || !*upsert):
+		return res, nil
+	}
+	return res, r.record(ctx, name, &ChangeRecord{Operation: name, DocumentIDs: ids, Set: fieldChanges(data)})
+}
+
+func (r *auditedRepository[T]) ReplaceOne(ctx context.Context, filter bson.M, doc T, opts ...*options.ReplaceOptions) (T, error) {
+	ids, prior, err := r.affected(ctx, "ReplaceOne", filter, false, r.ops.prior)
+	if err != nil {
+		return doc, err
+	}
+
+	res, err := r.RepositoryI.ReplaceOne(ctx, filter, doc, opts...)
+	if err != nil {
+		return res, err
+	}
+	if len(ids) == 0 {
+		// The document was upserted.
+		ids = insertedIDs(registryOf(r.RepositoryI), []T{res}, 1)
+	}
+	return res, r.record(ctx, "ReplaceOne", &ChangeRecord{Operation: "ReplaceOne", DocumentIDs: ids, Prior: prior})
+}
+
+func (r *auditedRepository[T]) FindOneOrCreate(ctx context.Context, filter bson.M, defaultDoc T, opts ...*options.FindOneAndUpdateOptions) (T, bool, error) {
+	res, created, err := r.Forwarder.FindOneOrCreate(ctx, filter, defaultDoc, opts...)
+	if err != nil || !created {
+		return res, created, err
+	}
+	return res, created, r.record(ctx, "FindOneOrCreate", &ChangeRecord{Operation: "FindOneOrCreate", DocumentIDs: insertedIDs(registryOf(r.RepositoryI), []T{res}, 1)})
+}
+
+func (r *auditedRepository[T]) UpsertManyByKey(ctx context.Context, docs []T, keyFields []string, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	filters, err := keyFilters(docs, keyFields)
+	if err != nil {
+		// inner returns the same error.
+		return r.Forwarder.UpsertManyByKey(ctx, docs, keyFields, opts...)
+	}
+
+	existing := make([][]interface{}, len(filters))
+	for i, filter := range filters {
+		if existing[i], _, err = r.affected(ctx, "UpsertManyByKey", filter, false, false); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := r.Forwarder.UpsertManyByKey(ctx, docs, keyFields, opts...)
+	if err != nil {
+		return res, err
+	}
+
+	records := make([]*ChangeRecord, len(docs))
+	for i, doc := range docs {
+		ids := existing[i]
+		if len(ids) == 0 {
+			ids = insertedIDs(registryOf(r.RepositoryI), []T{doc}, 1)
+		}
+		var set []FieldChange
+		if fields, err := withoutID(registryOf(r.RepositoryI), doc); err == nil {
+			set = fieldChanges(fields)
+		}
+		records[i] = &ChangeRecord{Operation: "UpsertManyByKey", DocumentIDs: ids, Set: set}
+	}
+	return res, r.record(ctx, "UpsertManyByKey", records...)
+}
+
+func (r *auditedRepository[T]) DeleteOne(ctx context.Context, filter bson.M, opts ...*options.DeleteOptions) error {
+	_, err := r.delete(ctx, "DeleteOne", filter, false, func() (int, error) {
+		return -1, r.RepositoryI.DeleteOne(ctx, filter, opts...)
+	})
+	return err
+}
+
+func (r *auditedRepository[T]) DeleteOneWhere(ctx context.Context, filter Filter, opts ...*options.DeleteOptions) error {
+	_, err := r.delete(ctx, "DeleteOneWhere", filter, false, func() (int, error) {
+		return -1, r.Forwarder.DeleteOneWhere(ctx, filter, opts...)
+	})
+	return err
+}
+
+func (r *auditedRepository[T]) DeleteMany(ctx context.Context, filter bson.M, opts ...*options.DeleteOptions) (int, error) {
+	return r.delete(ctx, "DeleteMany", filter, true, func() (int, error) {
+		return r.RepositoryI.DeleteMany(ctx, filter, opts...)
+	})
+}
+
+func (r *auditedRepository[T]) DeleteManyWhere(ctx context.Context, filter Filter, opts ...*options.DeleteOptions) (int, error) {
+	return r.delete(ctx, "DeleteManyWhere", filter, true, func() (int, error) {
+		return r.Forwarder.DeleteManyWhere(ctx, filter, opts...)
+	})
+}
+
+func (r *auditedRepository[T]) DeleteManyByIDs(ctx context.Context, ids []primitive.ObjectID, chunkSize int) (int, error) {
+	var prior []bson.Raw
+	if r.ops.prior {
+		err := ChunkedIn(ids, chunkSize, func(filterValue primitive.M) error {
+			_, docs, err := r.affected(ctx, "DeleteManyByIDs", bson.M{"_id": filterValue}, true, true)
+			prior = append(prior, docs...)
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.Forwarder.DeleteManyByIDs(ctx, ids, chunkSize)
+	if err != nil || n == 0 {
+		return n, err
+	}
+
+	documentIDs := make([]interface{}, len(ids))
+	for i, id := range ids {
+		documentIDs[i] = id
+	}
+	return n, r.record(ctx, "DeleteManyByIDs", &ChangeRecord{Operation: "DeleteManyByIDs", DocumentIDs: documentIDs, Prior: prior})
+}
+
+func (r *auditedRepository[T]) DeleteManyAudited(ctx context.Context, filter bson.M, batchSize int, onBatch func(deletedIDs []primitive.ObjectID) error) (int, error) {
+	var pending *ChangeRecord
+	flush := func() error {
+		if pending == nil {
+			return nil
+		}
+		record := pending
+		pending = nil
+		return r.record(ctx, "DeleteManyAudited", record)
+	}
+
+	n, err := r.Forwarder.DeleteManyAudited(ctx, filter, batchSize, func(deletedIDs []primitive.ObjectID) error {
+		// The previous batch was deleted, otherwise the next one would not be read.
+		if err := flush(); err != nil {
+			return err
+		}
+		if err := onBatch(deletedIDs); err != nil {
+			return err
+		}
+
+		record := &ChangeRecord{Operation: "DeleteManyAudited", DocumentIDs: make([]interface{}, len(deletedIDs))}
+		for i, id := range deletedIDs {
+			record.DocumentIDs[i] = id
+		}
+		if r.ops.prior {
+			_, prior, err := r.affected(ctx, "DeleteManyAudited", bson.M{"_id": bson.M{"$in": deletedIDs}}, true, true)
+			if err != nil {
+				return err
+			}
+			record.Prior = prior
+		}
+		pending = record
+		return nil
+	})
+	return n, JoinErrors(err, flush())
+}
+
+// delete runs the delete write, and records it with the _ids that matched filter before.
+// run returns the number of deleted documents, or -1 if it is not known.
+func (r *auditedRepository[T]) delete(ctx context.Context, name string, filter interface{}, many bool, run func() (int, error)) (int, error) {
+	ids, prior, err := r.affected(ctx, name, filter, many, r.ops.prior)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := run()
+	if n < 0 {
+		n = 0
+	}
+	if err != nil || n == 0 || len(ids) == 0 {
+		return n, err
+	}
+	return n, r.record(ctx, name, &ChangeRecord{Operation: name, DocumentIDs: ids, Prior: prior})
+}
+
+func (r *auditedRepository[T]) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	records := make([]*ChangeRecord, 0, len(models))
+	for _, model := range models {
+		var (
+			record = &ChangeRecord{}
+			filter interface{}
+			many   bool
+			prior  bool
+		)
+		switch model := model.(type) {
+		case *mongo.InsertOneModel:
+			record.Operation = "BulkWrite.InsertOne"
+			record.DocumentIDs = insertedIDs(registryOf(r.RepositoryI), []interface{}{model.Document}, 1)
+			records = append(records, record)
+			continue
+		case *mongo.UpdateOneModel:
+			record.Operation, filter, record.Set = "BulkWrite.UpdateOne", model.Filter, updateChanges(model.Update)
+		case *mongo.UpdateManyModel:
+			record.Operation, filter, many, record.Set = "BulkWrite.UpdateMany", model.Filter, true, updateChanges(model.Update)
+		case *mongo.ReplaceOneModel:
+			record.Operation, filter, prior = "BulkWrite.ReplaceOne", model.Filter, r.ops.prior
+		case *mongo.DeleteOneModel:
+			record.Operation, filter, prior = "BulkWrite.DeleteOne", model.Filter, r.ops.prior
+		case *mongo.DeleteManyModel:
+			record.Operation, filter, many, prior = "BulkWrite.DeleteMany", model.Filter, true, r.ops.prior
+		default:
+			// inner rejects the model.
+			return r.RepositoryI.BulkWrite(ctx, models, opts...)
+		}
+
+		var err error
+		if record.DocumentIDs, record.Prior, err = r.affected(ctx, record.Operation, filter, many, prior); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	res, err := r.RepositoryI.BulkWrite(ctx, models, opts...)
+	if err != nil || len(records) == 0 {
+		return res, err
+	}
+	return res, r.record(ctx, "BulkWrite", records...)
+}
+
+func (r *auditedRepository[T]) Drop(ctx context.Context) error {
+	if err := r.Forwarder.Drop(ctx); err != nil {
+		return err
+	}
+	return r.record(ctx, "Drop", &ChangeRecord{Operation: "Drop"})
+}
+
+// affected returns the _ids of the documents that match the filter of the write name, and the documents themselves if prior is set.
+// A write that is not many affects at most one document.
+func (r *auditedRepository[T]) affected(ctx context.Context, name string, filter interface{}, many, prior bool) ([]interface{}, []bson.Raw, error) {
+	opts := options.Find()
+	if !prior {
+		opts.SetProjection(bson.M{"_id": 1})
+	}
+	if !many {
+		opts.SetLimit(1)
+	}
+
+	docs, err := r.Forwarder.FindManyRaw(ctx, dryRunFilter(filter), opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%v: reading documents: %w", "mongodb.NewAuditedRepository."+name, err)
+	}
+
+	ids := make([]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		var id interface{}
+		if value, err := doc.LookupErr("_id"); err == nil && value.Unmarshal(&id) == nil {
+			ids = append(ids, id)
+		}
+	}
+	if !prior {
+		docs = nil
+	}
+	return ids, docs, nil
+}
+
+// record writes the records of the write name to the history repository.
+func (r *auditedRepository[T]) record(ctx context.Context, name string, records ...*ChangeRecord) error {
+	collection := r.ops.collection
+	if provider, ok := r.RepositoryI.(CollectionProvider); ok && collection == "" {
+		collection = provider.CollectionFor(ctx).Name()
+	}
+
+	timestamp := now()
+	actor := ""
+	if r.ops.actor != nil {
+		actor = r.ops.actor(ctx)
+	}
+	for _, record := range records {
+		record.Collection, record.Actor, record.Timestamp = collection, actor, timestamp
+		if record.DocumentIDs == nil {
+			record.DocumentIDs = []interface{}{}
+		}
+	}
+
+	var err error
+	if len(records) == 1 {
+		_, err = r.history.InsertOne(ctx, records[0])
+	} else {
+		_, err = r.history.InsertMany(ctx, records)
+	}
+	if err == nil {
+		return nil
+	}
+
+	if r.ops.strict {
+		return fmt.Errorf("%v: %w: %w", "mongodb.NewAuditedRepository."+name, ErrChangeNotRecorded, err)
+	}
+	if r.ops.logf != nil {
+		r.ops.logf("%v: %v of %v by %q: %v", "mongodb.NewAuditedRepository."+name, ErrChangeNotRecorded, collection, actor, err)
+	}
+	return nil
+}
+
+// fieldChanges returns the fields of an update document, sorted by field.
+func fieldChanges(fields interface{}) []FieldChange {
+	var changes []FieldChange
+	switch fields := fields.(type) {
+	case bson.M:
+		for field, value := range fields {
+			changes = append(changes, FieldChange{Field: field, Value: value})
+		}
+	case bson.D:
+		for _, e := range fields {
+			changes = append(changes, FieldChange{Field: e.Key, Value: e.Value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// updateChanges returns the fields set by the $set of an update document of a write model.
+func updateChanges(update interface{}) []FieldChange {
+	switch update := update.(type) {
+	case bson.M:
+		return fieldChanges(update["$set"])
+	case bson.D:
+		for _, e := range update {
+			if e.Key == "$set" {
+				return fieldChanges(e.Value)
+			}
+		}
+	}
+	return nil
+}
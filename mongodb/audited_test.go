@@ -0,0 +1,146 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongotest"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestAuditedRepositoryHistoryFailures(t *testing.T) {
+	ctx := mongodb.WithActor(context.Background(), "alice")
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	// The dry-run repository answers inserts without a server.
+	inner := mongodb.NewDryRunRepository[*User](mongodb.NewRepository[*User](client.Database("testdb").Collection("user_audited")), nil)
+	spy, history := mongotest.NewSpy[*mongodb.ChangeRecord](nil)
+	spy.StubError("InsertOne", nil)
+
+	id := primitive.NewObjectID()
+	repo := mongodb.NewAuditedRepository[*User](inner, history, mongodb.WithAuditCollection("user_audited"))
+	_, err = repo.InsertOne(ctx, &User{BaseModel: mongodb.BaseModel{MongoID: id}, Name: "Bob"})
+	assert.NoError(t, err)
+
+	if calls := spy.Calls("InsertOne"); assert.Len(t, calls, 1) {
+		record := calls[0].Doc.(*mongodb.ChangeRecord)
+		assert.Equal(t, "user_audited", record.Collection)
+		assert.Equal(t, "InsertOne", record.Operation)
+		assert.Equal(t, []interface{}{id}, record.DocumentIDs)
+		assert.Equal(t, "alice", record.Actor)
+		assert.False(t, record.Timestamp.IsZero())
+	}
+
+	errHistory := errors.New("history unavailable")
+	spy.StubError("InsertOne", errHistory)
+
+	var logged []string
+	repo = mongodb.NewAuditedRepository[*User](inner, history, mongodb.WithAuditLogger(func(format string, args ...interface{}) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}))
+	_, err = repo.InsertOne(ctx, &User{Name: "Bob"})
+	assert.NoError(t, err, "best-effort auditing only logs the failure")
+	if assert.Len(t, logged, 1) {
+		assert.Contains(t, logged[0], "history unavailable")
+	}
+
+	repo = mongodb.NewAuditedRepository[*User](inner, history, mongodb.WithStrictAudit())
+	_, err = repo.InsertOne(ctx, &User{Name: "Bob"})
+	assert.ErrorIs(t, err, mongodb.ErrChangeNotRecorded)
+	assert.ErrorIs(t, err, errHistory)
+}
+
+func TestAuditedRepository(t *testing.T) {
+	ctx := mongodb.WithActor(context.Background(), "alice")
+	inner := mongodb.NewRepository[*User](testCollection(t, "user_audited"))
+	history := mongodb.NewRepository[*mongodb.ChangeRecord](testCollection(t, "user_audited_history"))
+	repo := mongodb.NewAuditedRepository[*User](inner, history, mongodb.WithPriorDocuments())
+
+	records := func(operation string) []*mongodb.ChangeRecord {
+		t.Helper()
+		res, err := history.FindMany(ctx, bson.M{"operation": operation})
+		if err != nil {
+			t.Fatalf("Error on reading change records: %v", err)
+		}
+		return res
+	}
+
+	users, err := repo.InsertMany(ctx, []*User{{Name: "Bob"}, {Name: "Carol"}, {Name: "Dave"}})
+	if err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+	if inserted := records("InsertMany"); assert.Len(t, inserted, 1) {
+		assert.Equal(t, "user_audited", inserted[0].Collection)
+		assert.Equal(t, "alice", inserted[0].Actor)
+		assert.Equal(t, []interface{}{users[0].MongoID, users[1].MongoID, users[2].MongoID}, inserted[0].DocumentIDs)
+	}
+
+	_, err = repo.UpdateOne(mongodb.WithActor(ctx, "bob"), bson.M{"_id": users[0].MongoID}, bson.M{"name": "Robert", "email": "bob@example.com"})
+	assert.NoError(t, err)
+	if updated := records("UpdateOne"); assert.Len(t, updated, 1) {
+		assert.Equal(t, "bob", updated[0].Actor)
+		assert.Equal(t, []interface{}{users[0].MongoID}, updated[0].DocumentIDs)
+		assert.Equal(t, []mongodb.FieldChange{{Field: "email", Value: "bob@example.com"}, {Field: "name", Value: "Robert"}}, updated[0].Set)
+		assert.Empty(t, updated[0].Prior)
+	}
+
+	_, err = repo.UpdateOne(ctx, bson.M{"name": "nobody"}, bson.M{"name": "Nobody"})
+	assert.NoError(t, err)
+	assert.Len(t, records("UpdateOne"), 1, "updates that match no document are not recorded")
+
+	_, err = repo.ReplaceOne(ctx, bson.M{"_id": users[1].MongoID}, &User{BaseModel: users[1].BaseModel, Name: "Caroline"})
+	assert.NoError(t, err)
+	if replaced := records("ReplaceOne"); assert.Len(t, replaced, 1) {
+		assert.Equal(t, []interface{}{users[1].MongoID}, replaced[0].DocumentIDs)
+		if assert.Len(t, replaced[0].Prior, 1) {
+			name, err := mongodb.RawField[string](replaced[0].Prior[0], "name")
+			assert.NoError(t, err)
+			assert.Equal(t, "Carol", name)
+		}
+	}
+
+	assert.NoError(t, repo.DeleteOne(ctx, bson.M{"_id": users[2].MongoID}))
+	if deleted := records("DeleteOne"); assert.Len(t, deleted, 1) {
+		assert.Equal(t, []interface{}{users[2].MongoID}, deleted[0].DocumentIDs)
+		if assert.Len(t, deleted[0].Prior, 1) {
+			name, err := mongodb.RawField[string](deleted[0].Prior[0], "name")
+			assert.NoError(t, err)
+			assert.Equal(t, "Dave", name)
+		}
+	}
+
+	eve := &User{BaseModel: mongodb.BaseModel{MongoID: primitive.NewObjectID()}, Name: "Eve"}
+	_, err = repo.BulkWrite(ctx, []mongo.WriteModel{
+		mongo.NewInsertOneModel().SetDocument(eve),
+		mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": users[0].MongoID}).SetUpdate(bson.M{"$set": bson.M{"name": "Bobby"}}),
+		mongo.NewDeleteOneModel().SetFilter(bson.M{"_id": users[1].MongoID}),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{eve.MongoID}, records("BulkWrite.InsertOne")[0].DocumentIDs)
+	if updated := records("BulkWrite.UpdateOne"); assert.Len(t, updated, 1) {
+		assert.Equal(t, []mongodb.FieldChange{{Field: "name", Value: "Bobby"}}, updated[0].Set)
+	}
+	if deleted := records("BulkWrite.DeleteOne"); assert.Len(t, deleted, 1) {
+		assert.Equal(t, []interface{}{users[1].MongoID}, deleted[0].DocumentIDs)
+		assert.Len(t, deleted[0].Prior, 1)
+	}
+
+	n, err := repo.DeleteMany(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	if deleted := records("DeleteMany"); assert.Len(t, deleted, 1) {
+		assert.ElementsMatch(t, []interface{}{users[0].MongoID, eve.MongoID}, deleted[0].DocumentIDs)
+		assert.Len(t, deleted[0].Prior, 2)
+	}
+}
@@ -0,0 +1,177 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultBackfillBatchSize = 500
+
+type (
+	// BackfillOption configures [Backfill].
+	BackfillOption interface {
+		apply(*backfillOption)
+	}
+
+	// BackfillReport summarizes the result of [Backfill].
+	BackfillReport struct {
+		// Processed is the number of documents passed to the batch function.
+		Processed int
+		// Modified is the number of documents modified by the write models.
+		Modified int
+		// Batches is the number of processed batches.
+		Batches int
+		// LastID is the _id of the last processed document, it can be passed to [WithResumeAfter].
+		LastID interface{}
+	}
+)
+
+type (
+	backfillOption struct {
+		delay        time.Duration
+		opsPerSecond int
+		progress     func(processed int, lastID interface{})
+		resumeAfter  interface{}
+	}
+)
+
+type backfillDelayOption time.Duration
+
+func (value backfillDelayOption) apply(o *backfillOption) {
+	o.delay = time.Duration(value)
+}
+
+// WithBatchDelay sleeps for the given duration between two batches.
+func WithBatchDelay(delay time.Duration) BackfillOption {
+	return backfillDelayOption(delay)
+}
+
+type opsPerSecondOption int
+
+func (value opsPerSecondOption) apply(o *backfillOption) {
+	o.opsPerSecond = int(value)
+}
+
+// WithMaxOpsPerSecond limits the number of write models that are applied per second.
+func WithMaxOpsPerSecond(ops int) BackfillOption {
+	return opsPerSecondOption(ops)
+}
+
+type progressOption func(processed int, lastID interface{})
+
+func (value progressOption) apply(o *backfillOption) {
+	o.progress = value
+}
+
+// WithProgress calls fn after every batch with the number of processed documents and the _id of the last one.
+func WithProgress(fn func(processed int, lastID interface{})) BackfillOption {
+	return progressOption(fn)
+}
+
+type resumeAfterOption struct {
+	id interface{}
+}
+
+func (value resumeAfterOption) apply(o *backfillOption) {
+	o.resumeAfter = value.id
+}
+
+// WithResumeAfter only processes documents with an _id greater than id, e.g. [BackfillReport.LastID] of an earlier run.
+func WithResumeAfter(id interface{}) BackfillOption {
+	return resumeAfterOption{id: id}
+}
+
+// Backfill iterates over all documents matching filter in _id order and applies the write models fn returns for every batch.
+//
+// Every batch is a separate query for the documents after the last processed _id, so documents fn modifies
+// are never processed twice, and an aborted backfill can be resumed with [WithResumeAfter].
+// When ctx is canceled, the current batch is still written and Backfill returns the context error.
+// A batchSize <= 0 uses a batch size of 500.
+func Backfill[T Document[T]](ctx context.Context, r RepositoryI[T], filter bson.M, batchSize int, fn func(batch []T) ([]mongo.WriteModel, error), opts ...BackfillOption) (BackfillReport, error) {
+	ops := &backfillOption{}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+	if batchSize <= 0 {
+		batchSize = defaultBackfillBatchSize
+	}
+
+	report := BackfillReport{LastID: ops.resumeAfter}
+	findOpts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(batchSize))
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, fmt.Errorf("%v: %w", "mongodb.Backfill", err)
+		}
+
+		batchFilter := filter
+		if report.LastID != nil {
+			after := bson.M{"_id": bson.M{"$gt": report.LastID}}
+			if len(filter) == 0 {
+				batchFilter = after
+			} else {
+				batchFilter = bson.M{"$and": bson.A{filter, after}}
+			}
+		}
+		if batchFilter == nil {
+			batchFilter = bson.M{}
+		}
+
+		batch, err := r.FindMany(ctx, batchFilter, findOpts)
+		if err != nil {
+			return report, fmt.Errorf("%v: %w", "mongodb.Backfill", err)
+		}
+		if len(batch) == 0 {
+			return report, nil
+		}
+
+		lastID, err := documentID(registryOf(r), batch[len(batch)-1])
+		if err != nil {
+			return report, fmt.Errorf("%v: %w", "mongodb.Backfill", err)
+		}
+
+		started := time.Now()
+		models, err := fn(batch)
+		if err != nil {
+			return report, fmt.Errorf("%v: batch after %v: %w", "mongodb.Backfill", report.LastID, err)
+		}
+
+		// The batch is written even if ctx is canceled in the meantime, so that it is never applied partially.
+		res, err := r.BulkWrite(withoutCancel(ctx), models)
+		if err != nil {
+			return report, fmt.Errorf("%v: batch after %v: %w", "mongodb.Backfill", report.LastID, err)
+		}
+
+		report.Processed += len(batch)
+		report.Modified += int(res.ModifiedCount)
+		report.Batches++
+		report.LastID = lastID
+		if ops.progress != nil {
+			ops.progress(report.Processed, report.LastID)
+		}
+
+		if len(batch) < batchSize {
+			return report, nil
+		}
+
+		wait := ops.delay
+		if ops.opsPerSecond > 0 {
+			budget := time.Duration(len(models)) * time.Second / time.Duration(ops.opsPerSecond)
+			if remaining := budget - time.Since(started); remaining > wait {
+				wait = remaining
+			}
+		}
+		if wait > 0 {
+			select {
+			case <-ctx.Done():
+				return report, fmt.Errorf("%v: %w", "mongodb.Backfill", ctx.Err())
+			case <-time.After(wait):
+			}
+		}
+	}
+}
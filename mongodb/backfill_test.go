@@ -0,0 +1,66 @@
+package mongodb_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestBackfill(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_backfill"))
+
+	users := make([]*User, 25)
+	for i := range users {
+		users[i] = &User{Name: fmt.Sprintf("User %d", i), Email: fmt.Sprintf("user%d@example.com", i)}
+	}
+	if _, err := repo.InsertMany(ctx, users); err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	upperEmail := func(batch []*User) ([]mongo.WriteModel, error) {
+		models := make([]mongo.WriteModel, len(batch))
+		for i, u := range batch {
+			models[i] = mongo.NewUpdateOneModel().
+				SetFilter(mongodb.MongoIDFilter(u.MongoID)).
+				SetUpdate(bson.M{"$set": bson.M{"email": strings.ToUpper(u.Email)}})
+		}
+		return models, nil
+	}
+
+	// Cancel after the first batch, it is still written completely.
+	cancelCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	report, err := mongodb.Backfill[*User](cancelCtx, repo, bson.M{}, 10, upperEmail,
+		mongodb.WithProgress(func(processed int, lastID interface{}) { cancel() }))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 10, report.Processed)
+	assert.Equal(t, 10, report.Modified)
+
+	count, err := repo.CountDocuments(ctx, bson.M{"email": bson.M{"$regex": "^USER"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 10, count)
+
+	// Resume from the checkpoint.
+	var progress []int
+	resumed, err := mongodb.Backfill[*User](ctx, repo, bson.M{}, 10, upperEmail,
+		mongodb.WithResumeAfter(report.LastID),
+		mongodb.WithMaxOpsPerSecond(1000),
+		mongodb.WithProgress(func(processed int, lastID interface{}) { progress = append(progress, processed) }))
+	if err != nil {
+		t.Fatalf("Error on resuming backfill: %v", err)
+	}
+	assert.Equal(t, 15, resumed.Processed)
+	assert.Equal(t, 2, resumed.Batches)
+	assert.Equal(t, []int{10, 15}, progress)
+
+	count, err = repo.CountDocuments(ctx, bson.M{"email": bson.M{"$regex": "^USER"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 25, count)
+}
@@ -0,0 +1,255 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	// ErrBatchWriterClosed is the result of operations queued on a [BatchWriter] after Close was called.
+	ErrBatchWriterClosed = errors.New("mongodb: batch writer is closed")
+	// ErrNotWritten is the result of queued operations that were not written, because an earlier operation of the same batch failed.
+	ErrNotWritten = errors.New("mongodb: not written after an earlier error in the batch")
+)
+
+// batchWriteTimeout bounds the writes of a [BatchWriter], which do not use the context of a caller.
+const batchWriteTimeout = time.Minute
+
+type (
+	// BatchWriter coalesces many small writes into few BulkWrite calls, e.g. for a collector that updates
+	// thousands of documents per second. Queued operations are written in batches of up to maxBatch operations,
+	// at the latest maxDelay after the first operation of a batch was queued.
+	//
+	// Batches are written one after another in the order the operations were queued, as ordered bulk writes.
+	// Every Queue method returns a channel that receives the result of the operation once its batch was written.
+	// Writes use their own context, so that queued operations are not lost when the context of a caller ends,
+	// which times out after a minute, so that a stalled write does not block all later batches forever.
+	BatchWriter[T Document[T]] struct {
+		r        BulkWrite
+		maxBatch int
+		maxDelay time.Duration
+
+		mu      sync.Mutex
+		pending []queuedWrite
+		timer   *time.Timer
+		closed  bool
+		// batches are the batches waiting for the writing goroutine, which is woken up by wake.
+		batches []writeBatch
+
+		wake    chan struct{}
+		stopped chan struct{}
+	}
+
+	queuedWrite struct {
+		model mongo.WriteModel
+		done  chan error
+	}
+
+	writeBatch struct {
+		writes  []queuedWrite
+		written chan struct{}
+	}
+)
+
+// NewBatchWriter creates a BatchWriter that writes to r. A maxBatch <= 0 uses batches of 1000 operations,
+// a maxDelay <= 0 only writes full batches and on Flush. The BatchWriter must be closed with Close.
+func NewBatchWriter[T Document[T]](r BulkWrite, maxBatch int, maxDelay time.Duration) *BatchWriter[T] {
+	if maxBatch <= 0 {
+		maxBatch = defaultChunkSize
+	}
+
+	w := &BatchWriter[T]{
+		r:        r,
+		maxBatch: maxBatch,
+		maxDelay: maxDelay,
+		wake:     make(chan struct{}, 1),
+		stopped:  make(chan struct{}),
+	}
+	go w.run()
+
+	return w
+}
+
+// QueueUpdate queues an update of the first document matching filter, which sets the fields of set and updatedAt,
+// like [Repository.UpdateOne].
+func (w *BatchWriter[T]) QueueUpdate(filter bson.M, set bson.M) <-chan error {
+	return w.queue(mongo.NewUpdateOneModel().
+		SetFilter(filter).
+		SetUpdate(bson.M{"$set": set, "$currentDate": bson.M{"updatedAt": true}}))
+}
+
+// QueueInsert queues the insert of doc, which is initialized like by [Repository.InsertOne] right away.
+func (w *BatchWriter[T]) QueueInsert(doc T) <-chan error {
+	doc.InitDocument()
+	return w.queue(mongo.NewInsertOneModel().SetDocument(doc))
+}
+
+// QueueDelete queues the deletion of the first document matching filter.
+func (w *BatchWriter[T]) QueueDelete(filter bson.M) <-chan error {
+	return w.queue(mongo.NewDeleteOneModel().SetFilter(filter))
+}
+
+func (w *BatchWriter[T]) queue(model mongo.WriteModel) <-chan error {
+	done := make(chan error, 1)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		done <- ErrBatchWriterClosed
+		close(done)
+		return done
+	}
+
+	w.pending = append(w.pending, queuedWrite{model: model, done: done})
+	switch {
+	case len(w.pending) >= w.maxBatch:
+		w.sendPending()
+	case len(w.pending) == 1 && w.maxDelay > 0:
+		w.timer = time.AfterFunc(w.maxDelay, w.flushPending)
+	}
+
+	return done
+}
+
+// flushPending is called by the timer.
+func (w *BatchWriter[T]) flushPending() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.pending) > 0 && !w.closed {
+		w.sendPending()
+	}
+}
+
+// sendPending passes the pending operations to the writing goroutine, and returns the batch.
+// It must be called with mu held, so that batches are sent in the order their operations were queued.
+// It never blocks, so that mu is not held while a batch is written.
+func (w *BatchWriter[T]) sendPending() writeBatch {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+
+	batch := writeBatch{writes: w.pending, written: make(chan struct{})}
+	w.pending = nil
+	w.batches = append(w.batches, batch)
+	select {
+	case w.wake <- struct{}{}:
+	default:
+		// The writing goroutine is already woken up.
+	}
+	return batch
+}
+
+// Flush writes all queued operations and waits until they and all earlier batches are written, or ctx ends.
+func (w *BatchWriter[T]) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return fmt.Errorf("%v: %w", "mongodb.BatchWriter.Flush", ErrBatchWriterClosed)
+	}
+	batch := w.sendPending()
+	w.mu.Unlock()
+
+	select {
+	case <-batch.written:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%v: %w", "mongodb.BatchWriter.Flush", ctx.Err())
+	}
+}
+
+// Close writes all queued operations and stops the BatchWriter. Operations queued afterwards fail with [ErrBatchWriterClosed].
+//
+// If ctx ends first, Close returns its error, but the queued operations are still written in the background.
+func (w *BatchWriter[T]) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.sendPending()
+	w.closed = true
+	w.mu.Unlock()
+
+	select {
+	case <-w.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%v: %w", "mongodb.BatchWriter.Close", ctx.Err())
+	}
+}
+
+func (w *BatchWriter[T]) run() {
+	defer close(w.stopped)
+
+	for {
+		w.mu.Lock()
+		batches, closed := w.batches, w.closed
+		w.batches = nil
+		w.mu.Unlock()
+
+		for _, batch := range batches {
+			w.write(batch)
+			close(batch.written)
+		}
+		// No batches are added after Close, so the ones taken together with closed were the last ones.
+		if closed {
+			return
+		}
+		<-w.wake
+	}
+}
+
+func (w *BatchWriter[T]) write(batch writeBatch) {
+	if len(batch.writes) == 0 {
+		return
+	}
+
+	models := make([]mongo.WriteModel, len(batch.writes))
+	for i, write := range batch.writes {
+		models[i] = write.model
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), batchWriteTimeout)
+	defer cancel()
+
+	_, err := w.r.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(true))
+	results := batchResults(err, len(models))
+	for i, write := range batch.writes {
+		write.done <- results[i]
+		close(write.done)
+	}
+}
+
+// batchResults maps the error of an ordered bulk write to the results of its operations.
+func batchResults(err error, n int) []error {
+	results := make([]error, n)
+	if err == nil {
+		return results
+	}
+
+	var bwe mongo.BulkWriteException
+	if !errors.As(err, &bwe) || len(bwe.WriteErrors) == 0 {
+		for i := range results {
+			results[i] = fmt.Errorf("%v: %w", "mongodb.BatchWriter", err)
+		}
+		return results
+	}
+
+	// An ordered bulk write stops at the first failed operation.
+	failed := bwe.WriteErrors[0]
+	results[failed.Index] = fmt.Errorf("%v: %w", "mongodb.BatchWriter", failed.WriteError)
+	for i := failed.Index + 1; i < n; i++ {
+		results[i] = fmt.Errorf("%v: %w", "mongodb.BatchWriter", ErrNotWritten)
+	}
+	return results
+}
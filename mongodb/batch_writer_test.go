@@ -0,0 +1,182 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// recordingBulkWriter records the batches it receives and fails them with err, if set.
+// If stall is set, every write waits until it is closed.
+type recordingBulkWriter struct {
+	mu      sync.Mutex
+	batches [][]mongo.WriteModel
+	err     error
+	stall   chan struct{}
+}
+
+func (r *recordingBulkWriter) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	if r.stall != nil {
+		select {
+		case <-r.stall:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, models)
+	return &mongo.BulkWriteResult{}, r.err
+}
+
+func (r *recordingBulkWriter) sizes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sizes := make([]int, len(r.batches))
+	for i, batch := range r.batches {
+		sizes[i] = len(batch)
+	}
+	return sizes
+}
+
+func TestBatchWriterFlushesOnSize(t *testing.T) {
+	rec := &recordingBulkWriter{}
+	w := mongodb.NewBatchWriter[*User](rec, 3, time.Hour)
+
+	var results []<-chan error
+	for i := 0; i < 7; i++ {
+		results = append(results, w.QueueUpdate(bson.M{"i": i}, bson.M{"name": "x"}))
+	}
+	for _, result := range results[:6] {
+		assert.NoError(t, <-result)
+	}
+	assert.Equal(t, []int{3, 3}, rec.sizes())
+
+	assert.NoError(t, w.Close(context.Background()))
+	assert.NoError(t, <-results[6])
+	assert.Equal(t, []int{3, 3, 1}, rec.sizes())
+}
+
+func TestBatchWriterFlushesOnTimer(t *testing.T) {
+	rec := &recordingBulkWriter{}
+	w := mongodb.NewBatchWriter[*User](rec, 100, 20*time.Millisecond)
+	defer w.Close(context.Background())
+
+	insert := w.QueueInsert(&User{Name: "a"})
+	del := w.QueueDelete(bson.M{"name": "b"})
+
+	select {
+	case err := <-insert:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("batch was not flushed after maxDelay")
+	}
+	assert.NoError(t, <-del)
+	assert.Equal(t, []int{2}, rec.sizes())
+
+	rec.mu.Lock()
+	assert.IsType(t, &mongo.InsertOneModel{}, rec.batches[0][0])
+	assert.IsType(t, &mongo.DeleteOneModel{}, rec.batches[0][1])
+	rec.mu.Unlock()
+}
+
+func TestBatchWriterPropagatesErrors(t *testing.T) {
+	rec := &recordingBulkWriter{err: mongo.BulkWriteException{
+		WriteErrors: []mongo.BulkWriteError{{WriteError: mongo.WriteError{Index: 1, Code: 11000, Message: "duplicate key"}}},
+	}}
+	w := mongodb.NewBatchWriter[*User](rec, 10, 0)
+
+	first := w.QueueDelete(bson.M{"i": 0})
+	second := w.QueueDelete(bson.M{"i": 1})
+	third := w.QueueDelete(bson.M{"i": 2})
+	assert.NoError(t, w.Flush(context.Background()))
+
+	assert.NoError(t, <-first)
+	var we mongo.WriteError
+	assert.ErrorAs(t, <-second, &we)
+	assert.Equal(t, 11000, we.Code)
+	assert.ErrorIs(t, <-third, mongodb.ErrNotWritten)
+
+	errFailed := errors.New("connection lost")
+	rec.mu.Lock()
+	rec.err = errFailed
+	rec.mu.Unlock()
+
+	a, b := w.QueueDelete(bson.M{"i": 3}), w.QueueDelete(bson.M{"i": 4})
+	assert.NoError(t, w.Close(context.Background()))
+	assert.ErrorIs(t, <-a, errFailed)
+	assert.ErrorIs(t, <-b, errFailed)
+}
+
+func TestBatchWriterCloseWritesQueued(t *testing.T) {
+	rec := &recordingBulkWriter{}
+	w := mongodb.NewBatchWriter[*User](rec, 4, time.Hour)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 5; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				assert.NoError(t, <-w.QueueUpdate(bson.M{"g": g, "i": i}, bson.M{"name": "x"}))
+			}
+		}(g)
+	}
+
+	// Flush unblocks writers waiting for a batch that is not full yet.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for w.Flush(context.Background()) == nil {
+		}
+	}()
+	wg.Wait()
+
+	pending := w.QueueUpdate(bson.M{"last": true}, bson.M{"name": "x"})
+	assert.NoError(t, w.Close(context.Background()))
+	<-done
+	assert.NoError(t, <-pending)
+
+	total := 0
+	for _, size := range rec.sizes() {
+		total += size
+	}
+	assert.Equal(t, 51, total)
+
+	assert.ErrorIs(t, <-w.QueueDelete(bson.M{}), mongodb.ErrBatchWriterClosed)
+	assert.ErrorIs(t, w.Flush(context.Background()), mongodb.ErrBatchWriterClosed)
+	assert.NoError(t, w.Close(context.Background()))
+}
+
+func TestBatchWriterFlushWithStalledWrite(t *testing.T) {
+	rec := &recordingBulkWriter{stall: make(chan struct{})}
+	w := mongodb.NewBatchWriter[*User](rec, 1, 0)
+
+	first := w.QueueUpdate(bson.M{"i": 1}, bson.M{"name": "x"})
+	// The first batch is stalled, the second waits for it.
+	second := w.QueueUpdate(bson.M{"i": 2}, bson.M{"name": "x"})
+	third := w.QueueUpdate(bson.M{"i": 3}, bson.M{"name": "x"})
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		err := w.Flush(ctx)
+		cancel()
+		assert.ErrorIs(t, err, context.DeadlineExceeded, "Flush returns when its context ends")
+	}
+
+	close(rec.stall)
+	assert.NoError(t, w.Close(context.Background()))
+	for _, result := range []<-chan error{first, second, third} {
+		assert.NoError(t, <-result)
+	}
+	assert.Equal(t, []int{1, 1, 1}, rec.sizes())
+}
@@ -0,0 +1,106 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExhausted is returned by operations whose context has no query budget left, see [WithQueryBudget].
+var ErrBudgetExhausted = errors.New("mongodb: query budget exhausted")
+
+type (
+	// queryBudget is the remaining time of a [WithQueryBudget] context, shared by all operations with that context.
+	queryBudget struct {
+		mu        sync.Mutex
+		remaining time.Duration
+		parent    *queryBudget
+	}
+
+	queryBudgetKey struct{}
+)
+
+// WithQueryBudget limits the cumulative time all repository operations with the returned context may spend in MongoDB,
+// e.g. to cap the time of a single HTTP request.
+//
+// Every operation runs with the remaining budget as deadline, and the time it took is subtracted once it finished.
+// Once the budget is spent, operations fail with [ErrBudgetExhausted] without contacting the server,
+// and an operation that ran into the deadline of the budget returns an error wrapping ErrBudgetExhausted as well.
+// Operations of goroutines sharing the context are all subtracted, so concurrent operations spend the budget faster than the wall clock.
+//
+// A budget within a budget is limited by both: operations subtract their time from all of them,
+// and the smallest remaining budget is the deadline.
+//
+// Cursors that are returned by an operation, e.g. by Aggregate, are not limited once the operation returned.
+func WithQueryBudget(ctx context.Context, d time.Duration) context.Context {
+	parent, _ := ctx.Value(queryBudgetKey{}).(*queryBudget)
+
+	return context.WithValue(ctx, queryBudgetKey{}, &queryBudget{remaining: d, parent: parent})
+}
+
+// QueryBudget returns the remaining query budget of ctx, and false if ctx has no budget.
+func QueryBudget(ctx context.Context) (time.Duration, bool) {
+	budget, ok := ctx.Value(queryBudgetKey{}).(*queryBudget)
+	if !ok {
+		return 0, false
+	}
+
+	return budget.left(), true
+}
+
+// SpendQueryBudget starts an operation with the query budget of ctx, for implementations of repositories other than [Repository],
+// like fakes and decorators, which gets the same accounting built in.
+//
+// It returns the context for the operation, with the remaining budget as deadline, and a function that must be called
+// with the error of the operation once it finished. That function subtracts the time of the operation, and returns the error to pass on.
+// If the budget is already spent, an error wrapping [ErrBudgetExhausted] is returned. Without a budget, ctx is returned unchanged.
+func SpendQueryBudget(ctx context.Context) (context.Context, func(error) error, error) {
+	budget, ok := ctx.Value(queryBudgetKey{}).(*queryBudget)
+	if !ok {
+		return ctx, noopFinish, nil
+	}
+
+	remaining := budget.left()
+	if remaining <= 0 {
+		return ctx, nil, ErrBudgetExhausted
+	}
+
+	started := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, remaining)
+
+	return ctx, func(err error) error {
+		deadlineExceeded := errors.Is(ctx.Err(), context.DeadlineExceeded)
+		cancel()
+		budget.spend(time.Since(started))
+
+		if err != nil && deadlineExceeded && budget.left() <= 0 && !errors.Is(err, ErrBudgetExhausted) {
+			return fmt.Errorf("%w: %w", ErrBudgetExhausted, err)
+		}
+		return err
+	}, nil
+}
+
+// left returns the smallest remaining time of the budget and its parents.
+func (b *queryBudget) left() time.Duration {
+	b.mu.Lock()
+	remaining := b.remaining
+	b.mu.Unlock()
+
+	if b.parent != nil {
+		if parent := b.parent.left(); parent < remaining {
+			return parent
+		}
+	}
+
+	return remaining
+}
+
+func (b *queryBudget) spend(d time.Duration) {
+	for budget := b; budget != nil; budget = budget.parent {
+		budget.mu.Lock()
+		budget.remaining -= d
+		budget.mu.Unlock()
+	}
+}
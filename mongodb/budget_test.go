@@ -0,0 +1,142 @@
+package mongodb_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// slowCounter is a fake repository whose CountDocuments takes latency, or until the context is done.
+type slowCounter struct {
+	latency time.Duration
+}
+
+func (c slowCounter) CountDocuments(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (_ int, err error) {
+	ctx, spent, err := mongodb.SpendQueryBudget(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { err = spent(err) }()
+
+	select {
+	case <-time.After(c.latency):
+		return 1, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func TestQueryBudget(t *testing.T) {
+	ctx := mongodb.WithQueryBudget(context.Background(), 100*time.Millisecond)
+	counter := slowCounter{latency: 40 * time.Millisecond}
+
+	for i := 0; i < 2; i++ {
+		_, err := counter.CountDocuments(ctx, bson.M{})
+		assert.NoError(t, err, "call %d", i+1)
+	}
+
+	// The third call runs into the deadline of the remaining 20ms.
+	started := time.Now()
+	_, err := counter.CountDocuments(ctx, bson.M{})
+	assert.ErrorIs(t, err, mongodb.ErrBudgetExhausted)
+	assert.Less(t, time.Since(started), 40*time.Millisecond)
+
+	// Later calls fail immediately.
+	_, err = counter.CountDocuments(ctx, bson.M{})
+	assert.ErrorIs(t, err, mongodb.ErrBudgetExhausted)
+
+	remaining, ok := mongodb.QueryBudget(ctx)
+	assert.True(t, ok)
+	assert.LessOrEqual(t, remaining, time.Duration(0))
+}
+
+func TestNestedQueryBudget(t *testing.T) {
+	outer := mongodb.WithQueryBudget(context.Background(), time.Second)
+	inner := mongodb.WithQueryBudget(outer, 50*time.Millisecond)
+	counter := slowCounter{latency: 30 * time.Millisecond}
+
+	_, err := counter.CountDocuments(inner, bson.M{})
+	assert.NoError(t, err)
+	_, err = counter.CountDocuments(inner, bson.M{})
+	assert.ErrorIs(t, err, mongodb.ErrBudgetExhausted)
+
+	// The inner calls were subtracted from the outer budget too.
+	remaining, _ := mongodb.QueryBudget(outer)
+	assert.Less(t, remaining, 950*time.Millisecond)
+
+	// A larger inner budget is limited by the outer one.
+	small := mongodb.WithQueryBudget(context.Background(), 10*time.Millisecond)
+	remaining, _ = mongodb.QueryBudget(mongodb.WithQueryBudget(small, time.Hour))
+	assert.Equal(t, 10*time.Millisecond, remaining)
+
+	_, ok := mongodb.QueryBudget(context.Background())
+	assert.False(t, ok)
+}
+
+func TestQueryBudgetConcurrent(t *testing.T) {
+	ctx := mongodb.WithQueryBudget(context.Background(), 100*time.Millisecond)
+	counter := slowCounter{latency: 10 * time.Millisecond}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	exhausted := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				if _, err := counter.CountDocuments(ctx, bson.M{}); err != nil {
+					assert.ErrorIs(t, err, mongodb.ErrBudgetExhausted)
+					mu.Lock()
+					exhausted++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Greater(t, exhausted, 0)
+}
+
+func TestRepositoryQueryBudget(t *testing.T) {
+	ctx := context.Background()
+	// Connect does not need a server, the operation is rejected before it is sent.
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	var hook recordingHook
+	repo := mongodb.NewRepository[*User](client.Database("testdb").Collection("user_budget"), mongodb.WithHook(&hook))
+
+	_, err = repo.FindMany(mongodb.WithQueryBudget(ctx, 0), bson.M{})
+	assert.ErrorIs(t, err, mongodb.ErrBudgetExhausted)
+	assert.ErrorIs(t, hook.err, mongodb.ErrBudgetExhausted)
+
+	// Without a server, the operation waits for server selection until the budget is spent.
+	_, err = repo.FindMany(mongodb.WithQueryBudget(ctx, 50*time.Millisecond), bson.M{})
+	assert.ErrorIs(t, err, mongodb.ErrBudgetExhausted)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "the error of the operation is kept")
+}
+
+type recordingHook struct {
+	err error
+}
+
+func (h *recordingHook) Before(ctx context.Context, op *mongodb.Operation) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h *recordingHook) After(ctx context.Context, op *mongodb.Operation, err error) error {
+	h.err = err
+	return err
+}
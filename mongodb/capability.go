@@ -0,0 +1,282 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// Forwarder passes all operations to the embedded repository, including those that are not part of [RepositoryI],
+	// which fail with an error wrapping [ErrUnsupported] if the embedded repository does not implement them.
+	//
+	// Repositories that wrap another one embed it, and only implement the operations they change, like the
+	// repositories of [NewAuditedRepository] or [NewDryRunRepository]. WithSession is not forwarded, since it
+	// would return the embedded repository without the wrapper, use [WithSession] to bind the embedded repository.
+	//
+	// Since a Forwarder has every method, [Capability] succeeds for every repository that embeds one, whatever
+	// the embedded repository implements. Whether the operation is supported is only known when it is called.
+	Forwarder[T Document[T]] struct {
+		RepositoryI[T]
+	}
+)
+
+// Capability returns r as C, one of the interfaces of the operations that are not part of [RepositoryI], e.g. [GetByID]
+// or [IndexManager]. It returns an error wrapping [ErrUnsupported] if r does not implement C.
+//
+// Repositories that embed a [Forwarder] implement every C, their operations return the error instead.
+//
+//	indexes, err := mongodb.Capability[mongodb.IndexManager](repo)
+func Capability[C any](r interface{}) (C, error) {
+	c, ok := r.(C)
+	if !ok {
+		return c, fmt.Errorf("%w: %T does not implement %v", ErrUnsupported, r, reflect.TypeOf((*C)(nil)).Elem())
+	}
+	return c, nil
+}
+
+// WithSession returns a copy of r whose operations all run within sess, see [SessionBinder].
+// It panics if r does not implement [SessionBinder], since running the operations outside of the session would go unnoticed.
+func WithSession[T Document[T]](r RepositoryI[T], sess mongo.Session) RepositoryI[T] {
+	binder, err := Capability[SessionBinder[T]](r)
+	if err != nil {
+		panic(err)
+	}
+	return binder.WithSession(sess)
+}
+
+func (r Forwarder[T]) decodeResult(ctx context.Context, cur *mongo.Cursor) (T, bool, error) {
+	return decodeResultOf(ctx, r.RepositoryI, cur)
+}
+
+func (r Forwarder[T]) GetByID(ctx context.Context, id primitive.ObjectID, projection ...string) (T, error) {
+	inner, err := Capability[GetByID[T]](r.RepositoryI)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return inner.GetByID(ctx, id, projection...)
+}
+
+func (r Forwarder[T]) FindManyN(ctx context.Context, filter bson.M, expectedCount int, opts ...*options.FindOptions) ([]T, error) {
+	inner, err := Capability[FindManyN[T]](r.RepositoryI)
+	if err != nil {
+		return nil, err
+	}
+	return inner.FindManyN(ctx, filter, expectedCount, opts...)
+}
+
+func (r Forwarder[T]) FindManyRaw(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]bson.Raw, error) {
+	inner, err := Capability[FindManyRaw](r.RepositoryI)
+	if err != nil {
+		return nil, err
+	}
+	return inner.FindManyRaw(ctx, filter, opts...)
+}
+
+func (r Forwarder[T]) FindCursor(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	inner, err := Capability[CursorFinder](r.RepositoryI)
+	if err != nil {
+		return nil, err
+	}
+	return inner.FindCursor(ctx, filter, opts...)
+}
+
+func (r Forwarder[T]) FindManyParallel(ctx context.Context, filter bson.M, parallelism int, fn func([]T) error) error {
+	inner, err := Capability[ParallelFinder[T]](r.RepositoryI)
+	if err != nil {
+		return err
+	}
+	return inner.FindManyParallel(ctx, filter, parallelism, fn)
+}
+
+func (r Forwarder[T]) InsertManyResult(ctx context.Context, docs []T, opts ...*options.InsertManyOptions) ([]T, *mongo.InsertManyResult, error) {
+	inner, err := Capability[InsertManyResult[T]](r.RepositoryI)
+	if err != nil {
+		return nil, nil, err
+	}
+	return inner.InsertManyResult(ctx, docs, opts...)
+}
+
+func (r Forwarder[T]) UpdateManyResult(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	inner, err := Capability[UpdateManyResult](r.RepositoryI)
+	if err != nil {
+		return nil, err
+	}
+	return inner.UpdateManyResult(ctx, filter, data, opts...)
+}
+
+func (r Forwarder[T]) FindOneOrCreate(ctx context.Context, filter bson.M, defaultDoc T, opts ...*options.FindOneAndUpdateOptions) (T, bool, error) {
+	inner, err := Capability[FindOneOrCreate[T]](r.RepositoryI)
+	if err != nil {
+		return defaultDoc, false, err
+	}
+	return inner.FindOneOrCreate(ctx, filter, defaultDoc, opts...)
+}
+
+func (r Forwarder[T]) UpsertManyByKey(ctx context.Context, docs []T, keyFields []string, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	inner, err := Capability[UpsertManyByKey[T]](r.RepositoryI)
+	if err != nil {
+		return nil, err
+	}
+	return inner.UpsertManyByKey(ctx, docs, keyFields, opts...)
+}
+
+func (r Forwarder[T]) FindOneWhere(ctx context.Context, filter Filter, opts ...*options.FindOneOptions) (T, error) {
+	inner, err := Capability[FilterQuerier[T]](r.RepositoryI)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return inner.FindOneWhere(ctx, filter, opts...)
+}
+
+func (r Forwarder[T]) FindManyWhere(ctx context.Context, filter Filter, opts ...*options.FindOptions) ([]T, error) {
+	inner, err := Capability[FilterQuerier[T]](r.RepositoryI)
+	if err != nil {
+		return nil, err
+	}
+	return inner.FindManyWhere(ctx, filter, opts...)
+}
+
+func (r Forwarder[T]) UpdateOneWhere(ctx context.Context, filter Filter, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	inner, err := Capability[FilterQuerier[T]](r.RepositoryI)
+	if err != nil {
+		return nil, err
+	}
+	return inner.UpdateOneWhere(ctx, filter, data, opts...)
+}
+
+func (r Forwarder[T]) UpdateManyWhere(ctx context.Context, filter Filter, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	inner, err := Capability[FilterQuerier[T]](r.RepositoryI)
+	if err != nil {
+		return nil, err
+	}
+	return inner.UpdateManyWhere(ctx, filter, data, opts...)
+}
+
+func (r Forwarder[T]) DeleteOneWhere(ctx context.Context, filter Filter, opts ...*options.DeleteOptions) error {
+	inner, err := Capability[FilterQuerier[T]](r.RepositoryI)
+	if err != nil {
+		return err
+	}
+	return inner.DeleteOneWhere(ctx, filter, opts...)
+}
+
+func (r Forwarder[T]) DeleteManyWhere(ctx context.Context, filter Filter, opts ...*options.DeleteOptions) (int, error) {
+	inner, err := Capability[FilterQuerier[T]](r.RepositoryI)
+	if err != nil {
+		return 0, err
+	}
+	return inner.DeleteManyWhere(ctx, filter, opts...)
+}
+
+func (r Forwarder[T]) CountWhere(ctx context.Context, filter Filter, opts ...*options.CountOptions) (int, error) {
+	inner, err := Capability[FilterQuerier[T]](r.RepositoryI)
+	if err != nil {
+		return 0, err
+	}
+	return inner.CountWhere(ctx, filter, opts...)
+}
+
+func (r Forwarder[T]) DeleteManyByIDs(ctx context.Context, ids []primitive.ObjectID, chunkSize int) (int, error) {
+	inner, err := Capability[DeleteManyByIDs](r.RepositoryI)
+	if err != nil {
+		return 0, err
+	}
+	return inner.DeleteManyByIDs(ctx, ids, chunkSize)
+}
+
+func (r Forwarder[T]) DeleteManyAudited(ctx context.Context, filter bson.M, batchSize int, onBatch func(deletedIDs []primitive.ObjectID) error) (int, error) {
+	inner, err := Capability[DeleteManyAudited](r.RepositoryI)
+	if err != nil {
+		return 0, err
+	}
+	return inner.DeleteManyAudited(ctx, filter, batchSize, onBatch)
+}
+
+func (r Forwarder[T]) Watch(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error) {
+	inner, err := Capability[Watcher](r.RepositoryI)
+	if err != nil {
+		return nil, err
+	}
+	return inner.Watch(ctx, pipeline, opts...)
+}
+
+func (r Forwarder[T]) Drop(ctx context.Context) error {
+	inner, err := Capability[CollectionAdmin](r.RepositoryI)
+	if err != nil {
+		return err
+	}
+	return inner.Drop(ctx)
+}
+
+func (r Forwarder[T]) Stats(ctx context.Context) (CollectionStats, error) {
+	inner, err := Capability[CollectionAdmin](r.RepositoryI)
+	if err != nil {
+		return CollectionStats{}, err
+	}
+	return inner.Stats(ctx)
+}
+
+func (r Forwarder[T]) Verify(ctx context.Context) error {
+	inner, err := Capability[Verifier](r.RepositoryI)
+	if err != nil {
+		return err
+	}
+	return inner.Verify(ctx)
+}
+
+func (r Forwarder[T]) Distinct(ctx context.Context, path FieldPath, filter bson.M, opts ...*options.DistinctOptions) ([]interface{}, error) {
+	inner, err := Capability[Distincter](r.RepositoryI)
+	if err != nil {
+		return nil, err
+	}
+	return inner.Distinct(ctx, path, filter, opts...)
+}
+
+func (r Forwarder[T]) CreateIndex(ctx context.Context, keys bson.D, opts ...*options.IndexOptions) (string, error) {
+	inner, err := Capability[IndexManager](r.RepositoryI)
+	if err != nil {
+		return "", err
+	}
+	return inner.CreateIndex(ctx, keys, opts...)
+}
+
+func (r Forwarder[T]) CreateIndexes(ctx context.Context, models []mongo.IndexModel) ([]string, error) {
+	inner, err := Capability[IndexManager](r.RepositoryI)
+	if err != nil {
+		return nil, err
+	}
+	return inner.CreateIndexes(ctx, models)
+}
+
+func (r Forwarder[T]) DropIndex(ctx context.Context, name string) error {
+	inner, err := Capability[IndexManager](r.RepositoryI)
+	if err != nil {
+		return err
+	}
+	return inner.DropIndex(ctx, name)
+}
+
+func (r Forwarder[T]) ListIndexes(ctx context.Context) ([]IndexInfo, error) {
+	inner, err := Capability[IndexManager](r.RepositoryI)
+	if err != nil {
+		return nil, err
+	}
+	return inner.ListIndexes(ctx)
+}
+
+func (r Forwarder[T]) SetIndexExpireAfter(ctx context.Context, name string, expireAfter time.Duration) error {
+	inner, err := Capability[IndexManager](r.RepositoryI)
+	if err != nil {
+		return err
+	}
+	return inner.SetIndexExpireAfter(ctx, name, expireAfter)
+}
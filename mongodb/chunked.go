@@ -0,0 +1,76 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultChunkSize is the chunk size used for chunkSize <= 0.
+const defaultChunkSize = 1000
+
+type (
+	DeleteManyByIDs interface {
+		// Deletes the documents with the given _ids in chunks, and returns the number of documents that were deleted.
+		DeleteManyByIDs(ctx context.Context, ids []primitive.ObjectID, chunkSize int) (int, error)
+	}
+)
+
+// ChunkedIn removes duplicates from values and calls run with an {"$in": chunk} value for every chunk of up to chunkSize values,
+// e.g. to use as bson.M{"_id": filterValue}. A chunkSize <= 0 uses chunks of 1000 values.
+//
+// Large $in filters are slow and can exceed the maximum BSON document size, chunking keeps every query small.
+// ChunkedIn stops at the first error of run. Since run is not given a context, it should check for cancellation itself.
+func ChunkedIn[T comparable](values []T, chunkSize int, run func(filterValue primitive.M) error) error {
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	seen := make(map[T]struct{}, len(values))
+	unique := make([]T, 0, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		unique = append(unique, v)
+	}
+
+	for start := 0; start < len(unique); start += chunkSize {
+		end := start + chunkSize
+		if end > len(unique) {
+			end = len(unique)
+		}
+
+		if err := run(primitive.M{"$in": unique[start:end]}); err != nil {
+			return fmt.Errorf("chunk %d: %w", start/chunkSize, err)
+		}
+	}
+
+	return nil
+}
+
+// Deletes the documents with the given _ids in chunks of chunkSize, and returns the number of documents that were deleted.
+// Duplicate ids are ignored. A chunkSize <= 0 uses chunks of 1000 ids.
+//
+// The chunks are deleted one after another with [Repository.DeleteMany]. If ctx is canceled or a chunk fails,
+// the number of documents deleted so far is returned together with the error.
+func (r *Repository[T]) DeleteManyByIDs(ctx context.Context, ids []primitive.ObjectID, chunkSize int) (int, error) {
+	deleted := 0
+	err := ChunkedIn(ids, chunkSize, func(filterValue primitive.M) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, err := r.DeleteMany(ctx, bson.M{"_id": filterValue})
+		deleted += n
+		return err
+	})
+	if err != nil {
+		return deleted, fmt.Errorf("%v: %w", "mongodb.Repository.DeleteManyByIDs", err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,72 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestChunkedIn(t *testing.T) {
+	var chunks [][]int
+	err := mongodb.ChunkedIn([]int{1, 2, 2, 3, 4, 5, 1}, 2, func(filterValue primitive.M) error {
+		chunks = append(chunks, filterValue["$in"].([]int))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, chunks)
+
+	boom := errors.New("boom")
+	calls := 0
+	err = mongodb.ChunkedIn([]int{1, 2, 3}, 1, func(filterValue primitive.M) error {
+		calls++
+		return boom
+	})
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, 1, calls)
+
+	err = mongodb.ChunkedIn([]string{}, 0, func(filterValue primitive.M) error {
+		t.Fatal("run must not be called without values")
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestDeleteManyByIDs(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_delete_by_ids")).(*mongodb.Repository[*User])
+
+	users := make([]*User, 25_000)
+	for i := range users {
+		users[i] = &User{Name: "User"}
+	}
+	if _, err := repo.InsertMany(ctx, users); err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(users)+1)
+	for _, u := range users {
+		ids = append(ids, u.MongoID)
+	}
+	// Duplicates are ignored.
+	ids = append(ids, users[0].MongoID)
+
+	deleted, err := repo.DeleteManyByIDs(ctx, ids, 1000)
+	if err != nil {
+		t.Fatalf("Error on deleting users: %v", err)
+	}
+	assert.Equal(t, 25_000, deleted)
+
+	count, err := repo.CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = repo.DeleteManyByIDs(canceled, ids, 1000)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,99 @@
+package mongodb
+
+import (
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	// Clock provides the current time for the timestamps set by [BaseModel] and [Repository].
+	//
+	// Note that UpdateOne and UpdateMany set updatedAt with $currentDate, which always uses the server time.
+	Clock interface {
+		Now() time.Time
+	}
+
+	// IDGenerator provides new MongoIDs for [BaseModel.InitMongoID].
+	IDGenerator interface {
+		NewID() primitive.ObjectID
+	}
+
+	systemClock       struct{}
+	objectIDGenerator struct{}
+)
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (objectIDGenerator) NewID() primitive.ObjectID {
+	return primitive.NewObjectID()
+}
+
+var (
+	identityMu  sync.RWMutex
+	clock       Clock       = systemClock{}
+	idGenerator IDGenerator = objectIDGenerator{}
+)
+
+// SetClock replaces the package-wide Clock and returns a function that restores the previous one.
+// Passing nil restores the system clock.
+//
+// The clock is global state, so tests that replace it must not run in parallel with other tests that create documents.
+func SetClock(c Clock) (restore func()) {
+	if c == nil {
+		c = systemClock{}
+	}
+
+	identityMu.Lock()
+	previous := clock
+	clock = c
+	identityMu.Unlock()
+
+	return func() {
+		identityMu.Lock()
+		clock = previous
+		identityMu.Unlock()
+	}
+}
+
+// SetIDGenerator replaces the package-wide IDGenerator and returns a function that restores the previous one.
+// Passing nil restores the default generator, primitive.NewObjectID.
+//
+// The generator is global state, see [SetClock].
+func SetIDGenerator(g IDGenerator) (restore func()) {
+	if g == nil {
+		g = objectIDGenerator{}
+	}
+
+	identityMu.Lock()
+	previous := idGenerator
+	idGenerator = g
+	identityMu.Unlock()
+
+	return func() {
+		identityMu.Lock()
+		idGenerator = previous
+		identityMu.Unlock()
+	}
+}
+
+// now returns the current time of the package-wide Clock.
+func now() time.Time {
+	identityMu.RLock()
+	c := clock
+	identityMu.RUnlock()
+
+	return c.Now()
+}
+
+// newID returns a new MongoID of the package-wide IDGenerator.
+func newID() primitive.ObjectID {
+	identityMu.RLock()
+	g := idGenerator
+	identityMu.RUnlock()
+
+	return g.NewID()
+}
@@ -0,0 +1,122 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+type (
+	// CollectionProvider is implemented by [Repository], for code that needs the underlying collection of a repository,
+	// e.g. to run a command the repository does not offer.
+	//
+	// It is not part of [RepositoryI], since decorators and fakes usually have no collection.
+	CollectionProvider interface {
+		// Returns the collection handle the repository uses for operations with ctx.
+		CollectionFor(ctx context.Context) *mongo.Collection
+	}
+
+	// collectionClones caches the clones of a collection per read and write concern.
+	collectionClones struct {
+		mu     sync.Mutex
+		clones map[string]*mongo.Collection
+	}
+)
+
+type readConcernOption struct {
+	rc *readconcern.ReadConcern
+}
+
+func (value readConcernOption) apply(o *opOption) {
+	o.readConcern = value.rc
+}
+
+// ReadConcern runs an operation with the given read concern, e.g. readconcern.Linearizable() for a single critical read.
+//
+// The concern of an operation is the first set of: the ReadConcern of the context, see [WithOpOptions],
+// the concern of the collection of the repository, and the concern of the database and client of the DataStore.
+func ReadConcern(rc *readconcern.ReadConcern) OpOption {
+	return readConcernOption{rc: rc}
+}
+
+type writeConcernOption struct {
+	wc *writeconcern.WriteConcern
+}
+
+func (value writeConcernOption) apply(o *opOption) {
+	o.writeConcern = value.wc
+}
+
+// WriteConcern runs an operation with the given write concern, e.g. writeconcern.Majority() for a single critical write.
+// The precedence is the same as for [ReadConcern].
+func WriteConcern(wc *writeconcern.WriteConcern) OpOption {
+	return writeConcernOption{wc: wc}
+}
+
+// CollectionFor returns the collection handle the repository uses for operations with ctx.
+//
+// If ctx carries a [ReadConcern] or [WriteConcern], this is a clone of the collection with these concerns.
+// Clones are cached per concern, so repeated operations with the same concern share a handle.
+func (r *Repository[T]) CollectionFor(ctx context.Context) *mongo.Collection {
+	ops, _ := ctx.Value(opOptionsKey{}).(opOption)
+	if ops.readConcern == nil && ops.writeConcern == nil {
+		return r.db
+	}
+
+	if r.clones == nil {
+		return cloneWithConcerns(r.db, ops)
+	}
+
+	key := concernKey(ops)
+
+	r.clones.mu.Lock()
+	defer r.clones.mu.Unlock()
+
+	if clone, ok := r.clones.clones[key]; ok {
+		return clone
+	}
+	clone := cloneWithConcerns(r.db, ops)
+	r.clones.clones[key] = clone
+
+	return clone
+}
+
+func cloneWithConcerns(col *mongo.Collection, ops opOption) *mongo.Collection {
+	opts := options.Collection()
+	if ops.readConcern != nil {
+		opts.SetReadConcern(ops.readConcern)
+	}
+	if ops.writeConcern != nil {
+		opts.SetWriteConcern(ops.writeConcern)
+	}
+
+	// Clone only fails for invalid options, which concerns can not be.
+	clone, err := col.Clone(opts)
+	if err != nil {
+		panic(fmt.Sprintf("mongodb: cloning collection %v: %v", col.Name(), err))
+	}
+
+	return clone
+}
+
+func concernKey(ops opOption) string {
+	key := "rc:"
+	if ops.readConcern != nil {
+		key += ops.readConcern.Level
+	}
+	key += ";wc:"
+	if wc := ops.writeConcern; wc != nil {
+		key += fmt.Sprintf("%v,", wc.W)
+		if wc.Journal != nil {
+			key += fmt.Sprintf("%v", *wc.Journal)
+		}
+		key += fmt.Sprintf(",%v", wc.WTimeout)
+	}
+
+	return key
+}
@@ -0,0 +1,76 @@
+package mongodb_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+func TestCollectionForCachesClones(t *testing.T) {
+	ctx := context.Background()
+	// Connect does not need a server, no operation is run.
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	col := client.Database("testdb").Collection("user_concern_cache")
+	repo := mongodb.NewRepository[*User](col).(*mongodb.Repository[*User])
+
+	assert.Same(t, col, repo.CollectionFor(ctx))
+
+	majority := mongodb.WithOpOptions(ctx, mongodb.WriteConcern(writeconcern.Majority()))
+	clone := repo.CollectionFor(majority)
+	assert.NotSame(t, col, clone)
+	assert.Same(t, clone, repo.CollectionFor(mongodb.WithOpOptions(ctx, mongodb.WriteConcern(writeconcern.Majority()))))
+
+	linearizable := repo.CollectionFor(mongodb.WithOpOptions(ctx, mongodb.ReadConcern(readconcern.Linearizable())))
+	assert.NotSame(t, clone, linearizable)
+
+	// Other options do not need a clone.
+	assert.Same(t, col, repo.CollectionFor(mongodb.WithOpOptions(ctx, mongodb.Comment("report"))))
+}
+
+func TestPerCallConcerns(t *testing.T) {
+	var mu sync.Mutex
+	commands := map[string]bson.Raw{}
+	monitor := &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			commands[e.CommandName] = e.Command
+		},
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017").SetMonitor(monitor))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	col := client.Database("testdb").Collection("user_concern")
+	t.Cleanup(func() {
+		col.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+	repo := mongodb.NewRepository[*User](col)
+
+	_, err = repo.InsertOne(mongodb.WithOpOptions(ctx, mongodb.WriteConcern(writeconcern.Majority())), &User{Name: "alice"})
+	assert.NoError(t, err)
+	_, err = repo.FindMany(mongodb.WithOpOptions(ctx, mongodb.ReadConcern(readconcern.Majority())), bson.M{})
+	assert.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "majority", commands["insert"].Lookup("writeConcern", "w").StringValue())
+	assert.Equal(t, "majority", commands["find"].Lookup("readConcern", "level").StringValue())
+}
@@ -0,0 +1,40 @@
+package mongodb_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongotest"
+)
+
+func TestRepositoryConformance(t *testing.T) {
+	newRepository := func(t *testing.T) mongodb.RepositoryI[*User] {
+		return mongodb.NewRepository[*User](testCollection(t, "user_conformance"))
+	}
+
+	// Dry runs are left out, since their writes are not executed.
+	for name, factory := range map[string]func(t *testing.T) mongodb.RepositoryI[*User]{
+		"Repository": newRepository,
+		"Spy": func(t *testing.T) mongodb.RepositoryI[*User] {
+			_, repo := mongotest.NewSpy(newRepository(t))
+			return repo
+		},
+		"Audited": func(t *testing.T) mongodb.RepositoryI[*User] {
+			history := mongodb.NewRepository[*mongodb.ChangeRecord](testCollection(t, "user_conformance_history"))
+			return mongodb.NewAuditedRepository(newRepository(t), history)
+		},
+		"Singleflight": func(t *testing.T) mongodb.RepositoryI[*User] {
+			return mongodb.NewSingleflightRepository(newRepository(t))
+		},
+		"Chaos": func(t *testing.T) mongodb.RepositoryI[*User] {
+			return mongotest.NewChaosRepository(newRepository(t), 1)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mongotest.RunRepositorySuite(t, factory, func(i int) *User {
+				return &User{Name: fmt.Sprintf("User %d", i), Email: fmt.Sprintf("user%d@example.com", i)}
+			})
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultCopyBatchSize = 500
+
+type (
+	// CopyReport summarizes the result of [CopyDocuments].
+	CopyReport struct {
+		// Copied is the number of documents written to the destination.
+		Copied int
+		// Skipped is the number of documents the transform rejected.
+		Skipped int
+		// Failed is the number of documents that could not be decoded or written.
+		Failed int
+		// LastID is the _id of the last document of the last written batch.
+		// A copy that stopped with an error can be resumed with the filter {"_id": {"$gt": LastID}}.
+		LastID interface{}
+	}
+)
+
+// CopyDocuments copies all documents matching filter from src to dst and reports what was copied.
+//
+// The documents are streamed from src ordered by _id, passed through transform and written to dst in batches of
+// batchSize via BulkWrite, replacing documents with the same _id. Copying is therefore idempotent and can be resumed,
+// see [CopyReport.LastID]. transform may be nil; if it returns false the document is skipped.
+// A batchSize <= 0 uses a batch size of 500.
+//
+// Copying a collection onto itself is refused with [ErrSameCollection], since the cursor could see its own writes.
+func CopyDocuments[T Document[T]](ctx context.Context, src, dst RepositoryI[T], filter bson.M, transform func(T) (T, bool), batchSize int) (CopyReport, error) {
+	report := CopyReport{}
+	if sameRepository(src, dst) {
+		return report, fmt.Errorf("%v: %w", "mongodb.CopyDocuments", ErrSameCollection)
+	}
+	if batchSize <= 0 {
+		batchSize = defaultCopyBatchSize
+	}
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	cur, err := src.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	})
+	if err != nil {
+		return report, fmt.Errorf("%v: %w", "mongodb.CopyDocuments", err)
+	}
+	defer cur.Close(context.Background())
+
+	models := make([]mongo.WriteModel, 0, batchSize)
+	var lastID interface{}
+
+	flush := func() error {
+		if len(models) == 0 {
+			return nil
+		}
+
+		res, err := dst.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		var bwe mongo.BulkWriteException
+		if err != nil && (!errors.As(err, &bwe) || bwe.WriteConcernError != nil) {
+			return err
+		}
+
+		if res != nil {
+			report.Copied += int(res.UpsertedCount + res.MatchedCount)
+		}
+		report.Failed += len(bwe.WriteErrors)
+		report.LastID = lastID
+		models = models[:0]
+		return nil
+	}
+
+	for cur.Next(ctx) {
+		// Remember the source _id, so that resuming works even if transform changes it.
+		if id, err := cur.Current.LookupErr("_id"); err == nil {
+			lastID = id
+		}
+
+		doc := newTValue[T]()
+		if err := cur.Decode(doc); err != nil {
+			report.Failed++
+			continue
+		}
+
+		if transform != nil {
+			var ok bool
+			if doc, ok = transform(doc); !ok {
+				report.Skipped++
+				continue
+			}
+		}
+
+		id, err := documentID(registryOf(src), doc)
+		if err != nil {
+			report.Failed++
+			continue
+		}
+		models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.M{"_id": id}).SetReplacement(doc).SetUpsert(true))
+
+		if len(models) >= batchSize {
+			if err := flush(); err != nil {
+				return report, fmt.Errorf("%v: %w", "mongodb.CopyDocuments", err)
+			}
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return report, fmt.Errorf("%v: %w", "mongodb.CopyDocuments", err)
+	}
+
+	if err := flush(); err != nil {
+		return report, fmt.Errorf("%v: %w", "mongodb.CopyDocuments", err)
+	}
+	return report, nil
+}
+
+// sameRepository reports whether a and b operate on the same collection.
+func sameRepository[T Document[T]](a, b RepositoryI[T]) bool {
+	ra, okA := a.(*Repository[T])
+	rb, okB := b.(*Repository[T])
+	if okA && okB {
+		return ra.db.Database().Name() == rb.db.Database().Name() && ra.db.Name() == rb.db.Name()
+	}
+
+	ta := reflect.TypeOf(a)
+	return ta != nil && ta == reflect.TypeOf(b) && ta.Comparable() && a == b
+}
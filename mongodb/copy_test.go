@@ -0,0 +1,74 @@
+package mongodb_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestCopyDocumentsSameCollection(t *testing.T) {
+	ctx := context.Background()
+	// Connecting is lazy, no server is needed to build the repositories.
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	src := mongodb.NewRepository[*User](client.Database("testdb").Collection("user_copy"))
+	dst := mongodb.NewRepository[*User](client.Database("testdb").Collection("user_copy"))
+
+	_, err = mongodb.CopyDocuments(ctx, src, dst, bson.M{}, nil, 0)
+	assert.ErrorIs(t, err, mongodb.ErrSameCollection)
+}
+
+func TestCopyDocuments(t *testing.T) {
+	ctx := context.Background()
+	src := mongodb.NewRepository[*User](testCollection(t, "user_copy_src"))
+	dst := mongodb.NewRepository[*User](testCollection(t, "user_copy_dst"))
+
+	users := make([]*User, 25)
+	for i := range users {
+		users[i] = &User{Name: fmt.Sprintf("User %d", i), Email: fmt.Sprintf("user%d@example.com", i)}
+	}
+	if _, err := src.InsertMany(ctx, users); err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	transform := func(u *User) (*User, bool) {
+		if u.Name == "User 0" {
+			return u, false
+		}
+		u.Email = strings.ToUpper(u.Email)
+		return u, true
+	}
+
+	report, err := mongodb.CopyDocuments(ctx, src, dst, bson.M{}, transform, 10)
+	if err != nil {
+		t.Fatalf("Error on copying users: %v", err)
+	}
+	assert.Equal(t, 24, report.Copied)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, 0, report.Failed)
+
+	copied, err := dst.FindOne(ctx, mongodb.MongoIDFilter(users[1].MongoID))
+	if err != nil {
+		t.Fatalf("Error on finding copied user: %v", err)
+	}
+	assert.Equal(t, "USER1@EXAMPLE.COM", copied.Email)
+
+	// Copying again replaces the documents instead of duplicating them.
+	_, err = mongodb.CopyDocuments(ctx, src, dst, bson.M{}, transform, 10)
+	assert.NoError(t, err)
+
+	count, err := dst.CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 24, count)
+}
@@ -0,0 +1,94 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	companyIDField = "companyID"
+	// countAcrossParallelism is the number of repositories CountByCompanyAcross counts at the same time.
+	countAcrossParallelism = 4
+)
+
+type (
+	groupCount struct {
+		Key   bson.RawValue `bson:"_id"`
+		Count int           `bson:"count"`
+	}
+)
+
+// CountByCompany returns the number of documents matching filter per companyID, with a single aggregation.
+//
+// Documents without a companyID, or with a companyID that is not an ObjectID, are counted for [primitive.NilObjectID].
+func CountByCompany(ctx context.Context, r Aggregater, filter bson.M) (map[primitive.ObjectID]int, error) {
+	counts, err := groupCounts(ctx, r, companyIDField, filter)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.CountByCompany", err)
+	}
+
+	res := make(map[primitive.ObjectID]int, len(counts))
+	for _, c := range counts {
+		id, _ := c.Key.ObjectIDOK()
+		res[id] += c.Count
+	}
+	return res, nil
+}
+
+// CountByCompanyAcross runs [CountByCompany] for every repository, at most 4 at the same time,
+// and returns the counts by the key of the repository. The first error cancels the other counts.
+func CountByCompanyAcross(ctx context.Context, repos map[string]Aggregater, filter bson.M) (map[string]map[primitive.ObjectID]int, error) {
+	var mu sync.Mutex
+	res := make(map[string]map[primitive.ObjectID]int, len(repos))
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(countAcrossParallelism)
+	count := func(name string, r Aggregater) func() error {
+		return func() error {
+			counts, err := CountByCompany(groupCtx, r, filter)
+			if err != nil {
+				return fmt.Errorf("%v: %w", name, err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			res[name] = counts
+			return nil
+		}
+	}
+	for name, r := range repos {
+		group.Go(count(name, r))
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.CountByCompanyAcross", err)
+	}
+	return res, nil
+}
+
+// groupCounts counts the documents matching filter per value of field. Missing values are grouped as null.
+func groupCounts(ctx context.Context, r Aggregater, field string, filter bson.M) ([]groupCount, error) {
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	cur, err := r.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var counts []groupCount
+	if err := cur.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
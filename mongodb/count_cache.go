@@ -0,0 +1,201 @@
+package mongodb
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/sync/singleflight"
+)
+
+const defaultCountCacheEntries = 1000
+
+// countCacheReads are the repository operations that do not invalidate a [CountCache], see [CountCache.InvalidationHook].
+var countCacheReads = map[string]bool{
+	"FindOne":          true,
+	"FindOneWhere":     true,
+	"GetByID":          true,
+	"FindMany":         true,
+	"FindManyWhere":    true,
+	"FindManyN":        true,
+	"FindManyRaw":      true,
+	"FindCursor":       true,
+	"FindIter":         true,
+	"FindManyParallel": true,
+	"CountDocuments":   true,
+	"CountWhere":       true,
+	"Distinct":         true,
+	"Aggregate":        true,
+	"Watch":            true,
+	"Stats":            true,
+	"Verify":           true,
+	"ListIndexes":      true,
+}
+
+type (
+	// CountCache caches the results of CountDocuments, see [NewCachedCounter].
+	CountCache struct {
+		inner      Counter
+		ttl        time.Duration
+		maxEntries int
+		group      singleflight.Group
+
+		mu         sync.Mutex
+		entries    map[string]*list.Element
+		lru        *list.List
+		generation uint64
+	}
+
+	countCacheEntry struct {
+		key     string
+		count   int
+		expires time.Time
+	}
+
+	// countInvalidationHook invalidates a CountCache after every write, see [CountCache.InvalidationHook].
+	countInvalidationHook struct {
+		cache *CountCache
+	}
+)
+
+// Compile-time check that CountCache can replace the Counter it wraps.
+var _ Counter = (*CountCache)(nil)
+
+// NewCachedCounter wraps inner, so that the results of CountDocuments are cached for ttl, e.g. for dashboards
+// that request the same counts every few seconds. Concurrent identical counts are run only once, like with
+// [NewSingleflightRepository], and all callers get the result of that single call.
+//
+// Filters are compared regardless of the order of the keys of their maps. Calls with driver options are neither cached
+// nor deduplicated, and errors are not cached. Like with [NewSingleflightRepository], shared counts are not canceled
+// with the context of the caller that started them, so a canceled caller neither fails the others nor the cached
+// count. At most maxEntries counts are cached, the least recently used ones are evicted first. A maxEntries <= 0
+// caches up to 1000 counts.
+//
+// Writes are not seen by the cache, so counts are up to ttl old. Call [CountCache.Invalidate] after writes,
+// or register [CountCache.InvalidationHook] on the repositories that write the collection.
+func NewCachedCounter(inner Counter, ttl time.Duration, maxEntries int) *CountCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultCountCacheEntries
+	}
+	return &CountCache{inner: inner, ttl: ttl, maxEntries: maxEntries, entries: map[string]*list.Element{}, lru: list.New()}
+}
+
+// CountDocuments returns the cached count for filter, or counts the documents with the inner Counter.
+func (c *CountCache) CountDocuments(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int, error) {
+	if len(opts) > 0 {
+		return c.inner.CountDocuments(ctx, filter, opts...)
+	}
+
+	key, err := singleflightKey("CountDocuments", filter)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", "mongodb.CountCache.CountDocuments", err)
+	}
+
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*countCacheEntry)
+		if time.Now().Before(entry.expires) {
+			c.lru.MoveToFront(element)
+			c.mu.Unlock()
+			return entry.count, nil
+		}
+		c.remove(element)
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	// Calls started before an invalidation are not shared with calls after it.
+	res, err := doShared(ctx, &c.group, strconv.FormatUint(generation, 10)+":"+key, func(ctx context.Context) (interface{}, error) {
+		count, err := c.inner.CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		c.store(key, count, generation)
+		return count, nil
+	})
+	count, _ := res.(int)
+	return count, err
+}
+
+// store caches count for key, unless the cache was invalidated since the count started.
+func (c *CountCache) store(key string, count int, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	c.entries[key] = c.lru.PushFront(&countCacheEntry{key: key, count: count, expires: time.Now().Add(c.ttl)})
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *CountCache) remove(element *list.Element) {
+	c.lru.Remove(element)
+	delete(c.entries, element.Value.(*countCacheEntry).key)
+}
+
+// Invalidate removes the cached count for filter. Counts for filter that are running are not cached.
+func (c *CountCache) Invalidate(filter bson.M) {
+	key, err := singleflightKey("CountDocuments", filter)
+	if err != nil {
+		// A filter that can not be marshaled is never cached.
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+}
+
+// InvalidateAll removes all cached counts. Counts that are running are not cached.
+func (c *CountCache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = map[string]*list.Element{}
+	c.lru.Init()
+}
+
+// Len returns the number of cached counts, including expired ones that were not evicted yet.
+func (c *CountCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// InvalidationHook returns an [OperationHook] that invalidates all cached counts after every write of the repository
+// it is registered on with [WithHook], including failed writes, which may have written some documents.
+// Register it on the repositories that write the collection whose documents the cache counts.
+//
+// Every write invalidates all counts, since it is not known which filters it affects. Aggregations are not treated
+// as writes, so pipelines with $out or $merge have to be followed by [CountCache.InvalidateAll].
+func (c *CountCache) InvalidationHook() OperationHook {
+	return countInvalidationHook{cache: c}
+}
+
+func (h countInvalidationHook) Before(ctx context.Context, _ *Operation) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h countInvalidationHook) After(_ context.Context, op *Operation, err error) error {
+	if !countCacheReads[op.Name] {
+		h.cache.InvalidateAll()
+	}
+	return err
+}
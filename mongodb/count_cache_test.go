@@ -0,0 +1,166 @@
+package mongodb_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// tallyCounter counts its calls, and answers every count after a delay with the number of keys of the filter.
+type tallyCounter struct {
+	calls int32
+	delay time.Duration
+}
+
+func (c *tallyCounter) CountDocuments(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int, error) {
+	atomic.AddInt32(&c.calls, 1)
+	select {
+	case <-time.After(c.delay):
+		return len(filter), nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func TestCountCacheLeaderCanceled(t *testing.T) {
+	inner := &tallyCounter{delay: 100 * time.Millisecond}
+	cache := mongodb.NewCachedCounter(inner, time.Minute, 10)
+	filter := bson.M{"status": "open"}
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error)
+	go func() {
+		_, err := cache.CountDocuments(leaderCtx, filter)
+		leader <- err
+	}()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&inner.calls) == 1 }, time.Second, time.Millisecond)
+
+	waiter := make(chan int)
+	go func() {
+		count, err := cache.CountDocuments(context.Background(), filter)
+		assert.NoError(t, err)
+		waiter <- count
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-leader, context.Canceled)
+	assert.Equal(t, 1, <-waiter)
+
+	count, err := cache.CountDocuments(context.Background(), filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&inner.calls), "the count of the canceled leader is cached")
+}
+
+func TestCountCacheDeduplicates(t *testing.T) {
+	ctx := context.Background()
+	inner := &tallyCounter{delay: 50 * time.Millisecond}
+	cache := mongodb.NewCachedCounter(inner, time.Minute, 10)
+
+	var wg sync.WaitGroup
+	counts := make([]int, 20)
+	for i := range counts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			counts[i], _ = cache.CountDocuments(ctx, bson.M{"status": "open", "owner": bson.M{"name": "alice", "team": "a"}})
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&inner.calls))
+	for _, count := range counts {
+		assert.Equal(t, 2, count)
+	}
+
+	// Filters with the same keys in another order share the cached count.
+	count, err := cache.CountDocuments(ctx, bson.M{"owner": bson.M{"team": "a", "name": "alice"}, "status": "open"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&inner.calls))
+
+	_, err = cache.CountDocuments(ctx, bson.M{"status": "open"}, options.Count().SetLimit(1))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&inner.calls), "counts with options are not cached")
+}
+
+func TestCountCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	inner := &tallyCounter{}
+	cache := mongodb.NewCachedCounter(inner, 20*time.Millisecond, 2)
+
+	cache.CountDocuments(ctx, bson.M{"a": 1})
+	cache.CountDocuments(ctx, bson.M{"a": 1})
+	assert.Equal(t, int32(1), atomic.LoadInt32(&inner.calls))
+
+	time.Sleep(30 * time.Millisecond)
+	cache.CountDocuments(ctx, bson.M{"a": 1})
+	assert.Equal(t, int32(2), atomic.LoadInt32(&inner.calls), "expired counts are counted again")
+
+	// With two entries, the least recently used filter is evicted.
+	cache.CountDocuments(ctx, bson.M{"b": 1})
+	cache.CountDocuments(ctx, bson.M{"a": 1})
+	cache.CountDocuments(ctx, bson.M{"c": 1})
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, int32(4), atomic.LoadInt32(&inner.calls))
+	cache.CountDocuments(ctx, bson.M{"a": 1})
+	assert.Equal(t, int32(4), atomic.LoadInt32(&inner.calls))
+	cache.CountDocuments(ctx, bson.M{"b": 1})
+	assert.Equal(t, int32(5), atomic.LoadInt32(&inner.calls))
+
+	cache.Invalidate(bson.M{"b": 1})
+	cache.CountDocuments(ctx, bson.M{"b": 1})
+	assert.Equal(t, int32(6), atomic.LoadInt32(&inner.calls))
+}
+
+func TestCountCacheInvalidationHook(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	inner := &tallyCounter{}
+	cache := mongodb.NewCachedCounter(inner, time.Minute, 10)
+	writer := mongodb.NewRepository[*User](client.Database("testdb").Collection("user_count_cache"),
+		mongodb.WithHook(cache.InvalidationHook()), mongodb.WithHook(rejectingHook{}))
+
+	cache.CountDocuments(ctx, bson.M{"a": 1})
+	writer.CountDocuments(ctx, bson.M{})
+	cache.CountDocuments(ctx, bson.M{"a": 1})
+	assert.Equal(t, int32(1), atomic.LoadInt32(&inner.calls), "reads do not invalidate")
+
+	_, err = writer.InsertOne(ctx, &User{Name: "Alice"})
+	assert.ErrorIs(t, err, errRejected)
+	cache.CountDocuments(ctx, bson.M{"a": 1})
+	assert.Equal(t, int32(2), atomic.LoadInt32(&inner.calls), "failed writes invalidate, too")
+}
+
+func TestCountCacheInvalidationAfterInsert(t *testing.T) {
+	ctx := context.Background()
+	col := testCollection(t, "user_count_cache")
+	cache := mongodb.NewCachedCounter(mongodb.NewRepository[*User](col), time.Minute, 10)
+	writer := mongodb.NewRepository[*User](col, mongodb.WithHook(cache.InvalidationHook()))
+
+	count, err := cache.CountDocuments(ctx, bson.M{"name": "Alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	if _, err := writer.InsertOne(ctx, &User{Name: "Alice"}); err != nil {
+		t.Fatalf("Error on inserting user: %v", err)
+	}
+
+	count, err = cache.CountDocuments(ctx, bson.M{"name": "Alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,54 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCountByCompany(t *testing.T) {
+	ctx := context.Background()
+	users := testCollection(t, "user_count_by_company")
+	orders := testCollection(t, "order_count_by_company")
+
+	companyA, companyB := primitive.NewObjectID(), primitive.NewObjectID()
+	_, err := users.InsertMany(ctx, []interface{}{
+		bson.M{"companyID": companyA, "name": "Alice"},
+		bson.M{"companyID": companyA, "name": "Bob"},
+		bson.M{"companyID": companyB, "name": "Carol"},
+		bson.M{"name": "no company"},
+	})
+	if err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+	_, err = orders.InsertMany(ctx, []interface{}{
+		bson.M{"companyID": companyB},
+		bson.M{"companyID": companyB},
+	})
+	if err != nil {
+		t.Fatalf("Error on inserting orders: %v", err)
+	}
+
+	userRepo := mongodb.NewRepository[*User](users)
+	counts, err := mongodb.CountByCompany(ctx, userRepo, nil)
+	if err != nil {
+		t.Fatalf("Error on counting: %v", err)
+	}
+	assert.Equal(t, map[primitive.ObjectID]int{companyA: 2, companyB: 1, primitive.NilObjectID: 1}, counts)
+
+	across, err := mongodb.CountByCompanyAcross(ctx, map[string]mongodb.Aggregater{
+		"users":  userRepo,
+		"orders": mongodb.NewRepository[*User](orders),
+	}, bson.M{"companyID": companyB})
+	if err != nil {
+		t.Fatalf("Error on counting across repositories: %v", err)
+	}
+	assert.Equal(t, map[string]map[primitive.ObjectID]int{
+		"users":  {companyB: 1},
+		"orders": {companyB: 2},
+	}, across)
+}
@@ -0,0 +1,135 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrKeepAliveOrder is returned by [Repository.FindIter] with [WithCursorKeepAlive] for options that sort by other fields than _id.
+var ErrKeepAliveOrder = errors.New("mongodb: cursor keep-alive requires iteration in _id order")
+
+type cursorKeepAliveOption bool
+
+func (value cursorKeepAliveOption) apply(o *repositoryOption) {
+	o.cursorKeepAlive = bool(value)
+}
+
+// WithCursorKeepAlive makes [Repository.FindIter] survive server-side cursor timeouts, e.g. when processing
+// the documents of a batch takes longer than the cursorTimeoutMillis of the server, 10 minutes by default.
+//
+// When the cursor is no longer found on the server, the query is run again for the documents after the last yielded _id,
+// so that the iteration continues without yielding any document twice. Therefore the iteration is sorted by _id,
+// ascending unless the options sort by _id descending. Options that sort by any other field are rejected with [ErrKeepAliveOrder].
+// A skip of the options is only applied to the first query, and the limit counts the documents of all queries.
+//
+// NoCursorTimeout is not set instead, since servers remove idle cursors with their session after 30 minutes regardless,
+// and cursors that are never closed keep their resources forever. [Backfill] runs a query per batch and never needs it.
+func WithCursorKeepAlive() RepositoryOption {
+	return cursorKeepAliveOption(true)
+}
+
+// findIterKeepAlive reads the documents of filter like findIter, and resumes after the last read _id when the cursor was killed.
+func (r *Repository[T]) findIterKeepAlive(ctx context.Context, filter bson.M, opts []*options.FindOptions, yield func(T) bool) error {
+	findOpts := options.MergeFindOptions(opts...)
+	direction, err := keepAliveDirection(findOpts.Sort)
+	if err != nil {
+		return err
+	}
+	findOpts.SetSort(bson.D{{Key: "_id", Value: direction}})
+
+	after := "$gt"
+	if direction < 0 {
+		after = "$lt"
+	}
+
+	var lastID interface{}
+	for {
+		query := filter
+		if lastID != nil {
+			query = bson.M{"$and": bson.A{filter, bson.M{"_id": bson.M{after: lastID}}}}
+		}
+
+		read, done, err := r.readKeepAlive(ctx, query, findOpts, &lastID, yield)
+		if done || err == nil || !hasErrorCode(err, codeCursorNotFound) {
+			return err
+		}
+		if read == 0 {
+			// The new cursor was killed before it returned anything, restarting it again would not progress.
+			return err
+		}
+
+		// The documents up to lastID are skipped by the filter of the next query.
+		findOpts.Skip = nil
+		if findOpts.Limit != nil && *findOpts.Limit > 0 {
+			remaining := *findOpts.Limit - read
+			if remaining <= 0 {
+				return nil
+			}
+			findOpts.SetLimit(remaining)
+		}
+	}
+}
+
+// readKeepAlive runs a single query and yields its documents, and returns the number of read documents
+// and whether the iteration ended. lastID is set to the _id of every read document.
+func (r *Repository[T]) readKeepAlive(ctx context.Context, query bson.M, findOpts *options.FindOptions, lastID *interface{}, yield func(T) bool) (int64, bool, error) {
+	cur, err := r.CollectionFor(ctx).Find(ctx, query, findOpts)
+	if err != nil {
+		return 0, true, err
+	}
+	defer cur.Close(context.Background())
+
+	var read int64
+	for cur.Next(ctx) {
+		idValue, err := cur.Current.LookupErr("_id")
+		if err != nil {
+			return read, true, fmt.Errorf("%v: results without _id can not be resumed: %w", "mongodb.Repository.FindIter", err)
+		}
+		var id interface{}
+		if err := idValue.Unmarshal(&id); err != nil {
+			return read, true, err
+		}
+
+		doc, keep, err := r.decodeResult(ctx, cur)
+		if err != nil {
+			return read, true, err
+		}
+		*lastID = id
+		read++
+		if keep && !yield(doc) {
+			return read, true, nil
+		}
+	}
+
+	return read, false, cur.Err()
+}
+
+// keepAliveDirection returns the direction of an _id sort, 1 if sort is nil.
+func keepAliveDirection(sort interface{}) (int32, error) {
+	if sort == nil {
+		return 1, nil
+	}
+
+	data, err := bson.Marshal(sort)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrKeepAliveOrder, err)
+	}
+	elements, err := bson.Raw(data).Elements()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrKeepAliveOrder, err)
+	}
+	if len(elements) == 0 {
+		return 1, nil
+	}
+	if len(elements) == 1 && elements[0].Key() == "_id" {
+		if direction, ok := elements[0].Value().AsInt64OK(); ok && (direction == 1 || direction == -1) {
+			return int32(direction), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: sorted by %v", ErrKeepAliveOrder, bson.Raw(data))
+}
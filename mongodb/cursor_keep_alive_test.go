@@ -0,0 +1,110 @@
+//go:build go1.23
+
+package mongodb_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestCursorKeepAliveOrder(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	repo := mongodb.NewRepository[*User](client.Database("testdb").Collection("user_keep_alive"), mongodb.WithCursorKeepAlive()).(*mongodb.Repository[*User])
+	for _, sort := range []interface{}{bson.M{"name": 1}, bson.D{{Key: "_id", Value: 1}, {Key: "name", Value: 1}}, bson.M{"_id": 2}} {
+		for _, err := range repo.FindIter(ctx, bson.M{}, options.Find().SetSort(sort)) {
+			assert.ErrorIs(t, err, mongodb.ErrKeepAliveOrder, sort)
+		}
+	}
+}
+
+func TestCursorKeepAlive(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var cursorIDs []int64
+	monitor := &event.CommandMonitor{
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			if e.CommandName != "find" {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			cursorIDs = append(cursorIDs, e.Reply.Lookup("cursor", "id").Int64())
+		},
+	}
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017").SetMonitor(monitor))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	col := client.Database("testdb").Collection("user_keep_alive")
+	t.Cleanup(func() {
+		col.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+	col.Drop(ctx)
+
+	users := make([]*User, 10)
+	for i := range users {
+		users[i] = &User{Name: fmt.Sprintf("user%d", i)}
+	}
+	repo := mongodb.NewRepository[*User](col, mongodb.WithCursorKeepAlive()).(*mongodb.Repository[*User])
+	if _, err := repo.InsertMany(ctx, users); err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	// The cursor is killed on the server while the third user is processed.
+	var names []string
+	for user, err := range repo.FindIter(ctx, bson.M{}, options.Find().SetBatchSize(2).SetSkip(1).SetLimit(8)) {
+		if !assert.NoError(t, err) {
+			break
+		}
+		names = append(names, user.Name)
+		if len(names) == 3 {
+			mu.Lock()
+			id := cursorIDs[len(cursorIDs)-1]
+			mu.Unlock()
+			err := col.Database().RunCommand(ctx, bson.D{{Key: "killCursors", Value: col.Name()}, {Key: "cursors", Value: bson.A{id}}}).Err()
+			assert.NoError(t, err)
+		}
+	}
+
+	assert.Equal(t, []string{"user1", "user2", "user3", "user4", "user5", "user6", "user7", "user8"}, names)
+	assert.Len(t, cursorIDs, 2, "the query is restarted once")
+
+	// Without the option, the iteration fails.
+	repo = mongodb.NewRepository[*User](col).(*mongodb.Repository[*User])
+	var err2 error
+	seen := 0
+	for _, err := range repo.FindIter(ctx, bson.M{}, options.Find().SetBatchSize(2)) {
+		if err != nil {
+			err2 = err
+			break
+		}
+		seen++
+		if seen == 3 {
+			mu.Lock()
+			id := cursorIDs[len(cursorIDs)-1]
+			mu.Unlock()
+			col.Database().RunCommand(ctx, bson.D{{Key: "killCursors", Value: col.Name()}, {Key: "cursors", Value: bson.A{id}}})
+		}
+	}
+	var serverErr mongo.ServerError
+	if assert.ErrorAs(t, err2, &serverErr) {
+		assert.True(t, serverErr.HasErrorCode(43), "CursorNotFound")
+	}
+}
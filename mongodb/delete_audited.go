@@ -0,0 +1,73 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	DeleteManyAudited interface {
+		// Deletes the documents that match the given filter in batches, and passes the _ids of every batch to onBatch before it is deleted.
+		DeleteManyAudited(ctx context.Context, filter bson.M, batchSize int, onBatch func(deletedIDs []primitive.ObjectID) error) (int, error)
+	}
+)
+
+// Deletes the documents that match the given filter in batches of batchSize, and returns the number of deleted documents.
+// A batchSize <= 0 uses batches of 1000 documents.
+//
+// For every batch, the _ids of up to batchSize matching documents are read, passed to onBatch, e.g. to persist an audit record,
+// and then exactly the documents with these _ids are deleted. If onBatch returns an error, the batch is not deleted,
+// and the number of documents deleted so far is returned together with the error. Batches are read until no document matches,
+// so documents inserted during the run are deleted by a later batch.
+//
+// Every deleted _id is passed to onBatch exactly once. A document that is deleted concurrently by someone else
+// after its batch was read is still passed to onBatch, so the deleted count can be less than the number of passed _ids.
+func (r *Repository[T]) DeleteManyAudited(ctx context.Context, filter bson.M, batchSize int, onBatch func(deletedIDs []primitive.ObjectID) error) (int, error) {
+	if batchSize <= 0 {
+		batchSize = defaultChunkSize
+	}
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"_id": 1}).
+		SetSort(bson.M{"_id": 1}).
+		SetLimit(int64(batchSize))
+
+	deleted := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return deleted, fmt.Errorf("%v: %w", "mongodb.Repository.DeleteManyAudited", err)
+		}
+
+		batch, err := FindManyAs[struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}](ctx, r, filter, opts)
+		if err != nil {
+			return deleted, fmt.Errorf("%v: %w", "mongodb.Repository.DeleteManyAudited", err)
+		}
+		if len(batch) == 0 {
+			return deleted, nil
+		}
+
+		ids := make([]primitive.ObjectID, len(batch))
+		for i, doc := range batch {
+			ids[i] = doc.ID
+		}
+
+		if err := onBatch(ids); err != nil {
+			return deleted, fmt.Errorf("%v: batch not deleted: %w", "mongodb.Repository.DeleteManyAudited", err)
+		}
+
+		n, err := r.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+		deleted += n
+		if err != nil {
+			return deleted, fmt.Errorf("%v: %w", "mongodb.Repository.DeleteManyAudited", err)
+		}
+	}
+}
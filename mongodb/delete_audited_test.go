@@ -0,0 +1,85 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDeleteManyAudited(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_delete_audited")).(*mongodb.Repository[*User])
+
+	docs := make([]*User, 25)
+	for i := range docs {
+		name := "remove"
+		if i%5 == 0 {
+			name = "keep"
+		}
+		docs[i] = &User{Name: name, Email: fmt.Sprintf("user%d@example.com", i)}
+	}
+	docs, err := repo.InsertMany(ctx, docs)
+	if err != nil {
+		t.Fatalf("Error inserting users: %v", err)
+	}
+
+	audited := map[primitive.ObjectID]int{}
+	var batchSizes []int
+	deleted, err := repo.DeleteManyAudited(ctx, bson.M{"name": "remove"}, 7, func(ids []primitive.ObjectID) error {
+		batchSizes = append(batchSizes, len(ids))
+		for _, id := range ids {
+			audited[id]++
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 20, deleted)
+	assert.Equal(t, []int{7, 7, 6}, batchSizes)
+
+	for _, doc := range docs {
+		if doc.Name == "remove" {
+			assert.Equal(t, 1, audited[doc.GetMongoID()], doc.Email)
+		} else {
+			assert.Zero(t, audited[doc.GetMongoID()], doc.Email)
+		}
+	}
+
+	count, err := repo.CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, count)
+}
+
+func TestDeleteManyAuditedCallbackError(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_delete_audited_error")).(*mongodb.Repository[*User])
+
+	docs := make([]*User, 10)
+	for i := range docs {
+		docs[i] = &User{Name: "remove"}
+	}
+	if _, err := repo.InsertMany(ctx, docs); err != nil {
+		t.Fatalf("Error inserting users: %v", err)
+	}
+
+	errAudit := errors.New("audit log unavailable")
+	batches := 0
+	deleted, err := repo.DeleteManyAudited(ctx, bson.M{}, 4, func(ids []primitive.ObjectID) error {
+		batches++
+		if batches == 2 {
+			return errAudit
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errAudit)
+	assert.Equal(t, 4, deleted)
+
+	count, err := repo.CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 6, count)
+}
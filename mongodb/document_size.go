@@ -0,0 +1,312 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// MaxBSONDocumentSize is the maximum size of a document MongoDB stores, 16MiB.
+	MaxBSONDocumentSize = 16 * 1024 * 1024
+	// largestFieldsReported is the number of fields a [DocumentTooLargeError] lists.
+	largestFieldsReported = 3
+)
+
+// ErrDocumentTooLarge is returned for documents that exceed the size set by [WithMaxDocumentSize].
+var ErrDocumentTooLarge = errors.New("mongodb: document too large")
+
+var (
+	objectIDType = reflect.TypeOf(primitive.ObjectID{})
+	eType        = reflect.TypeOf(primitive.E{})
+	rawType      = reflect.TypeOf(bson.Raw{})
+)
+
+type (
+	// EstimateOption configures [EstimateBSONSize].
+	EstimateOption interface {
+		apply(*estimateOption)
+	}
+
+	// FieldSize is the size of a top-level field of a document in bytes, including its name.
+	FieldSize struct {
+		Name string
+		Size int
+	}
+
+	// DocumentTooLargeError is returned for documents that exceed the size set by [WithMaxDocumentSize].
+	//
+	// It matches [ErrDocumentTooLarge] with errors.Is.
+	DocumentTooLargeError struct {
+		// Index is the position of the document in InsertMany, 0 for InsertOne and ReplaceOne.
+		Index int
+		// Size is the BSON size of the document in bytes.
+		Size int
+		// Limit is the configured maximum size.
+		Limit int
+		// LargestFields are the largest top-level fields of the document, the largest first.
+		LargestFields []FieldSize
+	}
+
+	// sizeCheckKey marks a context whose operations skip the check of [WithMaxDocumentSize].
+	sizeCheckKey struct{}
+)
+
+type (
+	estimateOption struct {
+		reflective bool
+	}
+)
+
+func (e *DocumentTooLargeError) Error() string {
+	fields := make([]string, len(e.LargestFields))
+	for i, field := range e.LargestFields {
+		fields[i] = fmt.Sprintf("%v (%d bytes)", field.Name, field.Size)
+	}
+
+	return fmt.Sprintf("mongodb: document %d has %d bytes, more than the maximum of %d bytes, largest fields: %v",
+		e.Index, e.Size, e.Limit, strings.Join(fields, ", "))
+}
+
+func (e *DocumentTooLargeError) Is(target error) bool {
+	return target == ErrDocumentTooLarge
+}
+
+type reflectiveEstimateOption bool
+
+func (value reflectiveEstimateOption) apply(o *estimateOption) {
+	o.reflective = bool(value)
+}
+
+// WithReflectiveEstimate makes [EstimateBSONSize] walk the document with reflection instead of marshaling it.
+// This allocates no buffer for the document, but ignores custom marshalers and encodes every integer as int64,
+// so the result is only close to the actual size.
+func WithReflectiveEstimate() EstimateOption {
+	return reflectiveEstimateOption(true)
+}
+
+// EstimateBSONSize returns the size of doc in bytes once it is marshaled to BSON, e.g. to check it against
+// [MaxBSONDocumentSize] before building a large payload.
+//
+// By default, doc is marshaled and the exact size is returned. See [WithReflectiveEstimate] for a cheaper estimate.
+func EstimateBSONSize(doc any, opts ...EstimateOption) (int, error) {
+	ops := &estimateOption{}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+
+	if !ops.reflective {
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			return 0, fmt.Errorf("%v: %w", "mongodb.EstimateBSONSize", err)
+		}
+		return len(data), nil
+	}
+
+	v := reflect.ValueOf(doc)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return 0, fmt.Errorf("%v: nil document", "mongodb.EstimateBSONSize")
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map:
+	case reflect.Slice:
+		if v.Type().Elem() != eType && v.Type() != rawType {
+			return 0, fmt.Errorf("%v: %v is not a document", "mongodb.EstimateBSONSize", v.Type())
+		}
+	default:
+		return 0, fmt.Errorf("%v: %v is not a document", "mongodb.EstimateBSONSize", v.Type())
+	}
+
+	size, err := estimateValue(v)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", "mongodb.EstimateBSONSize", err)
+	}
+	return size, nil
+}
+
+// estimateValue returns the estimated encoded size of v, without the type byte and name of its element.
+func estimateValue(v reflect.Value) (int, error) {
+	if !v.IsValid() {
+		return 0, nil
+	}
+
+	switch v.Type() {
+	case timeType, dateTimeType:
+		return 8, nil
+	case objectIDType:
+		return 12, nil
+	case rawType:
+		return v.Len(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return 0, nil
+		}
+		return estimateValue(v.Elem())
+	case reflect.String:
+		return 4 + v.Len() + 1, nil
+	case reflect.Bool:
+		return 1, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return 4, nil
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return 8, nil
+	case reflect.Struct:
+		size := 4 + 1
+		err := estimateStructFields(v, &size)
+		return size, err
+	case reflect.Map:
+		size := 4 + 1
+		iter := v.MapRange()
+		for iter.Next() {
+			value, err := estimateValue(iter.Value())
+			if err != nil {
+				return 0, err
+			}
+			size += 1 + len(fmt.Sprint(iter.Key().Interface())) + 1 + value
+		}
+		return size, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			// Binary: length, subtype and data.
+			return 4 + 1 + v.Len(), nil
+		}
+
+		size := 4 + 1
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			if elem.Type() == eType {
+				// bson.D: the elements are the fields of a document.
+				e := elem.Interface().(primitive.E)
+				value, err := estimateValue(reflect.ValueOf(e.Value))
+				if err != nil {
+					return 0, err
+				}
+				size += 1 + len(e.Key) + 1 + value
+				continue
+			}
+
+			value, err := estimateValue(elem)
+			if err != nil {
+				return 0, err
+			}
+			size += 1 + len(strconv.Itoa(i)) + 1 + value
+		}
+		return size, nil
+	}
+
+	return 0, fmt.Errorf("can not estimate values of type %v", v.Type())
+}
+
+// estimateStructFields adds the estimated sizes of the fields of the struct v to size, flattening inline structs.
+func estimateStructFields(v reflect.Value, size *int) error {
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tags, err := bsoncodec.DefaultStructTagParser.ParseStructTags(sf)
+		if err != nil {
+			return err
+		}
+		field := v.Field(i)
+		if tags.Skip || (tags.OmitEmpty && field.IsZero()) {
+			continue
+		}
+
+		if tags.Inline {
+			for field.Kind() == reflect.Pointer {
+				if field.IsNil() {
+					break
+				}
+				field = field.Elem()
+			}
+			if field.Kind() == reflect.Struct {
+				if err := estimateStructFields(field, size); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		value, err := estimateValue(field)
+		if err != nil {
+			return err
+		}
+		*size += 1 + len(tags.Name) + 1 + value
+	}
+	return nil
+}
+
+type maxDocumentSizeOption int
+
+func (value maxDocumentSizeOption) apply(o *repositoryOption) {
+	o.maxDocumentSize = int(value)
+}
+
+// WithMaxDocumentSize makes InsertOne, InsertMany, InsertManyResult and ReplaceOne check the BSON size of every document
+// before it is sent, and return a [*DocumentTooLargeError] for documents larger than n bytes, which names the largest fields.
+// This points at the culprit much better than the error of the driver, e.g. with n = [MaxBSONDocumentSize].
+//
+// The check marshals every document once more. Use [WithoutSizeCheck] to skip it for performance-sensitive calls.
+func WithMaxDocumentSize(n int) RepositoryOption {
+	return maxDocumentSizeOption(n)
+}
+
+// WithoutSizeCheck returns a copy of ctx whose operations skip the check of [WithMaxDocumentSize].
+func WithoutSizeCheck(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sizeCheckKey{}, true)
+}
+
+// checkDocumentSize returns a [*DocumentTooLargeError] if doc exceeds the size set by WithMaxDocumentSize.
+func (r *Repository[T]) checkDocumentSize(ctx context.Context, index int, doc interface{}) error {
+	if r.maxDocumentSize <= 0 {
+		return nil
+	}
+	if skip, _ := ctx.Value(sizeCheckKey{}).(bool); skip {
+		return nil
+	}
+
+	data, err := bson.MarshalWithRegistry(r.Registry(), doc)
+	if err != nil {
+		return err
+	}
+	if len(data) <= r.maxDocumentSize {
+		return nil
+	}
+
+	return &DocumentTooLargeError{Index: index, Size: len(data), Limit: r.maxDocumentSize, LargestFields: largestFields(data)}
+}
+
+// largestFields returns the largest top-level fields of the encoded document data.
+func largestFields(data bson.Raw) []FieldSize {
+	elements, err := data.Elements()
+	if err != nil {
+		return nil
+	}
+
+	fields := make([]FieldSize, len(elements))
+	for i, element := range elements {
+		fields[i] = FieldSize{Name: element.Key(), Size: len(element)}
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Size > fields[j].Size })
+
+	if len(fields) > largestFieldsReported {
+		fields = fields[:largestFieldsReported]
+	}
+	return fields
+}
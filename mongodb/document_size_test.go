@@ -0,0 +1,103 @@
+package mongodb_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestEstimateBSONSize(t *testing.T) {
+	user := &User{Name: "Alice", Email: "alice@example.com"}
+	user.InitDocument()
+
+	data, err := bson.Marshal(user)
+	if err != nil {
+		t.Fatalf("Error on marshaling user: %v", err)
+	}
+
+	size, err := mongodb.EstimateBSONSize(user)
+	assert.NoError(t, err)
+	assert.Equal(t, len(data), size)
+
+	size, err = mongodb.EstimateBSONSize(user, mongodb.WithReflectiveEstimate())
+	assert.NoError(t, err)
+	assert.Equal(t, len(data), size)
+
+	doc := bson.D{{Key: "name", Value: "Bob"}, {Key: "tags", Value: bson.A{"a", "b"}}, {Key: "nested", Value: bson.M{"x": true}}}
+	data, err = bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Error on marshaling document: %v", err)
+	}
+
+	size, err = mongodb.EstimateBSONSize(doc, mongodb.WithReflectiveEstimate())
+	assert.NoError(t, err)
+	assert.Equal(t, len(data), size)
+
+	_, err = mongodb.EstimateBSONSize("no document", mongodb.WithReflectiveEstimate())
+	assert.Error(t, err)
+}
+
+func TestMaxDocumentSizeRejectsWithoutServer(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	repo := mongodb.NewRepository[*User](client.Database("testdb").Collection("user_document_size"), mongodb.WithMaxDocumentSize(200))
+	large := &User{Name: "Alice", Email: strings.Repeat("x", 300)}
+
+	_, err = repo.InsertOne(ctx, large)
+	assert.ErrorIs(t, err, mongodb.ErrDocumentTooLarge)
+
+	var tooLarge *mongodb.DocumentTooLargeError
+	if assert.ErrorAs(t, err, &tooLarge) {
+		assert.Equal(t, 0, tooLarge.Index)
+		assert.Equal(t, 200, tooLarge.Limit)
+		assert.Greater(t, tooLarge.Size, 300)
+		assert.Len(t, tooLarge.LargestFields, 3)
+		assert.Equal(t, "email", tooLarge.LargestFields[0].Name)
+	}
+
+	_, err = repo.InsertMany(ctx, []*User{{Name: "Bob"}, large})
+	if assert.ErrorAs(t, err, &tooLarge) {
+		assert.Equal(t, 1, tooLarge.Index)
+	}
+
+	_, err = repo.ReplaceOne(ctx, bson.M{"name": "Alice"}, large)
+	assert.ErrorIs(t, err, mongodb.ErrDocumentTooLarge)
+}
+
+func TestMaxDocumentSize(t *testing.T) {
+	ctx := context.Background()
+	collection := testCollection(t, "user_document_size")
+
+	user := &User{Name: "Alice", Email: strings.Repeat("x", 100)}
+	user.InitDocument()
+	size, err := mongodb.EstimateBSONSize(user)
+	if err != nil {
+		t.Fatalf("Error on estimating size: %v", err)
+	}
+
+	repo := mongodb.NewRepository[*User](collection, mongodb.WithMaxDocumentSize(size))
+
+	_, err = repo.InsertOne(ctx, &User{Name: "Alice", Email: strings.Repeat("x", 100)})
+	assert.NoError(t, err, "a document of exactly the limit is inserted")
+
+	_, err = repo.InsertOne(ctx, &User{Name: "Alice", Email: strings.Repeat("x", 101)})
+	assert.ErrorIs(t, err, mongodb.ErrDocumentTooLarge)
+
+	_, err = repo.InsertOne(mongodb.WithoutSizeCheck(ctx), &User{Name: "Alice", Email: strings.Repeat("x", 101)})
+	assert.NoError(t, err)
+
+	count, err := repo.CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
@@ -0,0 +1,381 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// dryRunSampleSize is the maximum number of _ids a [DryRunEvent] contains.
+const dryRunSampleSize = 10
+
+type (
+	// DryRunEvent describes a write that a dry-run repository did not execute, see [NewDryRunRepository].
+	DryRunEvent struct {
+		// Operation is the name of the repository method, e.g. "UpdateMany". Write models of BulkWrite and UpsertManyByKey
+		// are reported as separate events, e.g. "BulkWrite.DeleteOne".
+		Operation string
+		// Filter is the filter of the write as passed, or nil for inserts and index operations.
+		Filter interface{}
+		// Affected is the number of documents the write would have inserted, updated, replaced or deleted.
+		Affected int
+		// SampleIDs are the _ids of up to 10 of the affected documents. Inserted documents only have one if it was set before the insert.
+		SampleIDs []interface{}
+	}
+
+	// dryRunRepository reports the writes of the embedded repository instead of executing them, see [NewDryRunRepository].
+	dryRunRepository[T Document[T]] struct {
+		Forwarder[T]
+		log func(DryRunEvent)
+	}
+)
+
+// NewDryRunRepository wraps inner, so that writes are reported to log instead of being executed,
+// e.g. to preview what a maintenance script would touch before running it in production.
+//
+// Reads are passed through to inner. Every insert, update, replace and delete, BulkWrite, UpsertManyByKey, the create part
+// of FindOneOrCreate, Drop and the index changes are not executed. Instead, the documents they would affect are counted and
+// sampled with reads on inner, and their results are synthesized from these counts, e.g. the MatchedCount and ModifiedCount
+// of an UpdateResult. ModifiedCount equals MatchedCount, since updatedAt is always set. Documents passed to inserts are not
+// initialized with InitDocument, and DeleteManyAudited does not call onBatch. Aggregation pipelines with a $out or $merge
+// stage write as well, and are rejected with an error wrapping [ErrDryRunWrite], since their writes can not be counted.
+//
+// Results of writes that depend on each other, like the write models of a BulkWrite, are counted independently,
+// as if every write ran on its own. log is called synchronously, and may be nil to only get the synthesized results.
+func NewDryRunRepository[T Document[T]](inner RepositoryI[T], log func(DryRunEvent)) RepositoryI[T] {
+	if log == nil {
+		log = func(DryRunEvent) {}
+	}
+	return &dryRunRepository[T]{Forwarder: Forwarder[T]{RepositoryI: inner}, log: log}
+}
+
+func (r *dryRunRepository[T]) WithSession(sess mongo.Session) RepositoryI[T] {
+	return &dryRunRepository[T]{Forwarder: Forwarder[T]{RepositoryI: WithSession(r.RepositoryI, sess)}, log: r.log}
+}
+
+func (r *dryRunRepository[T]) Registry() *bsoncodec.Registry {
+	return registryOf(r.RepositoryI)
+}
+
+func (r *dryRunRepository[T]) Aggregate(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	for _, stage := range pipeline {
+		for _, e := range stage {
+			if e.Key == "$out" || e.Key == "$merge" {
+				return nil, fmt.Errorf("%v: %v stage: %w", "mongodb.NewDryRunRepository.Aggregate", e.Key, ErrDryRunWrite)
+			}
+		}
+	}
+	return r.RepositoryI.Aggregate(ctx, pipeline, opts...)
+}
+
+func (r *dryRunRepository[T]) InsertOne(ctx context.Context, doc T, opts ...*options.InsertOneOptions) (T, error) {
+	r.log(DryRunEvent{Operation: "InsertOne", Affected: 1, SampleIDs: insertedIDs(registryOf(r.RepositoryI), []T{doc}, dryRunSampleSize)})
+	return doc, nil
+}
+
+func (r *dryRunRepository[T]) InsertMany(ctx context.Context, docs []T, opts ...*options.InsertManyOptions) ([]T, error) {
+	r.log(DryRunEvent{Operation: "InsertMany", Affected: len(docs), SampleIDs: insertedIDs(registryOf(r.RepositoryI), docs, dryRunSampleSize)})
+	return docs, nil
+}
+
+func (r *dryRunRepository[T]) InsertManyResult(ctx context.Context, docs []T, opts ...*options.InsertManyOptions) ([]T, *mongo.InsertManyResult, error) {
+	r.log(DryRunEvent{Operation: "InsertManyResult", Affected: len(docs), SampleIDs: insertedIDs(registryOf(r.RepositoryI), docs, dryRunSampleSize)})
+	ids := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		ids[i] = presetID(registryOf(r.RepositoryI), doc)
+	}
+	return docs, &mongo.InsertManyResult{InsertedIDs: ids}, nil
+}
+
+func (r *dryRunRepository[T]) UpdateOne(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return r.update(ctx, "UpdateOne", filter, false, opts)
+}
+
+func (r *dryRunRepository[T]) UpdateMany(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) error {
+	_, err := r.update(ctx, "UpdateMany", filter, true, opts)
+	return err
+}
+
+func (r *dryRunRepository[T]) UpdateManyResult(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return r.update(ctx, "UpdateManyResult", filter, true, opts)
+}
+
+func (r *dryRunRepository[T]) UpdateOneWhere(ctx context.Context, filter Filter, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return r.update(ctx, "UpdateOneWhere", filter, false, opts)
+}
+
+func (r *dryRunRepository[T]) UpdateManyWhere(ctx context.Context, filter Filter, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return r.update(ctx, "UpdateManyWhere", filter, true, opts)
+}
+
+func (r *dryRunRepository[T]) update(ctx context.Context, name string, filter interface{}, many bool, opts []*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	n, err := r.report(ctx, name, filter, many)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &mongo.UpdateResult{MatchedCount: int64(n), ModifiedCount: int64(n)}
+	if upsert := options.MergeUpdateOptions(opts...).Upsert; n == 0 && upsert != nil && *upsert {
+		res.UpsertedCount = 1
+	}
+	return res, nil
+}
+
+func (r *dryRunRepository[T]) ReplaceOne(ctx context.Context, filter bson.M, doc T, opts ...*options.ReplaceOptions) (T, error) {
+	n, err := r.report(ctx, "ReplaceOne", filter, false)
+	if err != nil {
+		return doc, err
+	}
+
+	if upsert := options.MergeReplaceOptions(opts...).Upsert; n == 0 && (upsert == nil || !*upsert) {
+		return doc, fmt.Errorf("%v: %w", "mongodb.NewDryRunRepository.ReplaceOne", ErrNotFound)
+	}
+	return doc, nil
+}
+
+func (r *dryRunRepository[T]) FindOneOrCreate(ctx context.Context, filter bson.M, defaultDoc T, opts ...*options.FindOneAndUpdateOptions) (T, bool, error) {
+	doc, err := r.RepositoryI.FindOne(ctx, filter)
+	if err == nil {
+		return doc, false, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return defaultDoc, false, fmt.Errorf("%v: %w", "mongodb.NewDryRunRepository.FindOneOrCreate", err)
+	}
+
+	r.log(DryRunEvent{Operation: "FindOneOrCreate", Filter: filter, Affected: 1, SampleIDs: insertedIDs(registryOf(r.RepositoryI), []T{defaultDoc}, 1)})
+	return defaultDoc, true, nil
+}
+
+func (r *dryRunRepository[T]) UpsertManyByKey(ctx context.Context, docs []T, keyFields []string, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	filters, err := keyFilters(docs, keyFields)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.NewDryRunRepository.UpsertManyByKey", err)
+	}
+
+	res := &mongo.BulkWriteResult{UpsertedIDs: map[int64]interface{}{}}
+	for i, filter := range filters {
+		n, err := r.report(ctx, "UpsertManyByKey", filter, false)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			res.UpsertedCount++
+			res.UpsertedIDs[int64(i)] = presetID(registryOf(r.RepositoryI), docs[i])
+			continue
+		}
+		res.MatchedCount++
+		res.ModifiedCount++
+	}
+	return res, nil
+}
+
+func (r *dryRunRepository[T]) DeleteOne(ctx context.Context, filter bson.M, opts ...*options.DeleteOptions) error {
+	if len(filter) == 0 {
+		return fmt.Errorf("DeleteOne: Filter can not be empty. Filter: %v", filter)
+	}
+
+	_, err := r.report(ctx, "DeleteOne", filter, false)
+	return err
+}
+
+func (r *dryRunRepository[T]) DeleteOneWhere(ctx context.Context, filter Filter, opts ...*options.DeleteOptions) error {
+	if filter.Len() == 0 {
+		return fmt.Errorf("DeleteOneWhere: Filter can not be empty. Filter: %v", filter.Document())
+	}
+
+	_, err := r.report(ctx, "DeleteOneWhere", filter, false)
+	return err
+}
+
+func (r *dryRunRepository[T]) DeleteMany(ctx context.Context, filter bson.M, opts ...*options.DeleteOptions) (int, error) {
+	return r.report(ctx, "DeleteMany", filter, true)
+}
+
+func (r *dryRunRepository[T]) DeleteManyWhere(ctx context.Context, filter Filter, opts ...*options.DeleteOptions) (int, error) {
+	return r.report(ctx, "DeleteManyWhere", filter, true)
+}
+
+func (r *dryRunRepository[T]) DeleteManyByIDs(ctx context.Context, ids []primitive.ObjectID, chunkSize int) (int, error) {
+	deleted := 0
+	err := ChunkedIn(ids, chunkSize, func(filterValue primitive.M) error {
+		n, err := r.report(ctx, "DeleteManyByIDs", bson.M{"_id": filterValue}, true)
+		deleted += n
+		return err
+	})
+	return deleted, err
+}
+
+func (r *dryRunRepository[T]) DeleteManyAudited(ctx context.Context, filter bson.M, batchSize int, onBatch func(deletedIDs []primitive.ObjectID) error) (int, error) {
+	return r.report(ctx, "DeleteManyAudited", filter, true)
+}
+
+func (r *dryRunRepository[T]) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	res := &mongo.BulkWriteResult{UpsertedIDs: map[int64]interface{}{}}
+	for i, model := range models {
+		var (
+			n      int
+			err    error
+			upsert *bool
+		)
+		switch model := model.(type) {
+		case *mongo.InsertOneModel:
+			r.log(DryRunEvent{Operation: "BulkWrite.InsertOne", Affected: 1, SampleIDs: insertedIDs(registryOf(r.RepositoryI), []interface{}{model.Document}, 1)})
+			res.InsertedCount++
+			continue
+		case *mongo.UpdateOneModel:
+			n, err = r.report(ctx, "BulkWrite.UpdateOne", model.Filter, false)
+			upsert = model.Upsert
+		case *mongo.UpdateManyModel:
+			n, err = r.report(ctx, "BulkWrite.UpdateMany", model.Filter, true)
+			upsert = model.Upsert
+		case *mongo.ReplaceOneModel:
+			n, err = r.report(ctx, "BulkWrite.ReplaceOne", model.Filter, false)
+			upsert = model.Upsert
+		case *mongo.DeleteOneModel:
+			n, err = r.report(ctx, "BulkWrite.DeleteOne", model.Filter, false)
+			res.DeletedCount += int64(n)
+			continue
+		case *mongo.DeleteManyModel:
+			n, err = r.report(ctx, "BulkWrite.DeleteMany", model.Filter, true)
+			res.DeletedCount += int64(n)
+			continue
+		default:
+			return res, fmt.Errorf("%v: write model %d: unsupported type %T", "mongodb.NewDryRunRepository.BulkWrite", i, model)
+		}
+		if err != nil {
+			return res, err
+		}
+
+		res.MatchedCount += int64(n)
+		res.ModifiedCount += int64(n)
+		if n == 0 && upsert != nil && *upsert {
+			res.UpsertedCount++
+			res.UpsertedIDs[int64(i)] = nil
+		}
+	}
+	return res, nil
+}
+
+func (r *dryRunRepository[T]) Drop(ctx context.Context) error {
+	_, err := r.report(ctx, "Drop", bson.M{}, true)
+	return err
+}
+
+func (r *dryRunRepository[T]) CreateIndex(ctx context.Context, keys bson.D, opts ...*options.IndexOptions) (string, error) {
+	r.log(DryRunEvent{Operation: "CreateIndex"})
+	return declaredIndexName(mongo.IndexModel{Keys: keys, Options: options.MergeIndexOptions(opts...)}, keys), nil
+}
+
+func (r *dryRunRepository[T]) CreateIndexes(ctx context.Context, models []mongo.IndexModel) ([]string, error) {
+	names := make([]string, 0, len(models))
+	for i, model := range models {
+		keys, ok := model.Keys.(bson.D)
+		if !ok {
+			return nil, fmt.Errorf("%v: index %d: keys must be a bson.D, got %T", "mongodb.NewDryRunRepository.CreateIndexes", i, model.Keys)
+		}
+		names = append(names, declaredIndexName(model, keys))
+	}
+
+	r.log(DryRunEvent{Operation: "CreateIndexes"})
+	return names, nil
+}
+
+func (r *dryRunRepository[T]) DropIndex(ctx context.Context, name string) error {
+	r.log(DryRunEvent{Operation: "DropIndex"})
+	return nil
+}
+
+func (r *dryRunRepository[T]) SetIndexExpireAfter(ctx context.Context, name string, expireAfter time.Duration) error {
+	r.log(DryRunEvent{Operation: "SetIndexExpireAfter"})
+	return nil
+}
+
+// report counts and samples the documents matching filter, and logs them as the documents affected by the write name.
+// A write that is not many affects at most one document.
+func (r *dryRunRepository[T]) report(ctx context.Context, name string, filter interface{}, many bool) (int, error) {
+	query := dryRunFilter(filter)
+
+	limit := int64(1)
+	if many {
+		limit = dryRunSampleSize
+	}
+	cur, err := r.Forwarder.FindCursor(ctx, query, options.Find().SetProjection(bson.M{"_id": 1}).SetLimit(limit))
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", "mongodb.NewDryRunRepository."+name, err)
+	}
+	var docs []struct {
+		ID interface{} `bson:"_id"`
+	}
+	if err := cur.All(ctx, &docs); err != nil {
+		return 0, fmt.Errorf("%v: %w", "mongodb.NewDryRunRepository."+name, err)
+	}
+
+	ids := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+	}
+
+	n := len(ids)
+	if many && n == dryRunSampleSize {
+		if n, err = r.RepositoryI.CountDocuments(ctx, query); err != nil {
+			return 0, fmt.Errorf("%v: %w", "mongodb.NewDryRunRepository."+name, err)
+		}
+	}
+
+	r.log(DryRunEvent{Operation: name, Filter: filter, Affected: n, SampleIDs: ids})
+	return n, nil
+}
+
+// dryRunFilter converts the filter of a write into a bson.M for FindCursor and CountDocuments.
+func dryRunFilter(filter interface{}) bson.M {
+	switch filter := filter.(type) {
+	case nil:
+		return bson.M{}
+	case bson.M:
+		if filter == nil {
+			return bson.M{}
+		}
+		return filter
+	case Filter:
+		if filter.Len() == 0 {
+			return bson.M{}
+		}
+		return bson.M{"$and": bson.A{filter.Document()}}
+	default:
+		return bson.M{"$and": bson.A{filter}}
+	}
+}
+
+// insertedIDs returns the _ids of the first n documents that have one.
+func insertedIDs[D any](registry *bsoncodec.Registry, docs []D, n int) []interface{} {
+	var ids []interface{}
+	for _, doc := range docs {
+		if len(ids) == n {
+			break
+		}
+		if id := presetID(registry, doc); id != nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// presetID returns the _id of doc marshaled with registry, or nil if it has none yet.
+func presetID(registry *bsoncodec.Registry, doc interface{}) interface{} {
+	id, err := documentID(registry, doc)
+	if err != nil {
+		return nil
+	}
+	var value interface{}
+	if err := id.Unmarshal(&value); err != nil {
+		return nil
+	}
+	return value
+}
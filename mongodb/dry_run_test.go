@@ -0,0 +1,142 @@
+package mongodb_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestDryRunRepositoryInsertsWithoutServer(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	var events []mongodb.DryRunEvent
+	repo := mongodb.NewDryRunRepository[*User](mongodb.NewRepository[*User](client.Database("testdb").Collection("user_dry_run")),
+		func(e mongodb.DryRunEvent) { events = append(events, e) })
+
+	id := primitive.NewObjectID()
+	user := &User{BaseModel: mongodb.BaseModel{MongoID: id}, Name: "Alice"}
+	_, err = repo.InsertOne(ctx, user)
+	assert.NoError(t, err)
+	assert.True(t, user.CreatedAt.IsZero(), "the document must not be initialized")
+
+	inserter, err := mongodb.Capability[mongodb.InsertManyResult[*User]](repo)
+	assert.NoError(t, err)
+	_, res, err := inserter.InsertManyResult(ctx, []*User{{Name: "Bob"}, user})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{nil, id}, res.InsertedIDs)
+
+	indexes, err := mongodb.Capability[mongodb.IndexManager](repo)
+	assert.NoError(t, err)
+	name, err := indexes.CreateIndex(ctx, bson.D{{Key: "email", Value: 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, "email_1", name)
+
+	assert.Equal(t, []mongodb.DryRunEvent{
+		{Operation: "InsertOne", Affected: 1, SampleIDs: []interface{}{id}},
+		{Operation: "InsertManyResult", Affected: 2, SampleIDs: []interface{}{id}},
+		{Operation: "CreateIndex"},
+	}, events)
+}
+
+func TestDryRunRepositoryRejectsWritingPipelines(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	var events []mongodb.DryRunEvent
+	repo := mongodb.NewDryRunRepository[*User](mongodb.NewRepository[*User](client.Database("testdb").Collection("user_dry_run_pipeline")),
+		func(e mongodb.DryRunEvent) { events = append(events, e) })
+
+	for _, stage := range []bson.D{
+		{{Key: "$out", Value: "user_dry_run_out"}},
+		{{Key: "$merge", Value: bson.M{"into": "user_dry_run_out"}}},
+	} {
+		_, err := repo.Aggregate(ctx, mongo.Pipeline{{{Key: "$match", Value: bson.M{}}}, stage})
+		assert.ErrorIs(t, err, mongodb.ErrDryRunWrite)
+	}
+	assert.Empty(t, events)
+}
+
+func TestDryRunRepository(t *testing.T) {
+	ctx := context.Background()
+	inner := mongodb.NewRepository[*User](testCollection(t, "user_dry_run"))
+
+	users := make([]*User, 30)
+	for i := range users {
+		users[i] = &User{Name: fmt.Sprintf("User %d", i), Email: fmt.Sprintf("user%d@example.com", i%3)}
+	}
+	if _, err := inner.InsertMany(ctx, users); err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	var events []mongodb.DryRunEvent
+	repo := mongodb.NewDryRunRepository(inner, func(e mongodb.DryRunEvent) { events = append(events, e) })
+
+	updater, err := mongodb.Capability[mongodb.UpdateManyResult](repo)
+	assert.NoError(t, err)
+	res, err := updater.UpdateManyResult(ctx, bson.M{"email": "user0@example.com"}, bson.M{"name": "changed"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), res.MatchedCount)
+	assert.Equal(t, int64(10), res.ModifiedCount)
+
+	res, err = repo.UpdateOne(ctx, bson.M{"email": "nobody@example.com"}, bson.M{"name": "changed"}, options.Update().SetUpsert(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), res.MatchedCount)
+	assert.Equal(t, int64(1), res.UpsertedCount)
+
+	deleted, err := repo.DeleteMany(ctx, bson.M{"email": bson.M{"$in": bson.A{"user1@example.com", "user2@example.com"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, 20, deleted)
+
+	assert.NoError(t, repo.DeleteOne(ctx, bson.M{"_id": users[0].MongoID}))
+
+	_, err = repo.ReplaceOne(ctx, bson.M{"email": "nobody@example.com"}, &User{Name: "new"})
+	assert.ErrorIs(t, err, mongodb.ErrNotFound)
+
+	bulk, err := repo.BulkWrite(ctx, []mongo.WriteModel{
+		mongo.NewInsertOneModel().SetDocument(&User{Name: "new"}),
+		mongo.NewDeleteManyModel().SetFilter(bson.M{"email": "user0@example.com"}),
+		mongo.NewUpdateManyModel().SetFilter(bson.M{}).SetUpdate(bson.M{"$set": bson.M{"name": "x"}}),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), bulk.InsertedCount)
+	assert.Equal(t, int64(10), bulk.DeletedCount)
+	assert.Equal(t, int64(30), bulk.MatchedCount)
+
+	admin, err := mongodb.Capability[mongodb.CollectionAdmin](repo)
+	assert.NoError(t, err)
+	assert.NoError(t, admin.Drop(ctx))
+
+	// Reads are passed through, and nothing was changed.
+	count, err := repo.CountDocuments(ctx, bson.M{"name": bson.M{"$regex": "^User "}})
+	assert.NoError(t, err)
+	assert.Equal(t, 30, count)
+
+	if assert.Len(t, events, 9) {
+		assert.Equal(t, "UpdateManyResult", events[0].Operation)
+		assert.Equal(t, 10, events[0].Affected)
+		assert.Len(t, events[0].SampleIDs, 10)
+		assert.Equal(t, 0, events[1].Affected)
+		assert.Equal(t, 20, events[2].Affected)
+		assert.Len(t, events[2].SampleIDs, 10)
+		assert.Equal(t, mongodb.DryRunEvent{Operation: "DeleteOne", Filter: bson.M{"_id": users[0].MongoID}, Affected: 1, SampleIDs: []interface{}{users[0].MongoID}}, events[3])
+		assert.Equal(t, "BulkWrite.InsertOne", events[5].Operation)
+		assert.Equal(t, "BulkWrite.UpdateMany", events[7].Operation)
+		assert.Equal(t, mongodb.DryRunEvent{Operation: "Drop", Filter: bson.M{}, Affected: 30, SampleIDs: events[8].SampleIDs}, events[8])
+	}
+}
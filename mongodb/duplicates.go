@@ -0,0 +1,88 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxDuplicateIDs is the maximum number of _ids returned per [DuplicateGroup].
+const maxDuplicateIDs = 100
+
+type (
+	// DuplicateGroup are documents sharing the same values for the fields passed to [FindDuplicates].
+	DuplicateGroup struct {
+		// Key maps every field to the shared value. Missing fields and null are both reported as nil.
+		Key bson.M
+		// Count is the number of documents in the group.
+		Count int
+		// IDs are the _ids of the documents, at most 100 even if Count is larger.
+		IDs []interface{}
+	}
+)
+
+// FindDuplicates returns the groups of documents matching filter that have the same values for the given fields,
+// ordered by descending count. A limit > 0 limits the number of returned groups.
+//
+// Documents where a field is missing or null are grouped together, since a unique index treats them as equal as well.
+// It is meant to find the duplicates preventing a unique index on fields, see [EnsureUniqueIndex].
+// The _ids are collected with $firstN, so that large groups do not exceed the size limit of documents; it requires MongoDB 5.2.
+func FindDuplicates(ctx context.Context, r Aggregater, fields []string, filter bson.M, limit int) ([]DuplicateGroup, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%v: no fields", "mongodb.FindDuplicates")
+	}
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	// Field paths can contain dots, which are not allowed as keys of the group _id, so the keys are positional.
+	key := bson.D{}
+	for i, field := range fields {
+		key = append(key, bson.E{Key: duplicateKey(i), Value: bson.M{"$ifNull": bson.A{"$" + field, nil}}})
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   key,
+			"count": bson.M{"$sum": 1},
+			"ids":   bson.M{"$firstN": bson.M{"input": "$_id", "n": maxDuplicateIDs}},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+
+	cur, err := r.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.FindDuplicates", err)
+	}
+
+	var groups []struct {
+		Key   bson.M        `bson:"_id"`
+		Count int           `bson:"count"`
+		IDs   []interface{} `bson:"ids"`
+	}
+	if err := cur.All(ctx, &groups); err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.FindDuplicates", err)
+	}
+
+	res := make([]DuplicateGroup, len(groups))
+	for i, group := range groups {
+		key := make(bson.M, len(fields))
+		for j, field := range fields {
+			key[field] = group.Key[duplicateKey(j)]
+		}
+		res[i] = DuplicateGroup{Key: key, Count: group.Count, IDs: group.IDs}
+	}
+	return res, nil
+}
+
+func duplicateKey(i int) string {
+	return fmt.Sprintf("k%d", i)
+}
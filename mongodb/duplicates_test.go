@@ -0,0 +1,57 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	ctx := context.Background()
+	col := testCollection(t, "user_duplicates")
+	repo := mongodb.NewRepository[*User](col)
+
+	_, err := col.InsertMany(ctx, []interface{}{
+		bson.M{"name": "Alice", "email": "alice@example.com"},
+		bson.M{"name": "Alice", "email": "alice@example.com"},
+		bson.M{"name": "Alice", "email": "alice@example.com"},
+		bson.M{"name": "Alice", "email": "other@example.com"},
+		bson.M{"name": "Bob", "email": "bob@example.com"},
+		bson.M{"name": "Bob", "email": "bob@example.com"},
+		bson.M{"name": "Carol"},
+		bson.M{"name": "Carol", "email": nil},
+	})
+	if err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	groups, err := mongodb.FindDuplicates(ctx, repo, []string{"name", "email"}, nil, 0)
+	if err != nil {
+		t.Fatalf("Error on finding duplicates: %v", err)
+	}
+	if !assert.Len(t, groups, 3) {
+		return
+	}
+
+	assert.Equal(t, bson.M{"name": "Alice", "email": "alice@example.com"}, groups[0].Key)
+	assert.Equal(t, 3, groups[0].Count)
+	assert.Len(t, groups[0].IDs, 3)
+
+	// Missing and null are one group.
+	nullGroup := groups[1]
+	if nullGroup.Key["name"] != "Carol" {
+		nullGroup = groups[2]
+	}
+	assert.Equal(t, bson.M{"name": "Carol", "email": nil}, nullGroup.Key)
+	assert.Equal(t, 2, nullGroup.Count)
+
+	limited, err := mongodb.FindDuplicates(ctx, repo, []string{"name"}, bson.M{"name": bson.M{"$ne": "Carol"}}, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, limited, 1) {
+		assert.Equal(t, bson.M{"name": "Alice"}, limited[0].Key)
+		assert.Equal(t, 4, limited[0].Count)
+	}
+}
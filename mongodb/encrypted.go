@@ -0,0 +1,519 @@
+package mongodb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// encryptedVersion is the first byte of every ciphertext, so that the format can be changed later.
+	encryptedVersion byte = 1
+	encryptedRandom  byte = 'r'
+	// encryptedDeterministic ciphertexts derive their nonce from the plaintext, so that equal values encrypt equally.
+	encryptedDeterministic byte = 'd'
+	encryptedKeySize            = 32
+	encryptedNonceSize          = 12
+)
+
+var (
+	// ErrNoKeyProvider is returned when an encrypted field is marshaled or unmarshaled without a registry of [EncryptionRegistry].
+	ErrNoKeyProvider = errors.New("mongodb: no key provider for encrypted fields")
+	// ErrUnknownKey is returned by [StaticKeyProvider] for key ids it does not have.
+	ErrUnknownKey = errors.New("mongodb: unknown encryption key")
+	// ErrDecrypt is returned when an encrypted field can not be decrypted, e.g. because it was modified or encrypted with another key.
+	ErrDecrypt = errors.New("mongodb: can not decrypt field")
+)
+
+type (
+	// KeyProvider provides the AES-256 keys of [EncryptedString] and [EncryptedBytes] fields, see [EncryptionRegistry].
+	//
+	// Every ciphertext stores the id of its key, so keys can be rotated: new values are encrypted with the current key,
+	// while the old keys are still provided to decrypt the values that were not rewritten yet.
+	KeyProvider interface {
+		// CurrentKeyID returns the id of the key new values are encrypted with. Ids are at most 255 bytes.
+		CurrentKeyID() string
+		// Key returns the 32 byte key with the given id.
+		Key(id string) ([]byte, error)
+		// KeyIDs returns the ids of all keys that values may be encrypted with, see [EncryptedStringFilter].
+		KeyIDs() []string
+	}
+
+	// StaticKeyProvider is a [KeyProvider] for keys that are known at startup, e.g. read from the environment.
+	StaticKeyProvider struct {
+		// Current is the id of the key new values are encrypted with.
+		Current string
+		// Keys maps the key ids to the 32 byte keys.
+		Keys map[string][]byte
+	}
+
+	// EncryptedString is a string field that is stored encrypted with AES-256-GCM, with a key of the [KeyProvider]
+	// of [EncryptionRegistry].
+	//
+	// Values are encrypted randomly, so that a value encrypts to a different ciphertext each time.
+	// Fields tagged with encrypt:"deterministic" always encrypt a value to the same ciphertext with the same key,
+	// so that they can be queried for equality with [EncryptedStringFilter]. Their ciphertexts reveal which documents
+	// have equal values. The tag encrypt:"random" selects the default mode explicitly.
+	//
+	//	type Customer struct {
+	//		mongodb.BaseModel `bson:",inline"`
+	//		TaxID             mongodb.EncryptedString `bson:"taxID" encrypt:"deterministic"`
+	//		Notes             mongodb.EncryptedString `bson:"notes"`
+	//	}
+	EncryptedString struct {
+		Value           string
+		isDeterministic bool
+	}
+
+	// EncryptedBytes is a byte slice field that is stored encrypted, like [EncryptedString].
+	EncryptedBytes struct {
+		Value           []byte
+		isDeterministic bool
+	}
+
+	// EncryptedFilter is the filter value of [EncryptedStringFilter] and [EncryptedBytesFilter].
+	// It is encrypted when the filter is marshaled, with the keys of the registry it is marshaled with.
+	EncryptedFilter struct {
+		plaintext []byte
+	}
+
+	// encryptedValue is implemented by the encrypted field types.
+	encryptedValue interface {
+		plaintext() []byte
+		deterministic() bool
+	}
+
+	// encryptedTarget is implemented by the pointers to the encrypted field types.
+	encryptedTarget interface {
+		setPlaintext(plaintext []byte)
+		setDeterministic()
+	}
+
+	// encryptingStructCodec encodes structs like the default struct codec, after applying the encrypt tags of their fields.
+	encryptingStructCodec struct {
+		inner *bsoncodec.StructCodec
+	}
+)
+
+var (
+	encryptedStringType = reflect.TypeOf(EncryptedString{})
+	encryptedBytesType  = reflect.TypeOf(EncryptedBytes{})
+
+	// deterministicFields caches the indexes of the deterministic encrypted fields per struct type.
+	deterministicFields sync.Map
+)
+
+// Compile-time checks that the encrypted fields refuse to be marshaled without [EncryptionRegistry],
+// instead of being stored as plaintext by the default struct codec.
+var (
+	_ bson.ValueMarshaler   = EncryptedString{}
+	_ bson.ValueUnmarshaler = (*EncryptedString)(nil)
+	_ bson.ValueMarshaler   = EncryptedBytes{}
+	_ bson.ValueUnmarshaler = (*EncryptedBytes)(nil)
+	_ bson.Marshaler        = EncryptedFilter{}
+)
+
+func (p StaticKeyProvider) CurrentKeyID() string {
+	return p.Current
+}
+
+func (p StaticKeyProvider) Key(id string) ([]byte, error) {
+	key, ok := p.Keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	return key, nil
+}
+
+func (p StaticKeyProvider) KeyIDs() []string {
+	ids := make([]string, 0, len(p.Keys))
+	for id := range p.Keys {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (e EncryptedString) plaintext() []byte {
+	return []byte(e.Value)
+}
+
+func (e EncryptedString) deterministic() bool {
+	return e.isDeterministic
+}
+
+func (e *EncryptedString) setPlaintext(plaintext []byte) {
+	*e = EncryptedString{Value: string(plaintext)}
+}
+
+func (e *EncryptedString) setDeterministic() {
+	e.isDeterministic = true
+}
+
+func (e EncryptedBytes) plaintext() []byte {
+	return e.Value
+}
+
+func (e EncryptedBytes) deterministic() bool {
+	return e.isDeterministic
+}
+
+func (e *EncryptedBytes) setPlaintext(plaintext []byte) {
+	*e = EncryptedBytes{Value: plaintext}
+}
+
+func (e *EncryptedBytes) setDeterministic() {
+	e.isDeterministic = true
+}
+
+// MarshalBSONValue is only called for registries other than [EncryptionRegistry], and fails with [ErrNoKeyProvider].
+func (e EncryptedString) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return 0, nil, missingEncryptionRegistry(e)
+}
+
+// UnmarshalBSONValue is only called for registries other than [EncryptionRegistry], and fails with [ErrNoKeyProvider].
+func (e *EncryptedString) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	return missingEncryptionRegistry(*e)
+}
+
+// MarshalBSONValue is only called for registries other than [EncryptionRegistry], and fails with [ErrNoKeyProvider].
+func (e EncryptedBytes) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return 0, nil, missingEncryptionRegistry(e)
+}
+
+// UnmarshalBSONValue is only called for registries other than [EncryptionRegistry], and fails with [ErrNoKeyProvider].
+func (e *EncryptedBytes) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	return missingEncryptionRegistry(*e)
+}
+
+// missingEncryptionRegistry returns the error for a value that is marshaled or unmarshaled without [EncryptionRegistry].
+// There is no process-wide key provider to fall back to, so that a registry that was forgotten somewhere, e.g. by
+// unmarshaling with bson.Unmarshal, neither stores plaintext nor silently uses other keys.
+func missingEncryptionRegistry(value interface{}) error {
+	return fmt.Errorf("%w: %T must be marshaled with the registry of mongodb.EncryptionRegistry", ErrNoKeyProvider, value)
+}
+
+// EncryptedStringFilter returns the filter value that matches an [EncryptedString] field tagged with encrypt:"deterministic":
+//
+//	customer, err := repo.FindOne(ctx, bson.M{"taxID": mongodb.EncryptedStringFilter(taxID)})
+//
+// The value is encrypted with every key of the [KeyProvider] when the filter is marshaled, so that values written
+// before a key rotation still match.
+func EncryptedStringFilter(value string) EncryptedFilter {
+	return EncryptedFilter{plaintext: []byte(value)}
+}
+
+// EncryptedBytesFilter returns the filter value that matches a deterministic [EncryptedBytes] field, like [EncryptedStringFilter].
+func EncryptedBytesFilter(value []byte) EncryptedFilter {
+	return EncryptedFilter{plaintext: value}
+}
+
+// MarshalBSON is only called for registries other than [EncryptionRegistry], and fails with [ErrNoKeyProvider].
+func (f EncryptedFilter) MarshalBSON() ([]byte, error) {
+	return nil, missingEncryptionRegistry(f)
+}
+
+// marshal returns the filter document that matches the ciphertexts of the plaintext with every key of provider.
+func (f EncryptedFilter) marshal(provider KeyProvider) ([]byte, error) {
+	ciphertexts := bson.A{}
+	for _, id := range provider.KeyIDs() {
+		ciphertext, err := encrypt(provider, id, f.plaintext, true)
+		if err != nil {
+			return nil, err
+		}
+		ciphertexts = append(ciphertexts, primitive.Binary{Subtype: bsontype.BinaryUserDefined, Data: ciphertext})
+	}
+	return bson.Marshal(bson.M{"$in": ciphertexts})
+}
+
+// encrypt returns the ciphertext of plaintext as version, mode, key id length, key id, nonce and the sealed plaintext.
+// The header is authenticated as additional data.
+func encrypt(provider KeyProvider, keyID string, plaintext []byte, deterministic bool) ([]byte, error) {
+	if len(keyID) > 255 {
+		return nil, fmt.Errorf("key id %q is longer than 255 bytes", keyID)
+	}
+	aead, key, err := keyCipher(provider, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := encryptedRandom
+	if deterministic {
+		mode = encryptedDeterministic
+	}
+	header := append([]byte{encryptedVersion, mode, byte(len(keyID))}, keyID...)
+
+	nonce := make([]byte, encryptedNonceSize)
+	if deterministic {
+		// A synthetic nonce: equal plaintexts get equal nonces, different ones different nonces.
+		mac := hmac.New(sha256.New, deriveKey(key, "nonce"))
+		mac.Write(header)
+		mac.Write(plaintext)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	ciphertext := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+aead.Overhead())
+	ciphertext = append(append(ciphertext, header...), nonce...)
+	return aead.Seal(ciphertext, nonce, plaintext, header), nil
+}
+
+func decrypt(provider KeyProvider, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < 3 || ciphertext[0] != encryptedVersion {
+		return nil, fmt.Errorf("%w: unknown format", ErrDecrypt)
+	}
+	headerSize := 3 + int(ciphertext[2])
+	if len(ciphertext) < headerSize+encryptedNonceSize {
+		return nil, fmt.Errorf("%w: truncated ciphertext", ErrDecrypt)
+	}
+	header, keyID := ciphertext[:headerSize], string(ciphertext[3:headerSize])
+
+	aead, _, err := keyCipher(provider, keyID)
+	if err != nil {
+		return nil, err
+	}
+	nonce := ciphertext[headerSize : headerSize+encryptedNonceSize]
+	plaintext, err := aead.Open(nil, nonce, ciphertext[headerSize+encryptedNonceSize:], header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: key %q: %v", ErrDecrypt, keyID, err)
+	}
+	return plaintext, nil
+}
+
+// keyCipher returns the AES-GCM cipher for the key with the given id, and the key.
+func keyCipher(provider KeyProvider, keyID string) (cipher.AEAD, []byte, error) {
+	key, err := provider.Key(keyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(key) != encryptedKeySize {
+		return nil, nil, fmt.Errorf("key %q has %d bytes instead of %d", keyID, len(key), encryptedKeySize)
+	}
+
+	block, err := aes.NewCipher(deriveKey(key, "encryption"))
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aead, key, nil
+}
+
+// deriveKey derives a key for a single purpose from key, so that the same key is never used for AES and HMAC.
+func deriveKey(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("mongodb.encrypted " + purpose))
+	return mac.Sum(nil)
+}
+
+// EncryptionRegistry returns a registry with the default codecs, and codecs that encrypt and decrypt [EncryptedString]
+// and [EncryptedBytes] fields and [EncryptedFilter] values with the keys of provider. Its struct codec applies
+// the encrypt tags of the fields.
+//
+// Set it on the client with options.ClientOptions.SetRegistry, and pass it to every repository with [WithRegistry],
+// or use the DataStore option WithEncryptedFields, which does both. Since every registry has its own provider,
+// clients with different keys do not affect each other.
+func EncryptionRegistry(provider KeyProvider) *bsoncodec.Registry {
+	codec := encryptionCodec{provider: provider}
+	structCodec, err := bsoncodec.NewStructCodec(bsoncodec.DefaultStructTagParser)
+	if err != nil {
+		// The default struct tag parser never fails.
+		panic(err)
+	}
+
+	registry := bson.NewRegistry()
+	registry.RegisterKindEncoder(reflect.Struct, encryptingStructCodec{inner: structCodec})
+	for _, t := range []reflect.Type{encryptedStringType, encryptedBytesType} {
+		registry.RegisterTypeEncoder(t, bsoncodec.ValueEncoderFunc(codec.encodeValue))
+		registry.RegisterTypeDecoder(t, bsoncodec.ValueDecoderFunc(codec.decodeValue))
+		// Pointers would otherwise be encoded by the BSON methods of the types, which fail without a provider.
+		registry.RegisterTypeEncoder(reflect.PtrTo(t), pointerEncoder(codec.encodeValue))
+		registry.RegisterTypeDecoder(reflect.PtrTo(t), pointerDecoder(codec.decodeValue))
+	}
+	registry.RegisterTypeEncoder(reflect.TypeOf(EncryptedFilter{}), bsoncodec.ValueEncoderFunc(codec.encodeFilter))
+	registry.RegisterTypeEncoder(reflect.TypeOf(&EncryptedFilter{}), pointerEncoder(codec.encodeFilter))
+	return registry
+}
+
+// EncodeValue encodes a copy of val whose deterministic encrypted fields are marked, so that val itself is not modified.
+func (c encryptingStructCodec) EncodeValue(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if !val.IsValid() || val.Kind() != reflect.Struct {
+		return c.inner.EncodeValue(ec, vw, val)
+	}
+	indexes, err := deterministicIndexes(val.Type())
+	if err != nil {
+		return err
+	}
+	if len(indexes) == 0 {
+		return c.inner.EncodeValue(ec, vw, val)
+	}
+
+	cp := reflect.New(val.Type()).Elem()
+	cp.Set(val)
+	for _, index := range indexes {
+		field := cp.FieldByIndex(index)
+		if field.Kind() == reflect.Pointer {
+			if field.IsNil() {
+				continue
+			}
+			// The pointer is shared with val, so the value it points to is copied.
+			elem := reflect.New(field.Type().Elem())
+			elem.Elem().Set(field.Elem())
+			field.Set(elem)
+			field = elem.Elem()
+		}
+		field.Addr().Interface().(encryptedTarget).setDeterministic()
+	}
+	return c.inner.EncodeValue(ec, vw, cp)
+}
+
+// deterministicIndexes returns the indexes of the encrypted fields of t tagged with encrypt:"deterministic",
+// including those of inlined structs, which the struct codec encodes as part of t.
+func deterministicIndexes(t reflect.Type) ([][]int, error) {
+	if cached, ok := deterministicFields.Load(t); ok {
+		return cached.([][]int), nil
+	}
+
+	var indexes [][]int
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tags, err := bsoncodec.DefaultStructTagParser.ParseStructTags(sf)
+		if err != nil {
+			return nil, err
+		}
+		if tags.Skip {
+			continue
+		}
+
+		fieldType := sf.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		switch {
+		case fieldType == encryptedStringType || fieldType == encryptedBytesType:
+			switch mode := sf.Tag.Get("encrypt"); mode {
+			case "deterministic":
+				indexes = append(indexes, []int{i})
+			case "", "random":
+			default:
+				return nil, fmt.Errorf("%v.%v: unknown encrypt mode %q", t, sf.Name, mode)
+			}
+		case tags.Inline && sf.Type.Kind() == reflect.Struct:
+			nested, err := deterministicIndexes(sf.Type)
+			if err != nil {
+				return nil, err
+			}
+			for _, index := range nested {
+				indexes = append(indexes, append([]int{i}, index...))
+			}
+		}
+	}
+
+	deterministicFields.Store(t, indexes)
+	return indexes, nil
+}
+
+// encryptionCodec encodes and decodes the encrypted types with the keys of provider.
+type encryptionCodec struct {
+	provider KeyProvider
+}
+
+func (c encryptionCodec) keys() (KeyProvider, error) {
+	if c.provider == nil {
+		return nil, ErrNoKeyProvider
+	}
+	return c.provider, nil
+}
+
+func (c encryptionCodec) encodeValue(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	provider, err := c.keys()
+	if err != nil {
+		return err
+	}
+	value := val.Interface().(encryptedValue)
+	ciphertext, err := encrypt(provider, provider.CurrentKeyID(), value.plaintext(), value.deterministic())
+	if err != nil {
+		return err
+	}
+	return vw.WriteBinaryWithSubtype(ciphertext, bsontype.BinaryUserDefined)
+}
+
+func (c encryptionCodec) decodeValue(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	target := val.Addr().Interface().(encryptedTarget)
+	switch vr.Type() {
+	case bsontype.Null:
+		target.setPlaintext(nil)
+		return vr.ReadNull()
+	case bsontype.Binary:
+	default:
+		return fmt.Errorf("%w: can not decode %v into %v", ErrDecrypt, vr.Type(), val.Type())
+	}
+
+	ciphertext, _, err := vr.ReadBinary()
+	if err != nil {
+		return err
+	}
+	provider, err := c.keys()
+	if err != nil {
+		return err
+	}
+	plaintext, err := decrypt(provider, ciphertext)
+	if err != nil {
+		return err
+	}
+	target.setPlaintext(plaintext)
+	return nil
+}
+
+func (c encryptionCodec) encodeFilter(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	provider, err := c.keys()
+	if err != nil {
+		return err
+	}
+	data, err := val.Interface().(EncryptedFilter).marshal(provider)
+	if err != nil {
+		return err
+	}
+	return bsonrw.Copier{}.CopyDocumentFromBytes(vw, data)
+}
+
+// pointerEncoder encodes nil pointers as null and all other pointers with encode.
+func pointerEncoder(encode bsoncodec.ValueEncoderFunc) bsoncodec.ValueEncoderFunc {
+	return func(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+		if val.IsNil() {
+			return vw.WriteNull()
+		}
+		return encode(ec, vw, val.Elem())
+	}
+}
+
+// pointerDecoder decodes null into a nil pointer and all other values with decode into a new value.
+func pointerDecoder(decode bsoncodec.ValueDecoderFunc) bsoncodec.ValueDecoderFunc {
+	return func(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+		if vr.Type() == bsontype.Null {
+			val.Set(reflect.Zero(val.Type()))
+			return vr.ReadNull()
+		}
+		if val.IsNil() {
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+		return decode(dc, vr, val.Elem())
+	}
+}
@@ -0,0 +1,233 @@
+package mongodb_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Customer struct {
+	mongodb.BaseModel `bson:",inline"`
+	Name              string                  `bson:"name"`
+	TaxID             mongodb.EncryptedString `bson:"taxID" encrypt:"deterministic"`
+	Notes             mongodb.EncryptedString `bson:"notes" encrypt:"random"`
+	Card              *mongodb.EncryptedBytes `bson:"card,omitempty" encrypt:"deterministic"`
+}
+
+// testKeys returns a key provider with the given keys, of which current encrypts new values.
+func testKeys(current string, ids ...string) mongodb.StaticKeyProvider {
+	keys := map[string][]byte{}
+	for _, id := range ids {
+		keys[id] = bytes.Repeat([]byte(id[:1]), 32)
+	}
+	return mongodb.StaticKeyProvider{Current: current, Keys: keys}
+}
+
+func TestEncryptedRoundTrip(t *testing.T) {
+	registry := mongodb.EncryptionRegistry(testKeys("k1", "k1"))
+
+	customer := &Customer{Name: "Alice", TaxID: mongodb.EncryptedString{Value: "DE123"}, Notes: mongodb.EncryptedString{Value: "pays late"},
+		Card: &mongodb.EncryptedBytes{Value: []byte{4, 2}}}
+	data, err := bson.MarshalWithRegistry(registry, customer)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "DE123")
+	assert.NotContains(t, string(data), "pays late")
+
+	var decoded Customer
+	assert.NoError(t, bson.UnmarshalWithRegistry(registry, data, &decoded))
+	assert.Equal(t, "Alice", decoded.Name)
+	assert.Equal(t, "DE123", decoded.TaxID.Value)
+	assert.Equal(t, "pays late", decoded.Notes.Value)
+	assert.Equal(t, []byte{4, 2}, decoded.Card.Value)
+
+	again, err := bson.MarshalWithRegistry(registry, customer)
+	assert.NoError(t, err)
+	raw, rawAgain := bson.Raw(data), bson.Raw(again)
+	assert.Equal(t, raw.Lookup("taxID"), rawAgain.Lookup("taxID"), "deterministic fields encrypt equally")
+	assert.Equal(t, raw.Lookup("card"), rawAgain.Lookup("card"))
+	assert.NotEqual(t, raw.Lookup("notes"), rawAgain.Lookup("notes"), "other fields encrypt randomly")
+
+	// Decoded values keep the mode of their field.
+	decodedAgain, err := bson.MarshalWithRegistry(registry, &decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, raw.Lookup("taxID"), bson.Raw(decodedAgain).Lookup("taxID"))
+
+	// Encoding does not mark the fields of the encoded value.
+	assert.Equal(t, mongodb.EncryptedString{Value: "DE123"}, customer.TaxID)
+
+	// Modified ciphertexts are rejected.
+	_, ciphertext := raw.Lookup("taxID").Binary()
+	ciphertext = append([]byte(nil), ciphertext...)
+	ciphertext[len(ciphertext)-1] ^= 1
+	tampered, err := bson.Marshal(bson.M{"taxID": primitive.Binary{Subtype: 0x80, Data: ciphertext}})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, bson.UnmarshalWithRegistry(registry, tampered, &decoded), mongodb.ErrDecrypt)
+}
+
+func TestEncryptedKeyRotation(t *testing.T) {
+	data, err := bson.MarshalWithRegistry(mongodb.EncryptionRegistry(testKeys("old", "old")), &Customer{TaxID: mongodb.EncryptedString{Value: "DE123"}})
+	assert.NoError(t, err)
+
+	rotatedRegistry := mongodb.EncryptionRegistry(testKeys("new", "old", "new"))
+	var decoded Customer
+	assert.NoError(t, bson.UnmarshalWithRegistry(rotatedRegistry, data, &decoded), "old keys still decrypt")
+	assert.Equal(t, "DE123", decoded.TaxID.Value)
+
+	rotated, err := bson.MarshalWithRegistry(rotatedRegistry, &decoded)
+	assert.NoError(t, err)
+	assert.NotEqual(t, bson.Raw(data).Lookup("taxID"), bson.Raw(rotated).Lookup("taxID"), "new values use the current key")
+
+	filter, err := bson.MarshalWithRegistry(rotatedRegistry, bson.M{"taxID": mongodb.EncryptedStringFilter("DE123")})
+	assert.NoError(t, err)
+	values, err := bson.Raw(filter).Lookup("taxID", "$in").Array().Values()
+	assert.NoError(t, err)
+	assert.Len(t, values, 2)
+	for _, raw := range []bson.Raw{data, rotated} {
+		assert.Contains(t, values, raw.Lookup("taxID"))
+	}
+
+	assert.ErrorIs(t, bson.UnmarshalWithRegistry(mongodb.EncryptionRegistry(testKeys("new", "new")), data, &decoded), mongodb.ErrUnknownKey,
+		"removed keys no longer decrypt")
+}
+
+func TestEncryptionRegistriesAreIndependent(t *testing.T) {
+	first := mongodb.EncryptionRegistry(testKeys("a", "a"))
+	second := mongodb.EncryptionRegistry(testKeys("b", "b"))
+
+	data, err := bson.MarshalWithRegistry(first, &Customer{TaxID: mongodb.EncryptedString{Value: "DE123"}})
+	assert.NoError(t, err)
+	var decoded Customer
+	assert.ErrorIs(t, bson.UnmarshalWithRegistry(second, data, &decoded), mongodb.ErrUnknownKey)
+	assert.NoError(t, bson.UnmarshalWithRegistry(first, data, &decoded))
+
+	// Without the registry, there are no keys, and nothing is stored or decoded as plaintext.
+	_, err = bson.Marshal(&Customer{})
+	assert.ErrorIs(t, err, mongodb.ErrNoKeyProvider)
+	_, err = bson.Marshal(bson.M{"taxID": mongodb.EncryptedStringFilter("DE123")})
+	assert.ErrorIs(t, err, mongodb.ErrNoKeyProvider)
+	assert.ErrorIs(t, bson.Unmarshal(data, &decoded), mongodb.ErrNoKeyProvider)
+}
+
+func TestEncryptedUnknownMode(t *testing.T) {
+	type Invalid struct {
+		TaxID mongodb.EncryptedString `bson:"taxID" encrypt:"sometimes"`
+	}
+	_, err := bson.MarshalWithRegistry(mongodb.EncryptionRegistry(testKeys("k1", "k1")), &Invalid{})
+	assert.ErrorContains(t, err, `unknown encrypt mode "sometimes"`)
+}
+
+func TestEncryptedWritesUseTheRegistry(t *testing.T) {
+	registry := mongodb.EncryptionRegistry(testKeys("k1", "k1"))
+	customer := &Customer{Name: "Alice", TaxID: mongodb.EncryptedString{Value: "DE123"}}
+	customer.InitDocument()
+	data, err := bson.MarshalWithRegistry(registry, customer)
+	assert.NoError(t, err)
+	want := bson.Raw(data).Lookup("taxID")
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("ReplaceOne", func(mt *mtest.T) {
+		repo := mongodb.NewRepository[*Customer](mt.Coll, mongodb.WithRegistry(registry))
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		_, err := repo.ReplaceOne(context.Background(), mongodb.MongoIDFilter(customer.MongoID), customer)
+		assert.NoError(t, err)
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, want, update.Lookup("u", "taxID"))
+	})
+	mt.Run("UpsertManyByKey", func(mt *mtest.T) {
+		repo := mongodb.NewRepository[*Customer](mt.Coll, mongodb.WithRegistry(registry)).(*mongodb.Repository[*Customer])
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		_, err := repo.UpsertManyByKey(context.Background(), []*Customer{customer}, []string{"name"})
+		assert.NoError(t, err)
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, want, update.Lookup("u", "$set", "taxID"))
+	})
+	mt.Run("FindOne", func(mt *mtest.T) {
+		repo := mongodb.NewRepository[*Customer](mt.Coll, mongodb.WithRegistry(registry))
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "db.customer", mtest.FirstBatch, bson.D{{Key: "taxID", Value: want}}))
+
+		res, err := repo.FindOne(context.Background(), bson.M{"taxID": mongodb.EncryptedStringFilter("DE123")})
+		assert.NoError(t, err)
+		assert.Equal(t, "DE123", res.TaxID.Value)
+		filter := mt.GetStartedEvent().Command.Lookup("filter", "taxID", "$in").Array().Index(0).Value()
+		assert.Equal(t, want, filter)
+	})
+	mt.Run("FindOne strict", func(mt *mtest.T) {
+		repo := mongodb.NewRepository[*Customer](mt.Coll, mongodb.WithRegistry(registry), mongodb.WithStrictDecoding(nil))
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "db.customer", mtest.FirstBatch, bson.D{{Key: "taxID", Value: want}}))
+
+		res, err := repo.FindOne(context.Background(), bson.M{})
+		assert.NoError(t, err)
+		assert.Equal(t, "DE123", res.TaxID.Value)
+	})
+	mt.Run("Subscribe", func(mt *mtest.T) {
+		repo := mongodb.NewRepository[*Customer](mt.Coll, mongodb.WithRegistry(registry))
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "db.customer", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: bson.D{{Key: "_data", Value: "1"}}},
+			{Key: "operationType", Value: "insert"},
+			{Key: "fullDocument", Value: bson.D{{Key: "taxID", Value: want}}},
+		}))
+
+		events, cancel, err := mongodb.Subscribe[*Customer](context.Background(), repo, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer cancel()
+		event := <-events
+		assert.NoError(t, event.Err)
+		if assert.NotNil(t, event.FullDocument) {
+			assert.Equal(t, "DE123", event.FullDocument.TaxID.Value)
+		}
+	})
+}
+
+func TestEncryptedFilter(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+	col := client.Database("testdb").Collection("customer_encrypted")
+	if err := col.Drop(ctx); err != nil {
+		t.Fatalf("Error dropping collection: %v", err)
+	}
+	defer col.Drop(context.Background())
+	repo := mongodb.NewRepository[*Customer](col, mongodb.WithRegistry(mongodb.EncryptionRegistry(testKeys("k1", "k1"))))
+
+	_, err = repo.InsertMany(ctx, []*Customer{
+		{Name: "Alice", TaxID: mongodb.EncryptedString{Value: "DE123"}},
+		{Name: "Bob", TaxID: mongodb.EncryptedString{Value: "DE456"}},
+	})
+	if err != nil {
+		t.Fatalf("Error on inserting customers: %v", err)
+	}
+
+	repo = mongodb.NewRepository[*Customer](col, mongodb.WithRegistry(mongodb.EncryptionRegistry(testKeys("k2", "k1", "k2"))))
+	if _, err := repo.InsertOne(ctx, &Customer{Name: "Carol", TaxID: mongodb.EncryptedString{Value: "DE123"}}); err != nil {
+		t.Fatalf("Error on inserting customer: %v", err)
+	}
+
+	// Replaced documents keep matching.
+	alice, err := repo.FindOne(ctx, bson.M{"name": "Alice"})
+	assert.NoError(t, err)
+	_, err = repo.ReplaceOne(ctx, mongodb.MongoIDFilter(alice.MongoID), alice)
+	assert.NoError(t, err)
+
+	customers, err := repo.FindMany(ctx, bson.M{"taxID": mongodb.EncryptedStringFilter("DE123")}, options.Find().SetSort(bson.M{"name": 1}))
+	assert.NoError(t, err)
+	if assert.Len(t, customers, 2) {
+		assert.Equal(t, "Alice", customers[0].Name)
+		assert.Equal(t, "Carol", customers[1].Name)
+		assert.Equal(t, "DE123", customers[1].TaxID.Value)
+	}
+}
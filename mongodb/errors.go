@@ -0,0 +1,46 @@
+package mongodb
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	// ErrIndexConflict is returned when an index with the same name or keys but different options already exists.
+	ErrIndexConflict = errors.New("mongodb: conflicting index already exists")
+	// ErrNotFound is returned when an operation that requires a matching document matched none.
+	ErrNotFound = errors.New("mongodb: document not found")
+	// ErrSameCollection is returned by [CopyDocuments] when source and destination are the same collection.
+	ErrSameCollection = errors.New("mongodb: source and destination are the same collection")
+	// ErrDryRunWrite is returned by the repositories of [NewDryRunRepository] for writes they can not preview,
+	// like aggregation pipelines with a $out or $merge stage.
+	ErrDryRunWrite = errors.New("mongodb: write can not be previewed in dry-run mode")
+	// ErrUnsupported is returned for operations that are not part of [RepositoryI], when the repository does not implement them.
+	ErrUnsupported = errors.New("mongodb: operation not supported by the repository")
+)
+
+// Server error codes, see [https://github.com/mongodb/mongo/blob/master/src/mongo/base/error_codes.yml]
+const (
+	codeIndexOptionsConflict  int32 = 85
+	codeIndexKeySpecsConflict int32 = 86
+	codeNamespaceNotFound     int32 = 26
+	codeCursorNotFound        int32 = 43
+	codeDuplicateKey          int32 = 11000
+)
+
+// hasErrorCode reports whether err is a server error with one of the given codes.
+func hasErrorCode(err error, codes ...int32) bool {
+	var se mongo.ServerError
+	if !errors.As(err, &se) {
+		return false
+	}
+
+	for _, code := range codes {
+		if se.HasErrorCode(int(code)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,12 @@
+//go:build go1.20
+
+package mongodb
+
+import "errors"
+
+// JoinErrors returns an error that wraps the given errors, or nil if all errors are nil. See [errors.Join].
+//
+// errors.Join needs Go 1.20, older versions build the implementation of errors_join_legacy.go instead.
+func JoinErrors(errs ...error) error {
+	return errors.Join(errs...)
+}
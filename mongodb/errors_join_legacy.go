@@ -0,0 +1,58 @@
+//go:build !go1.20
+
+package mongodb
+
+import (
+	"errors"
+	"strings"
+)
+
+type joinError struct {
+	errs []error
+}
+
+// JoinErrors returns an error that wraps the given errors, or nil if all errors are nil.
+// Its message joins the messages of the errors with newlines, and errors.Is and errors.As match any of them.
+func JoinErrors(errs ...error) error {
+	e := &joinError{}
+	for _, err := range errs {
+		if err != nil {
+			e.errs = append(e.errs, err)
+		}
+	}
+	if len(e.errs) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *joinError) Error() string {
+	messages := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (e *joinError) Unwrap() []error {
+	return e.errs
+}
+
+// Is and As are needed, since errors.Is and errors.As only follow Unwrap() []error since Go 1.20.
+func (e *joinError) Is(target error) bool {
+	for _, err := range e.errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *joinError) As(target interface{}) bool {
+	for _, err := range e.errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ExistingIDs reports which of the given ids exist in the collection of r and match extraFilter, e.g. [CompanyIDFilter].
+//
+// The result has an entry for every id, true if a matching document exists. Duplicate ids are queried once.
+// It runs a single Find with an _id $in filter that only returns the _ids, so ids should be chunked by the caller
+// if there are more than a few thousand, see [ChunkedIn]. extraFilter may be nil, and is not modified;
+// a condition on _id in extraFilter is combined with $and. r must implement [CursorFinder], see [Capability].
+func ExistingIDs[T Document[T]](ctx context.Context, r RepositoryI[T], ids []primitive.ObjectID, extraFilter bson.M) (map[primitive.ObjectID]bool, error) {
+	res := make(map[primitive.ObjectID]bool, len(ids))
+	unique := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := res[id]; ok {
+			continue
+		}
+		res[id] = false
+		unique = append(unique, id)
+	}
+	if len(unique) == 0 {
+		return res, nil
+	}
+
+	filter, err := MergeBSON(extraFilter, bson.M{"_id": bson.M{"$in": unique}}, MergeFilter)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.ExistingIDs", err)
+	}
+
+	finder, err := Capability[CursorFinder](r)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.ExistingIDs", err)
+	}
+	found, err := FindManyAs[struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}](ctx, finder, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.ExistingIDs", err)
+	}
+
+	for _, doc := range found {
+		res[doc.ID] = true
+	}
+
+	return res, nil
+}
@@ -0,0 +1,49 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TenantUser struct {
+	mongodb.BaseModel `bson:",inline"`
+	CompanyID         primitive.ObjectID `bson:"companyID"`
+	Name              string             `bson:"name"`
+}
+
+func TestExistingIDs(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*TenantUser](testCollection(t, "user_existing_ids"))
+
+	companyA, companyB := primitive.NewObjectID(), primitive.NewObjectID()
+	users, err := repo.InsertMany(ctx, []*TenantUser{
+		{CompanyID: companyA, Name: "alice"},
+		{CompanyID: companyA, Name: "bob"},
+		{CompanyID: companyB, Name: "carol"},
+	})
+	if err != nil {
+		t.Fatalf("Error inserting users: %v", err)
+	}
+	alice, bob, carol := users[0].GetMongoID(), users[1].GetMongoID(), users[2].GetMongoID()
+	missing := primitive.NewObjectID()
+
+	filter := mongodb.CompanyIDFilter(companyA)
+	exists, err := mongodb.ExistingIDs[*TenantUser](ctx, repo, []primitive.ObjectID{alice, missing, carol, alice, bob}, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, map[primitive.ObjectID]bool{alice: true, bob: true, carol: false, missing: false}, exists)
+	assert.Equal(t, bson.M{"companyID": companyA}, filter)
+
+	// A condition on _id in the extra filter is combined with the ids.
+	exists, err = mongodb.ExistingIDs[*TenantUser](ctx, repo, []primitive.ObjectID{alice, bob}, bson.M{"_id": bson.M{"$ne": bob}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[primitive.ObjectID]bool{alice: true, bob: false}, exists)
+
+	exists, err = mongodb.ExistingIDs[*TenantUser](ctx, repo, nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, exists)
+}
@@ -0,0 +1,99 @@
+package mongodb
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// exportFlushInterval is the number of documents after which the exported lines are flushed to the writer.
+const exportFlushInterval = 100
+
+type (
+	// ExportOption configures [ExportNDJSON].
+	ExportOption interface {
+		apply(*exportOption)
+	}
+)
+
+type (
+	exportOption struct {
+		projection bson.M
+	}
+)
+
+type exportProjectionOption bson.M
+
+func (value exportProjectionOption) apply(o *exportOption) {
+	o.projection = bson.M(value)
+}
+
+// WithExportProjection limits the exported fields, e.g. bson.M{"password": 0} to exclude sensitive fields.
+func WithExportProjection(projection bson.M) ExportOption {
+	return exportProjectionOption(projection)
+}
+
+// ExportNDJSON writes all documents matching filter to w, one canonical Extended JSON document per line,
+// and returns the number of exported documents.
+//
+// The documents are streamed through a cursor ordered by _id and flushed to w every few documents,
+// so the collection is never loaded into memory. A canceled context stops the export after the current document.
+func ExportNDJSON(ctx context.Context, r Aggregater, filter bson.M, w io.Writer, opts ...ExportOption) (int, error) {
+	ops := &exportOption{}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+
+	if filter == nil {
+		filter = bson.M{}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+	if len(ops.projection) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$project", Value: ops.projection}})
+	}
+
+	cur, err := r.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", "mongodb.ExportNDJSON", err)
+	}
+	defer cur.Close(context.Background())
+
+	buf := bufio.NewWriter(w)
+	count := 0
+	for cur.Next(ctx) {
+		line, err := bson.MarshalExtJSON(cur.Current, true, false)
+		if err != nil {
+			return count, fmt.Errorf("%v: document %d: %w", "mongodb.ExportNDJSON", count+1, err)
+		}
+		if _, err := buf.Write(append(line, '\n')); err != nil {
+			return count, fmt.Errorf("%v: %w", "mongodb.ExportNDJSON", err)
+		}
+		count++
+
+		if count%exportFlushInterval == 0 {
+			if err := buf.Flush(); err != nil {
+				return count, fmt.Errorf("%v: %w", "mongodb.ExportNDJSON", err)
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			buf.Flush()
+			return count, fmt.Errorf("%v: %w", "mongodb.ExportNDJSON", err)
+		}
+	}
+	if err := cur.Err(); err != nil {
+		buf.Flush()
+		return count, fmt.Errorf("%v: %w", "mongodb.ExportNDJSON", err)
+	}
+
+	if err := buf.Flush(); err != nil {
+		return count, fmt.Errorf("%v: %w", "mongodb.ExportNDJSON", err)
+	}
+	return count, nil
+}
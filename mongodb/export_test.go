@@ -0,0 +1,84 @@
+package mongodb_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// staticCursor is an Aggregater that returns a cursor over fixed documents without a server.
+type staticCursor struct {
+	docs []interface{}
+}
+
+func (s staticCursor) Aggregate(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	return mongo.NewCursorFromDocuments(s.docs, nil, nil)
+}
+
+func TestExportNDJSONFormat(t *testing.T) {
+	docs := []interface{}{
+		bson.D{{Key: "_id", Value: 1}, {Key: "name", Value: "Alice"}},
+		bson.D{{Key: "_id", Value: 2}, {Key: "name", Value: "Bob"}},
+	}
+
+	var out bytes.Buffer
+	count, err := mongodb.ExportNDJSON(context.Background(), staticCursor{docs: docs}, nil, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, `{"_id":{"$numberInt":"1"},"name":"Alice"}`+"\n"+`{"_id":{"$numberInt":"2"},"name":"Bob"}`+"\n", out.String())
+}
+
+func TestExportNDJSONCanceled(t *testing.T) {
+	docs := make([]interface{}, 10)
+	for i := range docs {
+		docs[i] = bson.D{{Key: "_id", Value: i}}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var out bytes.Buffer
+	_, err := mongodb.ExportNDJSON(ctx, staticCursor{docs: docs}, nil, &out)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestExportNDJSON(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_export"))
+
+	users := make([]*User, 3000)
+	for i := range users {
+		users[i] = &User{Name: fmt.Sprintf("User %d", i), Email: fmt.Sprintf("user%d@example.com", i)}
+	}
+	if _, err := repo.InsertMany(ctx, users); err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	var out bytes.Buffer
+	count, err := mongodb.ExportNDJSON(ctx, repo, bson.M{}, &out, mongodb.WithExportProjection(bson.M{"email": 0}))
+	if err != nil {
+		t.Fatalf("Error on exporting users: %v", err)
+	}
+	assert.Equal(t, 3000, count)
+
+	lines := 0
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var user User
+		if err := bson.UnmarshalExtJSON(scanner.Bytes(), true, &user); err != nil {
+			t.Fatalf("Error on parsing line %d: %v", lines+1, err)
+		}
+		assert.Equal(t, users[lines].MongoID, user.MongoID)
+		assert.Empty(t, user.Email)
+		lines++
+	}
+	assert.Equal(t, 3000, lines)
+}
@@ -0,0 +1,112 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrPanic is matched by the [*PanicError] of a function passed to [Parallel] that panicked.
+var ErrPanic = errors.New("mongodb: function panicked")
+
+// PanicError is returned by [Parallel], [Fetch2] and [Fetch3] for a function that panicked.
+//
+// It matches [ErrPanic] with errors.Is, and unwraps to the panic value if that is an error.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("mongodb: function panicked: %v\n%s", e.Value, e.Stack)
+}
+
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+func (e *PanicError) Is(target error) bool {
+	return target == ErrPanic
+}
+
+// Parallel runs fns concurrently, e.g. the repository calls that gather the data of a single page, and waits for all of them.
+//
+//	var user *User
+//	var orders []*Order
+//	err := mongodb.Parallel(ctx,
+//		func(ctx context.Context) (err error) { user, err = users.GetByID(ctx, userID); return err },
+//		func(ctx context.Context) (err error) { orders, err = orderRepo.FindMany(ctx, filter); return err },
+//	)
+//
+// All functions share a context derived from ctx, which is canceled as soon as the first function fails,
+// and the error of the first failing function is returned. A panic is recovered and returned as a [*PanicError].
+func Parallel(ctx context.Context, fns ...func(ctx context.Context) error) error {
+	group, groupCtx := errgroup.WithContext(ctx)
+	for _, fn := range fns {
+		group.Go(recovered(groupCtx, fn))
+	}
+
+	return group.Wait()
+}
+
+// recovered returns a function that calls fn and returns a [*PanicError] if it panics.
+func recovered(ctx context.Context, fn func(ctx context.Context) error) func() error {
+	return func() (err error) {
+		defer func() {
+			if value := recover(); value != nil {
+				err = &PanicError{Value: value, Stack: debug.Stack()}
+			}
+		}()
+
+		return fn(ctx)
+	}
+}
+
+// Fetch2 runs both functions concurrently like [Parallel], and returns their results.
+//
+//	user, orders, err := mongodb.Fetch2(ctx, fetchUser, fetchOrders)
+//
+// If a function fails, the zero values are returned for both results together with the error.
+func Fetch2[A, B any](ctx context.Context, fetchA func(ctx context.Context) (A, error), fetchB func(ctx context.Context) (B, error)) (A, B, error) {
+	var a A
+	var b B
+	err := Parallel(ctx,
+		func(ctx context.Context) (err error) { a, err = fetchA(ctx); return err },
+		func(ctx context.Context) (err error) { b, err = fetchB(ctx); return err },
+	)
+	if err != nil {
+		var zeroA A
+		var zeroB B
+		return zeroA, zeroB, err
+	}
+
+	return a, b, nil
+}
+
+// Fetch3 runs the three functions concurrently like [Parallel], and returns their results.
+//
+// If a function fails, the zero values are returned for all results together with the error.
+func Fetch3[A, B, C any](ctx context.Context, fetchA func(ctx context.Context) (A, error), fetchB func(ctx context.Context) (B, error), fetchC func(ctx context.Context) (C, error)) (A, B, C, error) {
+	var a A
+	var b B
+	var c C
+	err := Parallel(ctx,
+		func(ctx context.Context) (err error) { a, err = fetchA(ctx); return err },
+		func(ctx context.Context) (err error) { b, err = fetchB(ctx); return err },
+		func(ctx context.Context) (err error) { c, err = fetchC(ctx); return err },
+	)
+	if err != nil {
+		var zeroA A
+		var zeroB B
+		var zeroC C
+		return zeroA, zeroB, zeroC, err
+	}
+
+	return a, b, c, nil
+}
@@ -0,0 +1,74 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallelCancelsSiblings(t *testing.T) {
+	errFailed := errors.New("failed")
+	canceled := make(chan error, 1)
+
+	err := mongodb.Parallel(context.Background(),
+		func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				canceled <- ctx.Err()
+				return ctx.Err()
+			case <-time.After(5 * time.Second):
+				canceled <- nil
+				return nil
+			}
+		},
+		func(ctx context.Context) error {
+			return errFailed
+		},
+	)
+	assert.ErrorIs(t, err, errFailed)
+	assert.ErrorIs(t, <-canceled, context.Canceled)
+}
+
+func TestParallelRecoversPanics(t *testing.T) {
+	errBoom := errors.New("boom")
+	err := mongodb.Parallel(context.Background(), func(ctx context.Context) error {
+		panic(errBoom)
+	})
+	assert.ErrorIs(t, err, mongodb.ErrPanic)
+	assert.ErrorIs(t, err, errBoom)
+
+	var panicErr *mongodb.PanicError
+	if assert.ErrorAs(t, err, &panicErr) {
+		assert.Contains(t, string(panicErr.Stack), "fetch_test.go")
+	}
+
+	_, _, err = mongodb.Fetch2(context.Background(),
+		func(ctx context.Context) (int, error) { return 1, nil },
+		func(ctx context.Context) (string, error) { panic("nil map") },
+	)
+	assert.ErrorIs(t, err, mongodb.ErrPanic)
+}
+
+func TestFetch(t *testing.T) {
+	ctx := context.Background()
+	users, orders, err := mongodb.Fetch2(ctx,
+		func(ctx context.Context) ([]string, error) { return []string{"alice"}, nil },
+		func(ctx context.Context) (int, error) { return 3, nil },
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, users)
+	assert.Equal(t, 3, orders)
+
+	errFailed := errors.New("failed")
+	a, b, c, err := mongodb.Fetch3(ctx,
+		func(ctx context.Context) (int, error) { return 1, nil },
+		func(ctx context.Context) (int, error) { return 2, errFailed },
+		func(ctx context.Context) (int, error) { return 3, nil },
+	)
+	assert.ErrorIs(t, err, errFailed)
+	assert.Equal(t, []int{0, 0, 0}, []int{a, b, c})
+}
@@ -0,0 +1,152 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// FieldPath is a dotted bson path that was validated against a document type, see [NestedField].
+	FieldPath string
+
+	Distincter interface {
+		// Returns the distinct values of the field at path in the documents that match the given filter.
+		//
+		// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.Distinct]
+		Distinct(ctx context.Context, path FieldPath, filter bson.M, opts ...*options.DistinctOptions) ([]interface{}, error)
+	}
+)
+
+// ErrInvalidFieldPath is returned by [NestedField] for Go field names that do not lead to a stored field,
+// and by updates with a key that is not a field of the registered model, see [WithFieldValidation].
+var ErrInvalidFieldPath = errors.New("mongodb: invalid field path")
+
+// NestedField validates a path of Go field names against the struct definition of T, and returns the dotted bson path.
+//
+//	path, err := mongodb.NestedField[*User]("Settings", "Flags", "Beta") // "settings.flags.beta"
+//
+// Fields of inline structs, like the [BaseModel], are addressed directly, e.g. NestedField[*User]("MongoID") is "_id".
+// A slice or array in the middle of the path is allowed, and the path continues with the fields of its elements:
+// MongoDB then matches the path against every element, e.g. "items.sku" matches documents with any item of that sku.
+func NestedField[T any](names ...string) (FieldPath, error) {
+	if len(names) == 0 {
+		return "", fmt.Errorf("%v: %w: empty path", "mongodb.NestedField", ErrInvalidFieldPath)
+	}
+
+	t := documentType[T]()
+	path := make([]string, 0, len(names))
+	for i, name := range names {
+		t = elementType(t)
+		if !IsSubdocumentType(t) {
+			return "", fmt.Errorf("%v: %w: %v is not a struct, at %q", "mongodb.NestedField", ErrInvalidFieldPath, t, strings.Join(names[:i+1], "."))
+		}
+
+		field, ok, err := topLevelField(t, name)
+		if err != nil {
+			return "", fmt.Errorf("%v: %w", "mongodb.NestedField", err)
+		}
+		if !ok {
+			return "", fmt.Errorf("%v: %w: %v has no stored field %v", "mongodb.NestedField", ErrInvalidFieldPath, t, name)
+		}
+
+		path = append(path, field.Path)
+		t = field.Field.Type
+	}
+
+	return FieldPath(strings.Join(path, ".")), nil
+}
+
+// MustNestedField is like [NestedField], but panics if the path is invalid.
+// It is intended for package-level variables, so that invalid paths fail every test of the package.
+func MustNestedField[T any](names ...string) FieldPath {
+	path, err := NestedField[T](names...)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+// elementType dereferences pointers and returns the element type of slices and arrays, except for byte slices.
+func elementType(t reflect.Type) reflect.Type {
+	t = indirectType(t)
+	for (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8 {
+		t = indirectType(t.Elem())
+	}
+	return t
+}
+
+// topLevelField finds the stored field of struct t with the given Go name, including the fields of inline structs.
+func topLevelField(t reflect.Type, name string) (structField, bool, error) {
+	fields, err := structFields(t)
+	if err != nil {
+		return structField{}, false, err
+	}
+
+	for _, field := range fields {
+		if len(field.GoPath) == 1 && field.GoPath[0] == name {
+			return field, true, nil
+		}
+	}
+
+	return structField{}, false, nil
+}
+
+// Desc returns the path for a descending sort, see [SortBy].
+func (p FieldPath) Desc() FieldPath {
+	return "-" + p
+}
+
+func (p FieldPath) String() string {
+	return string(p)
+}
+
+// SortBy builds a sort document from validated paths, where paths returned by [FieldPath.Desc] sort descending.
+//
+//	opts := options.Find().SetSort(mongodb.SortBy(countryField, createdAtField.Desc()))
+func SortBy(paths ...FieldPath) bson.D {
+	fields := make([]string, len(paths))
+	for i, path := range paths {
+		fields[i] = string(path)
+	}
+
+	return IndexKeys(fields...)
+}
+
+type withField struct {
+	path  FieldPath
+	value interface{}
+}
+
+func (w withField) Apply(m primitive.M) {
+	m[string(w.path)] = w.value
+}
+
+// WithField creates a new [FilterOption] that matches the field at the validated path with the value,
+// which may also be a query-condition like [In].
+func WithField(path FieldPath, value interface{}) FilterOption {
+	return withField{path: path, value: value}
+}
+
+// Returns the distinct values of the field at path in the documents that match the given filter.
+//
+// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.Distinct]
+func (r *Repository[T]) Distinct(ctx context.Context, path FieldPath, filter bson.M, opts ...*options.DistinctOptions) (_ []interface{}, err error) {
+	ctx, finish, err := r.begin(ctx, "Distinct", filter)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = finish(err) }()
+
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	return r.CollectionFor(ctx).Distinct(ctx, string(path), filter, opts...)
+}
@@ -0,0 +1,98 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type (
+	Account struct {
+		mongodb.BaseModel `bson:",inline"`
+		AccountProfile    `bson:",inline"`
+		Settings          AccountSettings `bson:"settings"`
+		Items             []*AccountItem  `bson:"items"`
+		Internal          string          `bson:"-"`
+	}
+
+	AccountProfile struct {
+		DisplayName string `bson:"name"`
+	}
+
+	AccountSettings struct {
+		Flags   AccountFlags `bson:"flags"`
+		Renamed string       `bson:"renamed_field"`
+		Since   time.Time    `bson:"since"`
+	}
+
+	AccountFlags struct {
+		Beta bool `bson:"beta"`
+	}
+
+	AccountItem struct {
+		SKU string `bson:"sku"`
+	}
+)
+
+func TestNestedField(t *testing.T) {
+	for _, tt := range []struct {
+		names []string
+		want  mongodb.FieldPath
+	}{
+		{[]string{"Settings", "Flags", "Beta"}, "settings.flags.beta"},
+		{[]string{"Settings", "Renamed"}, "settings.renamed_field"},
+		{[]string{"MongoID"}, "_id"},
+		{[]string{"DisplayName"}, "name"},
+		{[]string{"Items", "SKU"}, "items.sku"},
+	} {
+		path, err := mongodb.NestedField[*Account](tt.names...)
+		assert.NoError(t, err, tt.names)
+		assert.Equal(t, tt.want, path)
+	}
+}
+
+func TestNestedFieldInvalid(t *testing.T) {
+	for _, names := range [][]string{
+		{},
+		{"Setings", "Flags"},
+		{"Settings", "renamed_field"},
+		{"Internal"},
+		{"AccountProfile", "DisplayName"},
+		{"Settings", "Since", "Year"},
+		{"Settings", "Flags", "Beta", "Value"},
+	} {
+		_, err := mongodb.NestedField[*Account](names...)
+		assert.ErrorIs(t, err, mongodb.ErrInvalidFieldPath, names)
+	}
+
+	assert.Panics(t, func() { mongodb.MustNestedField[*Account]("Nope") })
+}
+
+func TestFieldPathFilterAndSort(t *testing.T) {
+	beta := mongodb.MustNestedField[*Account]("Settings", "Flags", "Beta")
+	name := mongodb.MustNestedField[*Account]("DisplayName")
+
+	assert.Equal(t, bson.M{"settings.flags.beta": true}, mongodb.NewFilter(mongodb.WithField(beta, true)))
+	assert.Equal(t, bson.D{{Key: "name", Value: 1}, {Key: "settings.flags.beta", Value: -1}}, mongodb.SortBy(name, beta.Desc()))
+}
+
+func TestDistinct(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*Account](testCollection(t, "account_distinct")).(*mongodb.Repository[*Account])
+
+	_, err := repo.InsertMany(ctx, []*Account{
+		{Items: []*AccountItem{{SKU: "a"}, {SKU: "b"}}},
+		{Items: []*AccountItem{{SKU: "b"}, {SKU: "c"}}},
+	})
+	if err != nil {
+		t.Fatalf("Error on inserting: %v", err)
+	}
+
+	values, err := repo.Distinct(ctx, mongodb.MustNestedField[*Account]("Items", "SKU"), nil)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []interface{}{"a", "b", "c"}, values)
+}
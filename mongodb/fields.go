@@ -0,0 +1,197 @@
+package mongodb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	// BSONField is a field of a struct as the bson encoder stores it, see [BSONFields].
+	BSONField struct {
+		// Name is the key of the field in the document.
+		Name string
+		// OmitEmpty reports whether the field is left out of the document if it is empty.
+		OmitEmpty bool
+		Field     reflect.StructField
+	}
+
+	// structField is a field of a document struct, as it is stored by the bson encoder.
+	structField struct {
+		// Path is the dotted bson path of the field, e.g. "address.city".
+		Path string
+		// GoPath are the names of the Go fields leading to the field, without inlined structs.
+		GoPath []string
+		Field  reflect.StructField
+	}
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	dateTimeType = reflect.TypeOf(primitive.DateTime(0))
+)
+
+// documentType returns the struct type behind the document type T, dereferencing pointers.
+func documentType[T any]() reflect.Type {
+	return indirectType(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// isDateType reports whether values of the type are stored as BSON dates.
+func isDateType(t reflect.Type) bool {
+	t = indirectType(t)
+	return t == timeType || t == dateTimeType
+}
+
+// IsSubdocumentType reports whether values of the type are stored as embedded documents with the fields of the struct, see [BSONFields].
+// Structs with an encoder of their own, like primitive.Binary, primitive.Timestamp, time.Time or types implementing
+// bson.Marshaler or bson.ValueMarshaler, are stored as a single value, so their fields have no paths.
+func IsSubdocumentType(t reflect.Type) bool {
+	t = indirectType(t)
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	encoder, err := bson.DefaultRegistry.LookupEncoder(t)
+	if err != nil {
+		return false
+	}
+	_, ok := encoder.(*bsoncodec.StructCodec)
+	return ok
+}
+
+// BSONFields returns the fields of a struct type the way the bson encoder stores them, with the struct tag rules of the driver:
+// the fields of inline structs are returned in place of the inline field, and fields tagged with "-" and unexported fields are skipped.
+// Nested structs are returned as a single field, [IsSubdocumentType] reports whether they are stored as embedded documents.
+func BSONFields(t reflect.Type) ([]BSONField, error) {
+	t = indirectType(t)
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%v is not a struct", t)
+	}
+
+	var fields []BSONField
+	err := collectBSONFields(t, map[reflect.Type]bool{}, &fields)
+
+	return fields, err
+}
+
+func collectBSONFields(t reflect.Type, inlining map[reflect.Type]bool, fields *[]BSONField) error {
+	if inlining[t] {
+		return nil
+	}
+	inlining[t] = true
+	defer delete(inlining, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tags, err := bsoncodec.DefaultStructTagParser.ParseStructTags(sf)
+		if err != nil {
+			return err
+		}
+		if tags.Skip {
+			continue
+		}
+
+		if tags.Inline {
+			if inline := indirectType(sf.Type); inline.Kind() == reflect.Struct {
+				err = collectBSONFields(inline, inlining, fields)
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		*fields = append(*fields, BSONField{Name: tags.Name, OmitEmpty: tags.OmitEmpty, Field: sf})
+	}
+
+	return nil
+}
+
+// structFields returns all fields of a struct type the way the bson encoder sees them:
+// inline structs are flattened, fields tagged with "-" and unexported fields are skipped,
+// and the fields of nested structs are returned after their parent field with dotted paths.
+func structFields(t reflect.Type) ([]structField, error) {
+	t = indirectType(t)
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%v is not a struct", t)
+	}
+
+	var fields []structField
+	err := collectStructFields(t, "", nil, map[reflect.Type]bool{}, &fields)
+
+	return fields, err
+}
+
+func collectStructFields(t reflect.Type, prefix string, goPrefix []string, visiting map[reflect.Type]bool, fields *[]structField) error {
+	if visiting[t] {
+		return nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	bsonFields, err := BSONFields(t)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range bsonFields {
+		path := prefix + field.Name
+		goPath := append(append([]string{}, goPrefix...), field.Field.Name)
+		*fields = append(*fields, structField{Path: path, GoPath: goPath, Field: field.Field})
+
+		if IsSubdocumentType(field.Field.Type) {
+			err = collectStructFields(indirectType(field.Field.Type), path+".", goPath, visiting, fields)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// lookupField finds the field with the given dotted bson path.
+func lookupField(t reflect.Type, path string) (structField, bool, error) {
+	fields, err := structFields(t)
+	if err != nil {
+		return structField{}, false, err
+	}
+
+	for _, field := range fields {
+		if field.Path == path {
+			return field, true, nil
+		}
+	}
+
+	return structField{}, false, nil
+}
+
+// splitTag splits a comma separated struct tag into its trimmed, non-empty parts.
+func splitTag(tag string) []string {
+	var parts []string
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return parts
+}
@@ -0,0 +1,47 @@
+package mongodb_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBSONFields(t *testing.T) {
+	type Address struct {
+		City string `bson:"city"`
+	}
+	type Contact struct {
+		mongodb.BaseModel `bson:",inline,omitempty"`
+		Name              string           `bson:"name,omitempty"`
+		Address           *Address         `bson:"address"`
+		Avatar            primitive.Binary `bson:"avatar"`
+		Internal          string           `bson:"-"`
+		Untagged          int
+		private           string
+	}
+
+	fields, err := mongodb.BSONFields(reflect.TypeOf(&Contact{}))
+	if err != nil {
+		t.Fatalf("Error on listing fields: %v", err)
+	}
+
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.Name
+	}
+	assert.Equal(t, []string{"_id", "createdAt", "updatedAt", "name", "address", "avatar", "untagged"}, names)
+	assert.True(t, fields[3].OmitEmpty)
+	assert.False(t, fields[4].OmitEmpty)
+
+	assert.True(t, mongodb.IsSubdocumentType(fields[4].Field.Type))
+	assert.False(t, mongodb.IsSubdocumentType(fields[5].Field.Type))
+	assert.False(t, mongodb.IsSubdocumentType(reflect.TypeOf(time.Time{})))
+	assert.False(t, mongodb.IsSubdocumentType(reflect.TypeOf(primitive.Timestamp{})))
+
+	_, err = mongodb.BSONFields(reflect.TypeOf(""))
+	assert.Error(t, err)
+}
@@ -22,6 +22,9 @@ type (
 //	filter := NewFilter(WithCompanyID(companyID))
 //
 // There are also convenience methods to create a filter by MongoID and by CompanyID: [MongoIDFilter] and [CompanyIDFilter]
+//
+// Options are applied in order, so a later option that sets the same field replaces the condition.
+// Use [WithFilter] to combine conditions on the same field with $and instead.
 func NewFilter(opts ...FilterOption) primitive.M {
 	f := primitive.M{}
 
@@ -32,6 +35,18 @@ func NewFilter(opts ...FilterOption) primitive.M {
 	return f
 }
 
+type withFilter primitive.M
+
+func (w withFilter) Apply(m primitive.M) {
+	mergeFilter(m, primitive.M(w))
+}
+
+// WithFilter creates a new [FilterOption] that merges filter into the conditions of the previous options with [MergeFilter],
+// so that conditions on the same field are combined with $and.
+func WithFilter(filter primitive.M) FilterOption {
+	return withFilter(filter)
+}
+
 type withMongoID primitive.ObjectID
 
 func (w withMongoID) Apply(m primitive.M) {
@@ -50,6 +65,22 @@ func MongoIDFilter(id primitive.ObjectID) primitive.M {
 	return NewFilter(WithMongoID(id))
 }
 
+type withCompanyID primitive.ObjectID
+
+func (w withCompanyID) Apply(m primitive.M) {
+	m["companyID"] = primitive.ObjectID(w)
+}
+
+// WithCompanyID creates a new [FilterOption] by the companyID.
+func WithCompanyID(id primitive.ObjectID) FilterOption {
+	return withCompanyID(id)
+}
+
+// CompanyIDFilter creates a new filter by the companyID.
+func CompanyIDFilter(id primitive.ObjectID) primitive.M {
+	return NewFilter(WithCompanyID(id))
+}
+
 // In creates an $in query-condition for the given array.
 // The result is not intended to be used as the root of a query, but as a field-query.
 //
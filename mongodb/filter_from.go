@@ -0,0 +1,153 @@
+package mongodb
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrFilterConflict is returned by [NewFilterFrom] when an option sets a key that the base filter already constrains.
+	ErrFilterConflict = errors.New("mongodb: filter key already set")
+	// ErrForbiddenOperator is returned for filters that contain an operator that runs code on the server, like $where.
+	ErrForbiddenOperator = errors.New("mongodb: forbidden filter operator")
+)
+
+// forbiddenOperators run JavaScript on the server, so they must not come from untrusted input.
+var forbiddenOperators = map[string]bool{
+	"$where":       true,
+	"$function":    true,
+	"$accumulator": true,
+}
+
+type allowOverride []string
+
+func (allowOverride) Apply(primitive.M) {}
+
+// AllowOverride lets the options of [NewFilterFrom] replace the conditions of the base filter for the given keys.
+// It has no effect on other filters.
+func AllowOverride(keys ...string) FilterOption {
+	return allowOverride(keys)
+}
+
+type allowOperators []string
+
+func (allowOperators) Apply(primitive.M) {}
+
+// AllowOperators lets [NewFilterFrom] accept the given operators, e.g. "$where", in the base filter.
+// It has no effect on other filters.
+func AllowOperators(operators ...string) FilterOption {
+	return allowOperators(operators)
+}
+
+// NewFilterFrom combines a pre-built filter, e.g. from a query-string parser or a saved search, with FilterOptions.
+//
+//	filter, err := mongodb.NewFilterFrom(parsed, mongodb.WithCompanyID(companyID))
+//
+// The base filter is copied, so it is never modified. Unlike [NewFilter], an option that sets a key the base filter already
+// constrains at the top level or inside of $and results in [ErrFilterConflict], so that e.g. a companyID of the base
+// can not silently widen or replace the scoping. [AllowOverride] lets the options replace the conditions for the given keys instead.
+//
+// A base filter containing $where, $function or $accumulator at any depth results in [ErrForbiddenOperator], unless allowed with [AllowOperators].
+func NewFilterFrom(base primitive.M, opts ...FilterOption) (primitive.M, error) {
+	overrides := map[string]bool{}
+	allowed := map[string]bool{}
+	var filterOpts []FilterOption
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case allowOverride:
+			for _, key := range o {
+				overrides[key] = true
+			}
+		case allowOperators:
+			for _, op := range o {
+				allowed[op] = true
+			}
+		default:
+			filterOpts = append(filterOpts, opt)
+		}
+	}
+
+	op, err := findOperator(base, func(op string) bool { return forbiddenOperators[op] && !allowed[op] })
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.NewFilterFrom", err)
+	}
+	if op != "" {
+		return nil, fmt.Errorf("%v: %w: %v", "mongodb.NewFilterFrom", ErrForbiddenOperator, op)
+	}
+
+	res := copyM(base)
+	additions := NewFilter(filterOpts...)
+
+	keys := make([]string, 0, len(additions))
+	for key := range additions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if key == "$and" {
+			continue
+		}
+		if !constrains(res, key) {
+			continue
+		}
+		if !overrides[key] {
+			return nil, fmt.Errorf("%v: %w: %v", "mongodb.NewFilterFrom", ErrFilterConflict, key)
+		}
+		removeCondition(res, key)
+	}
+
+	for _, key := range keys {
+		value := additions[key]
+		if key == "$and" {
+			res["$and"] = append(asA(res["$and"]), asA(value)...)
+			continue
+		}
+		res[key] = value
+	}
+
+	return res, nil
+}
+
+// constrains reports whether filter has a condition for key at the top level or inside of $and.
+func constrains(filter primitive.M, key string) bool {
+	if _, ok := filter[key]; ok {
+		return true
+	}
+	for _, part := range asA(filter["$and"]) {
+		if doc, ok := asM(part); ok && constrains(doc, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeCondition removes the conditions for key from filter, including those inside of $and.
+// Parts of $and that become empty are removed, as is an empty $and.
+func removeCondition(filter primitive.M, key string) {
+	delete(filter, key)
+
+	and, ok := filter["$and"]
+	if !ok {
+		return
+	}
+	var parts bson.A
+	for _, part := range asA(and) {
+		if doc, ok := asM(part); ok {
+			removeCondition(doc, key)
+			if len(doc) == 0 {
+				continue
+			}
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		delete(filter, "$and")
+		return
+	}
+	filter["$and"] = parts
+}
@@ -0,0 +1,127 @@
+package mongodb_test
+
+import (
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewFilterFrom(t *testing.T) {
+	companyID := primitive.NewObjectID()
+	base := bson.M{"status": "open", "tags": bson.M{"$in": bson.A{"a", "b"}}, "$and": bson.A{bson.M{"age": bson.M{"$gte": 18}}}}
+
+	filter, err := mongodb.NewFilterFrom(base, mongodb.WithCompanyID(companyID))
+	assert.NoError(t, err)
+	assert.Equal(t, bson.M{
+		"status":    "open",
+		"tags":      bson.M{"$in": bson.A{"a", "b"}},
+		"$and":      bson.A{bson.M{"age": bson.M{"$gte": 18}}},
+		"companyID": companyID,
+	}, filter)
+
+	// The result does not share documents with the base.
+	filter["tags"].(bson.M)["$in"] = bson.A{"c"}
+	filter["$and"].(bson.A)[0].(bson.M)["age"] = 0
+	assert.Equal(t, bson.M{"status": "open", "tags": bson.M{"$in": bson.A{"a", "b"}}, "$and": bson.A{bson.M{"age": bson.M{"$gte": 18}}}}, base)
+}
+
+func TestNewFilterFromConflicts(t *testing.T) {
+	companyID := primitive.NewObjectID()
+	other := primitive.NewObjectID()
+
+	for _, tt := range []struct {
+		name string
+		base primitive.M
+	}{
+		{"top level", bson.M{"companyID": other}},
+		{"inside $and", bson.M{"$and": bson.A{bson.M{"status": "open"}, bson.M{"companyID": other}}}},
+		{"inside nested $and", bson.M{"$and": []bson.M{{"$and": bson.A{bson.M{"companyID": other}}}}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			before := copyFilter(tt.base)
+
+			_, err := mongodb.NewFilterFrom(tt.base, mongodb.WithCompanyID(companyID))
+			assert.ErrorIs(t, err, mongodb.ErrFilterConflict)
+
+			filter, err := mongodb.NewFilterFrom(tt.base, mongodb.WithCompanyID(companyID), mongodb.AllowOverride("companyID"))
+			assert.NoError(t, err)
+			assert.Equal(t, companyID, filter["companyID"])
+			_, found := mongodb.NewFilterFrom(filter, mongodb.WithCompanyID(companyID))
+			assert.ErrorIs(t, found, mongodb.ErrFilterConflict)
+
+			assert.Equal(t, before, copyFilter(tt.base))
+		})
+	}
+}
+
+func TestNewFilterFromOverrideRemovesOnlyTheKey(t *testing.T) {
+	companyID := primitive.NewObjectID()
+	base := bson.M{"$and": bson.A{bson.M{"status": "open", "companyID": 1}, bson.M{"companyID": 2}}}
+
+	filter, err := mongodb.NewFilterFrom(base, mongodb.WithCompanyID(companyID), mongodb.AllowOverride("companyID"))
+	assert.NoError(t, err)
+	assert.Equal(t, bson.M{"$and": bson.A{bson.M{"status": "open"}}, "companyID": companyID}, filter)
+}
+
+func TestNewFilterFromForbiddenOperators(t *testing.T) {
+	for _, base := range []primitive.M{
+		{"$where": "this.a == 1"},
+		{"$or": bson.A{bson.M{"a": 1}, bson.M{"$where": "sleep(1000)"}}},
+		{"$expr": bson.M{"$function": bson.M{"body": "function() {}", "args": bson.A{}, "lang": "js"}}},
+		{"$or": bson.A{bson.D{{Key: "$where", Value: "sleep(1000)"}}}},
+		{"$and": []bson.D{{{Key: "a", Value: 1}}, {{Key: "$where", Value: "sleep(1000)"}}}},
+		{"$and": []bson.M{{"$where": "sleep(1000)"}}},
+		{"x": bson.D{{Key: "$function", Value: bson.M{"body": "function() {}"}}}},
+		{"x": bson.E{Key: "$where", Value: "sleep(1000)"}},
+		{"x": []bson.E{{Key: "$where", Value: "sleep(1000)"}}},
+		{"x": map[string]string{"$where": "sleep(1000)"}},
+		{"x": map[string][]bson.D{"$in": {{{Key: "$where", Value: "sleep(1000)"}}}}},
+		{"x": []interface{}{&bson.M{"$where": "sleep(1000)"}}},
+		{"x": [1]bson.M{{"$where": "sleep(1000)"}}},
+		{"x": mustMarshal(bson.M{"$where": "sleep(1000)"})},
+		{"x": bson.RawValue{Type: bson.TypeEmbeddedDocument, Value: mustMarshal(bson.M{"y": bson.A{bson.M{"$where": "1"}}})}},
+	} {
+		_, err := mongodb.NewFilterFrom(base)
+		assert.ErrorIs(t, err, mongodb.ErrForbiddenOperator, base)
+	}
+
+	// Values that can not be checked are rejected.
+	_, err := mongodb.NewFilterFrom(bson.M{"x": struct {
+		Where string `bson:"$where"`
+	}{"sleep(1000)"}})
+	assert.ErrorIs(t, err, mongodb.ErrUnsafeFilter)
+	_, err = mongodb.NewFilterFrom(bson.M{"x": bson.D{{Key: "a", Value: 1}, {Key: "a", Value: bson.M{"$where": "1"}}}})
+	assert.ErrorIs(t, err, mongodb.ErrUnsafeFilter, "duplicate keys")
+
+	filter, err := mongodb.NewFilterFrom(bson.M{"a": []string{"x"}, "b": map[string]int{"$gt": 1}, "c": primitive.NewObjectID()})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"x"}, filter["a"], "the base is not normalized")
+
+	filter, err = mongodb.NewFilterFrom(bson.M{"$where": "this.a == 1"}, mongodb.AllowOperators("$where"))
+	assert.NoError(t, err)
+	assert.Equal(t, bson.M{"$where": "this.a == 1"}, filter)
+}
+
+// copyFilter copies a filter by value, so that modifications of the original can be detected.
+func copyFilter(filter primitive.M) primitive.M {
+	data, err := bson.Marshal(filter)
+	if err != nil {
+		panic(err)
+	}
+	var res primitive.M
+	if err := bson.Unmarshal(data, &res); err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func mustMarshal(doc interface{}) bson.Raw {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
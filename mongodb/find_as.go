@@ -0,0 +1,142 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	CursorFinder interface {
+		// Finds all Documents that match the given filter, and returns the cursor instead of decoding them.
+		// The caller has to close the cursor.
+		//
+		// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.Find]
+		FindCursor(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (*mongo.Cursor, error)
+	}
+)
+
+// ErrEmptyProjection is returned by [AutoProject] for types without stored fields,
+// since MongoDB treats an empty projection as "all fields".
+var ErrEmptyProjection = errors.New("mongodb: projection has no fields")
+
+// Finds all Documents that match the given filter, and returns the cursor instead of decoding them.
+// The caller has to close the cursor.
+//
+// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.Find]
+func (r *Repository[T]) FindCursor(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (_ *mongo.Cursor, err error) {
+	ctx, finish, err := r.begin(ctx, "FindCursor", filter)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = finish(err) }()
+
+	return r.CollectionFor(ctx).Find(ctx, filter, r.findOptions(ctx, opts)...)
+}
+
+// FindManyAs finds all documents that match the given filter, and decodes them into the read model R
+// instead of the document type of the repository.
+//
+//	summaries, err := mongodb.FindManyAs[UserSummary](ctx, repo, filter, mongodb.MustAutoProject[UserSummary]())
+func FindManyAs[R any](ctx context.Context, r CursorFinder, filter bson.M, opts ...*options.FindOptions) ([]R, error) {
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	cur, err := r.FindCursor(ctx, filter, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.FindManyAs", err)
+	}
+
+	var res []R
+	err = cur.All(ctx, &res)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.FindManyAs", err)
+	}
+
+	return res, nil
+}
+
+// AutoProject returns find options with a projection of exactly the stored fields of R.
+//
+// Fields of nested structs are included by their dotted path, e.g. "address.city", so that the other fields
+// of the nested document are not transferred. Slices, maps and dates are included as a whole.
+// The _id is always included, unless R declares its MongoID field with the tag `bson:"-"`.
+//
+// An error wrapping [ErrEmptyProjection] is returned if R has no stored fields besides _id.
+func AutoProject[R any]() (*options.FindOptions, error) {
+	projection, err := projectionOf(documentType[R]())
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.AutoProject", err)
+	}
+
+	return options.Find().SetProjection(projection), nil
+}
+
+// MustAutoProject is like [AutoProject], but panics if R has no stored fields.
+func MustAutoProject[R any]() *options.FindOptions {
+	opts, err := AutoProject[R]()
+	if err != nil {
+		panic(err)
+	}
+	return opts
+}
+
+func projectionOf(t reflect.Type) (bson.D, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %v is not a struct", ErrEmptyProjection, t)
+	}
+
+	fields, err := structFields(t)
+	if err != nil {
+		return nil, err
+	}
+
+	projection := bson.D{}
+	if !skipsMongoID(t) {
+		projection = append(projection, bson.E{Key: "_id", Value: 1})
+	}
+	for i, field := range fields {
+		if field.Path == "_id" {
+			continue
+		}
+		// Nested structs are followed by their fields, which are included instead.
+		if i+1 < len(fields) && len(fields[i+1].GoPath) > len(field.GoPath) {
+			continue
+		}
+		projection = append(projection, bson.E{Key: field.Path, Value: 1})
+	}
+
+	if len(projection) == 0 || len(projection) == 1 && projection[0].Key == "_id" {
+		return nil, fmt.Errorf("%w: %v has no stored fields", ErrEmptyProjection, t)
+	}
+
+	return projection, nil
+}
+
+// skipsMongoID reports whether t, or one of its inline structs, has a MongoID field tagged with "-".
+func skipsMongoID(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tags, err := bsoncodec.DefaultStructTagParser.ParseStructTags(sf)
+		if err != nil {
+			continue
+		}
+		if sf.Name == "MongoID" && tags.Skip {
+			return true
+		}
+		if sf.Anonymous && tags.Inline {
+			if ft := indirectType(sf.Type); ft.Kind() == reflect.Struct && skipsMongoID(ft) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,129 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	AccountSummary struct {
+		MongoID  primitive.ObjectID `bson:"_id"`
+		Name     string             `bson:"name"`
+		Settings struct {
+			Flags struct {
+				Beta bool `bson:"beta"`
+			} `bson:"flags"`
+		} `bson:"settings"`
+		Items    []*AccountItem `bson:"items"`
+		Internal string         `bson:"-"`
+	}
+
+	AccountName struct {
+		MongoID primitive.ObjectID `bson:"-"`
+		Name    string             `bson:"name"`
+	}
+
+	UserName struct {
+		Name string `bson:"name"`
+	}
+
+	MemberProfile struct {
+		Name   string              `bson:"name"`
+		Avatar primitive.Binary    `bson:"avatar"`
+		Seen   primitive.Timestamp `bson:"seen"`
+	}
+
+	MemberName struct {
+		AccountName `bson:",inline,omitempty"`
+	}
+)
+
+func TestAutoProject(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts func() (interface{}, error)
+		want bson.D
+	}{
+		{"nested", projectionOf[AccountSummary], bson.D{
+			{Key: "_id", Value: 1}, {Key: "name", Value: 1}, {Key: "settings.flags.beta", Value: 1}, {Key: "items", Value: 1},
+		}},
+		{"skipped id", projectionOf[*AccountName], bson.D{{Key: "name", Value: 1}}},
+		{"without id", projectionOf[UserName], bson.D{{Key: "_id", Value: 1}, {Key: "name", Value: 1}}},
+		{"driver types", projectionOf[MemberProfile], bson.D{
+			{Key: "_id", Value: 1}, {Key: "name", Value: 1}, {Key: "avatar", Value: 1}, {Key: "seen", Value: 1},
+		}},
+		{"skipped id in inline struct", projectionOf[MemberName], bson.D{{Key: "name", Value: 1}}},
+		{"inline base model", projectionOf[User], bson.D{
+			{Key: "_id", Value: 1}, {Key: "createdAt", Value: 1}, {Key: "updatedAt", Value: 1}, {Key: "name", Value: 1}, {Key: "email", Value: 1},
+		}},
+	} {
+		projection, err := tt.opts()
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, projection, tt.name)
+	}
+}
+
+func projectionOf[R any]() (interface{}, error) {
+	opts, err := mongodb.AutoProject[R]()
+	if err != nil {
+		return nil, err
+	}
+	return opts.Projection, nil
+}
+
+func TestAutoProjectEmpty(t *testing.T) {
+	type onlyID struct {
+		MongoID primitive.ObjectID `bson:"_id"`
+	}
+	type unexported struct {
+		name string
+	}
+
+	for _, fn := range []func() (interface{}, error){projectionOf[onlyID], projectionOf[unexported], projectionOf[struct{}], projectionOf[string]} {
+		_, err := fn()
+		assert.ErrorIs(t, err, mongodb.ErrEmptyProjection)
+	}
+
+	assert.Panics(t, func() { mongodb.MustAutoProject[onlyID]() })
+}
+
+func TestFindManyAs(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_find_as")).(*mongodb.Repository[*User])
+
+	users, err := repo.InsertMany(ctx, []*User{
+		{Name: "alice", Email: "alice@example.com"},
+		{Name: "bob", Email: "bob@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Error inserting users: %v", err)
+	}
+
+	type userRef struct {
+		MongoID primitive.ObjectID `bson:"_id"`
+		Name    string             `bson:"name"`
+	}
+	refs, err := mongodb.FindManyAs[userRef](ctx, repo, bson.M{}, mongodb.MustAutoProject[userRef]().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		t.Fatalf("Error finding users: %v", err)
+	}
+	assert.Equal(t, []userRef{{users[0].GetMongoID(), "alice"}, {users[1].GetMongoID(), "bob"}}, refs)
+
+	// The projection leaves out the other fields, so decoding them into the full model leaves them empty.
+	cur, err := repo.FindCursor(ctx, bson.M{"name": "alice"}, mongodb.MustAutoProject[userRef]())
+	if err != nil {
+		t.Fatalf("Error finding alice: %v", err)
+	}
+	var full []*User
+	assert.NoError(t, cur.All(ctx, &full))
+	if assert.Len(t, full, 1) {
+		assert.Equal(t, "alice", full[0].Name)
+		assert.Empty(t, full[0].Email)
+		assert.True(t, full[0].GetCreatedAt().IsZero())
+	}
+}
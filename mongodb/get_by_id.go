@@ -0,0 +1,52 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	GetByID[T Document[T]] interface {
+		// Finds the document with the given _id, optionally with only the given fields and the _id.
+		// If there is none, an error wrapping [ErrNotFound] is returned.
+		GetByID(ctx context.Context, id primitive.ObjectID, projection ...string) (T, error)
+	}
+)
+
+var (
+	// idIndexHint forces the default _id index, so the server does not need to plan the query.
+	idIndexHint = bson.D{{Key: "_id", Value: 1}}
+	// getByIDOptions are shared by all GetByID calls without a projection. The driver does not modify them.
+	getByIDOptions = []*options.FindOneOptions{options.FindOne().SetHint(idIndexHint)}
+)
+
+// Finds the document with the given _id, optionally with only the given fields and the _id.
+// If there is none, an error wrapping [ErrNotFound] is returned, which also matches mongo.ErrNoDocuments.
+//
+// It is the cheapest way to read a single document: the filter is a single-element bson.D instead of a map,
+// and the _id index is passed as hint. Otherwise it behaves like [Repository.FindOne] with [MongoIDFilter].
+//
+// CAUTION: A query should almost always contain the companyID, use FindOne with a filter including it for documents of a tenant.
+func (r *Repository[T]) GetByID(ctx context.Context, id primitive.ObjectID, projection ...string) (T, error) {
+	opts := getByIDOptions
+	if len(projection) > 0 {
+		fields := make(bson.D, len(projection))
+		for i, field := range projection {
+			fields[i] = bson.E{Key: field, Value: 1}
+		}
+		opts = []*options.FindOneOptions{options.FindOne().SetHint(idIndexHint).SetProjection(fields)}
+	}
+
+	res, err := r.findOne(ctx, "GetByID", bson.D{{Key: "_id", Value: id}}, opts)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return res, fmt.Errorf("%v: %v: %w: %w", "mongodb.Repository.GetByID", id.Hex(), ErrNotFound, err)
+	}
+
+	return res, err
+}
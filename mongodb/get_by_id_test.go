@@ -0,0 +1,42 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestGetByID(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_get_by_id")).(*mongodb.Repository[*User])
+
+	inserted, err := repo.InsertOne(ctx, &User{Name: "alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("Error inserting user: %v", err)
+	}
+
+	found, err := repo.GetByID(ctx, inserted.GetMongoID())
+	assert.NoError(t, err)
+	expected, err := repo.FindOne(ctx, mongodb.MongoIDFilter(inserted.GetMongoID()))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, found)
+
+	projected, err := repo.GetByID(ctx, inserted.GetMongoID(), "name")
+	assert.NoError(t, err)
+	assert.Equal(t, inserted.GetMongoID(), projected.GetMongoID())
+	assert.Equal(t, "alice", projected.Name)
+	assert.Empty(t, projected.Email)
+	assert.True(t, projected.GetCreatedAt().IsZero())
+
+	missing, err := repo.GetByID(ctx, primitive.NewObjectID())
+	assert.ErrorIs(t, err, mongodb.ErrNotFound)
+	assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	assert.Nil(t, missing)
+
+	_, err = repo.FindOne(ctx, mongodb.MongoIDFilter(primitive.NewObjectID()))
+	assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+}
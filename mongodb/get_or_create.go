@@ -0,0 +1,138 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	FindOneOrCreate[T Document[T]] interface {
+		// Returns the document matching filter, or atomically inserts defaultDoc if there is none.
+		// The returned bool reports whether the document was created.
+		FindOneOrCreate(ctx context.Context, filter bson.M, defaultDoc T, opts ...*options.FindOneAndUpdateOptions) (T, bool, error)
+	}
+)
+
+// Returns the document matching filter, or atomically inserts defaultDoc if there is none.
+// The returned bool reports whether the document was created.
+//
+// defaultDoc is initialized with InitDocument and written with $setOnInsert in a single upserting FindOneAndUpdate,
+// so existing documents are never modified. The equality fields of filter take precedence over the fields of
+// defaultDoc, so that the created document always matches filter, and later calls find it instead of creating another one. The fields of filter should be covered by a unique index:
+// without one, concurrent calls can create duplicates; with one, the call that loses the race gets a duplicate key
+// error, which is handled by retrying, so that it returns the document created by the winner.
+// The result is decoded like the one of FindOne, so strict decoding and result transforms apply to it.
+func (r *Repository[T]) FindOneOrCreate(ctx context.Context, filter bson.M, defaultDoc T, opts ...*options.FindOneAndUpdateOptions) (res T, created bool, err error) {
+	ctx, finish, err := r.begin(ctx, "FindOneOrCreate", filter)
+	if err != nil {
+		return res, false, err
+	}
+	defer func() { err = finish(err) }()
+
+	defaultDoc.InitDocument()
+	insert, err := insertDocument(r.Registry(), defaultDoc, filter)
+	if err != nil {
+		return res, false, fmt.Errorf("%v: %w", "mongodb.Repository.FindOneOrCreate", err)
+	}
+	defaultID, err := documentID(r.Registry(), insert)
+	if err != nil {
+		return res, false, fmt.Errorf("%v: %w", "mongodb.Repository.FindOneOrCreate", err)
+	}
+
+	opts = append(opts, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After))
+	update := bson.M{"$setOnInsert": insert}
+
+	res, keep, err := r.decodeSingleResult(ctx, r.CollectionFor(ctx).FindOneAndUpdate(ctx, filter, update, opts...))
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent call inserted the document, it is found now.
+		res, keep, err = r.decodeSingleResult(ctx, r.CollectionFor(ctx).FindOneAndUpdate(ctx, filter, update, opts...))
+	}
+	if err != nil {
+		return res, false, fmt.Errorf("%v: %w", "mongodb.Repository.FindOneOrCreate", r.mapUniqueViolation(err))
+	}
+
+	id, err := documentID(r.Registry(), res)
+	if err != nil {
+		return res, false, fmt.Errorf("%v: %w", "mongodb.Repository.FindOneOrCreate", err)
+	}
+	created = id.Equal(defaultID)
+
+	if !keep {
+		// Like FindOne, a document removed by a result transform is not found.
+		var zero T
+		return zero, created, fmt.Errorf("%v: %w", "mongodb.Repository.FindOneOrCreate", mongo.ErrNoDocuments)
+	}
+
+	return res, created, nil
+}
+
+// insertDocument returns doc as bson.D, with the values of the equality fields of filter set at their paths.
+// Otherwise $setOnInsert would overwrite the values the upsert takes from filter, and the created document
+// would not match filter.
+func insertDocument(registry *bsoncodec.Registry, doc interface{}, filter bson.M) (bson.D, error) {
+	data, err := bson.MarshalWithRegistry(registry, doc)
+	if err != nil {
+		return nil, err
+	}
+	var d bson.D
+	if err := bson.UnmarshalWithRegistry(registry, data, &d); err != nil {
+		return nil, err
+	}
+
+	for key, value := range filter {
+		if strings.HasPrefix(key, "$") || !isEqualityValue(value) {
+			continue
+		}
+		d = setPath(d, strings.Split(key, "."), value)
+	}
+	return d, nil
+}
+
+// isEqualityValue reports whether a filter matches value by equality, i.e. value is no regex and no document of operators.
+func isEqualityValue(value interface{}) bool {
+	if _, ok := value.(primitive.Regex); ok {
+		return false
+	}
+	normalized, err := normalizeValue(value)
+	if err != nil {
+		return true
+	}
+	m, ok := normalized.(primitive.M)
+	if !ok {
+		return true
+	}
+	for key := range m {
+		if strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+// setPath sets value at path in d, creating the documents on the path that do not exist yet.
+func setPath(d bson.D, path []string, value interface{}) bson.D {
+	for i := range d {
+		if d[i].Key != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			d[i].Value = value
+		} else {
+			sub, _ := d[i].Value.(bson.D)
+			d[i].Value = setPath(sub, path[1:], value)
+		}
+		return d
+	}
+
+	if len(path) == 1 {
+		return append(d, bson.E{Key: path[0], Value: value})
+	}
+	return append(d, bson.E{Key: path[0], Value: setPath(nil, path[1:], value)})
+}
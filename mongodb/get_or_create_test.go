@@ -0,0 +1,85 @@
+package mongodb_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestFindOneOrCreate(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_get_or_create")).(*mongodb.Repository[*User])
+
+	user, created, err := repo.FindOneOrCreate(ctx, bson.M{"name": "settings"}, &User{Name: "settings", Email: "default@example.com"})
+	if err != nil {
+		t.Fatalf("Error on get-or-create: %v", err)
+	}
+	assert.True(t, created)
+	assert.Equal(t, "default@example.com", user.Email)
+	assert.False(t, user.CreatedAt.IsZero())
+
+	// An existing document is returned unchanged.
+	again, created, err := repo.FindOneOrCreate(ctx, bson.M{"name": "settings"}, &User{Name: "settings", Email: "other@example.com"})
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, user.MongoID, again.MongoID)
+	assert.Equal(t, "default@example.com", again.Email)
+}
+
+func TestFindOneOrCreateConcurrent(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_get_or_create_concurrent")).(*mongodb.Repository[*User])
+
+	if _, err := repo.CreateIndex(ctx, bson.D{{Key: "name", Value: 1}}, options.Index().SetUnique(true)); err != nil {
+		t.Fatalf("Error on creating index: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var createdCount int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, created, err := repo.FindOneOrCreate(ctx, bson.M{"name": "settings"}, &User{Name: "settings"})
+			assert.NoError(t, err)
+			if created {
+				atomic.AddInt32(&createdCount, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), createdCount)
+	count, err := repo.CountDocuments(ctx, bson.M{"name": "settings"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestFindOneOrCreateFilterTakesPrecedence(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_get_or_create_conflict")).(*mongodb.Repository[*User])
+
+	// defaultDoc disagrees with the filter, the created document still has to match it.
+	user, created, err := repo.FindOneOrCreate(ctx, bson.M{"name": "settings"}, &User{Name: "other", Email: "default@example.com"})
+	if err != nil {
+		t.Fatalf("Error on get-or-create: %v", err)
+	}
+	assert.True(t, created)
+	assert.Equal(t, "settings", user.Name)
+	assert.Equal(t, "default@example.com", user.Email)
+
+	again, created, err := repo.FindOneOrCreate(ctx, bson.M{"name": "settings"}, &User{Name: "other"})
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, user.MongoID, again.MongoID)
+
+	count, err := repo.CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
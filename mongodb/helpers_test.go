@@ -0,0 +1,28 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// testCollection connects to the local test database and drops the collection after the test.
+func testCollection(t *testing.T, name string) *mongo.Collection {
+	t.Helper()
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+
+	col := client.Database("testdb").Collection(name)
+	t.Cleanup(func() {
+		col.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+
+	return col
+}
@@ -0,0 +1,94 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type (
+	// Operation describes a single repository call for an [OperationHook].
+	Operation struct {
+		// Name is the name of the repository method, e.g. "FindOne".
+		Name string
+		// Collection is the name of the collection the operation runs on.
+		Collection string
+		// Filter is the filter of the operation, or nil for operations that have none.
+		Filter interface{}
+		// Started is the time at which the operation started.
+		Started time.Time
+	}
+
+	// OperationHook is called around every operation of a [Repository].
+	//
+	// It can be used to guard operations, e.g. to reject them during shutdown, or to observe them.
+	OperationHook interface {
+		// Before is called before the operation runs. It can replace the context of the operation,
+		// or reject it by returning an error, in which case After is not called for this hook.
+		Before(ctx context.Context, op *Operation) (context.Context, error)
+		// After is called once the operation finished, with the context returned by Before and the error of the operation.
+		// The returned error replaces the error of the operation.
+		After(ctx context.Context, op *Operation, err error) error
+	}
+)
+
+// begin applies the absolute timeout, see [WithAbsoluteTimeout], runs the Before hooks for an operation
+// and starts spending its query budget, see [WithQueryBudget].
+// The returned function must be called with the result of the operation, and returns the error that should be passed to the caller.
+func (r *Repository[T]) begin(ctx context.Context, name string, filter interface{}) (context.Context, func(error) error, error) {
+	if r.session != nil {
+		ctx = mongo.NewSessionContext(ctx, r.session)
+	}
+	ctx, timedOut := r.withAbsoluteTimeout(ctx, name)
+
+	if len(r.hooks) == 0 {
+		ctx, spent, err := SpendQueryBudget(ctx)
+		if err != nil {
+			return ctx, nil, timedOut(err)
+		}
+		return ctx, func(err error) error {
+			return timedOut(spent(err))
+		}, nil
+	}
+
+	op := &Operation{
+		Name:       name,
+		Collection: r.db.Name(),
+		Filter:     filter,
+		Started:    time.Now(),
+	}
+
+	ctxs := make([]context.Context, 0, len(r.hooks))
+	for _, hook := range r.hooks {
+		hookCtx, err := hook.Before(ctx, op)
+		if err != nil {
+			return ctx, nil, finishHooks(r.hooks[:len(ctxs)], ctxs, op, timedOut(err))
+		}
+		ctxs = append(ctxs, hookCtx)
+		ctx = hookCtx
+	}
+
+	// The budget is spent last, so that its deadline only covers the operation itself.
+	ctx, spent, err := SpendQueryBudget(ctx)
+	if err != nil {
+		return ctx, nil, finishHooks(r.hooks, ctxs, op, timedOut(err))
+	}
+
+	return ctx, func(err error) error {
+		return finishHooks(r.hooks, ctxs, op, timedOut(spent(err)))
+	}, nil
+}
+
+// finishHooks calls the After hooks in reverse order, each with the context its Before hook returned.
+func finishHooks(hooks []OperationHook, ctxs []context.Context, op *Operation, err error) error {
+	for i := len(hooks) - 1; i >= 0; i-- {
+		err = hooks[i].After(ctxs[i], op, err)
+	}
+
+	return err
+}
+
+func noopFinish(err error) error {
+	return err
+}
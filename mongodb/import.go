@@ -0,0 +1,236 @@
+package mongodb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultImportBatchSize = 500
+	// Import lines can contain large documents, the default limit of bufio.Scanner is 64KB.
+	maxImportLineSize = 16 * 1024 * 1024
+)
+
+type (
+	// ImportOption configures [ImportNDJSON].
+	ImportOption interface {
+		apply(*importOption)
+	}
+
+	// ImportReport summarizes the result of [ImportNDJSON].
+	ImportReport struct {
+		// Inserted is the number of documents that were newly created.
+		Inserted int
+		// Replaced is the number of existing documents that were replaced in upsert mode.
+		Replaced int
+		// Skipped contains the lines whose _id already existed in insert mode.
+		Skipped []ImportIssue
+		// Failed contains the lines that could not be decoded or written.
+		Failed []ImportIssue
+	}
+
+	// ImportIssue describes a single line that was not imported.
+	ImportIssue struct {
+		// Line is the 1-based line number in the input.
+		Line int
+		// Reason describes why the line was not imported.
+		Reason string
+	}
+)
+
+type (
+	importOption struct {
+		batchSize          int
+		preserveTimestamps bool
+		upsert             bool
+	}
+)
+
+type importBatchSizeOption int
+
+func (value importBatchSizeOption) apply(o *importOption) {
+	if value > 0 {
+		o.batchSize = int(value)
+	}
+}
+
+// WithImportBatchSize sets the number of documents written per batch, the default is 500.
+func WithImportBatchSize(size int) ImportOption {
+	return importBatchSizeOption(size)
+}
+
+type preserveTimestampsOption bool
+
+func (value preserveTimestampsOption) apply(o *importOption) {
+	o.preserveTimestamps = bool(value)
+}
+
+// WithPreserveTimestamps keeps the createdAt and updatedAt of documents without an _id,
+// instead of setting them to the current time. Missing timestamps are still set.
+func WithPreserveTimestamps() ImportOption {
+	return preserveTimestampsOption(true)
+}
+
+type upsertOption bool
+
+func (value upsertOption) apply(o *importOption) {
+	o.upsert = bool(value)
+}
+
+// Upsert replaces documents with an existing _id instead of skipping them.
+func Upsert() ImportOption {
+	return upsertOption(true)
+}
+
+// String returns a short summary of the report.
+func (r ImportReport) String() string {
+	return fmt.Sprintf("inserted: %d, replaced: %d, skipped: %d, failed: %d", r.Inserted, r.Replaced, len(r.Skipped), len(r.Failed))
+}
+
+// ImportNDJSON reads one Extended JSON document per line from rd, decodes it into T and writes it to r in batches.
+//
+// Documents without an _id are initialized with [Document.InitDocument], documents with an _id are stored as they are.
+// In insert mode, documents whose _id already exists are skipped; with [Upsert] they are replaced.
+// Lines that can not be decoded or written are reported in [ImportReport.Failed] and do not stop the import,
+// the returned error is only set when reading the input or talking to the database failed.
+//
+// The batches are written with BulkWrite instead of InsertMany, so that preserved timestamps are not overwritten
+// and every failure can be attributed to its line.
+func ImportNDJSON[T Document[T]](ctx context.Context, r RepositoryI[T], rd io.Reader, opts ...ImportOption) (ImportReport, error) {
+	ops := &importOption{batchSize: defaultImportBatchSize}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+
+	report := ImportReport{}
+	models := make([]mongo.WriteModel, 0, ops.batchSize)
+	lines := make([]int, 0, ops.batchSize)
+
+	flush := func() error {
+		if len(models) == 0 {
+			return nil
+		}
+		err := writeImportBatch(ctx, r, models, lines, ops.upsert, &report)
+		models, lines = models[:0], lines[:0]
+		return err
+	}
+
+	scanner := bufio.NewScanner(rd)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineSize)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		doc, id, err := decodeImportLine[T](registryOf(r), text, ops.preserveTimestamps)
+		if err != nil {
+			report.Failed = append(report.Failed, ImportIssue{Line: line, Reason: err.Error()})
+			continue
+		}
+
+		if ops.upsert {
+			models = append(models, mongo.NewReplaceOneModel().SetFilter(bson.M{"_id": id}).SetReplacement(doc).SetUpsert(true))
+		} else {
+			models = append(models, mongo.NewInsertOneModel().SetDocument(doc))
+		}
+		lines = append(lines, line)
+
+		if len(models) >= ops.batchSize {
+			if err := flush(); err != nil {
+				return report, fmt.Errorf("%v: %w", "mongodb.ImportNDJSON", err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return report, fmt.Errorf("%v: %w", "mongodb.ImportNDJSON", err)
+	}
+
+	if err := flush(); err != nil {
+		return report, fmt.Errorf("%v: %w", "mongodb.ImportNDJSON", err)
+	}
+	return report, nil
+}
+
+// decodeImportLine decodes a single line and initializes the document if it has no _id.
+// It returns the document and its _id.
+func decodeImportLine[T Document[T]](registry *bsoncodec.Registry, text []byte, preserveTimestamps bool) (T, interface{}, error) {
+	doc := newTValue[T]()
+
+	var raw bson.Raw
+	if err := bson.UnmarshalExtJSON(text, false, &raw); err != nil {
+		return doc, nil, err
+	}
+	if err := bson.UnmarshalWithRegistry(registry, raw, doc); err != nil {
+		return doc, nil, err
+	}
+
+	if id, err := raw.LookupErr("_id"); err == nil {
+		return doc, id, nil
+	}
+
+	if !preserveTimestamps {
+		doc.InitDocument()
+	} else {
+		doc.InitMongoID()
+		timestamp := now()
+		if _, err := raw.LookupErr("createdAt"); err != nil {
+			doc.SetCreatedAt(timestamp)
+		}
+		if _, err := raw.LookupErr("updatedAt"); err != nil {
+			doc.SetUpdatedAt(timestamp)
+		}
+	}
+
+	// The generated _id is needed for the upsert filter, read it back from the document.
+	id, err := documentID(registry, doc)
+	return doc, id, err
+}
+
+// documentID returns the _id of doc as it is stored in the database.
+func documentID(registry *bsoncodec.Registry, doc interface{}) (bson.RawValue, error) {
+	data, err := bson.MarshalWithRegistry(registry, doc)
+	if err != nil {
+		return bson.RawValue{}, err
+	}
+	id, err := bson.Raw(data).LookupErr("_id")
+	if err != nil {
+		return bson.RawValue{}, fmt.Errorf("document has no _id: %w", err)
+	}
+	return id, nil
+}
+
+func writeImportBatch[T Document[T]](ctx context.Context, r RepositoryI[T], models []mongo.WriteModel, lines []int, upsert bool, report *ImportReport) error {
+	res, err := r.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+
+	var bwe mongo.BulkWriteException
+	if err != nil && (!errors.As(err, &bwe) || bwe.WriteConcernError != nil) {
+		return err
+	}
+
+	if res != nil {
+		report.Inserted += int(res.InsertedCount + res.UpsertedCount)
+		report.Replaced += int(res.MatchedCount)
+	}
+
+	for _, we := range bwe.WriteErrors {
+		issue := ImportIssue{Line: lines[we.Index], Reason: we.Message}
+		if !upsert && we.Code == int(codeDuplicateKey) {
+			report.Skipped = append(report.Skipped, issue)
+		} else {
+			report.Failed = append(report.Failed, issue)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package mongodb_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestImportNDJSONMalformed(t *testing.T) {
+	input := "not json\n\n{\"name\": \n"
+
+	// Nothing is written, so the repository is never used.
+	report, err := mongodb.ImportNDJSON[*User](context.Background(), nil, strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.Inserted)
+	if assert.Len(t, report.Failed, 2) {
+		assert.Equal(t, 1, report.Failed[0].Line)
+		assert.Equal(t, 3, report.Failed[1].Line)
+	}
+}
+
+func TestImportNDJSON(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_import"))
+
+	input := strings.Join([]string{
+		`{"_id": {"$oid": "65a000000000000000000001"}, "name": "Alice", "createdAt": {"$date": "2020-01-01T00:00:00Z"}}`,
+		`{"name": "Bob"}`,
+		`{"name": `,
+		`{"_id": {"$oid": "65a000000000000000000001"}, "name": "Alice again"}`,
+		`{"name": "Carol"}`,
+	}, "\n")
+
+	report, err := mongodb.ImportNDJSON[*User](ctx, repo, strings.NewReader(input), mongodb.WithImportBatchSize(2))
+	if err != nil {
+		t.Fatalf("Error on importing: %v", err)
+	}
+	assert.Equal(t, 3, report.Inserted)
+	if assert.Len(t, report.Failed, 1) {
+		assert.Equal(t, 3, report.Failed[0].Line)
+	}
+	if assert.Len(t, report.Skipped, 1) {
+		assert.Equal(t, 4, report.Skipped[0].Line)
+	}
+
+	alice, err := repo.FindOne(ctx, bson.M{"name": "Alice"})
+	if err != nil {
+		t.Fatalf("Error on finding Alice: %v", err)
+	}
+	assert.Equal(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), alice.CreatedAt.UTC())
+
+	bob, err := repo.FindOne(ctx, bson.M{"name": "Bob"})
+	if err != nil {
+		t.Fatalf("Error on finding Bob: %v", err)
+	}
+	assert.False(t, bob.MongoID.IsZero())
+	assert.WithinDuration(t, time.Now(), bob.CreatedAt, time.Minute)
+
+	// Upsert replaces the existing document.
+	report, err = mongodb.ImportNDJSON[*User](ctx, repo, strings.NewReader(input), mongodb.Upsert())
+	if err != nil {
+		t.Fatalf("Error on importing with upsert: %v", err)
+	}
+	assert.Empty(t, report.Skipped)
+	assert.Equal(t, 2, report.Replaced)
+
+	count, err := repo.CountDocuments(ctx, bson.M{"name": "Alice again"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestImportNDJSONPreserveTimestamps(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_import_timestamps"))
+
+	input := `{"name": "Alice", "createdAt": {"$date": "2020-01-01T00:00:00Z"}, "updatedAt": {"$date": "2021-01-01T00:00:00Z"}}`
+
+	_, err := mongodb.ImportNDJSON[*User](ctx, repo, strings.NewReader(input), mongodb.WithPreserveTimestamps())
+	if err != nil {
+		t.Fatalf("Error on importing: %v", err)
+	}
+
+	alice, err := repo.FindOne(ctx, bson.M{"name": "Alice"})
+	if err != nil {
+		t.Fatalf("Error on finding Alice: %v", err)
+	}
+	assert.Equal(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), alice.CreatedAt.UTC())
+	assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), alice.UpdatedAt.UTC())
+}
@@ -0,0 +1,183 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// IndexManager manages the indexes of a single collection.
+	IndexManager interface {
+		// Creates a single index and returns its name.
+		// Creating an index that already exists with identical options is a no-op,
+		// while an existing index with conflicting options results in an error wrapping [ErrIndexConflict].
+		//
+		// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#IndexView.CreateOne]
+		CreateIndex(ctx context.Context, keys bson.D, opts ...*options.IndexOptions) (string, error)
+
+		// Creates multiple indexes and returns their names, see CreateIndex.
+		//
+		// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#IndexView.CreateMany]
+		CreateIndexes(ctx context.Context, models []mongo.IndexModel) ([]string, error)
+
+		// Drops the index with the given name.
+		//
+		// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#IndexView.DropOne]
+		DropIndex(ctx context.Context, name string) error
+
+		// Lists all indexes of the collection, including the default _id index.
+		//
+		// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#IndexView.List]
+		ListIndexes(ctx context.Context) ([]IndexInfo, error)
+
+		// Changes the expireAfterSeconds of an existing TTL index with the collMod command.
+		//
+		// See [https://www.mongodb.com/docs/manual/reference/command/collMod/#change-index-properties]
+		SetIndexExpireAfter(ctx context.Context, name string, expireAfter time.Duration) error
+	}
+
+	// IndexInfo describes an existing index, as returned by the listIndexes command.
+	IndexInfo struct {
+		Name   string `bson:"name"`
+		Keys   bson.D `bson:"key"`
+		Unique bool   `bson:"unique,omitempty"`
+		Sparse bool   `bson:"sparse,omitempty"`
+		// ExpireAfterSeconds is only set for TTL indexes.
+		ExpireAfterSeconds      *int32 `bson:"expireAfterSeconds,omitempty"`
+		PartialFilterExpression bson.M `bson:"partialFilterExpression,omitempty"`
+		// Weights contains the indexed fields of a text index, whose Keys are always _fts and _ftsx.
+		Weights bson.M `bson:"weights,omitempty"`
+	}
+)
+
+// IsTTL reports whether the index is a TTL index.
+func (i IndexInfo) IsTTL() bool {
+	return i.ExpireAfterSeconds != nil
+}
+
+// Creates a single index and returns its name.
+// Creating an index that already exists with identical options is a no-op,
+// while an existing index with conflicting options results in an error wrapping [ErrIndexConflict].
+//
+// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#IndexView.CreateOne]
+func (r *Repository[T]) CreateIndex(ctx context.Context, keys bson.D, opts ...*options.IndexOptions) (_ string, err error) {
+	ctx, finish, err := r.begin(ctx, "CreateIndex", nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() { err = finish(err) }()
+
+	name, err := r.db.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: keys, Options: options.MergeIndexOptions(opts...)})
+	if err != nil {
+		return "", indexError("mongodb.Repository.CreateIndex", err)
+	}
+
+	return name, nil
+}
+
+// Creates multiple indexes and returns their names, see [Repository.CreateIndex].
+//
+// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#IndexView.CreateMany]
+func (r *Repository[T]) CreateIndexes(ctx context.Context, models []mongo.IndexModel) (_ []string, err error) {
+	if len(models) == 0 {
+		return nil, nil
+	}
+
+	ctx, finish, err := r.begin(ctx, "CreateIndexes", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = finish(err) }()
+
+	names, err := r.db.Indexes().CreateMany(ctx, models)
+	if err != nil {
+		return nil, indexError("mongodb.Repository.CreateIndexes", err)
+	}
+
+	return names, nil
+}
+
+// Drops the index with the given name.
+//
+// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#IndexView.DropOne]
+func (r *Repository[T]) DropIndex(ctx context.Context, name string) (err error) {
+	ctx, finish, err := r.begin(ctx, "DropIndex", nil)
+	if err != nil {
+		return err
+	}
+	defer func() { err = finish(err) }()
+
+	_, err = r.db.Indexes().DropOne(ctx, name)
+	if err != nil {
+		return fmt.Errorf("%v: %w", "mongodb.Repository.DropIndex", err)
+	}
+
+	return nil
+}
+
+// Lists all indexes of the collection, including the default _id index.
+// A collection that does not exist has no indexes.
+//
+// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#IndexView.List]
+func (r *Repository[T]) ListIndexes(ctx context.Context) (_ []IndexInfo, err error) {
+	ctx, finish, err := r.begin(ctx, "ListIndexes", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = finish(err) }()
+
+	cur, err := r.db.Indexes().List(ctx)
+	if err != nil {
+		if hasErrorCode(err, codeNamespaceNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%v: %w", "mongodb.Repository.ListIndexes", err)
+	}
+
+	var res []IndexInfo
+	err = cur.All(ctx, &res)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.Repository.ListIndexes", err)
+	}
+
+	return res, nil
+}
+
+// Changes the expireAfterSeconds of an existing TTL index with the collMod command.
+//
+// See [https://www.mongodb.com/docs/manual/reference/command/collMod/#change-index-properties]
+func (r *Repository[T]) SetIndexExpireAfter(ctx context.Context, name string, expireAfter time.Duration) (err error) {
+	ctx, finish, err := r.begin(ctx, "SetIndexExpireAfter", nil)
+	if err != nil {
+		return err
+	}
+	defer func() { err = finish(err) }()
+
+	cmd := bson.D{
+		{Key: "collMod", Value: r.db.Name()},
+		{Key: "index", Value: bson.D{
+			{Key: "name", Value: name},
+			{Key: "expireAfterSeconds", Value: int64(expireAfter / time.Second)},
+		}},
+	}
+
+	err = r.db.Database().RunCommand(ctx, cmd).Err()
+	if err != nil {
+		return fmt.Errorf("%v: %w", "mongodb.Repository.SetIndexExpireAfter", err)
+	}
+
+	return nil
+}
+
+func indexError(op string, err error) error {
+	if hasErrorCode(err, codeIndexOptionsConflict, codeIndexKeySpecsConflict) {
+		return fmt.Errorf("%v: %w: %v", op, ErrIndexConflict, err)
+	}
+
+	return fmt.Errorf("%v: %w", op, err)
+}
@@ -0,0 +1,210 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// The name of the default index on _id, which is ignored by [DiffIndexes].
+const defaultIndexName = "_id_"
+
+type (
+	// IndexDiff is the difference between declared and existing indexes, see [DiffIndexes].
+	IndexDiff struct {
+		// Missing are declared indexes that do not exist.
+		Missing []mongo.IndexModel
+		// Unexpected are existing indexes that are not declared.
+		Unexpected []IndexInfo
+		// Mismatched are declared indexes that exist with other keys or options.
+		Mismatched []IndexMismatch
+	}
+
+	// IndexMismatch is a declared index that exists with different keys or options.
+	IndexMismatch struct {
+		Declared mongo.IndexModel
+		Actual   IndexInfo
+		// Differences describe every differing property, e.g. "unique: declared true, actual false".
+		Differences []string
+	}
+)
+
+// IsEmpty reports whether the declared and the existing indexes match.
+func (d IndexDiff) IsEmpty() bool {
+	return len(d.Missing) == 0 && len(d.Unexpected) == 0 && len(d.Mismatched) == 0
+}
+
+// DiffIndexes compares the declared indexes with the existing indexes of the collection. Nothing is modified.
+//
+// If declared is nil, the indexes declared for T are used, see [IndexModels].
+// Indexes are matched by name, or by keys if the name differs. The default _id index is ignored.
+// Use [Reconcile] to create the missing indexes.
+func DiffIndexes[T any](ctx context.Context, r IndexManager, declared []mongo.IndexModel) (IndexDiff, error) {
+	var diff IndexDiff
+
+	if declared == nil {
+		var err error
+		declared, err = IndexModels[T]()
+		if err != nil {
+			return diff, err
+		}
+	}
+
+	existing, err := r.ListIndexes(ctx)
+	if err != nil {
+		return diff, err
+	}
+
+	matched := map[string]bool{defaultIndexName: true}
+	for _, model := range declared {
+		keys, err := indexKeysOf(model)
+		if err != nil {
+			return diff, fmt.Errorf("DiffIndexes: %w", err)
+		}
+
+		actual, ok := findIndexByName(existing, declaredIndexName(model, keys))
+		if !ok {
+			actual, ok = findIndexByKeys(existing, keys)
+		}
+		if !ok {
+			diff.Missing = append(diff.Missing, model)
+			continue
+		}
+		matched[actual.Name] = true
+
+		differences, err := indexDifferences(model, keys, actual)
+		if err != nil {
+			return diff, fmt.Errorf("DiffIndexes: %w", err)
+		}
+		if len(differences) > 0 {
+			diff.Mismatched = append(diff.Mismatched, IndexMismatch{Declared: model, Actual: actual, Differences: differences})
+		}
+	}
+
+	for _, index := range existing {
+		if !matched[index.Name] {
+			diff.Unexpected = append(diff.Unexpected, index)
+		}
+	}
+
+	return diff, nil
+}
+
+// Reconcile creates the missing indexes of a diff and returns their names.
+// Unexpected and mismatched indexes are left untouched, because dropping indexes requires a human decision.
+func Reconcile(ctx context.Context, r IndexManager, diff IndexDiff) ([]string, error) {
+	return r.CreateIndexes(ctx, diff.Missing)
+}
+
+func declaredIndexName(model mongo.IndexModel, keys bson.D) string {
+	if model.Options != nil && model.Options.Name != nil {
+		return *model.Options.Name
+	}
+
+	return IndexName(keys)
+}
+
+func findIndexByName(indexes []IndexInfo, name string) (IndexInfo, bool) {
+	for _, index := range indexes {
+		if index.Name == name {
+			return index, true
+		}
+	}
+
+	return IndexInfo{}, false
+}
+
+func indexDifferences(model mongo.IndexModel, keys bson.D, actual IndexInfo) ([]string, error) {
+	var differences []string
+
+	if name := declaredIndexName(model, keys); name != actual.Name {
+		differences = append(differences, fmt.Sprintf("name: declared %v, actual %v", name, actual.Name))
+	}
+	if !sameIndexKeys(keys, actual.Keys) && !sameTextIndex(actual, keys) {
+		differences = append(differences, fmt.Sprintf("keys: declared %v, actual %v", keys, actual.Keys))
+	}
+
+	optionDifferences, err := indexOptionDifferences(model, actual)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(differences, optionDifferences...), nil
+}
+
+// indexOptionDifferences compares the options of a declared index with an existing one, ignoring the name and the keys.
+func indexOptionDifferences(model mongo.IndexModel, actual IndexInfo) ([]string, error) {
+	var differences []string
+
+	var unique, sparse bool
+	var expireAfterSeconds *int32
+	var partialFilter interface{}
+	if opts := model.Options; opts != nil {
+		unique = opts.Unique != nil && *opts.Unique
+		sparse = opts.Sparse != nil && *opts.Sparse
+		expireAfterSeconds = opts.ExpireAfterSeconds
+		partialFilter = opts.PartialFilterExpression
+	}
+
+	if unique != actual.Unique {
+		differences = append(differences, fmt.Sprintf("unique: declared %v, actual %v", unique, actual.Unique))
+	}
+	if sparse != actual.Sparse {
+		differences = append(differences, fmt.Sprintf("sparse: declared %v, actual %v", sparse, actual.Sparse))
+	}
+	if formatExpiry(expireAfterSeconds) != formatExpiry(actual.ExpireAfterSeconds) {
+		differences = append(differences, fmt.Sprintf("expireAfterSeconds: declared %v, actual %v", formatExpiry(expireAfterSeconds), formatExpiry(actual.ExpireAfterSeconds)))
+	}
+
+	samePartial, err := sameDocument(partialFilter, actual.PartialFilterExpression)
+	if err != nil {
+		return nil, err
+	}
+	if !samePartial {
+		differences = append(differences, fmt.Sprintf("partialFilterExpression: declared %v, actual %v", partialFilter, actual.PartialFilterExpression))
+	}
+
+	return differences, nil
+}
+
+func formatExpiry(seconds *int32) string {
+	if seconds == nil {
+		return "none"
+	}
+
+	return fmt.Sprint(*seconds)
+}
+
+// sameDocument compares two documents after a bson round trip, so that Go types like int and int32 compare equal.
+// nil and empty documents are equal.
+func sameDocument(a, b interface{}) (bool, error) {
+	na, err := normalizeDocument(a)
+	if err != nil {
+		return false, err
+	}
+	nb, err := normalizeDocument(b)
+	if err != nil {
+		return false, err
+	}
+
+	return reflect.DeepEqual(na, nb), nil
+}
+
+func normalizeDocument(doc interface{}) (bson.M, error) {
+	if doc == nil || reflect.ValueOf(doc).Kind() == reflect.Map && reflect.ValueOf(doc).Len() == 0 {
+		return nil, nil
+	}
+
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var res bson.M
+	err = bson.Unmarshal(raw, &res)
+
+	return res, err
+}
@@ -0,0 +1,94 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// staticIndexes is an IndexManager that only lists a fixed set of indexes and records created ones.
+type staticIndexes struct {
+	indexes []mongodb.IndexInfo
+	created []mongo.IndexModel
+}
+
+func (s *staticIndexes) CreateIndex(ctx context.Context, keys bson.D, opts ...*options.IndexOptions) (string, error) {
+	names, err := s.CreateIndexes(ctx, []mongo.IndexModel{{Keys: keys, Options: options.MergeIndexOptions(opts...)}})
+	return names[0], err
+}
+
+func (s *staticIndexes) CreateIndexes(_ context.Context, models []mongo.IndexModel) ([]string, error) {
+	var names []string
+	for _, model := range models {
+		s.created = append(s.created, model)
+		names = append(names, mongodb.IndexName(model.Keys.(bson.D)))
+	}
+	return names, nil
+}
+
+func (s *staticIndexes) DropIndex(context.Context, string) error { return nil }
+
+func (s *staticIndexes) ListIndexes(context.Context) ([]mongodb.IndexInfo, error) {
+	return s.indexes, nil
+}
+
+func (s *staticIndexes) SetIndexExpireAfter(context.Context, string, time.Duration) error {
+	return nil
+}
+
+func TestDiffIndexes(t *testing.T) {
+	ttl := int32(60)
+	manager := &staticIndexes{indexes: []mongodb.IndexInfo{
+		{Name: "_id_", Keys: bson.D{{Key: "_id", Value: int32(1)}}},
+		{Name: "email_1", Keys: bson.D{{Key: "email", Value: int32(1)}}},
+		{Name: "expiresAt_1", Keys: bson.D{{Key: "expiresAt", Value: int32(1)}}, ExpireAfterSeconds: &ttl},
+		{Name: "legacy_1", Keys: bson.D{{Key: "legacy", Value: int32(1)}}},
+		{Name: "status_1", Keys: bson.D{{Key: "status", Value: int32(1)}}, PartialFilterExpression: bson.M{"deleted": false}},
+	}}
+
+	declared := []mongo.IndexModel{
+		{Keys: mongodb.IndexKeys("email"), Options: options.Index().SetUnique(true)},
+		{Keys: mongodb.IndexKeys("expiresAt"), Options: options.Index().SetExpireAfterSeconds(60)},
+		{Keys: mongodb.IndexKeys("status"), Options: options.Index().SetPartialFilterExpression(bson.M{"deleted": false})},
+		{Keys: mongodb.IndexKeys("companyID", "-createdAt")},
+	}
+
+	diff, err := mongodb.DiffIndexes[*User](context.Background(), manager, declared)
+	if err != nil {
+		t.Fatalf("Error on diffing indexes: %v", err)
+	}
+
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, []mongo.IndexModel{declared[3]}, diff.Missing)
+
+	if assert.Len(t, diff.Unexpected, 1) {
+		assert.Equal(t, "legacy_1", diff.Unexpected[0].Name)
+	}
+	if assert.Len(t, diff.Mismatched, 1) {
+		assert.Equal(t, "email_1", diff.Mismatched[0].Actual.Name)
+		assert.Equal(t, []string{"unique: declared true, actual false"}, diff.Mismatched[0].Differences)
+	}
+
+	names, err := mongodb.Reconcile(context.Background(), manager, diff)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"companyID_1_createdAt_-1"}, names)
+	assert.Len(t, manager.created, 1)
+}
+
+func TestDiffIndexesDetectsChangedPartialFilter(t *testing.T) {
+	manager := &staticIndexes{indexes: []mongodb.IndexInfo{
+		{Name: "status_1", Keys: bson.D{{Key: "status", Value: int32(1)}}, PartialFilterExpression: bson.M{"deleted": false}},
+	}}
+
+	diff, err := mongodb.DiffIndexes[*User](context.Background(), manager, []mongo.IndexModel{
+		{Keys: mongodb.IndexKeys("status"), Options: options.Index().SetPartialFilterExpression(bson.M{"deleted": true})},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, diff.Mismatched, 1)
+}
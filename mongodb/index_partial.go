@@ -0,0 +1,74 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type (
+	// IndexOption configures the indexes created by index helpers like [EnsureUniqueIndex] and [EnsureTTLIndex].
+	IndexOption interface {
+		apply(*indexOption)
+	}
+
+	indexOption struct {
+		partialFilter bson.M
+	}
+)
+
+type partialIndexOption bson.M
+
+func (value partialIndexOption) apply(o *indexOption) {
+	o.partialFilter = bson.M(value)
+}
+
+// Partial restricts an index to the documents matching filter, with a partialFilterExpression.
+//
+// Re-running a helper with the same filter is a no-op. If the index exists with another filter, the helper returns
+// an error wrapping [ErrIndexConflict], since the filter of an existing index can not be changed without dropping it.
+//
+// See [https://www.mongodb.com/docs/manual/core/index-partial/]
+func Partial(filter bson.M) IndexOption {
+	return partialIndexOption(filter)
+}
+
+func newIndexOption(opts []IndexOption) *indexOption {
+	ops := &indexOption{}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+	return ops
+}
+
+// ensureIndex creates the index, unless an index with the same keys exists.
+// An existing index with other options results in an error wrapping [ErrIndexConflict] that lists the differences.
+func ensureIndex(ctx context.Context, op string, r IndexManager, model mongo.IndexModel) error {
+	keys, err := indexKeysOf(model)
+	if err != nil {
+		return fmt.Errorf("%v: %w", op, err)
+	}
+
+	existing, err := r.ListIndexes(ctx)
+	if err != nil {
+		return err
+	}
+
+	if index, ok := findIndexByKeys(existing, keys); ok {
+		differences, err := indexOptionDifferences(model, index)
+		if err != nil {
+			return fmt.Errorf("%v: %w", op, err)
+		}
+		if len(differences) > 0 {
+			return fmt.Errorf("%v: index %v exists with other options, %v: %w", op, index.Name, strings.Join(differences, ", "), ErrIndexConflict)
+		}
+		return nil
+	}
+
+	_, err = r.CreateIndex(ctx, keys, model.Options)
+
+	return err
+}
@@ -0,0 +1,124 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	ArchivableUser struct {
+		mongodb.BaseModel `bson:",inline"`
+		Email             string     `bson:"email"`
+		DeletedAt         *time.Time `bson:"deletedAt,omitempty"`
+	}
+
+	// DriftedUser declares the email index of ArchivableUser with another partial filter.
+	DriftedUser struct {
+		ArchivableUser `bson:",inline"`
+	}
+)
+
+var activeOnly = bson.M{"deletedAt": bson.M{"$exists": false}}
+
+func (*ArchivableUser) Indexes() []mongo.IndexModel {
+	return []mongo.IndexModel{{
+		Keys:    mongodb.IndexKeys("email"),
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(activeOnly),
+	}}
+}
+
+func (*DriftedUser) Indexes() []mongo.IndexModel {
+	return []mongo.IndexModel{{
+		Keys:    mongodb.IndexKeys("email"),
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"deletedAt": nil}),
+	}}
+}
+
+func TestPartialUniqueIndexSoftDelete(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*ArchivableUser](testCollection(t, "user_partial_soft_delete")).(*mongodb.Repository[*ArchivableUser])
+
+	assert.NoError(t, mongodb.EnsureUniqueIndex(ctx, repo, []string{"email"}, mongodb.Partial(activeOnly)))
+	assert.NoError(t, mongodb.EnsureUniqueIndex(ctx, repo, []string{"email"}, mongodb.Partial(bson.M{"deletedAt": bson.M{"$exists": false}})))
+
+	deletedAt := time.Now()
+	_, err := repo.InsertMany(ctx, []*ArchivableUser{
+		{Email: "willy@example.com", DeletedAt: &deletedAt},
+		{Email: "willy@example.com", DeletedAt: &deletedAt},
+		{Email: "willy@example.com"},
+	})
+	assert.NoError(t, err)
+
+	_, err = repo.InsertOne(ctx, &ArchivableUser{Email: "willy@example.com"})
+	assert.True(t, mongo.IsDuplicateKeyError(err))
+
+	indexes, err := repo.ListIndexes(ctx)
+	assert.NoError(t, err)
+	assert.Contains(t, indexes, mongodb.IndexInfo{
+		Name:                    "email_1",
+		Keys:                    bson.D{{Key: "email", Value: int32(1)}},
+		Unique:                  true,
+		PartialFilterExpression: bson.M{"deletedAt": bson.M{"$exists": false}},
+	})
+
+	err = mongodb.EnsureUniqueIndex(ctx, repo, []string{"email"}, mongodb.Partial(bson.M{"deletedAt": nil}))
+	assert.ErrorIs(t, err, mongodb.ErrIndexConflict)
+	assert.ErrorContains(t, err, "partialFilterExpression")
+
+	assert.ErrorIs(t, mongodb.EnsureUniqueIndex(ctx, repo, []string{"email"}), mongodb.ErrIndexConflict)
+}
+
+func TestEnsureIndexesReportsPartialFilterDrift(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*ArchivableUser](testCollection(t, "user_partial_drift")).(*mongodb.Repository[*ArchivableUser])
+
+	report, err := mongodb.EnsureIndexes[*ArchivableUser](ctx, repo)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"email_1"}, report.Created)
+
+	report, err = mongodb.EnsureIndexes[*ArchivableUser](ctx, repo)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"email_1"}, report.Present)
+	assert.Empty(t, report.Drifted)
+
+	report, err = mongodb.EnsureIndexes[*DriftedUser](ctx, repo)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Created)
+	if assert.Len(t, report.Drifted, 1) {
+		assert.Equal(t, "email_1", report.Drifted[0].Actual.Name)
+		assert.Len(t, report.Drifted[0].Differences, 1)
+		assert.Contains(t, report.Drifted[0].Differences[0], "partialFilterExpression")
+	}
+}
+
+func TestEnsureTTLIndexPartial(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*Session](testCollection(t, "session_ttl_partial")).(*mongodb.Repository[*Session])
+	guests := mongodb.Partial(bson.M{"token": "guest"})
+
+	assert.NoError(t, mongodb.EnsureTTLIndex[*Session](ctx, repo, "expiresAt", time.Hour, guests))
+	assert.NoError(t, mongodb.EnsureTTLIndex[*Session](ctx, repo, "expiresAt", time.Hour, guests))
+	assert.NoError(t, mongodb.EnsureTTLIndex[*Session](ctx, repo, "expiresAt", 2*time.Hour, guests))
+
+	indexes, err := repo.ListIndexes(ctx)
+	assert.NoError(t, err)
+	var found bool
+	for _, index := range indexes {
+		if index.Name == "expiresAt_1" {
+			found = true
+			assert.Equal(t, int32(7200), *index.ExpireAfterSeconds)
+			assert.Equal(t, bson.M{"token": "guest"}, index.PartialFilterExpression)
+		}
+	}
+	assert.True(t, found)
+
+	err = mongodb.EnsureTTLIndex[*Session](ctx, repo, "expiresAt", 2*time.Hour)
+	assert.ErrorIs(t, err, mongodb.ErrIndexConflict)
+}
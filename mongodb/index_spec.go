@@ -0,0 +1,276 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The struct tag that declares indexes, see [IndexModels].
+const indexTag = "mongoIndex"
+
+type (
+	// IndexSpecProvider can be implemented by documents to declare indexes that can not be expressed with struct tags,
+	// like compound or partial indexes. See [IndexModels].
+	IndexSpecProvider interface {
+		Indexes() []mongo.IndexModel
+	}
+
+	// EnsureIndexesReport lists the names of the indexes [EnsureIndexes] created and of those that already existed.
+	EnsureIndexesReport struct {
+		Created []string
+		Present []string
+		// Drifted are the existing indexes, also listed in Present, whose options differ from the declared ones,
+		// e.g. because the partialFilterExpression was changed.
+		Drifted []IndexMismatch
+	}
+)
+
+// IndexKeys builds the keys of an index from field names. A leading "-" sorts the field descending.
+//
+//	IndexKeys("companyID", "-createdAt") // bson.D{{"companyID", 1}, {"createdAt", -1}}
+func IndexKeys(fields ...string) bson.D {
+	keys := make(bson.D, 0, len(fields))
+	for _, field := range fields {
+		if strings.HasPrefix(field, "-") {
+			keys = append(keys, bson.E{Key: field[1:], Value: -1})
+		} else {
+			keys = append(keys, bson.E{Key: field, Value: 1})
+		}
+	}
+
+	return keys
+}
+
+// IndexModels returns all indexes declared for the document type T.
+//
+// Single field indexes are declared with the mongoIndex struct tag, which accepts a comma separated list of:
+//
+//   - "asc" or "index" for an ascending index, which is also the default
+//   - "desc" or "-" for a descending index
+//   - "unique" and "sparse" for the respective index options
+//   - "ttl:<duration>" for a TTL index on a date field, e.g. "ttl:24h"
+//   - "text" to include the field in the text index of the collection
+//
+// Fields of nested structs are indexed by their dotted path.
+// All text fields are combined into one index, because a collection can only have a single text index:
+//
+//	Email string `bson:"email" mongoIndex:"unique"`
+//	Name  string `bson:"name" mongoIndex:"text"`
+//
+// Indexes returned by [IndexSpecProvider] are appended.
+func IndexModels[T any]() ([]mongo.IndexModel, error) {
+	t := documentType[T]()
+
+	fields, err := structFields(t)
+	if err != nil {
+		return nil, fmt.Errorf("IndexModels: %w", err)
+	}
+
+	var models []mongo.IndexModel
+	var textKeys bson.D
+
+	for _, field := range fields {
+		tag, ok := field.Field.Tag.Lookup(indexTag)
+		if !ok {
+			continue
+		}
+
+		model, text, err := parseIndexTag(field, tag)
+		if err != nil {
+			return nil, fmt.Errorf("IndexModels: %v: %w", strings.Join(field.GoPath, "."), err)
+		}
+
+		if text {
+			textKeys = append(textKeys, bson.E{Key: field.Path, Value: "text"})
+			continue
+		}
+		models = append(models, model)
+	}
+
+	if len(textKeys) > 0 {
+		models = append(models, mongo.IndexModel{Keys: textKeys})
+	}
+
+	if provider, ok := any(newTValue[T]()).(IndexSpecProvider); ok {
+		models = append(models, provider.Indexes()...)
+	}
+
+	return models, nil
+}
+
+func parseIndexTag(field structField, tag string) (mongo.IndexModel, bool, error) {
+	direction := 1
+	text := false
+	opts := options.Index()
+
+	for _, part := range splitTag(tag) {
+		switch {
+		case part == "asc" || part == "index":
+			direction = 1
+		case part == "desc" || part == "-":
+			direction = -1
+		case part == "unique":
+			opts.SetUnique(true)
+		case part == "sparse":
+			opts.SetSparse(true)
+		case part == "text":
+			text = true
+		case strings.HasPrefix(part, "ttl:"):
+			d, err := time.ParseDuration(strings.TrimPrefix(part, "ttl:"))
+			if err != nil {
+				return mongo.IndexModel{}, false, fmt.Errorf("invalid ttl %q: %w", part, err)
+			}
+			if d < time.Second {
+				return mongo.IndexModel{}, false, fmt.Errorf("ttl %q must be at least one second", part)
+			}
+			if !isDateType(field.Field.Type) {
+				return mongo.IndexModel{}, false, fmt.Errorf("ttl index requires a date field, got %v", field.Field.Type)
+			}
+			opts.SetExpireAfterSeconds(int32(d / time.Second))
+		default:
+			return mongo.IndexModel{}, false, fmt.Errorf("unknown %v option %q", indexTag, part)
+		}
+	}
+
+	if text && (opts.ExpireAfterSeconds != nil || opts.Unique != nil || opts.Sparse != nil || direction != 1) {
+		return mongo.IndexModel{}, false, fmt.Errorf("text indexes can not be combined with other %v options", indexTag)
+	}
+
+	return mongo.IndexModel{Keys: bson.D{{Key: field.Path, Value: direction}}, Options: opts}, text, nil
+}
+
+// EnsureIndexes creates all indexes declared for T, see [IndexModels], that do not exist yet.
+//
+// Indexes are matched by their keys, existing indexes are never modified or dropped.
+// Existing indexes with other options, like a changed partialFilterExpression, are reported in [EnsureIndexesReport.Drifted].
+// It is intended to be called at startup and can be run any number of times.
+func EnsureIndexes[T any](ctx context.Context, r IndexManager) (EnsureIndexesReport, error) {
+	var report EnsureIndexesReport
+
+	models, err := IndexModels[T]()
+	if err != nil {
+		return report, err
+	}
+
+	existing, err := r.ListIndexes(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	var missing []mongo.IndexModel
+	for _, model := range models {
+		keys, err := indexKeysOf(model)
+		if err != nil {
+			return report, fmt.Errorf("EnsureIndexes: %w", err)
+		}
+
+		if index, ok := findIndexByKeys(existing, keys); ok {
+			report.Present = append(report.Present, index.Name)
+
+			differences, err := indexOptionDifferences(model, index)
+			if err != nil {
+				return report, fmt.Errorf("EnsureIndexes: %w", err)
+			}
+			if len(differences) > 0 {
+				report.Drifted = append(report.Drifted, IndexMismatch{Declared: model, Actual: index, Differences: differences})
+			}
+			continue
+		}
+		missing = append(missing, model)
+	}
+
+	created, err := r.CreateIndexes(ctx, missing)
+	if err != nil {
+		return report, err
+	}
+	report.Created = created
+
+	return report, nil
+}
+
+// indexKeysOf converts the keys of an index model into a bson.D, so they can be compared with [IndexInfo.Keys].
+func indexKeysOf(model mongo.IndexModel) (bson.D, error) {
+	if keys, ok := model.Keys.(bson.D); ok {
+		return keys, nil
+	}
+
+	raw, err := bson.Marshal(model.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys bson.D
+	err = bson.Unmarshal(raw, &keys)
+
+	return keys, err
+}
+
+func findIndexByKeys(indexes []IndexInfo, keys bson.D) (IndexInfo, bool) {
+	for _, index := range indexes {
+		if sameIndexKeys(index.Keys, keys) || sameTextIndex(index, keys) {
+			return index, true
+		}
+	}
+
+	return IndexInfo{}, false
+}
+
+// sameTextIndex reports whether keys describe the text index, which the server lists by its weights instead of the keys.
+func sameTextIndex(index IndexInfo, keys bson.D) bool {
+	if index.Weights == nil {
+		return false
+	}
+
+	textFields := 0
+	for _, key := range keys {
+		if key.Value != "text" {
+			continue
+		}
+		if _, ok := index.Weights[key.Key]; !ok {
+			return false
+		}
+		textFields++
+	}
+
+	return textFields > 0 && textFields == len(index.Weights)
+}
+
+// sameIndexKeys compares index keys in order, treating all numeric directions with the same sign as equal.
+func sameIndexKeys(a, b bson.D) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].Key != b[i].Key || normalizeIndexValue(a[i].Value) != normalizeIndexValue(b[i].Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func normalizeIndexValue(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return -1
+		}
+		return 1
+	case reflect.Float32, reflect.Float64:
+		if v.Float() < 0 {
+			return -1
+		}
+		return 1
+	}
+
+	return value
+}
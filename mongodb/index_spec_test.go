@@ -0,0 +1,104 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	Profile struct {
+		City string `bson:"city" mongoIndex:"desc"`
+	}
+
+	IndexedUser struct {
+		mongodb.BaseModel `bson:",inline"`
+		Email             string    `bson:"email" mongoIndex:"unique, sparse"`
+		Name              string    `bson:"name" mongoIndex:"text"`
+		Bio               string    `bson:"bio" mongoIndex:"text"`
+		ExpiresAt         time.Time `bson:"expiresAt" mongoIndex:"ttl:1h"`
+		Profile           Profile   `bson:"profile"`
+		CompanyID         string    `bson:"companyID"`
+	}
+
+	BadTTLUser struct {
+		mongodb.BaseModel `bson:",inline"`
+		Name              string `bson:"name" mongoIndex:"ttl:1h"`
+	}
+
+	BadOptionUser struct {
+		mongodb.BaseModel `bson:",inline"`
+		Name              string `bson:"name" mongoIndex:"uniq"`
+	}
+)
+
+func (u *IndexedUser) Indexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{Keys: mongodb.IndexKeys("companyID", "-createdAt")},
+	}
+}
+
+func TestIndexModels(t *testing.T) {
+	models, err := mongodb.IndexModels[*IndexedUser]()
+	if err != nil {
+		t.Fatalf("Error on parsing indexes: %v", err)
+	}
+
+	assert.Len(t, models, 5)
+
+	assert.Equal(t, bson.D{{Key: "email", Value: 1}}, models[0].Keys)
+	assert.True(t, *models[0].Options.Unique)
+	assert.True(t, *models[0].Options.Sparse)
+
+	assert.Equal(t, bson.D{{Key: "expiresAt", Value: 1}}, models[1].Keys)
+	assert.Equal(t, int32(3600), *models[1].Options.ExpireAfterSeconds)
+
+	assert.Equal(t, bson.D{{Key: "profile.city", Value: -1}}, models[2].Keys)
+
+	assert.Equal(t, bson.D{{Key: "name", Value: "text"}, {Key: "bio", Value: "text"}}, models[3].Keys)
+
+	assert.Equal(t, bson.D{{Key: "companyID", Value: 1}, {Key: "createdAt", Value: -1}}, models[4].Keys)
+}
+
+func TestIndexModelsInvalidTags(t *testing.T) {
+	_, err := mongodb.IndexModels[*BadTTLUser]()
+	assert.ErrorContains(t, err, "date field")
+
+	_, err = mongodb.IndexModels[*BadOptionUser]()
+	assert.ErrorContains(t, err, `unknown mongoIndex option "uniq"`)
+}
+
+func TestEnsureIndexesIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*IndexedUser](testCollection(t, "user_ensure_indexes")).(*mongodb.Repository[*IndexedUser])
+
+	report, err := mongodb.EnsureIndexes[*IndexedUser](ctx, repo)
+	if err != nil {
+		t.Fatalf("Error on ensuring indexes: %v", err)
+	}
+	assert.Len(t, report.Created, 5)
+	assert.Empty(t, report.Present)
+
+	report, err = mongodb.EnsureIndexes[*IndexedUser](ctx, repo)
+	if err != nil {
+		t.Fatalf("Error on ensuring indexes: %v", err)
+	}
+	assert.Empty(t, report.Created)
+	assert.Len(t, report.Present, 5)
+
+	// Unrelated indexes are never dropped.
+	_, err = repo.CreateIndex(ctx, bson.D{{Key: "other", Value: 1}}, options.Index())
+	assert.NoError(t, err)
+	_, err = mongodb.EnsureIndexes[*IndexedUser](ctx, repo)
+	assert.NoError(t, err)
+
+	indexes, err := repo.ListIndexes(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, indexes, 7)
+}
@@ -0,0 +1,76 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestIndexManagement(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_indexes")).(*mongodb.Repository[*User])
+
+	name, err := repo.CreateIndex(ctx, bson.D{{Key: "email", Value: 1}}, options.Index().SetUnique(true))
+	if err != nil {
+		t.Fatalf("Error on creating index: %v", err)
+	}
+	assert.Equal(t, "email_1", name)
+
+	// Identical options are a no-op.
+	_, err = repo.CreateIndex(ctx, bson.D{{Key: "email", Value: 1}}, options.Index().SetUnique(true))
+	assert.NoError(t, err)
+
+	// Conflicting options are reported.
+	_, err = repo.CreateIndex(ctx, bson.D{{Key: "email", Value: 1}}, options.Index().SetUnique(false).SetSparse(true))
+	assert.ErrorIs(t, err, mongodb.ErrIndexConflict)
+
+	indexes, err := repo.ListIndexes(ctx)
+	if err != nil {
+		t.Fatalf("Error on listing indexes: %v", err)
+	}
+	assert.Len(t, indexes, 2)
+	assert.Equal(t, "email_1", indexes[1].Name)
+	assert.True(t, indexes[1].Unique)
+	assert.Equal(t, bson.D{{Key: "email", Value: int32(1)}}, indexes[1].Keys)
+
+	assert.NoError(t, repo.DropIndex(ctx, "email_1"))
+
+	indexes, err = repo.ListIndexes(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, indexes, 1)
+}
+
+type Session struct {
+	mongodb.BaseModel `bson:",inline"`
+	Token             string    `bson:"token"`
+	ExpiresAt         time.Time `bson:"expiresAt"`
+}
+
+func TestEnsureTTLIndex(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*Session](testCollection(t, "session_ttl")).(*mongodb.Repository[*Session])
+
+	assert.ErrorContains(t, mongodb.EnsureTTLIndex[*Session](ctx, repo, "token", time.Hour), "must be a date")
+	assert.ErrorContains(t, mongodb.EnsureTTLIndex[*Session](ctx, repo, "missing", time.Hour), "has no field")
+
+	assert.NoError(t, mongodb.EnsureTTLIndex[*Session](ctx, repo, "expiresAt", time.Hour))
+	assert.NoError(t, mongodb.EnsureTTLIndex[*Session](ctx, repo, "expiresAt", time.Hour))
+
+	indexes, err := repo.ListIndexes(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, indexes, 2)
+	assert.Equal(t, int32(3600), *indexes[1].ExpireAfterSeconds)
+
+	// A different expiry updates the existing index instead of failing.
+	assert.NoError(t, mongodb.EnsureTTLIndex[*Session](ctx, repo, "expiresAt", 2*time.Hour))
+
+	indexes, err = repo.ListIndexes(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, indexes, 2)
+	assert.Equal(t, int32(7200), *indexes[1].ExpireAfterSeconds)
+}
@@ -0,0 +1,76 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureTTLIndex makes sure that documents expire expireAfter after the date stored in field.
+//
+// The field must exist on the document type T and be stored as a BSON date, otherwise MongoDB would silently never remove the documents.
+// If a TTL index on the field already exists with another expireAfter, it is updated using the collMod command.
+// An existing non-TTL index on the field results in an error wrapping [ErrIndexConflict].
+//
+// With [Partial], only the documents matching the filter expire. An existing TTL index on the field with another filter
+// results in an error wrapping [ErrIndexConflict].
+//
+// See [https://www.mongodb.com/docs/manual/core/index-ttl/]
+func EnsureTTLIndex[T any](ctx context.Context, r IndexManager, field string, expireAfter time.Duration, opts ...IndexOption) error {
+	if expireAfter < 0 {
+		return fmt.Errorf("EnsureTTLIndex: expireAfter must not be negative, got %v", expireAfter)
+	}
+
+	sf, ok, err := lookupField(documentType[T](), field)
+	if err != nil {
+		return fmt.Errorf("EnsureTTLIndex: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("EnsureTTLIndex: %v has no field %q", documentType[T](), field)
+	}
+	if !isDateType(sf.Field.Type) {
+		return fmt.Errorf("EnsureTTLIndex: field %q must be a date, got %v", field, sf.Field.Type)
+	}
+
+	existing, err := r.ListIndexes(ctx)
+	if err != nil {
+		return err
+	}
+
+	ops := newIndexOption(opts)
+	seconds := int32(expireAfter / time.Second)
+	keys := bson.D{{Key: field, Value: 1}}
+	indexOpts := options.Index().SetExpireAfterSeconds(seconds)
+	if ops.partialFilter != nil {
+		indexOpts.SetPartialFilterExpression(ops.partialFilter)
+	}
+
+	for _, index := range existing {
+		if !sameIndexKeys(index.Keys, keys) && !sameIndexKeys(index.Keys, bson.D{{Key: field, Value: -1}}) {
+			continue
+		}
+
+		if !index.IsTTL() {
+			return fmt.Errorf("EnsureTTLIndex: index %v on %q is not a TTL index: %w", index.Name, field, ErrIndexConflict)
+		}
+		samePartial, err := sameDocument(ops.partialFilter, index.PartialFilterExpression)
+		if err != nil {
+			return fmt.Errorf("EnsureTTLIndex: %w", err)
+		}
+		if !samePartial {
+			return fmt.Errorf("EnsureTTLIndex: index %v on %q has the partial filter %v instead of %v: %w", index.Name, field, index.PartialFilterExpression, ops.partialFilter, ErrIndexConflict)
+		}
+		if *index.ExpireAfterSeconds == seconds {
+			return nil
+		}
+
+		return r.SetIndexExpireAfter(ctx, index.Name, expireAfter)
+	}
+
+	_, err = r.CreateIndex(ctx, keys, indexOpts)
+
+	return err
+}
@@ -0,0 +1,193 @@
+//go:build go1.23
+
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The iterators of this file need the iter package, so they are only built with Go 1.23 and later,
+// while the rest of the module keeps the Go version of go.mod.
+var _ FindIter[*BaseModel] = (*Repository[*BaseModel])(nil)
+
+type (
+	FindIter[T Document[T]] interface {
+		// Finds all Documents that match the given filter, and yields them one by one while reading the cursor.
+		//
+		// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.Find]
+		FindIter(ctx context.Context, filter bson.M, opts ...*options.FindOptions) iter.Seq2[T, error]
+	}
+)
+
+// Finds all Documents that match the given filter, and yields them one by one while reading the cursor,
+// so that large results are never held in memory at once.
+//
+//	for user, err := range repo.FindIter(ctx, filter) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// The query runs when the iteration starts, and the cursor is closed when it ends, also on break.
+// An error ends the iteration, it is yielded with the zero value of T.
+func (r *Repository[T]) FindIter(ctx context.Context, filter bson.M, opts ...*options.FindOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		stopped := false
+
+		err := r.findIter(ctx, filter, opts, func(doc T) bool {
+			stopped = !yield(doc, nil)
+			return !stopped
+		})
+		if err != nil && !stopped {
+			yield(zero, err)
+		}
+	}
+}
+
+func (r Forwarder[T]) FindIter(ctx context.Context, filter bson.M, opts ...*options.FindOptions) iter.Seq2[T, error] {
+	inner, err := Capability[FindIter[T]](r.RepositoryI)
+	if err != nil {
+		return func(yield func(T, error) bool) {
+			var zero T
+			yield(zero, err)
+		}
+	}
+	return inner.FindIter(ctx, filter, opts...)
+}
+
+func (r *Repository[T]) findIter(ctx context.Context, filter bson.M, opts []*options.FindOptions, yield func(T) bool) (err error) {
+	ctx, finish, err := r.begin(ctx, "FindIter", filter)
+	if err != nil {
+		return err
+	}
+	defer func() { err = finish(err) }()
+
+	if filter == nil {
+		filter = bson.M{}
+	}
+	if r.cursorKeepAlive {
+		return r.findIterKeepAlive(ctx, filter, r.findOptions(ctx, opts), yield)
+	}
+
+	cur, err := r.CollectionFor(ctx).Find(ctx, filter, r.findOptions(ctx, opts)...)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(context.Background())
+
+	for cur.Next(ctx) {
+		doc, keep, err := r.decodeResult(ctx, cur)
+		if err != nil {
+			return err
+		}
+		if keep && !yield(doc) {
+			return nil
+		}
+	}
+
+	return cur.Err()
+}
+
+// defaultPrefetch is the number of results [Prefetch] buffers by default, the size of the first batch of a cursor.
+const defaultPrefetch = 101
+
+// AggregateIter runs an aggregation pipeline and yields its results decoded into R one by one while reading the cursor,
+// like [Repository.FindIter], so that large results are never held in memory at once.
+//
+//	for row, err := range mongodb.AggregateIter[ReportRow](ctx, repo, pipeline) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// The pipeline runs when the iteration starts, and the cursor is closed when it ends, also on break.
+// An error, also that of a canceled ctx, ends the iteration and is yielded with the zero value of R. Wrap the iterator with [Prefetch]
+// to read the next results while the current ones are processed.
+func AggregateIter[R any](ctx context.Context, r Aggregater, pipeline mongo.Pipeline, opts ...*options.AggregateOptions) iter.Seq2[R, error] {
+	return func(yield func(R, error) bool) {
+		var zero R
+
+		cur, err := r.Aggregate(ctx, pipeline, opts...)
+		if err != nil {
+			yield(zero, fmt.Errorf("%v: %w", "mongodb.AggregateIter", err))
+			return
+		}
+		defer cur.Close(context.Background())
+
+		for cur.Next(ctx) {
+			// The cursor only checks ctx when it fetches the next batch.
+			if err := ctx.Err(); err != nil {
+				yield(zero, fmt.Errorf("%v: %w", "mongodb.AggregateIter", err))
+				return
+			}
+
+			var res R
+			if err := cur.Decode(&res); err != nil {
+				yield(zero, fmt.Errorf("%v: %w", "mongodb.AggregateIter", err))
+				return
+			}
+			if !yield(res, nil) {
+				return
+			}
+		}
+
+		if err := cur.Err(); err != nil {
+			yield(zero, fmt.Errorf("%v: %w", "mongodb.AggregateIter", err))
+		}
+	}
+}
+
+// Prefetch reads seq on a background goroutine, up to n results ahead of the caller, so that reading the cursor
+// overlaps with processing the results. A n <= 0 buffers 101 results, the size of the first batch of a cursor.
+//
+//	for row, err := range mongodb.Prefetch(mongodb.AggregateIter[ReportRow](ctx, repo, pipeline), 1000) { ... }
+//
+// When the iteration ends, also on break, Prefetch waits until seq returned, so the cursor of seq is closed
+// once the loop is left. Cancel the context of seq to stop reading earlier.
+func Prefetch[T any](seq iter.Seq2[T, error], n int) iter.Seq2[T, error] {
+	if n <= 0 {
+		n = defaultPrefetch
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+
+	return func(yield func(T, error) bool) {
+		results := make(chan result, n)
+		done := make(chan struct{})
+		finished := make(chan struct{})
+
+		go func() {
+			defer close(finished)
+			defer close(results)
+			for value, err := range seq {
+				select {
+				case results <- result{value: value, err: err}:
+				case <-done:
+					return
+				}
+			}
+		}()
+		defer func() {
+			close(done)
+			<-finished
+		}()
+
+		for res := range results {
+			if !yield(res.value, res.err) {
+				return
+			}
+		}
+	}
+}
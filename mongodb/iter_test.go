@@ -0,0 +1,158 @@
+//go:build go1.23
+
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func init() {
+	outsideRepositoryI["FindIter"] = reflect.TypeFor[mongodb.FindIter[*User]]()
+}
+
+type failingAggregater struct {
+	err error
+}
+
+func (f failingAggregater) Aggregate(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	return nil, f.err
+}
+
+func TestAggregateIter(t *testing.T) {
+	ctx := context.Background()
+	docs := make([]interface{}, 500)
+	for i := range docs {
+		docs[i] = bson.D{{Key: "n", Value: i}}
+	}
+
+	type row struct {
+		N int `bson:"n"`
+	}
+
+	sum := 0
+	for res, err := range mongodb.AggregateIter[row](ctx, staticCursor{docs: docs}, mongo.Pipeline{}) {
+		assert.NoError(t, err)
+		sum += res.N
+	}
+	assert.Equal(t, 499*500/2, sum)
+
+	count := 0
+	for _, err := range mongodb.Prefetch(mongodb.AggregateIter[row](ctx, staticCursor{docs: docs}, mongo.Pipeline{}), 10) {
+		assert.NoError(t, err)
+		count++
+		if count == 20 {
+			break
+		}
+	}
+	assert.Equal(t, 20, count)
+
+	errFailed := errors.New("failed")
+	for _, err := range mongodb.AggregateIter[row](ctx, failingAggregater{err: errFailed}, mongo.Pipeline{}) {
+		assert.ErrorIs(t, err, errFailed)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	var last error
+	for _, err := range mongodb.AggregateIter[row](canceled, staticCursor{docs: docs}, mongo.Pipeline{}) {
+		last = err
+	}
+	assert.ErrorIs(t, last, context.Canceled)
+}
+
+func TestPrefetchWaitsForSequence(t *testing.T) {
+	returned := false
+	var seq iter.Seq2[int, error] = func(yield func(int, error) bool) {
+		defer func() { returned = true }()
+		for i := 0; ; i++ {
+			if !yield(i, nil) {
+				return
+			}
+		}
+	}
+
+	var got []int
+	for n, err := range mongodb.Prefetch(seq, 5) {
+		assert.NoError(t, err)
+		got = append(got, n)
+		if len(got) == 3 {
+			break
+		}
+	}
+	assert.Equal(t, []int{0, 1, 2}, got)
+	assert.True(t, returned)
+}
+
+func TestAggregateIterClosesCursor(t *testing.T) {
+	ctx := context.Background()
+	col := testCollection(t, "aggregate_iter")
+	repo := mongodb.NewRepository[*User](col)
+
+	users := make([]*User, 1000)
+	for i := range users {
+		users[i] = &User{Name: "user"}
+	}
+	if _, err := repo.InsertMany(ctx, users); err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	openCursors := func() int64 {
+		var status bson.M
+		if err := col.Database().RunCommand(ctx, bson.D{{Key: "serverStatus", Value: 1}}).Decode(&status); err != nil {
+			t.Fatalf("Error on reading serverStatus: %v", err)
+		}
+		open := status["metrics"].(bson.M)["cursor"].(bson.M)["open"].(bson.M)
+		return open["total"].(int64)
+	}
+	before := openCursors()
+
+	count := 0
+	seq := mongodb.AggregateIter[User](ctx, repo, mongo.Pipeline{}, options.Aggregate().SetBatchSize(10))
+	for _, err := range mongodb.Prefetch(seq, 10) {
+		assert.NoError(t, err)
+		count++
+		if count == 50 {
+			break
+		}
+	}
+	assert.Equal(t, 50, count)
+	assert.Equal(t, before, openCursors())
+}
+
+func TestFindIterIgnoresMaxResultSize(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_max_result_size_iter"), mongodb.WithMaxResultSize(3)).(*mongodb.Repository[*User])
+	for i := range 4 {
+		if _, err := repo.InsertOne(ctx, &User{Name: fmt.Sprintf("user %d", i)}); err != nil {
+			t.Fatalf("Error inserting user: %v", err)
+		}
+	}
+
+	iterated := 0
+	for _, err := range repo.FindIter(ctx, bson.M{}) {
+		assert.NoError(t, err)
+		iterated++
+	}
+	assert.Equal(t, 4, iterated)
+}
+
+func TestForwarderFindIterUnsupported(t *testing.T) {
+	forwarder := mongodb.Forwarder[*User]{RepositoryI: coreRepository{}}
+	count := 0
+	for _, err := range forwarder.FindIter(context.Background(), bson.M{}) {
+		assert.ErrorIs(t, err, mongodb.ErrUnsupported)
+		count++
+	}
+	assert.Equal(t, 1, count)
+}
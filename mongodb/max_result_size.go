@@ -0,0 +1,98 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrResultTruncated is returned when more documents match than allowed by [WithMaxResultSize] or [AggregateLimited].
+var ErrResultTruncated = errors.New("mongodb: result truncated")
+
+type maxResultSizeOption int
+
+func (value maxResultSizeOption) apply(o *repositoryOption) {
+	o.maxResultSize = int(value)
+}
+
+// WithMaxResultSize guards FindMany, FindManyN and FindManyWhere against unbounded results that are loaded into memory.
+//
+// If the caller sets no limit, a limit of n+1 is applied, and if more than n documents match, an error wrapping
+// [ErrResultTruncated] is returned. By default the documents are discarded, see [WithTruncatedResults].
+// An explicit limit of the caller, of any size, bypasses the guard. A n <= 0 disables it, which is the default.
+//
+// Callers that need all documents should use FindIter or FindManyParallel, which are not limited.
+func WithMaxResultSize(n int) RepositoryOption {
+	return maxResultSizeOption(n)
+}
+
+type truncatedResultsOption bool
+
+func (value truncatedResultsOption) apply(o *repositoryOption) {
+	o.keepTruncated = bool(value)
+}
+
+// WithTruncatedResults returns the first n documents together with the [ErrResultTruncated] of [WithMaxResultSize],
+// instead of discarding them.
+func WithTruncatedResults() RepositoryOption {
+	return truncatedResultsOption(true)
+}
+
+// limitResultSize adds the limit of [WithMaxResultSize] to the options, and reports whether it was added.
+func (r *Repository[T]) limitResultSize(opts []*options.FindOptions) ([]*options.FindOptions, bool) {
+	if r.maxResultSize <= 0 {
+		return opts, false
+	}
+	if limit := options.MergeFindOptions(opts...).Limit; limit != nil && *limit != 0 {
+		return opts, false
+	}
+
+	limited := make([]*options.FindOptions, 0, len(opts)+1)
+	limited = append(limited, opts...)
+	return append(limited, options.Find().SetLimit(int64(r.maxResultSize)+1)), true
+}
+
+// checkResultSize returns an [ErrResultTruncated] if res has more than the allowed documents.
+func (r *Repository[T]) checkResultSize(res []T) ([]T, error) {
+	if len(res) <= r.maxResultSize {
+		return res, nil
+	}
+
+	err := fmt.Errorf("%w: more than %d documents match in %v", ErrResultTruncated, r.maxResultSize, r.db.Name())
+	if r.keepTruncated {
+		return res[:r.maxResultSize], err
+	}
+	return nil, err
+}
+
+// AggregateLimited runs the pipeline with an additional final $limit stage of maxResults+1, and decodes the results into R.
+// If the pipeline produces more than maxResults documents, the first maxResults are returned together with an error
+// wrapping [ErrResultTruncated].
+func AggregateLimited[R any](ctx context.Context, r Aggregater, pipeline mongo.Pipeline, maxResults int, opts ...*options.AggregateOptions) ([]R, error) {
+	if maxResults <= 0 {
+		return nil, fmt.Errorf("%v: maxResults must be greater than 0, got %d", "mongodb.AggregateLimited", maxResults)
+	}
+
+	limited := make(mongo.Pipeline, 0, len(pipeline)+1)
+	limited = append(limited, pipeline...)
+	limited = append(limited, bson.D{{Key: "$limit", Value: maxResults + 1}})
+
+	cur, err := r.Aggregate(ctx, limited, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.AggregateLimited", err)
+	}
+
+	var res []R
+	if err := cur.All(ctx, &res); err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.AggregateLimited", err)
+	}
+	if len(res) > maxResults {
+		return res[:maxResults], fmt.Errorf("%v: %w: the pipeline produces more than %d documents", "mongodb.AggregateLimited", ErrResultTruncated, maxResults)
+	}
+
+	return res, nil
+}
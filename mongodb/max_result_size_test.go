@@ -0,0 +1,76 @@
+package mongodb_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestMaxResultSize(t *testing.T) {
+	ctx := context.Background()
+	col := testCollection(t, "user_max_result_size")
+	repo := mongodb.NewRepository[*User](col, mongodb.WithMaxResultSize(3)).(*mongodb.Repository[*User])
+	partial := mongodb.NewRepository[*User](col, mongodb.WithMaxResultSize(3), mongodb.WithTruncatedResults())
+
+	insert := func(n int) {
+		for i := 0; i < n; i++ {
+			if _, err := repo.InsertOne(ctx, &User{Name: fmt.Sprintf("user %d", i)}); err != nil {
+				t.Fatalf("Error inserting user: %v", err)
+			}
+		}
+	}
+
+	insert(3)
+	users, err := repo.FindMany(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Len(t, users, 3)
+
+	insert(1)
+	users, err = repo.FindMany(ctx, bson.M{})
+	assert.ErrorIs(t, err, mongodb.ErrResultTruncated)
+	assert.Nil(t, users)
+
+	users, err = repo.FindManyN(ctx, bson.M{}, 10)
+	assert.ErrorIs(t, err, mongodb.ErrResultTruncated)
+	assert.Nil(t, users)
+
+	users, err = partial.FindMany(ctx, bson.M{})
+	assert.ErrorIs(t, err, mongodb.ErrResultTruncated)
+	assert.Len(t, users, 3)
+
+	// An explicit limit bypasses the guard, also if it is larger.
+	users, err = repo.FindMany(ctx, bson.M{}, options.Find().SetLimit(10))
+	assert.NoError(t, err)
+	assert.Len(t, users, 4)
+}
+
+func TestAggregateLimited(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_aggregate_limited"))
+
+	for _, name := range []string{"alice", "bob", "carol"} {
+		if _, err := repo.InsertOne(ctx, &User{Name: name}); err != nil {
+			t.Fatalf("Error inserting user: %v", err)
+		}
+	}
+	pipeline := mongo.Pipeline{{{Key: "$sort", Value: bson.M{"name": 1}}}}
+
+	users, err := mongodb.AggregateLimited[User](ctx, repo, pipeline, 3)
+	assert.NoError(t, err)
+	assert.Len(t, users, 3)
+
+	users, err = mongodb.AggregateLimited[User](ctx, repo, pipeline, 2)
+	assert.ErrorIs(t, err, mongodb.ErrResultTruncated)
+	if assert.Len(t, users, 2) {
+		assert.Equal(t, "bob", users[1].Name)
+	}
+
+	_, err = mongodb.AggregateLimited[User](ctx, repo, pipeline, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,200 @@
+package mongodb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MergeStrategy decides how [MergeBSON] combines keys that are present in both documents.
+type MergeStrategy int
+
+const (
+	// MergeNested merges nested documents recursively, while for other values the value of src wins.
+	MergeNested MergeStrategy = iota
+	// MergeFilter combines filters, so that the result matches the documents that match both.
+	// Conflicting conditions on the same key are wrapped into an $and, and $and arrays are concatenated.
+	MergeFilter
+	// MergeUpdate combines update documents by the union of the fields of each operator, e.g. $set and $unset.
+	// Updating the same field with different values, or in different operators, is a conflict.
+	MergeUpdate
+)
+
+// ErrMergeConflict is returned by [MergeBSON] when both documents update the same field differently.
+var ErrMergeConflict = errors.New("mongodb: conflicting update")
+
+// MergeBSON merges src into a copy of dst, depending on the strategy. Neither dst nor src are modified.
+//
+//	filter, _ := mongodb.MergeBSON(baseFilter, bson.M{"status": "active"}, mongodb.MergeFilter)
+//	update, err := mongodb.MergeBSON(bson.M{"$set": bson.M{"a": 1}}, bson.M{"$set": bson.M{"b": 2}}, mongodb.MergeUpdate)
+//	// bson.M{"$set": bson.M{"a": 1, "b": 2}}
+func MergeBSON(dst, src primitive.M, strategy MergeStrategy) (primitive.M, error) {
+	res := copyM(dst)
+
+	switch strategy {
+	case MergeNested:
+		mergeNested(res, src)
+	case MergeFilter:
+		mergeFilter(res, src)
+	case MergeUpdate:
+		if err := mergeUpdate(res, src); err != nil {
+			return nil, fmt.Errorf("%v: %w", "mongodb.MergeBSON", err)
+		}
+	default:
+		return nil, fmt.Errorf("%v: unknown strategy %d", "mongodb.MergeBSON", strategy)
+	}
+
+	return res, nil
+}
+
+func mergeNested(dst, src primitive.M) {
+	for key, value := range src {
+		srcDoc, srcOK := asM(value)
+		dstDoc, dstOK := asM(dst[key])
+		if srcOK && dstOK {
+			nested := copyM(dstDoc)
+			mergeNested(nested, srcDoc)
+			dst[key] = nested
+			continue
+		}
+		dst[key] = copyValue(value)
+	}
+}
+
+func mergeFilter(dst, src primitive.M) {
+	// Keys are merged in order, so that the resulting $and is deterministic.
+	keys := make([]string, 0, len(src))
+	for key := range src {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := src[key]
+		existing, ok := dst[key]
+		switch {
+		case !ok:
+			dst[key] = copyValue(value)
+		case reflect.DeepEqual(existing, value):
+		case key == "$and":
+			dst[key] = append(asA(existing), asA(copyValue(value))...)
+		default:
+			delete(dst, key)
+			and := asA(dst["$and"])
+			dst["$and"] = append(and, primitive.M{key: existing}, primitive.M{key: copyValue(value)})
+		}
+	}
+}
+
+func mergeUpdate(dst, src primitive.M) error {
+	paths := map[string]string{}
+	for op, fields := range dst {
+		doc, ok := asM(fields)
+		if !ok {
+			return fmt.Errorf("%v is not an update operator document", op)
+		}
+		for path := range doc {
+			paths[path] = op
+		}
+	}
+
+	for op, fields := range src {
+		if !strings.HasPrefix(op, "$") {
+			return fmt.Errorf("%v is not an update operator", op)
+		}
+		srcDoc, ok := asM(fields)
+		if !ok {
+			return fmt.Errorf("%v is not an update operator document", op)
+		}
+
+		dstDoc, _ := asM(dst[op])
+		merged := copyM(dstDoc)
+		for path, value := range srcDoc {
+			for other, otherOp := range paths {
+				if other == path && otherOp == op {
+					if !reflect.DeepEqual(merged[path], value) {
+						return fmt.Errorf("%w: %v %v is set to %v and %v", ErrMergeConflict, op, path, merged[path], value)
+					}
+					continue
+				}
+				if other == path || strings.HasPrefix(other, path+".") || strings.HasPrefix(path, other+".") {
+					return fmt.Errorf("%w: %v %v and %v %v", ErrMergeConflict, otherOp, other, op, path)
+				}
+			}
+			merged[path] = copyValue(value)
+			paths[path] = op
+		}
+		dst[op] = merged
+	}
+
+	return nil
+}
+
+func asM(value interface{}) (primitive.M, bool) {
+	switch v := value.(type) {
+	case primitive.M:
+		return v, true
+	case map[string]interface{}:
+		return primitive.M(v), true
+	}
+	return nil, false
+}
+
+// asA returns the elements of an array value, and nil for other values.
+func asA(value interface{}) bson.A {
+	switch v := value.(type) {
+	case bson.A:
+		return append(bson.A{}, v...)
+	case []interface{}:
+		return append(bson.A{}, v...)
+	case []primitive.M:
+		res := make(bson.A, len(v))
+		for i, doc := range v {
+			res[i] = doc
+		}
+		return res
+	}
+	return nil
+}
+
+// copyM copies a document and all nested documents and arrays.
+func copyM(doc primitive.M) primitive.M {
+	res := make(primitive.M, len(doc))
+	for key, value := range doc {
+		res[key] = copyValue(value)
+	}
+	return res
+}
+
+// copyValue copies documents and arrays, including the documents and arrays they contain.
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.A:
+		res := make(bson.A, len(v))
+		for i, elem := range v {
+			res[i] = copyValue(elem)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, elem := range v {
+			res[i] = copyValue(elem)
+		}
+		return res
+	case []primitive.M:
+		res := make([]primitive.M, len(v))
+		for i, doc := range v {
+			res[i] = copyM(doc)
+		}
+		return res
+	}
+	if doc, ok := asM(value); ok {
+		return copyM(doc)
+	}
+	return value
+}
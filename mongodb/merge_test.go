@@ -0,0 +1,197 @@
+package mongodb_test
+
+import (
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMergeBSON(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		dst      primitive.M
+		src      primitive.M
+		strategy mongodb.MergeStrategy
+		want     primitive.M
+		conflict bool
+	}{
+		{
+			name:     "nested disjoint",
+			dst:      bson.M{"a": 1},
+			src:      bson.M{"b": 2},
+			strategy: mongodb.MergeNested,
+			want:     bson.M{"a": 1, "b": 2},
+		},
+		{
+			name:     "nested recursion",
+			dst:      bson.M{"settings": bson.M{"theme": "dark", "flags": bson.M{"beta": true}}},
+			src:      bson.M{"settings": bson.M{"flags": bson.M{"alpha": true}}},
+			strategy: mongodb.MergeNested,
+			want:     bson.M{"settings": bson.M{"theme": "dark", "flags": bson.M{"beta": true, "alpha": true}}},
+		},
+		{
+			name:     "nested leaf is overwritten by src",
+			dst:      bson.M{"a": bson.M{"b": 1}},
+			src:      bson.M{"a": bson.M{"b": 2}},
+			strategy: mongodb.MergeNested,
+			want:     bson.M{"a": bson.M{"b": 2}},
+		},
+		{
+			name:     "nested document replaces scalar",
+			dst:      bson.M{"a": 1},
+			src:      bson.M{"a": bson.M{"b": 2}},
+			strategy: mongodb.MergeNested,
+			want:     bson.M{"a": bson.M{"b": 2}},
+		},
+		{
+			name:     "filter disjoint",
+			dst:      bson.M{"companyID": 1},
+			src:      bson.M{"status": "active"},
+			strategy: mongodb.MergeFilter,
+			want:     bson.M{"companyID": 1, "status": "active"},
+		},
+		{
+			name:     "filter equal condition",
+			dst:      bson.M{"companyID": 1},
+			src:      bson.M{"companyID": 1},
+			strategy: mongodb.MergeFilter,
+			want:     bson.M{"companyID": 1},
+		},
+		{
+			name:     "filter conflict is wrapped in $and",
+			dst:      bson.M{"companyID": 1, "age": bson.M{"$gte": 18}},
+			src:      bson.M{"age": bson.M{"$lt": 65}},
+			strategy: mongodb.MergeFilter,
+			want: bson.M{"companyID": 1, "$and": bson.A{
+				bson.M{"age": bson.M{"$gte": 18}},
+				bson.M{"age": bson.M{"$lt": 65}},
+			}},
+		},
+		{
+			name:     "filter $or conflict",
+			dst:      bson.M{"$or": bson.A{bson.M{"a": 1}, bson.M{"b": 1}}},
+			src:      bson.M{"$or": bson.A{bson.M{"c": 1}, bson.M{"d": 1}}},
+			strategy: mongodb.MergeFilter,
+			want: bson.M{"$and": bson.A{
+				bson.M{"$or": bson.A{bson.M{"a": 1}, bson.M{"b": 1}}},
+				bson.M{"$or": bson.A{bson.M{"c": 1}, bson.M{"d": 1}}},
+			}},
+		},
+		{
+			name:     "filter $and arrays are concatenated",
+			dst:      bson.M{"$and": bson.A{bson.M{"a": 1}}},
+			src:      bson.M{"$and": []bson.M{{"b": 1}}},
+			strategy: mongodb.MergeFilter,
+			want:     bson.M{"$and": bson.A{bson.M{"a": 1}, bson.M{"b": 1}}},
+		},
+		{
+			name:     "filter conflict appends to existing $and",
+			dst:      bson.M{"$and": bson.A{bson.M{"a": 1}}, "b": 1},
+			src:      bson.M{"b": 2},
+			strategy: mongodb.MergeFilter,
+			want:     bson.M{"$and": bson.A{bson.M{"a": 1}, bson.M{"b": 1}, bson.M{"b": 2}}},
+		},
+		{
+			name:     "update $set union",
+			dst:      bson.M{"$set": bson.M{"a": 1}},
+			src:      bson.M{"$set": bson.M{"b": 2}},
+			strategy: mongodb.MergeUpdate,
+			want:     bson.M{"$set": bson.M{"a": 1, "b": 2}},
+		},
+		{
+			name:     "update $set and $unset union",
+			dst:      bson.M{"$set": bson.M{"a": 1}},
+			src:      bson.M{"$unset": bson.M{"b": ""}, "$inc": bson.M{"count": 1}},
+			strategy: mongodb.MergeUpdate,
+			want:     bson.M{"$set": bson.M{"a": 1}, "$unset": bson.M{"b": ""}, "$inc": bson.M{"count": 1}},
+		},
+		{
+			name:     "update same leaf with same value",
+			dst:      bson.M{"$set": bson.M{"a": 1}},
+			src:      bson.M{"$set": bson.M{"a": 1}},
+			strategy: mongodb.MergeUpdate,
+			want:     bson.M{"$set": bson.M{"a": 1}},
+		},
+		{
+			name:     "update same leaf with different values",
+			dst:      bson.M{"$set": bson.M{"a": 1}},
+			src:      bson.M{"$set": bson.M{"a": 2}},
+			strategy: mongodb.MergeUpdate,
+			conflict: true,
+		},
+		{
+			name:     "update field set and unset",
+			dst:      bson.M{"$set": bson.M{"a": 1}},
+			src:      bson.M{"$unset": bson.M{"a": ""}},
+			strategy: mongodb.MergeUpdate,
+			conflict: true,
+		},
+		{
+			name:     "update parent and child paths",
+			dst:      bson.M{"$set": bson.M{"address": bson.M{"city": "Berlin"}}},
+			src:      bson.M{"$set": bson.M{"address.zip": "10115"}},
+			strategy: mongodb.MergeUpdate,
+			conflict: true,
+		},
+		{
+			name:     "update similar prefix is no conflict",
+			dst:      bson.M{"$set": bson.M{"address": "x"}},
+			src:      bson.M{"$set": bson.M{"addressLine": "y"}},
+			strategy: mongodb.MergeUpdate,
+			want:     bson.M{"$set": bson.M{"address": "x", "addressLine": "y"}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mongodb.MergeBSON(tt.dst, tt.src, tt.strategy)
+			if tt.conflict {
+				assert.ErrorIs(t, err, mongodb.ErrMergeConflict)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMergeBSONDoesNotModifyInputs(t *testing.T) {
+	dst := bson.M{"settings": bson.M{"theme": "dark"}}
+	src := bson.M{"settings": bson.M{"lang": "de"}}
+
+	_, err := mongodb.MergeBSON(dst, src, mongodb.MergeNested)
+	assert.NoError(t, err)
+	assert.Equal(t, bson.M{"settings": bson.M{"theme": "dark"}}, dst)
+	assert.Equal(t, bson.M{"settings": bson.M{"lang": "de"}}, src)
+}
+
+func TestMergeBSONInvalidUpdate(t *testing.T) {
+	_, err := mongodb.MergeBSON(bson.M{"$set": bson.M{"a": 1}}, bson.M{"b": 2}, mongodb.MergeUpdate)
+	assert.ErrorContains(t, err, "b is not an update operator")
+}
+
+func TestNewFilterWithFilter(t *testing.T) {
+	id := primitive.NewObjectID()
+	other := primitive.NewObjectID()
+
+	assert.Equal(t, bson.M{"companyID": other}, mongodb.NewFilter(mongodb.WithCompanyID(id), mongodb.WithCompanyID(other)))
+	assert.Equal(t, bson.M{"companyID": id}, mongodb.NewFilter(mongodb.WithCompanyID(id), mongodb.WithFilter(bson.M{"companyID": id})))
+	assert.Equal(t, bson.M{"$and": bson.A{bson.M{"companyID": id}, bson.M{"companyID": other}}},
+		mongodb.NewFilter(mongodb.WithCompanyID(id), mongodb.WithFilter(bson.M{"companyID": other})))
+}
+
+// notDeleted is a custom FilterOption that depends on the conditions of the previous options.
+type notDeleted struct{}
+
+func (notDeleted) Apply(m bson.M) {
+	if _, ok := m["deletedAt"]; !ok {
+		m["deletedAt"] = bson.M{"$exists": false}
+	}
+}
+
+func TestNewFilterAppliesOptionsToFilter(t *testing.T) {
+	assert.Equal(t, bson.M{"deletedAt": bson.M{"$exists": false}}, mongodb.NewFilter(notDeleted{}))
+	assert.Equal(t, bson.M{"deletedAt": bson.M{"$exists": true}},
+		mongodb.NewFilter(mongodb.WithFilter(bson.M{"deletedAt": bson.M{"$exists": true}}), notDeleted{}))
+}
@@ -29,16 +29,19 @@ type (
 // InitMongoID creates a new MongoID if the existing one is Zero value.
 func (b *BaseModel) InitMongoID() {
 	if b.MongoID.IsZero() {
-		b.MongoID = primitive.NewObjectID()
+		b.MongoID = newID()
 	}
 }
 
 // InitDocument inits a new Document so that it can be inserted into the DB.
 // A new MongoDB is generated, and the createdAt and updatedAt are set to the current date.
+//
+// The MongoID and the date are provided by the package-wide [IDGenerator] and [Clock].
 func (b *BaseModel) InitDocument() {
 	b.InitMongoID()
-	b.SetCreatedAt(time.Now())
-	b.SetUpdatedAt(time.Now())
+	timestamp := now()
+	b.SetCreatedAt(timestamp)
+	b.SetUpdatedAt(timestamp)
 }
 
 // Sets the MongoID to the zero value.
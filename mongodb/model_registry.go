@@ -0,0 +1,141 @@
+package mongodb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	// registeredModel is a document type registered with [RegisterModel], with the update paths that were already validated.
+	registeredModel struct {
+		t     reflect.Type
+		paths sync.Map // path string -> bool
+	}
+)
+
+var models = struct {
+	sync.RWMutex
+	byCollection map[string]*registeredModel
+}{byCollection: map[string]*registeredModel{}}
+
+// RegisterModel registers T as the document type of the collection, for the update validation of [WithFieldValidation].
+// Registering a collection again replaces the type. It is intended to be called at startup, e.g. in an init func,
+// and panics if T is not a struct or a pointer to a struct.
+func RegisterModel[T any](collectionName string) {
+	t := documentType[T]()
+	if _, err := structFields(t); err != nil {
+		panic(fmt.Sprintf("mongodb: registering model for %v: %v", collectionName, err))
+	}
+
+	models.Lock()
+	defer models.Unlock()
+	models.byCollection[collectionName] = &registeredModel{t: t}
+}
+
+// registeredModelFor returns the model registered for the collection, or nil.
+func registeredModelFor(collectionName string) *registeredModel {
+	models.RLock()
+	defer models.RUnlock()
+	return models.byCollection[collectionName]
+}
+
+type fieldValidationOption bool
+
+func (value fieldValidationOption) apply(o *repositoryOption) {
+	o.fieldValidation = bool(value)
+}
+
+// WithFieldValidation rejects UpdateOne, UpdateMany, UpdateManyResult, UpdateOneWhere and UpdateManyWhere calls
+// whose data contains a key that is not a field of the model registered for the collection with [RegisterModel],
+// with an error wrapping [ErrInvalidFieldPath]. Updates of collections without a registered model are not validated.
+//
+// Keys may be dotted paths into nested structs, and may contain array indexes and positional operators
+// for slices, e.g. "items.3.qty", "items.$.qty", "items.$[].qty" or "items.$[item].qty".
+// Fields of maps and interface values are not validated.
+func WithFieldValidation() RepositoryOption {
+	return fieldValidationOption(true)
+}
+
+// validateUpdateFields checks the keys of the update data against the registered model of the collection.
+func (r *Repository[T]) validateUpdateFields(data primitive.M) error {
+	if !r.fieldValidation {
+		return nil
+	}
+	model := registeredModelFor(r.db.Name())
+	if model == nil {
+		return nil
+	}
+
+	for key := range data {
+		if !model.hasPath(key) {
+			return fmt.Errorf("%w: %v has no field %q", ErrInvalidFieldPath, model.t, key)
+		}
+	}
+
+	return nil
+}
+
+func (m *registeredModel) hasPath(path string) bool {
+	if ok, cached := m.paths.Load(path); cached {
+		return ok.(bool)
+	}
+
+	ok := typeHasPath(m.t, strings.Split(path, "."))
+	m.paths.Store(path, ok)
+	return ok
+}
+
+// typeHasPath reports whether the bson path segments lead to a field of t.
+func typeHasPath(t reflect.Type, segments []string) bool {
+	for _, segment := range segments {
+		t = indirectType(t)
+
+		switch t.Kind() {
+		case reflect.Map, reflect.Interface:
+			return true
+		case reflect.Slice, reflect.Array:
+			if t.Elem().Kind() == reflect.Uint8 || !isArraySegment(segment) {
+				return false
+			}
+			t = t.Elem()
+			continue
+		case reflect.Struct:
+			if !IsSubdocumentType(t) {
+				return false
+			}
+		default:
+			return false
+		}
+
+		field, ok, err := lookupField(t, segment)
+		if err != nil || !ok {
+			return false
+		}
+		t = field.Field.Type
+	}
+
+	return true
+}
+
+// isArraySegment reports whether a path segment addresses array elements, with an index or a positional operator.
+func isArraySegment(segment string) bool {
+	if segment == "$" || segment == "$[]" {
+		return true
+	}
+	if strings.HasPrefix(segment, "$[") && strings.HasSuffix(segment, "]") {
+		return true
+	}
+	if segment == "" {
+		return false
+	}
+	for _, c := range segment {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
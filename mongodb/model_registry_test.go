@@ -0,0 +1,71 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestFieldValidationRejectsUnknownFields(t *testing.T) {
+	ctx := context.Background()
+	// Connect does not need a server, invalid updates are rejected before they are sent.
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	mongodb.RegisterModel[*Account]("account_field_validation_invalid")
+	repo := mongodb.NewRepository[*Account](client.Database("testdb").Collection("account_field_validation_invalid"), mongodb.WithFieldValidation()).(*mongodb.Repository[*Account])
+
+	for _, key := range []string{
+		"nmae",
+		"settings.flags.bta",
+		"settings.since.year",
+		"items.$.quantity",
+		"items.sku",
+		"Internal",
+	} {
+		_, err := repo.UpdateOne(ctx, bson.M{}, bson.M{key: 1})
+		assert.ErrorIs(t, err, mongodb.ErrInvalidFieldPath, key)
+	}
+
+	_, err = repo.UpdateManyResult(ctx, bson.M{}, bson.M{"name": "ok", "settings.renamed": "typo"})
+	assert.ErrorIs(t, err, mongodb.ErrInvalidFieldPath)
+	assert.Contains(t, err.Error(), `"settings.renamed"`)
+}
+
+func TestFieldValidation(t *testing.T) {
+	ctx := context.Background()
+	col := testCollection(t, "account_field_validation")
+	mongodb.RegisterModel[*Account]("account_field_validation")
+	repo := mongodb.NewRepository[*Account](col, mongodb.WithFieldValidation())
+
+	account, err := repo.InsertOne(ctx, &Account{Items: []*AccountItem{{SKU: "a"}, {SKU: "b"}}})
+	if err != nil {
+		t.Fatalf("Error inserting account: %v", err)
+	}
+	filter := bson.M{"_id": account.GetMongoID(), "items.sku": "a"}
+
+	for _, data := range []bson.M{
+		{"items.$.sku": "c"},
+		{"name": "alice", "settings.flags.beta": true, "settings.renamed_field": "x"},
+		{"items.1.sku": "d"},
+		{"items.$[].sku": "e"},
+		{"settings": bson.M{"since": nil}},
+	} {
+		_, err := repo.UpdateOne(ctx, filter, data)
+		assert.NoError(t, err, data)
+		filter = bson.M{"_id": account.GetMongoID()}
+	}
+
+	// Collections without a registered model are not validated.
+	unregistered := mongodb.NewRepository[*Account](testCollection(t, "account_field_validation_unregistered"), mongodb.WithFieldValidation())
+	err = unregistered.UpdateMany(ctx, bson.M{}, bson.M{"nmae": "typo"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,41 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ValueDocument implements Document with value receivers, so its methods can not modify the document.
+type ValueDocument struct {
+	Name string `bson:"name"`
+}
+
+func (d ValueDocument) InitMongoID()                     {}
+func (d ValueDocument) SetUpdatedAt(updatedAt time.Time) {}
+func (d ValueDocument) SetCreatedAt(createdAt time.Time) {}
+func (d ValueDocument) InitDocument()                    {}
+func (d ValueDocument) ResetMongoID()                    {}
+
+func TestNewRepositoryDocumentTypes(t *testing.T) {
+	ctx := context.Background()
+	// Connecting is lazy, no server is needed to build the repositories.
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+	col := client.Database("testdb").Collection("document_types")
+
+	assert.NotPanics(t, func() { mongodb.NewRepository[*User](col) })
+	assert.NotPanics(t, func() { mongodb.NewRepository[*ValueDocument](col) })
+	assert.PanicsWithValue(t,
+		"mongodb: document type mongodb_test.ValueDocument must be a pointer type, e.g. *ValueDocument, since InitDocument on a value only modifies a copy",
+		func() { mongodb.NewRepository[ValueDocument](col) },
+	)
+}
@@ -0,0 +1,150 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type (
+	// NumericStats are the statistics of a numeric field, see [FieldStats].
+	NumericStats struct {
+		// Count is the number of matching documents with a numeric value in the field.
+		Count int
+		Sum   float64
+		Avg   float64
+		Min   float64
+		Max   float64
+	}
+
+	numericGroup struct {
+		Count int           `bson:"count"`
+		Sum   bson.RawValue `bson:"sum"`
+		Avg   bson.RawValue `bson:"avg"`
+		Min   bson.RawValue `bson:"min"`
+		Max   bson.RawValue `bson:"max"`
+	}
+)
+
+// FieldStats returns the count, sum, average, minimum and maximum of the numeric field of the documents matching filter,
+// with a single $group. field can be a dotted path.
+//
+// Documents where the field is missing or not a number are ignored. If no document has a number in the field,
+// all statistics are 0. The values are computed by the server in the type of the stored values, e.g. as Decimal128
+// if any value is a Decimal128, and are then converted to float64: sums of int64 above 2^53 and decimals with more than
+// about 15 significant digits lose precision, use [SumFieldDecimal] for exact sums.
+func FieldStats(ctx context.Context, r Aggregater, filter bson.M, field string) (NumericStats, error) {
+	group, err := numericGroupOf(ctx, r, filter, field, bson.M{
+		"_id":   nil,
+		"count": bson.M{"$sum": 1},
+		"sum":   bson.M{"$sum": "$" + field},
+		"avg":   bson.M{"$avg": "$" + field},
+		"min":   bson.M{"$min": "$" + field},
+		"max":   bson.M{"$max": "$" + field},
+	})
+	if err != nil {
+		return NumericStats{}, fmt.Errorf("%v: %w", "mongodb.FieldStats", err)
+	}
+
+	stats := NumericStats{Count: group.Count}
+	for _, value := range []struct {
+		raw bson.RawValue
+		dst *float64
+	}{{group.Sum, &stats.Sum}, {group.Avg, &stats.Avg}, {group.Min, &stats.Min}, {group.Max, &stats.Max}} {
+		if *value.dst, err = numberAsFloat(value.raw); err != nil {
+			return NumericStats{}, fmt.Errorf("%v: %w", "mongodb.FieldStats", err)
+		}
+	}
+
+	return stats, nil
+}
+
+// SumField returns the sum of the numeric field of the documents matching filter, see [FieldStats] for the float64 conversion.
+func SumField(ctx context.Context, r Aggregater, filter bson.M, field string) (float64, error) {
+	stats, err := FieldStats(ctx, r, filter, field)
+	return stats.Sum, err
+}
+
+// AvgField returns the average of the numeric field of the documents matching filter, see [FieldStats].
+func AvgField(ctx context.Context, r Aggregater, filter bson.M, field string) (float64, error) {
+	stats, err := FieldStats(ctx, r, filter, field)
+	return stats.Avg, err
+}
+
+// MinField returns the minimum of the numeric field of the documents matching filter, see [FieldStats].
+func MinField(ctx context.Context, r Aggregater, filter bson.M, field string) (float64, error) {
+	stats, err := FieldStats(ctx, r, filter, field)
+	return stats.Min, err
+}
+
+// MaxField returns the maximum of the numeric field of the documents matching filter, see [FieldStats].
+func MaxField(ctx context.Context, r Aggregater, filter bson.M, field string) (float64, error) {
+	stats, err := FieldStats(ctx, r, filter, field)
+	return stats.Max, err
+}
+
+// SumFieldDecimal is like [SumField], but converts every value to Decimal128 on the server before summing it,
+// so that the sum of amounts stored as Decimal128 or int64 is exact.
+func SumFieldDecimal(ctx context.Context, r Aggregater, filter bson.M, field string) (primitive.Decimal128, error) {
+	group, err := numericGroupOf(ctx, r, filter, field, bson.M{
+		"_id": nil,
+		"sum": bson.M{"$sum": bson.M{"$toDecimal": "$" + field}},
+	})
+	if err != nil {
+		return primitive.Decimal128{}, fmt.Errorf("%v: %w", "mongodb.SumFieldDecimal", err)
+	}
+
+	sum, ok := group.Sum.Decimal128OK()
+	if !ok {
+		// 0 with an exponent of 0, like the result of primitive.ParseDecimal128("0").
+		return primitive.NewDecimal128(0x3040000000000000, 0), nil
+	}
+	return sum, nil
+}
+
+// numericGroupOf groups the documents matching filter that have a number in field, and returns the zero group if there are none.
+func numericGroupOf(ctx context.Context, r Aggregater, filter bson.M, field string, group bson.M) (numericGroup, error) {
+	match, err := MergeBSON(filter, bson.M{field: bson.M{"$type": "number"}}, MergeFilter)
+	if err != nil {
+		return numericGroup{}, err
+	}
+
+	cur, err := r.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: group}},
+	})
+	if err != nil {
+		return numericGroup{}, err
+	}
+
+	var groups []numericGroup
+	if err := cur.All(ctx, &groups); err != nil {
+		return numericGroup{}, err
+	}
+	if len(groups) == 0 {
+		return numericGroup{}, nil
+	}
+	return groups[0], nil
+}
+
+// numberAsFloat converts a numeric value to float64, and returns 0 for missing and null values.
+func numberAsFloat(v bson.RawValue) (float64, error) {
+	switch v.Type {
+	case 0, bsontype.Null:
+		return 0, nil
+	case bsontype.Double:
+		return v.Double(), nil
+	case bsontype.Int32:
+		return float64(v.Int32()), nil
+	case bsontype.Int64:
+		return float64(v.Int64()), nil
+	case bsontype.Decimal128:
+		return strconv.ParseFloat(v.Decimal128().String(), 64)
+	}
+	return 0, fmt.Errorf("%v is not a number", v.Type)
+}
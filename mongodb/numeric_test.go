@@ -0,0 +1,71 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFieldStats(t *testing.T) {
+	ctx := context.Background()
+	col := testCollection(t, "invoice_field_stats")
+
+	companyA, companyB := primitive.NewObjectID(), primitive.NewObjectID()
+	cents, _ := primitive.ParseDecimal128("0.10")
+	_, err := col.InsertMany(ctx, []interface{}{
+		bson.M{"companyID": companyA, "amount": int32(10)},
+		bson.M{"companyID": companyA, "amount": int64(20)},
+		bson.M{"companyID": companyA, "amount": 2.5},
+		bson.M{"companyID": companyA, "amount": cents},
+		bson.M{"companyID": companyA},
+		bson.M{"companyID": companyA, "amount": "n/a"},
+		bson.M{"companyID": companyB, "amount": int64(1000)},
+	})
+	if err != nil {
+		t.Fatalf("Error inserting invoices: %v", err)
+	}
+	repo := mongodb.NewRepository[*User](col)
+	filter := mongodb.CompanyIDFilter(companyA)
+
+	stats, err := mongodb.FieldStats(ctx, repo, filter, "amount")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, stats.Count)
+	assert.InDelta(t, 32.6, stats.Sum, 1e-9)
+	assert.InDelta(t, 8.15, stats.Avg, 1e-9)
+	assert.InDelta(t, 0.1, stats.Min, 1e-9)
+	assert.InDelta(t, 20, stats.Max, 1e-9)
+
+	sum, err := mongodb.SumField(ctx, repo, filter, "amount")
+	assert.NoError(t, err)
+	assert.InDelta(t, 32.6, sum, 1e-9)
+
+	maxAmount, err := mongodb.MaxField(ctx, repo, nil, "amount")
+	assert.NoError(t, err)
+	assert.InDelta(t, 1000, maxAmount, 1e-9)
+
+	// Doubles are converted with 15 significant digits, the integers and decimals are summed exactly.
+	exact, err := mongodb.SumFieldDecimal(ctx, repo, bson.M{"companyID": companyA, "amount": bson.M{"$not": bson.M{"$type": "double"}}}, "amount")
+	assert.NoError(t, err)
+	assert.Equal(t, "30.10", exact.String())
+}
+
+func TestFieldStatsEmpty(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "invoice_field_stats_empty"))
+
+	stats, err := mongodb.FieldStats(ctx, repo, bson.M{}, "amount")
+	assert.NoError(t, err)
+	assert.Equal(t, mongodb.NumericStats{}, stats)
+
+	avg, err := mongodb.AvgField(ctx, repo, nil, "amount")
+	assert.NoError(t, err)
+	assert.Zero(t, avg)
+
+	sum, err := mongodb.SumFieldDecimal(ctx, repo, nil, "amount")
+	assert.NoError(t, err)
+	assert.Equal(t, "0", sum.String())
+}
@@ -0,0 +1,265 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+type (
+	// OpOption is a driver option that applies to every kind of operation, see [MaxTime], [Comment], [ReadConcern] and [WriteConcern].
+	//
+	// OpOptions are attached to a context with [WithOpOptions]. MaxTime and Comment are translated by FindOne, FindMany, FindManyN,
+	// Aggregate and CountDocuments into the driver options of the operation. Driver options passed to the method take precedence.
+	// To pass them as driver options directly, use [FindOptions], [FindOneOptions], [AggregateOptions] and [CountOptions].
+	OpOption interface {
+		apply(*opOption)
+	}
+)
+
+type (
+	opOption struct {
+		maxTime      *time.Duration
+		comment      *string
+		readConcern  *readconcern.ReadConcern
+		writeConcern *writeconcern.WriteConcern
+	}
+)
+
+type opOptionsKey struct{}
+
+// maxRequestTagLength caps the part of a comment that [WithRequestTagging] takes from the context, in bytes.
+const maxRequestTagLength = 128
+
+type maxTimeOption time.Duration
+
+func (value maxTimeOption) apply(o *opOption) {
+	d := time.Duration(value)
+	o.maxTime = &d
+}
+
+// MaxTime caps the server-side execution time of an operation.
+func MaxTime(d time.Duration) OpOption {
+	return maxTimeOption(d)
+}
+
+type commentOption string
+
+func (value commentOption) apply(o *opOption) {
+	s := string(value)
+	o.comment = &s
+}
+
+// Comment tags an operation, so that it can be identified in the currentOp, profiler and log output of the server.
+func Comment(s string) OpOption {
+	return commentOption(s)
+}
+
+type defaultCommentOption string
+
+func (value defaultCommentOption) apply(o *repositoryOption) {
+	o.defaultComment = string(value)
+}
+
+// WithDefaultComment sets the comment of all read operations of the repository that have no [Comment],
+// e.g. the name of the calling service.
+func WithDefaultComment(comment string) RepositoryOption {
+	return defaultCommentOption(comment)
+}
+
+type requestTaggingOption func(ctx context.Context) string
+
+func (value requestTaggingOption) apply(o *repositoryOption) {
+	o.requestTag = value
+}
+
+// WithRequestTagging tags every operation of the repository with a value of its context, e.g. a request or trace ID,
+// so that the currentOp, profiler and log output of the server show which request issued a query.
+//
+// The value returned by extract is appended to the comment of the operation, after a [Comment] of the context or the comment
+// of [WithDefaultComment], separated by a space. Unlike the default comment, it is also set on UpdateOne, UpdateMany and UpdateManyResult.
+// Values longer than 128 bytes are cut, and nothing is appended if extract returns "".
+//
+//	repo := mongodb.NewRepository[*Invoice](col, mongodb.WithDefaultComment("billing"), mongodb.WithRequestTagging(requestID))
+//	// The profiler shows the comment "billing 7f3c9a".
+func WithRequestTagging(extract func(ctx context.Context) string) RepositoryOption {
+	return requestTaggingOption(extract)
+}
+
+// WithOpOptions returns a copy of ctx with the given options attached, in addition to the options already attached to ctx.
+//
+//	ctx = mongodb.WithOpOptions(ctx, mongodb.MaxTime(2*time.Second), mongodb.Comment("billing.monthlyReport"))
+//	invoices, err := repo.FindMany(ctx, filter)
+func WithOpOptions(ctx context.Context, opts ...OpOption) context.Context {
+	ops := opOptionsFrom(ctx, "")
+	for _, opt := range opts {
+		opt.apply(&ops)
+	}
+
+	return context.WithValue(ctx, opOptionsKey{}, ops)
+}
+
+// opOptionsFrom returns the options attached to ctx, with comment as comment if none is attached.
+func opOptionsFrom(ctx context.Context, comment string) opOption {
+	ops, _ := ctx.Value(opOptionsKey{}).(opOption)
+	if ops.comment == nil && comment != "" {
+		ops.comment = &comment
+	}
+	return ops
+}
+
+// contextOptions returns the options attached to ctx with the comment of the repository, see [WithDefaultComment] and [WithRequestTagging].
+func (r *Repository[T]) contextOptions(ctx context.Context) opOption {
+	ops := opOptionsFrom(ctx, r.defaultComment)
+	if r.requestTag == nil {
+		return ops
+	}
+
+	tag := truncateUTF8(r.requestTag(ctx), maxRequestTagLength)
+	if tag == "" {
+		return ops
+	}
+	if ops.comment != nil && *ops.comment != "" {
+		tag = *ops.comment + " " + tag
+	}
+	ops.comment = &tag
+	return ops
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func newOpOption(opts []OpOption) opOption {
+	var ops opOption
+	for _, opt := range opts {
+		opt.apply(&ops)
+	}
+	return ops
+}
+
+// FindOptions translates opts into the driver options of Find.
+func FindOptions(opts ...OpOption) *options.FindOptions {
+	return newOpOption(opts).find()
+}
+
+// FindOneOptions translates opts into the driver options of FindOne.
+func FindOneOptions(opts ...OpOption) *options.FindOneOptions {
+	return newOpOption(opts).findOne()
+}
+
+// AggregateOptions translates opts into the driver options of Aggregate.
+func AggregateOptions(opts ...OpOption) *options.AggregateOptions {
+	return newOpOption(opts).aggregate()
+}
+
+// CountOptions translates opts into the driver options of CountDocuments.
+func CountOptions(opts ...OpOption) *options.CountOptions {
+	return newOpOption(opts).count()
+}
+
+// isZero reports whether none of the options translate into driver options of an operation.
+func (o opOption) isZero() bool {
+	return o.maxTime == nil && o.comment == nil
+}
+
+func (o opOption) find() *options.FindOptions {
+	res := options.Find()
+	if o.maxTime != nil {
+		res.SetMaxTime(*o.maxTime)
+	}
+	if o.comment != nil {
+		res.SetComment(*o.comment)
+	}
+	return res
+}
+
+func (o opOption) findOne() *options.FindOneOptions {
+	res := options.FindOne()
+	if o.maxTime != nil {
+		res.SetMaxTime(*o.maxTime)
+	}
+	if o.comment != nil {
+		res.SetComment(*o.comment)
+	}
+	return res
+}
+
+func (o opOption) aggregate() *options.AggregateOptions {
+	res := options.Aggregate()
+	if o.maxTime != nil {
+		res.SetMaxTime(*o.maxTime)
+	}
+	if o.comment != nil {
+		res.SetComment(*o.comment)
+	}
+	return res
+}
+
+func (o opOption) count() *options.CountOptions {
+	res := options.Count()
+	if o.maxTime != nil {
+		res.SetMaxTime(*o.maxTime)
+	}
+	if o.comment != nil {
+		res.SetComment(*o.comment)
+	}
+	return res
+}
+
+func (o opOption) update() *options.UpdateOptions {
+	res := options.Update()
+	if o.comment != nil {
+		res.SetComment(*o.comment)
+	}
+	return res
+}
+
+// The following put the options of ctx in front of opts, so that opts take precedence when the driver merges them.
+
+func (r *Repository[T]) findOptions(ctx context.Context, opts []*options.FindOptions) []*options.FindOptions {
+	ops := r.opOptions(ctx)
+	if ops.isZero() {
+		return opts
+	}
+	return append([]*options.FindOptions{ops.find()}, opts...)
+}
+
+func (r *Repository[T]) findOneOptions(ctx context.Context, opts []*options.FindOneOptions) []*options.FindOneOptions {
+	ops := r.opOptions(ctx)
+	if ops.isZero() {
+		return opts
+	}
+	return append([]*options.FindOneOptions{ops.findOne()}, opts...)
+}
+
+func (r *Repository[T]) countOptions(ctx context.Context, opts []*options.CountOptions) []*options.CountOptions {
+	ops := r.opOptions(ctx)
+	if ops.isZero() {
+		return opts
+	}
+	return append([]*options.CountOptions{ops.count()}, opts...)
+}
+
+// updateOptions only sets the comment of [WithRequestTagging], updates get no other options of ctx.
+func (r *Repository[T]) updateOptions(ctx context.Context, opts []*options.UpdateOptions) []*options.UpdateOptions {
+	if r.requestTag == nil {
+		return opts
+	}
+	ops := r.contextOptions(ctx)
+	if ops.comment == nil {
+		return opts
+	}
+	return append([]*options.UpdateOptions{ops.update()}, opts...)
+}
@@ -0,0 +1,161 @@
+package mongodb_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestOpOptionAdapters(t *testing.T) {
+	opts := []mongodb.OpOption{mongodb.MaxTime(2 * time.Second), mongodb.Comment("billing")}
+
+	find := mongodb.FindOptions(opts...)
+	assert.Equal(t, 2*time.Second, *find.MaxTime)
+	assert.Equal(t, "billing", *find.Comment)
+
+	findOne := mongodb.FindOneOptions(opts...)
+	assert.Equal(t, 2*time.Second, *findOne.MaxTime)
+	assert.Equal(t, "billing", *findOne.Comment)
+
+	aggregate := mongodb.AggregateOptions(opts...)
+	assert.Equal(t, 2*time.Second, *aggregate.MaxTime)
+	assert.Equal(t, "billing", *aggregate.Comment)
+
+	count := mongodb.CountOptions(opts...)
+	assert.Equal(t, 2*time.Second, *count.MaxTime)
+	assert.Equal(t, "billing", *count.Comment)
+
+	assert.Nil(t, mongodb.FindOptions().MaxTime)
+	assert.Nil(t, mongodb.FindOptions().Comment)
+}
+
+func TestOpOptionsFromContext(t *testing.T) {
+	var mu sync.Mutex
+	commands := map[string]bson.Raw{}
+	monitor := &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			commands[e.CommandName] = e.Command
+		},
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017").SetMonitor(monitor))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	col := client.Database("testdb").Collection("user_op_options")
+	t.Cleanup(func() {
+		col.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+	repo := mongodb.NewRepository[*User](col, mongodb.WithDefaultComment("user-service"))
+
+	opCtx := mongodb.WithOpOptions(ctx, mongodb.MaxTime(3*time.Second), mongodb.Comment("report"))
+	_, err = repo.FindMany(opCtx, bson.M{})
+	assert.NoError(t, err)
+	_, err = repo.Aggregate(opCtx, mongo.Pipeline{})
+	assert.NoError(t, err)
+	_, err = repo.CountDocuments(opCtx, bson.M{}, options.Count().SetMaxTime(time.Second))
+	assert.NoError(t, err)
+
+	mu.Lock()
+	assert.Equal(t, int64(3000), commands["find"].Lookup("maxTimeMS").AsInt64())
+	assert.Equal(t, "report", commands["find"].Lookup("comment").StringValue())
+	assert.Equal(t, int64(3000), commands["aggregate"].Lookup("maxTimeMS").AsInt64())
+	assert.Equal(t, "report", commands["aggregate"].Lookup("comment").StringValue())
+	mu.Unlock()
+
+	// Without a comment in the context, the repository default is used.
+	_, err = repo.FindOne(ctx, bson.M{})
+	assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+
+	mu.Lock()
+	assert.Equal(t, "user-service", commands["find"].Lookup("comment").StringValue())
+	_, hasMaxTime := commands["find"].Lookup("maxTimeMS").Int64OK()
+	assert.False(t, hasMaxTime)
+	mu.Unlock()
+}
+
+type requestIDKey struct{}
+
+func requestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func TestRequestTagging(t *testing.T) {
+	var mu sync.Mutex
+	commands := map[string]bson.Raw{}
+	monitor := &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			commands[e.CommandName] = e.Command
+		},
+	}
+	comment := func(command string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		value, ok := commands[command].Lookup("comment").StringValueOK()
+		if !ok {
+			return "<none>"
+		}
+		return value
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017").SetMonitor(monitor))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	col := client.Database("testdb").Collection("user_request_tagging")
+	t.Cleanup(func() {
+		col.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+	repo := mongodb.NewRepository[*User](col, mongodb.WithDefaultComment("user-service"), mongodb.WithRequestTagging(requestID))
+
+	reqCtx := context.WithValue(ctx, requestIDKey{}, "req-1")
+	_, err = repo.FindMany(reqCtx, bson.M{})
+	assert.NoError(t, err)
+	_, err = repo.Aggregate(reqCtx, mongo.Pipeline{})
+	assert.NoError(t, err)
+	_, err = repo.CountDocuments(reqCtx, bson.M{})
+	assert.NoError(t, err)
+	_, err = repo.UpdateOne(reqCtx, bson.M{"name": "nobody"}, bson.M{"name": "x"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "user-service req-1", comment("find"))
+	assert.Equal(t, "user-service req-1", comment("aggregate"))
+	assert.Equal(t, "user-service req-1", comment("count"))
+	assert.Equal(t, "user-service req-1", comment("update"))
+
+	// Every call takes the tag of its own context, after a Comment of the context.
+	reqCtx = mongodb.WithOpOptions(context.WithValue(ctx, requestIDKey{}, "req-2"), mongodb.Comment("report"))
+	_, err = repo.FindOne(reqCtx, bson.M{})
+	assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	assert.Equal(t, "report req-2", comment("find"))
+
+	// Long tags are cut, empty tags omitted.
+	_, err = repo.FindMany(context.WithValue(ctx, requestIDKey{}, strings.Repeat("x", 500)), bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, "user-service "+strings.Repeat("x", 128), comment("find"))
+
+	_, err = repo.FindMany(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, "user-service", comment("find"))
+	_, err = repo.UpdateOne(ctx, bson.M{"name": "nobody"}, bson.M{"name": "x"})
+	assert.NoError(t, err)
+	assert.Equal(t, "<none>", comment("update"))
+}
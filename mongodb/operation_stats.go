@@ -0,0 +1,320 @@
+package mongodb
+
+import (
+	"context"
+	"math/bits"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// defaultSlowestQueries is the number of slowest queries [WithStats] keeps.
+	defaultSlowestQueries = 10
+	// statsShards spreads the counters of an operation, so that concurrent operations rarely write the same cache line.
+	statsShards = 8
+	// latencyBuckets are powers of two of microseconds, the last bucket holds all durations above 2^30µs (about 18 minutes).
+	latencyBuckets = 32
+)
+
+type (
+	// OperationStatsReporter is implemented by [Repository], see [WithStats].
+	//
+	// It is not part of [RepositoryI], since statistics are only collected by repositories created with WithStats.
+	OperationStatsReporter interface {
+		// Returns a snapshot of the statistics collected so far.
+		OperationStats() RepositoryStats
+		// Discards the statistics collected so far.
+		ResetOperationStats()
+	}
+
+	// RepositoryStats is a snapshot of the operations of a repository since it was created or reset, see [WithStats].
+	RepositoryStats struct {
+		// Since is the time the statistics were started or last reset.
+		Since time.Time
+		// Operations are the statistics per repository method, e.g. "FindOne".
+		Operations map[string]OperationStats
+		// Slowest are the slowest operations, the slowest first.
+		Slowest []SlowQuery
+	}
+
+	// OperationStats are the statistics of a single repository method.
+	//
+	// The percentiles are the upper bounds of histogram buckets, which are powers of two of microseconds,
+	// so they overestimate the actual durations by less than a factor of two.
+	OperationStats struct {
+		Count  int64
+		Errors int64
+		P50    time.Duration
+		P90    time.Duration
+		P99    time.Duration
+		Max    time.Duration
+		// LastError is the error of the last failed operation, and LastErrorAt the time it finished.
+		LastError   error
+		LastErrorAt time.Time
+	}
+
+	// SlowQuery is a single slow operation. Filter is the Extended JSON of the filter with all values replaced by "?".
+	SlowQuery struct {
+		Operation string
+		Duration  time.Duration
+		Filter    string
+		At        time.Time
+	}
+)
+
+type (
+	statsCollector struct {
+		// slowThreshold is the duration of the fastest of the kept slow queries once slowest are kept, in nanoseconds.
+		// It is the first field, so that it is 64-bit aligned for the atomic operations on 32-bit platforms.
+		slowThreshold int64
+
+		started    atomic.Value // time.Time
+		operations sync.Map     // operation name -> *operationCounters
+		slowest    int
+
+		slowMu sync.Mutex
+		slow   []SlowQuery
+	}
+
+	operationCounters struct {
+		shards [statsShards]counterShard
+
+		errMu       sync.Mutex
+		lastError   error
+		lastErrorAt time.Time
+	}
+
+	counterShard struct {
+		count   int64
+		errors  int64
+		max     int64
+		buckets [latencyBuckets]int64
+		// Padding, so that neighbouring shards do not share a cache line.
+		_ [64]byte
+	}
+)
+
+type statsOption int
+
+func (value statsOption) apply(o *repositoryOption) {
+	o.stats = newStatsCollector(int(value))
+	o.hooks = append(o.hooks, o.stats)
+}
+
+// WithStats collects statistics of all operations of the repository, see [Repository.OperationStats]:
+// the number of calls and errors, latency percentiles and the last error per method, and the 10 slowest operations.
+//
+// The counters are updated atomically, so the overhead is small even under heavy concurrency.
+// Without WithStats, no statistics are collected at all.
+func WithStats() RepositoryOption {
+	return statsOption(defaultSlowestQueries)
+}
+
+// OperationStats returns a snapshot of the statistics collected by [WithStats], the zero value without it.
+func (r *Repository[T]) OperationStats() RepositoryStats {
+	if r.stats == nil {
+		return RepositoryStats{}
+	}
+	return r.stats.snapshot()
+}
+
+// ResetOperationStats discards the statistics collected by [WithStats] so far.
+func (r *Repository[T]) ResetOperationStats() {
+	if r.stats != nil {
+		r.stats.reset()
+	}
+}
+
+func newStatsCollector(slowest int) *statsCollector {
+	c := &statsCollector{slowest: slowest}
+	c.reset()
+	return c
+}
+
+func (c *statsCollector) Before(ctx context.Context, op *Operation) (context.Context, error) {
+	return ctx, nil
+}
+
+func (c *statsCollector) After(_ context.Context, op *Operation, err error) error {
+	duration := time.Since(op.Started)
+
+	counters, ok := c.operations.Load(op.Name)
+	if !ok {
+		counters, _ = c.operations.LoadOrStore(op.Name, &operationCounters{})
+	}
+	counters.(*operationCounters).record(duration, err)
+
+	if c.slowest > 0 && int64(duration) > atomic.LoadInt64(&c.slowThreshold) {
+		c.recordSlow(op, duration)
+	}
+	return err
+}
+
+func (c *statsCollector) recordSlow(op *Operation, duration time.Duration) {
+	query := SlowQuery{Operation: op.Name, Duration: duration, Filter: redactedFilter(op.Filter), At: time.Now()}
+
+	c.slowMu.Lock()
+	defer c.slowMu.Unlock()
+
+	if len(c.slow) == c.slowest {
+		if duration <= c.slow[len(c.slow)-1].Duration {
+			return
+		}
+		c.slow = c.slow[:len(c.slow)-1]
+	}
+	i := sort.Search(len(c.slow), func(i int) bool { return c.slow[i].Duration < duration })
+	c.slow = append(c.slow, SlowQuery{})
+	copy(c.slow[i+1:], c.slow[i:])
+	c.slow[i] = query
+
+	if len(c.slow) == c.slowest {
+		atomic.StoreInt64(&c.slowThreshold, int64(c.slow[len(c.slow)-1].Duration))
+	}
+}
+
+func (c *statsCollector) reset() {
+	c.started.Store(time.Now())
+	c.operations.Range(func(key, _ interface{}) bool {
+		c.operations.Delete(key)
+		return true
+	})
+
+	c.slowMu.Lock()
+	c.slow = nil
+	atomic.StoreInt64(&c.slowThreshold, 0)
+	c.slowMu.Unlock()
+}
+
+func (c *statsCollector) snapshot() RepositoryStats {
+	res := RepositoryStats{Since: c.started.Load().(time.Time), Operations: map[string]OperationStats{}}
+	c.operations.Range(func(key, value interface{}) bool {
+		res.Operations[key.(string)] = value.(*operationCounters).snapshot()
+		return true
+	})
+
+	c.slowMu.Lock()
+	res.Slowest = append([]SlowQuery(nil), c.slow...)
+	c.slowMu.Unlock()
+
+	return res
+}
+
+func (o *operationCounters) record(duration time.Duration, err error) {
+	shard := &o.shards[rand.Intn(statsShards)]
+	atomic.AddInt64(&shard.count, 1)
+	atomic.AddInt64(&shard.buckets[latencyBucket(duration)], 1)
+	for {
+		max := atomic.LoadInt64(&shard.max)
+		if int64(duration) <= max || atomic.CompareAndSwapInt64(&shard.max, max, int64(duration)) {
+			break
+		}
+	}
+
+	if err != nil {
+		atomic.AddInt64(&shard.errors, 1)
+		o.errMu.Lock()
+		o.lastError = err
+		o.lastErrorAt = time.Now()
+		o.errMu.Unlock()
+	}
+}
+
+func (o *operationCounters) snapshot() OperationStats {
+	var res OperationStats
+	var buckets [latencyBuckets]int64
+	for i := range o.shards {
+		shard := &o.shards[i]
+		res.Count += atomic.LoadInt64(&shard.count)
+		res.Errors += atomic.LoadInt64(&shard.errors)
+		if max := time.Duration(atomic.LoadInt64(&shard.max)); max > res.Max {
+			res.Max = max
+		}
+		for b := range buckets {
+			buckets[b] += atomic.LoadInt64(&shard.buckets[b])
+		}
+	}
+
+	res.P50 = percentile(buckets, 0.5, res.Max)
+	res.P90 = percentile(buckets, 0.9, res.Max)
+	res.P99 = percentile(buckets, 0.99, res.Max)
+
+	o.errMu.Lock()
+	res.LastError = o.lastError
+	res.LastErrorAt = o.lastErrorAt
+	o.errMu.Unlock()
+
+	return res
+}
+
+// latencyBucket returns the index of the smallest power of two of microseconds that is at least duration.
+func latencyBucket(duration time.Duration) int {
+	micros := uint64(duration / time.Microsecond)
+	if micros <= 1 {
+		return 0
+	}
+	if bucket := bits.Len64(micros - 1); bucket < latencyBuckets {
+		return bucket
+	}
+	return latencyBuckets - 1
+}
+
+// percentile returns the upper bound of the bucket that contains the given quantile, at most max.
+func percentile(buckets [latencyBuckets]int64, quantile float64, max time.Duration) time.Duration {
+	var total int64
+	for _, n := range buckets {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := int64(quantile*float64(total-1)) + 1
+	var seen int64
+	for i, n := range buckets {
+		seen += n
+		if seen >= rank {
+			if bound := time.Duration(1<<i) * time.Microsecond; bound < max {
+				return bound
+			}
+			return max
+		}
+	}
+	return max
+}
+
+// redactedFilter returns the Extended JSON of filter with all values replaced by "?", so that it can be kept without the data it contains.
+func redactedFilter(filter interface{}) string {
+	if filter == nil {
+		return ""
+	}
+	data, err := bson.MarshalExtJSON(bson.D{{Key: "filter", Value: redact(sortedKeys(filter))}}, false, false)
+	if err != nil {
+		return ""
+	}
+	// Strip the wrapping document, which is only needed because filters can be arrays.
+	return string(data[len(`{"filter":`) : len(data)-1])
+}
+
+func redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case primitive.D:
+		res := make(primitive.D, len(v))
+		for i, e := range v {
+			res[i] = primitive.E{Key: e.Key, Value: redact(e.Value)}
+		}
+		return res
+	case primitive.A:
+		res := make(primitive.A, len(v))
+		for i, e := range v {
+			res[i] = redact(e)
+		}
+		return res
+	}
+	return "?"
+}
@@ -0,0 +1,91 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var errRejected = errors.New("rejected")
+
+// rejectingHook rejects every operation after sleeping for the duration in the "delay" field of its filter,
+// so that no server is needed.
+type rejectingHook struct{}
+
+func (rejectingHook) Before(ctx context.Context, op *mongodb.Operation) (context.Context, error) {
+	if filter, ok := op.Filter.(bson.M); ok {
+		if delay, ok := filter["delay"].(time.Duration); ok {
+			time.Sleep(delay)
+		}
+	}
+	return ctx, errRejected
+}
+
+func (rejectingHook) After(ctx context.Context, op *mongodb.Operation, err error) error {
+	return err
+}
+
+func TestOperationStats(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	col := client.Database("testdb").Collection("user_operation_stats")
+	repo := mongodb.NewRepository[*User](col, mongodb.WithStats(), mongodb.WithHook(rejectingHook{})).(*mongodb.Repository[*User])
+
+	const workers, calls = 8, 100
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < calls; j++ {
+				repo.FindOne(ctx, bson.M{"name": "alice"})
+				repo.CountDocuments(ctx, bson.M{})
+			}
+		}()
+	}
+	wg.Wait()
+
+	_, err = repo.FindMany(ctx, bson.M{"delay": 20 * time.Millisecond, "companyID": "secret"})
+	assert.ErrorIs(t, err, errRejected)
+
+	stats := repo.OperationStats()
+	assert.Equal(t, int64(workers*calls), stats.Operations["FindOne"].Count)
+	assert.Equal(t, int64(workers*calls), stats.Operations["FindOne"].Errors)
+	assert.Equal(t, int64(workers*calls), stats.Operations["CountDocuments"].Count)
+	assert.ErrorIs(t, stats.Operations["CountDocuments"].LastError, errRejected)
+
+	findMany := stats.Operations["FindMany"]
+	assert.Equal(t, int64(1), findMany.Count)
+	assert.GreaterOrEqual(t, findMany.Max, 20*time.Millisecond)
+	assert.GreaterOrEqual(t, findMany.P50, 20*time.Millisecond)
+	assert.LessOrEqual(t, findMany.P99, findMany.Max)
+
+	if assert.Len(t, stats.Slowest, 10) {
+		assert.Equal(t, "FindMany", stats.Slowest[0].Operation)
+		assert.Equal(t, `{"companyID":"?","delay":"?"}`, stats.Slowest[0].Filter)
+		for i := 1; i < len(stats.Slowest); i++ {
+			assert.GreaterOrEqual(t, stats.Slowest[i-1].Duration, stats.Slowest[i].Duration)
+		}
+	}
+
+	repo.ResetOperationStats()
+	stats = repo.OperationStats()
+	assert.Empty(t, stats.Operations)
+	assert.Empty(t, stats.Slowest)
+
+	plain := mongodb.NewRepository[*User](col).(*mongodb.Repository[*User])
+	assert.Equal(t, mongodb.RepositoryStats{}, plain.OperationStats())
+}
@@ -0,0 +1,190 @@
+package mongodb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type optionalState uint8
+
+const (
+	optionalAbsent optionalState = iota
+	optionalSet
+	optionalNull
+	optionalUnset
+)
+
+// Optional is a field of a partial update, e.g. the request body of a PATCH endpoint, that distinguishes
+// a field that was not passed from a field that was set to null, see [SetFromStruct].
+//
+// The zero value is absent. Decoding JSON or BSON sets it to null for a null value and to set for any other value,
+// a field missing from the input stays absent. [OptionalUnset] can only be created in code.
+type Optional[T any] struct {
+	value T
+	state optionalState
+}
+
+// optional is implemented by every [Optional], so that [SetFromStruct] can read them without knowing T.
+type optional interface {
+	optionalValue() (optionalState, interface{})
+}
+
+// OptionalOf returns an Optional that sets the field to value.
+func OptionalOf[T any](value T) Optional[T] {
+	return Optional[T]{value: value, state: optionalSet}
+}
+
+// OptionalNull returns an Optional that sets the field to null.
+func OptionalNull[T any]() Optional[T] {
+	return Optional[T]{state: optionalNull}
+}
+
+// OptionalUnset returns an Optional that removes the field from the document.
+func OptionalUnset[T any]() Optional[T] {
+	return Optional[T]{state: optionalUnset}
+}
+
+// Get returns the value and true if the Optional sets a value.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.state == optionalSet
+}
+
+// IsSet reports whether the Optional sets a value.
+func (o Optional[T]) IsSet() bool {
+	return o.state == optionalSet
+}
+
+// IsNull reports whether the Optional sets the field to null.
+func (o Optional[T]) IsNull() bool {
+	return o.state == optionalNull
+}
+
+// IsUnset reports whether the Optional removes the field.
+func (o Optional[T]) IsUnset() bool {
+	return o.state == optionalUnset
+}
+
+// IsAbsent reports whether the Optional leaves the field untouched.
+func (o Optional[T]) IsAbsent() bool {
+	return o.state == optionalAbsent
+}
+
+func (o Optional[T]) optionalValue() (optionalState, interface{}) {
+	return o.state, o.value
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if o.state != optionalSet {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*o = OptionalNull[T]()
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*o = OptionalOf(value)
+	return nil
+}
+
+func (o Optional[T]) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	if o.state != optionalSet {
+		return bsontype.Null, nil, nil
+	}
+	return bson.MarshalValue(o.value)
+}
+
+func (o *Optional[T]) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	if t == bsontype.Null {
+		*o = OptionalNull[T]()
+		return nil
+	}
+
+	var value T
+	if err := bson.UnmarshalValue(t, data, &value); err != nil {
+		return err
+	}
+	*o = OptionalOf(value)
+	return nil
+}
+
+// generatedFields are maintained by the repository, so [SetFromStruct] never sets them.
+var generatedFields = map[string]bool{
+	"_id":       true,
+	"createdAt": true,
+	"updatedAt": true,
+}
+
+// SetFromStruct builds the data of an update from the struct v, e.g. the decoded request body of a PATCH endpoint.
+//
+// Every [Optional] field that is set or null is added to set with its bson path, and the paths of unset Optional fields
+// are returned in unset. Absent Optional fields are left out. Other fields are only added to set if they are not the zero value,
+// fields of nested structs are set one by one. _id, createdAt and updatedAt are never set.
+//
+//	set, unset, err := mongodb.SetFromStruct(patch)
+//	_, err = repo.UpdateOne(ctx, mongodb.MongoIDFilter(id), set)
+//
+// set can be passed to [Repository.UpdateOne] and [Repository.UpdateMany] as is. Fields in unset must be removed with
+// a $unset update on the collection, e.g. via [Repository.BulkWrite], because the update methods only $set fields.
+func SetFromStruct(v interface{}) (set primitive.M, unset []string, err error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, nil, fmt.Errorf("%v: %v is nil", "mongodb.SetFromStruct", rv.Type())
+		}
+		rv = rv.Elem()
+	}
+
+	fields, err := structFields(rv.Type())
+	if err != nil {
+		return nil, nil, fmt.Errorf("%v: %w", "mongodb.SetFromStruct", err)
+	}
+
+	set = primitive.M{}
+	for i, field := range fields {
+		if generatedFields[field.Path] {
+			continue
+		}
+		value, ok := goFieldValue(rv, field.GoPath)
+		if !ok {
+			// A nil pointer to a nested struct, its fields are absent.
+			continue
+		}
+
+		if opt, ok := value.Interface().(optional); ok {
+			state, v := opt.optionalValue()
+			switch state {
+			case optionalSet:
+				set[field.Path] = v
+			case optionalNull:
+				set[field.Path] = nil
+			case optionalUnset:
+				unset = append(unset, field.Path)
+			}
+			continue
+		}
+
+		// Nested structs are followed by their fields, which are set instead.
+		if i+1 < len(fields) && len(fields[i+1].GoPath) > len(field.GoPath) {
+			continue
+		}
+		if !value.IsZero() {
+			set[field.Path] = value.Interface()
+		}
+	}
+
+	return set, unset, nil
+}
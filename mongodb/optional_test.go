@@ -0,0 +1,80 @@
+package mongodb_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	UserPatch struct {
+		mongodb.BaseModel `bson:",inline"`
+		Name              mongodb.Optional[string] `bson:"name" json:"name"`
+		Nickname          mongodb.Optional[string] `bson:"nickname" json:"nickname"`
+		Age               mongodb.Optional[int]    `bson:"age" json:"age"`
+		Address           *AddressPatch            `bson:"address" json:"address"`
+		Role              string                   `bson:"role" json:"role"`
+	}
+
+	AddressPatch struct {
+		City mongodb.Optional[string] `bson:"city" json:"city"`
+		Zip  string                   `bson:"zip" json:"zip"`
+	}
+)
+
+func TestOptionalJSON(t *testing.T) {
+	var patch UserPatch
+	assert.NoError(t, json.Unmarshal([]byte(`{"name": "alice", "nickname": null}`), &patch))
+
+	name, ok := patch.Name.Get()
+	assert.True(t, ok)
+	assert.Equal(t, "alice", name)
+	assert.True(t, patch.Nickname.IsNull())
+	assert.True(t, patch.Age.IsAbsent())
+
+	patch = UserPatch{}
+	assert.NoError(t, json.Unmarshal([]byte(`{}`), &patch))
+	assert.True(t, patch.Name.IsAbsent())
+	assert.True(t, patch.Nickname.IsAbsent())
+
+	out, err := json.Marshal(struct {
+		A mongodb.Optional[int] `json:"a"`
+		B mongodb.Optional[int] `json:"b"`
+	}{A: mongodb.OptionalOf(1), B: mongodb.OptionalNull[int]()})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a": 1, "b": null}`, string(out))
+}
+
+func TestOptionalBSON(t *testing.T) {
+	raw, err := bson.Marshal(bson.M{"name": mongodb.OptionalOf("alice"), "nickname": mongodb.OptionalNull[string]()})
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", bson.Raw(raw).Lookup("name").StringValue())
+	assert.Equal(t, bson.TypeNull, bson.Raw(raw).Lookup("nickname").Type)
+
+	var patch UserPatch
+	assert.NoError(t, bson.Unmarshal(raw, &patch))
+	assert.True(t, patch.Name.IsSet())
+	assert.True(t, patch.Nickname.IsNull())
+	assert.True(t, patch.Age.IsAbsent())
+}
+
+func TestSetFromStruct(t *testing.T) {
+	var patch UserPatch
+	assert.NoError(t, json.Unmarshal([]byte(`{"name": "alice", "nickname": null, "address": {"city": null, "zip": "12345"}}`), &patch))
+	patch.Age = mongodb.OptionalUnset[int]()
+	patch.MongoID = primitive.NewObjectID()
+
+	set, unset, err := mongodb.SetFromStruct(&patch)
+	assert.NoError(t, err)
+	assert.Equal(t, primitive.M{"name": "alice", "nickname": nil, "address.city": nil, "address.zip": "12345"}, set)
+	assert.Equal(t, []string{"age"}, unset)
+
+	set, unset, err = mongodb.SetFromStruct(UserPatch{})
+	assert.NoError(t, err)
+	assert.Empty(t, set)
+	assert.Empty(t, unset)
+}
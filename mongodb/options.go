@@ -0,0 +1,55 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// RepositoryOption configures a [Repository], see [NewRepository].
+	RepositoryOption interface {
+		apply(*repositoryOption)
+	}
+)
+
+type (
+	repositoryOption struct {
+		hooks             []OperationHook
+		uniqueViolations  map[string]string
+		aggregateDefaults *options.AggregateOptions
+		strict            bool
+		onUnknown         UnknownFieldHandler
+		defaultComment    string
+		resultTransforms  []interface{}
+		absoluteTimeout   time.Duration
+		fieldValidation   bool
+		maxResultSize     int
+		keepTruncated     bool
+		serverDeadlines   bool
+		stats             *statsCollector
+		requestTag        func(ctx context.Context) string
+		maxDocumentSize   int
+		cursorKeepAlive   bool
+		registry          *bsoncodec.Registry
+	}
+)
+
+type hookOption struct {
+	hook OperationHook
+}
+
+func (value hookOption) apply(o *repositoryOption) {
+	if value.hook == nil {
+		return
+	}
+	o.hooks = append(o.hooks, value.hook)
+}
+
+// WithHook registers an [OperationHook] that is called around every operation of the repository.
+// Multiple hooks are called in the order they were registered.
+func WithHook(hook OperationHook) RepositoryOption {
+	return hookOption{hook: hook}
+}
@@ -0,0 +1,170 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// Filter is a query filter that is either unordered (bson.M) or ordered (bson.D), see [FromM] and [FromD].
+	//
+	// Ordered filters are needed when the key order matters, e.g. for $expr pipelines or hint-sensitive queries.
+	// The zero Filter matches all documents.
+	Filter struct {
+		m bson.M
+		d bson.D
+	}
+
+	FilterQuerier[T Document[T]] interface {
+		// Like FindOne, but accepts an ordered or unordered [Filter].
+		FindOneWhere(ctx context.Context, filter Filter, opts ...*options.FindOneOptions) (T, error)
+		// Like FindMany, but accepts an ordered or unordered [Filter].
+		FindManyWhere(ctx context.Context, filter Filter, opts ...*options.FindOptions) ([]T, error)
+		// Like UpdateOne, but accepts an ordered or unordered [Filter].
+		UpdateOneWhere(ctx context.Context, filter Filter, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+		// Like UpdateManyResult, but accepts an ordered or unordered [Filter].
+		UpdateManyWhere(ctx context.Context, filter Filter, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+		// Like DeleteOne, but accepts an ordered or unordered [Filter].
+		DeleteOneWhere(ctx context.Context, filter Filter, opts ...*options.DeleteOptions) error
+		// Like DeleteMany, but accepts an ordered or unordered [Filter].
+		DeleteManyWhere(ctx context.Context, filter Filter, opts ...*options.DeleteOptions) (int, error)
+		// Like CountDocuments, but accepts an ordered or unordered [Filter].
+		CountWhere(ctx context.Context, filter Filter, opts ...*options.CountOptions) (int, error)
+	}
+)
+
+// FromM creates an unordered [Filter].
+func FromM(m bson.M) Filter {
+	return Filter{m: m}
+}
+
+// FromD creates an ordered [Filter], whose keys are sent to the server in the given order.
+func FromD(d bson.D) Filter {
+	return Filter{d: d}
+}
+
+// IsOrdered reports whether the filter was created with [FromD].
+func (f Filter) IsOrdered() bool {
+	return f.d != nil
+}
+
+// Len returns the number of top-level keys of the filter.
+func (f Filter) Len() int {
+	if f.d != nil {
+		return len(f.d)
+	}
+	return len(f.m)
+}
+
+// Document returns the filter as it is passed to the driver, which is a bson.D for ordered and a bson.M for other filters.
+func (f Filter) Document() interface{} {
+	if f.d != nil {
+		return f.d
+	}
+	if f.m == nil {
+		return bson.M{}
+	}
+	return f.m
+}
+
+// M returns the filter as bson.M. The key order of ordered filters is lost.
+func (f Filter) M() bson.M {
+	if f.d == nil {
+		return f.m
+	}
+	return f.d.Map()
+}
+
+// NewOrderedFilter is like [NewFilter], but keeps the order of the options in the resulting bson.D.
+// Keys set by a single option are sorted, and a key set by multiple options keeps its first position.
+//
+//	filter := mongodb.FromD(mongodb.NewOrderedFilter(mongodb.WithCompanyID(companyID), mongodb.WithMongoID(id)))
+func NewOrderedFilter(opts ...FilterOption) bson.D {
+	f := bson.D{}
+	merged := primitive.M{}
+
+	for _, opt := range opts {
+		part := primitive.M{}
+		opt.Apply(part)
+
+		keys := make([]string, 0, len(part))
+		for key := range part {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if _, ok := merged[key]; !ok {
+				f = append(f, bson.E{Key: key})
+			}
+		}
+		// MergeFilter never fails.
+		merged, _ = MergeBSON(merged, part, MergeFilter)
+	}
+
+	// Conflicting keys were moved into $and by MergeFilter.
+	res := make(bson.D, 0, len(merged))
+	for _, e := range f {
+		if value, ok := merged[e.Key]; ok {
+			res = append(res, bson.E{Key: e.Key, Value: value})
+		}
+	}
+	if and, ok := merged["$and"]; ok && !hasKey(res, "$and") {
+		res = append(res, bson.E{Key: "$and", Value: and})
+	}
+
+	return res
+}
+
+func hasKey(d bson.D, key string) bool {
+	for _, e := range d {
+		if e.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Like [Repository.FindOne], but accepts an ordered or unordered [Filter].
+func (r *Repository[T]) FindOneWhere(ctx context.Context, filter Filter, opts ...*options.FindOneOptions) (T, error) {
+	return r.findOne(ctx, "FindOneWhere", filter.Document(), opts)
+}
+
+// Like [Repository.FindMany], but accepts an ordered or unordered [Filter].
+func (r *Repository[T]) FindManyWhere(ctx context.Context, filter Filter, opts ...*options.FindOptions) ([]T, error) {
+	return r.findMany(ctx, "FindManyWhere", filter.Document(), opts)
+}
+
+// Like [Repository.UpdateOne], but accepts an ordered or unordered [Filter].
+func (r *Repository[T]) UpdateOneWhere(ctx context.Context, filter Filter, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return r.updateOne(ctx, "UpdateOneWhere", filter.Document(), data, opts)
+}
+
+// Like [Repository.UpdateManyResult], but accepts an ordered or unordered [Filter].
+func (r *Repository[T]) UpdateManyWhere(ctx context.Context, filter Filter, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return r.updateMany(ctx, "UpdateManyWhere", filter.Document(), data, opts)
+}
+
+// Like [Repository.DeleteOne], but accepts an ordered or unordered [Filter], which can not be empty either.
+func (r *Repository[T]) DeleteOneWhere(ctx context.Context, filter Filter, opts ...*options.DeleteOptions) error {
+	if filter.Len() == 0 {
+		return fmt.Errorf("DeleteOneWhere: Filter can not be empty. Filter: %v", filter.Document())
+	}
+
+	return r.deleteOne(ctx, "DeleteOneWhere", filter.Document(), opts)
+}
+
+// Like [Repository.DeleteMany], but accepts an ordered or unordered [Filter].
+func (r *Repository[T]) DeleteManyWhere(ctx context.Context, filter Filter, opts ...*options.DeleteOptions) (int, error) {
+	return r.deleteMany(ctx, "DeleteManyWhere", filter.Document(), opts)
+}
+
+// Like [Repository.CountDocuments], but accepts an ordered or unordered [Filter].
+func (r *Repository[T]) CountWhere(ctx context.Context, filter Filter, opts ...*options.CountOptions) (int, error) {
+	return r.countDocuments(ctx, "CountWhere", filter.Document(), opts)
+}
@@ -0,0 +1,75 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewOrderedFilter(t *testing.T) {
+	companyID := primitive.NewObjectID()
+	id := primitive.NewObjectID()
+	other := primitive.NewObjectID()
+
+	assert.Equal(t, bson.D{{Key: "companyID", Value: companyID}, {Key: "_id", Value: id}},
+		mongodb.NewOrderedFilter(mongodb.WithCompanyID(companyID), mongodb.WithMongoID(id)))
+	assert.Equal(t, bson.D{{Key: "_id", Value: id}, {Key: "companyID", Value: companyID}},
+		mongodb.NewOrderedFilter(mongodb.WithMongoID(id), mongodb.WithCompanyID(companyID)))
+
+	// Conflicting conditions are combined with $and, like by NewFilter.
+	assert.Equal(t, bson.D{{Key: "$and", Value: bson.A{bson.M{"_id": id}, bson.M{"_id": other}}}},
+		mongodb.NewOrderedFilter(mongodb.WithMongoID(id), mongodb.WithMongoID(other)))
+}
+
+func TestFilter(t *testing.T) {
+	ordered := mongodb.FromD(bson.D{{Key: "b", Value: 1}, {Key: "a", Value: 2}})
+	assert.True(t, ordered.IsOrdered())
+	assert.Equal(t, 2, ordered.Len())
+	assert.Equal(t, bson.D{{Key: "b", Value: 1}, {Key: "a", Value: 2}}, ordered.Document())
+	assert.Equal(t, bson.M{"a": 2, "b": 1}, ordered.M())
+
+	unordered := mongodb.FromM(bson.M{"a": 1})
+	assert.False(t, unordered.IsOrdered())
+	assert.Equal(t, bson.M{"a": 1}, unordered.Document())
+
+	assert.Equal(t, bson.M{}, mongodb.Filter{}.Document())
+}
+
+func TestOrderedFilterWithExpr(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_ordered_filter")).(*mongodb.Repository[*User])
+
+	_, err := repo.InsertMany(ctx, []*User{
+		{Name: "same", Email: "same"},
+		{Name: "alice", Email: "alice@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Error on inserting: %v", err)
+	}
+
+	filter := mongodb.FromD(bson.D{{Key: "$expr", Value: bson.D{{Key: "$eq", Value: bson.A{"$name", "$email"}}}}})
+
+	users, err := repo.FindManyWhere(ctx, filter)
+	assert.NoError(t, err)
+	if assert.Len(t, users, 1) {
+		assert.Equal(t, "same", users[0].Name)
+	}
+
+	count, err := repo.CountWhere(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	res, err := repo.UpdateManyWhere(ctx, filter, bson.M{"email": "changed"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), res.MatchedCount)
+
+	deleted, err := repo.DeleteManyWhere(ctx, mongodb.FromD(bson.D{{Key: "email", Value: "changed"}}))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	assert.Error(t, repo.DeleteOneWhere(ctx, mongodb.FromD(bson.D{})))
+}
@@ -0,0 +1,243 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// parallelBatchSize is the maximum number of documents FindManyParallel passes to fn at once.
+	parallelBatchSize = 1000
+	// countParallelMinDocuments is the collection size below which CountParallel counts with a single CountDocuments.
+	countParallelMinDocuments = 10000
+	// countSamplesPerPartition is the number of sampled _ids CountParallel uses per partition to find the partition bounds.
+	countSamplesPerPartition = 20
+)
+
+type (
+	ParallelFinder[T Document[T]] interface {
+		// Finds all Documents that match the given filter with multiple concurrent cursors, and passes them to fn in batches.
+		FindManyParallel(ctx context.Context, filter bson.M, parallelism int, fn func([]T) error) error
+	}
+
+	idRange struct {
+		Min interface{} `bson:"min"`
+		Max interface{} `bson:"max"`
+	}
+)
+
+// Finds all Documents that match the given filter and passes them to fn in batches of up to 1000 documents.
+//
+// The _id space of the matching documents is split into parallelism ranges of about the same size with $bucketAuto,
+// and every range is read by its own cursor. fn is called concurrently from up to parallelism goroutines and must be
+// safe for concurrent use. The first error, of fn or of a cursor, cancels the other cursors and is returned.
+//
+// Documents inserted while the ranges are computed may be missed if their _id is outside of all ranges.
+func (r *Repository[T]) FindManyParallel(ctx context.Context, filter bson.M, parallelism int, fn func([]T) error) (err error) {
+	ctx, finish, err := r.begin(ctx, "FindManyParallel", filter)
+	if err != nil {
+		return err
+	}
+	defer func() { err = finish(err) }()
+
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	ranges, err := r.idRanges(ctx, filter, parallelism)
+	if err != nil {
+		return fmt.Errorf("%v: %w", "mongodb.Repository.FindManyParallel", err)
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	for i, rng := range ranges {
+		idFilter := bson.M{"$gte": rng.Min, "$lt": rng.Max}
+		if i == len(ranges)-1 {
+			// The upper bound of the last bucket is inclusive.
+			idFilter = bson.M{"$gte": rng.Min, "$lte": rng.Max}
+		}
+		rangeFilter := bson.M{"$and": bson.A{filter, bson.M{"_id": idFilter}}}
+
+		group.Go(func() error {
+			return r.scanRange(groupCtx, rangeFilter, fn)
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return fmt.Errorf("%v: %w", "mongodb.Repository.FindManyParallel", err)
+	}
+	return nil
+}
+
+// idRanges splits the _ids of the documents matching filter into at most n ranges.
+func (r *Repository[T]) idRanges(ctx context.Context, filter bson.M, n int) ([]idRange, error) {
+	cur, err := r.aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$bucketAuto", Value: bson.M{"groupBy": "$_id", "buckets": n}}},
+	}, []*options.AggregateOptions{options.Aggregate().SetAllowDiskUse(true)})
+	if err != nil {
+		return nil, err
+	}
+
+	var buckets []struct {
+		ID idRange `bson:"_id"`
+	}
+	if err := cur.All(ctx, &buckets); err != nil {
+		return nil, err
+	}
+
+	ranges := make([]idRange, len(buckets))
+	for i, bucket := range buckets {
+		ranges[i] = bucket.ID
+	}
+	return ranges, nil
+}
+
+func (r *Repository[T]) scanRange(ctx context.Context, filter bson.M, fn func([]T) error) error {
+	cur, err := r.CollectionFor(ctx).Find(ctx, filter, options.Find().SetBatchSize(parallelBatchSize))
+	if err != nil {
+		return err
+	}
+	defer cur.Close(context.Background())
+
+	batch := make([]T, 0, parallelBatchSize)
+	for cur.Next(ctx) {
+		doc, keep, err := r.decodeResult(ctx, cur)
+		if err != nil {
+			return err
+		}
+		if !keep {
+			continue
+		}
+		batch = append(batch, doc)
+
+		if len(batch) == parallelBatchSize {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			batch = make([]T, 0, parallelBatchSize)
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return err
+	}
+
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+	return nil
+}
+
+// CountParallel counts the documents matching filter with up to partitions concurrent CountDocuments, e.g. for exact counts
+// over large subsets of a collection that take too long with a single CountDocuments.
+//
+// The _id space is split into partitions like by [Repository.FindManyParallel], but the ranges are computed with $bucketAuto
+// over a $sample of the collection instead of all matching documents, so that computing them does not take as long as the count itself.
+// The first and the last range are open, so the ranges cover all _ids of the same type as the sampled ones. All _ids are expected
+// to have the same type, e.g. ObjectIDs.
+//
+// Collections with less than 10000 documents, and collections whose ranges cannot be computed, e.g. views,
+// are counted with a single CountDocuments. The first error cancels the other counts and is returned.
+func CountParallel[T Document[T]](ctx context.Context, r RepositoryI[T], filter bson.M, partitions int) (int, error) {
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	bounds, err := countBounds(ctx, r, partitions)
+	if err != nil || len(bounds) == 0 {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return 0, fmt.Errorf("%v: %w", "mongodb.CountParallel", ctxErr)
+		}
+
+		count, err := r.CountDocuments(ctx, filter)
+		if err != nil {
+			return 0, fmt.Errorf("%v: %w", "mongodb.CountParallel", err)
+		}
+		return count, nil
+	}
+
+	counts := make([]int, len(bounds)+1)
+	group, groupCtx := errgroup.WithContext(ctx)
+	for i := range counts {
+		idFilter := bson.M{}
+		if i > 0 {
+			idFilter["$gte"] = bounds[i-1]
+		}
+		if i < len(bounds) {
+			idFilter["$lt"] = bounds[i]
+		}
+		rangeFilter := bson.M{"$and": bson.A{filter, bson.M{"_id": idFilter}}}
+		count := &counts[i]
+
+		group.Go(func() error {
+			var err error
+			*count, err = r.CountDocuments(groupCtx, rangeFilter)
+			return err
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return 0, fmt.Errorf("%v: %w", "mongodb.CountParallel", err)
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	return total, nil
+}
+
+// countBounds returns up to partitions-1 ascending _ids that split the collection of r into partitions of about the same size,
+// or none if the collection is too small to be worth splitting.
+func countBounds[T Document[T]](ctx context.Context, r RepositoryI[T], partitions int) ([]interface{}, error) {
+	if partitions < 2 {
+		return nil, nil
+	}
+
+	admin, err := Capability[CollectionAdmin](r)
+	if err != nil {
+		// Without the size of the collection, it is counted in one piece.
+		return nil, nil
+	}
+	stats, err := admin.Stats(ctx)
+	if errors.Is(err, ErrUnsupported) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if stats.Count < countParallelMinDocuments {
+		return nil, nil
+	}
+
+	cur, err := r.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sample", Value: bson.M{"size": partitions * countSamplesPerPartition}}},
+		{{Key: "$bucketAuto", Value: bson.M{"groupBy": "$_id", "buckets": partitions}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buckets []struct {
+		ID idRange `bson:"_id"`
+	}
+	if err := cur.All(ctx, &buckets); err != nil {
+		return nil, err
+	}
+
+	// The lower bound of the first bucket is not needed, the first range is open.
+	bounds := make([]interface{}, 0, len(buckets))
+	for i := 1; i < len(buckets); i++ {
+		bounds = append(bounds, buckets[i].ID.Min)
+	}
+	return bounds, nil
+}
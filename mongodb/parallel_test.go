@@ -0,0 +1,84 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFindManyParallel(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_parallel")).(*mongodb.Repository[*User])
+
+	users := make([]*User, 5000)
+	for i := range users {
+		users[i] = &User{Name: fmt.Sprintf("User %d", i), Email: fmt.Sprintf("user%d@example.com", i%2)}
+	}
+	if _, err := repo.InsertMany(ctx, users); err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	var mu sync.Mutex
+	visited := map[primitive.ObjectID]int{}
+	err := repo.FindManyParallel(ctx, bson.M{"email": "user0@example.com"}, 4, func(batch []*User) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, u := range batch {
+			visited[u.MongoID]++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Error on parallel find: %v", err)
+	}
+
+	assert.Len(t, visited, 2500)
+	for id, count := range visited {
+		assert.Equal(t, 1, count, "document %v visited more than once", id)
+	}
+
+	// The first error is returned.
+	boom := errors.New("boom")
+	err = repo.FindManyParallel(ctx, bson.M{}, 4, func(batch []*User) error { return boom })
+	assert.ErrorIs(t, err, boom)
+}
+
+func TestCountParallel(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_count_parallel"))
+
+	users := make([]*User, 20000)
+	for i := range users {
+		users[i] = &User{Name: fmt.Sprintf("User %d", i), Email: fmt.Sprintf("user%d@example.com", i%3)}
+	}
+	if _, err := repo.InsertMany(ctx, users); err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	for _, filter := range []bson.M{{}, {"email": "user0@example.com"}, {"email": "nobody@example.com"}} {
+		sequential, err := repo.CountDocuments(ctx, filter)
+		assert.NoError(t, err)
+
+		for _, partitions := range []int{1, 4, 16} {
+			parallel, err := mongodb.CountParallel[*User](ctx, repo, filter, partitions)
+			assert.NoError(t, err)
+			assert.Equal(t, sequential, parallel, "filter %v with %d partitions", filter, partitions)
+		}
+	}
+}
+
+func TestCountParallelCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_count_parallel_canceled"))
+	_, err := mongodb.CountParallel[*User](ctx, repo, bson.M{}, 4)
+	assert.ErrorIs(t, err, context.Canceled)
+}
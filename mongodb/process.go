@@ -0,0 +1,168 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// defaultMaxProcessFailures is the default number of failures kept in a [ProcessReport].
+const defaultMaxProcessFailures = 100
+
+type (
+	// ProcessOption configures [ProcessConcurrently].
+	ProcessOption interface {
+		apply(*processOption)
+	}
+
+	// ProcessReport summarizes the result of [ProcessConcurrently].
+	ProcessReport struct {
+		// Succeeded is the number of documents fn returned no error for.
+		Succeeded int
+		// Failed is the number of documents fn returned an error for.
+		Failed int
+		// Failures contains the first failures, see [WithMaxFailures].
+		Failures []ProcessFailure
+	}
+
+	// ProcessFailure is a document fn returned an error for.
+	ProcessFailure struct {
+		// ID is the _id of the document.
+		ID  interface{}
+		Err error
+	}
+)
+
+type (
+	processOption struct {
+		failFast    bool
+		maxFailures int
+	}
+)
+
+type failFastOption bool
+
+func (value failFastOption) apply(o *processOption) {
+	o.failFast = bool(value)
+}
+
+// FailFast stops processing at the first error and returns it.
+func FailFast() ProcessOption {
+	return failFastOption(true)
+}
+
+type maxFailuresOption int
+
+func (value maxFailuresOption) apply(o *processOption) {
+	if value >= 0 {
+		o.maxFailures = int(value)
+	}
+}
+
+// WithMaxFailures sets how many failures are kept in [ProcessReport.Failures], the default is 100.
+// All failures are still counted in [ProcessReport.Failed].
+func WithMaxFailures(max int) ProcessOption {
+	return maxFailuresOption(max)
+}
+
+// ProcessConcurrently streams all documents matching filter from r and calls fn for each of them from workers goroutines.
+//
+// Documents are decoded like r decodes them, strictly and with the result transforms of r if enabled.
+// Errors of fn are collected in the report and do not stop the processing, unless [FailFast] is set.
+// The returned error is set if the cursor failed, ctx was canceled, or, with FailFast, fn returned an error.
+// The ctx passed to fn is canceled when processing stops early.
+func ProcessConcurrently[T Document[T]](ctx context.Context, r RepositoryI[T], filter bson.M, workers int, fn func(ctx context.Context, doc T) error, opts ...ProcessOption) (ProcessReport, error) {
+	ops := &processOption{maxFailures: defaultMaxProcessFailures}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	report := ProcessReport{}
+
+	cur, err := r.Aggregate(ctx, mongo.Pipeline{{{Key: "$match", Value: filter}}})
+	if err != nil {
+		return report, fmt.Errorf("%v: %w", "mongodb.ProcessConcurrently", err)
+	}
+	defer cur.Close(context.Background())
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	docs := make(chan T)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for doc := range docs {
+				err := fn(ctx, doc)
+
+				mu.Lock()
+				if err == nil {
+					report.Succeeded++
+				} else {
+					report.Failed++
+					if len(report.Failures) < ops.maxFailures {
+						id, _ := documentID(registryOf(r), doc)
+						report.Failures = append(report.Failures, ProcessFailure{ID: id, Err: err})
+					}
+					if ops.failFast && firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var cursorErr error
+produce:
+	for cur.Next(ctx) {
+		doc, keep, err := decodeResultOf(ctx, r, cur)
+		if err != nil {
+			cursorErr = err
+			break
+		}
+		if !keep {
+			continue
+		}
+
+		select {
+		case docs <- doc:
+		case <-ctx.Done():
+			break produce
+		}
+	}
+	if cursorErr == nil {
+		cursorErr = cur.Err()
+	}
+	close(docs)
+	wg.Wait()
+
+	switch {
+	case firstErr != nil:
+		return report, fmt.Errorf("%v: %w", "mongodb.ProcessConcurrently", firstErr)
+	case cursorErr != nil && ctx.Err() == nil:
+		return report, fmt.Errorf("%v: %w", "mongodb.ProcessConcurrently", cursorErr)
+	}
+	// ctx is only canceled by us on FailFast, so any other cancellation comes from the caller.
+	if err := ctx.Err(); err != nil {
+		return report, fmt.Errorf("%v: %w", "mongodb.ProcessConcurrently", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,95 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// cursorRepository answers Aggregate with fixed documents, all other methods are not implemented.
+type cursorRepository struct {
+	mongodb.RepositoryI[*User]
+	docs []interface{}
+}
+
+func (r cursorRepository) Aggregate(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	return mongo.NewCursorFromDocuments(r.docs, nil, nil)
+}
+
+func userDocuments(n int) []interface{} {
+	docs := make([]interface{}, n)
+	for i := range docs {
+		docs[i] = bson.M{"_id": primitive.NewObjectID(), "name": "User"}
+	}
+	return docs
+}
+
+func TestProcessConcurrently(t *testing.T) {
+	repo := cursorRepository{docs: userDocuments(50)}
+
+	var running, maxRunning int32
+	boom := errors.New("boom")
+	var calls int32
+	report, err := mongodb.ProcessConcurrently[*User](context.Background(), repo, nil, 4, func(ctx context.Context, u *User) error {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			seen := atomic.LoadInt32(&maxRunning)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+
+		if atomic.AddInt32(&calls, 1)%10 == 0 {
+			return boom
+		}
+		return nil
+	}, mongodb.WithMaxFailures(3))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 45, report.Succeeded)
+	assert.Equal(t, 5, report.Failed)
+	assert.Len(t, report.Failures, 3)
+	assert.ErrorIs(t, report.Failures[0].Err, boom)
+	assert.NotNil(t, report.Failures[0].ID)
+	assert.LessOrEqual(t, maxRunning, int32(4))
+	assert.Greater(t, maxRunning, int32(1))
+}
+
+func TestProcessConcurrentlyFailFast(t *testing.T) {
+	repo := cursorRepository{docs: userDocuments(100)}
+
+	boom := errors.New("boom")
+	report, err := mongodb.ProcessConcurrently[*User](context.Background(), repo, nil, 2, func(ctx context.Context, u *User) error {
+		return boom
+	}, mongodb.FailFast())
+
+	assert.ErrorIs(t, err, boom)
+	assert.Less(t, report.Failed, 100)
+}
+
+func TestProcessConcurrentlyCanceled(t *testing.T) {
+	repo := cursorRepository{docs: userDocuments(100)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int32
+	report, err := mongodb.ProcessConcurrently[*User](ctx, repo, nil, 2, func(ctx context.Context, u *User) error {
+		if atomic.AddInt32(&calls, 1) == 5 {
+			cancel()
+		}
+		return nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, report.Succeeded, 100)
+}
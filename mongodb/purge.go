@@ -0,0 +1,124 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// deletedAtField is the field soft deleted documents store their deletion date in.
+	deletedAtField    = "deletedAt"
+	defaultPurgeBatch = 1000
+	defaultPurgeDelay = 100 * time.Millisecond
+)
+
+// ErrNotSoftDeletable is returned by [PurgeDeleted] for document types without a deletedAt date field.
+var ErrNotSoftDeletable = errors.New("mongodb: document type has no deletedAt date field")
+
+type (
+	// PurgeOption configures [PurgeDeleted].
+	PurgeOption interface {
+		apply(*purgeOption)
+	}
+)
+
+type (
+	purgeOption struct {
+		delay time.Duration
+	}
+)
+
+type purgeDelayOption time.Duration
+
+func (value purgeDelayOption) apply(o *purgeOption) {
+	if value >= 0 {
+		o.delay = time.Duration(value)
+	}
+}
+
+// WithPurgeDelay sets the pause between two batches, the default is 100ms.
+func WithPurgeDelay(delay time.Duration) PurgeOption {
+	return purgeDelayOption(delay)
+}
+
+// PurgeDeleted permanently deletes documents whose deletedAt is more than olderThan ago, and returns the number of deleted documents.
+//
+// The documents are deleted in batches of batchSize with a short pause in between, so that large purges do not
+// block the collection. A batchSize <= 0 uses batches of 1000 documents. T must have a deletedAt date field,
+// otherwise [ErrNotSoftDeletable] is returned. If ctx is canceled, the number of documents purged so far is returned with the error.
+func PurgeDeleted[T Document[T]](ctx context.Context, r RepositoryI[T], olderThan time.Duration, batchSize int, opts ...PurgeOption) (int, error) {
+	ops := &purgeOption{delay: defaultPurgeDelay}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+	if batchSize <= 0 {
+		batchSize = defaultPurgeBatch
+	}
+
+	field, ok, err := lookupField(documentType[T](), deletedAtField)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", "mongodb.PurgeDeleted", err)
+	}
+	if !ok || !isDateType(field.Field.Type) {
+		return 0, fmt.Errorf("%v: %v: %w", "mongodb.PurgeDeleted", documentType[T](), ErrNotSoftDeletable)
+	}
+
+	filter := bson.M{deletedAtField: bson.M{"$lt": now().Add(-olderThan)}}
+	purged := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return purged, fmt.Errorf("%v: %w", "mongodb.PurgeDeleted", err)
+		}
+
+		ids, err := batchIDs(ctx, r, filter, batchSize)
+		if err != nil {
+			return purged, fmt.Errorf("%v: %w", "mongodb.PurgeDeleted", err)
+		}
+		if len(ids) == 0 {
+			return purged, nil
+		}
+
+		// The filter is repeated, in case a document was restored in the meantime.
+		deleted, err := r.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}, deletedAtField: filter[deletedAtField]})
+		purged += deleted
+		if err != nil {
+			return purged, fmt.Errorf("%v: %w", "mongodb.PurgeDeleted", err)
+		}
+
+		if len(ids) < batchSize {
+			return purged, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return purged, fmt.Errorf("%v: %w", "mongodb.PurgeDeleted", ctx.Err())
+		case <-time.After(ops.delay):
+		}
+	}
+}
+
+// batchIDs returns the _ids of up to limit documents matching filter.
+func batchIDs(ctx context.Context, r Aggregater, filter bson.M, limit int) ([]interface{}, error) {
+	cur, err := r.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: bson.M{"_id": 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(context.Background())
+
+	var ids []interface{}
+	for cur.Next(ctx) {
+		if id, err := cur.Current.LookupErr("_id"); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, cur.Err()
+}
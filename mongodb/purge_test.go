@@ -0,0 +1,53 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type RetainedUser struct {
+	mongodb.BaseModel `bson:",inline"`
+	Name              string     `bson:"name"`
+	DeletedAt         *time.Time `bson:"deletedAt,omitempty"`
+}
+
+func TestPurgeDeletedRequiresDeletedAt(t *testing.T) {
+	_, err := mongodb.PurgeDeleted[*User](context.Background(), nil, time.Hour, 0)
+	assert.ErrorIs(t, err, mongodb.ErrNotSoftDeletable)
+}
+
+func TestPurgeDeleted(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*RetainedUser](testCollection(t, "user_purge"))
+
+	recent := time.Now().Add(-24 * time.Hour)
+	old := time.Now().Add(-40 * 24 * time.Hour)
+	_, err := repo.InsertMany(ctx, []*RetainedUser{
+		{Name: "active"},
+		{Name: "recently deleted", DeletedAt: &recent},
+		{Name: "old 1", DeletedAt: &old},
+		{Name: "old 2", DeletedAt: &old},
+		{Name: "old 3", DeletedAt: &old},
+	})
+	if err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	purged, err := mongodb.PurgeDeleted[*RetainedUser](ctx, repo, 30*24*time.Hour, 2, mongodb.WithPurgeDelay(0))
+	if err != nil {
+		t.Fatalf("Error on purging: %v", err)
+	}
+	assert.Equal(t, 3, purged)
+
+	remaining, err := repo.FindMany(ctx, bson.M{})
+	assert.NoError(t, err)
+	if assert.Len(t, remaining, 2) {
+		assert.Equal(t, "active", remaining[0].Name)
+		assert.Equal(t, "recently deleted", remaining[1].Name)
+	}
+}
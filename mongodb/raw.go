@@ -0,0 +1,160 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	// ErrFieldNotFound is returned by [RawField] for paths that do not exist in the document.
+	ErrFieldNotFound = errors.New("mongodb: field not found")
+	// ErrFieldType is returned by [RawField] for values whose BSON type does not match the requested type.
+	ErrFieldType = errors.New("mongodb: field has a different type")
+)
+
+type (
+	FindManyRaw interface {
+		// Like FindMany, but returns the undecoded documents, e.g. to read single fields with [RawField].
+		FindManyRaw(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]bson.Raw, error)
+	}
+)
+
+// Finds all Documents that match the given filter, and returns them without decoding them into T.
+//
+// Reading single fields of large documents with [RawField] is much cheaper than decoding the whole documents.
+// The documents are neither strictly decoded nor transformed, see [WithStrictDecoding] and [WithResultTransform],
+// but [WithMaxResultSize] applies.
+//
+// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.Find]
+func (r *Repository[T]) FindManyRaw(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (res []bson.Raw, err error) {
+	ctx, finish, err := r.begin(ctx, "FindManyRaw", filter)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = finish(err) }()
+
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	opts, limited := r.limitResultSize(opts)
+	cur, err := r.CollectionFor(ctx).Find(ctx, filter, r.findOptions(ctx, opts)...)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.Repository.FindManyRaw", err)
+	}
+	defer cur.Close(context.Background())
+
+	for cur.Next(ctx) {
+		// Current is only valid until the next call of Next.
+		res = append(res, append(bson.Raw(nil), cur.Current...))
+	}
+	if err := cur.Err(); err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.Repository.FindManyRaw", err)
+	}
+
+	if limited && len(res) > r.maxResultSize {
+		err := fmt.Errorf("%w: more than %d documents match in %v", ErrResultTruncated, r.maxResultSize, r.db.Name())
+		if r.keepTruncated {
+			return res[:r.maxResultSize], err
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// RawField returns the value at the dotted path in raw, e.g. "address.city" or "items.0.name", without decoding the rest of the document.
+//
+// Strings, booleans, ObjectIDs, dates as time.Time or primitive.DateTime, and int, int32, int64 and float64 are read directly.
+// Integers are converted between the BSON integer types, if the value fits, and float64 also accepts integers.
+// Other types, e.g. structs or slices, are decoded with bson.Unmarshal.
+//
+// An error wrapping [ErrFieldNotFound] is returned if the path does not exist, and one wrapping [ErrFieldType]
+// if the value has another type. A null value is returned as the zero value of T.
+func RawField[T any](raw bson.Raw, path string) (T, error) {
+	var res T
+
+	value, err := raw.LookupErr(strings.Split(path, ".")...)
+	if err != nil {
+		return res, fmt.Errorf("%v: %v: %w", "mongodb.RawField", path, ErrFieldNotFound)
+	}
+	if value.Type == bsontype.Null {
+		return res, nil
+	}
+
+	ok := true
+	switch p := any(&res).(type) {
+	case *string:
+		*p, ok = value.StringValueOK()
+	case *bool:
+		*p, ok = value.BooleanOK()
+	case *primitive.ObjectID:
+		*p, ok = value.ObjectIDOK()
+	case *time.Time:
+		var dt int64
+		dt, ok = value.DateTimeOK()
+		*p = primitive.DateTime(dt).Time().UTC()
+	case *primitive.DateTime:
+		var dt int64
+		dt, ok = value.DateTimeOK()
+		*p = primitive.DateTime(dt)
+	case *int:
+		var n int64
+		n, ok = rawInt(value)
+		*p = int(n)
+	case *int64:
+		*p, ok = rawInt(value)
+	case *int32:
+		var n int64
+		n, ok = rawInt(value)
+		ok = ok && n == int64(int32(n))
+		*p = int32(n)
+	case *float64:
+		switch value.Type {
+		case bsontype.Double:
+			*p = value.Double()
+		case bsontype.Int32:
+			*p = float64(value.Int32())
+		case bsontype.Int64:
+			*p = float64(value.Int64())
+		default:
+			ok = false
+		}
+	case *bson.Raw:
+		var doc bson.Raw
+		doc, ok = value.DocumentOK()
+		if !ok {
+			doc, ok = value.ArrayOK()
+		}
+		*p = doc
+	default:
+		if err := value.Unmarshal(&res); err != nil {
+			return res, fmt.Errorf("%v: %v: %w: %w", "mongodb.RawField", path, ErrFieldType, err)
+		}
+	}
+
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%v: %v: %w: %v is %v, not %T", "mongodb.RawField", path, ErrFieldType, path, value.Type, zero)
+	}
+	return res, nil
+}
+
+// rawInt returns the value of a BSON integer, doubles are not truncated.
+func rawInt(value bson.RawValue) (int64, bool) {
+	switch value.Type {
+	case bsontype.Int32:
+		return int64(value.Int32()), true
+	case bsontype.Int64:
+		return value.Int64(), true
+	default:
+		return 0, false
+	}
+}
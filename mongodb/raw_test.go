@@ -0,0 +1,182 @@
+package mongodb_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WideDocument struct {
+	mongodb.BaseModel `bson:",inline"`
+	Name              string                `bson:"name"`
+	Counter           int                   `bson:"counter"`
+	Address           struct{ City string } `bson:"address"`
+	Tags              []string              `bson:"tags"`
+	Attributes        map[string]string     `bson:"attributes"`
+}
+
+func wideDocument() *WideDocument {
+	doc := &WideDocument{Name: "Alice", Counter: 42, Tags: []string{"a", "b"}, Attributes: map[string]string{}}
+	doc.Address.City = "Berlin"
+	for i := 0; i < 200; i++ {
+		doc.Attributes[fmt.Sprintf("attribute%d", i)] = fmt.Sprintf("value %d", i)
+	}
+	doc.InitDocument()
+	return doc
+}
+
+func TestRawField(t *testing.T) {
+	id := primitive.NewObjectID()
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	raw, err := bson.Marshal(bson.D{
+		{Key: "_id", Value: id},
+		{Key: "name", Value: "Alice"},
+		{Key: "age", Value: int32(30)},
+		{Key: "visits", Value: int64(1 << 40)},
+		{Key: "score", Value: 1.5},
+		{Key: "active", Value: true},
+		{Key: "createdAt", Value: at},
+		{Key: "deletedAt", Value: nil},
+		{Key: "address", Value: bson.D{{Key: "city", Value: "Berlin"}}},
+		{Key: "items", Value: bson.A{bson.D{{Key: "name", Value: "first"}}}},
+	})
+	if err != nil {
+		t.Fatalf("Error on marshaling document: %v", err)
+	}
+
+	name, err := mongodb.RawField[string](raw, "name")
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice", name)
+
+	age, err := mongodb.RawField[int](raw, "age")
+	assert.NoError(t, err)
+	assert.Equal(t, 30, age)
+
+	visits, err := mongodb.RawField[int64](raw, "visits")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1<<40), visits)
+
+	score, err := mongodb.RawField[float64](raw, "score")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, score)
+	ageFloat, err := mongodb.RawField[float64](raw, "age")
+	assert.NoError(t, err)
+	assert.Equal(t, 30.0, ageFloat)
+
+	active, err := mongodb.RawField[bool](raw, "active")
+	assert.NoError(t, err)
+	assert.True(t, active)
+
+	gotID, err := mongodb.RawField[primitive.ObjectID](raw, "_id")
+	assert.NoError(t, err)
+	assert.Equal(t, id, gotID)
+
+	createdAt, err := mongodb.RawField[time.Time](raw, "createdAt")
+	assert.NoError(t, err)
+	assert.Equal(t, at, createdAt)
+
+	deletedAt, err := mongodb.RawField[*time.Time](raw, "deletedAt")
+	assert.NoError(t, err)
+	assert.Nil(t, deletedAt)
+
+	city, err := mongodb.RawField[string](raw, "address.city")
+	assert.NoError(t, err)
+	assert.Equal(t, "Berlin", city)
+
+	item, err := mongodb.RawField[string](raw, "items.0.name")
+	assert.NoError(t, err)
+	assert.Equal(t, "first", item)
+
+	address, err := mongodb.RawField[struct {
+		City string `bson:"city"`
+	}](raw, "address")
+	assert.NoError(t, err)
+	assert.Equal(t, "Berlin", address.City)
+
+	for _, path := range []string{"missing", "address.street", "items.1.name", "name.first"} {
+		_, err = mongodb.RawField[string](raw, path)
+		assert.ErrorIs(t, err, mongodb.ErrFieldNotFound, path)
+	}
+
+	_, err = mongodb.RawField[int](raw, "name")
+	assert.ErrorIs(t, err, mongodb.ErrFieldType)
+	_, err = mongodb.RawField[int](raw, "score")
+	assert.ErrorIs(t, err, mongodb.ErrFieldType, "doubles are not truncated")
+	_, err = mongodb.RawField[int32](raw, "visits")
+	assert.ErrorIs(t, err, mongodb.ErrFieldType, "int64 values that do not fit")
+	_, err = mongodb.RawField[string](raw, "_id")
+	assert.ErrorIs(t, err, mongodb.ErrFieldType)
+	_, err = mongodb.RawField[[]string](raw, "address")
+	assert.ErrorIs(t, err, mongodb.ErrFieldType)
+}
+
+func TestFindManyRaw(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*WideDocument](testCollection(t, "wide_document_raw")).(*mongodb.Repository[*WideDocument])
+
+	docs := []*WideDocument{wideDocument(), wideDocument()}
+	docs[1].Name = "Bob"
+	if _, err := repo.InsertMany(ctx, docs); err != nil {
+		t.Fatalf("Error on inserting documents: %v", err)
+	}
+
+	raws, err := repo.FindManyRaw(ctx, bson.M{}, options.Find().SetSort(bson.M{"name": 1}))
+	assert.NoError(t, err)
+	if assert.Len(t, raws, 2) {
+		for i, raw := range raws {
+			name, err := mongodb.RawField[string](raw, "name")
+			assert.NoError(t, err)
+			assert.Equal(t, docs[i].Name, name)
+
+			id, err := mongodb.RawField[primitive.ObjectID](raw, "_id")
+			assert.NoError(t, err)
+			assert.Equal(t, docs[i].MongoID, id)
+		}
+	}
+
+	raws, err = repo.FindManyRaw(ctx, bson.M{"name": "nobody"})
+	assert.NoError(t, err)
+	assert.Empty(t, raws)
+}
+
+func BenchmarkWideDocumentDecode(b *testing.B) {
+	raw, err := bson.Marshal(wideDocument())
+	if err != nil {
+		b.Fatalf("Error on marshaling document: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var doc WideDocument
+		if err := bson.Unmarshal(raw, &doc); err != nil {
+			b.Fatal(err)
+		}
+		_, _ = doc.Name, doc.Counter
+	}
+}
+
+func BenchmarkWideDocumentRawField(b *testing.B) {
+	raw, err := bson.Marshal(wideDocument())
+	if err != nil {
+		b.Fatalf("Error on marshaling document: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := mongodb.RawField[string](raw, "name"); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := mongodb.RawField[int](raw, "counter"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
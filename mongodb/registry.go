@@ -0,0 +1,46 @@
+package mongodb
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+)
+
+// RegistryProvider is implemented by [Repository] and the decorators of this package.
+//
+// It is not part of [RepositoryI], since fakes usually marshal nothing themselves.
+type RegistryProvider interface {
+	// Returns the registry documents are marshaled with, bson.DefaultRegistry unless set with [WithRegistry].
+	Registry() *bsoncodec.Registry
+}
+
+type registryOption struct {
+	registry *bsoncodec.Registry
+}
+
+func (value registryOption) apply(o *repositoryOption) {
+	o.registry = value.registry
+}
+
+// WithRegistry sets registry on the collection of the repository, and marshals documents with it wherever the repository
+// marshals them itself, e.g. for ReplaceOne, UpsertManyByKey and the size check of [WithMaxDocumentSize].
+//
+// It is needed for registries with codecs that must be used for every write, like [EncryptionRegistry]:
+// setting the registry only on the client would leave those documents to bson.DefaultRegistry.
+func WithRegistry(registry *bsoncodec.Registry) RepositoryOption {
+	return registryOption{registry: registry}
+}
+
+func (r *Repository[T]) Registry() *bsoncodec.Registry {
+	if r.registry == nil {
+		return bson.DefaultRegistry
+	}
+	return r.registry
+}
+
+// registryOf returns the registry of r if it is a [RegistryProvider], and bson.DefaultRegistry otherwise.
+func registryOf(r interface{}) *bsoncodec.Registry {
+	if provider, ok := r.(RegistryProvider); ok {
+		return provider.Registry()
+	}
+	return bson.DefaultRegistry
+}
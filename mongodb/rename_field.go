@@ -0,0 +1,223 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultRenameBatchSize = 500
+
+type (
+	// RenameOption configures [RenameField].
+	RenameOption interface {
+		apply(*renameOption)
+	}
+
+	// RenameReport summarizes the result of [RenameField].
+	RenameReport struct {
+		// Migrated is the number of documents whose field was renamed or copied.
+		Migrated int
+		// Skipped is the number of documents that already had the value of the old field under the new name,
+		// so that copying did not change them. When renaming, the old field of these documents is removed, and they are Migrated.
+		Skipped int
+		// Remaining is the number of documents without conflict that still have the old field and were not migrated:
+		// with [WithVerifyOnly] all of them, otherwise those that were changed concurrently during the batch.
+		Remaining int
+		// Conflicts are the _ids of documents with different values under the old and the new name. They are never modified.
+		Conflicts []interface{}
+		// Batches is the number of processed batches.
+		Batches int
+	}
+)
+
+type (
+	renameOption struct {
+		verifyOnly bool
+		copy       bool
+		progress   func(report RenameReport)
+	}
+)
+
+type verifyOnlyOption bool
+
+func (value verifyOnlyOption) apply(o *renameOption) {
+	o.verifyOnly = bool(value)
+}
+
+// WithVerifyOnly makes [RenameField] only count the documents that still have to be migrated and the conflicts,
+// without modifying any document.
+func WithVerifyOnly() RenameOption {
+	return verifyOnlyOption(true)
+}
+
+type copyFieldOption bool
+
+func (value copyFieldOption) apply(o *renameOption) {
+	o.copy = bool(value)
+}
+
+// WithCopyField makes [RenameField] copy the old field instead of renaming it, so that both names are stored during
+// the transition, e.g. while old and new versions of a service run side by side. A later run without it removes the old field.
+func WithCopyField() RenameOption {
+	return copyFieldOption(true)
+}
+
+type renameProgressOption func(report RenameReport)
+
+func (value renameProgressOption) apply(o *renameOption) {
+	o.progress = value
+}
+
+// WithRenameProgress calls fn after every batch with the report of the batches so far.
+func WithRenameProgress(fn func(report RenameReport)) RenameOption {
+	return renameProgressOption(fn)
+}
+
+// RenameField renames the field oldName to newName in all documents of r that have it, in batches of batchSize documents
+// in _id order. A batchSize <= 0 uses a batch size of 500. Both names may be dotted paths, but neither may be a prefix of the other.
+//
+// Documents that already have a different value under newName are never overwritten, but reported as
+// [RenameReport.Conflicts]. Documents with the same value under both names only get the old field removed.
+// A rename is done in multiple steps when old and new versions of a service run side by side: copy the field with
+// [WithCopyField] while both names are read, rename it once no version reads the old name, and check with [WithVerifyOnly]
+// that no documents remain. Renamed documents no longer match, so an aborted run is resumed by running it again.
+//
+// When ctx is canceled, the current batch is still written and RenameField returns the context error.
+// Copying uses an update with an aggregation pipeline, which requires MongoDB 4.2. r must implement [FindManyRaw], see [Capability].
+func RenameField[T Document[T]](ctx context.Context, r RepositoryI[T], oldName, newName string, batchSize int, opts ...RenameOption) (RenameReport, error) {
+	ops := &renameOption{}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+	if batchSize <= 0 {
+		batchSize = defaultRenameBatchSize
+	}
+
+	var report RenameReport
+	if err := validateRename(oldName, newName); err != nil {
+		return report, fmt.Errorf("%v: %w", "mongodb.RenameField", err)
+	}
+	reader, err := Capability[FindManyRaw](r)
+	if err != nil {
+		return report, fmt.Errorf("%v: %w", "mongodb.RenameField", err)
+	}
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(batchSize)).
+		SetProjection(bson.M{"_id": 1, oldName: 1, newName: 1})
+
+	var lastID interface{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, fmt.Errorf("%v: %w", "mongodb.RenameField", err)
+		}
+
+		filter := bson.M{oldName: bson.M{"$exists": true}}
+		if lastID != nil {
+			filter["_id"] = bson.M{"$gt": lastID}
+		}
+		batch, err := reader.FindManyRaw(ctx, filter, findOpts)
+		if err != nil {
+			return report, fmt.Errorf("%v: %w", "mongodb.RenameField", err)
+		}
+		if len(batch) == 0 {
+			return report, nil
+		}
+
+		var missing, same []interface{}
+		for _, doc := range batch {
+			var id interface{}
+			if err := doc.Lookup("_id").Unmarshal(&id); err != nil {
+				return report, fmt.Errorf("%v: %w", "mongodb.RenameField", err)
+			}
+			lastID = id
+
+			oldValue := doc.Lookup(strings.Split(oldName, ".")...)
+			newValue, err := doc.LookupErr(strings.Split(newName, ".")...)
+			switch {
+			case err != nil:
+				missing = append(missing, id)
+			case newValue.Equal(oldValue):
+				same = append(same, id)
+			default:
+				report.Conflicts = append(report.Conflicts, id)
+			}
+		}
+
+		pending := len(missing)
+		if ops.copy {
+			report.Skipped += len(same)
+		} else {
+			pending += len(same)
+		}
+
+		if ops.verifyOnly {
+			report.Remaining += pending
+		} else if pending > 0 {
+			// The batch is written even if ctx is canceled in the meantime, so that it is never applied partially.
+			res, err := r.BulkWrite(withoutCancel(ctx), renameModels(oldName, newName, missing, same, ops.copy))
+			if err != nil {
+				return report, fmt.Errorf("%v: batch before %v: %w", "mongodb.RenameField", lastID, err)
+			}
+			report.Migrated += int(res.ModifiedCount)
+			report.Remaining += pending - int(res.ModifiedCount)
+		}
+
+		report.Batches++
+		if ops.progress != nil {
+			ops.progress(report)
+		}
+
+		if len(batch) < batchSize {
+			return report, nil
+		}
+	}
+}
+
+// renameModels returns the writes that move the old field of the documents in missing to the new name,
+// and, unless copying, remove the old field of the documents in same.
+func renameModels(oldName, newName string, missing, same []interface{}, copyField bool) []mongo.WriteModel {
+	var models []mongo.WriteModel
+	if len(missing) > 0 {
+		// Documents that got the new field since they were read are not overwritten.
+		filter := bson.M{"_id": bson.M{"$in": missing}, oldName: bson.M{"$exists": true}, newName: bson.M{"$exists": false}}
+
+		var update interface{} = bson.M{"$rename": bson.M{oldName: newName}, "$currentDate": bson.M{"updatedAt": true}}
+		if copyField {
+			update = mongo.Pipeline{{{Key: "$set", Value: bson.D{{Key: newName, Value: "$" + oldName}, {Key: "updatedAt", Value: "$$NOW"}}}}}
+		}
+		models = append(models, mongo.NewUpdateManyModel().SetFilter(filter).SetUpdate(update))
+	}
+	if len(same) > 0 && !copyField {
+		// Documents whose fields were changed to different values since they were read became conflicts, and are kept.
+		filter := bson.M{
+			"_id":   bson.M{"$in": same},
+			oldName: bson.M{"$exists": true},
+			newName: bson.M{"$exists": true},
+			"$expr": bson.M{"$eq": bson.A{"$" + oldName, "$" + newName}},
+		}
+		models = append(models, mongo.NewUpdateManyModel().
+			SetFilter(filter).
+			SetUpdate(bson.M{"$unset": bson.M{oldName: ""}, "$currentDate": bson.M{"updatedAt": true}}))
+	}
+	return models
+}
+
+// validateRename checks that a field can be renamed from oldName to newName with $rename.
+func validateRename(oldName, newName string) error {
+	for _, name := range []string{oldName, newName} {
+		if name == "" || name == "_id" || strings.HasPrefix(name, "$") {
+			return fmt.Errorf("%w: can not rename %q to %q", ErrInvalidFieldPath, oldName, newName)
+		}
+	}
+	if oldName == newName || strings.HasPrefix(oldName, newName+".") || strings.HasPrefix(newName, oldName+".") {
+		return fmt.Errorf("%w: can not rename %q to %q", ErrInvalidFieldPath, oldName, newName)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package mongodb_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestRenameFieldInvalidNames(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	repo := mongodb.NewRepository[*User](client.Database("testdb").Collection("user_rename"))
+	for _, names := range [][2]string{{"", "name"}, {"name", "name"}, {"_id", "id"}, {"address", "address.city"}, {"address.city", "address"}, {"$name", "name"}} {
+		_, err := mongodb.RenameField(ctx, repo, names[0], names[1], 10)
+		assert.ErrorIs(t, err, mongodb.ErrInvalidFieldPath, names)
+	}
+}
+
+func TestRenameField(t *testing.T) {
+	ctx := context.Background()
+	col := testCollection(t, "user_rename")
+	repo := mongodb.NewRepository[*User](col)
+
+	ids := make([]primitive.ObjectID, 5)
+	for i := range ids {
+		ids[i] = primitive.NewObjectID()
+	}
+	_, err := col.InsertMany(ctx, []interface{}{
+		bson.M{"_id": ids[0], "mail": "a@example.com"},
+		bson.M{"_id": ids[1], "mail": "b@example.com"},
+		bson.M{"_id": ids[2], "mail": "c@example.com"},
+		bson.M{"_id": ids[3], "mail": "d@example.com", "email": "d@example.com"},
+		bson.M{"_id": ids[4], "mail": "old@example.com", "email": "new@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	report, err := mongodb.RenameField(ctx, repo, "mail", "email", 2, mongodb.WithVerifyOnly())
+	assert.NoError(t, err)
+	assert.Equal(t, mongodb.RenameReport{Remaining: 4, Conflicts: []interface{}{ids[4]}, Batches: 3}, report)
+
+	var progress []int
+	report, err = mongodb.RenameField(ctx, repo, "mail", "email", 2, mongodb.WithCopyField(), mongodb.WithRenameProgress(func(report mongodb.RenameReport) {
+		progress = append(progress, report.Migrated)
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, mongodb.RenameReport{Migrated: 3, Skipped: 1, Conflicts: []interface{}{ids[4]}, Batches: 3}, report)
+	assert.Equal(t, []int{2, 3, 3}, progress)
+
+	users, err := repo.FindMany(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	assert.NoError(t, err)
+	if assert.Len(t, users, 5) {
+		assert.Equal(t, "a@example.com", users[0].Email)
+		assert.Equal(t, "new@example.com", users[4].Email, "conflicts are not overwritten")
+	}
+	copied, err := repo.CountDocuments(ctx, bson.M{"mail": bson.M{"$exists": true}})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, copied, "copying keeps the old field")
+
+	report, err = mongodb.RenameField(ctx, repo, "mail", "email", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, mongodb.RenameReport{Migrated: 4, Conflicts: []interface{}{ids[4]}, Batches: 3}, report)
+
+	report, err = mongodb.RenameField(ctx, repo, "mail", "email", 2, mongodb.WithVerifyOnly())
+	assert.NoError(t, err)
+	assert.Equal(t, mongodb.RenameReport{Conflicts: []interface{}{ids[4]}, Batches: 1}, report)
+
+	var conflict bson.M
+	assert.NoError(t, col.FindOne(ctx, bson.M{"_id": ids[4]}).Decode(&conflict))
+	assert.Equal(t, "old@example.com", conflict["mail"])
+	assert.Equal(t, "new@example.com", conflict["email"])
+}
+
+func TestRenameFieldResume(t *testing.T) {
+	col := testCollection(t, "user_rename_resume")
+	repo := mongodb.NewRepository[*User](col)
+
+	docs := make([]interface{}, 10)
+	for i := range docs {
+		docs[i] = bson.M{"mail": fmt.Sprintf("user%d@example.com", i)}
+	}
+	if _, err := col.InsertMany(context.Background(), docs); err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	// The run is aborted after its first batch.
+	ctx, cancel := context.WithCancel(context.Background())
+	report, err := mongodb.RenameField(ctx, repo, "mail", "email", 3, mongodb.WithRenameProgress(func(mongodb.RenameReport) { cancel() }))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 3, report.Migrated)
+
+	report, err = mongodb.RenameField(context.Background(), repo, "mail", "email", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, report.Migrated)
+	assert.Empty(t, report.Conflicts)
+
+	renamed, err := repo.CountDocuments(context.Background(), bson.M{"email": bson.M{"$exists": true}, "mail": bson.M{"$exists": false}})
+	assert.NoError(t, err)
+	assert.Equal(t, 10, renamed)
+}
@@ -3,9 +3,12 @@ package mongodb
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -26,6 +29,11 @@ type (
 		FindMany(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]T, error)
 	}
 
+	FindManyN[T Document[T]] interface {
+		// Like [FindMany], but preallocates the result for the expected number of documents.
+		FindManyN(ctx context.Context, filter bson.M, expectedCount int, opts ...*options.FindOptions) ([]T, error)
+	}
+
 	InsertOne[T Document[T]] interface {
 		// Inserts a document in the db.
 		// The document gets a new MongoID, if not already set, and the CreatedAt and UpdatedAt fields are set to the current time.
@@ -42,6 +50,17 @@ type (
 		InsertMany(ctx context.Context, docs []T, opts ...*options.InsertManyOptions) ([]T, error)
 	}
 
+	InsertManyResult[T Document[T]] interface {
+		// Like [InsertMany], but also returns the result of the driver, with the InsertedIDs in the order of the documents.
+		InsertManyResult(ctx context.Context, docs []T, opts ...*options.InsertManyOptions) ([]T, *mongo.InsertManyResult, error)
+	}
+
+	// InsertedIDSetter can be implemented by documents whose _id is generated by the driver or the server.
+	// InsertMany and InsertManyResult pass the generated _id to documents that have no _id after InitDocument.
+	InsertedIDSetter interface {
+		SetInsertedID(id interface{})
+	}
+
 	UpdateOne interface {
 		// Updates a single document that matches the given filter. updatedAt is automatically set to the current date for the updated document.
 		//
@@ -51,13 +70,24 @@ type (
 
 	UpdateMany interface {
 		// Updates multiple document that matches the given filter. updatedAt is automatically set to the current date for the updated documents.
+		// Use [UpdateManyResult] to get the number of matched and modified documents.
 		//
 		// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.UpdateMany]
 		UpdateMany(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) error
 	}
 
+	UpdateManyResult interface {
+		// Like [UpdateMany], but returns the result with the number of matched and modified documents.
+		// Since updatedAt is set on every matched document, ModifiedCount equals MatchedCount even for documents
+		// that already contained the new values.
+		//
+		// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.UpdateMany]
+		UpdateManyResult(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	}
+
 	ReplaceOne[T Document[T]] interface {
 		// Replaces the specified document.
+		// If filter matches no document and upsert is not set, [ErrNotFound] is returned.
 		//
 		// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.ReplaceOne]
 		ReplaceOne(ctx context.Context, filter bson.M, doc T, opts ...*options.ReplaceOptions) (T, error)
@@ -100,8 +130,44 @@ type (
 		CountDocuments(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int, error)
 	}
 
+	SessionBinder[T Document[T]] interface {
+		// Returns a copy of the repository whose operations all run within the given session.
+		WithSession(sess mongo.Session) RepositoryI[T]
+	}
+
+	// Reader is the dependency for code that only reads documents.
+	//
+	// Like the single-method interfaces it is composed of, Reader is meant to be declared as the dependency of a service
+	// instead of [RepositoryI], so that tests only have to fake the methods the service actually uses.
+	Reader[T Document[T]] interface {
+		FindOne[T]
+		FindMany[T]
+		Counter
+	}
+
+	// Writer is the dependency for code that only inserts, updates and deletes documents, see [Reader].
+	Writer[T Document[T]] interface {
+		InsertOne[T]
+		InsertMany[T]
+		UpdateOne
+		UpdateMany
+		DeleteOne
+		DeleteMany
+	}
+
+	// ReadWriter combines [Reader] and [Writer].
+	ReadWriter[T Document[T]] interface {
+		Reader[T]
+		Writer[T]
+	}
+
 	// RepositoryI is an interfaces for a single mongoDB collection. All mongodb operations are permitted on this repository
 	//
+	// RepositoryI only contains the operations every repository has to implement, and is not extended, so that
+	// implementations outside of this module keep compiling. Further operations, e.g. [GetByID], [FindIter] or
+	// [IndexManager], are separate interfaces, which [Repository] and the repositories wrapping it implement.
+	// Use [Capability] to get them from a RepositoryI.
+	//
 	// Please note that a repository always contains data for multiple company.
 	// Therefore, most query filters should filter for a specific companyID, see [mongodb.NewFilter]
 	RepositoryI[T Document[T]] interface {
@@ -124,58 +190,351 @@ type (
 	// Please note that a repository always contains data for multiple company.
 	// Therefore, most query filters should filter for a specific companyID, see [mongodb.NewFilter] and [mongodb.WithCompanyID]
 	Repository[T Document[T]] struct {
-		db *mongo.Collection
+		db                *mongo.Collection
+		hooks             []OperationHook
+		session           mongo.Session
+		uniqueViolations  map[string]string
+		aggregateDefaults *options.AggregateOptions
+		defaultComment    string
+		strict            *strictDecoder
+		clones            *collectionClones
+		transforms        []ResultTransform[T]
+		absoluteTimeout   time.Duration
+		fieldValidation   bool
+		maxResultSize     int
+		keepTruncated     bool
+		serverDeadlines   bool
+		stats             *statsCollector
+		requestTag        func(ctx context.Context) string
+		maxDocumentSize   int
+		cursorKeepAlive   bool
+		registry          *bsoncodec.Registry
 	}
 )
 
+// Compile-time checks that Repository implements RepositoryI and the interfaces that are not part of it.
+var (
+	_ RepositoryI[*BaseModel] = (*Repository[*BaseModel])(nil)
+	_ ReadWriter[*BaseModel]  = (*Repository[*BaseModel])(nil)
+	_ CollectionProvider      = (*Repository[*BaseModel])(nil)
+	_ OperationStatsReporter  = (*Repository[*BaseModel])(nil)
+	_ RegistryProvider        = (*Repository[*BaseModel])(nil)
+
+	_ GetByID[*BaseModel]          = (*Repository[*BaseModel])(nil)
+	_ FindManyN[*BaseModel]        = (*Repository[*BaseModel])(nil)
+	_ FindManyRaw                  = (*Repository[*BaseModel])(nil)
+	_ CursorFinder                 = (*Repository[*BaseModel])(nil)
+	_ ParallelFinder[*BaseModel]   = (*Repository[*BaseModel])(nil)
+	_ InsertManyResult[*BaseModel] = (*Repository[*BaseModel])(nil)
+	_ UpdateManyResult             = (*Repository[*BaseModel])(nil)
+	_ FindOneOrCreate[*BaseModel]  = (*Repository[*BaseModel])(nil)
+	_ UpsertManyByKey[*BaseModel]  = (*Repository[*BaseModel])(nil)
+	_ FilterQuerier[*BaseModel]    = (*Repository[*BaseModel])(nil)
+	_ DeleteManyByIDs              = (*Repository[*BaseModel])(nil)
+	_ DeleteManyAudited            = (*Repository[*BaseModel])(nil)
+	_ Watcher                      = (*Repository[*BaseModel])(nil)
+	_ CollectionAdmin              = (*Repository[*BaseModel])(nil)
+	_ Verifier                     = (*Repository[*BaseModel])(nil)
+	_ Distincter                   = (*Repository[*BaseModel])(nil)
+	_ SessionBinder[*BaseModel]    = (*Repository[*BaseModel])(nil)
+	_ IndexManager                 = (*Repository[*BaseModel])(nil)
+)
+
 // Creates a new repository for the specified mongo collection.
-func NewRepository[T Document[T]](collection *mongo.Collection) RepositoryI[T] {
-	return &Repository[T]{
-		db: collection,
+//
+// T must be a pointer type, e.g. NewRepository[*User]. Methods like InitDocument have to modify the document,
+// which is not possible on the copies a value type would be passed as, so NewRepository panics for other types.
+func NewRepository[T Document[T]](collection *mongo.Collection, opts ...RepositoryOption) RepositoryI[T] {
+	mustBePointer[T]()
+
+	ops := &repositoryOption{}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+
+	r := &Repository[T]{
+		db:                collection,
+		hooks:             ops.hooks,
+		uniqueViolations:  ops.uniqueViolations,
+		aggregateDefaults: ops.aggregateDefaults,
+		defaultComment:    ops.defaultComment,
+		clones:            &collectionClones{clones: map[string]*mongo.Collection{}},
+		transforms:        resultTransformsFor[T](ops.resultTransforms),
+		absoluteTimeout:   ops.absoluteTimeout,
+		fieldValidation:   ops.fieldValidation,
+		maxResultSize:     ops.maxResultSize,
+		keepTruncated:     ops.keepTruncated,
+		serverDeadlines:   ops.serverDeadlines,
+		stats:             ops.stats,
+		requestTag:        ops.requestTag,
+		maxDocumentSize:   ops.maxDocumentSize,
+		cursorKeepAlive:   ops.cursorKeepAlive,
+		registry:          ops.registry,
+	}
+	if ops.registry != nil {
+		clone, err := collection.Clone(options.Collection().SetRegistry(ops.registry))
+		if err != nil {
+			panic(fmt.Sprintf("mongodb: registry for %v: %v", collection.Name(), err))
+		}
+		r.db = clone
+	}
+	if ops.strict {
+		decoder, err := newStrictDecoder(documentType[T](), r.Registry(), ops.onUnknown)
+		if err != nil {
+			panic(fmt.Sprintf("mongodb: strict decoding for %v: %v", documentType[T](), err))
+		}
+		r.strict = decoder
+	}
+
+	return r
+}
+
+// mustBePointer panics if T is not a pointer type.
+func mustBePointer[T any]() {
+	var zero T
+	t := reflect.TypeOf(&zero).Elem()
+	if t.Kind() != reflect.Pointer {
+		panic(fmt.Sprintf("mongodb: document type %v must be a pointer type, e.g. *%v, since InitDocument on a value only modifies a copy", t, t.Name()))
 	}
 }
 
-//func newTValue[T Document[T]]()
+// newTValue returns a usable value of T. For pointer types, a pointer to a new zero value is returned instead of nil.
+func newTValue[T any]() T {
+	var zero T
+	t := reflect.TypeOf(&zero).Elem()
+	if t.Kind() != reflect.Pointer {
+		return zero
+	}
+
+	return reflect.New(t.Elem()).Interface().(T)
+}
+
+// Returns a copy of the repository whose operations all run within the given session,
+// e.g. inside a transaction started with session.StartTransaction, without passing a mongo.SessionContext around.
+//
+// The returned repository is not affected by decorators that wrap this repository,
+// so a retrying decorator must not be used around operations that run inside a transaction:
+// a retry after the transaction was committed or aborted would run outside of it.
+func (r *Repository[T]) WithSession(sess mongo.Session) RepositoryI[T] {
+	bound := *r
+	bound.session = sess
+
+	return &bound
+}
 
 // Tries to find a Document that matches the given filter, and returns it.
 //
 // See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.FindOne]
 func (r *Repository[T]) FindOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (T, error) {
-	var res T
-	err := r.db.FindOne(ctx, filter, opts...).Decode(&res)
+	return r.findOne(ctx, "FindOne", filter, opts)
+}
+
+func (r *Repository[T]) findOne(ctx context.Context, name string, filter interface{}, opts []*options.FindOneOptions) (res T, err error) {
+	ctx, finish, err := r.begin(ctx, name, filter)
+	if err != nil {
+		return res, err
+	}
+	defer func() { err = finish(err) }()
+
+	if r.strict != nil {
+		var raw bson.Raw
+		if raw, err = r.CollectionFor(ctx).FindOne(ctx, filter, r.findOneOptions(ctx, opts)...).Raw(); err != nil {
+			return res, err
+		}
+		err = r.strict.decode(r.db.Name(), raw, &res)
+	} else {
+		err = r.CollectionFor(ctx).FindOne(ctx, filter, r.findOneOptions(ctx, opts)...).Decode(&res)
+	}
+	if err != nil || len(r.transforms) == 0 {
+		return res, err
+	}
 
-	return res, err
+	keep, err := r.transformResult(ctx, res)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if !keep {
+		var zero T
+		return zero, mongo.ErrNoDocuments
+	}
+
+	return res, nil
 }
 
 // Finds all Documents that match the given filter, and returns them as a slice.
 //
 // See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.Find]
 func (r *Repository[T]) FindMany(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]T, error) {
-	var res []T
-	cur, err := r.db.Find(ctx, filter, opts...)
+	return r.findMany(ctx, "FindMany", filter, opts)
+}
 
+func (r *Repository[T]) findMany(ctx context.Context, name string, filter interface{}, opts []*options.FindOptions) (res []T, err error) {
+	ctx, finish, err := r.begin(ctx, name, filter)
 	if err != nil {
 		return nil, err
 	}
+	defer func() { err = finish(err) }()
 
-	err = cur.All(ctx, &res)
+	opts, limited := r.limitResultSize(opts)
+	cur, err := r.CollectionFor(ctx).Find(ctx, filter, r.findOptions(ctx, opts)...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if r.strict != nil || len(r.transforms) > 0 {
+		res, err = r.decodeAll(ctx, cur, 0)
+	} else {
+		err = cur.All(ctx, &res)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	if limited {
+		return r.checkResultSize(res)
+	}
 	return res, nil
 }
 
+// decodeAll decodes all documents of cur, strictly if enabled and with the result transforms, into a slice with the given capacity.
+func (r *Repository[T]) decodeAll(ctx context.Context, cur *mongo.Cursor, capacity int) ([]T, error) {
+	defer cur.Close(context.Background())
+
+	var res []T
+	if capacity > 0 {
+		res = make([]T, 0, capacity)
+	}
+	for cur.Next(ctx) {
+		doc, keep, err := r.decodeResult(ctx, cur)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			res = append(res, doc)
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// decodeResult decodes the current document of cur and applies the result transforms, see [WithResultTransform].
+func (r *Repository[T]) decodeResult(ctx context.Context, cur *mongo.Cursor) (T, bool, error) {
+	doc, err := r.decode(cur)
+	if err != nil {
+		return doc, false, err
+	}
+
+	keep, err := r.transformResult(ctx, doc)
+	return doc, keep, err
+}
+
+// resultDecoder is implemented by [Repository] and the repositories embedding a [Forwarder],
+// so that functions taking a RepositoryI decode results like the repository does.
+type resultDecoder[T Document[T]] interface {
+	decodeResult(ctx context.Context, cur *mongo.Cursor) (T, bool, error)
+}
+
+// decodeResultOf decodes the current document of cur like r, strictly and with the result transforms of r if enabled.
+// For other repositories, it decodes the document with the registry of the cursor.
+func decodeResultOf[T Document[T]](ctx context.Context, r RepositoryI[T], cur *mongo.Cursor) (T, bool, error) {
+	if decoder, ok := r.(resultDecoder[T]); ok {
+		return decoder.decodeResult(ctx, cur)
+	}
+
+	doc := newTValue[T]()
+	if err := cur.Decode(doc); err != nil {
+		return doc, false, err
+	}
+	return doc, true, nil
+}
+
+// decodeSingleResult is like decodeResult, for the document of a single result, e.g. of FindOneAndUpdate.
+func (r *Repository[T]) decodeSingleResult(ctx context.Context, res *mongo.SingleResult) (doc T, keep bool, err error) {
+	if r.strict != nil {
+		var raw bson.Raw
+		if raw, err = res.Raw(); err == nil {
+			err = r.strict.decode(r.db.Name(), raw, &doc)
+		}
+	} else {
+		err = res.Decode(&doc)
+	}
+	if err != nil {
+		return doc, false, err
+	}
+
+	keep, err = r.transformResult(ctx, doc)
+	return doc, keep, err
+}
+
+// decode decodes the current document of cur, strictly if enabled.
+func (r *Repository[T]) decode(cur *mongo.Cursor) (doc T, err error) {
+	if r.strict != nil {
+		err = r.strict.decode(r.db.Name(), cur.Current, &doc)
+	} else {
+		err = cur.Decode(&doc)
+	}
+	return doc, err
+}
+
+// maxHintBatchSize caps the cursor batch size FindManyN derives from the expected count.
+const maxHintBatchSize = 1000
+
+// Like [Repository.FindMany], but preallocates the result slice for expectedCount documents.
+//
+// Unless the options set a batch size, the cursor batch size is set to expectedCount, at most 1000,
+// so that small results arrive in a single batch. The result is the same as for FindMany.
+func (r *Repository[T]) FindManyN(ctx context.Context, filter bson.M, expectedCount int, opts ...*options.FindOptions) (res []T, err error) {
+	ctx, finish, err := r.begin(ctx, "FindManyN", filter)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = finish(err) }()
+
+	if expectedCount > 0 && options.MergeFindOptions(opts...).BatchSize == nil {
+		batchSize := expectedCount
+		if batchSize > maxHintBatchSize {
+			batchSize = maxHintBatchSize
+		}
+		opts = append(opts, options.Find().SetBatchSize(int32(batchSize)))
+	}
+
+	opts, limited := r.limitResultSize(opts)
+	cur, err := r.CollectionFor(ctx).Find(ctx, filter, r.findOptions(ctx, opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err = r.decodeAll(ctx, cur, expectedCount)
+	if err != nil || !limited {
+		return res, err
+	}
+	return r.checkResultSize(res)
+}
+
 // Inserts a document in the db.
 // The document gets a new MongoID, and the CreatedAt and UpdatedAt fields are set to the current time.
 //
 // See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.InsertOne]
-func (r *Repository[T]) InsertOne(ctx context.Context, doc T, opts ...*options.InsertOneOptions) (T, error) {
+func (r *Repository[T]) InsertOne(ctx context.Context, doc T, opts ...*options.InsertOneOptions) (_ T, err error) {
+	ctx, finish, err := r.begin(ctx, "InsertOne", nil)
+	if err != nil {
+		return doc, err
+	}
+	defer func() { err = finish(err) }()
+
 	doc.InitDocument()
+	if err := r.checkDocumentSize(ctx, 0, doc); err != nil {
+		return doc, fmt.Errorf("%v: %w", "mongodb.Repository.InsertOne", err)
+	}
 
-	_, err := r.db.InsertOne(ctx, doc, opts...)
+	_, err = r.CollectionFor(ctx).InsertOne(ctx, doc, opts...)
 	if err != nil {
-		return doc, err
+		return doc, r.mapUniqueViolation(err)
 	}
 
 	return doc, nil
@@ -186,26 +545,90 @@ func (r *Repository[T]) InsertOne(ctx context.Context, doc T, opts ...*options.I
 //
 // See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.InsertMany]
 func (r *Repository[T]) InsertMany(ctx context.Context, documents []T, opts ...*options.InsertManyOptions) ([]T, error) {
+	docs, _, err := r.insertMany(ctx, "InsertMany", documents, opts)
+	return docs, err
+}
+
+// Like [Repository.InsertMany], but also returns the result of the driver.
+//
+// Documents that implement [InsertedIDSetter] and have no _id after InitDocument get the _id the driver generated.
+// For an empty slice of documents, an empty result is returned.
+func (r *Repository[T]) InsertManyResult(ctx context.Context, documents []T, opts ...*options.InsertManyOptions) ([]T, *mongo.InsertManyResult, error) {
+	return r.insertMany(ctx, "InsertManyResult", documents, opts)
+}
+
+func (r *Repository[T]) insertMany(ctx context.Context, name string, documents []T, opts []*options.InsertManyOptions) (_ []T, _ *mongo.InsertManyResult, err error) {
 	if len(documents) <= 0 {
 		// mongoDB does not allow inserting 0 documents, but that is not an error for us.
-		return nil, nil
+		return nil, &mongo.InsertManyResult{}, nil
 	}
 
-	docs := make([]interface{}, len(documents))
+	ctx, finish, err := r.begin(ctx, name, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { err = finish(err) }()
+
+	docs := getInsertBuffer(len(documents))
+	defer putInsertBuffer(docs)
 
+	var missingIDs []int
 	for i := range documents {
 		doc := documents[i]
 		doc.InitDocument()
+		if err := r.checkDocumentSize(ctx, i, doc); err != nil {
+			return nil, nil, fmt.Errorf("%v: %w", "mongodb.Repository."+name, err)
+		}
+
+		if _, ok := any(doc).(InsertedIDSetter); ok {
+			if _, err := documentID(r.Registry(), doc); err != nil {
+				missingIDs = append(missingIDs, i)
+			}
+		}
 
-		docs[i] = doc
+		*docs = append(*docs, doc)
 	}
 
-	_, err := r.db.InsertMany(ctx, docs, opts...)
+	res, err := r.CollectionFor(ctx).InsertMany(ctx, *docs, opts...)
 	if err != nil {
-		return nil, err
+		return nil, res, r.mapUniqueViolation(err)
+	}
+
+	for _, i := range missingIDs {
+		if i < len(res.InsertedIDs) {
+			any(documents[i]).(InsertedIDSetter).SetInsertedID(res.InsertedIDs[i])
+		}
 	}
 
-	return documents, nil
+	return documents, res, nil
+}
+
+// insertBuffers pools the []interface{} InsertMany hands to the driver.
+var insertBuffers = sync.Pool{
+	New: func() interface{} { return new([]interface{}) },
+}
+
+// maxPooledInsertBuffer is the largest buffer that is returned to the pool, larger ones are left to the garbage collector.
+const maxPooledInsertBuffer = 10000
+
+func getInsertBuffer(size int) *[]interface{} {
+	buf := insertBuffers.Get().(*[]interface{})
+	if cap(*buf) < size {
+		*buf = make([]interface{}, 0, size)
+	}
+	return buf
+}
+
+func putInsertBuffer(buf *[]interface{}) {
+	if cap(*buf) > maxPooledInsertBuffer {
+		return
+	}
+	// Drop the references to the documents before pooling.
+	for i := range *buf {
+		(*buf)[i] = nil
+	}
+	*buf = (*buf)[:0]
+	insertBuffers.Put(buf)
 }
 
 // Updates a single document that matches the given filter. updatedAt is automatically set to the current date for the updated document.
@@ -213,29 +636,102 @@ func (r *Repository[T]) InsertMany(ctx context.Context, documents []T, opts ...*
 //
 // See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.UpdateOne]
 func (r *Repository[T]) UpdateOne(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
-	updateResult, err := r.db.UpdateOne(ctx, filter, bson.M{"$set": data, "$currentDate": bson.M{"updatedAt": true}}, opts...)
+	return r.updateOne(ctx, "UpdateOne", filter, data, opts)
+}
+
+func (r *Repository[T]) updateOne(ctx context.Context, name string, filter interface{}, data primitive.M, opts []*options.UpdateOptions) (_ *mongo.UpdateResult, err error) {
+	ctx, finish, err := r.begin(ctx, name, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = finish(err) }()
+
+	if err := r.validateUpdateFields(data); err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.Repository."+name, err)
+	}
+
+	updateResult, err := r.CollectionFor(ctx).UpdateOne(ctx, filter, bson.M{"$set": data, "$currentDate": bson.M{"updatedAt": true}}, r.updateOptions(ctx, opts)...)
 	if err != nil {
-		return updateResult, fmt.Errorf("%v: %w", "mongodb.Repository.UpdateOne", err)
+		return updateResult, fmt.Errorf("%v: %w", "mongodb.Repository."+name, r.mapUniqueViolation(err))
 	}
 
 	return updateResult, nil
 }
 
 // Updates multiple document that matches the given filter. updatedAt is automatically set to the current date for the updated documents.
+// Use [Repository.UpdateManyResult] to get the number of matched and modified documents.
 //
 // See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.UpdateMany]
 func (r *Repository[T]) UpdateMany(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) error {
-	_, err := r.db.UpdateMany(ctx, filter, bson.M{"$set": data, "$currentDate": bson.M{"updatedAt": true}}, opts...)
+	_, err := r.updateMany(ctx, "UpdateMany", filter, data, opts)
 	return err
 }
 
+// Like [Repository.UpdateMany], but returns the result with the number of matched and modified documents.
+// Since updatedAt is set on every matched document, ModifiedCount always equals MatchedCount,
+// even for documents that already contained the new values.
+//
+// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.UpdateMany]
+func (r *Repository[T]) UpdateManyResult(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return r.updateMany(ctx, "UpdateManyResult", filter, data, opts)
+}
+
+func (r *Repository[T]) updateMany(ctx context.Context, name string, filter interface{}, data primitive.M, opts []*options.UpdateOptions) (_ *mongo.UpdateResult, err error) {
+	ctx, finish, err := r.begin(ctx, name, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = finish(err) }()
+
+	if err := r.validateUpdateFields(data); err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.Repository."+name, err)
+	}
+
+	updateResult, err := r.CollectionFor(ctx).UpdateMany(ctx, filter, bson.M{"$set": data, "$currentDate": bson.M{"updatedAt": true}}, r.updateOptions(ctx, opts)...)
+	if err != nil {
+		return updateResult, fmt.Errorf("%v: %w", "mongodb.Repository."+name, r.mapUniqueViolation(err))
+	}
+
+	return updateResult, nil
+}
+
 // Replaces the specified document.
 //
+// If filter matches no document and upsert is not set, [ErrNotFound] is returned.
+// updatedAt is set to the current client time before the document is replaced.
+// If the replacement fails, updatedAt is restored on documents with a GetUpdatedAt method, like [BaseModel].
+//
 // See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.ReplaceOne]
-func (r *Repository[T]) ReplaceOne(ctx context.Context, filter bson.M, doc T, opts ...*options.ReplaceOptions) (T, error) {
-	doc.SetUpdatedAt(time.Now())
-	_, err := r.db.ReplaceOne(ctx, filter, doc, opts...)
-	return doc, err
+func (r *Repository[T]) ReplaceOne(ctx context.Context, filter bson.M, doc T, opts ...*options.ReplaceOptions) (_ T, err error) {
+	ctx, finish, err := r.begin(ctx, "ReplaceOne", filter)
+	if err != nil {
+		return doc, err
+	}
+	defer func() { err = finish(err) }()
+
+	if err := r.checkDocumentSize(ctx, 0, doc); err != nil {
+		return doc, fmt.Errorf("%v: %w", "mongodb.Repository.ReplaceOne", err)
+	}
+
+	if previous, ok := interface{}(doc).(interface{ GetUpdatedAt() time.Time }); ok {
+		updatedAt := previous.GetUpdatedAt()
+		defer func() {
+			if err != nil {
+				doc.SetUpdatedAt(updatedAt)
+			}
+		}()
+	}
+
+	doc.SetUpdatedAt(now())
+	res, err := r.CollectionFor(ctx).ReplaceOne(ctx, filter, doc, opts...)
+	if err != nil {
+		return doc, r.mapUniqueViolation(err)
+	}
+	if res.MatchedCount == 0 && res.UpsertedCount == 0 {
+		return doc, fmt.Errorf("%v: %v: %w", "mongodb.Repository.ReplaceOne", r.db.Name(), ErrNotFound)
+	}
+
+	return doc, nil
 }
 
 // Deletes one document that matches the given filter
@@ -245,7 +741,18 @@ func (r *Repository[T]) DeleteOne(ctx context.Context, filter bson.M, opts ...*o
 	if len(filter) == 0 {
 		return fmt.Errorf("DeleteOne: Filter can not be empty. Filter: %v", filter)
 	}
-	_, err := r.db.DeleteOne(ctx, filter, opts...)
+
+	return r.deleteOne(ctx, "DeleteOne", filter, opts)
+}
+
+func (r *Repository[T]) deleteOne(ctx context.Context, name string, filter interface{}, opts []*options.DeleteOptions) (err error) {
+	ctx, finish, err := r.begin(ctx, name, filter)
+	if err != nil {
+		return err
+	}
+	defer func() { err = finish(err) }()
+
+	_, err = r.CollectionFor(ctx).DeleteOne(ctx, filter, opts...)
 	return err
 }
 
@@ -256,7 +763,17 @@ func (r *Repository[T]) DeleteMany(ctx context.Context, filter bson.M, opts ...*
 	/* if len(filter) == 0 {
 		return 0, fmt.Errorf("DeleteMany: Filter can not be empty. Filter: %v", filter)
 	} */
-	res, err := r.db.DeleteMany(ctx, filter, opts...)
+	return r.deleteMany(ctx, "DeleteMany", filter, opts)
+}
+
+func (r *Repository[T]) deleteMany(ctx context.Context, name string, filter interface{}, opts []*options.DeleteOptions) (_ int, err error) {
+	ctx, finish, err := r.begin(ctx, name, filter)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { err = finish(err) }()
+
+	res, err := r.CollectionFor(ctx).DeleteMany(ctx, filter, opts...)
 	if err != nil {
 		return 0, err
 	}
@@ -268,26 +785,55 @@ func (r *Repository[T]) DeleteMany(ctx context.Context, filter bson.M, opts ...*
 // See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.Bulkwrite]
 //
 // While the mongo-Method returns an error if 0 operations are passed, this method returns an empty result and no error.
-func (r *Repository[T]) BulkWrite(ctx context.Context, Documents []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+func (r *Repository[T]) BulkWrite(ctx context.Context, Documents []mongo.WriteModel, opts ...*options.BulkWriteOptions) (_ *mongo.BulkWriteResult, err error) {
 
 	if len(Documents) == 0 {
 		return &mongo.BulkWriteResult{}, nil
 	}
 
-	return r.db.BulkWrite(ctx, Documents, opts...)
+	ctx, finish, err := r.begin(ctx, "BulkWrite", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = finish(err) }()
+
+	res, err := r.CollectionFor(ctx).BulkWrite(ctx, Documents, opts...)
+	return res, r.mapUniqueViolation(err)
 }
 
 // Runs an aggregation pipeline.
 //
 // See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.Aggregate]
-func (r *Repository[T]) Aggregate(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
-	return r.db.Aggregate(ctx, pipeline, opts...)
+func (r *Repository[T]) Aggregate(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.AggregateOptions) (_ *mongo.Cursor, err error) {
+	ctx, finish, err := r.begin(ctx, "Aggregate", pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = finish(err) }()
+
+	return r.aggregate(ctx, pipeline, opts)
+}
+
+// aggregate runs pipeline with the aggregate defaults, for Aggregate and the operations that aggregate internally.
+func (r *Repository[T]) aggregate(ctx context.Context, pipeline mongo.Pipeline, opts []*options.AggregateOptions) (*mongo.Cursor, error) {
+	cur, err := r.CollectionFor(ctx).Aggregate(ctx, pipeline, r.aggregateOptions(ctx, opts)...)
+	return cur, mapMemoryLimitError(err)
 }
 
 // Returns the number of documents that match the given filter.
 //
 // See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.CountDocuments]
 func (r *Repository[T]) CountDocuments(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int, error) {
-	count, err := r.db.CountDocuments(ctx, filter, opts...)
+	return r.countDocuments(ctx, "CountDocuments", filter, opts)
+}
+
+func (r *Repository[T]) countDocuments(ctx context.Context, name string, filter interface{}, opts []*options.CountOptions) (_ int, err error) {
+	ctx, finish, err := r.begin(ctx, name, filter)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { err = finish(err) }()
+
+	count, err := r.CollectionFor(ctx).CountDocuments(ctx, filter, r.countOptions(ctx, opts)...)
 	return int(count), err
 }
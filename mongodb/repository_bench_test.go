@@ -0,0 +1,131 @@
+package mongodb_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const benchmarkDocuments = 100_000
+
+// benchmarkRepository seeds a collection with benchmarkDocuments small users.
+func benchmarkRepository(b *testing.B) *mongodb.Repository[*User] {
+	b.Helper()
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		b.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	col := client.Database("testdb").Collection("user_benchmark")
+	b.Cleanup(func() {
+		col.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+
+	repo := mongodb.NewRepository[*User](col).(*mongodb.Repository[*User])
+	users := make([]*User, benchmarkDocuments)
+	for i := range users {
+		users[i] = &User{Name: fmt.Sprintf("User %d", i)}
+	}
+	if _, err := repo.InsertMany(ctx, users); err != nil {
+		b.Fatalf("Error on inserting users: %v", err)
+	}
+
+	return repo
+}
+
+func BenchmarkFindMany(b *testing.B) {
+	ctx := context.Background()
+	repo := benchmarkRepository(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repo.FindMany(ctx, bson.M{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFindManyN(b *testing.B) {
+	ctx := context.Background()
+	repo := benchmarkRepository(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repo.FindManyN(ctx, bson.M{}, benchmarkDocuments); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFindOneByID(b *testing.B) {
+	ctx := context.Background()
+	repo := benchmarkRepository(b)
+	user, err := repo.FindOne(ctx, bson.M{})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repo.FindOne(ctx, mongodb.MongoIDFilter(user.GetMongoID())); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetByID(b *testing.B) {
+	ctx := context.Background()
+	repo := benchmarkRepository(b)
+	user, err := repo.FindOne(ctx, bson.M{})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repo.GetByID(ctx, user.GetMongoID()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestFindManyN(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_find_many_n")).(*mongodb.Repository[*User])
+
+	users := make([]*User, 50)
+	for i := range users {
+		users[i] = &User{Name: fmt.Sprintf("User %d", i)}
+	}
+	if _, err := repo.InsertMany(ctx, users); err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	sorted := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	expected, err := repo.FindMany(ctx, bson.M{}, sorted)
+	if err != nil {
+		t.Fatalf("Error on finding users: %v", err)
+	}
+
+	for _, hint := range []int{0, 10, 50, 5000} {
+		found, err := repo.FindManyN(ctx, bson.M{}, hint, sorted)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, found, "hint %d", hint)
+	}
+
+	found, err := repo.FindManyN(ctx, bson.M{"name": "nobody"}, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+}
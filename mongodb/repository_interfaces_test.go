@@ -0,0 +1,124 @@
+package mongodb_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	_ mongodb.RepositoryI[*User]     = (*mongodb.Repository[*User])(nil)
+	_ mongodb.ReadWriter[*User]      = (*mongodb.Repository[*User])(nil)
+	_ mongodb.ReadWriter[*User]      = mongodb.RepositoryI[*User](nil)
+	_ mongodb.CollectionProvider     = (*mongodb.Repository[*User])(nil)
+	_ mongodb.OperationStatsReporter = (*mongodb.Repository[*User])(nil)
+	_ mongodb.RegistryProvider       = (*mongodb.Repository[*User])(nil)
+)
+
+// outsideRepositoryI are the exported methods of Repository that are not part of RepositoryI, with the interface they belong to.
+var outsideRepositoryI = map[string]reflect.Type{
+	"CollectionFor":       reflect.TypeOf((*mongodb.CollectionProvider)(nil)).Elem(),
+	"OperationStats":      reflect.TypeOf((*mongodb.OperationStatsReporter)(nil)).Elem(),
+	"ResetOperationStats": reflect.TypeOf((*mongodb.OperationStatsReporter)(nil)).Elem(),
+	"Registry":            reflect.TypeOf((*mongodb.RegistryProvider)(nil)).Elem(),
+	"WithSession":         reflect.TypeOf((*mongodb.SessionBinder[*User])(nil)).Elem(),
+
+	"GetByID":             reflect.TypeOf((*mongodb.GetByID[*User])(nil)).Elem(),
+	"FindManyN":           reflect.TypeOf((*mongodb.FindManyN[*User])(nil)).Elem(),
+	"FindManyRaw":         reflect.TypeOf((*mongodb.FindManyRaw)(nil)).Elem(),
+	"FindCursor":          reflect.TypeOf((*mongodb.CursorFinder)(nil)).Elem(),
+	"FindManyParallel":    reflect.TypeOf((*mongodb.ParallelFinder[*User])(nil)).Elem(),
+	"InsertManyResult":    reflect.TypeOf((*mongodb.InsertManyResult[*User])(nil)).Elem(),
+	"UpdateManyResult":    reflect.TypeOf((*mongodb.UpdateManyResult)(nil)).Elem(),
+	"FindOneOrCreate":     reflect.TypeOf((*mongodb.FindOneOrCreate[*User])(nil)).Elem(),
+	"UpsertManyByKey":     reflect.TypeOf((*mongodb.UpsertManyByKey[*User])(nil)).Elem(),
+	"FindOneWhere":        reflect.TypeOf((*mongodb.FilterQuerier[*User])(nil)).Elem(),
+	"FindManyWhere":       reflect.TypeOf((*mongodb.FilterQuerier[*User])(nil)).Elem(),
+	"UpdateOneWhere":      reflect.TypeOf((*mongodb.FilterQuerier[*User])(nil)).Elem(),
+	"UpdateManyWhere":     reflect.TypeOf((*mongodb.FilterQuerier[*User])(nil)).Elem(),
+	"DeleteOneWhere":      reflect.TypeOf((*mongodb.FilterQuerier[*User])(nil)).Elem(),
+	"DeleteManyWhere":     reflect.TypeOf((*mongodb.FilterQuerier[*User])(nil)).Elem(),
+	"CountWhere":          reflect.TypeOf((*mongodb.FilterQuerier[*User])(nil)).Elem(),
+	"DeleteManyByIDs":     reflect.TypeOf((*mongodb.DeleteManyByIDs)(nil)).Elem(),
+	"DeleteManyAudited":   reflect.TypeOf((*mongodb.DeleteManyAudited)(nil)).Elem(),
+	"Watch":               reflect.TypeOf((*mongodb.Watcher)(nil)).Elem(),
+	"Drop":                reflect.TypeOf((*mongodb.CollectionAdmin)(nil)).Elem(),
+	"Stats":               reflect.TypeOf((*mongodb.CollectionAdmin)(nil)).Elem(),
+	"Verify":              reflect.TypeOf((*mongodb.Verifier)(nil)).Elem(),
+	"Distinct":            reflect.TypeOf((*mongodb.Distincter)(nil)).Elem(),
+	"CreateIndex":         reflect.TypeOf((*mongodb.IndexManager)(nil)).Elem(),
+	"CreateIndexes":       reflect.TypeOf((*mongodb.IndexManager)(nil)).Elem(),
+	"DropIndex":           reflect.TypeOf((*mongodb.IndexManager)(nil)).Elem(),
+	"ListIndexes":         reflect.TypeOf((*mongodb.IndexManager)(nil)).Elem(),
+	"SetIndexExpireAfter": reflect.TypeOf((*mongodb.IndexManager)(nil)).Elem(),
+}
+
+// notForwarded are the methods of Repository that a Forwarder does not pass on.
+var notForwarded = map[string]bool{
+	"CollectionFor":       true,
+	"OperationStats":      true,
+	"ResetOperationStats": true,
+	"Registry":            true,
+	"WithSession":         true,
+}
+
+// TestRepositoryMethodsInInterfaces fails when a method is added to Repository without adding it to RepositoryI
+// or to a small interface of its own, so that code depending on the interfaces can use every method,
+// and when a Forwarder does not pass the method on.
+func TestRepositoryMethodsInInterfaces(t *testing.T) {
+	repo := reflect.TypeOf((**mongodb.Repository[*User])(nil)).Elem()
+	iface := reflect.TypeOf((*mongodb.RepositoryI[*User])(nil)).Elem()
+	forwarder := reflect.TypeOf((*mongodb.Forwarder[*User])(nil)).Elem()
+
+	for i := 0; i < repo.NumMethod(); i++ {
+		name := repo.Method(i).Name
+		if _, ok := iface.MethodByName(name); ok {
+			continue
+		}
+
+		other, ok := outsideRepositoryI[name]
+		if !assert.True(t, ok, "Repository.%v is neither part of RepositoryI nor of another interface", name) {
+			continue
+		}
+		_, ok = other.MethodByName(name)
+		assert.True(t, ok, "Repository.%v is not part of %v", name, other)
+
+		if !notForwarded[name] {
+			assert.True(t, forwarder.Implements(other), "Forwarder does not implement %v", other)
+		}
+	}
+}
+
+// coreRepository only implements RepositoryI, like a repository of another package that was written against it.
+type coreRepository struct {
+	mongodb.RepositoryI[*User]
+}
+
+func TestCapabilityUnsupported(t *testing.T) {
+	ctx := context.Background()
+	var core mongodb.RepositoryI[*User] = coreRepository{}
+
+	_, err := mongodb.Capability[mongodb.GetByID[*User]](core)
+	assert.ErrorIs(t, err, mongodb.ErrUnsupported)
+	getter, err := mongodb.Capability[mongodb.GetByID[*User]](mongodb.NewRepository[*User](nil))
+	assert.NoError(t, err)
+	assert.NotNil(t, getter)
+
+	// A Forwarder implements every capability, its operations report what the embedded repository does not support.
+	forwarder := mongodb.Forwarder[*User]{RepositoryI: core}
+	getter, err = mongodb.Capability[mongodb.GetByID[*User]](forwarder)
+	assert.NoError(t, err)
+	_, err = getter.GetByID(ctx, primitive.NewObjectID())
+	assert.ErrorIs(t, err, mongodb.ErrUnsupported)
+	_, err = forwarder.GetByID(ctx, primitive.NewObjectID())
+	assert.ErrorIs(t, err, mongodb.ErrUnsupported)
+	_, err = forwarder.CreateIndex(ctx, bson.D{{Key: "email", Value: 1}})
+	assert.ErrorIs(t, err, mongodb.ErrUnsupported)
+
+	assert.Panics(t, func() { mongodb.WithSession(core, nil) })
+}
@@ -3,6 +3,7 @@ package mongodb_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-txdb"
 	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
@@ -171,3 +172,118 @@ func TestReplaceUser(t *testing.T) {
 	}
 }
 
+func TestUpdateManyResult(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_update_many_result")).(*mongodb.Repository[*User])
+
+	_, err := repo.InsertMany(ctx, []*User{
+		{Name: "Alice", Email: "same@example.com"},
+		{Name: "Bob", Email: "same@example.com"},
+		{Name: "Carol", Email: "other@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	res, err := repo.UpdateManyResult(ctx, primitive.M{"name": primitive.M{"$in": []string{"Alice", "Bob"}}}, primitive.M{"email": "same@example.com"})
+	if err != nil {
+		t.Fatalf("Error on updating users: %v", err)
+	}
+	assert.Equal(t, int64(2), res.MatchedCount)
+	// The values did not change, but updatedAt is always bumped.
+	assert.Equal(t, int64(2), res.ModifiedCount)
+
+	res, err = repo.UpdateManyResult(ctx, primitive.M{"name": "nobody"}, primitive.M{"email": "x"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), res.MatchedCount)
+}
+
+// ServerIDEvent leaves generating the _id to the driver.
+type ServerIDEvent struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	CreatedAt time.Time          `bson:"createdAt"`
+	UpdatedAt time.Time          `bson:"updatedAt"`
+	Name      string             `bson:"name"`
+}
+
+func (e *ServerIDEvent) InitMongoID()                     {}
+func (e *ServerIDEvent) ResetMongoID()                    { e.ID = primitive.NilObjectID }
+func (e *ServerIDEvent) SetCreatedAt(createdAt time.Time) { e.CreatedAt = createdAt }
+func (e *ServerIDEvent) SetUpdatedAt(updatedAt time.Time) { e.UpdatedAt = updatedAt }
+func (e *ServerIDEvent) InitDocument() {
+	now := time.Now()
+	e.SetCreatedAt(now)
+	e.SetUpdatedAt(now)
+}
+func (e *ServerIDEvent) SetInsertedID(id interface{}) { e.ID = id.(primitive.ObjectID) }
+
+func TestInsertManyResult(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_insert_many_result")).(*mongodb.Repository[*User])
+
+	users, res, err := repo.InsertManyResult(ctx, []*User{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}})
+	if err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+	if assert.Len(t, res.InsertedIDs, 3) {
+		for i, u := range users {
+			assert.Equal(t, u.MongoID, res.InsertedIDs[i])
+		}
+	}
+
+	_, res, err = repo.InsertManyResult(ctx, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, res.InsertedIDs)
+}
+
+func TestInsertManyResultGeneratedIDs(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*ServerIDEvent](testCollection(t, "event_insert_many_result")).(*mongodb.Repository[*ServerIDEvent])
+
+	events, res, err := repo.InsertManyResult(ctx, []*ServerIDEvent{{Name: "first"}, {Name: "second"}})
+	if err != nil {
+		t.Fatalf("Error on inserting events: %v", err)
+	}
+
+	for i, e := range events {
+		assert.False(t, e.ID.IsZero())
+		assert.Equal(t, res.InsertedIDs[i], e.ID)
+
+		found, err := repo.FindOne(ctx, primitive.M{"_id": e.ID})
+		assert.NoError(t, err)
+		assert.Equal(t, e.Name, found.Name)
+	}
+}
+
+func TestReplaceOneResult(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_replace_result"))
+
+	user, err := repo.InsertOne(ctx, &User{Name: "Alice"})
+	if err != nil {
+		t.Fatalf("Error on inserting user: %v", err)
+	}
+	inserted := user.UpdatedAt
+
+	// Matched
+	time.Sleep(10 * time.Millisecond)
+	user.Name = "Alice2"
+	replaced, err := repo.ReplaceOne(ctx, mongodb.MongoIDFilter(user.MongoID), user)
+	assert.NoError(t, err)
+	assert.True(t, replaced.UpdatedAt.After(inserted))
+
+	// Unmatched
+	missing := &User{Name: "Bob"}
+	_, err = repo.ReplaceOne(ctx, mongodb.MongoIDFilter(primitive.NewObjectID()), missing)
+	assert.ErrorIs(t, err, mongodb.ErrNotFound)
+	assert.True(t, missing.UpdatedAt.IsZero(), "the document must not be modified on failure")
+
+	// Upsert
+	upserted, err := repo.ReplaceOne(ctx, primitive.M{"name": "Carol"}, &User{Name: "Carol"}, options.Replace().SetUpsert(true))
+	assert.NoError(t, err)
+	assert.False(t, upserted.UpdatedAt.IsZero())
+
+	count, err := repo.CountDocuments(ctx, primitive.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
@@ -0,0 +1,117 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// RollupBookmark records the last successful run of an [IncrementalRollup].
+	RollupBookmark struct {
+		BaseModel `bson:",inline"`
+		// Name identifies the rollup.
+		Name string `bson:"name"`
+		// Since is the time the last successful run started, the next run processes the documents updated since then.
+		Since time.Time `bson:"since"`
+	}
+)
+
+// RunRollup runs pipeline on src and writes its results into targetCollection of the same database with a $merge stage,
+// e.g. to maintain a materialized per-company daily rollup.
+//
+// mergeOn are the fields that identify a result document in the target, the target needs a unique index on them.
+// No mergeOn merges on _id. whenMatched is one of replace, keepExisting, merge and fail, whenNotMatched one of insert,
+// discard and fail, "" uses the default of the server, merge and insert.
+//
+// See [https://www.mongodb.com/docs/manual/reference/operator/aggregation/merge/]
+func RunRollup(ctx context.Context, src Aggregater, pipeline mongo.Pipeline, targetCollection string, mergeOn []string, whenMatched, whenNotMatched string) error {
+	merge, err := mergeStage(targetCollection, mergeOn, whenMatched, whenNotMatched)
+	if err != nil {
+		return fmt.Errorf("%v: %w", "mongodb.RunRollup", err)
+	}
+
+	stages := make(mongo.Pipeline, 0, len(pipeline)+1)
+	stages = append(append(stages, pipeline...), merge)
+
+	cur, err := src.Aggregate(ctx, stages, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return fmt.Errorf("%v: %w", "mongodb.RunRollup", err)
+	}
+	// $merge returns no documents, but errors of the later batches are only reported by the cursor.
+	if err := cur.All(ctx, &[]bson.Raw{}); err != nil {
+		return fmt.Errorf("%v: %w", "mongodb.RunRollup", err)
+	}
+
+	return nil
+}
+
+// IncrementalRollup is like [RunRollup], but only processes the source documents whose updatedAt changed since the last
+// successful run of the rollup with the given name. The time of the run is recorded in bookmarks, but only if the run succeeds,
+// so a failed run is repeated as a whole by the next one. The first run processes all documents.
+//
+// The $match on updatedAt is put in front of pipeline, so pipeline only sees the changed documents. Its results have to be
+// correct for them alone, e.g. a whenMatched of merge that sets fields derived from a single source document.
+// Since documents updated during a run are processed again by the next run, the rollup has to be idempotent.
+// updatedAt is compared with the time of the [Clock], so clocks of the clients and the server should be synchronized.
+func IncrementalRollup(ctx context.Context, src Aggregater, bookmarks RepositoryI[*RollupBookmark], name string, pipeline mongo.Pipeline, targetCollection string, mergeOn []string, whenMatched, whenNotMatched string) error {
+	start := now()
+
+	bookmark, err := bookmarks.FindOne(ctx, bson.M{"name": name})
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		bookmark = &RollupBookmark{Name: name}
+	case err != nil:
+		return fmt.Errorf("%v: reading bookmark %q: %w", "mongodb.IncrementalRollup", name, err)
+	}
+
+	stages := pipeline
+	if !bookmark.Since.IsZero() {
+		stages = append(mongo.Pipeline{{{Key: "$match", Value: bson.M{"updatedAt": bson.M{"$gte": bookmark.Since}}}}}, pipeline...)
+	}
+	if err := RunRollup(ctx, src, stages, targetCollection, mergeOn, whenMatched, whenNotMatched); err != nil {
+		return fmt.Errorf("%v: %w", "mongodb.IncrementalRollup", err)
+	}
+
+	// MongoDB stores milliseconds, truncating keeps documents updated in the same millisecond as start in the next run.
+	bookmark.Since = start.Truncate(time.Millisecond)
+	if _, err := bookmarks.ReplaceOne(ctx, bson.M{"name": name}, bookmark, options.Replace().SetUpsert(true)); err != nil {
+		return fmt.Errorf("%v: writing bookmark %q: %w", "mongodb.IncrementalRollup", name, err)
+	}
+
+	return nil
+}
+
+func mergeStage(targetCollection string, mergeOn []string, whenMatched, whenNotMatched string) (bson.D, error) {
+	if targetCollection == "" {
+		return nil, errors.New("no target collection")
+	}
+
+	merge := bson.D{{Key: "into", Value: targetCollection}}
+	if len(mergeOn) > 0 {
+		merge = append(merge, bson.E{Key: "on", Value: mergeOn})
+	}
+
+	switch whenMatched {
+	case "":
+	case "replace", "keepExisting", "merge", "fail":
+		merge = append(merge, bson.E{Key: "whenMatched", Value: whenMatched})
+	default:
+		return nil, fmt.Errorf("invalid whenMatched %q", whenMatched)
+	}
+
+	switch whenNotMatched {
+	case "":
+	case "insert", "discard", "fail":
+		merge = append(merge, bson.E{Key: "whenNotMatched", Value: whenNotMatched})
+	default:
+		return nil, fmt.Errorf("invalid whenNotMatched %q", whenNotMatched)
+	}
+
+	return bson.D{{Key: "$merge", Value: merge}}, nil
+}
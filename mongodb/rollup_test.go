@@ -0,0 +1,125 @@
+package mongodb_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// pipelineRecorder is an Aggregater that records the pipeline and returns an empty cursor.
+type pipelineRecorder struct {
+	pipeline mongo.Pipeline
+}
+
+func (p *pipelineRecorder) Aggregate(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	p.pipeline = pipeline
+	return mongo.NewCursorFromDocuments(nil, nil, nil)
+}
+
+func TestRunRollupMergeStage(t *testing.T) {
+	ctx := context.Background()
+	src := &pipelineRecorder{}
+	group := bson.D{{Key: "$group", Value: bson.M{"_id": "$companyID"}}}
+
+	assert.NoError(t, mongodb.RunRollup(ctx, src, mongo.Pipeline{group}, "daily", []string{"companyID", "day"}, "replace", "insert"))
+	assert.Equal(t, mongo.Pipeline{group, {{Key: "$merge", Value: bson.D{
+		{Key: "into", Value: "daily"},
+		{Key: "on", Value: []string{"companyID", "day"}},
+		{Key: "whenMatched", Value: "replace"},
+		{Key: "whenNotMatched", Value: "insert"},
+	}}}}, src.pipeline)
+
+	assert.NoError(t, mongodb.RunRollup(ctx, src, nil, "daily", nil, "", ""))
+	assert.Equal(t, mongo.Pipeline{{{Key: "$merge", Value: bson.D{{Key: "into", Value: "daily"}}}}}, src.pipeline)
+
+	assert.ErrorContains(t, mongodb.RunRollup(ctx, src, nil, "daily", nil, "overwrite", ""), `invalid whenMatched "overwrite"`)
+	assert.ErrorContains(t, mongodb.RunRollup(ctx, src, nil, "daily", nil, "", "upsert"), `invalid whenNotMatched "upsert"`)
+	assert.ErrorContains(t, mongodb.RunRollup(ctx, src, nil, "", nil, "", ""), "no target collection")
+}
+
+func TestIncrementalRollup(t *testing.T) {
+	ctx := context.Background()
+	src := mongodb.NewRepository[*User](testCollection(t, "user_rollup"))
+	target := testCollection(t, "user_rollup_target")
+	bookmarks := mongodb.NewRepository[*mongodb.RollupBookmark](testCollection(t, "user_rollup_bookmarks"))
+
+	users := make([]*User, 10)
+	for i := range users {
+		users[i] = &User{Name: fmt.Sprintf("User %d", i), Email: fmt.Sprintf("user%d@example.com", i)}
+	}
+	if _, err := src.InsertMany(ctx, users); err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$project", Value: bson.M{"label": bson.M{"$concat": bson.A{"$name", " <", "$email", ">"}}}}}}
+	run := func() {
+		t.Helper()
+		if err := mongodb.IncrementalRollup(ctx, src, bookmarks, "labels", pipeline, "user_rollup_target", nil, "replace", "insert"); err != nil {
+			t.Fatalf("Error on rollup: %v", err)
+		}
+	}
+	labels := func() map[string]string {
+		t.Helper()
+		cur, err := target.Find(ctx, bson.M{})
+		if err != nil {
+			t.Fatalf("Error on reading target: %v", err)
+		}
+		var docs []struct {
+			ID    interface{} `bson:"_id"`
+			Label string      `bson:"label"`
+		}
+		if err := cur.All(ctx, &docs); err != nil {
+			t.Fatalf("Error on reading target: %v", err)
+		}
+		res := map[string]string{}
+		for _, doc := range docs {
+			res[fmt.Sprint(doc.ID)] = doc.Label
+		}
+		return res
+	}
+
+	run()
+	first := labels()
+	assert.Len(t, first, 10)
+	assert.Equal(t, "User 3 <user3@example.com>", first[fmt.Sprint(users[3].MongoID)])
+
+	bookmark, err := bookmarks.FindOne(ctx, bson.M{"name": "labels"})
+	assert.NoError(t, err)
+	assert.False(t, bookmark.Since.IsZero())
+
+	// Running again is idempotent.
+	run()
+	assert.Equal(t, first, labels())
+
+	// Only documents changed since the last run are processed again.
+	time.Sleep(5 * time.Millisecond)
+	_, err = target.UpdateOne(ctx, bson.M{"_id": users[0].MongoID}, bson.M{"$set": bson.M{"label": "untouched"}})
+	assert.NoError(t, err)
+	_, err = src.UpdateOne(ctx, bson.M{"_id": users[1].MongoID}, bson.M{"name": "Renamed"})
+	assert.NoError(t, err)
+	added, err := src.InsertOne(ctx, &User{Name: "New", Email: "new@example.com"})
+	assert.NoError(t, err)
+
+	run()
+	second := labels()
+	assert.Len(t, second, 11)
+	assert.Equal(t, "untouched", second[fmt.Sprint(users[0].MongoID)])
+	assert.Equal(t, "Renamed <user1@example.com>", second[fmt.Sprint(users[1].MongoID)])
+	assert.Equal(t, "New <new@example.com>", second[fmt.Sprint(added.MongoID)])
+
+	// A failed run does not advance the bookmark.
+	before, err := bookmarks.FindOne(ctx, bson.M{"name": "labels"})
+	assert.NoError(t, err)
+	err = mongodb.IncrementalRollup(ctx, src, bookmarks, "labels", mongo.Pipeline{{{Key: "$invalidStage", Value: 1}}}, "user_rollup_target", nil, "", "")
+	assert.Error(t, err)
+	after, err := bookmarks.FindOne(ctx, bson.M{"name": "labels"})
+	assert.NoError(t, err)
+	assert.True(t, before.Since.Equal(after.Since))
+}
@@ -0,0 +1,311 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrUnsafeFilter is returned by [SanitizeFilter] for filters with fields that are not allowed, or that exceed the limits of the policy.
+var ErrUnsafeFilter = errors.New("mongodb: unsafe filter")
+
+// SanitizeOperators are the operators that [SanitizePolicy] allows by default.
+var SanitizeOperators = []string{"$and", "$or", "$eq", "$in", "$gte", "$lte", "$regex", "$options"}
+
+// logicalOperators combine whole filters instead of conditions on a field.
+var logicalOperators = map[string]bool{
+	"$and": true,
+	"$or":  true,
+	"$nor": true,
+}
+
+const (
+	defaultSanitizeRegexLength = 100
+	defaultSanitizeArrayLength = 100
+	defaultSanitizeDepth       = 5
+)
+
+// SanitizePolicy restricts the filters accepted by [SanitizeFilter]. The zero values of the limits use the defaults.
+type SanitizePolicy struct {
+	// Fields are the dotted field paths a filter may contain conditions on.
+	Fields []string
+	// Operators are the allowed operators, [SanitizeOperators] if empty. $where, $function and $accumulator are never allowed.
+	Operators []string
+	// MaxRegexLength is the maximum length of a $regex pattern, 100 by default.
+	MaxRegexLength int
+	// MaxArrayLength is the maximum number of elements of $in, $and and $or, 100 by default.
+	MaxArrayLength int
+	// MaxDepth is the maximum nesting depth of documents in the filter, 5 by default.
+	MaxDepth int
+	// StripFields removes the conditions on fields that are not allowed instead of rejecting the filter.
+	// Conditions inside of $or and $nor are never stripped, since that would widen the filter.
+	StripFields bool
+}
+
+type sanitizer struct {
+	fields      map[string]bool
+	operators   map[string]bool
+	regexLength int
+	arrayLength int
+	depth       int
+	strip       bool
+}
+
+// SanitizeFilter checks a filter built from untrusted input, e.g. a query of an API client, against policy,
+// and returns a copy that only contains the allowed fields and operators.
+//
+//	filter, err := mongodb.SanitizeFilter(userFilter, mongodb.SanitizePolicy{Fields: []string{"name", "status"}})
+//
+// Field names must not contain "$", the values of conditions must not be documents, and arrays must not contain documents,
+// apart from the filters of $and, $or and $nor, which are sanitized themselves. Documents and arrays of any Go type are checked,
+// e.g. map[string]string, []string or bson.Raw, values that can not be checked, like structs, are rejected.
+// Operators that are not allowed result in [ErrForbiddenOperator], fields that are not allowed and exceeded limits in [ErrUnsafeFilter].
+func SanitizeFilter(f primitive.M, policy SanitizePolicy) (primitive.M, error) {
+	res, err := newSanitizer(policy).sanitize(f, true)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.SanitizeFilter", err)
+	}
+	return res, nil
+}
+
+func newSanitizer(policy SanitizePolicy) *sanitizer {
+	s := &sanitizer{
+		fields:      make(map[string]bool, len(policy.Fields)),
+		operators:   map[string]bool{},
+		regexLength: policy.MaxRegexLength,
+		arrayLength: policy.MaxArrayLength,
+		depth:       policy.MaxDepth,
+		strip:       policy.StripFields,
+	}
+	for _, field := range policy.Fields {
+		s.fields[field] = true
+	}
+	operators := policy.Operators
+	if len(operators) == 0 {
+		operators = SanitizeOperators
+	}
+	for _, op := range operators {
+		if !forbiddenOperators[op] {
+			s.operators[op] = true
+		}
+	}
+	if s.regexLength <= 0 {
+		s.regexLength = defaultSanitizeRegexLength
+	}
+	if s.arrayLength <= 0 {
+		s.arrayLength = defaultSanitizeArrayLength
+	}
+	if s.depth <= 0 {
+		s.depth = defaultSanitizeDepth
+	}
+	return s
+}
+
+// sanitize normalizes a filter with [normalizeValue] and sanitizes it.
+func (s *sanitizer) sanitize(f interface{}, strip bool) (primitive.M, error) {
+	normalized, err := normalizeValue(f)
+	if err != nil {
+		return nil, err
+	}
+	doc, ok := normalized.(primitive.M)
+	if !ok {
+		return nil, fmt.Errorf("%w: the filter must be a document", ErrUnsafeFilter)
+	}
+	return s.filter(doc, 1, strip)
+}
+
+// filter sanitizes a normalized filter document at the given depth. strip is false inside of $or and $nor.
+func (s *sanitizer) filter(f primitive.M, depth int, strip bool) (primitive.M, error) {
+	if depth > s.depth {
+		return nil, fmt.Errorf("%w: nested deeper than %d", ErrUnsafeFilter, s.depth)
+	}
+
+	keys := make([]string, 0, len(f))
+	for key := range f {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	res := make(primitive.M, len(f))
+	for _, key := range keys {
+		value := f[key]
+		if strings.HasPrefix(key, "$") {
+			if !logicalOperators[key] || !s.operators[key] {
+				return nil, fmt.Errorf("%w: %v", ErrForbiddenOperator, key)
+			}
+			parts, err := s.logical(key, value, depth, strip && key == "$and")
+			if err != nil {
+				return nil, err
+			}
+			if len(parts) > 0 {
+				res[key] = parts
+			}
+			continue
+		}
+
+		if strings.ContainsAny(key, "$\x00") {
+			return nil, fmt.Errorf("%w: field %q", ErrUnsafeFilter, key)
+		}
+		if !s.fields[key] {
+			if strip && s.strip {
+				continue
+			}
+			return nil, fmt.Errorf("%w: field %v is not allowed", ErrUnsafeFilter, key)
+		}
+
+		condition, err := s.condition(key, value, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		res[key] = condition
+	}
+
+	return res, nil
+}
+
+// logical sanitizes the filters of $and, $or or $nor. Filters that are empty after stripping are removed.
+func (s *sanitizer) logical(op string, value interface{}, depth int, strip bool) (bson.A, error) {
+	parts := asA(value)
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("%w: %v must be a non-empty array", ErrUnsafeFilter, op)
+	}
+	if len(parts) > s.arrayLength {
+		return nil, fmt.Errorf("%w: %v has more than %d elements", ErrUnsafeFilter, op, s.arrayLength)
+	}
+
+	res := make(bson.A, 0, len(parts))
+	for _, part := range parts {
+		doc, ok := asM(part)
+		if !ok {
+			return nil, fmt.Errorf("%w: %v must contain documents", ErrUnsafeFilter, op)
+		}
+		sanitized, err := s.filter(doc, depth+1, strip)
+		if err != nil {
+			return nil, err
+		}
+		if len(sanitized) == 0 && len(doc) > 0 {
+			// Everything was stripped.
+			continue
+		}
+		res = append(res, sanitized)
+	}
+	return res, nil
+}
+
+// condition sanitizes the condition on a field, either a value or a document of operators.
+func (s *sanitizer) condition(field string, value interface{}, depth int) (interface{}, error) {
+	doc, ok := asM(value)
+	if !ok {
+		return s.value(field, value)
+	}
+	if depth > s.depth {
+		return nil, fmt.Errorf("%w: nested deeper than %d", ErrUnsafeFilter, s.depth)
+	}
+
+	res := make(primitive.M, len(doc))
+	for op, operand := range doc {
+		if !strings.HasPrefix(op, "$") {
+			return nil, fmt.Errorf("%w: %v must not be compared to a document", ErrUnsafeFilter, field)
+		}
+		if logicalOperators[op] || !s.operators[op] {
+			return nil, fmt.Errorf("%w: %v", ErrForbiddenOperator, op)
+		}
+
+		switch op {
+		case "$in", "$nin", "$all":
+			elems := asA(operand)
+			if elems == nil {
+				return nil, fmt.Errorf("%w: %v of %v must be an array", ErrUnsafeFilter, op, field)
+			}
+			if len(elems) > s.arrayLength {
+				return nil, fmt.Errorf("%w: %v of %v has more than %d elements", ErrUnsafeFilter, op, field, s.arrayLength)
+			}
+			for _, elem := range elems {
+				if _, err := s.value(field, elem); err != nil {
+					return nil, err
+				}
+			}
+			res[op] = elems
+		case "$regex":
+			if err := s.regex(field, operand); err != nil {
+				return nil, err
+			}
+			res[op] = operand
+		default:
+			checked, err := s.value(field, operand)
+			if err != nil {
+				return nil, err
+			}
+			res[op] = checked
+		}
+	}
+	return res, nil
+}
+
+// value checks that a compared value contains no documents or arrays, which could hide operators.
+func (s *sanitizer) value(field string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case primitive.Regex:
+		return v, s.regex(field, v)
+	case primitive.M, primitive.A:
+		return nil, fmt.Errorf("%w: %v must be compared to a scalar value", ErrUnsafeFilter, field)
+	}
+	return value, nil
+}
+
+func (s *sanitizer) regex(field string, value interface{}) error {
+	var pattern string
+	switch v := value.(type) {
+	case string:
+		pattern = v
+	case primitive.Regex:
+		pattern = v.Pattern
+	default:
+		return fmt.Errorf("%w: $regex of %v must be a string", ErrUnsafeFilter, field)
+	}
+	if len(pattern) > s.regexLength {
+		return fmt.Errorf("%w: $regex of %v is longer than %d characters", ErrUnsafeFilter, field, s.regexLength)
+	}
+	return nil
+}
+
+type sanitizeHook struct {
+	sanitizer *sanitizer
+}
+
+func (h sanitizeHook) Before(ctx context.Context, op *Operation) (context.Context, error) {
+	if op.Filter == nil {
+		return ctx, nil
+	}
+	normalized, err := normalizeValue(op.Filter)
+	if err == nil {
+		filter, ok := normalized.(primitive.M)
+		if !ok {
+			// A pipeline.
+			return ctx, nil
+		}
+		_, err = h.sanitizer.filter(filter, 1, false)
+	}
+	if err != nil {
+		return ctx, fmt.Errorf("%v: %v: %w", "mongodb.Repository."+op.Name, op.Collection, err)
+	}
+	return ctx, nil
+}
+
+func (h sanitizeHook) After(_ context.Context, _ *Operation, err error) error {
+	return err
+}
+
+// WithSanitizedFilters rejects every operation whose filter does not pass [SanitizeFilter] with policy, e.g. for a repository
+// that only serves queries of API clients. Filters are only checked, never stripped, see [SanitizePolicy.StripFields].
+//
+// Filters of any document type are checked, pipelines are not. This includes the filters built by methods like
+// [Repository.DeleteManyByIDs], so the policy must allow the fields they use, e.g. _id.
+func WithSanitizedFilters(policy SanitizePolicy) RepositoryOption {
+	return hookOption{hook: sanitizeHook{sanitizer: newSanitizer(policy)}}
+}
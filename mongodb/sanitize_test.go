@@ -0,0 +1,108 @@
+package mongodb_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestSanitizeFilter(t *testing.T) {
+	policy := mongodb.SanitizePolicy{Fields: []string{"name", "status", "age"}, MaxArrayLength: 3, MaxRegexLength: 10, MaxDepth: 3}
+
+	tests := []struct {
+		name   string
+		filter bson.M
+		want   bson.M
+		err    error
+	}{
+		{"plain values", bson.M{"name": "alice", "age": 3}, bson.M{"name": "alice", "age": 3}, nil},
+		{"allowed operators", bson.M{"age": bson.M{"$gte": 1, "$lte": 9}, "status": bson.M{"$in": bson.A{"a", "b"}}}, bson.M{"age": bson.M{"$gte": 1, "$lte": 9}, "status": bson.M{"$in": bson.A{"a", "b"}}}, nil},
+		{"regex", bson.M{"name": bson.M{"$regex": "^al", "$options": "i"}}, bson.M{"name": bson.M{"$regex": "^al", "$options": "i"}}, nil},
+		{"$or", bson.M{"$or": bson.A{bson.M{"name": "a"}, bson.M{"status": "b"}}}, bson.M{"$or": bson.A{bson.M{"name": "a"}, bson.M{"status": "b"}}}, nil},
+		{"typed $in", bson.M{"status": bson.M{"$in": []string{"a", "b"}}}, bson.M{"status": bson.M{"$in": bson.A{"a", "b"}}}, nil},
+		{"typed condition", bson.M{"age": map[string]int{"$gte": 1}}, bson.M{"age": bson.M{"$gte": 1}}, nil},
+		{"bson.D", bson.M{"$or": []bson.D{{{Key: "name", Value: "a"}}}}, bson.M{"$or": bson.A{bson.M{"name": "a"}}}, nil},
+
+		{"$where", bson.M{"$where": "sleep(1000)"}, nil, mongodb.ErrForbiddenOperator},
+		{"$expr", bson.M{"$expr": bson.M{"$gt": bson.A{"$age", 1}}}, nil, mongodb.ErrForbiddenOperator},
+		{"$where inside $or", bson.M{"$or": bson.A{bson.M{"name": "a"}, bson.M{"$where": "sleep(1000)"}}}, nil, mongodb.ErrForbiddenOperator},
+		{"$function in a condition", bson.M{"name": bson.M{"$function": bson.M{"body": "sleep(1000)"}}}, nil, mongodb.ErrForbiddenOperator},
+		{"operator not allowed", bson.M{"age": bson.M{"$ne": 1}}, nil, mongodb.ErrForbiddenOperator},
+		{"$nor not allowed", bson.M{"$nor": bson.A{bson.M{"name": "a"}}}, nil, mongodb.ErrForbiddenOperator},
+		{"unknown field", bson.M{"password": "x"}, nil, mongodb.ErrUnsafeFilter},
+		{"unknown field inside $or", bson.M{"$or": bson.A{bson.M{"name": "a"}, bson.M{"password": "x"}}}, nil, mongodb.ErrUnsafeFilter},
+		{"operator in field name", bson.M{"name.$where": "x"}, nil, mongodb.ErrUnsafeFilter},
+		{"document value", bson.M{"name": bson.M{"first": "a"}}, nil, mongodb.ErrUnsafeFilter},
+		{"document inside $in", bson.M{"name": bson.M{"$in": bson.A{bson.M{"$where": "1"}}}}, nil, mongodb.ErrUnsafeFilter},
+		{"document compared with $eq", bson.M{"name": bson.M{"$eq": bson.M{"$where": "1"}}}, nil, mongodb.ErrUnsafeFilter},
+		{"too many $in elements", bson.M{"name": bson.M{"$in": bson.A{1, 2, 3, 4}}}, nil, mongodb.ErrUnsafeFilter},
+		{"long regex", bson.M{"name": bson.M{"$regex": strings.Repeat("(a+)+", 5)}}, nil, mongodb.ErrUnsafeFilter},
+		{"long regex value", bson.M{"name": primitive.Regex{Pattern: strings.Repeat("a", 11)}}, nil, mongodb.ErrUnsafeFilter},
+		{"too deep", bson.M{"$or": bson.A{bson.M{"$or": bson.A{bson.M{"$or": bson.A{bson.M{"name": "a"}}}}}}}, nil, mongodb.ErrUnsafeFilter},
+		{"empty $or", bson.M{"$or": bson.A{}}, nil, mongodb.ErrUnsafeFilter},
+		{"typed map condition", bson.M{"name": map[string]string{"$ne": ""}}, nil, mongodb.ErrForbiddenOperator},
+		{"raw condition", bson.M{"name": mustMarshal(bson.M{"$gt": ""})}, nil, mongodb.ErrForbiddenOperator},
+		{"raw value condition", bson.M{"name": bson.RawValue{Type: bson.TypeEmbeddedDocument, Value: mustMarshal(bson.M{"$ne": ""})}}, nil, mongodb.ErrForbiddenOperator},
+		{"bson.D condition", bson.M{"name": bson.D{{Key: "$ne", Value: ""}}}, nil, mongodb.ErrForbiddenOperator},
+		{"pointer condition", bson.M{"name": &bson.M{"$ne": ""}}, nil, mongodb.ErrForbiddenOperator},
+		{"typed map in $or", bson.M{"$or": []map[string]interface{}{{"$where": "1"}}}, nil, mongodb.ErrForbiddenOperator},
+		{"typed slice value", bson.M{"name": []string{"a"}}, nil, mongodb.ErrUnsafeFilter},
+		{"typed map inside $in", bson.M{"name": bson.M{"$in": []map[string]string{{"$where": "1"}}}}, nil, mongodb.ErrUnsafeFilter},
+		{"struct value", bson.M{"name": struct{ First string }{"a"}}, nil, mongodb.ErrUnsafeFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mongodb.SanitizeFilter(tt.filter, policy)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, primitive.M(tt.want), got)
+		})
+	}
+}
+
+func TestSanitizeFilterStrip(t *testing.T) {
+	policy := mongodb.SanitizePolicy{Fields: []string{"name"}, StripFields: true}
+
+	got, err := mongodb.SanitizeFilter(bson.M{"name": "a", "password": "x", "$and": bson.A{bson.M{"role": "admin"}}}, policy)
+	assert.NoError(t, err)
+	assert.Equal(t, primitive.M{"name": "a"}, got)
+
+	// Stripping inside of $or would widen the filter.
+	_, err = mongodb.SanitizeFilter(bson.M{"$or": bson.A{bson.M{"name": "a"}, bson.M{"password": "x"}}}, policy)
+	assert.ErrorIs(t, err, mongodb.ErrUnsafeFilter)
+
+	// Operators are never stripped.
+	_, err = mongodb.SanitizeFilter(bson.M{"$where": "sleep(1000)"}, policy)
+	assert.ErrorIs(t, err, mongodb.ErrForbiddenOperator)
+}
+
+func TestWithSanitizedFilters(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	// The filter is rejected before the operation reaches the server.
+	repo := mongodb.NewRepository[*User](client.Database("testdb").Collection("user_sanitized"),
+		mongodb.WithSanitizedFilters(mongodb.SanitizePolicy{Fields: []string{"name"}})).(*mongodb.Repository[*User])
+	_, err = repo.FindMany(ctx, bson.M{"$where": "sleep(1000)"})
+	assert.ErrorIs(t, err, mongodb.ErrForbiddenOperator)
+	_, err = repo.CountDocuments(ctx, bson.M{"email": "a@example.com"})
+	assert.ErrorIs(t, err, mongodb.ErrUnsafeFilter)
+	_, err = repo.FindOneWhere(ctx, mongodb.FromD(bson.D{{Key: "name", Value: map[string]string{"$ne": ""}}}))
+	assert.ErrorIs(t, err, mongodb.ErrForbiddenOperator, "ordered filters are checked")
+}
@@ -0,0 +1,89 @@
+package mongodb
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FilterValidator checks a filter re-hydrated by [UnmarshalFilter], e.g. [OperatorAllowlist].
+type FilterValidator func(filter primitive.M) error
+
+// QueryOperators are the query operators that [OperatorAllowlist] accepts by default.
+var QueryOperators = []string{
+	"$eq", "$ne", "$gt", "$gte", "$lt", "$lte", "$in", "$nin",
+	"$and", "$or", "$nor", "$not",
+	"$exists", "$type", "$regex", "$options", "$elemMatch", "$size", "$all",
+}
+
+// MarshalFilter serializes a filter to canonical Extended JSON, so that it can be stored, e.g. as a saved search,
+// and re-hydrated by [UnmarshalFilter] with the same BSON types, like ObjectIDs, dates and decimals.
+//
+// Go ints are stored as int32 or int64, depending on their value, and are returned as such.
+func MarshalFilter(f primitive.M) (string, error) {
+	if f == nil {
+		f = primitive.M{}
+	}
+
+	data, err := bson.MarshalExtJSON(f, true, false)
+	if err != nil {
+		return "", fmt.Errorf("%v: %w", "mongodb.MarshalFilter", err)
+	}
+
+	return string(data), nil
+}
+
+// UnmarshalFilter re-hydrates a filter serialized by [MarshalFilter], which can be passed to FindMany unchanged.
+//
+// Since stored filters may come from untrusted input, filters containing $where, $function or $accumulator
+// are always rejected with [ErrForbiddenOperator]. The validators run after that, e.g. an [OperatorAllowlist].
+func UnmarshalFilter(s string, validators ...FilterValidator) (primitive.M, error) {
+	var f primitive.M
+	if err := bson.UnmarshalExtJSON([]byte(s), true, &f); err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.UnmarshalFilter", err)
+	}
+	if f == nil {
+		f = primitive.M{}
+	}
+
+	op, err := findOperator(f, func(op string) bool { return forbiddenOperators[op] })
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.UnmarshalFilter", err)
+	}
+	if op != "" {
+		return nil, fmt.Errorf("%v: %w: %v", "mongodb.UnmarshalFilter", ErrForbiddenOperator, op)
+	}
+
+	for _, validate := range validators {
+		if err := validate(f); err != nil {
+			return nil, fmt.Errorf("%v: %w", "mongodb.UnmarshalFilter", err)
+		}
+	}
+
+	return f, nil
+}
+
+// OperatorAllowlist rejects filters with [ErrForbiddenOperator] if they contain operators other than the given ones,
+// or other than [QueryOperators] if none are given.
+func OperatorAllowlist(operators ...string) FilterValidator {
+	if len(operators) == 0 {
+		operators = QueryOperators
+	}
+	allowed := make(map[string]bool, len(operators))
+	for _, op := range operators {
+		allowed[op] = true
+	}
+
+	return func(filter primitive.M) error {
+		op, err := findOperator(filter, func(key string) bool { return strings.HasPrefix(key, "$") && !allowed[key] })
+		if err != nil {
+			return err
+		}
+		if op != "" {
+			return fmt.Errorf("%w: %v", ErrForbiddenOperator, op)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,95 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func savedFilter(t *testing.T) primitive.M {
+	t.Helper()
+
+	price, err := primitive.ParseDecimal128("19.99")
+	if err != nil {
+		t.Fatalf("Error parsing decimal: %v", err)
+	}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	return bson.M{
+		"companyID": primitive.NewObjectID(),
+		"createdAt": bson.M{"$gte": primitive.NewDateTimeFromTime(from), "$lt": primitive.NewDateTimeFromTime(from.AddDate(0, 1, 0))},
+		"status":    bson.M{"$in": bson.A{"open", "pending"}},
+		"price":     price,
+		"$or": bson.A{
+			bson.M{"name": bson.M{"$regex": "^a", "$options": "i"}},
+			bson.M{"count": bson.M{"$gt": int32(3)}, "total": int64(1) << 40},
+		},
+	}
+}
+
+func TestMarshalFilterRoundTrip(t *testing.T) {
+	filter := savedFilter(t)
+
+	s, err := mongodb.MarshalFilter(filter)
+	if err != nil {
+		t.Fatalf("Error on marshalling: %v", err)
+	}
+	assert.Contains(t, s, `"$oid"`)
+	assert.Contains(t, s, `"$date"`)
+	assert.Contains(t, s, `"$numberDecimal"`)
+
+	restored, err := mongodb.UnmarshalFilter(s, mongodb.OperatorAllowlist())
+	assert.NoError(t, err)
+	assert.Equal(t, filter, restored)
+}
+
+func TestUnmarshalFilterRejectsOperators(t *testing.T) {
+	_, err := mongodb.UnmarshalFilter(`{"$where": "sleep(1000)"}`)
+	assert.ErrorIs(t, err, mongodb.ErrForbiddenOperator)
+
+	_, err = mongodb.UnmarshalFilter(`{"$or": [{"a": 1}, {"$expr": {"$function": {"body": "", "args": [], "lang": "js"}}}]}`)
+	assert.ErrorIs(t, err, mongodb.ErrForbiddenOperator)
+
+	_, err = mongodb.UnmarshalFilter(`{"$expr": {"$eq": ["$a", "$b"]}}`, mongodb.OperatorAllowlist())
+	assert.ErrorIs(t, err, mongodb.ErrForbiddenOperator)
+
+	f, err := mongodb.UnmarshalFilter(`{"$expr": {"$eq": ["$a", "$b"]}}`, mongodb.OperatorAllowlist("$expr", "$eq"))
+	assert.NoError(t, err)
+	assert.Equal(t, bson.M{"$expr": bson.M{"$eq": bson.A{"$a", "$b"}}}, f)
+
+	_, err = mongodb.UnmarshalFilter(`{"a": `)
+	assert.Error(t, err)
+}
+
+func TestUnmarshalFilterInFindMany(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_saved_filter"))
+
+	alice, err := repo.InsertOne(ctx, &User{Name: "alice"})
+	if err != nil {
+		t.Fatalf("Error on inserting: %v", err)
+	}
+	if _, err := repo.InsertOne(ctx, &User{Name: "bob"}); err != nil {
+		t.Fatalf("Error on inserting: %v", err)
+	}
+
+	s, err := mongodb.MarshalFilter(bson.M{"$or": bson.A{bson.M{"_id": alice.MongoID}, bson.M{"createdAt": bson.M{"$lt": time.Unix(0, 0)}}}})
+	if err != nil {
+		t.Fatalf("Error on marshalling: %v", err)
+	}
+	filter, err := mongodb.UnmarshalFilter(s, mongodb.OperatorAllowlist())
+	if err != nil {
+		t.Fatalf("Error on unmarshalling: %v", err)
+	}
+
+	users, err := repo.FindMany(ctx, filter)
+	assert.NoError(t, err)
+	if assert.Len(t, users, 1) {
+		assert.Equal(t, "alice", users[0].Name)
+	}
+}
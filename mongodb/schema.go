@@ -0,0 +1,258 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const defaultSchemaSampleSize = 1000
+
+type (
+	// SchemaReport describes the fields observed in a sample of the documents of a collection, see [AnalyzeSchema].
+	SchemaReport struct {
+		// SampleSize is the number of sampled documents.
+		SampleSize int `json:"sampleSize"`
+		// Fields are the observed fields, sorted by path.
+		Fields []SchemaField `json:"fields"`
+	}
+
+	// SchemaField holds the statistics of a single field of a [SchemaReport].
+	SchemaField struct {
+		// Path is the dotted bson path of the field. Fields of documents in arrays are addressed without index, e.g. "items.name".
+		Path string `json:"path"`
+		// Count is the number of sampled documents that have the field.
+		Count int `json:"count"`
+		// Types counts the values of the field by the alias of their BSON type, as used by $type, e.g. "string" or "objectId".
+		// Fields of documents in arrays can occur multiple times per document, so the counts can add up to more than Count.
+		Types map[string]int `json:"types"`
+		// StringLength is the range of the lengths of the string values in characters, nil if there are none.
+		StringLength *LengthRange `json:"stringLength,omitempty"`
+		// ArrayLength is the range of the lengths of the array values, nil if there are none.
+		ArrayLength *LengthRange `json:"arrayLength,omitempty"`
+	}
+
+	// LengthRange is the range of the observed lengths of a field, see [SchemaField].
+	LengthRange struct {
+		Min int `json:"min"`
+		Max int `json:"max"`
+	}
+
+	// SchemaDiff compares a [SchemaReport] with a document struct, see [DiffSchema].
+	SchemaDiff struct {
+		// Unobserved are the paths of the struct that no sampled document has.
+		Unobserved []string `json:"unobserved"`
+		// Unknown are the observed paths that the struct does not declare.
+		Unknown []string `json:"unknown"`
+	}
+)
+
+// bsonTypeAliases are the aliases of the BSON types as used by the $type query operator.
+var bsonTypeAliases = map[bsontype.Type]string{
+	bsontype.Double:           "double",
+	bsontype.String:           "string",
+	bsontype.EmbeddedDocument: "object",
+	bsontype.Array:            "array",
+	bsontype.Binary:           "binData",
+	bsontype.Undefined:        "undefined",
+	bsontype.ObjectID:         "objectId",
+	bsontype.Boolean:          "bool",
+	bsontype.DateTime:         "date",
+	bsontype.Null:             "null",
+	bsontype.Regex:            "regex",
+	bsontype.DBPointer:        "dbPointer",
+	bsontype.JavaScript:       "javascript",
+	bsontype.Symbol:           "symbol",
+	bsontype.CodeWithScope:    "javascriptWithScope",
+	bsontype.Int32:            "int",
+	bsontype.Timestamp:        "timestamp",
+	bsontype.Int64:            "long",
+	bsontype.Decimal128:       "decimal",
+	bsontype.MinKey:           "minKey",
+	bsontype.MaxKey:           "maxKey",
+}
+
+// AnalyzeSchema samples up to sampleSize random documents with $sample, and reports which fields they have
+// with which BSON types. A sampleSize <= 0 samples 1000 documents.
+//
+// Embedded documents are analyzed field by field, also within arrays. The report can be marshaled to JSON to share it,
+// and compared with the document struct with [DiffSchema], e.g. before refactoring a model.
+func AnalyzeSchema(ctx context.Context, r Aggregater, sampleSize int) (SchemaReport, error) {
+	if sampleSize <= 0 {
+		sampleSize = defaultSchemaSampleSize
+	}
+
+	cur, err := r.Aggregate(ctx, mongo.Pipeline{{{Key: "$sample", Value: bson.M{"size": sampleSize}}}})
+	if err != nil {
+		return SchemaReport{}, fmt.Errorf("%v: %w", "mongodb.AnalyzeSchema", err)
+	}
+	defer cur.Close(context.Background())
+
+	var report SchemaReport
+	fields := map[string]*SchemaField{}
+	for cur.Next(ctx) {
+		report.SampleSize++
+		analyzeDocument(cur.Current, "", fields, map[string]bool{})
+	}
+	if err := cur.Err(); err != nil {
+		return SchemaReport{}, fmt.Errorf("%v: %w", "mongodb.AnalyzeSchema", err)
+	}
+
+	report.Fields = make([]SchemaField, 0, len(fields))
+	for _, stats := range fields {
+		report.Fields = append(report.Fields, *stats)
+	}
+	sort.Slice(report.Fields, func(i, j int) bool { return report.Fields[i].Path < report.Fields[j].Path })
+
+	return report, nil
+}
+
+// analyzeDocument adds the fields of doc to fields. seen are the paths already counted for the sampled document.
+func analyzeDocument(doc bson.Raw, prefix string, fields map[string]*SchemaField, seen map[string]bool) {
+	elements, err := doc.Elements()
+	if err != nil {
+		return
+	}
+
+	for _, e := range elements {
+		analyzeValue(prefix+e.Key(), e.Value(), fields, seen)
+	}
+}
+
+func analyzeValue(path string, value bson.RawValue, fields map[string]*SchemaField, seen map[string]bool) {
+	stats, ok := fields[path]
+	if !ok {
+		stats = &SchemaField{Path: path, Types: map[string]int{}}
+		fields[path] = stats
+	}
+	if !seen[path] {
+		seen[path] = true
+		stats.Count++
+	}
+
+	alias, ok := bsonTypeAliases[value.Type]
+	if !ok {
+		alias = value.Type.String()
+	}
+	stats.Types[alias]++
+
+	switch value.Type {
+	case bsontype.String:
+		stats.StringLength = stats.StringLength.add(utf8.RuneCountInString(value.StringValue()))
+	case bsontype.EmbeddedDocument:
+		analyzeDocument(value.Document(), path+".", fields, seen)
+	case bsontype.Array:
+		values, err := value.Array().Values()
+		if err != nil {
+			return
+		}
+		stats.ArrayLength = stats.ArrayLength.add(len(values))
+		for _, element := range values {
+			if doc, ok := element.DocumentOK(); ok {
+				analyzeDocument(doc, path+".", fields, seen)
+			}
+		}
+	}
+}
+
+// add returns the range extended by n, or a range of only n if r is nil.
+func (r *LengthRange) add(n int) *LengthRange {
+	if r == nil {
+		return &LengthRange{Min: n, Max: n}
+	}
+	if n < r.Min {
+		r.Min = n
+	}
+	if n > r.Max {
+		r.Max = n
+	}
+	return r
+}
+
+// DiffSchema compares the observed fields of report with the fields of the document struct T, the way the bson encoder stores them.
+//
+// Fields of structs in slices are addressed without index, like in the report. Observed fields below a map or
+// interface field of T are never unknown, since T accepts any field there.
+func DiffSchema[T any](report SchemaReport) (SchemaDiff, error) {
+	declared := map[string]reflect.Type{}
+	if err := collectSchemaPaths(documentType[T](), "", map[reflect.Type]bool{}, declared); err != nil {
+		return SchemaDiff{}, fmt.Errorf("%v: %w", "mongodb.DiffSchema", err)
+	}
+
+	diff := SchemaDiff{Unobserved: []string{}, Unknown: []string{}}
+	observed := map[string]bool{}
+	for _, field := range report.Fields {
+		observed[field.Path] = true
+		if !declaresPath(declared, field.Path) {
+			diff.Unknown = append(diff.Unknown, field.Path)
+		}
+	}
+	for path := range declared {
+		if !observed[path] {
+			diff.Unobserved = append(diff.Unobserved, path)
+		}
+	}
+	sort.Strings(diff.Unobserved)
+	sort.Strings(diff.Unknown)
+
+	return diff, nil
+}
+
+// collectSchemaPaths adds the paths of the fields of the struct type t to paths, including the fields of structs in slices.
+func collectSchemaPaths(t reflect.Type, prefix string, visiting map[reflect.Type]bool, paths map[string]reflect.Type) error {
+	if visiting[t] {
+		return nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	fields, err := structFields(t)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		path := prefix + field.Path
+		paths[path] = field.Field.Type
+
+		fieldType := indirectType(field.Field.Type)
+		if kind := fieldType.Kind(); kind != reflect.Slice && kind != reflect.Array {
+			continue
+		}
+		if elem := elementType(fieldType); IsSubdocumentType(elem) {
+			if err := collectSchemaPaths(elem, path+".", visiting, paths); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// declaresPath reports whether path is one of the declared paths, or below a declared map or interface field.
+func declaresPath(declared map[string]reflect.Type, path string) bool {
+	if _, ok := declared[path]; ok {
+		return true
+	}
+
+	segments := strings.Split(path, ".")
+	for i := len(segments) - 1; i > 0; i-- {
+		t, ok := declared[strings.Join(segments[:i], ".")]
+		if !ok {
+			continue
+		}
+		switch elementType(t).Kind() {
+		case reflect.Map, reflect.Interface:
+			return true
+		}
+	}
+
+	return false
+}
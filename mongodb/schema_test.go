@@ -0,0 +1,101 @@
+package mongodb_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type (
+	SchemaItem struct {
+		SKU      string `bson:"sku"`
+		Quantity int    `bson:"quantity"`
+	}
+
+	SchemaOrder struct {
+		mongodb.BaseModel `bson:",inline"`
+		Number            string            `bson:"number"`
+		Items             []SchemaItem      `bson:"items"`
+		Labels            map[string]string `bson:"labels"`
+		Note              string            `bson:"note,omitempty"`
+	}
+)
+
+func TestDiffSchema(t *testing.T) {
+	report := mongodb.SchemaReport{SampleSize: 2, Fields: []mongodb.SchemaField{
+		{Path: "_id", Count: 2, Types: map[string]int{"objectId": 2}},
+		{Path: "number", Count: 2, Types: map[string]int{"string": 1, "int": 1}},
+		{Path: "items", Count: 1, Types: map[string]int{"array": 1}, ArrayLength: &mongodb.LengthRange{Min: 2, Max: 2}},
+		{Path: "items.sku", Count: 1, Types: map[string]int{"string": 2}},
+		{Path: "items.price", Count: 1, Types: map[string]int{"double": 2}},
+		{Path: "labels.color", Count: 1, Types: map[string]int{"string": 1}},
+		{Path: "legacy", Count: 1, Types: map[string]int{"bool": 1}},
+	}}
+
+	diff, err := mongodb.DiffSchema[*SchemaOrder](report)
+	assert.NoError(t, err)
+	assert.Equal(t, mongodb.SchemaDiff{
+		Unobserved: []string{"createdAt", "items.quantity", "labels", "note", "updatedAt"},
+		Unknown:    []string{"items.price", "legacy"},
+	}, diff)
+
+	data, err := json.Marshal(report)
+	assert.NoError(t, err)
+	var decoded mongodb.SchemaReport
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, report, decoded)
+}
+
+func TestAnalyzeSchema(t *testing.T) {
+	ctx := context.Background()
+	col := testCollection(t, "order_schema")
+	_, err := col.InsertMany(ctx, []interface{}{
+		bson.M{"number": "A-1", "items": bson.A{bson.M{"sku": "apple", "quantity": 1}, bson.M{"sku": "kiwi", "quantity": int64(2)}}},
+		bson.M{"number": "A-100", "items": bson.A{}, "labels": bson.M{"color": "red"}},
+		bson.M{"number": 3, "items": bson.A{bson.M{"sku": "banana"}}, "legacy": true},
+		bson.M{"number": nil},
+	})
+	if err != nil {
+		t.Fatalf("Error on inserting orders: %v", err)
+	}
+
+	report, err := mongodb.AnalyzeSchema(ctx, mongodb.NewRepository[*SchemaOrder](col), 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, report.SampleSize)
+
+	fields := map[string]mongodb.SchemaField{}
+	for _, field := range report.Fields {
+		fields[field.Path] = field
+	}
+	assert.Equal(t, []string{"_id", "items", "items.quantity", "items.sku", "labels", "labels.color", "legacy", "number"}, keysOf(report))
+
+	assert.Equal(t, mongodb.SchemaField{
+		Path: "number", Count: 4, Types: map[string]int{"string": 2, "int": 1, "null": 1},
+		StringLength: &mongodb.LengthRange{Min: 3, Max: 5},
+	}, fields["number"])
+	assert.Equal(t, 3, fields["items"].Count)
+	assert.Equal(t, &mongodb.LengthRange{Min: 0, Max: 2}, fields["items"].ArrayLength)
+	assert.Equal(t, mongodb.SchemaField{
+		Path: "items.sku", Count: 2, Types: map[string]int{"string": 3},
+		StringLength: &mongodb.LengthRange{Min: 4, Max: 6},
+	}, fields["items.sku"])
+	assert.Equal(t, map[string]int{"int": 1, "long": 1}, fields["items.quantity"].Types)
+	assert.Equal(t, map[string]int{"object": 1}, fields["labels"].Types)
+
+	diff, err := mongodb.DiffSchema[*SchemaOrder](report)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"legacy"}, diff.Unknown)
+	assert.Equal(t, []string{"createdAt", "note", "updatedAt"}, diff.Unobserved)
+}
+
+func keysOf(report mongodb.SchemaReport) []string {
+	paths := make([]string, len(report.Fields))
+	for i, field := range report.Fields {
+		paths[i] = field.Path
+	}
+	return paths
+}
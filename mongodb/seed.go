@@ -0,0 +1,180 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// SeedReport summarizes the result of [SeedByKey].
+	SeedReport struct {
+		// Inserted is the number of documents that did not exist yet.
+		Inserted int
+		// Updated is the number of existing documents that were replaced.
+		Updated int
+		// Skipped is the number of existing documents that were left untouched.
+		Skipped int
+	}
+)
+
+// SeedIfEmpty inserts docs only if the collection contains no documents, and returns the number of inserted documents.
+func SeedIfEmpty[T Document[T]](ctx context.Context, r RepositoryI[T], docs []T) (int, error) {
+	count, err := r.CountDocuments(ctx, bson.M{}, options.Count().SetLimit(1))
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", "mongodb.SeedIfEmpty", err)
+	}
+	if count > 0 {
+		return 0, nil
+	}
+
+	inserted, err := r.InsertMany(ctx, docs)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", "mongodb.SeedIfEmpty", err)
+	}
+	return len(inserted), nil
+}
+
+// SeedByKey ensures that a document exists for the value of keyField of every document in docs.
+//
+// Documents whose key does not exist yet are inserted. Existing documents are left untouched, or, if overwrite is set,
+// replaced while keeping their _id and createdAt. keyField can be a dotted path and should have a unique index,
+// inserts are upserts so that concurrent seeders do not create duplicates.
+func SeedByKey[T Document[T]](ctx context.Context, r RepositoryI[T], keyField string, docs []T, overwrite bool) (SeedReport, error) {
+	report := SeedReport{}
+	path := strings.Split(keyField, ".")
+
+	registry := registryOf(r)
+	keys := make([]bson.RawValue, len(docs))
+	seen := make(map[string]bool, len(docs))
+	for i, doc := range docs {
+		raw, err := bson.MarshalWithRegistry(registry, doc)
+		if err != nil {
+			return report, fmt.Errorf("%v: document %d: %w", "mongodb.SeedByKey", i, err)
+		}
+		key, err := bson.Raw(raw).LookupErr(path...)
+		if err != nil {
+			return report, fmt.Errorf("%v: document %d has no %v: %w", "mongodb.SeedByKey", i, keyField, err)
+		}
+		if seen[rawValueKey(key)] {
+			return report, fmt.Errorf("%v: document %d: duplicate %v %v", "mongodb.SeedByKey", i, keyField, key)
+		}
+		seen[rawValueKey(key)] = true
+		keys[i] = key
+	}
+	if len(docs) == 0 {
+		return report, nil
+	}
+
+	existing, err := existingByKey(ctx, r, path, keyField, keys)
+	if err != nil {
+		return report, fmt.Errorf("%v: %w", "mongodb.SeedByKey", err)
+	}
+
+	var inserts, replaces []mongo.WriteModel
+	for i, doc := range docs {
+		current, ok := existing[rawValueKey(keys[i])]
+		if !ok {
+			doc.InitDocument()
+			inserts = append(inserts, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{keyField: keys[i]}).
+				SetUpdate(bson.M{"$setOnInsert": doc}).
+				SetUpsert(true))
+			continue
+		}
+		if !overwrite {
+			report.Skipped++
+			continue
+		}
+
+		id, createdAt := current.Lookup("_id"), current.Lookup("createdAt")
+		doc.SetUpdatedAt(now())
+		replacement, err := withoutID(registry, doc)
+		if err != nil {
+			return report, fmt.Errorf("%v: document %d: %w", "mongodb.SeedByKey", i, err)
+		}
+		if !createdAt.IsZero() {
+			replacement = replaceElement(replacement, "createdAt", createdAt)
+		}
+		replaces = append(replaces, mongo.NewReplaceOneModel().SetFilter(bson.M{"_id": id}).SetReplacement(replacement))
+	}
+
+	res, err := r.BulkWrite(ctx, inserts)
+	if err != nil {
+		return report, fmt.Errorf("%v: %w", "mongodb.SeedByKey", err)
+	}
+	report.Inserted += int(res.UpsertedCount)
+	// A concurrent seeder inserted the document in the meantime.
+	report.Skipped += int(res.MatchedCount)
+
+	res, err = r.BulkWrite(ctx, replaces)
+	if err != nil {
+		return report, fmt.Errorf("%v: %w", "mongodb.SeedByKey", err)
+	}
+	report.Updated += int(res.MatchedCount)
+
+	return report, nil
+}
+
+// existingByKey loads the documents whose keyField is one of keys, indexed by [rawValueKey] of their key.
+func existingByKey[T Document[T]](ctx context.Context, r RepositoryI[T], path []string, keyField string, keys []bson.RawValue) (map[string]bson.Raw, error) {
+	cur, err := r.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{keyField: bson.M{"$in": keys}}}},
+		{{Key: "$project", Value: bson.M{"_id": 1, "createdAt": 1, keyField: 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	res := map[string]bson.Raw{}
+	for cur.Next(ctx) {
+		doc := make(bson.Raw, len(cur.Current))
+		copy(doc, cur.Current)
+		if key, err := doc.LookupErr(path...); err == nil {
+			res[rawValueKey(key)] = doc
+		}
+	}
+	return res, cur.Err()
+}
+
+// rawValueKey returns a map key that is equal for equal BSON values.
+func rawValueKey(v bson.RawValue) string {
+	return string(v.Type) + string(v.Value)
+}
+
+// withoutID marshals doc with registry, without its _id.
+func withoutID(registry *bsoncodec.Registry, doc interface{}) (bson.D, error) {
+	data, err := bson.MarshalWithRegistry(registry, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var d bson.D
+	if err := bson.UnmarshalWithRegistry(registry, data, &d); err != nil {
+		return nil, err
+	}
+
+	res := d[:0]
+	for _, e := range d {
+		if e.Key != "_id" {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+func replaceElement(d bson.D, key string, value interface{}) bson.D {
+	for i := range d {
+		if d[i].Key == key {
+			d[i].Value = value
+			return d
+		}
+	}
+	return append(d, bson.E{Key: key, Value: value})
+}
@@ -0,0 +1,89 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func seedUsers() []*User {
+	return []*User{
+		{Name: "admin", Email: "admin@example.com"},
+		{Name: "viewer", Email: "viewer@example.com"},
+	}
+}
+
+func TestSeedIfEmpty(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_seed_empty"))
+
+	inserted, err := mongodb.SeedIfEmpty[*User](ctx, repo, seedUsers())
+	if err != nil {
+		t.Fatalf("Error on seeding: %v", err)
+	}
+	assert.Equal(t, 2, inserted)
+
+	inserted, err = mongodb.SeedIfEmpty[*User](ctx, repo, seedUsers())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, inserted)
+}
+
+func TestSeedByKey(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_seed_key"))
+
+	report, err := mongodb.SeedByKey[*User](ctx, repo, "name", seedUsers(), false)
+	if err != nil {
+		t.Fatalf("Error on seeding: %v", err)
+	}
+	assert.Equal(t, mongodb.SeedReport{Inserted: 2}, report)
+
+	// An operator edits a seeded document.
+	_, err = repo.UpdateOne(ctx, bson.M{"name": "admin"}, bson.M{"email": "ops@example.com"})
+	if err != nil {
+		t.Fatalf("Error on updating: %v", err)
+	}
+	before, err := repo.FindOne(ctx, bson.M{"name": "admin"})
+	if err != nil {
+		t.Fatalf("Error on finding admin: %v", err)
+	}
+
+	// The second run in skip mode is a no-op.
+	report, err = mongodb.SeedByKey[*User](ctx, repo, "name", seedUsers(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, mongodb.SeedReport{Skipped: 2}, report)
+
+	admin, err := repo.FindOne(ctx, bson.M{"name": "admin"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ops@example.com", admin.Email)
+
+	// Overwrite replaces the document but keeps _id and createdAt.
+	time.Sleep(10 * time.Millisecond)
+	report, err = mongodb.SeedByKey[*User](ctx, repo, "name", seedUsers(), true)
+	assert.NoError(t, err)
+	assert.Equal(t, mongodb.SeedReport{Updated: 2}, report)
+
+	admin, err = repo.FindOne(ctx, bson.M{"name": "admin"})
+	assert.NoError(t, err)
+	assert.Equal(t, "admin@example.com", admin.Email)
+	assert.Equal(t, before.MongoID, admin.MongoID)
+	assert.Equal(t, before.CreatedAt, admin.CreatedAt)
+	assert.True(t, admin.UpdatedAt.After(before.UpdatedAt))
+
+	count, err := repo.CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestSeedByKeyMissingKey(t *testing.T) {
+	// The key is validated before the repository is used.
+	_, err := mongodb.SeedByKey[*User](context.Background(), nil, "company", seedUsers(), false)
+	assert.Error(t, err)
+
+	_, err = mongodb.SeedByKey[*User](context.Background(), nil, "name", append(seedUsers(), &User{Name: "admin"}), false)
+	assert.Error(t, err)
+}
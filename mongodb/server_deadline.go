@@ -0,0 +1,48 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+)
+
+// serverDeadlineMargin is subtracted from the remaining time of the context, so that the client deadline fires
+// before the server gives up on the operation, and the caller sees context.DeadlineExceeded rather than a server error.
+const serverDeadlineMargin = 50 * time.Millisecond
+
+type serverSideDeadlinesOption bool
+
+func (value serverSideDeadlinesOption) apply(o *repositoryOption) {
+	o.serverDeadlines = bool(value)
+}
+
+// WithServerSideDeadlines translates the deadline of the context into the maxTimeMS of FindOne, FindMany, FindManyN,
+// FindCursor, FindIter, Aggregate and CountDocuments, so that the server stops working on an operation the caller gave up on.
+//
+// The server-side limit is the remaining time of the context minus a margin of 50ms. It is only set if neither a [MaxTime]
+// in the context nor the driver options of the call set one, and not at all if less than the margin is left.
+func WithServerSideDeadlines() RepositoryOption {
+	return serverSideDeadlinesOption(true)
+}
+
+// opOptions returns the [OpOption]s of ctx, see [Repository.contextOptions], with the maxTime derived from the deadline of ctx
+// if [WithServerSideDeadlines] is set and ctx sets no maxTime.
+func (r *Repository[T]) opOptions(ctx context.Context) opOption {
+	ops := r.contextOptions(ctx)
+	if !r.serverDeadlines || ops.maxTime != nil {
+		return ops
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return ops
+	}
+	// maxTimeMS has millisecond precision, and 0 would mean no limit at all.
+	remaining := time.Until(deadline) - serverDeadlineMargin
+	if remaining < time.Millisecond {
+		return ops
+	}
+
+	remaining = remaining.Truncate(time.Millisecond)
+	ops.maxTime = &remaining
+	return ops
+}
@@ -0,0 +1,85 @@
+package mongodb_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestServerSideDeadlines(t *testing.T) {
+	var mu sync.Mutex
+	var commands []bson.Raw
+	monitor := &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			commands = append(commands, e.Command)
+		},
+	}
+	// lastMaxTime returns the maxTimeMS of the last command, or -1 if it has none.
+	lastMaxTime := func() int64 {
+		mu.Lock()
+		defer mu.Unlock()
+		value, err := commands[len(commands)-1].LookupErr("maxTimeMS")
+		if err != nil {
+			return -1
+		}
+		return value.AsInt64()
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017").SetMonitor(monitor))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	col := client.Database("testdb").Collection("user_server_deadlines")
+	t.Cleanup(func() {
+		col.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+	repo := mongodb.NewRepository[*User](col, mongodb.WithServerSideDeadlines())
+
+	deadlineCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err = repo.FindMany(deadlineCtx, bson.M{})
+	assert.NoError(t, err)
+	maxTime := lastMaxTime()
+	assert.Greater(t, maxTime, int64(4000))
+	assert.LessOrEqual(t, maxTime, int64(4950))
+
+	_, err = repo.Aggregate(deadlineCtx, mongo.Pipeline{})
+	assert.NoError(t, err)
+	assert.Greater(t, lastMaxTime(), int64(4000))
+
+	_, err = repo.CountDocuments(deadlineCtx, bson.M{})
+	assert.NoError(t, err)
+	assert.Greater(t, lastMaxTime(), int64(4000))
+
+	// An explicit maxTime is respected.
+	_, err = repo.FindMany(deadlineCtx, bson.M{}, options.Find().SetMaxTime(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), lastMaxTime())
+
+	_, err = repo.FindMany(mongodb.WithOpOptions(deadlineCtx, mongodb.MaxTime(2*time.Second)), bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2000), lastMaxTime())
+
+	// Without a deadline, or without the option, no maxTime is set.
+	_, err = repo.FindMany(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), lastMaxTime())
+
+	plain := mongodb.NewRepository[*User](col)
+	_, err = plain.FindMany(deadlineCtx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), lastMaxTime())
+}
@@ -0,0 +1,173 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/sync/singleflight"
+)
+
+// sharedCallTimeout bounds shared calls, since they are not canceled with the context of the caller that started them.
+const sharedCallTimeout = time.Minute
+
+type (
+	// singleflightRepository deduplicates concurrent identical reads of the embedded repository, see [NewSingleflightRepository].
+	singleflightRepository[T Document[T]] struct {
+		Forwarder[T]
+		group *singleflight.Group
+	}
+)
+
+// NewSingleflightRepository wraps inner, so that concurrent identical calls of FindOne, GetByID and CountDocuments
+// run only once, and all callers get the result of that single call. This avoids a stampede of identical queries,
+// e.g. when a hot document expired from a cache.
+//
+// Calls are identical if they have the same method, filter and arguments. Filters are compared regardless of the
+// order of the keys of their maps. Calls with driver options are never deduplicated. Every caller gets its own copy of the
+// document, decoded from the BSON of the shared result, so fields that are not stored in BSON are not copied.
+//
+// The shared call runs with the values of the context of the first caller, but is not canceled with it, and times out
+// after a minute. Every caller stops waiting when its own context is done, without affecting the other callers.
+// All other methods, including writes, are passed through to inner unchanged. The repositories returned by
+// WithSession and similar methods of inner do not deduplicate calls.
+func NewSingleflightRepository[T Document[T]](inner RepositoryI[T]) RepositoryI[T] {
+	return &singleflightRepository[T]{Forwarder: Forwarder[T]{RepositoryI: inner}, group: &singleflight.Group{}}
+}
+
+func (r *singleflightRepository[T]) Registry() *bsoncodec.Registry {
+	return registryOf(r.RepositoryI)
+}
+
+func (r *singleflightRepository[T]) FindOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (T, error) {
+	if len(opts) > 0 {
+		return r.RepositoryI.FindOne(ctx, filter, opts...)
+	}
+
+	return r.findShared(ctx, "FindOne", filter, func(ctx context.Context) (T, error) {
+		return r.RepositoryI.FindOne(ctx, filter)
+	})
+}
+
+func (r *singleflightRepository[T]) GetByID(ctx context.Context, id primitive.ObjectID, projection ...string) (T, error) {
+	return r.findShared(ctx, "GetByID", bson.M{"_id": id, "projection": projection}, func(ctx context.Context) (T, error) {
+		return r.Forwarder.GetByID(ctx, id, projection...)
+	})
+}
+
+func (r *singleflightRepository[T]) CountDocuments(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int, error) {
+	if len(opts) > 0 {
+		return r.RepositoryI.CountDocuments(ctx, filter, opts...)
+	}
+
+	key, err := singleflightKey("CountDocuments", filter)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", "mongodb.NewSingleflightRepository.CountDocuments", err)
+	}
+
+	res, err := doShared(ctx, r.group, key, func(ctx context.Context) (interface{}, error) {
+		return r.RepositoryI.CountDocuments(ctx, filter)
+	})
+	count, _ := res.(int)
+	return count, err
+}
+
+// findShared runs find once for all concurrent callers with the same key, and decodes a copy of the document for every caller.
+func (r *singleflightRepository[T]) findShared(ctx context.Context, name string, filter bson.M, find func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	key, err := singleflightKey(name, filter)
+	if err != nil {
+		return zero, fmt.Errorf("%v: %w", "mongodb.NewSingleflightRepository."+name, err)
+	}
+
+	res, err := doShared(ctx, r.group, key, func(ctx context.Context) (interface{}, error) {
+		doc, err := find(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return bson.MarshalWithRegistry(registryOf(r.RepositoryI), doc)
+	})
+	if err != nil {
+		return zero, err
+	}
+
+	var doc T
+	if err := bson.UnmarshalWithRegistry(registryOf(r.RepositoryI), res.([]byte), &doc); err != nil {
+		return zero, fmt.Errorf("%v: %w", "mongodb.NewSingleflightRepository."+name, err)
+	}
+	return doc, nil
+}
+
+// doShared runs fn once for all concurrent callers with the same key, with a context that keeps the values of ctx,
+// but is not canceled with it. It returns early with the error of ctx, when ctx is done before fn returns.
+func doShared(ctx context.Context, group *singleflight.Group, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	ch := group.DoChan(key, func() (interface{}, error) {
+		shared, cancel := context.WithTimeout(withoutCancel(ctx), sharedCallTimeout)
+		defer cancel()
+		return fn(shared)
+	})
+
+	select {
+	case res := <-ch:
+		return res.Val, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// singleflightKey returns the Extended JSON of the operation name and the filter, with the keys of all maps sorted.
+func singleflightKey(name string, filter bson.M) (string, error) {
+	data, err := bson.MarshalExtJSON(bson.D{{Key: "op", Value: name}, {Key: "filter", Value: sortedKeys(filter)}}, true, false)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// sortedKeys converts all maps in v into bson.D with sorted keys, so that equal filters are marshaled the same way.
+func sortedKeys(v interface{}) interface{} {
+	switch v := v.(type) {
+	case primitive.M:
+		return sortedMap(v)
+	case map[string]interface{}:
+		return sortedMap(v)
+	case primitive.D:
+		res := make(primitive.D, len(v))
+		for i, e := range v {
+			res[i] = primitive.E{Key: e.Key, Value: sortedKeys(e.Value)}
+		}
+		return res
+	case primitive.A:
+		res := make(primitive.A, len(v))
+		for i, e := range v {
+			res[i] = sortedKeys(e)
+		}
+		return res
+	case []interface{}:
+		res := make(primitive.A, len(v))
+		for i, e := range v {
+			res[i] = sortedKeys(e)
+		}
+		return res
+	}
+	return v
+}
+
+func sortedMap(m map[string]interface{}) primitive.D {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	res := make(primitive.D, len(keys))
+	for i, key := range keys {
+		res[i] = primitive.E{Key: key, Value: sortedKeys(m[key])}
+	}
+	return res
+}
@@ -0,0 +1,116 @@
+package mongodb_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// countingFinder counts the calls of FindOne and CountDocuments, which block until release is closed.
+// All other methods panic, since the embedded repository is nil.
+type countingFinder struct {
+	mongodb.RepositoryI[*User]
+	calls   int32
+	release chan struct{}
+}
+
+func (f *countingFinder) FindOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (*User, error) {
+	atomic.AddInt32(&f.calls, 1)
+	select {
+	case <-f.release:
+		return &User{Name: "alice"}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (f *countingFinder) CountDocuments(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int, error) {
+	atomic.AddInt32(&f.calls, 1)
+	select {
+	case <-f.release:
+		return 42, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func TestSingleflightRepository(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingFinder{release: make(chan struct{})}
+	repo := mongodb.NewSingleflightRepository[*User](inner)
+
+	const callers = 50
+	users := make([]*User, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// The same filter with the keys in a different order.
+			filter := bson.M{"name": "alice", "companyID": 1}
+			if i%2 == 0 {
+				filter = bson.M{"companyID": 1, "name": "alice"}
+			}
+			user, err := repo.FindOne(ctx, filter)
+			assert.NoError(t, err)
+			users[i] = user
+		}(i)
+	}
+	// Give all callers time to join the in-flight call.
+	time.Sleep(100 * time.Millisecond)
+	close(inner.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&inner.calls))
+	users[0].Name = "changed"
+	for _, user := range users[1:] {
+		assert.Equal(t, "alice", user.Name)
+	}
+
+	count, err := repo.CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, count)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&inner.calls))
+
+	// Calls with driver options are not deduplicated, but passed through.
+	_, err = repo.FindOne(ctx, bson.M{}, options.FindOne())
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&inner.calls))
+}
+
+func TestSingleflightRepositoryLeaderCanceled(t *testing.T) {
+	inner := &countingFinder{release: make(chan struct{})}
+	repo := mongodb.NewSingleflightRepository[*User](inner)
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error)
+	go func() {
+		_, err := repo.FindOne(leaderCtx, bson.M{"name": "alice"})
+		leader <- err
+	}()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&inner.calls) == 1 }, time.Second, time.Millisecond)
+
+	waiter := make(chan *User)
+	go func() {
+		user, err := repo.FindOne(context.Background(), bson.M{"name": "alice"})
+		assert.NoError(t, err)
+		waiter <- user
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-leader, context.Canceled, "the leader returns when its context is canceled")
+	close(inner.release)
+	user := <-waiter
+	if assert.NotNil(t, user) {
+		assert.Equal(t, "alice", user.Name, "the waiter gets the result of the shared call")
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&inner.calls))
+}
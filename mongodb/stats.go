@@ -0,0 +1,96 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type (
+	CollectionAdmin interface {
+		// Drops the collection with all its documents and indexes.
+		Drop(ctx context.Context) error
+		// Returns the storage statistics of the collection.
+		Stats(ctx context.Context) (CollectionStats, error)
+	}
+
+	// CollectionStats are the storage statistics of a collection, see [Repository.Stats].
+	//
+	// Sizes are in bytes. On sharded collections, the statistics of all shards are summed up.
+	CollectionStats struct {
+		Count          int64            `bson:"count,truncate"`
+		Size           int64            `bson:"size,truncate"`
+		AvgObjSize     int64            `bson:"avgObjSize,truncate"`
+		StorageSize    int64            `bson:"storageSize,truncate"`
+		IndexCount     int              `bson:"nindexes,truncate"`
+		TotalIndexSize int64            `bson:"totalIndexSize,truncate"`
+		IndexSizes     map[string]int64 `bson:"indexSizes"`
+	}
+)
+
+// Drops the collection with all its documents and indexes. Dropping a collection that does not exist is not an error.
+//
+// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.Drop]
+func (r *Repository[T]) Drop(ctx context.Context) (err error) {
+	ctx, finish, err := r.begin(ctx, "Drop", nil)
+	if err != nil {
+		return err
+	}
+	defer func() { err = finish(err) }()
+
+	return r.db.Drop(ctx)
+}
+
+// Returns the storage statistics of the collection, using the $collStats aggregation stage.
+// A collection that does not exist has zero statistics.
+//
+// See [https://www.mongodb.com/docs/manual/reference/operator/aggregation/collStats/]
+func (r *Repository[T]) Stats(ctx context.Context) (_ CollectionStats, err error) {
+	pipeline := mongo.Pipeline{{{Key: "$collStats", Value: bson.M{"storageStats": bson.M{}}}}}
+
+	ctx, finish, err := r.begin(ctx, "Stats", pipeline)
+	if err != nil {
+		return CollectionStats{}, err
+	}
+	defer func() { err = finish(err) }()
+
+	cur, err := r.db.Aggregate(ctx, pipeline)
+	if hasErrorCode(err, codeNamespaceNotFound) {
+		return CollectionStats{}, nil
+	}
+	if err != nil {
+		return CollectionStats{}, fmt.Errorf("%v: %w", "mongodb.Repository.Stats", err)
+	}
+
+	var shards []struct {
+		StorageStats CollectionStats `bson:"storageStats"`
+	}
+	if err := cur.All(ctx, &shards); err != nil {
+		return CollectionStats{}, fmt.Errorf("%v: %w", "mongodb.Repository.Stats", err)
+	}
+
+	var stats CollectionStats
+	for _, shard := range shards {
+		s := shard.StorageStats
+		stats.Count += s.Count
+		stats.Size += s.Size
+		stats.StorageSize += s.StorageSize
+		stats.TotalIndexSize += s.TotalIndexSize
+		if s.IndexCount > stats.IndexCount {
+			stats.IndexCount = s.IndexCount
+		}
+		for name, size := range s.IndexSizes {
+			if stats.IndexSizes == nil {
+				stats.IndexSizes = map[string]int64{}
+			}
+			stats.IndexSizes[name] += size
+		}
+	}
+	if stats.Count > 0 {
+		stats.AvgObjSize = stats.Size / stats.Count
+	}
+
+	return stats, nil
+}
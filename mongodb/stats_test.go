@@ -0,0 +1,40 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestStatsAndDrop(t *testing.T) {
+	ctx := context.Background()
+	col := testCollection(t, "user_stats")
+	repo := mongodb.NewRepository[*User](col).(*mongodb.Repository[*User])
+
+	if _, err := repo.InsertMany(ctx, []*User{{Name: "alice"}, {Name: "bob"}, {Name: "carol"}}); err != nil {
+		t.Fatalf("Error on inserting: %v", err)
+	}
+
+	stats, err := repo.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Error on stats: %v", err)
+	}
+	assert.Equal(t, int64(3), stats.Count)
+	assert.Greater(t, stats.Size, int64(0))
+	assert.Greater(t, stats.AvgObjSize, int64(0))
+	assert.Greater(t, stats.TotalIndexSize, int64(0))
+	assert.Contains(t, stats.IndexSizes, "_id_")
+
+	assert.NoError(t, repo.Drop(ctx))
+
+	names, err := col.Database().ListCollectionNames(ctx, bson.M{"name": col.Name()})
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+
+	stats, err = repo.Stats(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, mongodb.CollectionStats{}, stats)
+}
@@ -0,0 +1,213 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrUnknownField is wrapped by the [*DecodeError] for a stored field that has no struct field, see [WithStrictDecoding].
+var ErrUnknownField = errors.New("mongodb: unknown field")
+
+type (
+	// UnknownFieldHandler is called for every stored field that has no struct field when decoding strictly.
+	// field is the dotted path of the field. Returning nil ignores the field, e.g. after logging a warning,
+	// returning an error fails the decoding.
+	UnknownFieldHandler func(collection string, id interface{}, field string) error
+
+	// DecodeError is returned when decoding strictly fails for a stored document.
+	DecodeError struct {
+		// Collection is the name of the collection, it is empty for [DecodeAllStrict].
+		Collection string
+		// ID is the _id of the document, or nil if it has none.
+		ID interface{}
+		// Field is the dotted path of the offending field, if known.
+		Field string
+		err   error
+	}
+
+	strictDecoder struct {
+		known     map[string]structField
+		registry  *bsoncodec.Registry
+		onUnknown UnknownFieldHandler
+	}
+)
+
+func (e *DecodeError) Error() string {
+	msg := "mongodb: decoding document"
+	if e.Collection != "" {
+		msg += " of " + e.Collection
+	}
+	if e.ID != nil {
+		msg += fmt.Sprintf(" %v", e.ID)
+	}
+	if e.Field != "" {
+		msg += ", field " + e.Field
+	}
+	return msg + ": " + e.err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.err
+}
+
+type strictDecodingOption struct {
+	onUnknown UnknownFieldHandler
+}
+
+func (value strictDecodingOption) apply(o *repositoryOption) {
+	o.strict = true
+	o.onUnknown = value.onUnknown
+}
+
+// WithStrictDecoding checks every document the repository reads for fields that the document struct does not have,
+// and adds the collection, the _id and the field path to decoding errors, see [*DecodeError].
+//
+// Unknown fields are passed to onUnknown. If onUnknown is nil, an unknown field fails the decoding with [ErrUnknownField].
+// Fields of nested structs are checked, the contents of maps, slices and interface fields are not.
+func WithStrictDecoding(onUnknown UnknownFieldHandler) RepositoryOption {
+	return strictDecodingOption{onUnknown: onUnknown}
+}
+
+// DecodeAllStrict decodes all documents of cur into a slice of T with the rules of [WithStrictDecoding],
+// e.g. for the cursor returned by Aggregate. The documents are decoded with the registry of cur.
+func DecodeAllStrict[T any](ctx context.Context, cur *mongo.Cursor, onUnknown UnknownFieldHandler) ([]T, error) {
+	defer cur.Close(context.Background())
+
+	decoder, err := newStrictDecoder(documentType[T](), nil, onUnknown)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.DecodeAllStrict", err)
+	}
+
+	var res []T
+	for cur.Next(ctx) {
+		id, err := decoder.check("", cur.Current)
+		if err != nil {
+			return res, err
+		}
+		var doc T
+		if err := cur.Decode(&doc); err != nil {
+			return res, newDecodeError("", id, err)
+		}
+		res = append(res, doc)
+	}
+
+	return res, cur.Err()
+}
+
+// newStrictDecoder returns the strict decoder for documents of type t, which decodes them with registry.
+func newStrictDecoder(t reflect.Type, registry *bsoncodec.Registry, onUnknown UnknownFieldHandler) (*strictDecoder, error) {
+	decoder := &strictDecoder{registry: registry, onUnknown: onUnknown}
+	if hasInlineMap(t) {
+		// All fields are stored in the map, none are unknown.
+		return decoder, nil
+	}
+
+	fields, err := structFields(t)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder.known = make(map[string]structField, len(fields))
+	for _, field := range fields {
+		decoder.known[field.Path] = field
+	}
+	return decoder, nil
+}
+
+// decode checks raw for unknown fields and decodes it into val.
+func (d *strictDecoder) decode(collection string, raw bson.Raw, val interface{}) error {
+	id, err := d.check(collection, raw)
+	if err != nil {
+		return err
+	}
+
+	if err := bson.UnmarshalWithRegistry(d.registry, raw, val); err != nil {
+		return newDecodeError(collection, id, err)
+	}
+
+	return nil
+}
+
+// check checks raw for unknown fields and returns its _id.
+func (d *strictDecoder) check(collection string, raw bson.Raw) (interface{}, error) {
+	var id interface{}
+	if rawID, err := raw.LookupErr("_id"); err == nil {
+		id = rawID
+	}
+
+	if d.known != nil {
+		for _, field := range d.unknownFields(raw, "") {
+			var err error = ErrUnknownField
+			if d.onUnknown != nil {
+				err = d.onUnknown(collection, id, field)
+			}
+			if err != nil {
+				return id, &DecodeError{Collection: collection, ID: id, Field: field, err: err}
+			}
+		}
+	}
+
+	return id, nil
+}
+
+// newDecodeError returns the [*DecodeError] for an error of the bson decoding, with the path of the field that failed.
+func newDecodeError(collection string, id interface{}, err error) *DecodeError {
+	decodeErr := &DecodeError{Collection: collection, ID: id, err: err}
+	var de *bsoncodec.DecodeError
+	if errors.As(err, &de) {
+		decodeErr.Field = strings.Join(de.Keys(), ".")
+	}
+	return decodeErr
+}
+
+func (d *strictDecoder) unknownFields(raw bson.Raw, prefix string) []string {
+	elements, err := raw.Elements()
+	if err != nil {
+		return nil
+	}
+
+	var unknown []string
+	for _, e := range elements {
+		path := prefix + e.Key()
+		field, ok := d.known[path]
+		if !ok {
+			unknown = append(unknown, path)
+			continue
+		}
+
+		fieldType := indirectType(field.Field.Type)
+		if nested, ok := e.Value().DocumentOK(); ok && IsSubdocumentType(fieldType) && !hasInlineMap(fieldType) {
+			unknown = append(unknown, d.unknownFields(nested, path+".")...)
+		}
+	}
+
+	return unknown
+}
+
+// hasInlineMap reports whether t is a struct with an inlined map, which receives all fields without a struct field.
+func hasInlineMap(t reflect.Type) bool {
+	t = indirectType(t)
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tags, err := bsoncodec.DefaultStructTagParser.ParseStructTags(sf)
+		if err != nil || !tags.Inline {
+			continue
+		}
+		if fieldType := indirectType(sf.Type); fieldType.Kind() == reflect.Map || (fieldType.Kind() == reflect.Struct && hasInlineMap(fieldType)) {
+			return true
+		}
+	}
+
+	return false
+}
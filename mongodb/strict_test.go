@@ -0,0 +1,157 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+type (
+	StrictAddress struct {
+		City string `bson:"city"`
+	}
+
+	StrictUser struct {
+		mongodb.BaseModel `bson:",inline"`
+		Name              string        `bson:"name"`
+		Age               int           `bson:"age"`
+		Address           StrictAddress `bson:"address"`
+		Tags              bson.M        `bson:"tags"`
+	}
+)
+
+func TestDecodeAllStrict(t *testing.T) {
+	ctx := context.Background()
+
+	cur, err := mongo.NewCursorFromDocuments([]interface{}{
+		bson.M{"_id": primitive.NewObjectID(), "name": "Alice", "address": bson.M{"city": "Berlin"}, "tags": bson.M{"anything": true}},
+	}, nil, nil)
+	assert.NoError(t, err)
+	users, err := mongodb.DecodeAllStrict[*StrictUser](ctx, cur, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, users, 1) {
+		assert.Equal(t, "Berlin", users[0].Address.City)
+	}
+
+	// Unknown fields fail without a handler.
+	bobID := primitive.NewObjectID()
+	cur, _ = mongo.NewCursorFromDocuments([]interface{}{
+		bson.M{"_id": bobID, "name": "Bob", "address": bson.M{"zip": "10115"}},
+	}, nil, nil)
+	_, err = mongodb.DecodeAllStrict[*StrictUser](ctx, cur, nil)
+	assert.ErrorIs(t, err, mongodb.ErrUnknownField)
+	var decodeErr *mongodb.DecodeError
+	if assert.ErrorAs(t, err, &decodeErr) {
+		assert.Equal(t, "address.zip", decodeErr.Field)
+		assert.Equal(t, bobID, decodeErr.ID.(bson.RawValue).ObjectID())
+	}
+
+	// A handler can turn them into warnings.
+	var warnings []string
+	cur, _ = mongo.NewCursorFromDocuments([]interface{}{
+		bson.M{"_id": primitive.NewObjectID(), "name": "Carol", "nickname": "C"},
+	}, nil, nil)
+	users, err = mongodb.DecodeAllStrict[*StrictUser](ctx, cur, func(collection string, id interface{}, field string) error {
+		warnings = append(warnings, field)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.Equal(t, []string{"nickname"}, warnings)
+
+	// Type mismatches name the field.
+	cur, _ = mongo.NewCursorFromDocuments([]interface{}{
+		bson.M{"_id": primitive.NewObjectID(), "name": "Dave", "age": "forty"},
+	}, nil, nil)
+	_, err = mongodb.DecodeAllStrict[*StrictUser](ctx, cur, nil)
+	if assert.ErrorAs(t, err, &decodeErr) {
+		assert.Equal(t, "age", decodeErr.Field)
+	}
+}
+
+func TestStrictDecodingRepository(t *testing.T) {
+	ctx := context.Background()
+	col := testCollection(t, "user_strict")
+	repo := mongodb.NewRepository[*StrictUser](col, mongodb.WithStrictDecoding(nil))
+
+	_, err := col.InsertMany(ctx, []interface{}{
+		bson.M{"name": "Alice", "age": 30},
+		bson.M{"name": "Bob", "age": "thirty"},
+		bson.M{"name": "Carol", "renamed": "x"},
+	})
+	if err != nil {
+		t.Fatalf("Error on inserting users: %v", err)
+	}
+
+	_, err = repo.FindOne(ctx, bson.M{"name": "Alice"})
+	assert.NoError(t, err)
+
+	var decodeErr *mongodb.DecodeError
+	_, err = repo.FindOne(ctx, bson.M{"name": "Bob"})
+	if assert.ErrorAs(t, err, &decodeErr) {
+		assert.Equal(t, "user_strict", decodeErr.Collection)
+		assert.Equal(t, "age", decodeErr.Field)
+		assert.NotNil(t, decodeErr.ID)
+	}
+
+	_, err = repo.FindMany(ctx, bson.M{"name": "Carol"})
+	assert.ErrorIs(t, err, mongodb.ErrUnknownField)
+	if assert.ErrorAs(t, err, &decodeErr) {
+		assert.Equal(t, "renamed", decodeErr.Field)
+	}
+}
+
+func TestStrictDecodingFindOne(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("type mismatch", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "db.user_strict", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "name", Value: "Bob"}, {Key: "age", Value: "thirty"}}))
+		repo := mongodb.NewRepository[*StrictUser](mt.Coll, mongodb.WithStrictDecoding(nil))
+
+		_, err := repo.FindOne(context.Background(), bson.M{"name": "Bob"})
+		var decodeErr *mongodb.DecodeError
+		if assert.ErrorAs(t, err, &decodeErr) {
+			assert.Equal(t, "age", decodeErr.Field)
+		}
+	})
+	mt.Run("unknown field", func(mt *mtest.T) {
+		carolID := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "db.user_strict", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: carolID}, {Key: "name", Value: "Carol"}, {Key: "renamed", Value: "x"}}))
+		repo := mongodb.NewRepository[*StrictUser](mt.Coll, mongodb.WithStrictDecoding(nil)).(*mongodb.Repository[*StrictUser])
+
+		_, err := repo.GetByID(context.Background(), carolID)
+		assert.ErrorIs(t, err, mongodb.ErrUnknownField)
+	})
+}
+
+func TestStrictDecodingFindOneOrCreate(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("unknown field", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value",
+			Value: bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "name", Value: "Dave"}, {Key: "renamed", Value: "x"}}}))
+		repo := mongodb.NewRepository[*StrictUser](mt.Coll, mongodb.WithStrictDecoding(nil)).(*mongodb.Repository[*StrictUser])
+
+		_, _, err := repo.FindOneOrCreate(context.Background(), bson.M{"name": "Dave"}, &StrictUser{})
+		assert.ErrorIs(t, err, mongodb.ErrUnknownField)
+	})
+}
+
+func TestStrictDecodingProcessConcurrently(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("unknown field", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "db.user_strict", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "name", Value: "Erin"}, {Key: "renamed", Value: "x"}}))
+		// Wrappers decode like the repository they wrap.
+		repo := mongodb.NewSingleflightRepository(mongodb.NewRepository[*StrictUser](mt.Coll, mongodb.WithStrictDecoding(nil)))
+
+		_, err := mongodb.ProcessConcurrently(context.Background(), repo, nil, 1, func(ctx context.Context, u *StrictUser) error { return nil })
+		assert.ErrorIs(t, err, mongodb.ErrUnknownField)
+	})
+}
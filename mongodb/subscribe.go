@@ -0,0 +1,252 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// ChangeEvent is a change event of a [Subscribe] change stream.
+	ChangeEvent[T any] struct {
+		// OperationType is e.g. "insert", "update", "replace", "delete" or "invalidate".
+		OperationType string
+		// DocumentKey is the _id of the changed document, and the shard key on sharded collections.
+		DocumentKey bson.M
+		// FullDocument is the changed document, decoded into T.
+		// It is the zero value for deletes, and for updates of documents that were deleted before the lookup.
+		FullDocument T
+		// UpdateDescription describes the changed fields of an update.
+		UpdateDescription *UpdateDescription
+		ClusterTime       primitive.Timestamp
+		// ResumeToken can be passed to [options.ChangeStreamOptions.SetResumeAfter] to continue after this event.
+		ResumeToken bson.Raw
+		// Err is set on the last event before the channel is closed, if the stream failed or was invalidated.
+		Err error
+	}
+
+	UpdateDescription struct {
+		UpdatedFields bson.M   `bson:"updatedFields"`
+		RemovedFields []string `bson:"removedFields"`
+	}
+
+	// SlowConsumerPolicy decides what [Subscribe] does with an event when the channel buffer is full.
+	SlowConsumerPolicy int
+
+	// SubscribeOption configures [Subscribe].
+	SubscribeOption interface {
+		apply(*subscribeOption)
+	}
+)
+
+const (
+	// Block waits until the consumer receives an event, so that no event is lost. It is the default.
+	Block SlowConsumerPolicy = iota
+	// DropOldest removes the oldest buffered event to make room for the new one.
+	DropOldest
+)
+
+var (
+	// ErrChangeStreamInvalidated is the Err of the last [ChangeEvent] of a stream that was invalidated, e.g. because the collection was dropped.
+	ErrChangeStreamInvalidated = errors.New("mongodb: change stream invalidated")
+)
+
+type (
+	subscribeOption struct {
+		buffer int
+		policy SlowConsumerPolicy
+		stream *options.ChangeStreamOptions
+	}
+)
+
+type subscribeBufferOption int
+
+func (value subscribeBufferOption) apply(o *subscribeOption) {
+	if value > 0 {
+		o.buffer = int(value)
+	}
+}
+
+// WithSubscribeBuffer sets the buffer size of the event channel, the default is 64.
+func WithSubscribeBuffer(size int) SubscribeOption {
+	return subscribeBufferOption(size)
+}
+
+type slowConsumerPolicyOption SlowConsumerPolicy
+
+func (value slowConsumerPolicyOption) apply(o *subscribeOption) {
+	o.policy = SlowConsumerPolicy(value)
+}
+
+// WithSlowConsumerPolicy sets what happens when the channel buffer is full, the default is [Block].
+func WithSlowConsumerPolicy(policy SlowConsumerPolicy) SubscribeOption {
+	return slowConsumerPolicyOption(policy)
+}
+
+type changeStreamOption struct {
+	opts *options.ChangeStreamOptions
+}
+
+func (value changeStreamOption) apply(o *subscribeOption) {
+	o.stream = value.opts
+}
+
+// WithChangeStreamOptions sets the options of the change stream, e.g. to resume after a token.
+// By default, the full document of updates is looked up.
+func WithChangeStreamOptions(opts *options.ChangeStreamOptions) SubscribeOption {
+	return changeStreamOption{opts: opts}
+}
+
+// changeEvent is the part of a change stream event that is decoded by [Subscribe].
+type changeEvent struct {
+	OperationType     string              `bson:"operationType"`
+	DocumentKey       bson.M              `bson:"documentKey"`
+	FullDocument      bson.Raw            `bson:"fullDocument"`
+	UpdateDescription *UpdateDescription  `bson:"updateDescription"`
+	ClusterTime       primitive.Timestamp `bson:"clusterTime"`
+}
+
+// Subscribe runs a change stream on the collection of r in a goroutine and sends the decoded events to the returned channel.
+// pipeline filters or transforms the events and may be nil.
+//
+// The stream stops when ctx is done or the returned cancel func is called, which waits until the goroutine has exited.
+// The channel is closed when the stream stops. If the stream failed or was invalidated, e.g. by dropping the collection,
+// the last event has Err set, [ErrChangeStreamInvalidated] for invalidations.
+//
+// The result transforms of a [Repository], see [WithResultTransform], are applied to the FullDocument of the events,
+// and events whose document is dropped are skipped. Decorators that wrap the repository hide its transforms.
+// r must implement [Watcher], see [Capability].
+//
+//	events, cancel, err := mongodb.Subscribe[*User](ctx, repo, nil)
+//	if err != nil {
+//		return err
+//	}
+//	defer cancel()
+//
+//	for event := range events {
+//		if event.Err != nil {
+//			return event.Err
+//		}
+//		...
+//	}
+func Subscribe[T Document[T]](ctx context.Context, r RepositoryI[T], pipeline mongo.Pipeline, opts ...SubscribeOption) (<-chan ChangeEvent[T], func(), error) {
+	ops := &subscribeOption{
+		buffer: 64,
+		stream: options.ChangeStream().SetFullDocument(options.UpdateLookup),
+	}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+
+	watcher, err := Capability[Watcher](r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%v: %w", "mongodb.Subscribe", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := watcher.Watch(ctx, pipeline, ops.stream)
+	if err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("%v: %w", "mongodb.Subscribe", err)
+	}
+
+	events := make(chan ChangeEvent[T], ops.buffer)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer close(events)
+		defer stream.Close(context.Background())
+
+		transformer, _ := r.(resultTransformer[T])
+		registry := registryOf(r)
+		for stream.Next(ctx) {
+			event, keep, err := decodeChangeEvent[T](ctx, stream, registry, transformer)
+			if err == nil && !keep {
+				continue
+			}
+			if err == nil && event.OperationType == "invalidate" {
+				event.Err = ErrChangeStreamInvalidated
+			}
+			if err != nil {
+				event = ChangeEvent[T]{Err: fmt.Errorf("%v: %w", "mongodb.Subscribe", err)}
+			}
+
+			if !sendChangeEvent(ctx, events, event, ops.policy) || event.Err != nil {
+				return
+			}
+		}
+
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			sendChangeEvent(ctx, events, ChangeEvent[T]{Err: fmt.Errorf("%v: %w", "mongodb.Subscribe", err)}, ops.policy)
+		}
+	}()
+
+	return events, func() {
+		cancel()
+		<-done
+	}, nil
+}
+
+// decodeChangeEvent decodes the current event of stream, with the full document decoded with registry,
+// and reports false if transformer dropped its full document.
+func decodeChangeEvent[T Document[T]](ctx context.Context, stream *mongo.ChangeStream, registry *bsoncodec.Registry, transformer resultTransformer[T]) (ChangeEvent[T], bool, error) {
+	var raw changeEvent
+	if err := stream.Decode(&raw); err != nil {
+		return ChangeEvent[T]{}, false, err
+	}
+
+	event := ChangeEvent[T]{
+		OperationType:     raw.OperationType,
+		DocumentKey:       raw.DocumentKey,
+		UpdateDescription: raw.UpdateDescription,
+		ClusterTime:       raw.ClusterTime,
+		ResumeToken:       stream.ResumeToken(),
+	}
+
+	if len(raw.FullDocument) > 0 {
+		doc := newTValue[T]()
+		if err := bson.UnmarshalWithRegistry(registry, raw.FullDocument, doc); err != nil {
+			return event, false, fmt.Errorf("decoding full document: %w", err)
+		}
+		event.FullDocument = doc
+
+		if transformer != nil {
+			keep, err := transformer.transformResult(ctx, doc)
+			return event, keep, err
+		}
+	}
+
+	return event, true, nil
+}
+
+// sendChangeEvent sends event according to policy, and reports false if ctx is done before it could be sent.
+func sendChangeEvent[T any](ctx context.Context, events chan ChangeEvent[T], event ChangeEvent[T], policy SlowConsumerPolicy) bool {
+	if policy == DropOldest {
+		// Only this goroutine sends, so there is room for the event once the oldest one was removed.
+		for {
+			select {
+			case events <- event:
+				return true
+			default:
+			}
+			select {
+			case <-events:
+			default:
+			}
+		}
+	}
+
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
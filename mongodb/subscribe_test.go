@@ -0,0 +1,101 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/goleak"
+)
+
+func nextEvent[T any](t *testing.T, events <-chan mongodb.ChangeEvent[T]) mongodb.ChangeEvent[T] {
+	t.Helper()
+
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatalf("Channel closed before the next event")
+		}
+		return event
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Timeout waiting for the next event")
+	}
+	return mongodb.ChangeEvent[T]{}
+}
+
+// verifyNoLeaks fails t if goroutines started during the test are still running after its cleanup,
+// including the one that disconnects the client of [testCollection].
+func verifyNoLeaks(t *testing.T) {
+	t.Helper()
+
+	ignore := goleak.IgnoreCurrent()
+	t.Cleanup(func() { goleak.VerifyNone(t, ignore) })
+}
+
+func TestSubscribe(t *testing.T) {
+	ctx := context.Background()
+	verifyNoLeaks(t)
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_subscribe"))
+
+	events, cancel, err := mongodb.Subscribe[*User](ctx, repo, nil)
+	if err != nil {
+		t.Fatalf("Error on subscribing: %v", err)
+	}
+
+	user := &User{Name: "alice", Email: "alice@example.com"}
+	if _, err := repo.InsertOne(ctx, user); err != nil {
+		t.Fatalf("Error on inserting: %v", err)
+	}
+	event := nextEvent(t, events)
+	assert.Equal(t, "insert", event.OperationType)
+	assert.Equal(t, "alice", event.FullDocument.Name)
+
+	if _, err := repo.UpdateOne(ctx, bson.M{"_id": user.MongoID}, bson.M{"email": "alice@example.org"}); err != nil {
+		t.Fatalf("Error on updating: %v", err)
+	}
+	event = nextEvent(t, events)
+	assert.Equal(t, "update", event.OperationType)
+	assert.Equal(t, "alice@example.org", event.FullDocument.Email)
+	if assert.NotNil(t, event.UpdateDescription) {
+		assert.Equal(t, "alice@example.org", event.UpdateDescription.UpdatedFields["email"])
+	}
+
+	if err := repo.DeleteOne(ctx, bson.M{"_id": user.MongoID}); err != nil {
+		t.Fatalf("Error on deleting: %v", err)
+	}
+	event = nextEvent(t, events)
+	assert.Equal(t, "delete", event.OperationType)
+	assert.Equal(t, user.MongoID, event.DocumentKey["_id"])
+	assert.Nil(t, event.FullDocument)
+
+	cancel()
+	_, open := <-events
+	assert.False(t, open)
+}
+
+func TestSubscribeInvalidate(t *testing.T) {
+	ctx := context.Background()
+	verifyNoLeaks(t)
+	col := testCollection(t, "user_subscribe_invalidate")
+	repo := mongodb.NewRepository[*User](col)
+
+	events, cancel, err := mongodb.Subscribe[*User](ctx, repo, nil, mongodb.WithSlowConsumerPolicy(mongodb.DropOldest))
+	if err != nil {
+		t.Fatalf("Error on subscribing: %v", err)
+	}
+	defer cancel()
+
+	if _, err := repo.InsertOne(ctx, &User{Name: "bob"}); err != nil {
+		t.Fatalf("Error on inserting: %v", err)
+	}
+	assert.NoError(t, col.Drop(ctx))
+
+	var last mongodb.ChangeEvent[*User]
+	for event := range events {
+		last = event
+	}
+	assert.ErrorIs(t, last.Err, mongodb.ErrChangeStreamInvalidated)
+}
@@ -0,0 +1,133 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type (
+	// TimeBucket is the size of the buckets of [CountByTimeBucket].
+	TimeBucket int
+
+	// BucketCount is the number of documents in a single bucket of [CountByTimeBucket].
+	BucketCount struct {
+		// Start is the beginning of the bucket in the location of the count, e.g. midnight for [Day].
+		Start time.Time
+		Count int
+	}
+)
+
+const (
+	Hour TimeBucket = iota
+	Day
+	// Week buckets start on Monday.
+	Week
+	Month
+)
+
+// ErrInvalidTimeRange is returned by [CountByTimeBucket] if from is not before to.
+var ErrInvalidTimeRange = errors.New("mongodb: invalid time range")
+
+func (b TimeBucket) String() string {
+	switch b {
+	case Hour:
+		return "hour"
+	case Day:
+		return "day"
+	case Week:
+		return "week"
+	case Month:
+		return "month"
+	}
+	return fmt.Sprintf("TimeBucket(%d)", int(b))
+}
+
+// CountByTimeBucket returns the number of documents matching filter whose dateField is in [from, to),
+// per hour, day, week or month in the given location, with a single aggregation. A nil loc is UTC.
+//
+// The buckets are computed by the server with $dateTrunc in loc, so that days start at local midnight also across DST changes.
+// The result is sorted chronologically and contains every bucket from the one containing from to the one containing to,
+// buckets without documents have a Count of 0. $dateTrunc requires MongoDB 5.0.
+func CountByTimeBucket(ctx context.Context, r Aggregater, filter bson.M, dateField string, bucket TimeBucket, loc *time.Location, from, to time.Time) ([]BucketCount, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if bucket < Hour || bucket > Month {
+		return nil, fmt.Errorf("%v: unknown bucket %v", "mongodb.CountByTimeBucket", bucket)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%v: %w: %v is not before %v", "mongodb.CountByTimeBucket", ErrInvalidTimeRange, from, to)
+	}
+
+	match, err := MergeBSON(filter, bson.M{dateField: bson.M{"$gte": from, "$lt": to}}, MergeFilter)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.CountByTimeBucket", err)
+	}
+
+	trunc := bson.M{"date": "$" + dateField, "unit": bucket.String(), "timezone": loc.String()}
+	if bucket == Week {
+		trunc["startOfWeek"] = "monday"
+	}
+
+	cur, err := r.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{"_id": bson.M{"$dateTrunc": trunc}, "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.CountByTimeBucket", err)
+	}
+
+	var counts []struct {
+		Start time.Time `bson:"_id"`
+		Count int       `bson:"count"`
+	}
+	if err := cur.All(ctx, &counts); err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.CountByTimeBucket", err)
+	}
+
+	byStart := make(map[int64]int, len(counts))
+	for _, c := range counts {
+		byStart[c.Start.UnixMilli()] += c.Count
+	}
+
+	var res []BucketCount
+	for start := truncateToBucket(from.In(loc), bucket); start.Before(to); start = nextBucket(start, bucket) {
+		res = append(res, BucketCount{Start: start, Count: byStart[start.UnixMilli()]})
+	}
+
+	return res, nil
+}
+
+// truncateToBucket returns the start of the bucket containing t, in the location of t.
+func truncateToBucket(t time.Time, bucket TimeBucket) time.Time {
+	year, month, day := t.Date()
+	switch bucket {
+	case Hour:
+		// Subtracting the minutes keeps the offset of t, which time.Date can not tell apart in the repeated hour of a DST change.
+		return t.Add(-time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	case Week:
+		return time.Date(year, month, day-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	case Month:
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// nextBucket returns the start of the bucket after the one starting at start.
+func nextBucket(start time.Time, bucket TimeBucket) time.Time {
+	year, month, day := start.Date()
+	switch bucket {
+	case Hour:
+		return start.Add(time.Hour)
+	case Week:
+		return time.Date(year, month, day+7, 0, 0, 0, 0, start.Location())
+	case Month:
+		return time.Date(year, month+1, 1, 0, 0, 0, 0, start.Location())
+	}
+	return time.Date(year, month, day+1, 0, 0, 0, 0, start.Location())
+}
@@ -0,0 +1,80 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCountByTimeBucketAcrossDST(t *testing.T) {
+	ctx := context.Background()
+	col := testCollection(t, "event_count_by_time_bucket")
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("Error loading location: %v", err)
+	}
+
+	// Clocks in Berlin moved from 02:00 to 03:00 on 2024-03-31.
+	local := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.March, day, hour, minute, 0, 0, berlin)
+	}
+	_, err = col.InsertMany(ctx, []interface{}{
+		bson.M{"kind": "login", "at": local(30, 23, 30)},
+		bson.M{"kind": "login", "at": local(31, 0, 30)}, // still March 30 in UTC
+		bson.M{"kind": "login", "at": local(31, 3, 30)},
+		bson.M{"kind": "login", "at": local(31, 23, 30)},
+		bson.M{"kind": "login", "at": local(32, 0, 30)}, // April 1, still March 31 in UTC
+		bson.M{"kind": "logout", "at": local(31, 12, 0)},
+	})
+	if err != nil {
+		t.Fatalf("Error inserting events: %v", err)
+	}
+
+	repo := mongodb.NewRepository[*User](col)
+	counts, err := mongodb.CountByTimeBucket(ctx, repo, bson.M{"kind": "login"}, "at", mongodb.Day, berlin, local(29, 0, 0), local(34, 0, 0))
+	if err != nil {
+		t.Fatalf("Error counting: %v", err)
+	}
+
+	assert.Equal(t, []mongodb.BucketCount{
+		{Start: local(29, 0, 0), Count: 0},
+		{Start: local(30, 0, 0), Count: 1},
+		{Start: local(31, 0, 0), Count: 3},
+		{Start: local(32, 0, 0), Count: 1},
+		{Start: local(33, 0, 0), Count: 0},
+	}, counts)
+	// March 31 is only 23 hours long.
+	assert.Equal(t, 23*time.Hour, counts[3].Start.Sub(counts[2].Start))
+
+	hours, err := mongodb.CountByTimeBucket(ctx, repo, nil, "at", mongodb.Hour, berlin, local(31, 0, 0), local(31, 4, 0))
+	if err != nil {
+		t.Fatalf("Error counting hours: %v", err)
+	}
+	if assert.Len(t, hours, 3) {
+		assert.Equal(t, []int{1, 0, 1}, []int{hours[0].Count, hours[1].Count, hours[2].Count})
+		assert.Equal(t, local(31, 3, 0), hours[2].Start)
+	}
+
+	months, err := mongodb.CountByTimeBucket(ctx, repo, nil, "at", mongodb.Month, berlin, local(1, 0, 0), local(45, 0, 0))
+	if err != nil {
+		t.Fatalf("Error counting months: %v", err)
+	}
+	assert.Equal(t, []mongodb.BucketCount{
+		{Start: local(1, 0, 0), Count: 5},
+		{Start: local(32, 0, 0), Count: 1},
+	}, months)
+}
+
+func TestCountByTimeBucketInvalid(t *testing.T) {
+	from := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := mongodb.CountByTimeBucket(context.Background(), nil, nil, "at", mongodb.Day, nil, from, from)
+	assert.ErrorIs(t, err, mongodb.ErrInvalidTimeRange)
+
+	_, err = mongodb.CountByTimeBucket(context.Background(), nil, nil, "at", mongodb.TimeBucket(7), nil, from, from.Add(time.Hour))
+	assert.Error(t, err)
+}
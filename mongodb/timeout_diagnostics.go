@@ -0,0 +1,265 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type (
+	// TimeoutDiagnostics records driver events to explain why operations timed out, see [NewTimeoutDiagnostics].
+	TimeoutDiagnostics struct {
+		mu    sync.Mutex
+		pools map[string]*PoolStats
+	}
+
+	// TimeoutInfo describes where the time of a timed-out operation was spent, see [TimeoutDetails].
+	TimeoutInfo struct {
+		// Address is the server the last command was sent to, or empty if no command was sent.
+		Address string
+		// ConnectionWait is the time the operation did not execute a command, mostly selecting a server and waiting for a connection.
+		ConnectionWait time.Duration
+		// Execution is the time commands of the operation ran, from sending them until their reply or failure.
+		Execution time.Duration
+		// WaitingForConnection is true if no command was running when the operation timed out,
+		// e.g. because the pool was exhausted or no server was reachable.
+		WaitingForConnection bool
+		// Pool are the connections of the pool of Address when the operation timed out.
+		// If no command was sent, they are the connections of all pools.
+		Pool PoolStats
+	}
+
+	// PoolStats counts the connections of a connection pool, see [TimeoutInfo].
+	PoolStats struct {
+		// InUse is the number of connections checked out by operations.
+		InUse int
+		// Idle is the number of open connections that are not checked out.
+		Idle int
+		// Waiting is the number of operations waiting to check out a connection.
+		Waiting int
+	}
+
+	// OpError is returned by operations that timed out on a repository with [TimeoutDiagnostics] registered.
+	// It unwraps to the error of the operation, see [TimeoutDetails].
+	OpError struct {
+		// Operation is the name of the repository method, e.g. "FindOne".
+		Operation  string
+		Collection string
+		Timeout    TimeoutInfo
+		err        error
+	}
+
+	// timeoutTrace collects the command events of a single operation.
+	timeoutTrace struct {
+		mu        sync.Mutex
+		address   string
+		running   int
+		started   time.Time
+		execution time.Duration
+	}
+
+	timeoutTraceKey struct{}
+)
+
+// Compile-time check that TimeoutDiagnostics can be registered with [WithHook].
+var _ OperationHook = (*TimeoutDiagnostics)(nil)
+
+// NewTimeoutDiagnostics returns diagnostics that enrich timeout errors with the time spent waiting for a connection
+// and executing commands, the server address and the state of its connection pool.
+//
+// It needs both the [TimeoutDiagnostics.CommandMonitor] and the [TimeoutDiagnostics.PoolMonitor] of the client,
+// and has to be registered with [WithHook] on the repositories whose errors it enriches:
+//
+//	diagnostics := mongodb.NewTimeoutDiagnostics()
+//	clientOptions.SetMonitor(diagnostics.CommandMonitor()).SetPoolMonitor(diagnostics.PoolMonitor())
+//	repo := mongodb.NewRepository[*User](col, mongodb.WithHook(diagnostics))
+//
+// Timed-out operations then return an [*OpError], whose diagnostics are returned by [TimeoutDetails].
+func NewTimeoutDiagnostics() *TimeoutDiagnostics {
+	return &TimeoutDiagnostics{pools: map[string]*PoolStats{}}
+}
+
+func (e *OpError) Error() string {
+	info := e.Timeout
+	address := info.Address
+	if address == "" {
+		address = "no server"
+	}
+	return fmt.Sprintf("mongodb: %v on %v timed out after waiting %v for a connection and executing %v on %v (pool: %d in use, %d idle, %d waiting): %v",
+		e.Operation, e.Collection, info.ConnectionWait, info.Execution, address, info.Pool.InUse, info.Pool.Idle, info.Pool.Waiting, e.err)
+}
+
+func (e *OpError) Unwrap() error {
+	return e.err
+}
+
+// TimeoutDetails returns the diagnostics of err, if it is or wraps an [*OpError].
+func TimeoutDetails(err error) (TimeoutInfo, bool) {
+	var opErr *OpError
+	if !errors.As(err, &opErr) {
+		return TimeoutInfo{}, false
+	}
+	return opErr.Timeout, true
+}
+
+// CommandMonitor returns the command monitor to install with options.ClientOptions.SetMonitor.
+func (d *TimeoutDiagnostics) CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			if trace, ok := ctx.Value(timeoutTraceKey{}).(*timeoutTrace); ok {
+				trace.commandStarted(connectionAddress(evt.ConnectionID))
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			if trace, ok := ctx.Value(timeoutTraceKey{}).(*timeoutTrace); ok {
+				trace.commandFinished()
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			if trace, ok := ctx.Value(timeoutTraceKey{}).(*timeoutTrace); ok {
+				trace.commandFinished()
+			}
+		},
+	}
+}
+
+// PoolMonitor returns the pool monitor to install with options.ClientOptions.SetPoolMonitor.
+func (d *TimeoutDiagnostics) PoolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{Event: d.poolEvent}
+}
+
+func (d *TimeoutDiagnostics) poolEvent(evt *event.PoolEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if evt.Type == event.PoolClosedEvent {
+		delete(d.pools, evt.Address)
+		return
+	}
+
+	pool, ok := d.pools[evt.Address]
+	if !ok {
+		pool = &PoolStats{}
+		d.pools[evt.Address] = pool
+	}
+
+	// Idle counts all open connections here, the checked out ones are subtracted in poolStats.
+	switch evt.Type {
+	case event.ConnectionCreated:
+		pool.Idle++
+	case event.ConnectionClosed:
+		pool.Idle--
+	case event.GetStarted:
+		pool.Waiting++
+	case event.GetFailed:
+		pool.Waiting--
+	case event.GetSucceeded:
+		pool.Waiting--
+		pool.InUse++
+	case event.ConnectionReturned:
+		pool.InUse--
+	}
+}
+
+// poolStats returns the connections of the pool of address, or of all pools if address is empty.
+func (d *TimeoutDiagnostics) poolStats(address string) PoolStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var stats PoolStats
+	for poolAddress, pool := range d.pools {
+		if address != "" && poolAddress != address {
+			continue
+		}
+		stats.InUse += pool.InUse
+		stats.Idle += pool.Idle
+		stats.Waiting += pool.Waiting
+	}
+	stats.Idle -= stats.InUse
+	if stats.Idle < 0 {
+		stats.Idle = 0
+	}
+
+	return stats
+}
+
+func (d *TimeoutDiagnostics) Before(ctx context.Context, _ *Operation) (context.Context, error) {
+	return context.WithValue(ctx, timeoutTraceKey{}, &timeoutTrace{}), nil
+}
+
+func (d *TimeoutDiagnostics) After(ctx context.Context, op *Operation, err error) error {
+	if err == nil || !(errors.Is(err, context.DeadlineExceeded) || mongo.IsTimeout(err)) {
+		return err
+	}
+	// Operations that run other operations of the repository are only enriched once, by the operation that timed out.
+	var opErr *OpError
+	if errors.As(err, &opErr) {
+		return err
+	}
+	trace, ok := ctx.Value(timeoutTraceKey{}).(*timeoutTrace)
+	if !ok {
+		return err
+	}
+
+	info := trace.info(time.Since(op.Started))
+	info.Pool = d.poolStats(info.Address)
+
+	return &OpError{Operation: op.Name, Collection: op.Collection, Timeout: info, err: err}
+}
+
+func (t *timeoutTrace) commandStarted(address string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.address = address
+	if t.running == 0 {
+		t.started = time.Now()
+	}
+	t.running++
+}
+
+// commandFinished ends a command. Commands that run concurrently, e.g. for [Repository.FindManyParallel], are counted once.
+func (t *timeoutTrace) commandFinished() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.running--
+	if t.running == 0 {
+		t.execution += time.Since(t.started)
+	}
+}
+
+// info splits elapsed, the duration of the operation, into the time spent waiting and executing commands.
+func (t *timeoutTrace) info(elapsed time.Duration) TimeoutInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	execution := t.execution
+	if t.running > 0 {
+		execution += time.Since(t.started)
+	}
+	if execution > elapsed {
+		execution = elapsed
+	}
+
+	return TimeoutInfo{
+		Address:              t.address,
+		ConnectionWait:       elapsed - execution,
+		Execution:            execution,
+		WaitingForConnection: t.running == 0,
+	}
+}
+
+// connectionAddress returns the server address of a driver connection id like "localhost:27017[-3]".
+func connectionAddress(connectionID string) string {
+	if i := strings.LastIndex(connectionID, "[-"); i >= 0 {
+		return connectionID[:i]
+	}
+	return connectionID
+}
@@ -0,0 +1,110 @@
+package mongodb_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestTimeoutDetails(t *testing.T) {
+	ctx := context.Background()
+	diagnostics := mongodb.NewTimeoutDiagnostics()
+	pool := diagnostics.PoolMonitor()
+	for _, typ := range []string{event.ConnectionCreated, event.ConnectionCreated, event.GetStarted, event.GetSucceeded, event.GetStarted} {
+		pool.Event(&event.PoolEvent{Type: typ, Address: "db1:27017"})
+	}
+	pool.Event(&event.PoolEvent{Type: event.ConnectionCreated, Address: "db2:27017"})
+
+	op := &mongodb.Operation{Name: "FindOne", Collection: "users", Started: time.Now()}
+
+	// The command was sent, but the server did not reply in time.
+	opCtx, err := diagnostics.Before(ctx, op)
+	assert.NoError(t, err)
+	monitor := diagnostics.CommandMonitor()
+	monitor.Started(opCtx, &event.CommandStartedEvent{ConnectionID: "db1:27017[-7]"})
+	time.Sleep(10 * time.Millisecond)
+
+	err = diagnostics.After(opCtx, op, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	info, ok := mongodb.TimeoutDetails(fmt.Errorf("loading user: %w", err))
+	if assert.True(t, ok) {
+		assert.Equal(t, "db1:27017", info.Address)
+		assert.False(t, info.WaitingForConnection)
+		assert.GreaterOrEqual(t, info.Execution, 10*time.Millisecond)
+		assert.Equal(t, mongodb.PoolStats{InUse: 1, Idle: 1, Waiting: 1}, info.Pool)
+	}
+	assert.Contains(t, err.Error(), "FindOne on users timed out")
+
+	// The command was never sent.
+	opCtx, _ = diagnostics.Before(ctx, op)
+	info, ok = mongodb.TimeoutDetails(diagnostics.After(opCtx, op, context.DeadlineExceeded))
+	if assert.True(t, ok) {
+		assert.Empty(t, info.Address)
+		assert.True(t, info.WaitingForConnection)
+		assert.Zero(t, info.Execution)
+		assert.Equal(t, mongodb.PoolStats{InUse: 1, Idle: 2, Waiting: 1}, info.Pool, "all pools are reported")
+	}
+
+	// Other errors are not enriched.
+	opCtx, _ = diagnostics.Before(ctx, op)
+	err = diagnostics.After(opCtx, op, mongo.ErrNoDocuments)
+	assert.Equal(t, mongo.ErrNoDocuments, err)
+	_, ok = mongodb.TimeoutDetails(err)
+	assert.False(t, ok)
+}
+
+func TestTimeoutDiagnosticsPoolExhausted(t *testing.T) {
+	ctx := context.Background()
+	col := testCollection(t, "user_timeout_diagnostics")
+	if _, err := col.InsertOne(ctx, bson.M{"name": "Alice"}); err != nil {
+		t.Fatalf("Error on inserting user: %v", err)
+	}
+
+	diagnostics := mongodb.NewTimeoutDiagnostics()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017").SetMaxPoolSize(1).
+		SetMonitor(diagnostics.CommandMonitor()).SetPoolMonitor(diagnostics.PoolMonitor()))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+	repo := mongodb.NewRepository[*User](client.Database(col.Database().Name()).Collection(col.Name()), mongodb.WithHook(diagnostics))
+
+	// The only connection of the pool is held by a slow query.
+	held := make(chan error)
+	go func() {
+		_, err := repo.FindOne(ctx, bson.M{"$where": "sleep(500) || true"})
+		held <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = repo.FindOne(timeoutCtx, bson.M{"name": "Alice"})
+	info, ok := mongodb.TimeoutDetails(err)
+	if assert.True(t, ok, err) {
+		assert.True(t, info.WaitingForConnection)
+		assert.Zero(t, info.Execution)
+		assert.Equal(t, 1, info.Pool.InUse)
+		assert.Equal(t, 0, info.Pool.Idle)
+	}
+	assert.NoError(t, <-held)
+
+	// The slow query itself times out while executing.
+	timeoutCtx, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = repo.FindOne(timeoutCtx, bson.M{"$where": "sleep(500) || true"})
+	info, ok = mongodb.TimeoutDetails(err)
+	if assert.True(t, ok, err) {
+		assert.False(t, info.WaitingForConnection)
+		assert.Equal(t, "localhost:27017", info.Address)
+		assert.Greater(t, info.Execution, time.Duration(0))
+	}
+}
@@ -0,0 +1,73 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+)
+
+type (
+	// ResultTransform is called for every document a read method decodes, see [WithResultTransform].
+	// It can modify the document in place, drop it from the result with keep=false, or abort the read with an error.
+	ResultTransform[T Document[T]] func(ctx context.Context, doc T) (keep bool, err error)
+
+	// resultTransformer is implemented by repositories that transform their results,
+	// so that helpers decoding documents of the repository themselves, like [Subscribe], apply the same transforms.
+	resultTransformer[T Document[T]] interface {
+		transformResult(ctx context.Context, doc T) (bool, error)
+	}
+)
+
+type resultTransformOption struct {
+	fn interface{}
+}
+
+func (value resultTransformOption) apply(o *repositoryOption) {
+	if value.fn == nil {
+		return
+	}
+	o.resultTransforms = append(o.resultTransforms, value.fn)
+}
+
+// WithResultTransform registers a [ResultTransform] that is applied to the decoded documents of FindOne, FindMany, FindManyN,
+// FindManyParallel, FindIter, the Where methods and [Subscribe], e.g. to redact fields depending on the caller's role.
+//
+// A document dropped by FindOne results in mongo.ErrNoDocuments, as if it did not match.
+// Multiple transforms are applied in the order they were registered, and a dropped document is not passed to the later ones.
+// Since T is a pointer type, the transform modifies the document in place.
+//
+// T must be the document type of the repository, otherwise [NewRepository] panics.
+func WithResultTransform[T Document[T]](fn ResultTransform[T]) RepositoryOption {
+	if fn == nil {
+		return resultTransformOption{}
+	}
+	return resultTransformOption{fn: fn}
+}
+
+// resultTransformsFor asserts the registered transforms to the document type of the repository.
+func resultTransformsFor[T Document[T]](transforms []interface{}) []ResultTransform[T] {
+	res := make([]ResultTransform[T], 0, len(transforms))
+	for _, transform := range transforms {
+		fn, ok := transform.(ResultTransform[T])
+		if !ok {
+			panic(fmt.Sprintf("mongodb: result transform %T does not match the document type %v", transform, documentType[T]()))
+		}
+		res = append(res, fn)
+	}
+
+	return res
+}
+
+// transformResult applies the result transforms to doc, and reports whether it is kept.
+func (r *Repository[T]) transformResult(ctx context.Context, doc T) (bool, error) {
+	for _, transform := range r.transforms {
+		keep, err := transform(ctx, doc)
+		if err != nil {
+			return false, fmt.Errorf("%v: %w", "mongodb.ResultTransform", err)
+		}
+		if !keep {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,128 @@
+//go:build go1.23
+
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type roleKey struct{}
+
+func TestResultTransform(t *testing.T) {
+	ctx := context.Background()
+
+	// Hides the email from callers without the admin role, and drops users whose name starts with "internal".
+	redact := func(ctx context.Context, user *User) (bool, error) {
+		if ctx.Value(roleKey{}) != "admin" {
+			user.Email = ""
+		}
+		return true, nil
+	}
+	dropInternal := func(ctx context.Context, user *User) (bool, error) {
+		return !strings.HasPrefix(user.Name, "internal"), nil
+	}
+	var seen []string
+	record := func(ctx context.Context, user *User) (bool, error) {
+		seen = append(seen, user.Name)
+		return true, nil
+	}
+
+	col := testCollection(t, "user_result_transform")
+	repo := mongodb.NewRepository[*User](col, mongodb.WithResultTransform(redact), mongodb.WithResultTransform(dropInternal), mongodb.WithResultTransform(record)).(*mongodb.Repository[*User])
+
+	_, err := repo.InsertMany(ctx, []*User{
+		{Name: "alice", Email: "alice@example.com"},
+		{Name: "internal-bot", Email: "bot@example.com"},
+		{Name: "bob", Email: "bob@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Error inserting users: %v", err)
+	}
+	sortByName := options.Find().SetSort(bson.M{"name": 1})
+
+	users, err := repo.FindMany(ctx, bson.M{}, sortByName)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, userNames(users))
+	assert.Equal(t, []string{"", ""}, userEmails(users))
+
+	var iterated []*User
+	for user, err := range repo.FindIter(ctx, bson.M{}, sortByName) {
+		assert.NoError(t, err)
+		iterated = append(iterated, user)
+	}
+	assert.Equal(t, userNames(users), userNames(iterated))
+	assert.Equal(t, userEmails(users), userEmails(iterated))
+
+	admin, err := repo.FindOne(context.WithValue(ctx, roleKey{}, "admin"), bson.M{"name": "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, "alice@example.com", admin.Email)
+
+	_, err = repo.FindOne(ctx, bson.M{"name": "internal-bot"})
+	assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+
+	// Dropped documents are not passed to later transforms.
+	assert.NotContains(t, seen, "internal-bot")
+}
+
+func TestResultTransformError(t *testing.T) {
+	ctx := context.Background()
+	errDenied := errors.New("denied")
+	deny := func(ctx context.Context, user *User) (bool, error) {
+		return false, errDenied
+	}
+
+	col := testCollection(t, "user_result_transform_error")
+	repo := mongodb.NewRepository[*User](col, mongodb.WithResultTransform(deny)).(*mongodb.Repository[*User])
+	if _, err := repo.InsertOne(ctx, &User{Name: "alice"}); err != nil {
+		t.Fatalf("Error inserting user: %v", err)
+	}
+
+	_, err := repo.FindOne(ctx, bson.M{})
+	assert.ErrorIs(t, err, errDenied)
+
+	users, err := repo.FindMany(ctx, bson.M{})
+	assert.ErrorIs(t, err, errDenied)
+	assert.Nil(t, users)
+
+	calls := 0
+	for _, err := range repo.FindIter(ctx, bson.M{}) {
+		calls++
+		assert.ErrorIs(t, err, errDenied)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestResultTransformTypeMismatch(t *testing.T) {
+	transform := mongodb.WithResultTransform(func(ctx context.Context, account *Account) (bool, error) {
+		return true, nil
+	})
+
+	assert.Panics(t, func() {
+		mongodb.NewRepository[*User](&mongo.Collection{}, transform)
+	})
+}
+
+func userNames(users []*User) []string {
+	names := make([]string, len(users))
+	for i, user := range users {
+		names[i] = user.Name
+	}
+	return names
+}
+
+func userEmails(users []*User) []string {
+	emails := make([]string, len(users))
+	for i, user := range users {
+		emails[i] = user.Email
+	}
+	return emails
+}
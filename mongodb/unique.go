@@ -0,0 +1,143 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrUniqueViolation is returned by write operations when a document violates a unique index
+// that was registered with [WithUniqueViolationMessage].
+//
+// It wraps the original driver error, so mongo.IsDuplicateKeyError still reports true.
+type ErrUniqueViolation struct {
+	// Index is the name of the violated index.
+	Index string
+	// Fields are the indexed fields, if the server reported them.
+	Fields []string
+	// Message is the message registered for the index, suitable to be shown to users.
+	Message string
+
+	err error
+}
+
+func (e *ErrUniqueViolation) Error() string {
+	return e.Message
+}
+
+func (e *ErrUniqueViolation) Unwrap() error {
+	return e.err
+}
+
+type uniqueViolationMessageOption struct {
+	index   string
+	message string
+}
+
+func (value uniqueViolationMessageOption) apply(o *repositoryOption) {
+	if o.uniqueViolations == nil {
+		o.uniqueViolations = map[string]string{}
+	}
+	o.uniqueViolations[value.index] = value.message
+}
+
+// WithUniqueViolationMessage maps duplicate key errors on the given index to an [*ErrUniqueViolation] with the message.
+// It applies to InsertOne, InsertMany, UpdateOne, UpdateMany, ReplaceOne and BulkWrite.
+//
+// Index names default to the fields and directions joined by underscores, see [IndexName].
+func WithUniqueViolationMessage(indexName, message string) RepositoryOption {
+	return uniqueViolationMessageOption{index: indexName, message: message}
+}
+
+// IndexName returns the name MongoDB generates for an index with the given keys, e.g. "companyID_1_email_1".
+func IndexName(keys bson.D) string {
+	parts := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		parts = append(parts, key.Key, fmt.Sprint(key.Value))
+	}
+
+	return strings.Join(parts, "_")
+}
+
+// EnsureUniqueIndex creates a unique index on the given fields, unless it already exists.
+// The index is named as described in [IndexName].
+//
+// With [Partial], the index only applies to the documents matching the filter, e.g. for soft deleted documents,
+// Partial(bson.M{"deletedAt": bson.M{"$exists": false}}) allows deleted duplicates.
+//
+// An existing index on the fields with other options, e.g. another partial filter, results in an error wrapping [ErrIndexConflict].
+func EnsureUniqueIndex(ctx context.Context, r IndexManager, fields []string, opts ...IndexOption) error {
+	if len(fields) == 0 {
+		return errors.New("EnsureUniqueIndex: at least one field is required")
+	}
+
+	ops := newIndexOption(opts)
+	indexOpts := options.Index().SetUnique(true)
+	if ops.partialFilter != nil {
+		indexOpts.SetPartialFilterExpression(ops.partialFilter)
+	}
+
+	return ensureIndex(ctx, "EnsureUniqueIndex", r, mongo.IndexModel{Keys: IndexKeys(fields...), Options: indexOpts})
+}
+
+var duplicateKeyIndexPattern = regexp.MustCompile(`index: (\S+) dup key`)
+
+// mapUniqueViolation converts duplicate key errors on registered indexes into an [*ErrUniqueViolation].
+func (r *Repository[T]) mapUniqueViolation(err error) error {
+	if err == nil || len(r.uniqueViolations) == 0 || !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+
+	for _, we := range duplicateKeyErrors(err) {
+		match := duplicateKeyIndexPattern.FindStringSubmatch(we.Message)
+		if match == nil {
+			continue
+		}
+
+		message, ok := r.uniqueViolations[match[1]]
+		if !ok {
+			continue
+		}
+
+		var fields []string
+		var keyPattern bson.D
+		if raw, lookupErr := we.Raw.LookupErr("keyPattern"); lookupErr == nil && raw.Unmarshal(&keyPattern) == nil {
+			for _, key := range keyPattern {
+				fields = append(fields, key.Key)
+			}
+		}
+
+		return &ErrUniqueViolation{Index: match[1], Fields: fields, Message: message, err: err}
+	}
+
+	return err
+}
+
+func duplicateKeyErrors(err error) []mongo.WriteError {
+	var res []mongo.WriteError
+
+	var we mongo.WriteException
+	if errors.As(err, &we) {
+		res = append(res, we.WriteErrors...)
+	}
+
+	var bwe mongo.BulkWriteException
+	if errors.As(err, &bwe) {
+		for _, e := range bwe.WriteErrors {
+			res = append(res, e.WriteError)
+		}
+	}
+
+	var ce mongo.CommandError
+	if errors.As(err, &ce) {
+		res = append(res, mongo.WriteError{Code: int(ce.Code), Message: ce.Message, Raw: ce.Raw})
+	}
+
+	return res
+}
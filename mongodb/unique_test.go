@@ -0,0 +1,64 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SoftDeletableUser struct {
+	mongodb.BaseModel `bson:",inline"`
+	Email             string `bson:"email"`
+	Deleted           bool   `bson:"deleted"`
+}
+
+func TestUniqueViolationMapping(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_unique"),
+		mongodb.WithUniqueViolationMessage("email_1", "This email address is already registered.")).(*mongodb.Repository[*User])
+
+	assert.NoError(t, mongodb.EnsureUniqueIndex(ctx, repo, []string{"email"}))
+	assert.NoError(t, mongodb.EnsureUniqueIndex(ctx, repo, []string{"email"}))
+
+	_, err := repo.InsertOne(ctx, &User{Name: "Willy", Email: "willy@example.com"})
+	assert.NoError(t, err)
+
+	second, err := repo.InsertOne(ctx, &User{Name: "Other", Email: "other@example.com"})
+	assert.NoError(t, err)
+
+	_, err = repo.InsertOne(ctx, &User{Name: "Willy2", Email: "willy@example.com"})
+	var violation *mongodb.ErrUniqueViolation
+	if assert.True(t, errors.As(err, &violation)) {
+		assert.Equal(t, "email_1", violation.Index)
+		assert.Equal(t, []string{"email"}, violation.Fields)
+		assert.Equal(t, "This email address is already registered.", violation.Message)
+	}
+	assert.True(t, mongo.IsDuplicateKeyError(err))
+
+	second.Email = "willy@example.com"
+	_, err = repo.ReplaceOne(ctx, mongodb.MongoIDFilter(second.MongoID), second)
+	assert.True(t, errors.As(err, &violation))
+}
+
+func TestPartialUniqueIndex(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*SoftDeletableUser](testCollection(t, "user_partial_unique")).(*mongodb.Repository[*SoftDeletableUser])
+
+	err := mongodb.EnsureUniqueIndex(ctx, repo, []string{"email"}, mongodb.Partial(bson.M{"deleted": false}))
+	assert.NoError(t, err)
+
+	_, err = repo.InsertMany(ctx, []*SoftDeletableUser{
+		{Email: "willy@example.com", Deleted: true},
+		{Email: "willy@example.com", Deleted: true},
+		{Email: "willy@example.com"},
+	})
+	assert.NoError(t, err)
+
+	_, err = repo.InsertOne(ctx, &SoftDeletableUser{Email: "willy@example.com"})
+	assert.True(t, mongo.IsDuplicateKeyError(err))
+}
@@ -0,0 +1,67 @@
+package mongodb
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	// UpdateOption is an update building block that can be combined into a full update document for a mongodb query.
+	//
+	// See [NewUpdate]
+	UpdateOption interface {
+		// Update returns the update operators of the option, e.g. primitive.M{"$set": primitive.M{"name": "Willy"}}.
+		Update() primitive.M
+	}
+)
+
+// NewUpdate builds a new MongoDB update document from the update operators of the UpdateOptions passed.
+//
+// It can be used like this to combine update fragments from several sources:
+//
+//	update, err := NewUpdate(WithSet(primitive.M{"name": name}), WithUnset("draft"), WithInc("version", 1))
+//
+// The operators are merged with [MergeUpdate]: the fields of the same operator are combined, while updating the same field
+// to different values, or with different operators, returns an error wrapping [ErrMergeConflict].
+func NewUpdate(opts ...UpdateOption) (primitive.M, error) {
+	u := primitive.M{}
+
+	for _, opt := range opts {
+		if err := mergeUpdate(u, opt.Update()); err != nil {
+			return nil, fmt.Errorf("%v: %w", "mongodb.NewUpdate", err)
+		}
+	}
+
+	return u, nil
+}
+
+type withUpdate primitive.M
+
+func (w withUpdate) Update() primitive.M {
+	return primitive.M(w)
+}
+
+// WithUpdate creates a new [UpdateOption] from an update document with any update operators.
+func WithUpdate(update primitive.M) UpdateOption {
+	return withUpdate(update)
+}
+
+// WithSet creates a new [UpdateOption] that sets the given fields with $set.
+func WithSet(fields primitive.M) UpdateOption {
+	return withUpdate{"$set": fields}
+}
+
+// WithUnset creates a new [UpdateOption] that removes the given fields with $unset.
+func WithUnset(fields ...string) UpdateOption {
+	unset := primitive.M{}
+	for _, field := range fields {
+		unset[field] = ""
+	}
+	return withUpdate{"$unset": unset}
+}
+
+// WithInc creates a new [UpdateOption] that increments the field by n with $inc.
+func WithInc(field string, n interface{}) UpdateOption {
+	return withUpdate{"$inc": primitive.M{field: n}}
+}
@@ -0,0 +1,147 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// versionField is the field a document can store a version number in, which [UpdateWithRetry] increments on every write.
+	versionField               = "version"
+	defaultUpdateRetryAttempts = 5
+)
+
+// ErrVersionConflict is returned by [UpdateWithRetry] when the document was modified concurrently on every attempt.
+var ErrVersionConflict = errors.New("mongodb: document was modified concurrently")
+
+// UpdateWithRetry reads the document matching filter, applies mutate to it and replaces it, but only if it was not modified
+// in between. If it was, it is read again and mutate is applied to the fresh document, up to maxAttempts times.
+// A maxAttempts <= 0 uses 5 attempts. The replaced document is returned.
+//
+//	user, err := mongodb.UpdateWithRetry(ctx, users, mongodb.MongoIDFilter(id), func(user *User) (*User, error) {
+//		user.LoginCount++
+//		return user, nil
+//	}, 0)
+//
+// If T has an integer field stored as "version", the document is replaced only if its version is unchanged,
+// and the version is incremented. Otherwise, the updatedAt date of [BaseModel] is compared, and T must provide it
+// with a GetUpdatedAt method. Since updatedAt is stored with millisecond precision, the version field is safer
+// if the document is written by several processes with clocks that differ.
+//
+// mutate must not have side effects, since it can be called more than once. If no document matches filter,
+// an error wrapping [ErrNotFound] and mongo.ErrNoDocuments is returned, also if the document is deleted between two attempts.
+// If all attempts conflict, an error wrapping [ErrVersionConflict] is returned. Errors of mutate are returned unchanged.
+func UpdateWithRetry[T Document[T]](ctx context.Context, r RepositoryI[T], filter bson.M, mutate func(T) (T, error), maxAttempts int) (T, error) {
+	var zero T
+	if maxAttempts <= 0 {
+		maxAttempts = defaultUpdateRetryAttempts
+	}
+
+	guard, err := newVersionGuard[T]()
+	if err != nil {
+		return zero, fmt.Errorf("%v: %w", "mongodb.UpdateWithRetry", err)
+	}
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		doc, err := r.FindOne(ctx, filter)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return zero, fmt.Errorf("%v: %w: %w", "mongodb.UpdateWithRetry", ErrNotFound, err)
+		}
+		if err != nil {
+			return zero, fmt.Errorf("%v: %w", "mongodb.UpdateWithRetry", err)
+		}
+
+		// The previous state is read before mutate, which may modify doc in place.
+		previous, err := guard.filter(doc)
+		if err != nil {
+			return zero, fmt.Errorf("%v: %w", "mongodb.UpdateWithRetry", err)
+		}
+
+		mutated, err := mutate(doc)
+		if err != nil {
+			return zero, err
+		}
+		guard.prepare(mutated)
+
+		res, err := r.ReplaceOne(ctx, previous, mutated)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return zero, fmt.Errorf("%v: %w", "mongodb.UpdateWithRetry", err)
+		}
+		return res, nil
+	}
+
+	return zero, fmt.Errorf("%v: %w after %d attempts", "mongodb.UpdateWithRetry", ErrVersionConflict, maxAttempts)
+}
+
+// versionGuard builds the filter that matches a document only in the state it was read in.
+type versionGuard[T any] struct {
+	// version are the Go field names leading to the version field, nil if T has none.
+	version []string
+}
+
+func newVersionGuard[T any]() (versionGuard[T], error) {
+	field, ok, err := lookupField(documentType[T](), versionField)
+	if err != nil {
+		return versionGuard[T]{}, err
+	}
+	if ok && isIntegerKind(field.Field.Type.Kind()) {
+		return versionGuard[T]{version: field.GoPath}, nil
+	}
+
+	var zero T
+	if _, ok := interface{}(zero).(interface{ GetUpdatedAt() time.Time }); !ok {
+		return versionGuard[T]{}, fmt.Errorf("%v has neither a version field nor a GetUpdatedAt method", documentType[T]())
+	}
+	return versionGuard[T]{}, nil
+}
+
+func (g versionGuard[T]) filter(doc T) (bson.M, error) {
+	id, ok := interface{}(doc).(interface{ GetMongoID() primitive.ObjectID })
+	if !ok {
+		return nil, fmt.Errorf("%v has no GetMongoID method", documentType[T]())
+	}
+
+	if g.version != nil {
+		value, _ := goFieldValue(reflect.ValueOf(doc), g.version)
+		if value.IsZero() {
+			// Documents written before the version field was added have none.
+			return bson.M{"_id": id.GetMongoID(), versionField: bson.M{"$in": bson.A{0, nil}}}, nil
+		}
+		return bson.M{"_id": id.GetMongoID(), versionField: value.Interface()}, nil
+	}
+
+	updatedAt := interface{}(doc).(interface{ GetUpdatedAt() time.Time }).GetUpdatedAt()
+	// Wait until the new updatedAt differs from the one that is compared, even at millisecond precision.
+	if wait := updatedAt.Truncate(time.Millisecond).Add(time.Millisecond).Sub(now()); wait > 0 {
+		time.Sleep(wait)
+	}
+	return bson.M{"_id": id.GetMongoID(), "updatedAt": updatedAt}, nil
+}
+
+// prepare increments the version of the mutated document.
+func (g versionGuard[T]) prepare(doc T) {
+	if g.version == nil {
+		return
+	}
+	if value, ok := goFieldValue(reflect.ValueOf(doc), g.version); ok && value.CanSet() {
+		value.SetInt(value.Int() + 1)
+	}
+}
+
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
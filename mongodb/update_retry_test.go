@@ -0,0 +1,116 @@
+package mongodb_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type (
+	VersionedCounter struct {
+		mongodb.BaseModel `bson:",inline"`
+		Count             int `bson:"count"`
+		Version           int `bson:"version"`
+	}
+
+	TimestampedCounter struct {
+		mongodb.BaseModel `bson:",inline"`
+		Count             int `bson:"count"`
+	}
+)
+
+func TestUpdateWithRetryVersion(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*VersionedCounter](testCollection(t, "update_retry_version"))
+
+	counter, err := repo.InsertOne(ctx, &VersionedCounter{})
+	if err != nil {
+		t.Fatalf("Error on inserting counter: %v", err)
+	}
+
+	const mutators = 10
+	var wg sync.WaitGroup
+	for i := 0; i < mutators; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := mongodb.UpdateWithRetry(ctx, repo, mongodb.MongoIDFilter(counter.MongoID), func(c *VersionedCounter) (*VersionedCounter, error) {
+				c.Count++
+				return c, nil
+			}, 100)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	res, err := repo.FindOne(ctx, mongodb.MongoIDFilter(counter.MongoID))
+	assert.NoError(t, err)
+	assert.Equal(t, mutators, res.Count)
+	assert.Equal(t, mutators, res.Version)
+}
+
+func TestUpdateWithRetryUpdatedAt(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*TimestampedCounter](testCollection(t, "update_retry_updated_at"))
+
+	counter, err := repo.InsertOne(ctx, &TimestampedCounter{})
+	if err != nil {
+		t.Fatalf("Error on inserting counter: %v", err)
+	}
+
+	const mutators = 10
+	var wg sync.WaitGroup
+	for i := 0; i < mutators; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := mongodb.UpdateWithRetry(ctx, repo, mongodb.MongoIDFilter(counter.MongoID), func(c *TimestampedCounter) (*TimestampedCounter, error) {
+				c.Count++
+				return c, nil
+			}, 100)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	res, err := repo.FindOne(ctx, mongodb.MongoIDFilter(counter.MongoID))
+	assert.NoError(t, err)
+	assert.Equal(t, mutators, res.Count)
+}
+
+func TestUpdateWithRetryErrors(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*VersionedCounter](testCollection(t, "update_retry_errors"))
+
+	_, err := mongodb.UpdateWithRetry(ctx, repo, bson.M{"count": 42}, func(c *VersionedCounter) (*VersionedCounter, error) {
+		return c, nil
+	}, 0)
+	assert.ErrorIs(t, err, mongodb.ErrNotFound)
+	assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+
+	counter, err := repo.InsertOne(ctx, &VersionedCounter{})
+	if err != nil {
+		t.Fatalf("Error on inserting counter: %v", err)
+	}
+
+	// A competing write on every attempt exhausts the attempts.
+	_, err = mongodb.UpdateWithRetry(ctx, repo, mongodb.MongoIDFilter(counter.MongoID), func(c *VersionedCounter) (*VersionedCounter, error) {
+		if _, err := repo.UpdateOne(ctx, mongodb.MongoIDFilter(counter.MongoID), bson.M{"version": c.Version + 1}); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}, 3)
+	assert.ErrorIs(t, err, mongodb.ErrVersionConflict)
+
+	errMutate := errors.New("mutate")
+	_, err = mongodb.UpdateWithRetry(ctx, repo, mongodb.MongoIDFilter(counter.MongoID), func(c *VersionedCounter) (*VersionedCounter, error) {
+		return nil, errMutate
+	}, 0)
+	assert.ErrorIs(t, err, errMutate)
+}
@@ -0,0 +1,44 @@
+package mongodb_test
+
+import (
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestNewUpdate(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		opts     []mongodb.UpdateOption
+		want     bson.M
+		conflict bool
+	}{
+		{"empty", nil, bson.M{}, false},
+		{"operators", []mongodb.UpdateOption{mongodb.WithSet(bson.M{"name": "Willy"}), mongodb.WithUnset("draft"), mongodb.WithInc("version", 1)},
+			bson.M{"$set": bson.M{"name": "Willy"}, "$unset": bson.M{"draft": ""}, "$inc": bson.M{"version": 1}}, false},
+		{"same operator", []mongodb.UpdateOption{mongodb.WithSet(bson.M{"name": "Willy"}), mongodb.WithSet(bson.M{"email": "willy@example.com"})},
+			bson.M{"$set": bson.M{"name": "Willy", "email": "willy@example.com"}}, false},
+		{"same value", []mongodb.UpdateOption{mongodb.WithSet(bson.M{"name": "Willy"}), mongodb.WithUpdate(bson.M{"$set": bson.M{"name": "Willy"}})},
+			bson.M{"$set": bson.M{"name": "Willy"}}, false},
+		{"different values", []mongodb.UpdateOption{mongodb.WithSet(bson.M{"name": "Willy"}), mongodb.WithSet(bson.M{"name": "Bob"})}, nil, true},
+		{"different operators", []mongodb.UpdateOption{mongodb.WithSet(bson.M{"draft": true}), mongodb.WithUnset("draft")}, nil, true},
+		{"nested path", []mongodb.UpdateOption{mongodb.WithSet(bson.M{"settings": bson.M{}}), mongodb.WithInc("settings.count", 1)}, nil, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mongodb.NewUpdate(tt.opts...)
+			if tt.conflict {
+				assert.ErrorIs(t, err, mongodb.ErrMergeConflict)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewUpdateInvalidOperator(t *testing.T) {
+	_, err := mongodb.NewUpdate(mongodb.WithUpdate(bson.M{"name": "Willy"}))
+	assert.ErrorContains(t, err, "name is not an update operator")
+}
@@ -0,0 +1,126 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	UpsertManyByKey[T Document[T]] interface {
+		// Inserts the documents whose key fields match no document, and updates the others.
+		UpsertManyByKey(ctx context.Context, docs []T, keyFields []string, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error)
+	}
+)
+
+// Inserts the documents whose key fields match no stored document, and updates the matching ones, in a single BulkWrite.
+// keyFields are bson paths, e.g. "externalID" or "source.id", and every document must have a non-zero value for each of them.
+//
+// Every document is written as an upserting update that sets all its fields, with _id and createdAt only set on insert,
+// so updated documents keep their stored _id and createdAt. updatedAt is set to the current time for all documents.
+// In the result, UpsertedCount is the number of inserted and MatchedCount the number of updated documents.
+// Documents that had no _id and were updated get their MongoID reset, since the generated one was not stored.
+func (r *Repository[T]) UpsertManyByKey(ctx context.Context, docs []T, keyFields []string, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	filters, err := keyFilters(docs, keyFields)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "mongodb.Repository.UpsertManyByKey", err)
+	}
+
+	hadID := make([]bool, len(docs))
+	models := make([]mongo.WriteModel, len(docs))
+	for i, doc := range docs {
+		_, err := documentID(r.Registry(), doc)
+		hadID[i] = err == nil
+		doc.InitDocument()
+
+		set, err := withoutID(r.Registry(), doc)
+		if err != nil {
+			return nil, fmt.Errorf("%v: document %d: %w", "mongodb.Repository.UpsertManyByKey", i, err)
+		}
+		var createdAt interface{}
+		fields := set[:0]
+		for _, e := range set {
+			if e.Key == "createdAt" {
+				createdAt = e.Value
+				continue
+			}
+			fields = append(fields, e)
+		}
+		id, err := documentID(r.Registry(), doc)
+		if err != nil {
+			return nil, fmt.Errorf("%v: document %d: %w", "mongodb.Repository.UpsertManyByKey", i, err)
+		}
+
+		models[i] = mongo.NewUpdateOneModel().
+			SetFilter(filters[i]).
+			SetUpdate(bson.M{"$set": fields, "$setOnInsert": bson.M{"_id": id, "createdAt": createdAt}}).
+			SetUpsert(true)
+	}
+
+	res, err := r.BulkWrite(ctx, models, opts...)
+	if err != nil {
+		return res, fmt.Errorf("%v: %w", "mongodb.Repository.UpsertManyByKey", err)
+	}
+
+	for i, doc := range docs {
+		if _, inserted := res.UpsertedIDs[int64(i)]; !inserted && !hadID[i] {
+			doc.ResetMongoID()
+		}
+	}
+
+	return res, nil
+}
+
+// keyFilters returns a filter for every document that matches the values of its keyFields, see [Repository.UpsertManyByKey].
+func keyFilters[T Document[T]](docs []T, keyFields []string) ([]bson.M, error) {
+	if len(keyFields) == 0 {
+		return nil, errors.New("no key fields")
+	}
+
+	keys := make([]structField, len(keyFields))
+	for i, path := range keyFields {
+		field, ok, err := lookupField(documentType[T](), path)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%v has no field %q", documentType[T](), path)
+		}
+		keys[i] = field
+	}
+
+	filters := make([]bson.M, len(docs))
+	for i, doc := range docs {
+		filter := bson.M{}
+		for _, key := range keys {
+			value, ok := goFieldValue(reflect.ValueOf(doc), key.GoPath)
+			if !ok || value.IsZero() {
+				return nil, fmt.Errorf("document %d has no value for key field %q", i, key.Path)
+			}
+			filter[key.Path] = value.Interface()
+		}
+		filters[i] = filter
+	}
+
+	return filters, nil
+}
+
+// goFieldValue follows the Go field names of path from v, dereferencing pointers.
+// It reports false if a pointer on the way is nil.
+func goFieldValue(v reflect.Value, path []string) (reflect.Value, bool) {
+	for _, name := range path {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+		}
+		v = v.FieldByName(name)
+	}
+	return v, v.IsValid()
+}
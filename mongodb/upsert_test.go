@@ -0,0 +1,68 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestUpsertManyByKey(t *testing.T) {
+	ctx := context.Background()
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_upsert_key")).(*mongodb.Repository[*User])
+
+	res, err := repo.UpsertManyByKey(ctx, []*User{
+		{Name: "alice", Email: "alice@example.com"},
+		{Name: "bob", Email: "bob@example.com"},
+	}, []string{"name"})
+	if err != nil {
+		t.Fatalf("Error on first upsert: %v", err)
+	}
+	assert.Equal(t, int64(2), res.UpsertedCount)
+	assert.Equal(t, int64(0), res.MatchedCount)
+
+	before, err := repo.FindOne(ctx, bson.M{"name": "alice"})
+	if err != nil {
+		t.Fatalf("Error on finding alice: %v", err)
+	}
+
+	docs := []*User{
+		{Name: "alice", Email: "alice@example.org"},
+		{Name: "carol", Email: "carol@example.com"},
+	}
+	res, err = repo.UpsertManyByKey(ctx, docs, []string{"name"})
+	if err != nil {
+		t.Fatalf("Error on second upsert: %v", err)
+	}
+	assert.Equal(t, int64(1), res.UpsertedCount)
+	assert.Equal(t, int64(1), res.MatchedCount)
+	assert.True(t, docs[0].GetMongoID().IsZero())
+	assert.False(t, docs[1].GetMongoID().IsZero())
+
+	count, err := repo.CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	alice, err := repo.FindOne(ctx, bson.M{"name": "alice"})
+	if err != nil {
+		t.Fatalf("Error on finding alice: %v", err)
+	}
+	assert.Equal(t, "alice@example.org", alice.Email)
+	assert.Equal(t, before.GetMongoID(), alice.GetMongoID())
+	assert.True(t, before.GetCreatedAt().Equal(alice.GetCreatedAt()))
+}
+
+func TestUpsertManyByKeyRejectsZeroKey(t *testing.T) {
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_upsert_key_zero")).(*mongodb.Repository[*User])
+
+	_, err := repo.UpsertManyByKey(context.Background(), []*User{
+		{Name: "alice"},
+		{Email: "nameless@example.com"},
+	}, []string{"name"})
+	assert.ErrorContains(t, err, `document 1 has no value for key field "name"`)
+
+	_, err = repo.UpsertManyByKey(context.Background(), []*User{{Name: "alice"}}, []string{"nickname"})
+	assert.ErrorContains(t, err, `has no field "nickname"`)
+}
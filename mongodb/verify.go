@@ -0,0 +1,124 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	// ErrCollectionNotFound is reported by [Repository.Verify] when the collection of the repository does not exist.
+	ErrCollectionNotFound = errors.New("mongodb: collection does not exist")
+	// ErrIndexMissing is reported by [Repository.Verify] for a declared index that does not exist or differs.
+	ErrIndexMissing = errors.New("mongodb: declared index missing")
+)
+
+type (
+	Verifier interface {
+		// Checks that the repository can work with its collection, e.g. at startup or in a readiness check.
+		Verify(ctx context.Context) error
+	}
+
+	// VerifyError lists all problems [Repository.Verify] found for a collection.
+	//
+	// errors.Is matches the error against every problem, e.g. [ErrCollectionNotFound].
+	VerifyError struct {
+		Collection string
+		Problems   []error
+	}
+)
+
+func (e *VerifyError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		msgs[i] = problem.Error()
+	}
+	return fmt.Sprintf("mongodb: verifying %v: %v", e.Collection, strings.Join(msgs, "; "))
+}
+
+func (e *VerifyError) Is(target error) bool {
+	for _, problem := range e.Problems {
+		if errors.Is(problem, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *VerifyError) As(target interface{}) bool {
+	for _, problem := range e.Problems {
+		if errors.As(problem, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks that the repository can work with its collection, and returns a [*VerifyError] listing every problem:
+//
+//   - the collection must exist, which catches misspelled collection names
+//   - the indexes declared for T must exist with the declared options, see [IndexModels] and [DiffIndexes]
+//   - a sample document, if there is any, must decode into T with the rules of [WithStrictDecoding]
+//
+// Errors of the checks themselves, e.g. a lost connection, are returned as they are.
+func (r *Repository[T]) Verify(ctx context.Context) (err error) {
+	ctx, finish, err := r.begin(ctx, "Verify", nil)
+	if err != nil {
+		return err
+	}
+	defer func() { err = finish(err) }()
+
+	verr := &VerifyError{Collection: r.db.Name()}
+
+	col := r.CollectionFor(ctx)
+	names, err := col.Database().ListCollectionNames(ctx, bson.M{"name": col.Name()})
+	if err != nil {
+		return fmt.Errorf("%v: %w", "mongodb.Repository.Verify", err)
+	}
+	if len(names) == 0 {
+		verr.Problems = append(verr.Problems, ErrCollectionNotFound)
+		return verr
+	}
+
+	declared, err := IndexModels[T]()
+	if err != nil {
+		verr.Problems = append(verr.Problems, err)
+	} else if len(declared) > 0 {
+		diff, err := DiffIndexes[T](ctx, r, declared)
+		if err != nil {
+			return fmt.Errorf("%v: %w", "mongodb.Repository.Verify", err)
+		}
+		for _, model := range diff.Missing {
+			keys, _ := indexKeysOf(model)
+			verr.Problems = append(verr.Problems, fmt.Errorf("%w: %v", ErrIndexMissing, declaredIndexName(model, keys)))
+		}
+		for _, mismatch := range diff.Mismatched {
+			verr.Problems = append(verr.Problems, fmt.Errorf("%w: %v differs: %v", ErrIndexMissing, mismatch.Actual.Name, strings.Join(mismatch.Differences, ", ")))
+		}
+	}
+
+	raw, err := col.FindOne(ctx, bson.M{}).Raw()
+	switch {
+	case err == nil:
+		decoder, derr := newStrictDecoder(documentType[T](), r.Registry(), nil)
+		if derr != nil {
+			verr.Problems = append(verr.Problems, derr)
+			break
+		}
+		var doc T
+		if derr := decoder.decode(r.db.Name(), raw, &doc); derr != nil {
+			verr.Problems = append(verr.Problems, derr)
+		}
+	case !errors.Is(err, mongo.ErrNoDocuments):
+		return fmt.Errorf("%v: %w", "mongodb.Repository.Verify", err)
+	}
+
+	if len(verr.Problems) > 0 {
+		return verr
+	}
+	return nil
+}
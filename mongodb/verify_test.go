@@ -0,0 +1,58 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type VerifiedUser struct {
+	mongodb.BaseModel `bson:",inline"`
+	Email             string `bson:"email" mongoIndex:"unique"`
+}
+
+func TestVerifyMissingCollection(t *testing.T) {
+	repo := mongodb.NewRepository[*User](testCollection(t, "user_verify_typo")).(*mongodb.Repository[*User])
+
+	err := repo.Verify(context.Background())
+	assert.ErrorIs(t, err, mongodb.ErrCollectionNotFound)
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	col := testCollection(t, "user_verify")
+	repo := mongodb.NewRepository[*VerifiedUser](col).(*mongodb.Repository[*VerifiedUser])
+
+	if _, err := repo.InsertOne(ctx, &VerifiedUser{Email: "alice@example.com"}); err != nil {
+		t.Fatalf("Error on inserting: %v", err)
+	}
+
+	// The unique index on email was not created.
+	err := repo.Verify(ctx)
+	assert.ErrorIs(t, err, mongodb.ErrIndexMissing)
+	assert.ErrorContains(t, err, "email_1")
+
+	if _, err := mongodb.EnsureIndexes[*VerifiedUser](ctx, repo); err != nil {
+		t.Fatalf("Error on creating indexes: %v", err)
+	}
+	assert.NoError(t, repo.Verify(ctx))
+
+	// A document with a field VerifiedUser does not have.
+	if _, err := col.InsertOne(ctx, bson.M{"email": "bob@example.com", "emial": "typo"}); err != nil {
+		t.Fatalf("Error on inserting: %v", err)
+	}
+	if _, err := col.DeleteOne(ctx, bson.M{"email": "alice@example.com"}); err != nil {
+		t.Fatalf("Error on deleting: %v", err)
+	}
+
+	err = repo.Verify(ctx)
+	assert.ErrorIs(t, err, mongodb.ErrUnknownField)
+	var decodeErr *mongodb.DecodeError
+	if assert.ErrorAs(t, err, &decodeErr) {
+		assert.Equal(t, "emial", decodeErr.Field)
+	}
+	assert.NotErrorIs(t, err, mongodb.ErrIndexMissing)
+}
@@ -0,0 +1,197 @@
+package mongodb
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// normalizeValue returns a copy of value in which all documents are primitive.M and all arrays are bson.A, at any depth,
+// so that filters can be checked without knowing the Go types they are built from.
+//
+// Documents are bson.D, bson.E, bson.Raw, documents in a bson.RawValue, maps with string keys and values implementing bson.Marshaler.
+// Arrays are slices and arrays of any element type apart from []byte. Scalar BSON values are returned unchanged.
+// Any other value, e.g. a struct, results in [ErrUnsafeFilter], since the keys it marshals to can not be checked.
+// So does a document with duplicate keys, since only one of them would be checked.
+func normalizeValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil, bool, string, []byte, time.Time, primitive.ObjectID, primitive.DateTime, primitive.Decimal128, primitive.Regex,
+		primitive.Binary, primitive.Timestamp, primitive.Null, primitive.Undefined, primitive.MinKey, primitive.MaxKey,
+		primitive.Symbol, primitive.DBPointer, primitive.JavaScript, EncryptedFilter:
+		return value, nil
+	case primitive.M:
+		return normalizeMap(reflect.ValueOf(v))
+	case primitive.D:
+		return normalizeD(v)
+	case []primitive.E:
+		return normalizeD(v)
+	case primitive.E:
+		return normalizeD(primitive.D{v})
+	case bson.Raw:
+		return normalizeRaw(v)
+	case bson.RawValue:
+		return normalizeRawValue(v)
+	case bson.Marshaler:
+		data, err := v.MarshalBSON()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsafeFilter, err)
+		}
+		return normalizeRaw(data)
+	case bsoncodec.ValueMarshaler:
+		t, data, err := v.MarshalBSONValue()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsafeFilter, err)
+		}
+		return normalizeRawValue(bson.RawValue{Type: t, Value: data})
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Bool, reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return value, nil
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return normalizeValue(rv.Elem().Interface())
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		return normalizeMap(rv)
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, nil
+		}
+		res := make(bson.A, rv.Len())
+		for i := range res {
+			elem, err := normalizeValue(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			res[i] = elem
+		}
+		return res, nil
+	}
+
+	return nil, fmt.Errorf("%w: values of type %T can not be checked", ErrUnsafeFilter, value)
+}
+
+func normalizeMap(rv reflect.Value) (primitive.M, error) {
+	res := make(primitive.M, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		elem, err := normalizeValue(iter.Value().Interface())
+		if err != nil {
+			return nil, err
+		}
+		res[iter.Key().String()] = elem
+	}
+	return res, nil
+}
+
+func normalizeD(d primitive.D) (primitive.M, error) {
+	res := make(primitive.M, len(d))
+	for _, e := range d {
+		if _, ok := res[e.Key]; ok {
+			return nil, fmt.Errorf("%w: duplicate key %v", ErrUnsafeFilter, e.Key)
+		}
+		elem, err := normalizeValue(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		res[e.Key] = elem
+	}
+	return res, nil
+}
+
+func normalizeRaw(raw bson.Raw) (primitive.M, error) {
+	elements, err := raw.Elements()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsafeFilter, err)
+	}
+	res := make(primitive.M, len(elements))
+	for _, e := range elements {
+		if _, ok := res[e.Key()]; ok {
+			return nil, fmt.Errorf("%w: duplicate key %v", ErrUnsafeFilter, e.Key())
+		}
+		elem, err := normalizeRawValue(e.Value())
+		if err != nil {
+			return nil, err
+		}
+		res[e.Key()] = elem
+	}
+	return res, nil
+}
+
+func normalizeRawValue(rv bson.RawValue) (interface{}, error) {
+	switch rv.Type {
+	case bsontype.EmbeddedDocument:
+		return normalizeRaw(rv.Value)
+	case bsontype.Array:
+		values, err := bson.Raw(rv.Value).Values()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsafeFilter, err)
+		}
+		res := make(bson.A, len(values))
+		for i, elem := range values {
+			if res[i], err = normalizeRawValue(elem); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	case bsontype.CodeWithScope:
+		return nil, fmt.Errorf("%w: values of type %v can not be checked", ErrUnsafeFilter, rv.Type)
+	}
+
+	var res interface{}
+	if err := rv.Unmarshal(&res); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsafeFilter, err)
+	}
+	return res, nil
+}
+
+// findOperator returns the first key at any depth of value, in sorted order, for which match returns true.
+// Values that [normalizeValue] can not check result in an error.
+func findOperator(value interface{}, match func(string) bool) (string, error) {
+	normalized, err := normalizeValue(value)
+	if err != nil {
+		return "", err
+	}
+	op, _ := findKey(normalized, match)
+	return op, nil
+}
+
+// findKey returns the first key at any depth of a normalized value for which match returns true.
+func findKey(value interface{}, match func(string) bool) (string, bool) {
+	switch v := value.(type) {
+	case primitive.M:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if match(key) {
+				return key, true
+			}
+			if op, ok := findKey(v[key], match); ok {
+				return op, true
+			}
+		}
+	case bson.A:
+		for _, elem := range v {
+			if op, ok := findKey(elem, match); ok {
+				return op, true
+			}
+		}
+	}
+	return "", false
+}
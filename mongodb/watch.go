@@ -0,0 +1,34 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	Watcher interface {
+		// Opens a change stream on the collection.
+		//
+		// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.Watch]
+		Watch(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error)
+	}
+)
+
+// Opens a change stream on the collection, see [Subscribe] for typed change events.
+//
+// See [https://pkg.go.dev/go.mongodb.org/mongo-driver/mongo#Collection.Watch]
+func (r *Repository[T]) Watch(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.ChangeStreamOptions) (_ *mongo.ChangeStream, err error) {
+	ctx, finish, err := r.begin(ctx, "Watch", pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = finish(err) }()
+
+	if pipeline == nil {
+		pipeline = mongo.Pipeline{}
+	}
+
+	return r.CollectionFor(ctx).Watch(ctx, pipeline, opts...)
+}
@@ -0,0 +1,11 @@
+//go:build go1.21
+
+package mongodb
+
+import "context"
+
+// withoutCancel returns a context that keeps the values of ctx, but is not canceled when ctx is.
+// context.WithoutCancel needs Go 1.21, older versions build the implementation of without_cancel_legacy.go instead.
+func withoutCancel(ctx context.Context) context.Context {
+	return context.WithoutCancel(ctx)
+}
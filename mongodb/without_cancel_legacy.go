@@ -0,0 +1,34 @@
+//go:build !go1.21
+
+package mongodb
+
+import (
+	"context"
+	"time"
+)
+
+// detachedContext keeps the values of its parent, but neither its deadline nor its cancellation.
+type detachedContext struct {
+	parent context.Context
+}
+
+// withoutCancel returns a context that keeps the values of ctx, but is not canceled when ctx is.
+func withoutCancel(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
@@ -0,0 +1,474 @@
+package mongotest
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// ChaosRule injects a failure or a delay into the calls of a [ChaosRepository], see [ChaosRepository.AddRule].
+	ChaosRule struct {
+		// Operations are the names of the repository methods the rule applies to, e.g. "FindMany". Without any, it applies to all methods.
+		Operations []string
+		// Match restricts the rule to the calls whose filter it returns true for. It is called with nil for methods without a filter.
+		// Filters of the Where methods are passed without their key order.
+		Match func(filter bson.M) bool
+		// Probability is the chance that the rule fires for a matching call, e.g. 0.1 for 10% of the calls.
+		// A Probability <= 0 or >= 1 fires on every matching call.
+		Probability float64
+		// Delay delays the call before it runs, or until its context is done.
+		Delay time.Duration
+		// Err is returned instead of running the call. Without one, the call runs after the delay.
+		Err error
+	}
+
+	// ChaosRepository is a repository that injects failures and delays into the calls of an inner repository,
+	// e.g. to test retries and timeouts without a misbehaving cluster. Calls that no rule fires for are passed through.
+	//
+	// Whether a rule fires is decided by a random generator per rule, seeded from the seed of [NewChaosRepository]
+	// and the id of the rule, so that the same calls in the same order fire the same rules in every run.
+	// Rules can be added and removed while the repository is used, it is safe for concurrent use.
+	ChaosRepository[T mongodb.Document[T]] struct {
+		inner mongodb.Forwarder[T]
+		chaos *chaos
+	}
+
+	// chaos holds the rules of a ChaosRepository, which are shared with the repositories returned by WithSession.
+	chaos struct {
+		seed uint64
+
+		mu     sync.Mutex
+		nextID int
+		rules  []*chaosRule
+	}
+
+	chaosRule struct {
+		ChaosRule
+		id    int
+		rand  *rand.Rand
+		fired int
+	}
+)
+
+// Compile-time check that ChaosRepository can replace the repository it wraps.
+var _ mongodb.RepositoryI[*mongodb.BaseModel] = (*ChaosRepository[*mongodb.BaseModel])(nil)
+
+// NewChaosRepository creates a ChaosRepository for inner, whose rules fire deterministically for the given seed.
+func NewChaosRepository[T mongodb.Document[T]](inner mongodb.RepositoryI[T], seed uint64) *ChaosRepository[T] {
+	return &ChaosRepository[T]{inner: mongodb.Forwarder[T]{RepositoryI: inner}, chaos: &chaos{seed: seed}}
+}
+
+// NetworkError returns an error that the driver reports as a network error with [mongo.IsNetworkError],
+// and that is labeled as retryable, like a dropped connection.
+func NetworkError() error {
+	return mongo.CommandError{
+		Message: "mongotest: injected network error",
+		Labels:  []string{"NetworkError", "RetryableWriteError"},
+	}
+}
+
+// MatchID returns a [ChaosRule.Match] predicate for the calls whose filter selects the document with the given _id,
+// e.g. with [mongodb.MongoIDFilter] or GetByID.
+func MatchID(id interface{}) func(filter bson.M) bool {
+	return func(filter bson.M) bool {
+		value, ok := filter["_id"]
+		return ok && value == id
+	}
+}
+
+// AddRule adds a rule after the existing ones and returns its id, see [ChaosRepository.RemoveRule].
+//
+// For every call, the matching rules are checked in the order they were added. The delays of all firing rules are added up,
+// and the error of the first firing rule with an error is returned instead of running the call.
+func (r *ChaosRepository[T]) AddRule(rule ChaosRule) int {
+	r.chaos.mu.Lock()
+	defer r.chaos.mu.Unlock()
+
+	r.chaos.nextID++
+	id := r.chaos.nextID
+	r.chaos.rules = append(r.chaos.rules, &chaosRule{
+		ChaosRule: rule,
+		id:        id,
+		rand:      rand.New(rand.NewSource(int64(r.chaos.seed ^ uint64(id)<<32))),
+	})
+	return id
+}
+
+// RemoveRule removes the rule with the given id. Calls that already fired it are not affected.
+func (r *ChaosRepository[T]) RemoveRule(id int) {
+	r.chaos.mu.Lock()
+	defer r.chaos.mu.Unlock()
+
+	for i, rule := range r.chaos.rules {
+		if rule.id == id {
+			r.chaos.rules = append(r.chaos.rules[:i:i], r.chaos.rules[i+1:]...)
+			return
+		}
+	}
+}
+
+// ClearRules removes all rules, so that all following calls are passed through.
+func (r *ChaosRepository[T]) ClearRules() {
+	r.chaos.mu.Lock()
+	defer r.chaos.mu.Unlock()
+
+	r.chaos.rules = nil
+}
+
+// Fired returns how often the rule with the given id fired, or 0 if it was removed.
+func (r *ChaosRepository[T]) Fired(id int) int {
+	r.chaos.mu.Lock()
+	defer r.chaos.mu.Unlock()
+
+	for _, rule := range r.chaos.rules {
+		if rule.id == id {
+			return rule.fired
+		}
+	}
+	return 0
+}
+
+// inject applies the rules that fire for a call, and returns the error that replaces the call.
+func (r *ChaosRepository[T]) inject(ctx context.Context, method string, filter bson.M) error {
+	delay, err := r.chaos.fire(method, filter)
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+func (c *chaos) fire(method string, filter bson.M) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var delay time.Duration
+	for _, rule := range c.rules {
+		if len(rule.Operations) > 0 && !containsString(rule.Operations, method) {
+			continue
+		}
+		if rule.Match != nil && !rule.Match(filter) {
+			continue
+		}
+		// The generator is only advanced for matching calls, so that other calls do not change the decisions.
+		if rule.Probability > 0 && rule.Probability < 1 && rule.rand.Float64() >= rule.Probability {
+			continue
+		}
+
+		rule.fired++
+		delay += rule.Delay
+		if rule.Err != nil {
+			return delay, rule.Err
+		}
+	}
+	return delay, nil
+}
+
+// containsString reports whether values contains value.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// WithSession binds the inner repository to the session. The returned repository shares the rules.
+func (r *ChaosRepository[T]) WithSession(sess mongo.Session) mongodb.RepositoryI[T] {
+	return &ChaosRepository[T]{inner: mongodb.Forwarder[T]{RepositoryI: mongodb.WithSession(r.inner.RepositoryI, sess)}, chaos: r.chaos}
+}
+
+func (r *ChaosRepository[T]) FindOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (_ T, err error) {
+	if err = r.inject(ctx, "FindOne", filter); err != nil {
+		return
+	}
+	return r.inner.FindOne(ctx, filter, opts...)
+}
+
+func (r *ChaosRepository[T]) GetByID(ctx context.Context, id primitive.ObjectID, projection ...string) (_ T, err error) {
+	if err = r.inject(ctx, "GetByID", mongodb.MongoIDFilter(id)); err != nil {
+		return
+	}
+	return r.inner.GetByID(ctx, id, projection...)
+}
+
+func (r *ChaosRepository[T]) FindMany(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (_ []T, err error) {
+	if err = r.inject(ctx, "FindMany", filter); err != nil {
+		return
+	}
+	return r.inner.FindMany(ctx, filter, opts...)
+}
+
+func (r *ChaosRepository[T]) FindManyN(ctx context.Context, filter bson.M, expectedCount int, opts ...*options.FindOptions) (_ []T, err error) {
+	if err = r.inject(ctx, "FindManyN", filter); err != nil {
+		return
+	}
+	return r.inner.FindManyN(ctx, filter, expectedCount, opts...)
+}
+
+func (r *ChaosRepository[T]) FindManyRaw(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (_ []bson.Raw, err error) {
+	if err = r.inject(ctx, "FindManyRaw", filter); err != nil {
+		return
+	}
+	return r.inner.FindManyRaw(ctx, filter, opts...)
+}
+
+func (r *ChaosRepository[T]) FindCursor(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (_ *mongo.Cursor, err error) {
+	if err = r.inject(ctx, "FindCursor", filter); err != nil {
+		return
+	}
+	return r.inner.FindCursor(ctx, filter, opts...)
+}
+
+func (r *ChaosRepository[T]) FindManyParallel(ctx context.Context, filter bson.M, parallelism int, fn func([]T) error) error {
+	if err := r.inject(ctx, "FindManyParallel", filter); err != nil {
+		return err
+	}
+	return r.inner.FindManyParallel(ctx, filter, parallelism, fn)
+}
+
+func (r *ChaosRepository[T]) InsertOne(ctx context.Context, doc T, opts ...*options.InsertOneOptions) (T, error) {
+	if err := r.inject(ctx, "InsertOne", nil); err != nil {
+		return doc, err
+	}
+	return r.inner.InsertOne(ctx, doc, opts...)
+}
+
+func (r *ChaosRepository[T]) InsertMany(ctx context.Context, docs []T, opts ...*options.InsertManyOptions) ([]T, error) {
+	if err := r.inject(ctx, "InsertMany", nil); err != nil {
+		return docs, err
+	}
+	return r.inner.InsertMany(ctx, docs, opts...)
+}
+
+func (r *ChaosRepository[T]) InsertManyResult(ctx context.Context, docs []T, opts ...*options.InsertManyOptions) ([]T, *mongo.InsertManyResult, error) {
+	if err := r.inject(ctx, "InsertManyResult", nil); err != nil {
+		return docs, nil, err
+	}
+	return r.inner.InsertManyResult(ctx, docs, opts...)
+}
+
+func (r *ChaosRepository[T]) UpdateOne(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) (_ *mongo.UpdateResult, err error) {
+	if err = r.inject(ctx, "UpdateOne", filter); err != nil {
+		return
+	}
+	return r.inner.UpdateOne(ctx, filter, data, opts...)
+}
+
+func (r *ChaosRepository[T]) UpdateMany(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) error {
+	if err := r.inject(ctx, "UpdateMany", filter); err != nil {
+		return err
+	}
+	return r.inner.UpdateMany(ctx, filter, data, opts...)
+}
+
+func (r *ChaosRepository[T]) UpdateManyResult(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) (_ *mongo.UpdateResult, err error) {
+	if err = r.inject(ctx, "UpdateManyResult", filter); err != nil {
+		return
+	}
+	return r.inner.UpdateManyResult(ctx, filter, data, opts...)
+}
+
+func (r *ChaosRepository[T]) ReplaceOne(ctx context.Context, filter bson.M, doc T, opts ...*options.ReplaceOptions) (T, error) {
+	if err := r.inject(ctx, "ReplaceOne", filter); err != nil {
+		return doc, err
+	}
+	return r.inner.ReplaceOne(ctx, filter, doc, opts...)
+}
+
+func (r *ChaosRepository[T]) FindOneOrCreate(ctx context.Context, filter bson.M, defaultDoc T, opts ...*options.FindOneAndUpdateOptions) (_ T, _ bool, err error) {
+	if err = r.inject(ctx, "FindOneOrCreate", filter); err != nil {
+		return
+	}
+	return r.inner.FindOneOrCreate(ctx, filter, defaultDoc, opts...)
+}
+
+func (r *ChaosRepository[T]) UpsertManyByKey(ctx context.Context, docs []T, keyFields []string, opts ...*options.BulkWriteOptions) (_ *mongo.BulkWriteResult, err error) {
+	if err = r.inject(ctx, "UpsertManyByKey", nil); err != nil {
+		return
+	}
+	return r.inner.UpsertManyByKey(ctx, docs, keyFields, opts...)
+}
+
+func (r *ChaosRepository[T]) FindOneWhere(ctx context.Context, filter mongodb.Filter, opts ...*options.FindOneOptions) (_ T, err error) {
+	if err = r.inject(ctx, "FindOneWhere", filter.M()); err != nil {
+		return
+	}
+	return r.inner.FindOneWhere(ctx, filter, opts...)
+}
+
+func (r *ChaosRepository[T]) FindManyWhere(ctx context.Context, filter mongodb.Filter, opts ...*options.FindOptions) (_ []T, err error) {
+	if err = r.inject(ctx, "FindManyWhere", filter.M()); err != nil {
+		return
+	}
+	return r.inner.FindManyWhere(ctx, filter, opts...)
+}
+
+func (r *ChaosRepository[T]) UpdateOneWhere(ctx context.Context, filter mongodb.Filter, data primitive.M, opts ...*options.UpdateOptions) (_ *mongo.UpdateResult, err error) {
+	if err = r.inject(ctx, "UpdateOneWhere", filter.M()); err != nil {
+		return
+	}
+	return r.inner.UpdateOneWhere(ctx, filter, data, opts...)
+}
+
+func (r *ChaosRepository[T]) UpdateManyWhere(ctx context.Context, filter mongodb.Filter, data primitive.M, opts ...*options.UpdateOptions) (_ *mongo.UpdateResult, err error) {
+	if err = r.inject(ctx, "UpdateManyWhere", filter.M()); err != nil {
+		return
+	}
+	return r.inner.UpdateManyWhere(ctx, filter, data, opts...)
+}
+
+func (r *ChaosRepository[T]) DeleteOneWhere(ctx context.Context, filter mongodb.Filter, opts ...*options.DeleteOptions) error {
+	if err := r.inject(ctx, "DeleteOneWhere", filter.M()); err != nil {
+		return err
+	}
+	return r.inner.DeleteOneWhere(ctx, filter, opts...)
+}
+
+func (r *ChaosRepository[T]) DeleteManyWhere(ctx context.Context, filter mongodb.Filter, opts ...*options.DeleteOptions) (_ int, err error) {
+	if err = r.inject(ctx, "DeleteManyWhere", filter.M()); err != nil {
+		return
+	}
+	return r.inner.DeleteManyWhere(ctx, filter, opts...)
+}
+
+func (r *ChaosRepository[T]) CountWhere(ctx context.Context, filter mongodb.Filter, opts ...*options.CountOptions) (_ int, err error) {
+	if err = r.inject(ctx, "CountWhere", filter.M()); err != nil {
+		return
+	}
+	return r.inner.CountWhere(ctx, filter, opts...)
+}
+
+func (r *ChaosRepository[T]) DeleteOne(ctx context.Context, filter bson.M, opts ...*options.DeleteOptions) error {
+	if err := r.inject(ctx, "DeleteOne", filter); err != nil {
+		return err
+	}
+	return r.inner.DeleteOne(ctx, filter, opts...)
+}
+
+func (r *ChaosRepository[T]) DeleteMany(ctx context.Context, filter bson.M, opts ...*options.DeleteOptions) (_ int, err error) {
+	if err = r.inject(ctx, "DeleteMany", filter); err != nil {
+		return
+	}
+	return r.inner.DeleteMany(ctx, filter, opts...)
+}
+
+func (r *ChaosRepository[T]) DeleteManyByIDs(ctx context.Context, ids []primitive.ObjectID, chunkSize int) (_ int, err error) {
+	if err = r.inject(ctx, "DeleteManyByIDs", nil); err != nil {
+		return
+	}
+	return r.inner.DeleteManyByIDs(ctx, ids, chunkSize)
+}
+
+func (r *ChaosRepository[T]) DeleteManyAudited(ctx context.Context, filter bson.M, batchSize int, onBatch func(deletedIDs []primitive.ObjectID) error) (_ int, err error) {
+	if err = r.inject(ctx, "DeleteManyAudited", filter); err != nil {
+		return
+	}
+	return r.inner.DeleteManyAudited(ctx, filter, batchSize, onBatch)
+}
+
+func (r *ChaosRepository[T]) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (_ *mongo.BulkWriteResult, err error) {
+	if err = r.inject(ctx, "BulkWrite", nil); err != nil {
+		return
+	}
+	return r.inner.BulkWrite(ctx, models, opts...)
+}
+
+func (r *ChaosRepository[T]) Watch(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.ChangeStreamOptions) (_ *mongo.ChangeStream, err error) {
+	if err = r.inject(ctx, "Watch", nil); err != nil {
+		return
+	}
+	return r.inner.Watch(ctx, pipeline, opts...)
+}
+
+func (r *ChaosRepository[T]) Drop(ctx context.Context) error {
+	if err := r.inject(ctx, "Drop", nil); err != nil {
+		return err
+	}
+	return r.inner.Drop(ctx)
+}
+
+func (r *ChaosRepository[T]) Stats(ctx context.Context) (_ mongodb.CollectionStats, err error) {
+	if err = r.inject(ctx, "Stats", nil); err != nil {
+		return
+	}
+	return r.inner.Stats(ctx)
+}
+
+func (r *ChaosRepository[T]) Distinct(ctx context.Context, path mongodb.FieldPath, filter bson.M, opts ...*options.DistinctOptions) (_ []interface{}, err error) {
+	if err = r.inject(ctx, "Distinct", filter); err != nil {
+		return
+	}
+	return r.inner.Distinct(ctx, path, filter, opts...)
+}
+
+func (r *ChaosRepository[T]) Verify(ctx context.Context) error {
+	if err := r.inject(ctx, "Verify", nil); err != nil {
+		return err
+	}
+	return r.inner.Verify(ctx)
+}
+
+func (r *ChaosRepository[T]) Aggregate(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.AggregateOptions) (_ *mongo.Cursor, err error) {
+	if err = r.inject(ctx, "Aggregate", nil); err != nil {
+		return
+	}
+	return r.inner.Aggregate(ctx, pipeline, opts...)
+}
+
+func (r *ChaosRepository[T]) CountDocuments(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (_ int, err error) {
+	if err = r.inject(ctx, "CountDocuments", filter); err != nil {
+		return
+	}
+	return r.inner.CountDocuments(ctx, filter, opts...)
+}
+
+func (r *ChaosRepository[T]) CreateIndex(ctx context.Context, keys bson.D, opts ...*options.IndexOptions) (_ string, err error) {
+	if err = r.inject(ctx, "CreateIndex", nil); err != nil {
+		return
+	}
+	return r.inner.CreateIndex(ctx, keys, opts...)
+}
+
+func (r *ChaosRepository[T]) CreateIndexes(ctx context.Context, models []mongo.IndexModel) (_ []string, err error) {
+	if err = r.inject(ctx, "CreateIndexes", nil); err != nil {
+		return
+	}
+	return r.inner.CreateIndexes(ctx, models)
+}
+
+func (r *ChaosRepository[T]) DropIndex(ctx context.Context, name string) error {
+	if err := r.inject(ctx, "DropIndex", nil); err != nil {
+		return err
+	}
+	return r.inner.DropIndex(ctx, name)
+}
+
+func (r *ChaosRepository[T]) ListIndexes(ctx context.Context) (_ []mongodb.IndexInfo, err error) {
+	if err = r.inject(ctx, "ListIndexes", nil); err != nil {
+		return
+	}
+	return r.inner.ListIndexes(ctx)
+}
+
+func (r *ChaosRepository[T]) SetIndexExpireAfter(ctx context.Context, name string, expireAfter time.Duration) error {
+	if err := r.inject(ctx, "SetIndexExpireAfter", nil); err != nil {
+		return err
+	}
+	return r.inner.SetIndexExpireAfter(ctx, name, expireAfter)
+}
@@ -0,0 +1,21 @@
+//go:build go1.23
+
+package mongotest
+
+import (
+	"context"
+	"iter"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *ChaosRepository[T]) FindIter(ctx context.Context, filter bson.M, opts ...*options.FindOptions) iter.Seq2[T, error] {
+	if err := r.inject(ctx, "FindIter", filter); err != nil {
+		return func(yield func(T, error) bool) {
+			var zero T
+			yield(zero, err)
+		}
+	}
+	return r.inner.FindIter(ctx, filter, opts...)
+}
@@ -0,0 +1,128 @@
+package mongotest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongotest"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestChaosRepositoryPassThrough(t *testing.T) {
+	ctx := context.Background()
+	spy, inner := mongotest.NewSpy[*User](nil)
+	spy.StubFindMany([]*User{{Name: "Alice"}}, nil)
+	spy.StubCountDocuments(1, nil)
+	repo := mongotest.NewChaosRepository(inner, 1)
+	repo.AddRule(mongotest.ChaosRule{Operations: []string{"UpdateOne"}, Err: mongotest.NetworkError()})
+
+	users, err := repo.FindMany(ctx, bson.M{"name": "Alice"})
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+	count, err := repo.CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, bson.M{"name": "Alice"}, spy.LastFilter("FindMany"))
+}
+
+func TestChaosRepositoryRules(t *testing.T) {
+	ctx := context.Background()
+	spy, inner := mongotest.NewSpy[*User](nil)
+	spy.StubFindOne(&User{Name: "Alice"}, nil)
+	spy.StubError("UpdateOne", nil)
+	repo := mongotest.NewChaosRepository(inner, 1)
+
+	missing := primitive.NewObjectID()
+	notFound := repo.AddRule(mongotest.ChaosRule{Operations: []string{"FindOne"}, Match: mongotest.MatchID(missing), Err: mongodb.ErrNotFound})
+	slow := repo.AddRule(mongotest.ChaosRule{Operations: []string{"UpdateOne"}, Delay: 50 * time.Millisecond})
+
+	_, err := repo.FindOne(ctx, mongodb.MongoIDFilter(missing))
+	assert.ErrorIs(t, err, mongodb.ErrNotFound)
+	_, err = repo.FindOne(ctx, mongodb.MongoIDFilter(primitive.NewObjectID()))
+	assert.NoError(t, err, "other filters are passed through")
+	assert.Equal(t, 1, spy.CallCount("FindOne"), "failed calls do not reach the inner repository")
+	assert.Equal(t, 1, repo.Fired(notFound))
+
+	started := time.Now()
+	_, err = repo.UpdateOne(ctx, bson.M{}, bson.M{"name": "Bob"})
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(started), 50*time.Millisecond)
+	assert.Equal(t, 1, spy.CallCount("UpdateOne"))
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = repo.UpdateOne(timeoutCtx, bson.M{}, bson.M{"name": "Bob"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "delays end with the context")
+	assert.Equal(t, 1, spy.CallCount("UpdateOne"))
+
+	repo.RemoveRule(slow)
+	started = time.Now()
+	_, err = repo.UpdateOne(ctx, bson.M{}, bson.M{"name": "Bob"})
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(started), 50*time.Millisecond)
+	assert.Equal(t, 0, repo.Fired(slow))
+
+	// Rules without operations apply to all methods.
+	repo.AddRule(mongotest.ChaosRule{Err: mongotest.NetworkError()})
+	_, err = repo.CountDocuments(ctx, bson.M{})
+	assert.True(t, mongo.IsNetworkError(err))
+	repo.ClearRules()
+	_, err = repo.FindOne(ctx, mongodb.MongoIDFilter(missing))
+	assert.NoError(t, err)
+}
+
+func TestChaosRepositoryDeterminism(t *testing.T) {
+	ctx := context.Background()
+	failures := func(seed uint64) []bool {
+		spy, inner := mongotest.NewSpy[*User](nil)
+		spy.StubFindMany(nil, nil)
+		repo := mongotest.NewChaosRepository(inner, seed)
+		repo.AddRule(mongotest.ChaosRule{Operations: []string{"FindMany"}, Probability: 0.1, Err: mongotest.NetworkError()})
+
+		res := make([]bool, 1000)
+		for i := range res {
+			_, err := repo.FindMany(ctx, bson.M{})
+			res[i] = err != nil
+		}
+		return res
+	}
+
+	first := failures(42)
+	assert.Equal(t, first, failures(42))
+	assert.NotEqual(t, first, failures(43))
+
+	failed := 0
+	for _, f := range first {
+		if f {
+			failed++
+		}
+	}
+	assert.InDelta(t, 100, failed, 40)
+}
+
+func TestChaosRepositoryRetry(t *testing.T) {
+	ctx := context.Background()
+	spy, inner := mongotest.NewSpy[*User](nil)
+	spy.StubFindMany([]*User{{Name: "Alice"}}, nil)
+	repo := mongotest.NewChaosRepository(inner, 7)
+	flaky := repo.AddRule(mongotest.ChaosRule{Operations: []string{"FindMany"}, Probability: 0.5, Err: mongotest.NetworkError()})
+
+	// A caller that retries network errors eventually succeeds.
+	var users []*User
+	var err error
+	attempts := 0
+	for attempts < 20 {
+		attempts++
+		if users, err = repo.FindMany(ctx, bson.M{}); !mongo.IsNetworkError(err) {
+			break
+		}
+	}
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.Equal(t, attempts, spy.CallCount("FindMany")+repo.Fired(flaky))
+}
@@ -0,0 +1,166 @@
+package mongotest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	jsonExt   = ".json"
+	ndjsonExt = ".ndjson"
+	// Fixture lines can contain large documents, the default limit of bufio.Scanner is 64KB.
+	maxLineSize = 16 * 1024 * 1024
+)
+
+type (
+	// FixtureOption configures [LoadFixtures].
+	FixtureOption interface {
+		apply(*fixtureOption)
+	}
+)
+
+type (
+	fixtureOption struct {
+		dropFirst bool
+	}
+)
+
+type dropFirstOption bool
+
+func (value dropFirstOption) apply(o *fixtureOption) {
+	o.dropFirst = bool(value)
+}
+
+// WithDropFirst drops every collection before its fixtures are inserted.
+func WithDropFirst() FixtureOption {
+	return dropFirstOption(true)
+}
+
+// LoadFixtures inserts the documents of all fixture files in dir into db.
+//
+// The collection name is the file name without extension. Files ending in .json must contain an array of documents,
+// files ending in .ndjson one document per line. Documents are parsed as MongoDB Extended JSON,
+// so {"$oid": "..."} and {"$date": "..."} are stored as ObjectID and date. Other files are ignored.
+func LoadFixtures(ctx context.Context, db *mongo.Database, dir string, opts ...FixtureOption) error {
+	ops := &fixtureOption{}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("LoadFixtures: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != jsonExt && ext != ndjsonExt) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		docs, err := readFixture[bson.Raw](path)
+		if err != nil {
+			return fmt.Errorf("LoadFixtures: %w", err)
+		}
+
+		col := db.Collection(strings.TrimSuffix(entry.Name(), ext))
+		if ops.dropFirst {
+			if err := col.Drop(ctx); err != nil {
+				return fmt.Errorf("LoadFixtures: dropping %v: %w", col.Name(), err)
+			}
+		}
+
+		if len(docs) == 0 {
+			continue
+		}
+
+		insert := make([]interface{}, len(docs))
+		for i := range docs {
+			insert[i] = docs[i]
+		}
+		if _, err := col.InsertMany(ctx, insert); err != nil {
+			return fmt.Errorf("LoadFixtures: inserting %v: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// LoadFixture reads the documents of a single .json or .ndjson fixture file as T, see [LoadFixtures].
+func LoadFixture[T any](path string) ([]T, error) {
+	docs, err := readFixture[T](path)
+	if err != nil {
+		return nil, fmt.Errorf("LoadFixture: %w", err)
+	}
+
+	return docs, nil
+}
+
+func readFixture[T any](path string) ([]T, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if filepath.Ext(path) == ndjsonExt {
+		return parseNDJSON[T](path, data)
+	}
+
+	return parseJSONArray[T](path, data)
+}
+
+func parseJSONArray[T any](path string, data []byte) ([]T, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return nil, fmt.Errorf("%v: expected an array of documents: %w", path, err)
+	}
+
+	docs := make([]T, 0, len(elements))
+	for i, element := range elements {
+		var doc T
+		if err := bson.UnmarshalExtJSON(element, false, &doc); err != nil {
+			return nil, fmt.Errorf("%v: element %d: %w", path, i, err)
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+func parseNDJSON[T any](path string, data []byte) ([]T, error) {
+	var docs []T
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var doc T
+		if err := bson.UnmarshalExtJSON(text, false, &doc); err != nil {
+			return nil, fmt.Errorf("%v: line %d: %w", path, line, err)
+		}
+		docs = append(docs, doc)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%v: %w", path, err)
+	}
+
+	return docs, nil
+}
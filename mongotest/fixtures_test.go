@@ -0,0 +1,69 @@
+package mongotest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongotest"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestLoadFixtureExtendedJSON(t *testing.T) {
+	users, err := mongotest.LoadFixture[*User]("testdata/fixtures/users.json")
+	if err != nil {
+		t.Fatalf("Error on loading fixture: %v", err)
+	}
+
+	id, _ := primitive.ObjectIDFromHex("65f1a2b3c4d5e6f708091a2b")
+	assert.Len(t, users, 2)
+	assert.Equal(t, id, users[0].MongoID)
+	assert.Equal(t, "Willy", users[0].Name)
+	assert.True(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC).Equal(users[0].CreatedAt))
+
+	events, err := mongotest.LoadFixture[bson.M]("testdata/fixtures/events.ndjson")
+	if err != nil {
+		t.Fatalf("Error on loading fixture: %v", err)
+	}
+	assert.Len(t, events, 2)
+	assert.Equal(t, int64(43), events[1]["count"])
+}
+
+func TestLoadFixtureMalformed(t *testing.T) {
+	_, err := mongotest.LoadFixture[bson.M]("testdata/malformed/users.json")
+	assert.ErrorContains(t, err, "testdata/malformed/users.json: element 1")
+
+	_, err = mongotest.LoadFixture[bson.M]("testdata/malformed/events.ndjson")
+	assert.ErrorContains(t, err, "testdata/malformed/events.ndjson: line 3")
+}
+
+func TestLoadFixtures(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	db := client.Database("testdb_fixtures")
+	defer db.Drop(ctx)
+
+	// Loading twice with WithDropFirst must not fail on duplicate _ids.
+	for i := 0; i < 2; i++ {
+		if err := mongotest.LoadFixtures(ctx, db, "testdata/fixtures", mongotest.WithDropFirst()); err != nil {
+			t.Fatalf("Error on loading fixtures: %v", err)
+		}
+	}
+
+	count, err := db.Collection("users").CountDocuments(ctx, bson.M{"_id": bson.M{"$type": "objectId"}, "createdAt": bson.M{"$type": "date"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	count, err = db.Collection("events").CountDocuments(ctx, bson.M{"count": bson.M{"$type": "long"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
@@ -0,0 +1,429 @@
+package mongotest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// fakeTag is the struct tag that selects the kind of value generated for a field, see [Generate].
+	fakeTag = "fake"
+	// maxGenerateDepth stops the recursion of self-referencing types.
+	maxGenerateDepth = 8
+)
+
+var (
+	objectIDType = reflect.TypeOf(primitive.ObjectID{})
+	timeType     = reflect.TypeOf(time.Time{})
+
+	fakeWords = []string{
+		"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel", "india", "juliett",
+		"kilo", "lima", "mike", "november", "oscar", "papa", "quebec", "romeo", "sierra", "tango",
+	}
+	fakeFirstNames = []string{"Anna", "Ben", "Clara", "David", "Emma", "Felix", "Greta", "Hannah", "Jonas", "Lena", "Max", "Sophie"}
+	fakeLastNames  = []string{"Becker", "Fischer", "Hoffmann", "Koch", "Meyer", "Müller", "Richter", "Schmidt", "Schulz", "Wagner"}
+	fakeDomains    = []string{"example.com", "example.org", "example.net"}
+)
+
+type (
+	// GenOption configures [Generate] and [Populate].
+	GenOption interface {
+		apply(*genOption)
+	}
+)
+
+type (
+	genOption struct {
+		seed       uint64
+		minLen     int
+		maxLen     int
+		minInt     int64
+		maxInt     int64
+		from       time.Time
+		to         time.Time
+		progress   func(inserted, total int)
+		noProgress bool
+	}
+
+	generator struct {
+		ops *genOption
+		rnd *rand.Rand
+	}
+)
+
+type seedOption uint64
+
+func (value seedOption) apply(o *genOption) {
+	o.seed = uint64(value)
+}
+
+// WithSeed makes the generated documents deterministic: the same seed and options always yield the same documents.
+// Without a seed every call generates different documents.
+func WithSeed(seed uint64) GenOption {
+	return seedOption(seed)
+}
+
+type stringLengthOption [2]int
+
+func (value stringLengthOption) apply(o *genOption) {
+	o.minLen, o.maxLen = value[0], value[1]
+}
+
+// WithStringLength sets the length of generated strings without a fake tag. The default is between 5 and 20 characters.
+func WithStringLength(min, max int) GenOption {
+	return stringLengthOption{min, max}
+}
+
+type intRangeOption [2]int64
+
+func (value intRangeOption) apply(o *genOption) {
+	o.minInt, o.maxInt = value[0], value[1]
+}
+
+// WithIntRange sets the bounds, both inclusive, of generated integers and floats. The default is between 0 and 1000.
+// Unsigned fields never get negative values.
+func WithIntRange(min, max int64) GenOption {
+	return intRangeOption{min, max}
+}
+
+type timeRangeOption [2]time.Time
+
+func (value timeRangeOption) apply(o *genOption) {
+	o.from, o.to = value[0], value[1]
+}
+
+// WithTimeRange sets the range of generated times. The default are the 30 days before the start of the current day (UTC),
+// which keeps the output of a seed stable during the day. Pass a fixed range for output that never changes.
+func WithTimeRange(from, to time.Time) GenOption {
+	return timeRangeOption{from, to}
+}
+
+type progressOption func(inserted, total int)
+
+func (value progressOption) apply(o *genOption) {
+	o.progress = value
+	o.noProgress = value == nil
+}
+
+// WithProgress replaces the progress output of [Populate], which logs every inserted batch with log.Printf by default.
+// Passing nil disables the progress output. Generate ignores this option.
+func WithProgress(progress func(inserted, total int)) GenOption {
+	return progressOption(progress)
+}
+
+// Generate creates n documents of type T with plausible random values, e.g. to load-test indexes and queries.
+//
+// All exported fields are filled, including embedded and nested structs, pointers, slices and maps with string keys:
+// strings with random letters, bounded integers and floats, recent times, and random ObjectIDs.
+// Fields ending in "ID" and of type string get the hex of an ObjectID. Fields with a fake tag get smarter values:
+//
+//	Email  string `bson:"email" fake:"email"`
+//	Status string `bson:"status" fake:"oneof:active|inactive"`
+//	Notes  string `bson:"notes" fake:"-"`
+//
+// The supported tags are email, name, firstname, lastname, word, sentence, url, phone, uuid, hex, oneof:a|b|c and - to skip the field.
+// Fake tags on pointers, slices, arrays and maps of strings apply to their elements.
+// Unknown tags panic, as do fake tags on fields that are no strings.
+//
+// The documents are not initialized with InitDocument, InsertMany does that.
+func Generate[T mongodb.Document[T]](n int, opts ...GenOption) []T {
+	return newGenerator(opts).generate(reflect.TypeOf((*T)(nil)).Elem(), n).([]T)
+}
+
+// Populate generates n documents like [Generate] and inserts them into repo with InsertMany in batches of batchSize,
+// and returns the number of inserted documents. A batchSize <= 0 uses batches of 1000 documents.
+//
+// The documents are generated batch by batch, so that large n don't need to fit into memory.
+// WithSeed yields the same documents as Generate with the same seed.
+func Populate[T mongodb.Document[T]](ctx context.Context, repo mongodb.InsertMany[T], n int, batchSize int, opts ...GenOption) (int, error) {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+
+	g := newGenerator(opts)
+	progress := g.ops.progress
+	if progress == nil && !g.ops.noProgress {
+		progress = func(inserted, total int) {
+			log.Printf("mongotest.Populate: inserted %d/%d documents", inserted, total)
+		}
+	}
+
+	inserted := 0
+	for inserted < n {
+		if err := ctx.Err(); err != nil {
+			return inserted, fmt.Errorf("Populate: %w", err)
+		}
+
+		size := batchSize
+		if n-inserted < size {
+			size = n - inserted
+		}
+		docs := g.generate(reflect.TypeOf((*T)(nil)).Elem(), size).([]T)
+		if _, err := repo.InsertMany(ctx, docs); err != nil {
+			return inserted, fmt.Errorf("Populate: inserting documents %d to %d: %w", inserted, inserted+len(docs), err)
+		}
+		inserted += len(docs)
+
+		if progress != nil {
+			progress(inserted, n)
+		}
+	}
+
+	return inserted, nil
+}
+
+func newGenerator(opts []GenOption) *generator {
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	ops := &genOption{
+		seed:   rand.Uint64(),
+		minLen: 5,
+		maxLen: 20,
+		minInt: 0,
+		maxInt: 1000,
+		from:   to.AddDate(0, 0, -30),
+		to:     to,
+	}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+
+	return &generator{ops: ops, rnd: rand.New(rand.NewSource(int64(ops.seed)))}
+}
+
+// generate returns a []typ with n generated values.
+func (g *generator) generate(typ reflect.Type, n int) interface{} {
+	docs := reflect.MakeSlice(reflect.SliceOf(typ), n, n)
+	for i := 0; i < n; i++ {
+		g.fill(docs.Index(i), "", "", 0)
+	}
+
+	return docs.Interface()
+}
+
+// fill sets v to a random value. name and tag are the field name and fake tag of v, if v is a struct field.
+func (g *generator) fill(v reflect.Value, name string, tag string, depth int) {
+	if depth > maxGenerateDepth {
+		return
+	}
+
+	// Fake tags apply to strings, and to the elements of pointers, slices, arrays and maps of strings.
+	if tag != "" {
+		switch elem := leafType(v.Type()); {
+		case elem.Kind() != reflect.String:
+			panic(fmt.Sprintf("mongotest.Generate: fake tag %q on field %v of type %v, only strings are supported", tag, name, v.Type()))
+		case v.Kind() == reflect.String:
+			v.SetString(g.fake(name, tag))
+			return
+		}
+	}
+
+	switch v.Type() {
+	case objectIDType:
+		v.Set(reflect.ValueOf(g.objectID()))
+		return
+	case timeType:
+		v.Set(reflect.ValueOf(g.time()))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		g.fill(elem.Elem(), name, tag, depth+1)
+		v.Set(elem)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			fieldTag := field.Tag.Get(fakeTag)
+			if !field.IsExported() || fieldTag == "-" || field.Tag.Get("bson") == "-" {
+				continue
+			}
+			g.fill(v.Field(i), field.Name, fieldTag, depth+1)
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 && tag == "" {
+			b := make([]byte, g.length())
+			for i := range b {
+				b[i] = byte(g.rnd.Intn(256))
+			}
+			v.SetBytes(b)
+			return
+		}
+
+		n := 1 + g.rnd.Intn(3)
+		slice := reflect.MakeSlice(v.Type(), n, n)
+		for i := 0; i < n; i++ {
+			g.fill(slice.Index(i), name, tag, depth+1)
+		}
+		v.Set(slice)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			g.fill(v.Index(i), name, tag, depth+1)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+
+		m := reflect.MakeMap(v.Type())
+		n := 1 + g.rnd.Intn(3)
+		for i := 0; i < n; i++ {
+			key := reflect.New(v.Type().Key()).Elem()
+			key.SetString(fakeWords[g.rnd.Intn(len(fakeWords))])
+			value := reflect.New(v.Type().Elem()).Elem()
+			g.fill(value, name, tag, depth+1)
+			m.SetMapIndex(key, value)
+		}
+		v.Set(m)
+	case reflect.String:
+		if strings.HasSuffix(name, "ID") {
+			v.SetString(g.objectID().Hex())
+			return
+		}
+		v.SetString(g.letters(g.length()))
+	case reflect.Bool:
+		v.SetBool(g.rnd.Intn(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(clampInt(g.int(), v.Type()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n := g.int()
+		if n < 0 {
+			n = 0
+		}
+		v.SetUint(uint64(clampInt(n, v.Type())))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(g.ops.minInt) + g.rnd.Float64()*float64(g.ops.maxInt-g.ops.minInt))
+	}
+}
+
+func (g *generator) fake(name string, tag string) string {
+	if strings.HasPrefix(tag, "oneof:") {
+		options := strings.Split(strings.TrimPrefix(tag, "oneof:"), "|")
+		return options[g.rnd.Intn(len(options))]
+	}
+
+	switch tag {
+	case "email":
+		first, last := g.pick(fakeFirstNames), g.pick(fakeLastNames)
+		return strings.ToLower(first+"."+last) + fmt.Sprintf("%d@", g.rnd.Intn(1000)) + g.pick(fakeDomains)
+	case "name":
+		return g.pick(fakeFirstNames) + " " + g.pick(fakeLastNames)
+	case "firstname":
+		return g.pick(fakeFirstNames)
+	case "lastname":
+		return g.pick(fakeLastNames)
+	case "word":
+		return g.pick(fakeWords)
+	case "sentence":
+		words := make([]string, 4+g.rnd.Intn(8))
+		for i := range words {
+			words[i] = g.pick(fakeWords)
+		}
+		return strings.ToUpper(words[0][:1]) + strings.Join(words, " ")[1:] + "."
+	case "url":
+		return "https://" + g.pick(fakeDomains) + "/" + g.pick(fakeWords) + "/" + g.letters(8)
+	case "phone":
+		return fmt.Sprintf("+49 %03d %07d", 100+g.rnd.Intn(900), g.rnd.Intn(10000000))
+	case "uuid":
+		var b [16]byte
+		for i := range b {
+			b[i] = byte(g.rnd.Intn(256))
+		}
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	case "hex":
+		return g.objectID().Hex()
+	}
+
+	panic(fmt.Sprintf("mongotest.Generate: unknown fake tag %q on field %v", tag, name))
+}
+
+func (g *generator) pick(values []string) string {
+	return values[g.rnd.Intn(len(values))]
+}
+
+func (g *generator) length() int {
+	if g.ops.maxLen <= g.ops.minLen {
+		if g.ops.minLen < 0 {
+			return 0
+		}
+		return g.ops.minLen
+	}
+	return g.ops.minLen + g.rnd.Intn(g.ops.maxLen-g.ops.minLen+1)
+}
+
+func (g *generator) letters(n int) string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz"
+
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[g.rnd.Intn(len(alphabet))]
+	}
+	return string(b)
+}
+
+func (g *generator) int() int64 {
+	if g.ops.maxInt <= g.ops.minInt {
+		return g.ops.minInt
+	}
+	return g.ops.minInt + g.rnd.Int63n(g.ops.maxInt-g.ops.minInt+1)
+}
+
+// time returns a time between from and to, truncated to milliseconds, since MongoDB stores no finer times.
+func (g *generator) time() time.Time {
+	span := g.ops.to.Sub(g.ops.from)
+	if span <= 0 {
+		return g.ops.from
+	}
+	return g.ops.from.Add(time.Duration(g.rnd.Int63n(int64(span)))).Truncate(time.Millisecond)
+}
+
+// objectID returns a valid ObjectID with a timestamp in the time range and random remaining bytes.
+func (g *generator) objectID() primitive.ObjectID {
+	id := primitive.NewObjectIDFromTimestamp(g.time())
+	for i := 4; i < len(id); i++ {
+		id[i] = byte(g.rnd.Intn(256))
+	}
+	return id
+}
+
+// leafType returns the element type of pointers, slices, arrays and maps, or typ itself.
+func leafType(typ reflect.Type) reflect.Type {
+	for {
+		switch typ.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			typ = typ.Elem()
+		default:
+			return typ
+		}
+	}
+}
+
+// clampInt limits v to the range of the integer type typ.
+func clampInt(v int64, typ reflect.Type) int64 {
+	bits := typ.Bits()
+	if bits == 64 {
+		return v
+	}
+
+	upper, lower := int64(1)<<(bits-1)-1, -(int64(1) << (bits - 1))
+	if typ.Kind() >= reflect.Uint && typ.Kind() <= reflect.Uint64 {
+		upper, lower = int64(1)<<bits-1, v
+	}
+	if v > upper {
+		return upper
+	}
+	if v < lower {
+		return lower
+	}
+	return v
+}
@@ -0,0 +1,152 @@
+package mongotest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongotest"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Customer struct {
+	mongodb.BaseModel `bson:",inline"`
+	CompanyID         primitive.ObjectID `bson:"companyID"`
+	ExternalID        string             `bson:"externalID"`
+	Name              string             `bson:"name" fake:"name"`
+	Email             string             `bson:"email" fake:"email"`
+	Status            string             `bson:"status" fake:"oneof:active|inactive"`
+	Code              string             `bson:"code"`
+	Age               int                `bson:"age"`
+	Score             uint8              `bson:"score"`
+	Tags              []string           `bson:"tags" fake:"word"`
+	Address           *Address           `bson:"address"`
+	LastLogin         time.Time          `bson:"lastLogin"`
+	Internal          string             `bson:"-"`
+	Notes             string             `bson:"notes" fake:"-"`
+}
+
+type Address struct {
+	City string `bson:"city"`
+	Zip  string `bson:"zip"`
+}
+
+func (c *Customer) Validate() error {
+	var errs []error
+	if c.CompanyID.IsZero() {
+		errs = append(errs, errors.New("companyID is missing"))
+	}
+	if _, err := primitive.ObjectIDFromHex(c.ExternalID); err != nil {
+		errs = append(errs, fmt.Errorf("externalID: %w", err))
+	}
+	if _, err := mail.ParseAddress(c.Email); err != nil {
+		errs = append(errs, fmt.Errorf("email: %w", err))
+	}
+	if c.Status != "active" && c.Status != "inactive" {
+		errs = append(errs, fmt.Errorf("invalid status %q", c.Status))
+	}
+	if len(c.Code) < 3 || len(c.Code) > 8 {
+		errs = append(errs, fmt.Errorf("code %q has to be 3 to 8 characters", c.Code))
+	}
+	if c.Age < 18 || c.Age > 99 {
+		errs = append(errs, fmt.Errorf("age %d out of range", c.Age))
+	}
+	if c.Address == nil || c.Address.City == "" {
+		errs = append(errs, errors.New("address is missing"))
+	}
+	return mongodb.JoinErrors(errs...)
+}
+
+func customerOptions(seed uint64) []mongotest.GenOption {
+	to := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	return []mongotest.GenOption{
+		mongotest.WithSeed(seed),
+		mongotest.WithStringLength(3, 8),
+		mongotest.WithIntRange(18, 99),
+		mongotest.WithTimeRange(to.AddDate(0, 0, -7), to),
+	}
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	first := mongotest.Generate[*Customer](50, customerOptions(42)...)
+	second := mongotest.Generate[*Customer](50, customerOptions(42)...)
+	other := mongotest.Generate[*Customer](50, customerOptions(43)...)
+
+	assert.Len(t, first, 50)
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first, other)
+}
+
+func TestGenerateValues(t *testing.T) {
+	opts := customerOptions(7)
+	from, to := time.Date(2024, 5, 25, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, c := range mongotest.Generate[*Customer](200, opts...) {
+		assert.NoError(t, c.Validate())
+		assert.False(t, c.MongoID.IsZero())
+		assert.NotEmpty(t, c.Tags)
+		assert.Empty(t, c.Internal)
+		assert.Empty(t, c.Notes)
+		assert.True(t, c.Score >= 18 && c.Score <= 99)
+		assert.False(t, c.LastLogin.Before(from) || c.LastLogin.After(to), c.LastLogin)
+		assert.Equal(t, c.LastLogin, c.LastLogin.Truncate(time.Millisecond))
+		assert.Len(t, strings.Fields(c.Name), 2)
+	}
+}
+
+func TestGenerateUnknownTag(t *testing.T) {
+	type invalid struct {
+		mongodb.BaseModel `bson:",inline"`
+		Name              string `fake:"nickname"`
+	}
+
+	assert.PanicsWithValue(t, `mongotest.Generate: unknown fake tag "nickname" on field Name`, func() {
+		mongotest.Generate[*invalid](1)
+	})
+}
+
+// insertRecorder is a mongodb.InsertMany that records the inserted batches.
+type insertRecorder struct {
+	batches [][]*Customer
+	err     error
+}
+
+func (r *insertRecorder) InsertMany(ctx context.Context, docs []*Customer, opts ...*options.InsertManyOptions) ([]*Customer, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	r.batches = append(r.batches, docs)
+	return docs, nil
+}
+
+func TestPopulate(t *testing.T) {
+	rec := &insertRecorder{}
+	var progress []int
+	opts := append(customerOptions(42), mongotest.WithProgress(func(inserted, total int) {
+		assert.Equal(t, 50, total)
+		progress = append(progress, inserted)
+	}))
+
+	n, err := mongotest.Populate[*Customer](context.Background(), rec, 50, 20, opts...)
+	assert.NoError(t, err)
+	assert.Equal(t, 50, n)
+	assert.Equal(t, []int{20, 40, 50}, progress)
+
+	var inserted []*Customer
+	for _, batch := range rec.batches {
+		inserted = append(inserted, batch...)
+	}
+	assert.Equal(t, mongotest.Generate[*Customer](50, customerOptions(42)...), inserted)
+
+	errFailed := errors.New("insert failed")
+	n, err = mongotest.Populate[*Customer](context.Background(), &insertRecorder{err: errFailed}, 50, 20, mongotest.WithProgress(nil))
+	assert.ErrorIs(t, err, errFailed)
+	assert.Equal(t, 0, n)
+}
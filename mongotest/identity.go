@@ -0,0 +1,103 @@
+package mongotest
+
+import (
+	"encoding/binary"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	// IDSequence is a [mongodb.IDGenerator] producing predictable MongoIDs, see [SequentialIDs].
+	IDSequence struct {
+		mu   sync.Mutex
+		seed uint32
+		next uint64
+	}
+
+	// ManualClock is a [mongodb.Clock] that only moves when told to, see [FrozenClock].
+	ManualClock struct {
+		mu  sync.Mutex
+		now time.Time
+	}
+)
+
+// SequentialIDs creates an IDGenerator whose IDs consist of the seed in the first four bytes
+// and a counter starting at 1 in the remaining eight bytes, so the same seed always yields the same IDs.
+func SequentialIDs(seed int) *IDSequence {
+	return &IDSequence{seed: uint32(seed), next: 1}
+}
+
+func (s *IDSequence) NewID() primitive.ObjectID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var id primitive.ObjectID
+	binary.BigEndian.PutUint32(id[:4], s.seed)
+	binary.BigEndian.PutUint64(id[4:], s.next)
+	s.next++
+
+	return id
+}
+
+// FrozenClock creates a Clock that always returns t until it is changed with Set or Advance.
+func FrozenClock(t time.Time) *ManualClock {
+	return &ManualClock{now: t}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Set moves the clock to t.
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = t
+}
+
+// Advance moves the clock forward by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// DeterministicEpoch is the time of the clock installed by [WithDeterministicIdentity].
+var DeterministicEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// identityMu serializes the tests that use WithDeterministicIdentity, since the clock and ID generator are package-wide.
+var identityMu sync.Mutex
+
+// WithDeterministicIdentity installs SequentialIDs(1) and a FrozenClock at [DeterministicEpoch] for the duration of the test,
+// and returns them so the test can derive expected values or advance the clock.
+//
+// The defaults are restored when the test finishes. Tests using WithDeterministicIdentity are serialized,
+// even when they call t.Parallel. Tests that don't use it but create documents while it is active will
+// get deterministic values too, so they should not run in parallel with it.
+func WithDeterministicIdentity(t testing.TB) (*IDSequence, *ManualClock) {
+	t.Helper()
+
+	identityMu.Lock()
+
+	ids := SequentialIDs(1)
+	clock := FrozenClock(DeterministicEpoch)
+	restoreIDs := mongodb.SetIDGenerator(ids)
+	restoreClock := mongodb.SetClock(clock)
+
+	t.Cleanup(func() {
+		restoreClock()
+		restoreIDs()
+		identityMu.Unlock()
+	})
+
+	return ids, clock
+}
@@ -0,0 +1,43 @@
+package mongotest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDeterministicIdentity(t *testing.T) {
+	expected := mongotest.SequentialIDs(1)
+
+	t.Run("first", func(t *testing.T) {
+		_, clock := mongotest.WithDeterministicIdentity(t)
+
+		user := &User{}
+		user.InitDocument()
+		assert.Equal(t, expected.NewID(), user.MongoID)
+		assert.Equal(t, mongotest.DeterministicEpoch, user.CreatedAt)
+
+		clock.Advance(time.Hour)
+		other := &User{}
+		other.InitDocument()
+		assert.Equal(t, expected.NewID(), other.MongoID)
+		assert.Equal(t, mongotest.DeterministicEpoch.Add(time.Hour), other.UpdatedAt)
+	})
+
+	t.Run("restored", func(t *testing.T) {
+		user := &User{}
+		user.InitDocument()
+		assert.NotEqual(t, mongotest.DeterministicEpoch, user.CreatedAt)
+		assert.WithinDuration(t, time.Now(), user.CreatedAt, time.Minute)
+	})
+
+	t.Run("sequence restarts", func(t *testing.T) {
+		mongotest.WithDeterministicIdentity(t)
+
+		user := &User{}
+		user.InitDocument()
+		assert.Equal(t, mongotest.SequentialIDs(1).NewID(), user.MongoID)
+	})
+}
@@ -0,0 +1,500 @@
+// Package mongotest contains helpers for testing code that depends on the mongodb package.
+package mongotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrNotStubbed is returned by a [Spy] without an inner repository for calls that were not stubbed.
+var ErrNotStubbed = errors.New("mongotest: call not stubbed")
+
+type (
+	// Call is a single recorded call of a [Spy].
+	Call struct {
+		// Method is the name of the repository method, e.g. "UpdateMany".
+		Method string
+		// Filter is the filter of the call, or nil for methods without a filter.
+		// Ordered filters of the Where methods are recorded without their key order.
+		Filter bson.M
+		// Data is the update data of UpdateOne, UpdateMany, UpdateManyResult, UpdateOneWhere and UpdateManyWhere.
+		Data primitive.M
+		// Doc is the document or the documents passed to insert, replace, upsert and FindOneOrCreate methods,
+		// the write models passed to BulkWrite, the ids passed to DeleteManyByIDs, the pipeline passed to Aggregate and Watch, the path passed to Distinct, or the projection passed to GetByID.
+		Doc interface{}
+		// Options are the driver options of the call.
+		Options []interface{}
+		// Err is the error returned to the caller. It is nil while the call is running.
+		Err error
+	}
+
+	// Spy records every call made on a repository, optionally answering them with stubbed results.
+	//
+	// Calls are recorded when they start, so concurrent calls are recorded in the order they were made, not in the order they returned.
+	// Filters and update data are copied, so later changes by the caller do not change the recorded calls.
+	//
+	// A Spy is safe for concurrent use. Use [Spy.Reset] to clear the recorded calls and stubs between subtests.
+	Spy[T mongodb.Document[T]] struct {
+		inner mongodb.RepositoryI[T]
+
+		mu    sync.Mutex
+		calls []*Call
+		stubs map[string]stub
+	}
+
+	stub struct {
+		result interface{}
+		err    error
+	}
+
+	spyRepository[T mongodb.Document[T]] struct {
+		spy   *Spy[T]
+		inner mongodb.Forwarder[T]
+	}
+)
+
+// NewSpy creates a Spy and the repository that records into it.
+//
+// Calls that are not stubbed are passed to inner. If inner is nil, they return [ErrNotStubbed].
+func NewSpy[T mongodb.Document[T]](inner mongodb.RepositoryI[T]) (*Spy[T], mongodb.RepositoryI[T]) {
+	spy := &Spy[T]{inner: inner, stubs: map[string]stub{}}
+
+	return spy, &spyRepository[T]{spy: spy, inner: mongodb.Forwarder[T]{RepositoryI: inner}}
+}
+
+// Calls returns the recorded calls of the given method in call order. Without a method name, all calls are returned.
+func (s *Spy[T]) Calls(method ...string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var res []Call
+	for _, call := range s.calls {
+		if len(method) == 0 || call.Method == method[0] {
+			res = append(res, *call)
+		}
+	}
+
+	return res
+}
+
+// CallCount returns how often the given method was called.
+func (s *Spy[T]) CallCount(method string) int {
+	return len(s.Calls(method))
+}
+
+// LastFilter returns the filter of the last call of the given method, or nil if it was not called.
+func (s *Spy[T]) LastFilter(method string) bson.M {
+	calls := s.Calls(method)
+	if len(calls) == 0 {
+		return nil
+	}
+
+	return calls[len(calls)-1].Filter
+}
+
+// Reset removes all recorded calls and stubs.
+func (s *Spy[T]) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = nil
+	s.stubs = map[string]stub{}
+}
+
+// StubFindOne answers all following FindOne calls with the given document and error.
+func (s *Spy[T]) StubFindOne(doc T, err error) {
+	s.setStub("FindOne", doc, err)
+}
+
+// StubFindMany answers all following FindMany calls with the given documents and error.
+func (s *Spy[T]) StubFindMany(docs []T, err error) {
+	s.setStub("FindMany", docs, err)
+}
+
+// StubCountDocuments answers all following CountDocuments calls with the given count and error.
+func (s *Spy[T]) StubCountDocuments(count int, err error) {
+	s.setStub("CountDocuments", count, err)
+}
+
+// StubError answers all following calls of the given method with the zero result and the error.
+// Write methods that return the passed documents still return them.
+func (s *Spy[T]) StubError(method string, err error) {
+	s.setStub(method, nil, err)
+}
+
+func (s *Spy[T]) setStub(method string, result interface{}, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stubs[method] = stub{result: result, err: err}
+}
+
+// call records the call, and answers it from a stub or runs it on the inner repository.
+func (s *Spy[T]) call(inner mongodb.RepositoryI[T], call Call, run func() (interface{}, error)) (interface{}, error) {
+	call.Filter = copyM(call.Filter)
+	call.Data = copyM(call.Data)
+
+	s.mu.Lock()
+	st, stubbed := s.stubs[call.Method]
+	recorded := &call
+	s.calls = append(s.calls, recorded)
+	s.mu.Unlock()
+
+	var result interface{}
+	var err error
+	switch {
+	case stubbed:
+		result, err = st.result, st.err
+	case inner == nil:
+		err = fmt.Errorf("%v: %w", call.Method, ErrNotStubbed)
+	default:
+		result, err = run()
+	}
+
+	s.mu.Lock()
+	recorded.Err = err
+	s.mu.Unlock()
+
+	return result, err
+}
+
+// copyM copies doc with all nested documents and arrays.
+func copyM(doc primitive.M) primitive.M {
+	if doc == nil {
+		return nil
+	}
+
+	// MergeNested copies the documents and arrays of src, and never fails.
+	res, _ := mongodb.MergeBSON(primitive.M{}, doc, mongodb.MergeNested)
+	return res
+}
+
+func optionList[O any](opts []O) []interface{} {
+	res := make([]interface{}, len(opts))
+	for i, opt := range opts {
+		res[i] = opt
+	}
+
+	return res
+}
+
+func resultAs[R any](result interface{}) R {
+	res, _ := result.(R)
+	return res
+}
+
+func (r *spyRepository[T]) FindOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (T, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "FindOne", Filter: filter, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.FindOne(ctx, filter, opts...)
+	})
+	return resultAs[T](res), err
+}
+
+func (r *spyRepository[T]) GetByID(ctx context.Context, id primitive.ObjectID, projection ...string) (T, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "GetByID", Filter: mongodb.MongoIDFilter(id), Doc: projection}, func() (interface{}, error) {
+		return r.inner.GetByID(ctx, id, projection...)
+	})
+	return resultAs[T](res), err
+}
+
+func (r *spyRepository[T]) FindMany(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]T, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "FindMany", Filter: filter, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.FindMany(ctx, filter, opts...)
+	})
+	return resultAs[[]T](res), err
+}
+
+func (r *spyRepository[T]) FindManyN(ctx context.Context, filter bson.M, expectedCount int, opts ...*options.FindOptions) ([]T, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "FindManyN", Filter: filter, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.FindManyN(ctx, filter, expectedCount, opts...)
+	})
+	return resultAs[[]T](res), err
+}
+
+func (r *spyRepository[T]) FindManyRaw(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]bson.Raw, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "FindManyRaw", Filter: filter, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.FindManyRaw(ctx, filter, opts...)
+	})
+	return resultAs[[]bson.Raw](res), err
+}
+
+func (r *spyRepository[T]) FindCursor(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "FindCursor", Filter: filter, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.FindCursor(ctx, filter, opts...)
+	})
+	return resultAs[*mongo.Cursor](res), err
+}
+
+func (r *spyRepository[T]) FindManyParallel(ctx context.Context, filter bson.M, parallelism int, fn func([]T) error) error {
+	_, err := r.spy.call(r.inner.RepositoryI, Call{Method: "FindManyParallel", Filter: filter}, func() (interface{}, error) {
+		return nil, r.inner.FindManyParallel(ctx, filter, parallelism, fn)
+	})
+	return err
+}
+
+func (r *spyRepository[T]) InsertOne(ctx context.Context, doc T, opts ...*options.InsertOneOptions) (T, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "InsertOne", Doc: doc, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.InsertOne(ctx, doc, opts...)
+	})
+	if res == nil {
+		return doc, err
+	}
+	return resultAs[T](res), err
+}
+
+func (r *spyRepository[T]) InsertMany(ctx context.Context, docs []T, opts ...*options.InsertManyOptions) ([]T, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "InsertMany", Doc: docs, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.InsertMany(ctx, docs, opts...)
+	})
+	if res == nil {
+		return docs, err
+	}
+	return resultAs[[]T](res), err
+}
+
+func (r *spyRepository[T]) InsertManyResult(ctx context.Context, docs []T, opts ...*options.InsertManyOptions) ([]T, *mongo.InsertManyResult, error) {
+	var result *mongo.InsertManyResult
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "InsertManyResult", Doc: docs, Options: optionList(opts)}, func() (interface{}, error) {
+		inserted, insertResult, err := r.inner.InsertManyResult(ctx, docs, opts...)
+		result = insertResult
+		return inserted, err
+	})
+	if res == nil {
+		return docs, result, err
+	}
+	return resultAs[[]T](res), result, err
+}
+
+func (r *spyRepository[T]) UpdateOne(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "UpdateOne", Filter: filter, Data: data, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.UpdateOne(ctx, filter, data, opts...)
+	})
+	return resultAs[*mongo.UpdateResult](res), err
+}
+
+func (r *spyRepository[T]) UpdateMany(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) error {
+	_, err := r.spy.call(r.inner.RepositoryI, Call{Method: "UpdateMany", Filter: filter, Data: data, Options: optionList(opts)}, func() (interface{}, error) {
+		return nil, r.inner.UpdateMany(ctx, filter, data, opts...)
+	})
+	return err
+}
+
+func (r *spyRepository[T]) UpdateManyResult(ctx context.Context, filter bson.M, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "UpdateManyResult", Filter: filter, Data: data, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.UpdateManyResult(ctx, filter, data, opts...)
+	})
+	return resultAs[*mongo.UpdateResult](res), err
+}
+
+func (r *spyRepository[T]) ReplaceOne(ctx context.Context, filter bson.M, doc T, opts ...*options.ReplaceOptions) (T, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "ReplaceOne", Filter: filter, Doc: doc, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.ReplaceOne(ctx, filter, doc, opts...)
+	})
+	if res == nil {
+		return doc, err
+	}
+	return resultAs[T](res), err
+}
+
+func (r *spyRepository[T]) FindOneOrCreate(ctx context.Context, filter bson.M, defaultDoc T, opts ...*options.FindOneAndUpdateOptions) (T, bool, error) {
+	var created bool
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "FindOneOrCreate", Filter: filter, Doc: defaultDoc, Options: optionList(opts)}, func() (interface{}, error) {
+		doc, ok, err := r.inner.FindOneOrCreate(ctx, filter, defaultDoc, opts...)
+		created = ok
+		return doc, err
+	})
+	return resultAs[T](res), created, err
+}
+
+func (r *spyRepository[T]) UpsertManyByKey(ctx context.Context, docs []T, keyFields []string, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "UpsertManyByKey", Doc: docs, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.UpsertManyByKey(ctx, docs, keyFields, opts...)
+	})
+	return resultAs[*mongo.BulkWriteResult](res), err
+}
+
+func (r *spyRepository[T]) FindOneWhere(ctx context.Context, filter mongodb.Filter, opts ...*options.FindOneOptions) (T, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "FindOneWhere", Filter: filter.M(), Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.FindOneWhere(ctx, filter, opts...)
+	})
+	return resultAs[T](res), err
+}
+
+func (r *spyRepository[T]) FindManyWhere(ctx context.Context, filter mongodb.Filter, opts ...*options.FindOptions) ([]T, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "FindManyWhere", Filter: filter.M(), Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.FindManyWhere(ctx, filter, opts...)
+	})
+	return resultAs[[]T](res), err
+}
+
+func (r *spyRepository[T]) UpdateOneWhere(ctx context.Context, filter mongodb.Filter, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "UpdateOneWhere", Filter: filter.M(), Data: data, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.UpdateOneWhere(ctx, filter, data, opts...)
+	})
+	return resultAs[*mongo.UpdateResult](res), err
+}
+
+func (r *spyRepository[T]) UpdateManyWhere(ctx context.Context, filter mongodb.Filter, data primitive.M, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "UpdateManyWhere", Filter: filter.M(), Data: data, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.UpdateManyWhere(ctx, filter, data, opts...)
+	})
+	return resultAs[*mongo.UpdateResult](res), err
+}
+
+func (r *spyRepository[T]) DeleteOneWhere(ctx context.Context, filter mongodb.Filter, opts ...*options.DeleteOptions) error {
+	_, err := r.spy.call(r.inner.RepositoryI, Call{Method: "DeleteOneWhere", Filter: filter.M(), Options: optionList(opts)}, func() (interface{}, error) {
+		return nil, r.inner.DeleteOneWhere(ctx, filter, opts...)
+	})
+	return err
+}
+
+func (r *spyRepository[T]) DeleteManyWhere(ctx context.Context, filter mongodb.Filter, opts ...*options.DeleteOptions) (int, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "DeleteManyWhere", Filter: filter.M(), Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.DeleteManyWhere(ctx, filter, opts...)
+	})
+	return resultAs[int](res), err
+}
+
+func (r *spyRepository[T]) CountWhere(ctx context.Context, filter mongodb.Filter, opts ...*options.CountOptions) (int, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "CountWhere", Filter: filter.M(), Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.CountWhere(ctx, filter, opts...)
+	})
+	return resultAs[int](res), err
+}
+
+func (r *spyRepository[T]) DeleteOne(ctx context.Context, filter bson.M, opts ...*options.DeleteOptions) error {
+	_, err := r.spy.call(r.inner.RepositoryI, Call{Method: "DeleteOne", Filter: filter, Options: optionList(opts)}, func() (interface{}, error) {
+		return nil, r.inner.DeleteOne(ctx, filter, opts...)
+	})
+	return err
+}
+
+func (r *spyRepository[T]) DeleteMany(ctx context.Context, filter bson.M, opts ...*options.DeleteOptions) (int, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "DeleteMany", Filter: filter, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.DeleteMany(ctx, filter, opts...)
+	})
+	return resultAs[int](res), err
+}
+
+func (r *spyRepository[T]) DeleteManyByIDs(ctx context.Context, ids []primitive.ObjectID, chunkSize int) (int, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "DeleteManyByIDs", Doc: ids}, func() (interface{}, error) {
+		return r.inner.DeleteManyByIDs(ctx, ids, chunkSize)
+	})
+	return resultAs[int](res), err
+}
+
+func (r *spyRepository[T]) DeleteManyAudited(ctx context.Context, filter bson.M, batchSize int, onBatch func(deletedIDs []primitive.ObjectID) error) (int, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "DeleteManyAudited", Filter: filter}, func() (interface{}, error) {
+		return r.inner.DeleteManyAudited(ctx, filter, batchSize, onBatch)
+	})
+	return resultAs[int](res), err
+}
+
+func (r *spyRepository[T]) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "BulkWrite", Doc: models, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.BulkWrite(ctx, models, opts...)
+	})
+	return resultAs[*mongo.BulkWriteResult](res), err
+}
+
+func (r *spyRepository[T]) Watch(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "Watch", Doc: pipeline, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.Watch(ctx, pipeline, opts...)
+	})
+	return resultAs[*mongo.ChangeStream](res), err
+}
+
+func (r *spyRepository[T]) Drop(ctx context.Context) error {
+	_, err := r.spy.call(r.inner.RepositoryI, Call{Method: "Drop"}, func() (interface{}, error) {
+		return nil, r.inner.Drop(ctx)
+	})
+	return err
+}
+
+func (r *spyRepository[T]) Stats(ctx context.Context) (mongodb.CollectionStats, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "Stats"}, func() (interface{}, error) {
+		return r.inner.Stats(ctx)
+	})
+	return resultAs[mongodb.CollectionStats](res), err
+}
+
+func (r *spyRepository[T]) Distinct(ctx context.Context, path mongodb.FieldPath, filter bson.M, opts ...*options.DistinctOptions) ([]interface{}, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "Distinct", Filter: filter, Doc: path, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.Distinct(ctx, path, filter, opts...)
+	})
+	return resultAs[[]interface{}](res), err
+}
+
+func (r *spyRepository[T]) Verify(ctx context.Context) error {
+	_, err := r.spy.call(r.inner.RepositoryI, Call{Method: "Verify"}, func() (interface{}, error) {
+		return nil, r.inner.Verify(ctx)
+	})
+	return err
+}
+
+func (r *spyRepository[T]) Aggregate(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "Aggregate", Doc: pipeline, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.Aggregate(ctx, pipeline, opts...)
+	})
+	return resultAs[*mongo.Cursor](res), err
+}
+
+func (r *spyRepository[T]) CountDocuments(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "CountDocuments", Filter: filter, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.CountDocuments(ctx, filter, opts...)
+	})
+	return resultAs[int](res), err
+}
+
+// WithSession binds the inner repository to the session. Calls of the returned repository are recorded by the same Spy.
+func (r *spyRepository[T]) WithSession(sess mongo.Session) mongodb.RepositoryI[T] {
+	if r.inner.RepositoryI == nil {
+		return r
+	}
+
+	return &spyRepository[T]{spy: r.spy, inner: mongodb.Forwarder[T]{RepositoryI: mongodb.WithSession(r.inner.RepositoryI, sess)}}
+}
+
+func (r *spyRepository[T]) CreateIndex(ctx context.Context, keys bson.D, opts ...*options.IndexOptions) (string, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "CreateIndex", Doc: keys, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.CreateIndex(ctx, keys, opts...)
+	})
+	return resultAs[string](res), err
+}
+
+func (r *spyRepository[T]) CreateIndexes(ctx context.Context, models []mongo.IndexModel) ([]string, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "CreateIndexes", Doc: models}, func() (interface{}, error) {
+		return r.inner.CreateIndexes(ctx, models)
+	})
+	return resultAs[[]string](res), err
+}
+
+func (r *spyRepository[T]) DropIndex(ctx context.Context, name string) error {
+	_, err := r.spy.call(r.inner.RepositoryI, Call{Method: "DropIndex", Doc: name}, func() (interface{}, error) {
+		return nil, r.inner.DropIndex(ctx, name)
+	})
+	return err
+}
+
+func (r *spyRepository[T]) ListIndexes(ctx context.Context) ([]mongodb.IndexInfo, error) {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "ListIndexes"}, func() (interface{}, error) {
+		return r.inner.ListIndexes(ctx)
+	})
+	return resultAs[[]mongodb.IndexInfo](res), err
+}
+
+func (r *spyRepository[T]) SetIndexExpireAfter(ctx context.Context, name string, expireAfter time.Duration) error {
+	_, err := r.spy.call(r.inner.RepositoryI, Call{Method: "SetIndexExpireAfter", Doc: name, Options: []interface{}{expireAfter}}, func() (interface{}, error) {
+		return nil, r.inner.SetIndexExpireAfter(ctx, name, expireAfter)
+	})
+	return err
+}
@@ -0,0 +1,58 @@
+package mongotest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongotest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type User struct {
+	mongodb.BaseModel `bson:",inline"`
+	CompanyID         primitive.ObjectID `bson:"companyID"`
+	Name              string             `bson:"name"`
+	Active            bool               `bson:"active"`
+}
+
+// deactivateCompany is the code under test.
+func deactivateCompany(ctx context.Context, repo mongodb.RepositoryI[*User], companyID primitive.ObjectID) error {
+	return repo.UpdateMany(ctx, bson.M{"companyID": companyID}, bson.M{"active": false})
+}
+
+func ExampleSpy() {
+	spy, repo := mongotest.NewSpy[*User](nil)
+	spy.StubError("UpdateMany", nil)
+
+	companyID := primitive.NewObjectID()
+	_ = deactivateCompany(context.Background(), repo, companyID)
+
+	fmt.Println(spy.CallCount("UpdateMany"))
+	fmt.Println(spy.LastFilter("UpdateMany")["companyID"] == companyID)
+	fmt.Println(spy.Calls("UpdateMany")[0].Data)
+	// Output:
+	// 1
+	// true
+	// map[active:false]
+}
+
+func ExampleSpy_StubFindOne() {
+	spy, repo := mongotest.NewSpy[*User](nil)
+	spy.StubFindOne(&User{Name: "Willy"}, nil)
+
+	user, err := repo.FindOne(context.Background(), bson.M{"name": "Willy"})
+	fmt.Println(user.Name, err)
+
+	_, err = repo.CountDocuments(context.Background(), bson.M{})
+	fmt.Println(errors.Is(err, mongotest.ErrNotStubbed))
+
+	spy.Reset()
+	fmt.Println(len(spy.Calls()))
+	// Output:
+	// Willy <nil>
+	// true
+	// 0
+}
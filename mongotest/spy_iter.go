@@ -0,0 +1,27 @@
+//go:build go1.23
+
+package mongotest
+
+import (
+	"context"
+	"iter"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *spyRepository[T]) FindIter(ctx context.Context, filter bson.M, opts ...*options.FindOptions) iter.Seq2[T, error] {
+	res, err := r.spy.call(r.inner.RepositoryI, Call{Method: "FindIter", Filter: filter, Options: optionList(opts)}, func() (interface{}, error) {
+		return r.inner.FindIter(ctx, filter, opts...), nil
+	})
+	if err != nil {
+		return func(yield func(T, error) bool) {
+			var zero T
+			yield(zero, err)
+		}
+	}
+	if seq := resultAs[iter.Seq2[T, error]](res); seq != nil {
+		return seq
+	}
+	return func(yield func(T, error) bool) {}
+}
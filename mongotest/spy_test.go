@@ -0,0 +1,63 @@
+package mongotest_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongotest"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSpyIsSafeForConcurrentUse(t *testing.T) {
+	spy, repo := mongotest.NewSpy[*User](nil)
+	spy.StubCountDocuments(3, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			count, err := repo.CountDocuments(context.Background(), bson.M{"i": i})
+			assert.NoError(t, err)
+			assert.Equal(t, 3, count)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 50, spy.CallCount("CountDocuments"))
+	assert.Equal(t, 0, spy.CallCount("FindMany"))
+}
+
+func TestSpyRecordsCallsOnEntry(t *testing.T) {
+	errSlow := errors.New("slow")
+	chaos := mongotest.NewChaosRepository[*User](nil, 1)
+	chaos.AddRule(mongotest.ChaosRule{Operations: []string{"FindOne"}, Delay: 50 * time.Millisecond, Err: errSlow})
+	spy, repo := mongotest.NewSpy[*User](chaos)
+	spy.StubCountDocuments(1, nil)
+
+	done := make(chan error)
+	go func() {
+		_, err := repo.FindOne(context.Background(), bson.M{"name": "slow"})
+		done <- err
+	}()
+	assert.Eventually(t, func() bool { return spy.CallCount("FindOne") == 1 }, time.Second, time.Millisecond)
+
+	filter := bson.M{"tags": bson.M{"$in": bson.A{"a"}}}
+	_, err := repo.CountDocuments(context.Background(), filter)
+	assert.NoError(t, err)
+	filter["tags"].(bson.M)["$in"] = bson.A{"b"}
+	filter["other"] = 1
+
+	assert.ErrorIs(t, <-done, errSlow)
+	calls := spy.Calls()
+	if assert.Len(t, calls, 2) {
+		assert.Equal(t, "FindOne", calls[0].Method)
+		assert.ErrorIs(t, calls[0].Err, errSlow)
+		assert.Equal(t, "CountDocuments", calls[1].Method)
+		assert.Equal(t, bson.M{"tags": bson.M{"$in": bson.A{"a"}}}, calls[1].Filter)
+	}
+}
@@ -0,0 +1,220 @@
+package mongotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SuiteDocument are the documents [RunRepositorySuite] can check, which is every document embedding [mongodb.BaseModel].
+type SuiteDocument[T any] interface {
+	mongodb.Document[T]
+	GetMongoID() primitive.ObjectID
+	GetCreatedAt() time.Time
+	GetUpdatedAt() time.Time
+}
+
+// RunRepositorySuite checks that a RepositoryI implementation behaves like [mongodb.Repository].
+//
+// factory is called for every subtest and must return a repository for an empty collection.
+// newDoc must return a new, uninitialized document that is distinct for every i.
+func RunRepositorySuite[T SuiteDocument[T]](t *testing.T, factory func(t *testing.T) mongodb.RepositoryI[T], newDoc func(i int) T) {
+	ctx := context.Background()
+
+	t.Run("InsertOne and FindOne round trip", func(t *testing.T) {
+		repo := factory(t)
+
+		inserted, err := repo.InsertOne(ctx, newDoc(0))
+		require.NoError(t, err)
+		require.False(t, inserted.GetMongoID().IsZero(), "InsertOne must set the MongoID")
+
+		found, err := repo.FindOne(ctx, mongodb.MongoIDFilter(inserted.GetMongoID()))
+		require.NoError(t, err)
+		assert.Equal(t, inserted.GetMongoID(), found.GetMongoID())
+	})
+
+	t.Run("InsertOne sets timestamps", func(t *testing.T) {
+		repo := factory(t)
+
+		before := time.Now().Add(-time.Second)
+		inserted, err := repo.InsertOne(ctx, newDoc(0))
+		require.NoError(t, err)
+
+		assert.True(t, inserted.GetCreatedAt().After(before), "createdAt must be set to the current time")
+		assert.Equal(t, inserted.GetCreatedAt(), inserted.GetUpdatedAt())
+	})
+
+	t.Run("InsertMany inserts all documents", func(t *testing.T) {
+		repo := factory(t)
+
+		docs, err := repo.InsertMany(ctx, []T{newDoc(0), newDoc(1), newDoc(2)})
+		require.NoError(t, err)
+		require.Len(t, docs, 3)
+
+		ids := map[primitive.ObjectID]bool{}
+		for _, doc := range docs {
+			ids[doc.GetMongoID()] = true
+		}
+		assert.Len(t, ids, 3, "every document must get its own MongoID")
+
+		found, err := repo.FindMany(ctx, bson.M{})
+		require.NoError(t, err)
+		assert.Len(t, found, 3)
+	})
+
+	t.Run("InsertMany without documents", func(t *testing.T) {
+		repo := factory(t)
+
+		docs, err := repo.InsertMany(ctx, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, docs)
+	})
+
+	t.Run("BulkWrite without operations", func(t *testing.T) {
+		repo := factory(t)
+
+		res, err := repo.BulkWrite(ctx, nil)
+		assert.NoError(t, err)
+		assert.NotNil(t, res)
+	})
+
+	t.Run("DeleteOne rejects empty filter", func(t *testing.T) {
+		repo := factory(t)
+
+		_, err := repo.InsertOne(ctx, newDoc(0))
+		require.NoError(t, err)
+
+		assert.Error(t, repo.DeleteOne(ctx, bson.M{}))
+
+		count, err := repo.CountDocuments(ctx, bson.M{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("UpdateOne bumps updatedAt", func(t *testing.T) {
+		repo := factory(t)
+
+		inserted, err := repo.InsertOne(ctx, newDoc(0))
+		require.NoError(t, err)
+		filter := mongodb.MongoIDFilter(inserted.GetMongoID())
+
+		before, err := repo.FindOne(ctx, filter)
+		require.NoError(t, err)
+
+		time.Sleep(10 * time.Millisecond)
+		res, err := repo.UpdateOne(ctx, filter, bson.M{"suiteMarker": 1})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), res.MatchedCount)
+
+		after, err := repo.FindOne(ctx, filter)
+		require.NoError(t, err)
+		assert.True(t, after.GetUpdatedAt().After(before.GetUpdatedAt()), "updatedAt must be bumped")
+		assert.Equal(t, before.GetCreatedAt(), after.GetCreatedAt(), "createdAt must not change")
+	})
+
+	t.Run("UpdateMany updates all matching documents", func(t *testing.T) {
+		repo := factory(t)
+
+		docs, err := repo.InsertMany(ctx, []T{newDoc(0), newDoc(1), newDoc(2)})
+		require.NoError(t, err)
+		ids := []primitive.ObjectID{docs[0].GetMongoID(), docs[1].GetMongoID()}
+
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, repo.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, bson.M{"suiteMarker": 1}))
+
+		count, err := repo.CountDocuments(ctx, bson.M{"suiteMarker": 1})
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		updated, err := repo.FindOne(ctx, mongodb.MongoIDFilter(ids[0]))
+		require.NoError(t, err)
+		assert.True(t, updated.GetUpdatedAt().After(docs[0].GetUpdatedAt()), "updatedAt must be bumped")
+	})
+
+	t.Run("Aggregate runs the pipeline", func(t *testing.T) {
+		repo := factory(t)
+
+		docs, err := repo.InsertMany(ctx, []T{newDoc(0), newDoc(1), newDoc(2)})
+		require.NoError(t, err)
+
+		cur, err := repo.Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"_id": bson.M{"$ne": docs[0].GetMongoID()}}}},
+			{{Key: "$count", Value: "n"}},
+		})
+		require.NoError(t, err)
+
+		var res []struct {
+			N int `bson:"n"`
+		}
+		require.NoError(t, cur.All(ctx, &res))
+		require.Len(t, res, 1)
+		assert.Equal(t, 2, res[0].N)
+	})
+
+	t.Run("ReplaceOne replaces the document", func(t *testing.T) {
+		repo := factory(t)
+
+		inserted, err := repo.InsertOne(ctx, newDoc(0))
+		require.NoError(t, err)
+		filter := mongodb.MongoIDFilter(inserted.GetMongoID())
+
+		_, err = repo.ReplaceOne(ctx, filter, inserted)
+		require.NoError(t, err)
+
+		count, err := repo.CountDocuments(ctx, bson.M{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("CountDocuments and DeleteMany are accurate", func(t *testing.T) {
+		repo := factory(t)
+
+		docs, err := repo.InsertMany(ctx, []T{newDoc(0), newDoc(1), newDoc(2), newDoc(3)})
+		require.NoError(t, err)
+
+		count, err := repo.CountDocuments(ctx, bson.M{})
+		require.NoError(t, err)
+		assert.Equal(t, 4, count)
+
+		count, err = repo.CountDocuments(ctx, mongodb.MongoIDFilter(docs[0].GetMongoID()))
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		require.NoError(t, repo.DeleteOne(ctx, mongodb.MongoIDFilter(docs[0].GetMongoID())))
+
+		deleted, err := repo.DeleteMany(ctx, bson.M{})
+		require.NoError(t, err)
+		assert.Equal(t, 3, deleted)
+	})
+
+	t.Run("not found semantics", func(t *testing.T) {
+		repo := factory(t)
+		filter := mongodb.MongoIDFilter(primitive.NewObjectID())
+
+		_, err := repo.FindOne(ctx, filter)
+		assert.True(t, errors.Is(err, mongo.ErrNoDocuments), "FindOne must return mongo.ErrNoDocuments, got %v", err)
+
+		docs, err := repo.FindMany(ctx, filter)
+		assert.NoError(t, err)
+		assert.Empty(t, docs)
+
+		_, err = repo.ReplaceOne(ctx, filter, newDoc(0))
+		assert.True(t, errors.Is(err, mongodb.ErrNotFound), "ReplaceOne must return mongodb.ErrNotFound, got %v", err)
+
+		count, err := repo.CountDocuments(ctx, filter)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		deleted, err := repo.DeleteMany(ctx, filter)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, deleted)
+	})
+}
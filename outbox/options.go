@@ -0,0 +1,128 @@
+package outbox
+
+import "time"
+
+type (
+	// OutboxOption configures an [Outbox], see [NewOutbox].
+	OutboxOption interface {
+		apply(*outboxOption)
+	}
+
+	// RelayOption configures a [Relay], see [NewRelay].
+	RelayOption interface {
+		apply(*relayOption)
+	}
+)
+
+type (
+	outboxOption struct {
+		collection string
+	}
+
+	relayOption struct {
+		pollInterval   time.Duration
+		batchSize      int
+		maxAttempts    int
+		initialBackoff time.Duration
+		maxBackoff     time.Duration
+		lease          time.Duration
+		logf           func(format string, args ...interface{})
+	}
+)
+
+type collectionOption string
+
+func (value collectionOption) apply(o *outboxOption) {
+	o.collection = string(value)
+}
+
+// WithCollection sets the collection of the default database that stores the events, the default is "outbox".
+func WithCollection(name string) OutboxOption {
+	return collectionOption(name)
+}
+
+type pollIntervalOption time.Duration
+
+func (value pollIntervalOption) apply(o *relayOption) {
+	if value > 0 {
+		o.pollInterval = time.Duration(value)
+	}
+}
+
+// WithPollInterval sets how often [Relay.Run] looks for pending events, the default is one second.
+func WithPollInterval(interval time.Duration) RelayOption {
+	return pollIntervalOption(interval)
+}
+
+type batchSizeOption int
+
+func (value batchSizeOption) apply(o *relayOption) {
+	if value > 0 {
+		o.batchSize = int(value)
+	}
+}
+
+// WithBatchSize sets the maximum number of events a single [Relay.RunOnce] publishes, the default is 100.
+func WithBatchSize(n int) RelayOption {
+	return batchSizeOption(n)
+}
+
+type maxAttemptsOption int
+
+func (value maxAttemptsOption) apply(o *relayOption) {
+	if value > 0 {
+		o.maxAttempts = int(value)
+	}
+}
+
+// WithMaxAttempts sets how often the publication of an event is attempted before it is marked as failed, the default is 10.
+func WithMaxAttempts(n int) RelayOption {
+	return maxAttemptsOption(n)
+}
+
+type backoffOption struct {
+	initial time.Duration
+	max     time.Duration
+}
+
+func (value backoffOption) apply(o *relayOption) {
+	if value.initial > 0 {
+		o.initialBackoff = value.initial
+	}
+	if value.max > 0 {
+		o.maxBackoff = value.max
+	}
+}
+
+// WithBackoff sets the delay before the first retry of a failed publication, which doubles with every further attempt
+// up to max. The defaults are one second and five minutes.
+func WithBackoff(initial, max time.Duration) RelayOption {
+	return backoffOption{initial: initial, max: max}
+}
+
+type leaseOption time.Duration
+
+func (value leaseOption) apply(o *relayOption) {
+	if value > 0 {
+		o.lease = time.Duration(value)
+	}
+}
+
+// WithLease sets how long an event claimed by a relay is hidden from other relays, the default is one minute.
+// If a relay crashes while publishing, the event is published again once the lease expired.
+// The lease should be much longer than a publication takes.
+func WithLease(lease time.Duration) RelayOption {
+	return leaseOption(lease)
+}
+
+type loggerOption func(format string, args ...interface{})
+
+func (value loggerOption) apply(o *relayOption) {
+	o.logf = value
+}
+
+// WithLogger sets the function that logs the errors of [Relay.Run], the default is log.Printf.
+// Passing nil disables logging.
+func WithLogger(logf func(format string, args ...interface{})) RelayOption {
+	return loggerOption(logf)
+}
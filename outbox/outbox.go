@@ -0,0 +1,181 @@
+// Package outbox implements the transactional outbox pattern: events are written to an outbox collection in the same
+// transaction as the business data, and a [Relay] publishes them afterwards, e.g. to a message broker.
+//
+// Events are published at least once, they may be published again if a relay fails after publishing an event,
+// but before marking it as sent. Consumers should deduplicate them by [OutboxEvent.IdempotencyKey].
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const defaultCollection = "outbox"
+
+// The statuses of an [OutboxEvent].
+const (
+	// StatusPending events wait for their first or next publication.
+	StatusPending EventStatus = "pending"
+	// StatusPublishing events are claimed by a relay, until their lease expires.
+	StatusPublishing EventStatus = "publishing"
+	// StatusSent events were published.
+	StatusSent EventStatus = "sent"
+	// StatusFailed events reached the maximum number of attempts, see [WithMaxAttempts]. They are not retried.
+	StatusFailed EventStatus = "failed"
+)
+
+// ErrNoTransaction is returned by [Outbox.StageEvent] if ctx does not carry a running transaction.
+var ErrNoTransaction = errors.New("outbox: no transaction")
+
+type (
+	// EventStatus is the publication status of an [OutboxEvent].
+	EventStatus string
+
+	// OutboxEvent is an event staged in the outbox collection.
+	OutboxEvent struct {
+		mongodb.BaseModel `bson:",inline"`
+		Topic             string      `bson:"topic"`
+		Payload           bson.Raw    `bson:"payload"`
+		Status            EventStatus `bson:"status"`
+		// Attempts is the number of failed publications.
+		Attempts int `bson:"attempts"`
+		// NextAttemptAt is the earliest time the event is published, or, while publishing, the end of the lease.
+		NextAttemptAt time.Time `bson:"nextAttemptAt"`
+		// LastError is the error of the last failed publication.
+		LastError string `bson:"lastError,omitempty"`
+		// SentAt is the time the event was published.
+		SentAt *time.Time `bson:"sentAt,omitempty"`
+	}
+
+	// Outbox stages events in the outbox collection of a DataStore.
+	Outbox struct {
+		store  *datastore.DataStore
+		events mongodb.RepositoryI[*OutboxEvent]
+	}
+
+	// Backlog reports the events of the outbox collection that are not sent yet.
+	Backlog struct {
+		// Pending is the number of events that wait for their first or next publication, including those being published.
+		Pending int
+		// Retrying is the number of pending events that failed at least once.
+		Retrying int
+		// Failed is the number of events that reached the maximum number of attempts.
+		Failed int
+		// OldestPending is the creation time of the oldest pending event, zero if there is none.
+		OldestPending time.Time
+	}
+)
+
+// IdempotencyKey returns the key consumers should deduplicate the event by. It is the same for every publication of the event.
+func (e *OutboxEvent) IdempotencyKey() string {
+	return e.MongoID.Hex()
+}
+
+// Indexes declares the index the relay uses to find the events that are due, see [mongodb.EnsureIndexes].
+func (*OutboxEvent) Indexes() []mongo.IndexModel {
+	return []mongo.IndexModel{{Keys: mongodb.IndexKeys("status", "nextAttemptAt")}}
+}
+
+// NewOutbox creates an Outbox for the events in the outbox collection of the default database of store, see [WithCollection].
+func NewOutbox(store *datastore.DataStore, opts ...OutboxOption) *Outbox {
+	ops := &outboxOption{collection: defaultCollection}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+
+	return &Outbox{store: store, events: datastore.RepositoryFor[*OutboxEvent](store, ops.collection)}
+}
+
+// EnsureIndexes creates the index of the outbox collection, if it does not exist. It is intended to be called at startup.
+func (o *Outbox) EnsureIndexes(ctx context.Context) error {
+	indexes, err := mongodb.Capability[mongodb.IndexManager](o.events)
+	if err != nil {
+		return fmt.Errorf("%v: %w", "outbox.EnsureIndexes", err)
+	}
+	if _, err := mongodb.EnsureIndexes[*OutboxEvent](ctx, indexes); err != nil {
+		return fmt.Errorf("%v: %w", "outbox.EnsureIndexes", err)
+	}
+	return nil
+}
+
+// StageEvent writes an event with the given topic and payload to the outbox collection.
+//
+// It must be called with the ctx of a [datastore.DataStore.WithTransaction], so that the event is committed atomically
+// with the business writes of the transaction, and discarded if the transaction aborts. Otherwise [ErrNoTransaction]
+// is returned. payload is marshaled to a BSON document, so it has to be a struct, a map or a bson.D.
+func (o *Outbox) StageEvent(ctx context.Context, topic string, payload any) error {
+	if !inTransaction(ctx) {
+		return fmt.Errorf("%v: %w", "outbox.StageEvent", ErrNoTransaction)
+	}
+
+	data, err := bson.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%v: marshaling payload: %w", "outbox.StageEvent", err)
+	}
+
+	event := &OutboxEvent{Topic: topic, Payload: data, Status: StatusPending, NextAttemptAt: time.Now()}
+	if _, err := o.events.InsertOne(ctx, event); err != nil {
+		return fmt.Errorf("%v: %w", "outbox.StageEvent", err)
+	}
+
+	return nil
+}
+
+// inTransaction reports whether ctx carries a session with a running transaction.
+func inTransaction(ctx context.Context) bool {
+	session := mongo.SessionFromContext(ctx)
+	if session == nil {
+		return false
+	}
+
+	// The driver only exposes the transaction state through its unstable XSession interface.
+	// Sessions that do not implement it are trusted to run a transaction.
+	if xs, ok := session.(mongo.XSession); ok {
+		return xs.ClientSession().TransactionRunning()
+	}
+	return true
+}
+
+// Backlog returns the number of events that are not sent yet, e.g. to export them as metrics.
+func (o *Outbox) Backlog(ctx context.Context) (Backlog, error) {
+	cur, err := o.events.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"status": bson.M{"$in": bson.A{StatusPending, StatusPublishing, StatusFailed}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", StatusFailed}}, StatusFailed, StatusPending}},
+			"count":    bson.M{"$sum": 1},
+			"retrying": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$attempts", 0}}, 1, 0}}},
+			"oldest":   bson.M{"$min": "$createdAt"},
+		}}},
+	})
+	if err != nil {
+		return Backlog{}, fmt.Errorf("%v: %w", "outbox.Backlog", err)
+	}
+
+	var groups []struct {
+		Status   EventStatus `bson:"_id"`
+		Count    int         `bson:"count"`
+		Retrying int         `bson:"retrying"`
+		Oldest   time.Time   `bson:"oldest"`
+	}
+	if err := cur.All(ctx, &groups); err != nil {
+		return Backlog{}, fmt.Errorf("%v: %w", "outbox.Backlog", err)
+	}
+
+	var res Backlog
+	for _, group := range groups {
+		if group.Status == StatusFailed {
+			res.Failed = group.Count
+			continue
+		}
+		res.Pending, res.Retrying, res.OldestPending = group.Count, group.Retrying, group.Oldest
+	}
+
+	return res, nil
+}
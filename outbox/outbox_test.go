@@ -0,0 +1,189 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/DataInsightHub/Go-Mongo-Helper/outbox"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	Order struct {
+		mongodb.BaseModel `bson:",inline"`
+		Number            string `bson:"number"`
+	}
+
+	OrderPlaced struct {
+		Number string `bson:"number"`
+	}
+)
+
+func newTestDataStore(t *testing.T) *datastore.DataStore {
+	t.Helper()
+
+	store, err := datastore.NewDataStore("mongodb://localhost:27017", "testdb_outbox")
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	t.Cleanup(func() {
+		store.Database.Drop(context.Background())
+		store.Disconnect()
+	})
+
+	return store
+}
+
+// placeOrder inserts an order and stages its event in a single transaction, which is aborted if abort is set.
+func placeOrder(ctx context.Context, store *datastore.DataStore, box *outbox.Outbox, number string, abort bool) error {
+	orders := datastore.RepositoryFor[*Order](store, "orders")
+
+	return store.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, err := orders.InsertOne(ctx, &Order{Number: number}); err != nil {
+			return err
+		}
+		if err := box.StageEvent(ctx, "orders.placed", OrderPlaced{Number: number}); err != nil {
+			return err
+		}
+		if abort {
+			return errors.New("abort")
+		}
+		return nil
+	})
+}
+
+func TestStageEventWithoutTransaction(t *testing.T) {
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+
+	box := outbox.NewOutbox(&datastore.DataStore{Client: client, Database: client.Database("testdb_outbox")})
+
+	err = box.StageEvent(ctx, "orders.placed", OrderPlaced{Number: "1"})
+	assert.ErrorIs(t, err, outbox.ErrNoTransaction)
+
+	session, err := client.StartSession()
+	if err != nil {
+		t.Fatalf("Error starting session: %v", err)
+	}
+	defer session.EndSession(ctx)
+
+	err = box.StageEvent(mongo.NewSessionContext(ctx, session), "orders.placed", OrderPlaced{Number: "1"})
+	assert.ErrorIs(t, err, outbox.ErrNoTransaction, "a session without transaction is rejected")
+}
+
+func TestStageEvent(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDataStore(t)
+	box := outbox.NewOutbox(store)
+	if err := box.EnsureIndexes(ctx); err != nil {
+		t.Fatalf("Error on creating indexes: %v", err)
+	}
+
+	err := placeOrder(ctx, store, box, "1", true)
+	assert.EqualError(t, err, "abort")
+
+	backlog, err := box.Backlog(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, outbox.Backlog{}, backlog, "the event of the aborted transaction is not visible")
+
+	assert.NoError(t, placeOrder(ctx, store, box, "2", false))
+
+	backlog, err = box.Backlog(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, backlog.Pending)
+	assert.False(t, backlog.OldestPending.IsZero())
+
+	var published []OrderPlaced
+	relay := outbox.NewRelay(box, func(ctx context.Context, event *outbox.OutboxEvent) error {
+		var payload OrderPlaced
+		if err := bson.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		assert.Equal(t, "orders.placed", event.Topic)
+		assert.NotEmpty(t, event.IdempotencyKey())
+		published = append(published, payload)
+		return nil
+	})
+
+	n, err := relay.RunOnce(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []OrderPlaced{{Number: "2"}}, published)
+
+	n, err = relay.RunOnce(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n, "sent events are not published again")
+
+	backlog, err = box.Backlog(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, outbox.Backlog{}, backlog)
+}
+
+func TestRelayRetries(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDataStore(t)
+	box := outbox.NewOutbox(store, outbox.WithCollection("outbox_retries"))
+
+	if err := placeOrder(ctx, store, box, "1", false); err != nil {
+		t.Fatalf("Error on placing order: %v", err)
+	}
+
+	calls := 0
+	relay := outbox.NewRelay(box, func(ctx context.Context, event *outbox.OutboxEvent) error {
+		calls++
+		if calls <= 2 {
+			return errors.New("broker unavailable")
+		}
+		return nil
+	}, outbox.WithBackoff(10*time.Millisecond, 10*time.Millisecond), outbox.WithLogger(nil))
+
+	n, err := relay.RunOnce(ctx)
+	assert.ErrorContains(t, err, "broker unavailable")
+	assert.Equal(t, 0, n)
+
+	n, err = relay.RunOnce(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n, "the event is not retried before the backoff passed")
+
+	backlog, err := box.Backlog(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, backlog.Pending)
+	assert.Equal(t, 1, backlog.Retrying)
+
+	for _, want := range []int{0, 1} {
+		time.Sleep(20 * time.Millisecond)
+		n, _ = relay.RunOnce(ctx)
+		assert.Equal(t, want, n)
+	}
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, outbox.RelayStats{Published: 1, Failed: 2}, relay.Stats())
+
+	// An event that always fails is given up after the maximum number of attempts.
+	if err := placeOrder(ctx, store, box, "2", false); err != nil {
+		t.Fatalf("Error on placing order: %v", err)
+	}
+	failing := outbox.NewRelay(box, func(ctx context.Context, event *outbox.OutboxEvent) error {
+		return errors.New("rejected")
+	}, outbox.WithBackoff(time.Millisecond, time.Millisecond), outbox.WithMaxAttempts(2))
+
+	for i := 0; i < 3; i++ {
+		failing.RunOnce(ctx)
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Equal(t, int64(2), failing.Stats().Failed)
+
+	backlog, err = box.Backlog(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, outbox.Backlog{Failed: 1}, backlog)
+}
@@ -0,0 +1,186 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// PublishFunc publishes a single event, e.g. to a message broker. If it returns an error, the publication is retried.
+	PublishFunc func(ctx context.Context, event *OutboxEvent) error
+
+	// Relay publishes the pending events of an [Outbox] in the order they are due.
+	//
+	// Multiple relays can run concurrently, e.g. one per instance of a service: an event is claimed by a single relay
+	// for the duration of its lease, see [WithLease].
+	Relay struct {
+		// The counters are the first fields, so that they are 64-bit aligned for the atomic operations on 32-bit platforms.
+		published int64
+		failed    int64
+
+		outbox         *Outbox
+		publish        PublishFunc
+		pollInterval   time.Duration
+		batchSize      int
+		maxAttempts    int
+		initialBackoff time.Duration
+		maxBackoff     time.Duration
+		lease          time.Duration
+		logf           func(format string, args ...interface{})
+	}
+
+	// RelayStats counts the publications of a [Relay] since it was created.
+	RelayStats struct {
+		// Published is the number of events that were published.
+		Published int64
+		// Failed is the number of failed publications, including those that are retried.
+		Failed int64
+	}
+)
+
+// NewRelay creates a Relay that passes the events of o to publish.
+func NewRelay(o *Outbox, publish PublishFunc, opts ...RelayOption) *Relay {
+	ops := &relayOption{
+		pollInterval:   time.Second,
+		batchSize:      100,
+		maxAttempts:    10,
+		initialBackoff: time.Second,
+		maxBackoff:     5 * time.Minute,
+		lease:          time.Minute,
+		logf:           log.Printf,
+	}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+
+	return &Relay{
+		outbox:         o,
+		publish:        publish,
+		pollInterval:   ops.pollInterval,
+		batchSize:      ops.batchSize,
+		maxAttempts:    ops.maxAttempts,
+		initialBackoff: ops.initialBackoff,
+		maxBackoff:     ops.maxBackoff,
+		lease:          ops.lease,
+		logf:           ops.logf,
+	}
+}
+
+// Stats returns the number of publications of the relay, see [Outbox.Backlog] for the events that are not sent yet.
+func (r *Relay) Stats() RelayStats {
+	return RelayStats{Published: atomic.LoadInt64(&r.published), Failed: atomic.LoadInt64(&r.failed)}
+}
+
+// RunOnce publishes up to one batch of the events that are due, see [WithBatchSize], and returns the number of published events.
+//
+// A failed publication is retried with exponential backoff, see [WithBackoff], until the maximum number of attempts
+// is reached. The returned error joins the errors of the failed publications and of the database.
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	now := time.Now()
+	events, err := r.outbox.events.FindMany(ctx,
+		bson.M{"status": bson.M{"$in": bson.A{StatusPending, StatusPublishing}}, "nextAttemptAt": bson.M{"$lte": now}},
+		options.Find().SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}, {Key: "_id", Value: 1}}).SetLimit(int64(r.batchSize)))
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", "outbox.Relay.RunOnce", err)
+	}
+
+	published := 0
+	var errs []error
+	for _, event := range events {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		ok, err := r.relay(ctx, event)
+		if ok {
+			published++
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%v: event %v: %w", "outbox.Relay.RunOnce", event.IdempotencyKey(), err))
+		}
+	}
+
+	return published, mongodb.JoinErrors(errs...)
+}
+
+// relay claims and publishes a single event, and records the result. It reports whether the event was published.
+func (r *Relay) relay(ctx context.Context, event *OutboxEvent) (bool, error) {
+	// The claim only succeeds if no other relay changed the event since it was read.
+	claimed, err := r.outbox.events.UpdateOne(ctx,
+		bson.M{"_id": event.MongoID, "status": event.Status, "nextAttemptAt": event.NextAttemptAt},
+		bson.M{"status": StatusPublishing, "nextAttemptAt": time.Now().Add(r.lease)})
+	if err != nil {
+		return false, fmt.Errorf("claiming: %w", err)
+	}
+	if claimed.MatchedCount == 0 {
+		return false, nil
+	}
+
+	publishErr := r.publish(ctx, event)
+	if publishErr == nil {
+		atomic.AddInt64(&r.published, 1)
+		if _, err := r.outbox.events.UpdateOne(ctx, bson.M{"_id": event.MongoID}, bson.M{"status": StatusSent, "sentAt": time.Now()}); err != nil {
+			return true, fmt.Errorf("marking as sent: %w", err)
+		}
+		return true, nil
+	}
+
+	atomic.AddInt64(&r.failed, 1)
+	attempts := event.Attempts + 1
+	update := bson.M{"attempts": attempts, "lastError": publishErr.Error(), "status": StatusPending, "nextAttemptAt": time.Now().Add(r.backoff(attempts))}
+	if attempts >= r.maxAttempts {
+		update["status"] = StatusFailed
+	}
+	if _, err := r.outbox.events.UpdateOne(ctx, bson.M{"_id": event.MongoID}, update); err != nil {
+		return false, mongodb.JoinErrors(fmt.Errorf("publishing: %w", publishErr), fmt.Errorf("recording the failure: %w", err))
+	}
+
+	return false, fmt.Errorf("publishing, attempt %d of %d: %w", attempts, r.maxAttempts, publishErr)
+}
+
+// backoff returns the delay before the next attempt after the given number of failed attempts.
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.initialBackoff
+	for i := 1; i < attempts && delay < r.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.maxBackoff {
+		return r.maxBackoff
+	}
+	return delay
+}
+
+// Run calls [Relay.RunOnce] until ctx is canceled, and returns the error of ctx. A full batch is followed by the next one
+// immediately, otherwise the relay waits for the poll interval, see [WithPollInterval].
+// The errors of the runs are logged, see [WithLogger].
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		n, err := r.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.log("%v", err)
+		}
+		if n == r.batchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.pollInterval):
+		}
+	}
+}
+
+func (r *Relay) log(format string, args ...interface{}) {
+	if r.logf != nil {
+		r.logf(format, args...)
+	}
+}
@@ -0,0 +1,63 @@
+package retention
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type (
+	// RunnerOption configures a [Runner], see [NewRetentionRunner].
+	RunnerOption interface {
+		apply(*runnerOption)
+	}
+
+	// PolicyOption configures a single policy, see [Runner.Add].
+	PolicyOption interface {
+		apply(*policyOption)
+	}
+)
+
+type (
+	runnerOption struct {
+		logf func(format string, args ...interface{})
+	}
+
+	policyOption struct {
+		name   string
+		filter bson.M
+	}
+)
+
+type loggerOption func(format string, args ...interface{})
+
+func (value loggerOption) apply(o *runnerOption) {
+	o.logf = value
+}
+
+// WithLogger sets the function that logs hints and the errors of [Runner.RunPeriodically], the default is log.Printf.
+// Passing nil disables logging.
+func WithLogger(logf func(format string, args ...interface{})) RunnerOption {
+	return loggerOption(logf)
+}
+
+type policyNameOption string
+
+func (value policyNameOption) apply(o *policyOption) {
+	o.name = string(value)
+}
+
+// WithName sets the name of the policy in the [Report] and the log, e.g. the name of the collection.
+func WithName(name string) PolicyOption {
+	return policyNameOption(name)
+}
+
+type policyFilterOption bson.M
+
+func (value policyFilterOption) apply(o *policyOption) {
+	o.filter = bson.M(value)
+}
+
+// WithFilter restricts the policy to the documents matching filter, e.g. the documents of a company
+// with a retention period of its own.
+func WithFilter(filter bson.M) PolicyOption {
+	return policyFilterOption(filter)
+}
@@ -0,0 +1,178 @@
+// Package retention deletes expired documents according to declarative retention policies,
+// e.g. "delete raw events older than 90 days".
+//
+// Where a policy only depends on the insertion date of the documents, a TTL index is preferable, since the server
+// removes the documents without a running process, see [mongodb.EnsureTTLIndex]. The [Runner] covers the cases
+// TTL indexes cannot express, like an age relative to a field that changes after insert or retention periods per company.
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const defaultBatchSize = 1000
+
+type (
+	// Runner deletes the documents that are older than the maximum age of its policies, see [Runner.Add].
+	//
+	// A Runner must not be modified while it is running.
+	Runner struct {
+		store    *datastore.DataStore
+		logf     func(format string, args ...interface{})
+		policies []policy
+	}
+
+	// Report is the result of [Runner.RunOnce], with one entry per policy in the order they were added.
+	Report struct {
+		Policies []PolicyResult
+	}
+
+	// PolicyResult is the result of a single policy.
+	PolicyResult struct {
+		Name   string
+		Field  string
+		MaxAge time.Duration
+		// Cutoff is the date before which documents were deleted.
+		Cutoff time.Time
+		// Deleted is the number of deleted documents, if Err is set, the number deleted before the error occurred.
+		Deleted int
+		Err     error
+	}
+
+	policy struct {
+		repo      mongodb.DeleteManyAudited
+		name      string
+		field     string
+		maxAge    time.Duration
+		batchSize int
+		filter    bson.M
+	}
+
+	// expiredDocument is the document type of collections added by name, only _id is ever read.
+	expiredDocument struct {
+		mongodb.BaseModel `bson:",inline"`
+	}
+)
+
+// NewRetentionRunner creates a Runner without policies for the collections of store.
+func NewRetentionRunner(store *datastore.DataStore, opts ...RunnerOption) *Runner {
+	ops := &runnerOption{logf: log.Printf}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+
+	return &Runner{store: store, logf: ops.logf}
+}
+
+// Add adds a policy that deletes the documents of r whose date field is older than maxAge, in batches of batchSize.
+// A batchSize <= 0 uses batches of 1000 documents.
+//
+// Documents without the field are never deleted. A policy without [WithFilter] could be replaced by a TTL index on field,
+// if field is not changed after insert, so a hint is logged for it.
+func (r *Runner) Add(repo mongodb.DeleteManyAudited, field string, maxAge time.Duration, batchSize int, opts ...PolicyOption) {
+	ops := &policyOption{name: field}
+	for _, opt := range opts {
+		opt.apply(ops)
+	}
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	if ops.filter == nil {
+		r.log("retention: policy %v: a TTL index on %v with expireAfterSeconds %d deletes the same documents without a runner, unless %v changes after insert",
+			ops.name, field, int64(maxAge/time.Second), field)
+	}
+
+	r.policies = append(r.policies, policy{
+		repo:      repo,
+		name:      ops.name,
+		field:     field,
+		maxAge:    maxAge,
+		batchSize: batchSize,
+		filter:    ops.filter,
+	})
+}
+
+// AddCollection adds a policy for the collection with the given name of the default database of the DataStore, see [Runner.Add].
+// The policy is named after the collection, unless [WithName] is passed.
+func (r *Runner) AddCollection(collection string, field string, maxAge time.Duration, batchSize int, opts ...PolicyOption) {
+	opts = append([]PolicyOption{WithName(collection)}, opts...)
+	r.Add(datastore.RepositoryFor[*expiredDocument](r.store, collection).(mongodb.DeleteManyAudited), field, maxAge, batchSize, opts...)
+}
+
+// RunOnce applies every policy once, and returns the number of deleted documents per policy.
+//
+// A failing policy does not stop the others: its error is recorded in the report, and the returned error joins
+// the errors of all failed policies. If ctx is canceled, the remaining policies fail with the error of ctx.
+func (r *Runner) RunOnce(ctx context.Context) (Report, error) {
+	report := Report{Policies: make([]PolicyResult, len(r.policies))}
+
+	var errs []error
+	for i, p := range r.policies {
+		res := p.run(ctx, time.Now())
+		report.Policies[i] = res
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("%v: %v: %w", "retention.RunOnce", p.name, res.Err))
+		}
+	}
+
+	return report, mongodb.JoinErrors(errs...)
+}
+
+// RunPeriodically calls [Runner.RunOnce] immediately and then every interval until ctx is canceled, and returns the error of ctx.
+// The errors of the runs are logged, see [WithLogger].
+func (r *Runner) RunPeriodically(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			r.log("%v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner) log(format string, args ...interface{}) {
+	if r.logf != nil {
+		r.logf(format, args...)
+	}
+}
+
+func (p policy) run(ctx context.Context, now time.Time) PolicyResult {
+	cutoff := now.Add(-p.maxAge)
+	res := PolicyResult{Name: p.name, Field: p.field, MaxAge: p.maxAge, Cutoff: cutoff}
+
+	filter := bson.M{p.field: bson.M{"$lt": cutoff}}
+	if p.filter != nil {
+		// MergeFilter never fails.
+		filter, _ = mongodb.MergeBSON(filter, p.filter, mongodb.MergeFilter)
+	}
+
+	res.Deleted, res.Err = p.repo.DeleteManyAudited(ctx, filter, p.batchSize, func([]primitive.ObjectID) error {
+		return ctx.Err()
+	})
+	return res
+}
+
+// Total returns the number of documents deleted by all policies.
+func (r Report) Total() int {
+	total := 0
+	for _, p := range r.Policies {
+		total += p.Deleted
+	}
+	return total
+}
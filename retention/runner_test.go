@@ -0,0 +1,115 @@
+package retention_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DataInsightHub/Go-Mongo-Helper/datastore"
+	"github.com/DataInsightHub/Go-Mongo-Helper/mongodb"
+	"github.com/DataInsightHub/Go-Mongo-Helper/retention"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Event struct {
+	mongodb.BaseModel `bson:",inline"`
+	CompanyID         primitive.ObjectID `bson:"companyID"`
+	ReceivedAt        time.Time          `bson:"receivedAt"`
+}
+
+func newTestDataStore(t *testing.T) *datastore.DataStore {
+	t.Helper()
+
+	store, err := datastore.NewDataStore("mongodb://localhost:27017", "testdb_retention")
+	if err != nil {
+		t.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	t.Cleanup(func() {
+		store.Database.Drop(context.Background())
+		store.Disconnect()
+	})
+
+	return store
+}
+
+func TestRunOnce(t *testing.T) {
+	ctx := context.Background()
+	store := newTestDataStore(t)
+	events := datastore.RepositoryFor[*Event](store, "events")
+	audits := datastore.RepositoryFor[*Event](store, "audits").(*mongodb.Repository[*Event])
+
+	short, other := primitive.NewObjectID(), primitive.NewObjectID()
+	day := 24 * time.Hour
+	var seeded []*Event
+	for _, age := range []time.Duration{10 * day, 40 * day, 100 * day, 200 * day} {
+		seeded = append(seeded, &Event{CompanyID: short, ReceivedAt: time.Now().Add(-age)}, &Event{CompanyID: other, ReceivedAt: time.Now().Add(-age)})
+	}
+	if _, err := events.InsertMany(ctx, seeded); err != nil {
+		t.Fatalf("Error on inserting events: %v", err)
+	}
+	for i := range seeded {
+		seeded[i].ResetMongoID()
+	}
+	if _, err := audits.InsertMany(ctx, seeded); err != nil {
+		t.Fatalf("Error on inserting audits: %v", err)
+	}
+
+	var hints []string
+	runner := retention.NewRetentionRunner(store, retention.WithLogger(func(format string, args ...interface{}) {
+		hints = append(hints, fmt.Sprintf(format, args...))
+	}))
+	runner.AddCollection("events", "receivedAt", 90*day, 2)
+	runner.Add(audits, "receivedAt", 30*day, 0, retention.WithName("audits of short"), retention.WithFilter(bson.M{"companyID": short}))
+	if assert.Len(t, hints, 1) {
+		assert.Contains(t, hints[0], "TTL index on receivedAt")
+	}
+
+	report, err := runner.RunOnce(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, report.Policies, 2) {
+		assert.Equal(t, "events", report.Policies[0].Name)
+		assert.Equal(t, 4, report.Policies[0].Deleted)
+		assert.Equal(t, "audits of short", report.Policies[1].Name)
+		assert.Equal(t, 3, report.Policies[1].Deleted)
+	}
+	assert.Equal(t, 7, report.Total())
+
+	count, err := events.CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+	count, err = audits.CountDocuments(ctx, bson.M{"companyID": short})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	count, err = audits.CountDocuments(ctx, bson.M{"companyID": other})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	// A second run finds nothing to delete.
+	report, err = runner.RunOnce(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.Total())
+}
+
+func TestRunPeriodically(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	store := newTestDataStore(t)
+	events := datastore.RepositoryFor[*Event](store, "events_periodic").(*mongodb.Repository[*Event])
+
+	runner := retention.NewRetentionRunner(store, retention.WithLogger(nil))
+	runner.Add(events, "receivedAt", time.Hour, 0)
+
+	if _, err := events.InsertOne(ctx, &Event{ReceivedAt: time.Now().Add(-2 * time.Hour)}); err != nil {
+		t.Fatalf("Error on inserting event: %v", err)
+	}
+
+	err := runner.RunPeriodically(ctx, 50*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	count, err := events.CountDocuments(context.Background(), bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}